@@ -0,0 +1,51 @@
+package feather
+
+import (
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// AccelerationProvider returns an external acceleration (m/s²) for a body.
+// Providers are evaluated per dynamic, awake body at each substep, before integration,
+// and their results are summed with World.Gravity.
+type AccelerationProvider func(body *actor.RigidBody) mgl64.Vec3
+
+// AddAcceleration registers an acceleration provider evaluated at each substep
+func (w *World) AddAcceleration(provider AccelerationProvider) {
+	w.accelerations = append(w.accelerations, provider)
+}
+
+// acceleration sums the gravity and all the registered providers for a body
+func (w *World) acceleration(body *actor.RigidBody) mgl64.Vec3 {
+	acceleration := w.Gravity
+	if !body.IsActive() {
+		return acceleration
+	}
+
+	for _, provider := range w.accelerations {
+		acceleration = acceleration.Add(provider(body))
+	}
+
+	return acceleration
+}
+
+// ConstantAcceleration returns a provider applying the same acceleration to every body (e.g. wind)
+func ConstantAcceleration(acceleration mgl64.Vec3) AccelerationProvider {
+	return func(body *actor.RigidBody) mgl64.Vec3 {
+		return acceleration
+	}
+}
+
+// RotatingFrameAcceleration returns the pseudo-accelerations of a reference frame rotating
+// at angularVelocity (rad/s) around origin, e.g. a space station or a spinning platform:
+//   - Centrifugal: -ω × (ω × r)
+//   - Coriolis: -2ω × v
+func RotatingFrameAcceleration(origin mgl64.Vec3, angularVelocity mgl64.Vec3) AccelerationProvider {
+	return func(body *actor.RigidBody) mgl64.Vec3 {
+		r := body.Transform.Position.Sub(origin)
+		centrifugal := angularVelocity.Cross(angularVelocity.Cross(r)).Mul(-1)
+		coriolis := angularVelocity.Cross(body.Velocity).Mul(-2)
+
+		return centrifugal.Add(coriolis)
+	}
+}
@@ -0,0 +1,74 @@
+package feather
+
+import (
+	"testing"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestWorld_AccelerationProviders(t *testing.T) {
+	body := createSphere(mgl64.Vec3{0, 10, 0}, 0.5, actor.BodyTypeDynamic)
+	world := &World{
+		Gravity:     mgl64.Vec3{0, -10, 0},
+		Substeps:    1,
+		SpatialGrid: NewSpatialGrid(1.0, 1024),
+		Events:      NewEvents(),
+	}
+	world.AddBody(body)
+	world.AddAcceleration(ConstantAcceleration(mgl64.Vec3{2, 0, 0}))
+	world.AddAcceleration(ConstantAcceleration(mgl64.Vec3{0, 0, -4}))
+
+	world.Step(0.1)
+
+	expected := mgl64.Vec3{0.2, -1, -0.4}
+	if !vec3ApproxEqual(body.Velocity, expected, 1e-9) {
+		t.Errorf("velocity = %v, want %v", body.Velocity, expected)
+	}
+}
+
+func TestWorld_Acceleration_SkipsStaticAndSleeping(t *testing.T) {
+	world := &World{Gravity: mgl64.Vec3{0, -9.81, 0}}
+	calls := 0
+	world.AddAcceleration(func(body *actor.RigidBody) mgl64.Vec3 {
+		calls++
+		return mgl64.Vec3{1, 0, 0}
+	})
+
+	static := createBox(mgl64.Vec3{}, mgl64.Vec3{1, 1, 1}, actor.BodyTypeStatic)
+	sleeping := createBox(mgl64.Vec3{}, mgl64.Vec3{1, 1, 1}, actor.BodyTypeDynamic)
+	sleeping.IsSleeping = true
+
+	if acceleration := world.acceleration(static); acceleration != world.Gravity {
+		t.Errorf("static body acceleration = %v, want gravity only", acceleration)
+	}
+	if acceleration := world.acceleration(sleeping); acceleration != world.Gravity {
+		t.Errorf("sleeping body acceleration = %v, want gravity only", acceleration)
+	}
+	if calls != 0 {
+		t.Errorf("providers should not be evaluated for static or sleeping bodies, got %d calls", calls)
+	}
+}
+
+func TestRotatingFrameAcceleration(t *testing.T) {
+	provider := RotatingFrameAcceleration(mgl64.Vec3{}, mgl64.Vec3{0, 1, 0})
+
+	t.Run("centrifugal", func(t *testing.T) {
+		body := createSphere(mgl64.Vec3{2, 0, 0}, 0.5, actor.BodyTypeDynamic)
+
+		// Pushed outward by ω²r
+		if acceleration := provider(body); !vec3ApproxEqual(acceleration, mgl64.Vec3{2, 0, 0}, 1e-9) {
+			t.Errorf("acceleration = %v, want {2 0 0}", acceleration)
+		}
+	})
+
+	t.Run("coriolis", func(t *testing.T) {
+		body := createSphere(mgl64.Vec3{}, 0.5, actor.BodyTypeDynamic)
+		body.Velocity = mgl64.Vec3{1, 0, 0}
+
+		// -2ω × v = -2 * (0,1,0) × (1,0,0) = (0,0,2)
+		if acceleration := provider(body); !vec3ApproxEqual(acceleration, mgl64.Vec3{0, 0, 2}, 1e-9) {
+			t.Errorf("acceleration = %v, want {0 0 2}", acceleration)
+		}
+	})
+}
@@ -0,0 +1,212 @@
+// Package accuracy runs canonical scenes with an analytic solution through a World, and reports the simulated
+// outcome against the expected one. It verifies the accuracy of the engine on a platform and with given settings
+// (time step, substeps, workers, build tags...) before shipping, e.g.
+//
+//	for _, result := range accuracy.Run(1.0/60.0, feather.WithSubsteps(8)) {
+//		fmt.Printf("%s: %v (expected %v, error %.2f%%)\n", result.Name, result.Simulated, result.Expected, 100*result.RelativeError())
+//	}
+//
+// The scenes create their World with feather.NewWorld and the given options, then set the gravity
+// and disable the sleep system: the bodies must not freeze at the turning points.
+package accuracy
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/akmonengine/feather"
+	"github.com/akmonengine/feather/actor"
+	"github.com/akmonengine/feather/constraint"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// Gravity is the acceleration (m/s²) of the scenes, along -Y
+const Gravity = 9.81
+
+// Result is the outcome of a scene
+type Result struct {
+	// Name of the scene, with its parameters
+	Name string
+	// Expected is the analytic value, Simulated the value measured in the World (same unit, see each scene)
+	Expected  float64
+	Simulated float64
+}
+
+// Error returns the absolute difference between the simulated and the expected values
+func (r Result) Error() float64 {
+	return math.Abs(r.Simulated - r.Expected)
+}
+
+// RelativeError returns the error relative to the expected value, the absolute error if it is 0
+func (r Result) RelativeError() float64 {
+	if r.Expected == 0 {
+		return r.Error()
+	}
+
+	return r.Error() / math.Abs(r.Expected)
+}
+
+// Run runs the canonical scenes: a pendulum, a box on an incline under and over its sliding threshold,
+// and an elastic collision between two spheres
+func Run(dt float64, options ...feather.WorldOption) []Result {
+	return []Result{
+		Pendulum(1.0, 0.5, dt, options...),
+		InclinedPlane(0.5, 20*math.Pi/180, dt, options...),
+		InclinedPlane(0.5, 35*math.Pi/180, dt, options...),
+		ElasticCollision(1.0, 3.0, 4.0, dt, options...),
+	}
+}
+
+// Pendulum swings a ball (radius 0.1m) hung at length (m) under a fixed pivot, released at amplitude (rad).
+// The result is the period (s), measured over 3 oscillations. The expected period is the one of the
+// physical pendulum at this amplitude (the ball rotates with the string), without small angle approximation.
+func Pendulum(length, amplitude, dt float64, options ...feather.WorldOption) Result {
+	const radius = 0.1
+	const oscillations = 3
+
+	world := newWorld(mgl64.Vec3{0, -Gravity, 0}, options...)
+	pivot := mgl64.Vec3{0, 0, 0}
+	position := mgl64.Vec3{length * math.Sin(amplitude), -length * math.Cos(amplitude), 0}
+	ball := actor.NewRigidBodyWithMass(
+		actor.Transform{Position: position, Rotation: mgl64.QuatIdent()},
+		&actor.Sphere{Radius: radius},
+		actor.BodyTypeDynamic,
+		1.0,
+	)
+	world.AddBody(ball)
+
+	// The string is a pinned particle attached at the pivot, out of the ball
+	anchor := actor.NewParticle(pivot, 0)
+	world.AddParticle(anchor)
+	world.AddConstraint(constraint.NewParticleAttachment(anchor, ball, 0))
+
+	// I/(m*g*L) of the physical pendulum, the period is 4*sqrt(I/(m*g*L))*K(sin(amplitude/2))
+	inertia := 0.4*radius*radius + length*length
+	expected := 4 * math.Sqrt(inertia/(Gravity*length)) * ellipticK(math.Sin(amplitude/2))
+
+	// The period is the time between the crossings of the vertical, from the positive side
+	angle := amplitude
+	var crossings []float64
+	for step := 0; len(crossings) <= oscillations && float64(step)*dt < 2*oscillations*expected; step++ {
+		world.Step(dt)
+
+		offset := ball.Transform.Position.Sub(pivot)
+		next := math.Atan2(offset.X(), -offset.Y())
+		if angle > 0 && next <= 0 {
+			crossings = append(crossings, (float64(step)+angle/(angle-next))*dt)
+		}
+		angle = next
+	}
+
+	simulated := math.Inf(1)
+	if len(crossings) > oscillations {
+		simulated = (crossings[oscillations] - crossings[0]) / oscillations
+	}
+
+	return Result{
+		Name:      fmt.Sprintf("pendulum period (length %gm, amplitude %grad)", length, amplitude),
+		Expected:  expected,
+		Simulated: simulated,
+	}
+}
+
+// InclinedPlane releases a box at rest on a static slope tilted by angle (rad), both with the friction coefficient
+// (static and dynamic). The result is the distance (m) the box slid down after 1 second: 0 under the threshold
+// tan(angle) <= friction, g*(sin(angle) - friction*cos(angle))*t²/2 over it.
+func InclinedPlane(friction, angle, dt float64, options ...feather.WorldOption) Result {
+	const duration = 1.0
+	halfExtent := 0.25
+
+	world := newWorld(mgl64.Vec3{0, -Gravity, 0}, options...)
+	rotation := mgl64.QuatRotate(angle, mgl64.Vec3{0, 0, 1})
+	normal := rotation.Rotate(mgl64.Vec3{0, 1, 0})
+	downhill := rotation.Rotate(mgl64.Vec3{-1, 0, 0})
+
+	slope := actor.NewRigidBody(
+		actor.Transform{Position: normal.Mul(-1), Rotation: rotation, InverseRotation: rotation.Inverse()},
+		&actor.Box{HalfExtents: mgl64.Vec3{20, 1, 5}},
+		actor.BodyTypeStatic,
+		0,
+	)
+	box := actor.NewRigidBodyWithMass(
+		actor.Transform{Position: normal.Mul(halfExtent), Rotation: rotation, InverseRotation: rotation.Inverse()},
+		&actor.Box{HalfExtents: mgl64.Vec3{halfExtent, halfExtent, halfExtent}},
+		actor.BodyTypeDynamic,
+		1.0,
+	)
+	for _, body := range []*actor.RigidBody{slope, box} {
+		body.Material.StaticFriction = friction
+		body.Material.DynamicFriction = friction
+		world.AddBody(body)
+	}
+
+	start := box.Transform.Position
+	for step := 0; float64(step)*dt < duration-dt/2; step++ {
+		world.Step(dt)
+	}
+
+	acceleration := math.Max(0, Gravity*(math.Sin(angle)-friction*math.Cos(angle)))
+
+	return Result{
+		Name:      fmt.Sprintf("inclined plane slide distance (friction %g, angle %grad)", friction, angle),
+		Expected:  0.5 * acceleration * duration * duration,
+		Simulated: box.Transform.Position.Sub(start).Dot(downhill),
+	}
+}
+
+// ElasticCollision throws a sphere of massA (kg) at speed (m/s) on a sphere of massB at rest, both with a
+// restitution of 1 and without gravity. The result is the velocity (m/s) of the second sphere after the impact,
+// expected at 2*massA*speed/(massA+massB) by the conservation of the momentum and of the kinetic energy.
+func ElasticCollision(massA, massB, speed, dt float64, options ...feather.WorldOption) Result {
+	const radius = 0.5
+
+	world := newWorld(mgl64.Vec3{}, options...)
+	a := actor.NewRigidBodyWithMass(
+		actor.Transform{Position: mgl64.Vec3{-2, 0, 0}, Rotation: mgl64.QuatIdent()},
+		&actor.Sphere{Radius: radius},
+		actor.BodyTypeDynamic,
+		massA,
+	)
+	a.Velocity = mgl64.Vec3{speed, 0, 0}
+	b := actor.NewRigidBodyWithMass(
+		actor.Transform{Position: mgl64.Vec3{0, 0, 0}, Rotation: mgl64.QuatIdent()},
+		&actor.Sphere{Radius: radius},
+		actor.BodyTypeDynamic,
+		massB,
+	)
+	for _, body := range []*actor.RigidBody{a, b} {
+		body.Material.Restitution = 1
+		world.AddBody(body)
+	}
+
+	// Until the spheres separate, with a margin for a slow sphere B
+	duration := 2 * (2 - 2*radius) / speed
+	for step := 0; float64(step)*dt < duration; step++ {
+		world.Step(dt)
+	}
+
+	return Result{
+		Name:      fmt.Sprintf("elastic collision velocity (masses %gkg and %gkg, speed %gm/s)", massA, massB, speed),
+		Expected:  2 * massA * speed / (massA + massB),
+		Simulated: b.Velocity.X(),
+	}
+}
+
+// newWorld creates the World of a scene: the options, then the gravity of the scene without sleep
+func newWorld(gravity mgl64.Vec3, options ...feather.WorldOption) *feather.World {
+	world := feather.NewWorld(options...)
+	world.Gravity = gravity
+	world.Tolerances.SleepTime = math.Inf(1)
+
+	return world
+}
+
+// ellipticK returns the complete elliptic integral of the first kind K(k), by the arithmetic-geometric mean
+func ellipticK(k float64) float64 {
+	a, b := 1.0, math.Sqrt(1-k*k)
+	for math.Abs(a-b) > 1e-15*a {
+		a, b = (a+b)/2, math.Sqrt(a*b)
+	}
+
+	return math.Pi / (2 * a)
+}
@@ -0,0 +1,87 @@
+package accuracy
+
+import (
+	"math"
+	"testing"
+
+	"github.com/akmonengine/feather"
+)
+
+func TestResult_Error(t *testing.T) {
+	r := Result{Expected: 2, Simulated: 1.5}
+	if r.Error() != 0.5 || r.RelativeError() != 0.25 {
+		t.Errorf("Error = %v, RelativeError = %v, want 0.5 and 0.25", r.Error(), r.RelativeError())
+	}
+
+	r = Result{Expected: 0, Simulated: -0.1}
+	if r.RelativeError() != 0.1 {
+		t.Errorf("RelativeError = %v, want the absolute error 0.1", r.RelativeError())
+	}
+}
+
+func TestEllipticK(t *testing.T) {
+	if k := ellipticK(0); math.Abs(k-math.Pi/2) > 1e-15 {
+		t.Errorf("K(0) = %v, want pi/2", k)
+	}
+	// K(sin(pi/4)), tabulated
+	if k := ellipticK(math.Sqrt2 / 2); math.Abs(k-1.8540746773013719) > 1e-12 {
+		t.Errorf("K(1/sqrt(2)) = %v, want 1.8540746773013719", k)
+	}
+}
+
+func TestPendulum(t *testing.T) {
+	result := Pendulum(1.0, 0.5, 1.0/60.0, feather.WithSubsteps(8))
+	if result.RelativeError() > 0.01 {
+		t.Errorf("%s: %v, want %v", result.Name, result.Simulated, result.Expected)
+	}
+
+	// The error decreases with the substeps
+	coarse := Pendulum(1.0, 0.5, 1.0/60.0, feather.WithSubsteps(2))
+	if coarse.Error() <= result.Error() {
+		t.Errorf("error with 2 substeps %v, not above the error with 8 substeps %v", coarse.Error(), result.Error())
+	}
+}
+
+func TestInclinedPlane_Frictionless(t *testing.T) {
+	angle := 20 * math.Pi / 180
+	result := InclinedPlane(0, angle, 1.0/60.0)
+	if result.Expected != 0.5*Gravity*math.Sin(angle) {
+		t.Errorf("expected %v, want g*sin(angle)/2", result.Expected)
+	}
+	if result.RelativeError() > 0.02 {
+		t.Errorf("%s: %v, want %v", result.Name, result.Simulated, result.Expected)
+	}
+}
+
+func TestInclinedPlane_HoldsUnderFrictionThreshold(t *testing.T) {
+	result := InclinedPlane(0.5, 20*math.Pi/180, 1.0/60.0)
+	if result.Expected != 0 {
+		t.Fatalf("expected %v, want 0 under the threshold tan(angle) <= friction", result.Expected)
+	}
+	// The friction acts on the velocities: the box only creeps by the gravity displacement of the substeps
+	frictionless := InclinedPlane(0, 20*math.Pi/180, 1.0/60.0)
+	if result.Simulated > 0.05*frictionless.Simulated {
+		t.Errorf("%s: the box slid %vm, want held by the static friction (%vm without)", result.Name, result.Simulated, frictionless.Simulated)
+	}
+}
+
+func TestElasticCollision(t *testing.T) {
+	for _, masses := range [][2]float64{{1, 1}, {1, 3}, {3, 1}} {
+		result := ElasticCollision(masses[0], masses[1], 4.0, 1.0/60.0)
+		if result.RelativeError() > 0.01 {
+			t.Errorf("%s: %v, want %v", result.Name, result.Simulated, result.Expected)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	results := Run(1.0/60.0, feather.WithWorkers(2))
+	if len(results) != 4 {
+		t.Fatalf("expected 4 results, got %d", len(results))
+	}
+	for _, result := range results {
+		if result.Name == "" || math.IsNaN(result.Simulated) || math.IsInf(result.Simulated, 0) {
+			t.Errorf("invalid result %+v", result)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package actor
 
-import "github.com/go-gl/mathgl/mgl64"
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
 
 // AABB represents an axis-aligned bounding box
 type AABB struct {
@@ -22,3 +26,57 @@ func (a AABB) Overlaps(other AABB) bool {
 		a.Max.Y() >= other.Min.Y() && a.Min.Y() <= other.Max.Y() &&
 		a.Max.Z() >= other.Min.Z() && a.Min.Z() <= other.Max.Z()
 }
+
+// Swept returns the AABB extended to contain itself translated by displacement
+func (a AABB) Swept(displacement mgl64.Vec3) AABB {
+	for i := 0; i < 3; i++ {
+		if displacement[i] < 0 {
+			a.Min[i] += displacement[i]
+		} else {
+			a.Max[i] += displacement[i]
+		}
+	}
+
+	return a
+}
+
+// RayHit returns the distance along the normalized direction at which the ray enters the AABB,
+// 0 if the origin is inside. Returns false if the ray misses it within maxDist.
+func (a AABB) RayHit(origin, direction mgl64.Vec3, maxDist float64) (float64, bool) {
+	distance, _, ok := raySlabs(origin, direction, a.Min, a.Max, maxDist)
+
+	return distance, ok
+}
+
+// raySlabs intersects the ray with the slabs of the box [min, max] (Kay-Kajiya slab test).
+// Returns the entry distance and the axis of the slab entered last, -1 if the origin is inside.
+func raySlabs(origin, direction, min, max mgl64.Vec3, maxDist float64) (float64, int, bool) {
+	tMin, tMax := 0.0, maxDist
+	axis := -1
+
+	for i := 0; i < 3; i++ {
+		if math.Abs(direction[i]) < 1e-12 {
+			// Parallel to the slab: the origin must be between its planes
+			if origin[i] < min[i] || origin[i] > max[i] {
+				return 0, -1, false
+			}
+			continue
+		}
+
+		inverse := 1 / direction[i]
+		t1 := (min[i] - origin[i]) * inverse
+		t2 := (max[i] - origin[i]) * inverse
+		if t1 > t2 {
+			t1, t2 = t2, t1
+		}
+		if t1 > tMin {
+			tMin, axis = t1, i
+		}
+		tMax = math.Min(tMax, t2)
+		if tMin > tMax {
+			return 0, -1, false
+		}
+	}
+
+	return tMin, axis, true
+}
@@ -802,3 +802,16 @@ func TestAABBContainsPoint_ContainmentHierarchy(t *testing.T) {
 		}
 	})
 }
+
+func TestAABBSwept(t *testing.T) {
+	aabb := AABB{Min: mgl64.Vec3{-1, -1, -1}, Max: mgl64.Vec3{1, 1, 1}}
+
+	swept := aabb.Swept(mgl64.Vec3{2, -3, 0})
+	expected := AABB{Min: mgl64.Vec3{-1, -4, -1}, Max: mgl64.Vec3{3, 1, 1}}
+	if swept != expected {
+		t.Errorf("Swept = %v, want %v", swept, expected)
+	}
+	if aabb.Swept(mgl64.Vec3{}) != aabb {
+		t.Errorf("Swept by a zero displacement changed the AABB")
+	}
+}
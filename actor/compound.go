@@ -0,0 +1,326 @@
+package actor
+
+import (
+	"errors"
+	"math"
+	"slices"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// ErrInvalidCompound is returned when a compound has no children, or a child that is not a convex shape
+var ErrInvalidCompound = errors.New("actor: compound requires at least one child with a convex shape and a volume")
+
+// CompoundChild is a convex shape placed in the local space of a Compound
+type CompoundChild struct {
+	Shape    ShapeInterface
+	Position mgl64.Vec3
+	// Rotation of the child, a zero quaternion is the identity
+	Rotation mgl64.Quat
+	// Trigger makes the child a sensor, e.g. the bumper of a vehicle: its overlaps send the trigger events
+	// of the body (see feather.TriggerChild), without contacts. It keeps its share of the mass.
+	Trigger bool
+}
+
+// Compound represents a collision shape made of several convex shapes, e.g. a table or a vehicle.
+// The children are translated so the local origin is the center of mass.
+// The narrow phase tests each child separately, the compound itself is not convex.
+type Compound struct {
+	// Children in local space, relative to the center of mass.
+	// They must not be changed directly, the mass properties would be stale: see AddChild and RemoveChild.
+	Children []CompoundChild
+	// CenterOfMass is the translation removed from the input positions:
+	// a child given at p is at p - CenterOfMass in local space
+	CenterOfMass mgl64.Vec3
+
+	volume      float64
+	unitInertia mgl64.Mat3 // Inertia tensor for a unit density
+	aabb        AABB
+	// userShift is the offset of the center of mass set by RigidBody.SetCenterOfMass, kept by AddChild and RemoveChild
+	userShift mgl64.Vec3
+	// pendingShift is the move of the center of mass by AddChild and RemoveChild, not applied to the body yet
+	pendingShift    mgl64.Vec3
+	childrenChanged bool
+	shapeChanges
+}
+
+// NewCompound creates a compound from its children, and precomputes the mass properties:
+// the volume, the center of mass and the inertia combined with the parallel axis theorem.
+// Returns ErrInvalidCompound for an empty compound, or a child that is a plane, a triangle mesh or a compound.
+func NewCompound(children []CompoundChild) (*Compound, error) {
+	c := &Compound{}
+	if err := c.build(children); err != nil {
+		return nil, err
+	}
+	c.ComputeAABB(NewTransform())
+
+	return c, nil
+}
+
+// build sets the children, given in the input space, translated to their center of mass, and the mass properties.
+// The compound is unchanged on error.
+func (c *Compound) build(input []CompoundChild) error {
+	if len(input) == 0 {
+		return ErrInvalidCompound
+	}
+
+	children := make([]CompoundChild, len(input))
+	var weightedCenter mgl64.Vec3
+	volume := 0.0
+	for i, child := range input {
+		switch child.Shape.(type) {
+		case nil, *Plane, *TriangleMesh, *Compound:
+			return ErrInvalidCompound
+		}
+		if child.Rotation == (mgl64.Quat{}) {
+			child.Rotation = mgl64.QuatIdent()
+		}
+		child.Rotation = child.Rotation.Normalize()
+
+		childVolume := child.Shape.ComputeMass(1.0)
+		volume += childVolume
+		weightedCenter = weightedCenter.Add(child.Position.Mul(childVolume))
+		children[i] = child
+	}
+	if !(volume > 0) || math.IsInf(volume, 1) {
+		return ErrInvalidCompound
+	}
+
+	c.Children = children
+	c.volume = volume
+	c.CenterOfMass = weightedCenter.Mul(1.0 / volume)
+	c.unitInertia = mgl64.Mat3{}
+	for i := range c.Children {
+		child := &c.Children[i]
+		child.Position = child.Position.Sub(c.CenterOfMass)
+
+		// Child inertia rotated into the compound space, moved to the center of mass by the parallel axis theorem
+		childVolume := child.Shape.ComputeMass(1.0)
+		rotation := child.Rotation.Mat4().Mat3()
+		inertia := rotation.Mul3(child.Shape.ComputeInertia(childVolume)).Mul3(rotation.Transpose())
+		offset := mgl64.Ident3().Mul(child.Position.Dot(child.Position)).Sub(child.Position.OuterProd3(child.Position))
+		c.unitInertia = c.unitInertia.Add(inertia).Add(offset.Mul(childVolume))
+	}
+
+	return nil
+}
+
+// AddChild adds a child given in the input space of NewCompound, e.g. a turret mounted on a tank.
+// The mass properties are computed again, and the center of mass moves: the owning body applies the changes
+// at the next World.Step, or immediately with RigidBody.ApplyShapeChanges, keeping the other children in place.
+// Returns ErrInvalidCompound for a child that is not a convex shape with a volume.
+func (c *Compound) AddChild(child CompoundChild) error {
+	return c.rebuild(append(c.inputChildren(), child))
+}
+
+// RemoveChild removes the child at index, e.g. a turret knocked off a tank, and returns it in the input space
+// of NewCompound. The mass properties are updated like AddChild.
+// Returns ErrInvalidCompound if index is out of range, or for the last child: a compound cannot be empty.
+func (c *Compound) RemoveChild(index int) (CompoundChild, error) {
+	if index < 0 || index >= len(c.Children) || len(c.Children) == 1 {
+		return CompoundChild{}, ErrInvalidCompound
+	}
+
+	children := c.inputChildren()
+	removed := children[index]
+	if err := c.rebuild(slices.Delete(children, index, index+1)); err != nil {
+		return CompoundChild{}, err
+	}
+
+	return removed, nil
+}
+
+// inputChildren returns a copy of the children in the input space of NewCompound
+func (c *Compound) inputChildren() []CompoundChild {
+	children := slices.Clone(c.Children)
+	for i := range children {
+		children[i].Position = children[i].Position.Add(c.CenterOfMass)
+	}
+
+	return children
+}
+
+// rebuild replaces the children, keeps the offset of SetCenterOfMass, and records the move of the center of mass
+// for the owning body
+func (c *Compound) rebuild(children []CompoundChild) error {
+	previous := c.CenterOfMass
+	if err := c.build(children); err != nil {
+		return err
+	}
+	for i := range c.Children {
+		c.Children[i].Position = c.Children[i].Position.Sub(c.userShift)
+	}
+	c.CenterOfMass = c.CenterOfMass.Add(c.userShift)
+
+	c.pendingShift = c.pendingShift.Add(c.CenterOfMass.Sub(previous))
+	c.childrenChanged = true
+	c.markDirty(true)
+
+	return nil
+}
+
+// ChildrenChanged returns true if children were added or removed since the owning body applied the changes
+func (c *Compound) ChildrenChanged() bool {
+	return c.childrenChanged
+}
+
+// consumeShift returns the move of the center of mass since the owning body applied the changes, and clears it
+func (c *Compound) consumeShift() mgl64.Vec3 {
+	shift := c.pendingShift
+	c.pendingShift = mgl64.Vec3{}
+	c.childrenChanged = false
+
+	return shift
+}
+
+// shiftCenterOfMass moves the local origin by offset, see RigidBody.SetCenterOfMass: the children are translated
+// by -offset and the inertia is kept. The AABB must be computed again by the body.
+func (c *Compound) shiftCenterOfMass(offset mgl64.Vec3) {
+	for i := range c.Children {
+		c.Children[i].Position = c.Children[i].Position.Sub(offset)
+	}
+	c.CenterOfMass = c.CenterOfMass.Add(offset)
+	c.userShift = c.userShift.Add(offset)
+}
+
+// ChildTransform returns the world transform of a child, for a compound at the given transform
+func (c *Compound) ChildTransform(index int, transform Transform) Transform {
+	child := c.Children[index]
+	rotation := transform.Rotation.Mul(child.Rotation)
+
+	return Transform{
+		Position:        transform.Position.Add(transform.Rotation.Rotate(child.Position)),
+		Rotation:        rotation,
+		InverseRotation: rotation.Conjugate(),
+	}
+}
+
+// ComputeAABB also updates the AABB of each child, used by the narrow phase to skip the distant children
+func (c *Compound) ComputeAABB(transform Transform) {
+	for i, child := range c.Children {
+		child.Shape.ComputeAABB(c.ChildTransform(i, transform))
+		if i == 0 {
+			c.aabb = child.Shape.GetAABB()
+		} else {
+			c.aabb = mergeAABB(c.aabb, child.Shape.GetAABB())
+		}
+	}
+}
+
+func (c *Compound) GetAABB() AABB {
+	return c.aabb
+}
+
+// ComputeMass calculates mass data for the compound
+func (c *Compound) ComputeMass(density float64) float64 {
+	return density * c.volume
+}
+
+func (c *Compound) ComputeInertia(mass float64) mgl64.Mat3 {
+	return c.unitInertia.Mul(mass / c.volume)
+}
+
+// Support returns the farthest support point of the children: the support of their convex hull
+func (c *Compound) Support(direction mgl64.Vec3) mgl64.Vec3 {
+	var best mgl64.Vec3
+	bestDot := math.Inf(-1)
+	for _, child := range c.Children {
+		localDirection := child.Rotation.Conjugate().Rotate(direction)
+		point := child.Position.Add(child.Rotation.Rotate(child.Shape.Support(localDirection)))
+		if dot := point.Dot(direction); dot > bestDot {
+			best, bestDot = point, dot
+		}
+	}
+
+	return best
+}
+
+// GetContactFeature returns the support point: the narrow phase builds the manifolds from the children
+func (c *Compound) GetContactFeature(direction mgl64.Vec3, output *[8]mgl64.Vec3, count *int) {
+	output[0] = c.Support(direction)
+	*count = 1
+}
+
+// CollideWithPlane - Collision Compound/Plane, the contact points of the children are reduced to 4.
+// The trigger children have no contact.
+func (c *Compound) CollideWithPlane(planeNormal mgl64.Vec3, planeDistance float64, myTransform Transform) (bool, PlaneContact) {
+	var contactPoints []ContactPoint
+
+	for i, child := range c.Children {
+		if child.Trigger {
+			continue
+		}
+		if collision, points := child.Shape.CollideWithPlane(planeNormal, planeDistance, c.ChildTransform(i, myTransform)); collision {
+			contactPoints = append(contactPoints, points...)
+		}
+	}
+
+	if len(contactPoints) == 0 {
+		return false, PlaneContact{}
+	}
+
+	if len(contactPoints) > 4 {
+		contactPoints = reduceTo4ContactPoints(contactPoints, planeNormal)
+	}
+
+	return true, contactPoints
+}
+
+// ClosestPoint returns the point unchanged if it is inside a child,
+// otherwise the closest of the closest points of the children
+func (c *Compound) ClosestPoint(point mgl64.Vec3) mgl64.Vec3 {
+	var closest mgl64.Vec3
+	closestDistSq := math.Inf(1)
+	for _, child := range c.Children {
+		localPoint := child.Rotation.Conjugate().Rotate(point.Sub(child.Position))
+		localClosest := child.Shape.ClosestPoint(localPoint)
+		if localClosest == localPoint {
+			return point
+		}
+
+		candidate := child.Position.Add(child.Rotation.Rotate(localClosest))
+		if distSq := candidate.Sub(point).LenSqr(); distSq < closestDistSq {
+			closest, closestDistSq = candidate, distSq
+		}
+	}
+
+	return closest
+}
+
+// ContainsPoint returns true if a child contains the point
+func (c *Compound) ContainsPoint(point mgl64.Vec3) bool {
+	for _, child := range c.Children {
+		if child.Shape.ContainsPoint(child.Rotation.Conjugate().Rotate(point.Sub(child.Position))) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// BoundingRadius returns the distance from the center of mass to the farthest child bounding sphere
+func (c *Compound) BoundingRadius() float64 {
+	radius := 0.0
+	for _, child := range c.Children {
+		radius = math.Max(radius, child.Position.Len()+child.Shape.BoundingRadius())
+	}
+
+	return radius
+}
+
+// Raycast returns the nearest hit of the children
+func (c *Compound) Raycast(origin, direction mgl64.Vec3, maxDist float64) (float64, mgl64.Vec3, bool) {
+	best := maxDist
+	var normal mgl64.Vec3
+	hit := false
+
+	for _, child := range c.Children {
+		inverse := child.Rotation.Conjugate()
+		localOrigin := inverse.Rotate(origin.Sub(child.Position))
+		if distance, localNormal, ok := child.Shape.Raycast(localOrigin, inverse.Rotate(direction), best); ok {
+			best, normal, hit = distance, child.Rotation.Rotate(localNormal), true
+		}
+	}
+
+	return best, normal, hit
+}
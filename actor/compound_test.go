@@ -0,0 +1,243 @@
+package actor
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// twoCubes returns a compound of two unit cubes side by side along X, equivalent to a 2x1x1 box
+func twoCubes(t *testing.T, offset mgl64.Vec3) *Compound {
+	t.Helper()
+	compound, err := NewCompound([]CompoundChild{
+		{Shape: &Box{HalfExtents: mgl64.Vec3{0.5, 0.5, 0.5}}, Position: offset.Add(mgl64.Vec3{-0.5, 0, 0})},
+		{Shape: &Box{HalfExtents: mgl64.Vec3{0.5, 0.5, 0.5}}, Position: offset.Add(mgl64.Vec3{0.5, 0, 0})},
+	})
+	if err != nil {
+		t.Fatalf("NewCompound failed: %v", err)
+	}
+
+	return compound
+}
+
+func TestNewCompound_Invalid(t *testing.T) {
+	inputs := [][]CompoundChild{
+		nil,
+		{{Shape: nil}},
+		{{Shape: &Plane{Normal: mgl64.Vec3{0, 1, 0}}}},
+		{{Shape: &Sphere{Radius: 0}}},
+	}
+	for _, children := range inputs {
+		if _, err := NewCompound(children); !errors.Is(err, ErrInvalidCompound) {
+			t.Errorf("NewCompound(%v) = %v, want ErrInvalidCompound", children, err)
+		}
+	}
+}
+
+// TestCompound_MassProperties compares two cubes side by side to the equivalent box
+func TestCompound_MassProperties(t *testing.T) {
+	offset := mgl64.Vec3{3, 1, -2}
+	compound := twoCubes(t, offset)
+	box := &Box{HalfExtents: mgl64.Vec3{1, 0.5, 0.5}}
+
+	if !vec3Equal(compound.CenterOfMass, offset, 1e-9) {
+		t.Errorf("CenterOfMass = %v, want %v", compound.CenterOfMass, offset)
+	}
+	if mass := compound.ComputeMass(3); math.Abs(mass-box.ComputeMass(3)) > 1e-9 {
+		t.Errorf("mass = %v, want %v", mass, box.ComputeMass(3))
+	}
+
+	inertia := compound.ComputeInertia(6)
+	expected := box.ComputeInertia(6)
+	for i := range inertia {
+		if math.Abs(inertia[i]-expected[i]) > 1e-9 {
+			t.Fatalf("inertia = %v, want %v", inertia, expected)
+		}
+	}
+}
+
+// TestCompound_RotatedChild rotates a flat box child, its inertia is rotated with it
+// TestCompound_AddRemoveChild builds the two cubes one child at a time, then removes one
+func TestCompound_AddRemoveChild(t *testing.T) {
+	offset := mgl64.Vec3{3, 1, -2}
+	compound, err := NewCompound([]CompoundChild{{Shape: &Box{HalfExtents: mgl64.Vec3{0.5, 0.5, 0.5}}, Position: offset.Add(mgl64.Vec3{-0.5, 0, 0})}})
+	if err != nil {
+		t.Fatalf("NewCompound failed: %v", err)
+	}
+	if err := compound.AddChild(CompoundChild{Shape: &Box{HalfExtents: mgl64.Vec3{0.5, 0.5, 0.5}}, Position: offset.Add(mgl64.Vec3{0.5, 0, 0})}); err != nil {
+		t.Fatalf("AddChild failed: %v", err)
+	}
+
+	expected := twoCubes(t, offset)
+	if !vec3Equal(compound.CenterOfMass, expected.CenterOfMass, 1e-9) || compound.ComputeMass(3) != expected.ComputeMass(3) {
+		t.Errorf("center of mass %v and mass %v, want %v and %v", compound.CenterOfMass, compound.ComputeMass(3), expected.CenterOfMass, expected.ComputeMass(3))
+	}
+	inertia, expectedInertia := compound.ComputeInertia(6), expected.ComputeInertia(6)
+	for i := range inertia {
+		if math.Abs(inertia[i]-expectedInertia[i]) > 1e-9 {
+			t.Fatalf("inertia = %v, want %v", inertia, expectedInertia)
+		}
+	}
+
+	removed, err := compound.RemoveChild(0)
+	if err != nil || !vec3Equal(removed.Position, offset.Add(mgl64.Vec3{-0.5, 0, 0}), 1e-9) {
+		t.Errorf("RemoveChild = %+v, %v, want the first cube in the input space", removed, err)
+	}
+	if !vec3Equal(compound.CenterOfMass, offset.Add(mgl64.Vec3{0.5, 0, 0}), 1e-9) || !vec3Equal(compound.Children[0].Position, mgl64.Vec3{}, 1e-9) {
+		t.Errorf("center of mass %v, want the remaining cube", compound.CenterOfMass)
+	}
+
+	if _, err := compound.RemoveChild(0); !errors.Is(err, ErrInvalidCompound) {
+		t.Errorf("RemoveChild of the last child = %v, want ErrInvalidCompound", err)
+	}
+	if _, err := compound.RemoveChild(3); !errors.Is(err, ErrInvalidCompound) {
+		t.Errorf("RemoveChild out of range = %v, want ErrInvalidCompound", err)
+	}
+	if err := compound.AddChild(CompoundChild{Shape: &Plane{Normal: mgl64.Vec3{0, 1, 0}}}); !errors.Is(err, ErrInvalidCompound) || len(compound.Children) != 1 {
+		t.Errorf("AddChild of a plane = %v with %d children, want ErrInvalidCompound and the compound unchanged", err, len(compound.Children))
+	}
+}
+
+// TestCompound_RemoveChildFromBody checks the body follows the center of mass, the remaining child stays in place
+func TestCompound_RemoveChildFromBody(t *testing.T) {
+	compound := twoCubes(t, mgl64.Vec3{})
+	transform := NewTransform()
+	transform.Position = mgl64.Vec3{1, 2, 3}
+	transform.Rotation = mgl64.QuatRotate(math.Pi/2, mgl64.Vec3{0, 0, 1})
+	transform.InverseRotation = transform.Rotation.Inverse()
+	body := NewRigidBody(transform, compound, BodyTypeDynamic, 2)
+	kept := compound.ChildTransform(1, body.Transform).Position
+
+	if _, err := compound.RemoveChild(0); err != nil {
+		t.Fatalf("RemoveChild failed: %v", err)
+	}
+	if !compound.ChildrenChanged() || !body.ApplyShapeChanges() || compound.ChildrenChanged() {
+		t.Fatalf("children changes not applied to the body")
+	}
+
+	if position := compound.ChildTransform(0, body.Transform).Position; !vec3Equal(position, kept, 1e-9) {
+		t.Errorf("remaining cube at %v, want kept at %v", position, kept)
+	}
+	if !vec3Equal(body.Transform.Position, kept, 1e-9) {
+		t.Errorf("body at %v, want at the center of the remaining cube %v", body.Transform.Position, kept)
+	}
+	if mass := body.Material.GetMass(); math.Abs(mass-2) > 1e-9 {
+		t.Errorf("mass = %v, want the density of the remaining cube", mass)
+	}
+	if expected := (&Box{HalfExtents: mgl64.Vec3{0.5, 0.5, 0.5}}).ComputeInertia(2); body.InertiaLocal != expected {
+		t.Errorf("inertia = %v, want the one of the remaining cube %v", body.InertiaLocal, expected)
+	}
+	if aabb := body.Shape.GetAABB(); !vec3Equal(aabb.Min, kept.Sub(mgl64.Vec3{0.5, 0.5, 0.5}), 1e-9) {
+		t.Errorf("AABB = %+v, want around the remaining cube", aabb)
+	}
+}
+
+func TestCompound_RotatedChild(t *testing.T) {
+	flat := &Box{HalfExtents: mgl64.Vec3{2, 0.1, 1}}
+	compound, err := NewCompound([]CompoundChild{{Shape: flat, Rotation: mgl64.QuatRotate(math.Pi/2, mgl64.Vec3{0, 0, 1})}})
+	if err != nil {
+		t.Fatalf("NewCompound failed: %v", err)
+	}
+
+	inertia := compound.ComputeInertia(1)
+	expected := (&Box{HalfExtents: mgl64.Vec3{0.1, 2, 1}}).ComputeInertia(1)
+	for i := range inertia {
+		if math.Abs(inertia[i]-expected[i]) > 1e-9 {
+			t.Fatalf("inertia = %v, want %v", inertia, expected)
+		}
+	}
+	if support := compound.Support(mgl64.Vec3{0, 1, 0}); math.Abs(support.Y()-2) > 1e-9 {
+		t.Errorf("support = %v, want the rotated long axis along Y", support)
+	}
+}
+
+func TestCompound_AABBAndSupport(t *testing.T) {
+	compound := twoCubes(t, mgl64.Vec3{})
+	transform := Transform{Position: mgl64.Vec3{0, 5, 0}, Rotation: mgl64.QuatRotate(math.Pi/2, mgl64.Vec3{0, 1, 0})}
+	compound.ComputeAABB(transform)
+
+	aabb := compound.GetAABB()
+	if !vec3Equal(aabb.Min, mgl64.Vec3{-0.5, 4.5, -1}, 1e-9) || !vec3Equal(aabb.Max, mgl64.Vec3{0.5, 5.5, 1}, 1e-9) {
+		t.Errorf("AABB = %v, want the long axis along Z", aabb)
+	}
+	// The children AABB follow the compound
+	if child := compound.Children[0].Shape.GetAABB(); child.Max.Y() != aabb.Max.Y() || child.Max.Z()-child.Min.Z() > 1+1e-9 {
+		t.Errorf("child AABB = %v, want a unit cube at the compound height", child)
+	}
+
+	if support := compound.Support(mgl64.Vec3{1, 0.1, 0}); !vec3Equal(support, mgl64.Vec3{1, 0.5, 0.5}, 1e-9) && !vec3Equal(support, mgl64.Vec3{1, 0.5, -0.5}, 1e-9) {
+		t.Errorf("support = %v, want a top corner of the right cube", support)
+	}
+	if radius := compound.BoundingRadius(); math.Abs(radius-(0.5+math.Sqrt(0.75))) > 1e-9 {
+		t.Errorf("BoundingRadius = %v, want %v", radius, 0.5+math.Sqrt(0.75))
+	}
+}
+
+func TestCompound_CollideWithPlane(t *testing.T) {
+	compound := twoCubes(t, mgl64.Vec3{})
+	transform := Transform{Position: mgl64.Vec3{0, 0.4, 0}, Rotation: mgl64.QuatIdent()}
+
+	collision, points := compound.CollideWithPlane(mgl64.Vec3{0, 1, 0}, 0, transform)
+	if !collision {
+		t.Fatal("expected a collision")
+	}
+	// 8 bottom corners reduced to at most 4, spanning both cubes
+	if len(points) < 2 || len(points) > 4 {
+		t.Fatalf("points = %d, want 2 to 4", len(points))
+	}
+	minX, maxX := math.Inf(1), math.Inf(-1)
+	for _, point := range points {
+		if math.Abs(point.Penetration-0.1) > 1e-9 {
+			t.Errorf("penetration = %v, want 0.1", point.Penetration)
+		}
+		minX, maxX = math.Min(minX, point.Position.X()), math.Max(maxX, point.Position.X())
+	}
+	if minX != -1 || maxX != 1 {
+		t.Errorf("points span [%v, %v], want [-1, 1]", minX, maxX)
+	}
+
+	if collision, _ := compound.CollideWithPlane(mgl64.Vec3{0, 1, 0}, 0, Transform{Position: mgl64.Vec3{0, 1, 0}, Rotation: mgl64.QuatIdent()}); collision {
+		t.Error("expected no collision above the plane")
+	}
+}
+
+func TestCompound_ClosestPoint(t *testing.T) {
+	compound := twoCubes(t, mgl64.Vec3{})
+
+	if point := (mgl64.Vec3{0.7, 0.2, 0}); compound.ClosestPoint(point) != point {
+		t.Errorf("ClosestPoint(%v) = %v, want the point inside unchanged", point, compound.ClosestPoint(point))
+	}
+	if closest := compound.ClosestPoint(mgl64.Vec3{3, 0, 0}); !vec3Equal(closest, mgl64.Vec3{1, 0, 0}, 1e-9) {
+		t.Errorf("ClosestPoint = %v, want {1, 0, 0}", closest)
+	}
+}
+
+func TestCompound_ContainsPoint(t *testing.T) {
+	compound := twoCubes(t, mgl64.Vec3{})
+
+	if !compound.ContainsPoint(mgl64.Vec3{0.7, 0.2, 0}) {
+		t.Error("expected the point inside a child to be contained")
+	}
+	if compound.ContainsPoint(mgl64.Vec3{3, 0, 0}) {
+		t.Error("expected the point beside the children not to be contained")
+	}
+}
+
+func TestCompound_Raycast(t *testing.T) {
+	compound := twoCubes(t, mgl64.Vec3{})
+
+	// Down into the right cube
+	distance, normal, hit := compound.Raycast(mgl64.Vec3{0.7, 3, 0}, mgl64.Vec3{0, -1, 0}, 10)
+	if !hit || math.Abs(distance-2.5) > 1e-9 || !vec3Equal(normal, mgl64.Vec3{0, 1, 0}, 1e-9) {
+		t.Errorf("hit %v at %v with normal %v, want the top of the right cube at 2.5", hit, distance, normal)
+	}
+	// Along X, the left cube is entered first
+	if distance, _, hit := compound.Raycast(mgl64.Vec3{-4, 0, 0}, mgl64.Vec3{1, 0, 0}, 10); !hit || math.Abs(distance-3) > 1e-9 {
+		t.Errorf("hit %v at %v, want the left cube at 3", hit, distance)
+	}
+	if _, _, hit := compound.Raycast(mgl64.Vec3{0, 3, 2}, mgl64.Vec3{0, -1, 0}, 10); hit {
+		t.Error("expected the ray beside the cubes to miss")
+	}
+}
@@ -0,0 +1,577 @@
+package actor
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// hillClimbingThreshold is the vertex count above which Support walks the vertex adjacency
+// instead of testing every vertex
+const hillClimbingThreshold = 32
+
+// maxFeatureVertices is the number of vertices returned by GetContactFeature for the large faces:
+// clipping two features of 4 points stays within the 8 points of the manifold clipping buffers
+const maxFeatureVertices = 4
+
+// ErrDegenerateHull is returned when the points of a convex hull are coplanar, colinear or merged
+var ErrDegenerateHull = errors.New("actor: convex hull requires at least 4 non-coplanar points")
+
+// ConvexHull represents a convex polyhedron collision shape, built from an arbitrary point set.
+// The vertices are translated so the local origin is the center of mass.
+type ConvexHull struct {
+	// Vertices of the hull in local space, relative to the center of mass
+	Vertices []mgl64.Vec3
+	// CenterOfMass is the translation removed from the input points:
+	// the input point p is at p - CenterOfMass in local space
+	CenterOfMass mgl64.Vec3
+
+	faces       []hullFace
+	triangles   [][3]int
+	neighbors   [][]int
+	volume      float64
+	unitInertia mgl64.Mat3 // Inertia tensor for a unit density
+	aabb        AABB
+	collisionMargin
+}
+
+// hullFace is a polygon of the hull, merged from its coplanar triangles
+type hullFace struct {
+	normal   mgl64.Vec3
+	offset   float64 // normal · p for any point p of the face
+	vertices []int   // Counter-clockwise around the normal
+}
+
+// hullTriangle is a triangle of the hull under construction, counter-clockwise seen from outside
+type hullTriangle struct {
+	a, b, c int
+	normal  mgl64.Vec3
+	offset  float64
+}
+
+// NewConvexHull computes the convex hull of the points: the points inside the hull are discarded,
+// the faces, the vertex adjacency and the mass properties are precomputed.
+// Returns ErrDegenerateHull if the points do not enclose a volume.
+func NewConvexHull(points []mgl64.Vec3) (*ConvexHull, error) {
+	triangles, err := buildHullTriangles(points)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the vertices used by the triangles only
+	remap := make(map[int]int)
+	hull := &ConvexHull{}
+	for _, triangle := range triangles {
+		for _, index := range [3]int{triangle.a, triangle.b, triangle.c} {
+			if _, ok := remap[index]; !ok {
+				remap[index] = len(hull.Vertices)
+				hull.Vertices = append(hull.Vertices, points[index])
+			}
+		}
+		hull.triangles = append(hull.triangles, [3]int{remap[triangle.a], remap[triangle.b], remap[triangle.c]})
+	}
+
+	hull.computeMassProperties()
+	for i := range hull.Vertices {
+		hull.Vertices[i] = hull.Vertices[i].Sub(hull.CenterOfMass)
+	}
+	hull.buildFaces()
+	hull.buildNeighbors()
+
+	return hull, nil
+}
+
+// buildHullTriangles runs an incremental hull construction: each point outside the current hull
+// replaces the faces it sees by a fan connecting it to their horizon.
+func buildHullTriangles(points []mgl64.Vec3) ([]hullTriangle, error) {
+	if len(points) < 4 {
+		return nil, ErrDegenerateHull
+	}
+
+	// Tolerance relative to the size of the point set
+	min, max := points[0], points[0]
+	for _, p := range points[1:] {
+		for i := 0; i < 3; i++ {
+			min[i] = math.Min(min[i], p[i])
+			max[i] = math.Max(max[i], p[i])
+		}
+	}
+	epsilon := 1e-9 * max.Sub(min).Len()
+	if epsilon == 0 {
+		return nil, ErrDegenerateHull
+	}
+
+	initial, ok := initialTetrahedron(points, epsilon)
+	if !ok {
+		return nil, ErrDegenerateHull
+	}
+
+	interior := points[initial[0]].Add(points[initial[1]]).Add(points[initial[2]]).Add(points[initial[3]]).Mul(0.25)
+	newTriangle := func(a, b, c int) hullTriangle {
+		normal := points[b].Sub(points[a]).Cross(points[c].Sub(points[a])).Normalize()
+		if normal.Dot(interior.Sub(points[a])) > 0 {
+			b, c = c, b
+			normal = normal.Mul(-1)
+		}
+
+		return hullTriangle{a: a, b: b, c: c, normal: normal, offset: normal.Dot(points[a])}
+	}
+
+	triangles := []hullTriangle{
+		newTriangle(initial[0], initial[1], initial[2]),
+		newTriangle(initial[0], initial[1], initial[3]),
+		newTriangle(initial[0], initial[2], initial[3]),
+		newTriangle(initial[1], initial[2], initial[3]),
+	}
+
+	for i, p := range points {
+		if i == initial[0] || i == initial[1] || i == initial[2] || i == initial[3] {
+			continue
+		}
+
+		// Directed edges of the faces visible from the point
+		edges := make(map[[2]int]bool)
+		kept := triangles[:0:0]
+		for _, triangle := range triangles {
+			if triangle.normal.Dot(p)-triangle.offset > epsilon {
+				edges[[2]int{triangle.a, triangle.b}] = true
+				edges[[2]int{triangle.b, triangle.c}] = true
+				edges[[2]int{triangle.c, triangle.a}] = true
+			} else {
+				kept = append(kept, triangle)
+			}
+		}
+		if len(edges) == 0 {
+			continue
+		}
+
+		// The horizon edges belong to a single visible face, the fan keeps their orientation
+		triangles = kept
+		for edge := range edges {
+			if !edges[[2]int{edge[1], edge[0]}] {
+				normal := points[edge[1]].Sub(points[edge[0]]).Cross(p.Sub(points[edge[0]])).Normalize()
+				triangles = append(triangles, hullTriangle{a: edge[0], b: edge[1], c: i, normal: normal, offset: normal.Dot(p)})
+			}
+		}
+	}
+
+	// Deterministic order, the horizon was walked through a map
+	sort.Slice(triangles, func(i, j int) bool {
+		a, b := triangles[i], triangles[j]
+		if a.a != b.a {
+			return a.a < b.a
+		}
+		if a.b != b.b {
+			return a.b < b.b
+		}
+		return a.c < b.c
+	})
+
+	return triangles, nil
+}
+
+// initialTetrahedron returns 4 points enclosing a volume: the extremes along the widest axis,
+// the farthest point from their line, and the farthest point from their plane
+func initialTetrahedron(points []mgl64.Vec3, epsilon float64) ([4]int, bool) {
+	var result [4]int
+
+	bestSpread := -1.0
+	for axis := 0; axis < 3; axis++ {
+		minIndex, maxIndex := 0, 0
+		for i, p := range points {
+			if p[axis] < points[minIndex][axis] {
+				minIndex = i
+			}
+			if p[axis] > points[maxIndex][axis] {
+				maxIndex = i
+			}
+		}
+		if spread := points[maxIndex][axis] - points[minIndex][axis]; spread > bestSpread {
+			bestSpread = spread
+			result[0], result[1] = minIndex, maxIndex
+		}
+	}
+	if bestSpread <= epsilon {
+		return result, false
+	}
+
+	a, b := points[result[0]], points[result[1]]
+	direction := b.Sub(a).Normalize()
+	bestDistance := 0.0
+	for i, p := range points {
+		if distance := p.Sub(a).Cross(direction).Len(); distance > bestDistance {
+			bestDistance = distance
+			result[2] = i
+		}
+	}
+	if bestDistance <= epsilon {
+		return result, false
+	}
+
+	normal := b.Sub(a).Cross(points[result[2]].Sub(a)).Normalize()
+	bestDistance = 0.0
+	for i, p := range points {
+		if distance := math.Abs(p.Sub(a).Dot(normal)); distance > bestDistance {
+			bestDistance = distance
+			result[3] = i
+		}
+	}
+	if bestDistance <= epsilon {
+		return result, false
+	}
+
+	return result, true
+}
+
+// computeMassProperties sums the tetrahedra made of each triangle and an interior point:
+// volume, center of mass, and the inertia tensor for a unit density from the covariance matrices
+// (J. Blow, A. Binstock, "How to find the inertia tensor (or other mass properties) of a 3D solid body", 2004)
+func (h *ConvexHull) computeMassProperties() {
+	canonical := mgl64.Mat3{
+		2, 1, 1,
+		1, 2, 1,
+		1, 1, 2,
+	}.Mul(1.0 / 120.0)
+
+	var origin mgl64.Vec3
+	for _, v := range h.Vertices {
+		origin = origin.Add(v)
+	}
+	origin = origin.Mul(1.0 / float64(len(h.Vertices)))
+
+	var volume float64
+	var weightedCenter mgl64.Vec3
+	var covariance mgl64.Mat3
+	for _, triangle := range h.triangles {
+		a := h.Vertices[triangle[0]].Sub(origin)
+		b := h.Vertices[triangle[1]].Sub(origin)
+		c := h.Vertices[triangle[2]].Sub(origin)
+
+		determinant := a.Dot(b.Cross(c))
+		volume += determinant / 6.0
+		weightedCenter = weightedCenter.Add(a.Add(b).Add(c).Mul(determinant / 24.0))
+
+		columns := mgl64.Mat3FromCols(a, b, c)
+		covariance = covariance.Add(columns.Mul3(canonical).Mul3(columns.Transpose()).Mul(determinant))
+	}
+
+	center := weightedCenter.Mul(1.0 / volume)
+	h.volume = volume
+	h.CenterOfMass = origin.Add(center)
+
+	// Parallel axis theorem on the covariance, from the interior point to the center of mass
+	covariance = covariance.Sub(center.OuterProd3(center).Mul(volume))
+	trace := covariance.At(0, 0) + covariance.At(1, 1) + covariance.At(2, 2)
+	h.unitInertia = mgl64.Ident3().Mul(trace).Sub(covariance)
+}
+
+// buildFaces merges the coplanar triangles into polygons
+func (h *ConvexHull) buildFaces() {
+	const coplanarTolerance = 1e-9
+
+	scale := h.BoundingRadius()
+	for _, triangle := range h.triangles {
+		a, b, c := h.Vertices[triangle[0]], h.Vertices[triangle[1]], h.Vertices[triangle[2]]
+		normal := b.Sub(a).Cross(c.Sub(a)).Normalize()
+		offset := normal.Dot(a)
+
+		var face *hullFace
+		for i := range h.faces {
+			if h.faces[i].normal.Dot(normal) > 1-coplanarTolerance && math.Abs(h.faces[i].offset-offset) <= coplanarTolerance*scale {
+				face = &h.faces[i]
+				break
+			}
+		}
+		if face == nil {
+			h.faces = append(h.faces, hullFace{normal: normal, offset: offset})
+			face = &h.faces[len(h.faces)-1]
+		}
+
+		for _, index := range triangle {
+			found := false
+			for _, existing := range face.vertices {
+				if existing == index {
+					found = true
+					break
+				}
+			}
+			if !found {
+				face.vertices = append(face.vertices, index)
+			}
+		}
+	}
+
+	// Sort the polygons counter-clockwise around their normal
+	for i := range h.faces {
+		face := &h.faces[i]
+		tangent1, tangent2 := getTangentBasis(face.normal)
+
+		var center mgl64.Vec3
+		for _, index := range face.vertices {
+			center = center.Add(h.Vertices[index])
+		}
+		center = center.Mul(1.0 / float64(len(face.vertices)))
+
+		angles := make(map[int]float64, len(face.vertices))
+		for _, index := range face.vertices {
+			offset := h.Vertices[index].Sub(center)
+			angles[index] = math.Atan2(offset.Dot(tangent2), offset.Dot(tangent1))
+		}
+		sort.Slice(face.vertices, func(a, b int) bool {
+			return angles[face.vertices[a]] < angles[face.vertices[b]]
+		})
+	}
+}
+
+// buildNeighbors records the vertices connected by an edge, for the hill climbing support
+func (h *ConvexHull) buildNeighbors() {
+	h.neighbors = make([][]int, len(h.Vertices))
+
+	connect := func(a, b int) {
+		for _, neighbor := range h.neighbors[a] {
+			if neighbor == b {
+				return
+			}
+		}
+		h.neighbors[a] = append(h.neighbors[a], b)
+		h.neighbors[b] = append(h.neighbors[b], a)
+	}
+
+	for _, triangle := range h.triangles {
+		connect(triangle[0], triangle[1])
+		connect(triangle[1], triangle[2])
+		connect(triangle[2], triangle[0])
+	}
+}
+
+func (h *ConvexHull) ComputeAABB(transform Transform) {
+	min := transform.Rotation.Rotate(h.Vertices[0])
+	max := min
+
+	for _, vertex := range h.Vertices[1:] {
+		worldVertex := transform.Rotation.Rotate(vertex)
+		for i := 0; i < 3; i++ {
+			min[i] = math.Min(min[i], worldVertex[i])
+			max[i] = math.Max(max[i], worldVertex[i])
+		}
+	}
+
+	h.aabb = AABB{Min: min.Add(transform.Position), Max: max.Add(transform.Position)}
+}
+
+func (h *ConvexHull) GetAABB() AABB {
+	return h.aabb
+}
+
+// ComputeMass calculates mass data for the hull
+func (h *ConvexHull) ComputeMass(density float64) float64 {
+	return density * h.volume
+}
+
+func (h *ConvexHull) ComputeInertia(mass float64) mgl64.Mat3 {
+	return h.unitInertia.Mul(mass / h.volume)
+}
+
+// Support tests every vertex of small hulls. Large hulls climb the vertex adjacency
+// towards the direction: on a convex polyhedron, the first local maximum is the support point.
+func (h *ConvexHull) Support(direction mgl64.Vec3) mgl64.Vec3 {
+	best := 0
+	bestDot := h.Vertices[0].Dot(direction)
+
+	if len(h.Vertices) <= hillClimbingThreshold {
+		for i, vertex := range h.Vertices[1:] {
+			if dot := vertex.Dot(direction); dot > bestDot {
+				best, bestDot = i+1, dot
+			}
+		}
+
+		return h.Vertices[best]
+	}
+
+	for improved := true; improved; {
+		improved = false
+		for _, neighbor := range h.neighbors[best] {
+			if dot := h.Vertices[neighbor].Dot(direction); dot > bestDot {
+				best, bestDot = neighbor, dot
+				improved = true
+			}
+		}
+	}
+
+	return h.Vertices[best]
+}
+
+// GetContactFeature returns the face the most aligned with the direction.
+// Faces with more than maxFeatureVertices vertices are sampled evenly.
+func (h *ConvexHull) GetContactFeature(direction mgl64.Vec3, output *[8]mgl64.Vec3, count *int) {
+	vertices := h.faces[h.FeatureIndex(direction)].vertices
+	*count = min(len(vertices), maxFeatureVertices)
+	for i := 0; i < *count; i++ {
+		output[i] = h.Vertices[vertices[i*len(vertices) / *count]]
+	}
+}
+
+// FeatureIndex returns the index of the face the most aligned with the direction
+func (h *ConvexHull) FeatureIndex(direction mgl64.Vec3) int {
+	best := 0
+	bestDot := math.Inf(-1)
+	for i, face := range h.faces {
+		if dot := face.normal.Dot(direction); dot > bestDot {
+			best, bestDot = i, dot
+		}
+	}
+
+	return best
+}
+
+// CollideWithPlane - Collision ConvexHull/Plane
+func (h *ConvexHull) CollideWithPlane(planeNormal mgl64.Vec3, planeDistance float64, myTransform Transform) (bool, PlaneContact) {
+	var contactPoints []ContactPoint
+
+	for _, vertex := range h.Vertices {
+		worldVertex := myTransform.Rotation.Rotate(vertex).Add(myTransform.Position)
+		distance := worldVertex.Sub(planeNormal.Mul(-planeDistance)).Dot(planeNormal)
+
+		if distance < 0 {
+			contactPoints = append(contactPoints, ContactPoint{
+				Position:    worldVertex.Sub(planeNormal.Mul(distance)),
+				Penetration: -distance,
+			})
+		}
+	}
+
+	if len(contactPoints) == 0 {
+		return false, PlaneContact{}
+	}
+
+	if len(contactPoints) > 4 {
+		contactPoints = reduceTo4ContactPoints(contactPoints, planeNormal)
+	}
+
+	return true, contactPoints
+}
+
+// ClosestPoint returns the point unchanged if it is behind every face,
+// otherwise the closest point of the triangles of the hull
+func (h *ConvexHull) ClosestPoint(point mgl64.Vec3) mgl64.Vec3 {
+	if h.ContainsPoint(point) {
+		return point
+	}
+
+	var closest mgl64.Vec3
+	closestDistSq := math.Inf(1)
+	for _, triangle := range h.triangles {
+		candidate := closestPointOnTriangle(point, h.Vertices[triangle[0]], h.Vertices[triangle[1]], h.Vertices[triangle[2]])
+		if distSq := candidate.Sub(point).LenSqr(); distSq < closestDistSq {
+			closest, closestDistSq = candidate, distSq
+		}
+	}
+
+	return closest
+}
+
+// ContainsPoint returns true if the point lies behind every face of the hull
+func (h *ConvexHull) ContainsPoint(point mgl64.Vec3) bool {
+	for _, face := range h.faces {
+		if face.normal.Dot(point)-face.offset > 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// BoundingRadius returns the distance from the center of mass to the farthest vertex
+func (h *ConvexHull) BoundingRadius() float64 {
+	radius := 0.0
+	for _, vertex := range h.Vertices {
+		radius = math.Max(radius, vertex.Len())
+	}
+
+	return radius
+}
+
+// Raycast clips the ray against the planes of the faces, the normal is the face entered last
+func (h *ConvexHull) Raycast(origin, direction mgl64.Vec3, maxDist float64) (float64, mgl64.Vec3, bool) {
+	enter, exit := 0.0, maxDist
+	var normal mgl64.Vec3
+	entered := false
+
+	for _, face := range h.faces {
+		distance := face.normal.Dot(origin) - face.offset
+		approach := face.normal.Dot(direction)
+		if math.Abs(approach) < 1e-12 {
+			if distance > 0 {
+				return 0, mgl64.Vec3{}, false
+			}
+			continue
+		}
+
+		t := -distance / approach
+		if approach < 0 {
+			if t > enter {
+				enter, normal, entered = t, face.normal, true
+			}
+		} else {
+			exit = math.Min(exit, t)
+		}
+		if enter > exit {
+			return 0, mgl64.Vec3{}, false
+		}
+	}
+
+	if !entered {
+		// Behind every face: the origin is inside
+		return 0, direction.Mul(-1), true
+	}
+
+	return enter, normal, true
+}
+
+// closestPointOnTriangle returns the point of the triangle abc closest to p
+// (C. Ericson, "Real-Time Collision Detection", 2004, section 5.1.5)
+func closestPointOnTriangle(p, a, b, c mgl64.Vec3) mgl64.Vec3 {
+	ab := b.Sub(a)
+	ac := c.Sub(a)
+	ap := p.Sub(a)
+	d1 := ab.Dot(ap)
+	d2 := ac.Dot(ap)
+	if d1 <= 0 && d2 <= 0 {
+		return a
+	}
+
+	bp := p.Sub(b)
+	d3 := ab.Dot(bp)
+	d4 := ac.Dot(bp)
+	if d3 >= 0 && d4 <= d3 {
+		return b
+	}
+
+	vc := d1*d4 - d3*d2
+	if vc <= 0 && d1 >= 0 && d3 <= 0 {
+		return a.Add(ab.Mul(d1 / (d1 - d3)))
+	}
+
+	cp := p.Sub(c)
+	d5 := ab.Dot(cp)
+	d6 := ac.Dot(cp)
+	if d6 >= 0 && d5 <= d6 {
+		return c
+	}
+
+	vb := d5*d2 - d1*d6
+	if vb <= 0 && d2 >= 0 && d6 <= 0 {
+		return a.Add(ac.Mul(d2 / (d2 - d6)))
+	}
+
+	va := d3*d6 - d5*d4
+	if va <= 0 && (d4-d3) >= 0 && (d5-d6) >= 0 {
+		return b.Add(c.Sub(b).Mul((d4 - d3) / ((d4 - d3) + (d5 - d6))))
+	}
+
+	denominator := 1.0 / (va + vb + vc)
+
+	return a.Add(ab.Mul(vb * denominator)).Add(ac.Mul(vc * denominator))
+}
@@ -0,0 +1,289 @@
+package actor
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// cubePoints returns the 8 corners of a cube of half extent h, centered on center
+func cubePoints(h float64, center mgl64.Vec3) []mgl64.Vec3 {
+	var points []mgl64.Vec3
+	for _, x := range []float64{-h, h} {
+		for _, y := range []float64{-h, h} {
+			for _, z := range []float64{-h, h} {
+				points = append(points, center.Add(mgl64.Vec3{x, y, z}))
+			}
+		}
+	}
+
+	return points
+}
+
+// spherePoints returns n points on a sphere, spread with a golden angle spiral
+func spherePoints(n int, radius float64) []mgl64.Vec3 {
+	points := make([]mgl64.Vec3, n)
+	goldenAngle := math.Pi * (3 - math.Sqrt(5))
+	for i := range points {
+		y := 1 - 2*(float64(i)+0.5)/float64(n)
+		r := math.Sqrt(1 - y*y)
+		theta := goldenAngle * float64(i)
+		points[i] = mgl64.Vec3{r * math.Cos(theta), y, r * math.Sin(theta)}.Mul(radius)
+	}
+
+	return points
+}
+
+func TestNewConvexHull_Cube(t *testing.T) {
+	center := mgl64.Vec3{5, -2, 1}
+	points := cubePoints(1, center)
+	// Interior points are discarded
+	points = append(points, center, center.Add(mgl64.Vec3{0.5, 0.2, -0.3}))
+
+	hull, err := NewConvexHull(points)
+	if err != nil {
+		t.Fatalf("NewConvexHull failed: %v", err)
+	}
+
+	if len(hull.Vertices) != 8 {
+		t.Errorf("vertices = %d, want 8", len(hull.Vertices))
+	}
+	if len(hull.faces) != 6 {
+		t.Errorf("faces = %d, want 6", len(hull.faces))
+	}
+	if !vec3Equal(hull.CenterOfMass, center, 1e-9) {
+		t.Errorf("CenterOfMass = %v, want %v", hull.CenterOfMass, center)
+	}
+	for _, vertex := range hull.Vertices {
+		if !vec3Equal(mgl64.Vec3{math.Abs(vertex.X()), math.Abs(vertex.Y()), math.Abs(vertex.Z())}, mgl64.Vec3{1, 1, 1}, 1e-9) {
+			t.Errorf("vertex %v is not a corner of the centered cube", vertex)
+		}
+	}
+
+	if mass := hull.ComputeMass(2); !floatEqual(mass, 16, 1e-9) {
+		t.Errorf("mass = %v, want 16", mass)
+	}
+
+	// Same inertia as a Box of the same size
+	box := &Box{HalfExtents: mgl64.Vec3{1, 1, 1}}
+	if inertia := hull.ComputeInertia(8); !mat3Equal(inertia, box.ComputeInertia(8), 1e-9) {
+		t.Errorf("inertia = %v, want %v", inertia, box.ComputeInertia(8))
+	}
+}
+
+func TestNewConvexHull_Tetrahedron(t *testing.T) {
+	hull, err := NewConvexHull([]mgl64.Vec3{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {0, 0, 1}})
+	if err != nil {
+		t.Fatalf("NewConvexHull failed: %v", err)
+	}
+
+	if mass := hull.ComputeMass(1); !floatEqual(mass, 1.0/6.0, 1e-12) {
+		t.Errorf("volume = %v, want 1/6", mass)
+	}
+	if !vec3Equal(hull.CenterOfMass, mgl64.Vec3{0.25, 0.25, 0.25}, 1e-12) {
+		t.Errorf("CenterOfMass = %v, want (1/4, 1/4, 1/4)", hull.CenterOfMass)
+	}
+
+	// Covariance of the unit right tetrahedron: 1/60 on the diagonal, 1/120 elsewhere,
+	// shifted to the center of mass by the volume times (1/4)²
+	covarianceDiagonal := 1.0/60 - (1.0/6)/16
+	covarianceProduct := 1.0/120 - (1.0/6)/16
+	diagonal := 2 * covarianceDiagonal
+	product := -covarianceProduct
+	expected := mgl64.Mat3{
+		diagonal, product, product,
+		product, diagonal, product,
+		product, product, diagonal,
+	}
+	if inertia := hull.ComputeInertia(1.0 / 6.0); !mat3Equal(inertia, expected, 1e-12) {
+		t.Errorf("inertia = %v, want %v", inertia, expected)
+	}
+}
+
+func TestNewConvexHull_Degenerate(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []mgl64.Vec3
+	}{
+		{"empty", nil},
+		{"too few points", []mgl64.Vec3{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}},
+		{"merged points", []mgl64.Vec3{{1, 1, 1}, {1, 1, 1}, {1, 1, 1}, {1, 1, 1}}},
+		{"colinear", []mgl64.Vec3{{0, 0, 0}, {1, 0, 0}, {2, 0, 0}, {3, 0, 0}}},
+		{"coplanar", []mgl64.Vec3{{0, 0, 0}, {1, 0, 0}, {0, 0, 1}, {1, 0, 1}, {0.5, 0, 0.5}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewConvexHull(tt.points); !errors.Is(err, ErrDegenerateHull) {
+				t.Errorf("err = %v, want ErrDegenerateHull", err)
+			}
+		})
+	}
+}
+
+func TestConvexHullSupport_HillClimbing(t *testing.T) {
+	hull, err := NewConvexHull(spherePoints(500, 2))
+	if err != nil {
+		t.Fatalf("NewConvexHull failed: %v", err)
+	}
+	if len(hull.Vertices) <= hillClimbingThreshold {
+		t.Fatalf("vertices = %d, want more than %d to test the hill climbing", len(hull.Vertices), hillClimbingThreshold)
+	}
+
+	for _, direction := range spherePoints(200, 1) {
+		bestDot := math.Inf(-1)
+		for _, vertex := range hull.Vertices {
+			bestDot = math.Max(bestDot, vertex.Dot(direction))
+		}
+
+		if dot := hull.Support(direction).Dot(direction); !floatEqual(dot, bestDot, 1e-12) {
+			t.Errorf("Support(%v) reaches %v, brute force reaches %v", direction, dot, bestDot)
+		}
+	}
+}
+
+func TestConvexHullGetContactFeature(t *testing.T) {
+	hull, err := NewConvexHull(cubePoints(1, mgl64.Vec3{}))
+	if err != nil {
+		t.Fatalf("NewConvexHull failed: %v", err)
+	}
+	var output [8]mgl64.Vec3
+	var count int
+
+	hull.GetContactFeature(mgl64.Vec3{0.1, -1, 0.2}, &output, &count)
+	if count != 4 {
+		t.Fatalf("feature count = %d, want 4", count)
+	}
+	for _, point := range output[:count] {
+		if !floatEqual(point.Y(), -1, 1e-9) {
+			t.Errorf("point %v is not on the bottom face", point)
+		}
+	}
+
+	// Counter-clockwise around the face normal
+	normal := output[1].Sub(output[0]).Cross(output[2].Sub(output[0]))
+	if normal.Y() >= 0 {
+		t.Errorf("face winding gives normal %v, want -Y", normal)
+	}
+
+	// Large faces are sampled down to 4 points
+	prism, err := NewConvexHull(append(regularPolygon(16, 0), regularPolygon(16, 1)...))
+	if err != nil {
+		t.Fatalf("NewConvexHull failed: %v", err)
+	}
+	prism.GetContactFeature(mgl64.Vec3{0, 1, 0}, &output, &count)
+	if count != maxFeatureVertices {
+		t.Errorf("feature count = %d, want %d", count, maxFeatureVertices)
+	}
+}
+
+// regularPolygon returns n points on a unit circle at the height y
+func regularPolygon(n int, y float64) []mgl64.Vec3 {
+	points := make([]mgl64.Vec3, n)
+	for i := range points {
+		angle := 2 * math.Pi * float64(i) / float64(n)
+		points[i] = mgl64.Vec3{math.Cos(angle), y, math.Sin(angle)}
+	}
+
+	return points
+}
+
+func TestConvexHullCollideWithPlane(t *testing.T) {
+	hull, err := NewConvexHull(cubePoints(1, mgl64.Vec3{}))
+	if err != nil {
+		t.Fatalf("NewConvexHull failed: %v", err)
+	}
+
+	collision, contacts := hull.CollideWithPlane(mgl64.Vec3{0, 1, 0}, 0, Transform{Position: mgl64.Vec3{0, 0.9, 0}, Rotation: mgl64.QuatIdent()})
+	if !collision || len(contacts) != 4 {
+		t.Fatalf("collision = %v, %d contacts, want 4", collision, len(contacts))
+	}
+	for _, contact := range contacts {
+		if !floatEqual(contact.Penetration, 0.1, 1e-9) {
+			t.Errorf("penetration = %v, want 0.1", contact.Penetration)
+		}
+	}
+
+	if collision, _ := hull.CollideWithPlane(mgl64.Vec3{0, 1, 0}, 0, Transform{Position: mgl64.Vec3{0, 2, 0}, Rotation: mgl64.QuatIdent()}); collision {
+		t.Error("expected no collision above the plane")
+	}
+}
+
+func TestConvexHullContainsPoint(t *testing.T) {
+	hull, err := NewConvexHull([]mgl64.Vec3{{0, 0, 0}, {2, 0, 0}, {0, 2, 0}, {0, 0, 2}})
+	if err != nil {
+		t.Fatalf("NewConvexHull failed: %v", err)
+	}
+
+	// The vertices are centered on the center of mass (0.5, 0.5, 0.5)
+	if !hull.ContainsPoint(mgl64.Vec3{}) {
+		t.Error("expected the center of mass to be contained")
+	}
+	if !hull.ContainsPoint(mgl64.Vec3{-0.4, -0.4, -0.4}) {
+		t.Error("expected the point near the right angle corner to be contained")
+	}
+	if hull.ContainsPoint(mgl64.Vec3{0.5, 0.5, 0.5}) {
+		t.Error("expected the point beyond the slanted face not to be contained")
+	}
+}
+
+func TestConvexHullClosestPointAndBounds(t *testing.T) {
+	hull, err := NewConvexHull(cubePoints(1, mgl64.Vec3{}))
+	if err != nil {
+		t.Fatalf("NewConvexHull failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		point    mgl64.Vec3
+		expected mgl64.Vec3
+	}{
+		{"inside", mgl64.Vec3{0.2, -0.5, 0.1}, mgl64.Vec3{0.2, -0.5, 0.1}},
+		{"facing a face", mgl64.Vec3{3, 0.5, 0}, mgl64.Vec3{1, 0.5, 0}},
+		{"facing an edge", mgl64.Vec3{3, 3, 0}, mgl64.Vec3{1, 1, 0}},
+		{"facing a corner", mgl64.Vec3{-3, 3, 3}, mgl64.Vec3{-1, 1, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if closest := hull.ClosestPoint(tt.point); !vec3Equal(closest, tt.expected, 1e-9) {
+				t.Errorf("ClosestPoint(%v) = %v, want %v", tt.point, closest, tt.expected)
+			}
+		})
+	}
+
+	if radius := hull.BoundingRadius(); !floatEqual(radius, math.Sqrt(3), 1e-9) {
+		t.Errorf("BoundingRadius = %v, want sqrt(3)", radius)
+	}
+
+	hull.ComputeAABB(Transform{Position: mgl64.Vec3{10, 0, 0}, Rotation: mgl64.QuatRotate(math.Pi/4, mgl64.Vec3{0, 1, 0})})
+	aabb := hull.GetAABB()
+	if !vec3Equal(aabb.Min, mgl64.Vec3{10 - math.Sqrt2, -1, -math.Sqrt2}, 1e-9) || !vec3Equal(aabb.Max, mgl64.Vec3{10 + math.Sqrt2, 1, math.Sqrt2}, 1e-9) {
+		t.Errorf("AABB = %v", aabb)
+	}
+}
+
+// TestConvexHullRaycast compares a cube hull to the equivalent box
+func TestConvexHullRaycast(t *testing.T) {
+	hull, err := NewConvexHull(cubePoints(1, mgl64.Vec3{}))
+	if err != nil {
+		t.Fatalf("NewConvexHull failed: %v", err)
+	}
+	box := &Box{HalfExtents: mgl64.Vec3{1, 1, 1}}
+
+	origins := []mgl64.Vec3{{3, 0.2, -0.1}, {0.5, 4, 0.5}, {-2, -2, -2}, {0.2, 0.3, 0.1}, {3, 3, 0}}
+	for _, origin := range origins {
+		direction := origin.Mul(-1).Add(mgl64.Vec3{0.1, 0, 0.05}).Normalize()
+		hullDistance, hullNormal, hullHit := hull.Raycast(origin, direction, 10)
+		boxDistance, boxNormal, boxHit := box.Raycast(origin, direction, 10)
+
+		if hullHit != boxHit || math.Abs(hullDistance-boxDistance) > 1e-9 || !vec3Equal(hullNormal, boxNormal, 1e-9) {
+			t.Errorf("from %v: hull hit %v at %v %v, box hit %v at %v %v", origin, hullHit, hullDistance, hullNormal, boxHit, boxDistance, boxNormal)
+		}
+	}
+
+	if _, _, hit := hull.Raycast(mgl64.Vec3{3, 0, 0}, mgl64.Vec3{0, 1, 0}, 10); hit {
+		t.Error("expected the ray passing beside the hull to miss")
+	}
+}
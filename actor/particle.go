@@ -0,0 +1,58 @@
+package actor
+
+import (
+	"github.com/akmonengine/feather/dmath"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// Particle is a point mass without rotation nor shape (3 degrees of freedom), e.g. a vertex of a cloth or of a rope.
+// It does not collide, it is coupled to the rigid bodies by constraints (see constraint.ParticleAttachment).
+// Like the bodies, it is integrated at each substep and its velocity is derived from the corrected positions.
+type Particle struct {
+	Position         mgl64.Vec3
+	PreviousPosition mgl64.Vec3
+	Velocity         mgl64.Vec3
+	// Mass in kg, 0 pins the particle: it is not integrated nor moved by the constraints, the user moves it
+	Mass float64
+	// LinearDamping slows the particle down exponentially, like Material.LinearDamping
+	LinearDamping float64
+}
+
+// NewParticle creates a particle at rest, a mass of 0 pins it
+func NewParticle(position mgl64.Vec3, mass float64) *Particle {
+	return &Particle{
+		Position:         position,
+		PreviousPosition: position,
+		Mass:             mass,
+	}
+}
+
+// InverseMass returns 1/Mass, 0 for a pinned particle
+func (p *Particle) InverseMass() float64 {
+	if p.Mass <= 0 {
+		return 0
+	}
+
+	return 1.0 / p.Mass
+}
+
+// Integrate predicts the position of the particle after dt under the acceleration
+func (p *Particle) Integrate(dt float64, acceleration mgl64.Vec3) {
+	p.PreviousPosition = p.Position
+	if p.Mass <= 0 {
+		return
+	}
+
+	p.Velocity = p.Velocity.Add(acceleration.Mul(dt))
+	p.Velocity = p.Velocity.Mul(dmath.Exp(-p.LinearDamping * dt))
+	p.Position = p.Position.Add(p.Velocity.Mul(dt))
+}
+
+// Update derives the velocity from the position corrected by the constraints
+func (p *Particle) Update(dt float64) {
+	if p.Mass <= 0 {
+		return
+	}
+
+	p.Velocity = p.Position.Sub(p.PreviousPosition).Mul(1.0 / dt)
+}
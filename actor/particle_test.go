@@ -0,0 +1,49 @@
+package actor
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestParticle_IntegrateUpdate(t *testing.T) {
+	p := NewParticle(mgl64.Vec3{0, 10, 0}, 1)
+	dt := 0.1
+
+	p.Integrate(dt, mgl64.Vec3{0, -10, 0})
+	if !p.Position.ApproxEqualThreshold(mgl64.Vec3{0, 9.9, 0}, 1e-12) {
+		t.Errorf("position = %v, want {0 9.9 0}", p.Position)
+	}
+
+	// A constraint pushes the particle back up: the velocity follows the correction
+	p.Position = mgl64.Vec3{0, 10, 0}
+	p.Update(dt)
+	if p.Velocity.Len() > 1e-12 {
+		t.Errorf("velocity = %v, want 0", p.Velocity)
+	}
+}
+
+func TestParticle_Damping(t *testing.T) {
+	p := NewParticle(mgl64.Vec3{}, 1)
+	p.Velocity = mgl64.Vec3{1, 0, 0}
+	p.LinearDamping = 2
+
+	p.Integrate(0.5, mgl64.Vec3{})
+	if math.Abs(p.Velocity.X()-math.Exp(-1)) > 1e-12 {
+		t.Errorf("velocity = %v, want %v", p.Velocity.X(), math.Exp(-1))
+	}
+}
+
+func TestParticle_Pinned(t *testing.T) {
+	p := NewParticle(mgl64.Vec3{1, 2, 3}, 0)
+	if p.InverseMass() != 0 {
+		t.Errorf("InverseMass = %v, want 0", p.InverseMass())
+	}
+
+	p.Integrate(0.1, mgl64.Vec3{0, -10, 0})
+	p.Update(0.1)
+	if p.Position != (mgl64.Vec3{1, 2, 3}) || p.Velocity != (mgl64.Vec3{}) {
+		t.Errorf("pinned particle moved: %v, velocity %v", p.Position, p.Velocity)
+	}
+}
@@ -1,9 +1,12 @@
 package actor
 
 import (
+	"errors"
+	"fmt"
 	"math"
 	"sync"
 
+	"github.com/akmonengine/feather/dmath"
 	"github.com/go-gl/mathgl/mgl64"
 )
 
@@ -20,15 +23,135 @@ const (
 	BodyTypeStatic
 )
 
+// Body flags
+//
+// The BodyType, IsTrigger and IsSleeping flags combine as follows:
+//
+//	BodyType  IsTrigger  IsSleeping  Behaviour
+//	Dynamic   false      false       simulated, generates contacts
+//	Dynamic   false      true        frozen until woken up by a contact, a force or WakeUp
+//	Dynamic   true       false       simulated, reports trigger events without contact response
+//	Dynamic   true       true        frozen trigger, events are not reported for pairs of inactive bodies
+//	Static    false      false       immovable, generates contacts
+//	Static    true       false       immovable trigger zone
+//	Static    any        true        invalid: static bodies never sleep
+//
+// A body is inactive when it is static, sleeping or deferred (see IsActive): pairs of inactive bodies
+// are skipped by the broad phase, the solver and the events.
+// The flags must be changed with SetBodyType, SetTrigger and SetSleeping, which validate the combination
+// and wake the body up so its pairs are evaluated again at the next step.
+var (
+	// ErrInvalidBodyType is returned for a BodyType other than BodyTypeDynamic and BodyTypeStatic
+	ErrInvalidBodyType = errors.New("actor: invalid body type")
+	// ErrStaticSleeping is returned when a static body is set to sleep
+	ErrStaticSleeping = errors.New("actor: static bodies cannot sleep")
+	// ErrDynamicWithoutDensity is returned when a body without density is made dynamic
+	ErrDynamicWithoutDensity = errors.New("actor: dynamic bodies require a positive density")
+	// ErrDynamicMesh is returned when a body with a TriangleMesh is made dynamic
+	ErrDynamicMesh = errors.New("actor: triangle meshes are static only")
+	// ErrInvalidMass is returned by SetMass for a negative, infinite or NaN mass
+	ErrInvalidMass = errors.New("actor: the mass must be positive and finite")
+	// ErrInvalidInertia is returned by SetInertia for a tensor that is not symmetric positive definite
+	ErrInvalidInertia = errors.New("actor: the inertia tensor must be symmetric positive definite")
+	// ErrCenterOfMassShape is returned by SetCenterOfMass for a shape that cannot be a child of a Compound
+	ErrCenterOfMassShape = errors.New("actor: the center of mass of planes and triangle meshes cannot be moved")
+)
+
+// IsValid returns true for BodyTypeDynamic and BodyTypeStatic
+func (bodyType BodyType) IsValid() bool {
+	return bodyType == BodyTypeDynamic || bodyType == BodyTypeStatic
+}
+
+const (
+	// MaxRotationPerStep limits the rotation of a body during a single substep (radians).
+	// Beyond π/2 the orientation aliases (a quarter turn of a box looks like no rotation at all),
+	// and the contacts generated from the predicted orientation become inconsistent.
+	// The applied rotation is clamped, Update then derives the angular velocity from it.
+	MaxRotationPerStep = 0.45 * math.Pi
+
+	// VelocityExpansion scales the displacement (velocity * dt) by which BroadPhaseAABB extends the AABB
+	VelocityExpansion = 1.0
+
+	// rotationIntegrationStep is the largest rotation integrated at once by the first order
+	// quaternion update, larger rotations are subdivided to keep it accurate.
+	rotationIntegrationStep = math.Pi / 16
+)
+
 type Material struct {
 	Density     float64
 	mass        float64
 	Restitution float64 // 0= no rebound, 1= perfect restitution
+	// RestitutionCurve scales Restitution with the approach speed of each contact point
+	RestitutionCurve RestitutionCurve
 
 	StaticFriction  float64
 	DynamicFriction float64
 	LinearDamping   float64 // 0.0 - 1.0, typique : 0.01
 	AngularDamping  float64 // 0.0 - 1.0, typique : 0.05
+
+	// SpinFriction (m) opposes the spin about the contact normal (torsional friction): the torque is bounded
+	// by SpinFriction times the normal force, like the radius of the contact patch. 0 lets the bodies spin freely.
+	SpinFriction float64
+
+	// FrictionCombine and RestitutionCombine select how the coefficients of two materials in contact are mixed.
+	// When the materials disagree, the highest mode wins (e.g. an ice material with CombineMin stays slippery).
+	FrictionCombine    CombineMode
+	RestitutionCombine CombineMode
+}
+
+// CombineMode is the rule mixing a coefficient (friction, restitution) of two materials in contact
+type CombineMode uint8
+
+const (
+	// CombineDefault uses the default rule of the coefficient: the average restitution, the geometric mean of the frictions
+	CombineDefault CombineMode = iota
+	// CombineAverage is (a + b) / 2
+	CombineAverage
+	// CombineGeometric is sqrt(a * b)
+	CombineGeometric
+	// CombineMin is the lowest coefficient
+	CombineMin
+	// CombineMultiply is a * b
+	CombineMultiply
+	// CombineMax is the highest coefficient
+	CombineMax
+)
+
+// Combine mixes the coefficients a and b
+func (mode CombineMode) Combine(a, b float64) float64 {
+	switch mode {
+	case CombineGeometric:
+		return math.Sqrt(a * b)
+	case CombineMin:
+		return math.Min(a, b)
+	case CombineMultiply:
+		return a * b
+	case CombineMax:
+		return math.Max(a, b)
+	default:
+		return (a + b) / 2.0
+	}
+}
+
+// RestitutionCurve makes the restitution depend on the approach speed (m/s) of a contact point:
+// no bounce under MinSpeed, the full restitution from MaxSpeed, linear in between.
+// Real objects stop bouncing at low speed, resting contacts then do not jitter while hard impacts still bounce.
+// The zero curve keeps the restitution constant.
+type RestitutionCurve struct {
+	MinSpeed float64
+	MaxSpeed float64
+}
+
+// Scale returns the factor applied to the restitution at the approach speed, in [0, 1]
+func (curve RestitutionCurve) Scale(speed float64) float64 {
+	switch {
+	case speed < curve.MinSpeed:
+		return 0
+	case speed >= curve.MaxSpeed:
+		return 1
+	}
+
+	return (speed - curve.MinSpeed) / (curve.MaxSpeed - curve.MinSpeed)
 }
 
 func (material Material) GetMass() float64 {
@@ -39,15 +162,26 @@ func (material Material) GetMass() float64 {
 type RigidBody struct {
 	// Useful to map to user data (e.g. entity id)
 	Id any
+	// Name is optional, it identifies the body in the diagnostics (validation errors, Stats, debug assertions)
+	Name string
 
 	// Spatial properties
+	// Transform is the pose of the shape, whose local origin is the center of mass, see ShapeOffset
 	PreviousTransform Transform
 	Transform         Transform
+	// shapeOffset is the pose of the shape relative to the body origin, see SetShapeOffset
+	shapeOffset Transform
+	// centerOfMass is the offset of the center of mass from the one of the shape, see SetCenterOfMass
+	centerOfMass mgl64.Vec3
 
 	// Linear motion
 	PresolveVelocity mgl64.Vec3
 	Velocity         mgl64.Vec3 // Linear velocity (m/s)
 
+	// MaxVelocity (m/s) and MaxAngularVelocity (rad/s) override the limits of the world when positive, see ClampVelocity
+	MaxVelocity        float64
+	MaxAngularVelocity float64
+
 	// Angular motion (NOUVEAU)
 	PresolveAngularVelocity mgl64.Vec3
 	AngularVelocity         mgl64.Vec3 // Vitesse de rotation (rad/s)
@@ -55,19 +189,42 @@ type RigidBody struct {
 	InertiaLocal        mgl64.Mat3 // Tenseur d'inertie en espace local
 	InverseInertiaLocal mgl64.Mat3
 
+	// Force (N) and torque (N⋅m) in world space applied during the next Step, see ApplyForce and ApplyTorque
 	accumulatedForce  mgl64.Vec3
 	accumulatedTorque mgl64.Vec3
 
+	// Cache of GetInverseInertiaWorld, valid while the rotation equals inverseInertiaRotation
+	inverseInertiaWorld    mgl64.Mat3
+	inverseInertiaRotation mgl64.Quat
+	inverseInertiaCached   bool
+	// inertiaForm selects the fast path of GetInertiaWorld and GetInverseInertiaWorld, see InvalidateInertia
+	inertiaForm inertiaForm
+
 	IsTrigger  bool
 	IsSleeping bool
 	SleepTimer float64
+	// disabled removes the body from the simulation while keeping its state, see SetEnabled
+	disabled bool
+	// deferred freezes the body for the rest of a Step, see SetDeferred
+	deferred bool
+	// linearLock and angularLock are the world axes the body cannot move along or rotate about, see SetAxisLocks
+	linearLock  AxisLock
+	angularLock AxisLock
 
 	// Physical properties
 	Material Material
 	BodyType BodyType // Dynamic or Static
+	// massOverride and inertiaOverride replace the values derived from the shape when set, see SetMass and SetInertia
+	massOverride    float64
+	inertiaOverride mgl64.Mat3
 
 	// Collision shape
 	Shape ShapeInterface // The collision shape
+	// sweep is the displacement expected during the next substep, see BroadPhaseAABB
+	sweep mgl64.Vec3
+	// swappedAABB is the AABB of the shape replaced by SetShape, until the next ApplyShapeChanges
+	swappedAABB  AABB
+	shapeSwapped bool
 
 	Mutex sync.Mutex
 }
@@ -83,55 +240,382 @@ func NewRigidBody(transform Transform, shape ShapeInterface, bodyType BodyType,
 		Velocity:          mgl64.Vec3{0, 0, 0},
 	}
 
-	// Calculate mass data based on body type
+	// Static bodies ignore the density
 	if bodyType == BodyTypeStatic {
-		// Static bodies have infinite mass
-		rb.Material = Material{
-			Density:         0,
-			mass:            math.Inf(1),
-			StaticFriction:  0.0,
-			DynamicFriction: 0.0,
-		}
-	} else {
-		// Dynamic bodies compute mass from shape and density
-		rb.Material = Material{
-			Density:         density,
-			mass:            shape.ComputeMass(density),
-			Restitution:     0.0,
-			StaticFriction:  0.0,
-			DynamicFriction: 0.0,
-			LinearDamping:   0.0,
-			AngularDamping:  0.0,
-		}
+		density = 0
+	}
+	rb.Material = Material{
+		Density:         density,
+		Restitution:     0.0,
+		StaticFriction:  0.0,
+		DynamicFriction: 0.0,
+		LinearDamping:   0.0,
+		AngularDamping:  0.0,
 	}
 
-	rb.InertiaLocal = shape.ComputeInertia(rb.Material.mass)
-	rb.InverseInertiaLocal = rb.InertiaLocal.Inv()
+	rb.computeMassData()
 	rb.Shape.ComputeAABB(rb.Transform)
 
 	return rb
 }
 
-// TrySleep check if a body can be set to sleep.
+// NewRigidBodyWithMass creates a new rigid body with the given mass instead of a density
+// The density is derived from the shape volume (ignored for static bodies)
+func NewRigidBodyWithMass(transform Transform, shape ShapeInterface, bodyType BodyType, mass float64) *RigidBody {
+	density := 0.0
+	if unitMass := shape.ComputeMass(1.0); unitMass > 0 && !math.IsInf(unitMass, 1) {
+		density = mass / unitMass
+	}
+
+	return NewRigidBody(transform, shape, bodyType, density)
+}
+
+// computeMassData computes the mass and the inertia from the body type and the density:
+// static bodies have an infinite mass, dynamic bodies compute it from the shape unless it is overridden
+func (rb *RigidBody) computeMassData() {
+	switch {
+	case rb.BodyType == BodyTypeStatic:
+		rb.Material.mass = math.Inf(1)
+	case rb.massOverride > 0:
+		rb.Material.mass = rb.massOverride
+	default:
+		rb.Material.mass = rb.Shape.ComputeMass(rb.Material.Density)
+	}
+
+	if rb.BodyType != BodyTypeStatic && rb.inertiaOverride != (mgl64.Mat3{}) {
+		rb.InertiaLocal = rb.inertiaOverride
+	} else {
+		rb.InertiaLocal = rb.Shape.ComputeInertia(rb.Material.mass)
+	}
+	rb.InverseInertiaLocal = rb.InertiaLocal.Inv()
+	rb.InvalidateInertia()
+}
+
+// Validate returns an error if the flags of the body are an invalid combination.
+// The error is wrapped with the Name of the body, errors.Is still matches it.
+func (rb *RigidBody) Validate() error {
+	if !rb.BodyType.IsValid() {
+		return rb.named(ErrInvalidBodyType)
+	}
+	if rb.BodyType == BodyTypeStatic && rb.IsSleeping {
+		return rb.named(ErrStaticSleeping)
+	}
+	if _, isMesh := rb.Shape.(*TriangleMesh); isMesh && rb.BodyType == BodyTypeDynamic {
+		return rb.named(ErrDynamicMesh)
+	}
+
+	return nil
+}
+
+// named wraps the error with the name of the body, if any
+func (rb *RigidBody) named(err error) error {
+	if rb.Name == "" {
+		return err
+	}
+
+	return fmt.Errorf("%w: %q", err, rb.Name)
+}
+
+// Label identifies the body in the diagnostics: its Name, or its Id if it has no name
+func (rb *RigidBody) Label() string {
+	if rb.Name != "" {
+		return rb.Name
+	}
+	if rb.Id != nil {
+		return fmt.Sprint(rb.Id)
+	}
+
+	return "unnamed body"
+}
+
+// IsActive returns true if the body is dynamic, awake, enabled and not deferred
+func (rb *RigidBody) IsActive() bool {
+	return rb.BodyType != BodyTypeStatic && !rb.IsSleeping && !rb.disabled && !rb.deferred
+}
+
+// IsEnabled returns false if the body was disabled by SetEnabled, the bodies are enabled when created
+func (rb *RigidBody) IsEnabled() bool {
+	return !rb.disabled
+}
+
+// SetEnabled disables the body or enables it back, e.g. a pooled object or a body of a streamed level.
+// A disabled body stays in the world but is skipped by the broad phase, the queries, the integration and the events:
+// it keeps its pose, velocities and sleep state until it is enabled, and the joints hold to it like to a static body.
+func (rb *RigidBody) SetEnabled(enabled bool) {
+	rb.disabled = !enabled
+}
+
+// SetDeferred freezes the body like a sleeping one, keeping its velocities, or releases it.
+// World.Step defers the islands far from World.BudgetFocus to respect World.StepBudget,
+// and releases them before returning.
+func (rb *RigidBody) SetDeferred(deferred bool) {
+	rb.deferred = deferred
+}
+
+// IsDeferred returns true while the body is frozen by SetDeferred
+func (rb *RigidBody) IsDeferred() bool {
+	return rb.deferred
+}
+
+// AxisLock is a set of world axes, see RigidBody.SetAxisLocks
+type AxisLock uint8
+
+// LockX, LockY and LockZ are the world axes
+const (
+	LockX AxisLock = 1 << iota
+	LockY
+	LockZ
+	// LockAll locks the three axes
+	LockAll = LockX | LockY | LockZ
+)
+
+// project returns the vector without its components on the locked axes
+func (lock AxisLock) project(v mgl64.Vec3) mgl64.Vec3 {
+	for axis := range 3 {
+		if lock&(1<<axis) != 0 {
+			v[axis] = 0
+		}
+	}
+
+	return v
+}
+
+// projectMatrix returns P * m * P, P removing the locked axes: the rows and columns of the locked axes are zeroed
+func (lock AxisLock) projectMatrix(m mgl64.Mat3) mgl64.Mat3 {
+	for axis := range 3 {
+		if lock&(1<<axis) == 0 {
+			continue
+		}
+		for i := range 3 {
+			m.Set(axis, i, 0)
+			m.Set(i, axis, 0)
+		}
+	}
+
+	return m
+}
+
+// AxisLocks returns the world axes along which the body cannot move, and about which it cannot rotate
+func (rb *RigidBody) AxisLocks() (linear, angular AxisLock) {
+	return rb.linearLock, rb.angularLock
+}
+
+// SetAxisLocks constrains the motion of the body, e.g. LockZ and LockX|LockY for a 2.5D game in the XY plane.
+// The linear locks freeze the position along world axes, the angular locks the rotation about world axes.
+// The velocities on the locked axes are removed, and the body is woken up.
+// The solvers see an inverse inertia without the locked axes (see GetInverseInertiaWorld), the corrections
+// along a locked linear axis are discarded when the velocities are derived from the positions.
+func (rb *RigidBody) SetAxisLocks(linear, angular AxisLock) {
+	rb.linearLock = linear & LockAll
+	rb.angularLock = angular & LockAll
+	rb.ApplyAxisLocks()
+	rb.WakeUp()
+}
+
+// ApplyAxisLocks removes the velocities on the locked axes, the world calls it after the velocity solve
+func (rb *RigidBody) ApplyAxisLocks() {
+	rb.Velocity = rb.linearLock.project(rb.Velocity)
+	rb.AngularVelocity = rb.angularLock.project(rb.AngularVelocity)
+}
+
+// SetBodyType changes the type of the body at runtime.
+// The mass and inertia are recomputed from Material.Density, the velocities and forces are reset,
+// and the body is woken up.
+func (rb *RigidBody) SetBodyType(bodyType BodyType) error {
+	if !bodyType.IsValid() {
+		return ErrInvalidBodyType
+	}
+	if _, isMesh := rb.Shape.(*TriangleMesh); isMesh && bodyType == BodyTypeDynamic {
+		return ErrDynamicMesh
+	}
+	if bodyType == BodyTypeDynamic && rb.Material.Density <= 0 && rb.massOverride <= 0 {
+		return ErrDynamicWithoutDensity
+	}
+	if bodyType == rb.BodyType {
+		return nil
+	}
+
+	rb.BodyType = bodyType
+	rb.computeMassData()
+
+	rb.Velocity = mgl64.Vec3{}
+	rb.AngularVelocity = mgl64.Vec3{}
+	rb.ClearForces()
+	rb.WakeUp()
+
+	return nil
+}
+
+// SetTrigger changes the body into a trigger (no contact response, trigger events only), or back.
+// The body is woken up, so its overlapping pairs report the new kind of events.
+func (rb *RigidBody) SetTrigger(isTrigger bool) {
+	rb.IsTrigger = isTrigger
+	rb.WakeUp()
+}
+
+// SetSleeping sets the body to sleep or wakes it up.
+// Returns ErrStaticSleeping for a static body set to sleep.
+func (rb *RigidBody) SetSleeping(isSleeping bool) error {
+	if !isSleeping {
+		rb.WakeUp()
+
+		return nil
+	}
+	if rb.BodyType == BodyTypeStatic {
+		return ErrStaticSleeping
+	}
+
+	rb.Sleep()
+
+	return nil
+}
+
+// SleepThresholds are the thresholds of the sleep hysteresis, see TrySleep.
+// The linear velocities are in m/s, the angular velocities in rad/s.
+type SleepThresholds struct {
+	// Time is the duration (s) a body must stay under LinearVelocity and AngularVelocity to fall asleep
+	Time            float64
+	LinearVelocity  float64
+	AngularVelocity float64
+	// WakeLinearVelocity and WakeAngularVelocity are the velocities a sleeping body must receive to wake up,
+	// higher than LinearVelocity and AngularVelocity
+	WakeLinearVelocity  float64
+	WakeAngularVelocity float64
+}
+
+// TrySleep check if a body can be set to sleep, or must be woken up.
+// The thresholds implement a hysteresis to avoid flickering between the two states:
+//   - an awake body falls asleep after staying under both sleep velocities for thresholds.Time seconds
+//   - a sleeping body wakes up only when pushed above a wake velocity (higher than the sleep velocities),
+//     smaller velocities received while sleeping (solver noise) are discarded
+//
+// Static bodies never sleep, disabled and deferred bodies keep their state.
+//
 // returns 0 if no changes, 1 if set to sleep, 2 if waken
-func (rb *RigidBody) TrySleep(dt float64, timethreshold float64, velocityThreshold float64) uint8 {
-	if rb.Velocity.Len() < velocityThreshold && rb.AngularVelocity.Len() < velocityThreshold {
-		rb.SleepTimer += dt // Incrémente le timer
-		if !rb.IsSleeping && rb.SleepTimer >= timethreshold {
+func (rb *RigidBody) TrySleep(dt float64, thresholds SleepThresholds) uint8 {
+	if rb.BodyType == BodyTypeStatic || rb.disabled || rb.deferred {
+		return 0
+	}
+
+	if rb.IsSleeping {
+		if rb.Velocity.Len() > thresholds.WakeLinearVelocity || rb.AngularVelocity.Len() > thresholds.WakeAngularVelocity {
+			rb.WakeUp()
+
+			return 2
+		}
+
+		rb.Velocity = mgl64.Vec3{}
+		rb.AngularVelocity = mgl64.Vec3{}
+
+		return 0
+	}
+
+	if rb.Velocity.Len() < thresholds.LinearVelocity && rb.AngularVelocity.Len() < thresholds.AngularVelocity {
+		rb.SleepTimer += dt
+		if rb.SleepTimer >= thresholds.Time {
 			rb.Sleep()
 
 			return 1
 		}
 	} else {
-		rb.WakeUp()
-
-		return 2
+		rb.SleepTimer = 0.0
 	}
 
 	return 0
 }
 
+// SetShape replaces the collision shape at runtime, e.g. a crouching character or a growing object.
+// The mass, inertia and AABB are recomputed immediately, the overrides of SetMass, SetInertia and SetCenterOfMass
+// are kept: Transform stays the pose of the center of mass. The body is woken up, and at the next World.Step
+// the contacts cached for the previous shape are discarded and the sleeping bodies around both shapes are woken up.
+// Returns ErrDynamicMesh for a TriangleMesh on a dynamic body, ErrCenterOfMassShape for a plane or a triangle mesh
+// on a body whose center of mass was moved.
+func (rb *RigidBody) SetShape(shape ShapeInterface) error {
+	switch shape.(type) {
+	case *TriangleMesh:
+		if rb.BodyType == BodyTypeDynamic {
+			return ErrDynamicMesh
+		}
+		if rb.centerOfMass != (mgl64.Vec3{}) {
+			return ErrCenterOfMassShape
+		}
+	case *Plane:
+		if rb.centerOfMass != (mgl64.Vec3{}) {
+			return ErrCenterOfMassShape
+		}
+	}
+
+	if !rb.shapeSwapped {
+		rb.swappedAABB = rb.Shape.GetAABB()
+		rb.shapeSwapped = true
+	}
+
+	// The new shape is placed at the pose of the previous one, then its center of mass is moved the same way
+	centerOfMass := rb.centerOfMass
+	if err := rb.SetCenterOfMass(mgl64.Vec3{}); err != nil {
+		return err
+	}
+	rb.Shape = shape
+	if mutable, ok := shape.(mutableShape); ok {
+		mutable.consumeChanges()
+	}
+	if err := rb.SetCenterOfMass(centerOfMass); err != nil {
+		return err
+	}
+
+	rb.computeMassData()
+	rb.Shape.ComputeAABB(rb.Transform)
+	rb.WakeUp()
+
+	return nil
+}
+
+// SwappedShape returns the AABB of the shape replaced by SetShape since the last ApplyShapeChanges,
+// the world wakes up the sleeping bodies around it
+func (rb *RigidBody) SwappedShape() (AABB, bool) {
+	return rb.swappedAABB, rb.shapeSwapped
+}
+
+// ApplyShapeChanges applies the parameters changed by the shape setters (e.g. Box.SetHalfExtents):
+// the mass and inertia are recomputed if requested, the AABB is recomputed so the SpatialGrid
+// uses the new size at the next broad phase, and the body is woken up. The body follows the center of mass
+// moved by Compound.AddChild and RemoveChild.
+// Returns false if the shape has no pending changes and was not replaced by SetShape.
+func (rb *RigidBody) ApplyShapeChanges() bool {
+	swapped := rb.shapeSwapped
+	rb.shapeSwapped = false
+
+	shape, ok := rb.Shape.(mutableShape)
+	if !ok {
+		return swapped
+	}
+
+	dirty, recomputeMass := shape.consumeChanges()
+	if !dirty {
+		return swapped
+	}
+
+	// The children added or removed moved the center of mass, the body follows it so the others stay in place
+	if compound, ok := rb.Shape.(*Compound); ok {
+		rb.moveCenterOfMass(compound.consumeShift())
+	}
+	if recomputeMass && rb.BodyType != BodyTypeStatic {
+		rb.computeMassData()
+	}
+
+	rb.Shape.ComputeAABB(rb.Transform)
+	rb.WakeUp()
+
+	return true
+}
+
+// Sleep freezes the body until it is woken up, static bodies are ignored.
+// The velocities, forces and sleep timer are reset; a world emits ON_SLEEP at the end of its next Step.
 func (rb *RigidBody) Sleep() {
+	if rb.BodyType == BodyTypeStatic {
+		return
+	}
+
 	rb.IsSleeping = true
 	rb.SleepTimer = 0.0
 
@@ -139,15 +623,27 @@ func (rb *RigidBody) Sleep() {
 	rb.ClearForces()
 	rb.Velocity = mgl64.Vec3{}
 	rb.AngularVelocity = mgl64.Vec3{}
+	rb.sweep = mgl64.Vec3{}
 }
 
+// WakeUp resumes the simulation of the body, and restarts its sleep timer: an awake body is kept awake
+// for the sleep time. A world emits ON_WAKE at the end of its next Step if the body was sleeping.
 func (rb *RigidBody) WakeUp() {
 	rb.IsSleeping = false
 	rb.SleepTimer = 0.0
 }
 
+// Integrate applies the gravity, the forces and the damping to the velocities, then moves the body by them.
+// The velocities are bounded by MaxVelocity and MaxAngularVelocity, see IntegrateClamped.
 func (rb *RigidBody) Integrate(dt float64, gravity mgl64.Vec3) {
-	if rb.BodyType == BodyTypeStatic || rb.IsSleeping {
+	rb.IntegrateClamped(dt, gravity, rb.MaxVelocity, rb.MaxAngularVelocity)
+}
+
+// IntegrateClamped is Integrate with the velocities bounded by maxVelocity (m/s) and maxAngularVelocity (rad/s)
+// once the forces are applied, before the body is moved (0 disables a limit), see ClampVelocity
+func (rb *RigidBody) IntegrateClamped(dt float64, gravity mgl64.Vec3, maxVelocity, maxAngularVelocity float64) {
+	if rb.BodyType == BodyTypeStatic || rb.IsSleeping || rb.disabled || rb.deferred {
+		rb.sweep = mgl64.Vec3{}
 		return
 	}
 
@@ -156,71 +652,191 @@ func (rb *RigidBody) Integrate(dt float64, gravity mgl64.Vec3) {
 	rb.PreviousTransform.Rotation = rb.Transform.Rotation
 
 	// ========== INTÉGRATION LINÉAIRE ==========
-	forces := gravity.Mul(rb.Material.mass).Mul(dt * (1.0 / rb.Material.GetMass()))
-	forces = forces.Add(rb.accumulatedForce.Mul(1.0 / rb.Material.GetMass()))
-	rb.Velocity = rb.Velocity.Add(forces)
+	forces := gravity.Mul(rb.Material.mass).Add(rb.accumulatedForce)
+	rb.Velocity = rb.Velocity.Add(forces.Mul(dt / rb.Material.GetMass()))
 
 	// ========== LINEAR DAMPING ==========
-	rb.Velocity = rb.Velocity.Mul(math.Exp(-rb.Material.LinearDamping * dt))
-	rb.Transform.Position = rb.Transform.Position.Add(rb.Velocity.Mul(dt))
+	rb.Velocity = rb.linearLock.project(rb.Velocity.Mul(dmath.Exp(-rb.Material.LinearDamping * dt)))
 
 	// ========== INTÉGRATION ANGULAIRE ==========
 	I_inv := rb.GetInverseInertiaWorld()
-	torques := rb.accumulatedTorque.Mul(1.0 / dt)
-	angularAccel := I_inv.Mul3x1(torques)
+	angularAccel := I_inv.Mul3x1(rb.accumulatedTorque)
 	rb.AngularVelocity = rb.AngularVelocity.Add(angularAccel.Mul(dt))
 
 	// ========== ANGULAR DAMPING ==========
-	rb.AngularVelocity = rb.AngularVelocity.Mul(math.Exp(-rb.Material.AngularDamping * dt))
+	rb.AngularVelocity = rb.AngularVelocity.Mul(dmath.Exp(-rb.Material.AngularDamping * dt))
+	rb.ApplyAxisLocks()
+	rb.ClampVelocity(maxVelocity, maxAngularVelocity)
+
+	rb.Transform.Position = rb.Transform.Position.Add(rb.Velocity.Mul(dt))
 
 	// ========== UPDATE QUATERNION ==========
-	omegaQuat := mgl64.Quat{V: rb.AngularVelocity, W: 0}
-	q_dot := omegaQuat.Mul(rb.Transform.Rotation).Scale(0.5)
-	rb.Transform.Rotation = rb.Transform.Rotation.Add(q_dot.Scale(dt)).Normalize()
-	rb.Transform.InverseRotation = rb.Transform.Rotation.Inverse()
+	rb.integrateRotation(dt)
 
 	rb.PresolveVelocity = rb.Velocity
 	rb.PresolveAngularVelocity = rb.AngularVelocity
 
 	rb.Shape.ComputeAABB(rb.Transform)
-	rb.ClearForces()
+	rb.sweep = rb.Velocity.Mul(dt * VelocityExpansion)
+}
+
+// BroadPhaseAABB returns the AABB of the shape swept by the displacement expected during the next substep,
+// VelocityExpansion times the velocity at the last Integrate: the broad phase pairs the bodies that may collide
+// before it runs again, for the speculative contacts and the continuous collision detection.
+// Static and sleeping bodies return the AABB of their shape.
+func (rb *RigidBody) BroadPhaseAABB() AABB {
+	return rb.Shape.GetAABB().Swept(rb.sweep)
+}
+
+// integrateRotation clamps the rotation of the substep to MaxRotationPerStep,
+// then integrates the quaternion in increments of at most rotationIntegrationStep
+func (rb *RigidBody) integrateRotation(dt float64) {
+	omega := rb.AngularVelocity
+	angle := omega.Len() * dt
+	if angle > MaxRotationPerStep {
+		omega = omega.Mul(MaxRotationPerStep / angle)
+		angle = MaxRotationPerStep
+	}
+
+	steps := int(math.Ceil(angle / rotationIntegrationStep))
+	if steps < 1 {
+		steps = 1
+	}
+	h := dt / float64(steps)
+
+	omegaQuat := mgl64.Quat{V: omega, W: 0}
+	for i := 0; i < steps; i++ {
+		q_dot := omegaQuat.Mul(rb.Transform.Rotation).Scale(0.5)
+		rb.Transform.Rotation = rb.Transform.Rotation.Add(q_dot.Scale(h)).Normalize()
+	}
+	rb.Transform.InverseRotation = rb.Transform.Rotation.Inverse()
 }
 
 func (rb *RigidBody) Update(dt float64) {
-	if rb.BodyType == BodyTypeStatic || rb.IsSleeping {
+	if rb.BodyType == BodyTypeStatic || rb.IsSleeping || rb.disabled || rb.deferred {
 		return
 	}
 
+	// The corrections of the solver along the locked axes are discarded
+	for axis := range 3 {
+		if rb.linearLock&(1<<axis) != 0 {
+			rb.Transform.Position[axis] = rb.PreviousTransform.Position[axis]
+		}
+	}
+
 	// Commit predicted position to actual position
 	rb.Velocity = rb.Transform.Position.Sub(rb.PreviousTransform.Position).Mul(1.0 / dt)
 	qDelta := rb.Transform.Rotation.Mul(rb.PreviousTransform.Rotation.Conjugate())
 	qDelta = qDelta.Normalize()
-	if qDelta.W >= 0.0 {
+	if qDelta.W < 0.0 {
+		qDelta = qDelta.Scale(-1)
+	}
+
+	// Exact angle of the rotation: the small angle approximation (2*V/dt) underestimates
+	// the angular velocity of rotations close to MaxRotationPerStep
+	sinHalfAngle := qDelta.V.Len()
+	if sinHalfAngle < 1e-9 {
 		rb.AngularVelocity = qDelta.V.Mul(2.0 / dt)
 	} else {
-		rb.AngularVelocity = qDelta.V.Mul(-2.0 / dt)
+		angle := 2.0 * dmath.Atan2(sinHalfAngle, qDelta.W)
+		rb.AngularVelocity = qDelta.V.Mul(angle / (sinHalfAngle * dt))
 	}
 }
 
-// AddForce in 1000N (1000 * kg⋅m/s²)
+// ClampVelocity scales down the linear velocity above maxVelocity (m/s) and the angular velocity above
+// maxAngularVelocity (rad/s), the direction is kept. A limit of 0 is disabled.
+// The world clamps the bodies at each substep, with their MaxVelocity and MaxAngularVelocity or its own limits.
+func (rb *RigidBody) ClampVelocity(maxVelocity, maxAngularVelocity float64) {
+	if speed := rb.Velocity.Len(); maxVelocity > 0 && speed > maxVelocity {
+		rb.Velocity = rb.Velocity.Mul(maxVelocity / speed)
+	}
+	if speed := rb.AngularVelocity.Len(); maxAngularVelocity > 0 && speed > maxAngularVelocity {
+		rb.AngularVelocity = rb.AngularVelocity.Mul(maxAngularVelocity / speed)
+	}
+}
+
+// AddForce changes the linear velocity by an impulse of 1000 × force N⋅s
+//
+// Deprecated: use ApplyForce for a force in N, or ApplyImpulse.
 func (rb *RigidBody) AddForce(force mgl64.Vec3) {
+	rb.ApplyImpulse(force.Mul(1000))
+}
+
+// AddTorque changes the angular velocity by an angular impulse of 1000 × torque N⋅m⋅s
+//
+// Deprecated: use ApplyTorque for a torque in N⋅m, or ApplyAngularImpulse.
+func (rb *RigidBody) AddTorque(torque mgl64.Vec3) {
+	rb.ApplyAngularImpulse(torque.Mul(1000))
+}
+
+// ApplyForce applies a force in N at the center of mass during the next Step, e.g. a thruster held down:
+// the forces are accumulated, integrated at each substep, then cleared at the end of the Step by the world,
+// so a continuous force is applied before each Step
+func (rb *RigidBody) ApplyForce(force mgl64.Vec3) {
 	if rb.BodyType != BodyTypeStatic {
 		rb.WakeUp()
 
-		rb.accumulatedForce = rb.accumulatedForce.Add(force.Mul(1000))
+		rb.accumulatedForce = rb.accumulatedForce.Add(force)
 	}
 }
 
-// AddTorque in 1000N⋅m
-func (rb *RigidBody) AddTorque(torque mgl64.Vec3) {
+// ApplyForceAtPoint applies a force in N at a point in world space during the next Step:
+// a force off the center of mass also applies a torque, see ApplyForce
+func (rb *RigidBody) ApplyForceAtPoint(force mgl64.Vec3, point mgl64.Vec3) {
+	if rb.BodyType != BodyTypeStatic {
+		r := point.Sub(rb.Transform.Position)
+
+		rb.ApplyForce(force)
+		rb.ApplyTorque(r.Cross(force))
+	}
+}
+
+// ApplyTorque applies a torque in N⋅m in world space during the next Step, see ApplyForce
+func (rb *RigidBody) ApplyTorque(torque mgl64.Vec3) {
+	if rb.BodyType != BodyTypeStatic {
+		rb.WakeUp()
+
+		rb.accumulatedTorque = rb.accumulatedTorque.Add(torque)
+	}
+}
+
+// ApplyImpulse changes the linear velocity instantly, impulse in N⋅s (kg⋅m/s) applied at the center of mass
+func (rb *RigidBody) ApplyImpulse(impulse mgl64.Vec3) {
 	if rb.BodyType != BodyTypeStatic {
 		rb.WakeUp()
 
-		rb.accumulatedTorque = rb.accumulatedTorque.Add(torque.Mul(1000))
+		rb.Velocity = rb.Velocity.Add(rb.linearLock.project(impulse).Mul(1.0 / rb.Material.GetMass()))
+	}
+}
+
+// ApplyAngularImpulse changes the angular velocity instantly, angular impulse in N⋅m⋅s in world space
+// e.g. to add spin to a ball
+func (rb *RigidBody) ApplyAngularImpulse(angularImpulse mgl64.Vec3) {
+	if rb.BodyType != BodyTypeStatic {
+		rb.WakeUp()
+
+		rb.AngularVelocity = rb.AngularVelocity.Add(rb.GetInverseInertiaWorld().Mul3x1(angularImpulse))
+	}
+}
+
+// ApplyImpulseAtPoint applies an impulse in N⋅s at a point in world space:
+// an impulse off the center of mass also changes the angular velocity
+func (rb *RigidBody) ApplyImpulseAtPoint(impulse mgl64.Vec3, point mgl64.Vec3) {
+	if rb.BodyType != BodyTypeStatic {
+		r := point.Sub(rb.Transform.Position)
+
+		rb.ApplyImpulse(impulse)
+		rb.ApplyAngularImpulse(r.Cross(impulse))
 	}
 }
 
-// Méthodes optionnelles pour reset
+// ApplyImpulseAtLocalPoint applies an impulse in N⋅s (world space) at a point in the local space of the body
+// e.g. a kick on the side of a ball
+func (rb *RigidBody) ApplyImpulseAtLocalPoint(impulse mgl64.Vec3, localPoint mgl64.Vec3) {
+	rb.ApplyImpulseAtPoint(impulse, rb.Transform.Position.Add(rb.Transform.Rotation.Rotate(localPoint)))
+}
+
+// ClearForces removes the forces and torques applied for the next Step
 func (rb *RigidBody) ClearForces() {
 	rb.accumulatedForce = mgl64.Vec3{0, 0, 0}
 	rb.accumulatedTorque = mgl64.Vec3{0, 0, 0}
@@ -238,20 +854,285 @@ func (rb *RigidBody) SupportWorld(direction mgl64.Vec3) mgl64.Vec3 {
 	return rb.Transform.Position.Add(worldSupport)
 }
 
+// RaycastWorld casts a world-space ray against the body's shape, direction must be normalized.
+// Returns the distance to the hit along direction, and the world-space surface normal there.
+func (rb *RigidBody) RaycastWorld(origin, direction mgl64.Vec3, maxDist float64) (float64, mgl64.Vec3, bool) {
+	inverse := rb.Transform.Rotation.Conjugate()
+	localOrigin := inverse.Rotate(origin.Sub(rb.Transform.Position))
+
+	distance, localNormal, ok := rb.Shape.Raycast(localOrigin, inverse.Rotate(direction), maxDist)
+	if !ok {
+		return 0, mgl64.Vec3{}, false
+	}
+
+	return distance, rb.Transform.Rotation.Rotate(localNormal), true
+}
+
+// ClosestPointWorld returns the point of the body's shape closest to a world-space point
+func (rb *RigidBody) ClosestPointWorld(point mgl64.Vec3) mgl64.Vec3 {
+	localPoint := rb.Transform.Rotation.Conjugate().Rotate(point.Sub(rb.Transform.Position))
+	localClosest := rb.Shape.ClosestPoint(localPoint)
+
+	return rb.Transform.Position.Add(rb.Transform.Rotation.Rotate(localClosest))
+}
+
+// ContainsPointWorld returns true if the world-space point lies inside the body's shape, see ShapeInterface.ContainsPoint
+func (rb *RigidBody) ContainsPointWorld(point mgl64.Vec3) bool {
+	return rb.Shape.ContainsPoint(rb.Transform.Rotation.Conjugate().Rotate(point.Sub(rb.Transform.Position)))
+}
+
+// ShapeOffset returns the pose of the shape relative to the body origin, the identity by default
+func (rb *RigidBody) ShapeOffset() Transform {
+	if rb.shapeOffset.Rotation == (mgl64.Quat{}) {
+		return NewTransform()
+	}
+
+	return rb.shapeOffset
+}
+
+// SetShapeOffset places the shape relative to the body origin, e.g. a character whose model origin is
+// at its feet, or a convex hull built around its CenterOfMass.
+// The solver rotates the bodies around their center of mass, so Transform stays the pose of the shape,
+// and SupportWorld, the AABB and the inertia are unchanged: the body origin moves instead.
+// OriginTransform and SetOriginTransform convert from and to the pose of the origin.
+func (rb *RigidBody) SetShapeOffset(position mgl64.Vec3, rotation mgl64.Quat) {
+	rb.shapeOffset = Transform{Position: position, Rotation: rotation.Normalize()}
+	rb.shapeOffset.InverseRotation = rb.shapeOffset.Rotation.Conjugate()
+}
+
+// OriginTransform returns the pose of the body origin, e.g. to render a model whose origin
+// is not its collision center
+func (rb *RigidBody) OriginTransform() Transform {
+	offset := rb.ShapeOffset()
+	rotation := rb.Transform.Rotation.Mul(offset.Rotation.Conjugate())
+
+	return Transform{
+		Position:        rb.Transform.Position.Sub(rotation.Rotate(offset.Position)),
+		Rotation:        rotation,
+		InverseRotation: rotation.Conjugate(),
+	}
+}
+
+// SetOriginTransform teleports the body so its origin is at the given pose, the velocities are kept
+func (rb *RigidBody) SetOriginTransform(origin Transform) {
+	offset := rb.ShapeOffset()
+	rotation := origin.Rotation.Mul(offset.Rotation)
+
+	rb.Transform = Transform{
+		Position:        origin.Position.Add(origin.Rotation.Rotate(offset.Position)),
+		Rotation:        rotation,
+		InverseRotation: rotation.Conjugate(),
+	}
+	rb.PreviousTransform = rb.Transform
+	rb.Shape.ComputeAABB(rb.Transform)
+	rb.InvalidateInertia()
+}
+
+// CenterOfMass returns the offset of the center of mass set by SetCenterOfMass, in the local space of the shape
+func (rb *RigidBody) CenterOfMass() mgl64.Vec3 {
+	return rb.centerOfMass
+}
+
+// SetCenterOfMass moves the center of mass to offset, in the local space of the shape as it was created
+// (its own center of mass is at the origin), e.g. lowered for a vehicle that does not roll over.
+// Transform is the pose of the center of mass, so the solvers and the integration rotate the body about it:
+// the shape is wrapped into a Compound, unless it is one, whose children are translated by -offset.
+// The shape and the body origin stay in place, Transform and the ShapeOffset move. The inertia is kept, see SetInertia.
+// Returns ErrCenterOfMassShape for a plane or a triangle mesh.
+func (rb *RigidBody) SetCenterOfMass(offset mgl64.Vec3) error {
+	delta := offset.Sub(rb.centerOfMass)
+	if delta == (mgl64.Vec3{}) {
+		return nil
+	}
+
+	compound, ok := rb.Shape.(*Compound)
+	if !ok {
+		var err error
+		if compound, err = NewCompound([]CompoundChild{{Shape: rb.Shape}}); err != nil {
+			return ErrCenterOfMassShape
+		}
+		rb.Shape = compound
+	}
+	compound.shiftCenterOfMass(delta)
+	rb.centerOfMass = offset
+
+	rb.moveCenterOfMass(delta)
+	rb.Shape.ComputeAABB(rb.Transform)
+	rb.WakeUp()
+
+	return nil
+}
+
+// moveCenterOfMass moves the body by delta in its local space, and its shape offset along:
+// the shape, whose origin moved by delta, and the body origin stay in place in the world
+func (rb *RigidBody) moveCenterOfMass(delta mgl64.Vec3) {
+	if delta == (mgl64.Vec3{}) {
+		return
+	}
+
+	shapeOffset := rb.ShapeOffset()
+	rb.SetShapeOffset(shapeOffset.Position.Add(shapeOffset.Rotation.Rotate(delta)), shapeOffset.Rotation)
+	rb.Transform.Position = rb.Transform.Position.Add(rb.Transform.Rotation.Rotate(delta))
+	rb.PreviousTransform.Position = rb.PreviousTransform.Position.Add(rb.PreviousTransform.Rotation.Rotate(delta))
+}
+
+// MassOverride returns the mass set by SetMass, 0 if the mass is derived from the shape and the density
+func (rb *RigidBody) MassOverride() float64 {
+	return rb.massOverride
+}
+
+// SetMass sets an explicit mass (kg) instead of the one derived from the shape and Material.Density,
+// kept when the mass data is computed again (e.g. by SetBodyType). The inertia derived from the shape scales with it.
+// 0 restores the derived mass. Static bodies keep an infinite mass. The body is woken up.
+func (rb *RigidBody) SetMass(mass float64) error {
+	if !(mass >= 0) || math.IsInf(mass, 1) {
+		return ErrInvalidMass
+	}
+	if mass == 0 && rb.BodyType == BodyTypeDynamic && rb.Material.Density <= 0 {
+		return ErrDynamicWithoutDensity
+	}
+
+	rb.massOverride = mass
+	rb.computeMassData()
+	rb.WakeUp()
+
+	return nil
+}
+
+// InertiaOverride returns the tensor set by SetInertia, the zero matrix if the inertia is derived from the shape
+func (rb *RigidBody) InertiaOverride() mgl64.Mat3 {
+	return rb.inertiaOverride
+}
+
+// SetInertia sets an explicit inertia tensor (kg⋅m²) about the center of mass, in the local space of the body,
+// instead of the one derived from the shape and the mass. The zero matrix restores the derived inertia.
+// Static bodies keep an infinite inertia. The body is woken up.
+func (rb *RigidBody) SetInertia(inertia mgl64.Mat3) error {
+	if inertia != (mgl64.Mat3{}) && !isPositiveDefinite(inertia) {
+		return ErrInvalidInertia
+	}
+
+	rb.inertiaOverride = inertia
+	rb.computeMassData()
+	rb.WakeUp()
+
+	return nil
+}
+
+// isPositiveDefinite returns true for a symmetric matrix whose leading principal minors are positive (Sylvester)
+func isPositiveDefinite(m mgl64.Mat3) bool {
+	const epsilon = 1e-12
+	for i := range 3 {
+		for j := i + 1; j < 3; j++ {
+			if math.Abs(m.At(i, j)-m.At(j, i)) > epsilon*math.Max(1, math.Abs(m.At(i, j))) {
+				return false
+			}
+		}
+	}
+
+	return m.At(0, 0) > 0 && m.At(0, 0)*m.At(1, 1)-m.At(0, 1)*m.At(1, 0) > 0 && m.Det() > 0
+}
+
+// inertiaForm is the structure of the local inertia tensor, detected when the mass data is computed
+type inertiaForm uint8
+
+const (
+	// inertiaGeneral is the full R * I * R^T product, the zero value for the bodies not built by NewRigidBody
+	inertiaGeneral inertiaForm = iota
+	// inertiaDiagonal only has principal moments (e.g. boxes, cones): R * D * R^T is summed from the diagonal
+	inertiaDiagonal
+	// inertiaIsotropic has equal principal moments (e.g. spheres, cubes): the tensor is invariant by rotation
+	inertiaIsotropic
+)
+
+// classifyInertia returns the form shared by the inertia tensor and its inverse
+func classifyInertia(inertia, inverse mgl64.Mat3) inertiaForm {
+	form := inertiaIsotropic
+	for _, m := range []mgl64.Mat3{inertia, inverse} {
+		if m.At(0, 1) != 0 || m.At(0, 2) != 0 || m.At(1, 0) != 0 || m.At(1, 2) != 0 || m.At(2, 0) != 0 || m.At(2, 1) != 0 {
+			return inertiaGeneral
+		}
+		if m.At(0, 0) != m.At(1, 1) || m.At(0, 0) != m.At(2, 2) {
+			form = inertiaDiagonal
+		}
+	}
+
+	return form
+}
+
+// rotateDiagonal returns R * D * R^T for the diagonal matrix D, the matrix is symmetric
+func rotateDiagonal(rotation mgl64.Quat, diagonal mgl64.Mat3) mgl64.Mat3 {
+	R := rotation.Mat4().Mat3()
+	d := mgl64.Vec3{diagonal.At(0, 0), diagonal.At(1, 1), diagonal.At(2, 2)}
+
+	var m mgl64.Mat3
+	for i := 0; i < 3; i++ {
+		for j := i; j < 3; j++ {
+			value := R.At(i, 0)*d[0]*R.At(j, 0) + R.At(i, 1)*d[1]*R.At(j, 1) + R.At(i, 2)*d[2]*R.At(j, 2)
+			m.Set(i, j, value)
+			m.Set(j, i, value)
+		}
+	}
+
+	return m
+}
+
 // Inertie en espace monde
+// Isotropic and diagonal tensors skip the full matrix product, see InvalidateInertia
 func (rb *RigidBody) GetInertiaWorld() mgl64.Mat3 {
+	switch rb.inertiaForm {
+	case inertiaIsotropic:
+		return rb.InertiaLocal
+	case inertiaDiagonal:
+		return rotateDiagonal(rb.Transform.Rotation, rb.InertiaLocal)
+	}
+
 	// I_world = R * I_local * R^T
 	R := rb.Transform.Rotation.Mat4().Mat3()
 	return R.Mul3(rb.InertiaLocal).Mul3(R.Transpose())
 }
 
 // Inverse de l'inertie en espace monde
+// The contact solver queries it many times per substep, the result is cached until the rotation changes.
+// InertiaLocal and InverseInertiaLocal modifications must be followed by InvalidateInertia.
+// The rows and columns of the locked angular axes are zeroed, see SetAxisLocks: the solvers cannot rotate the body about them.
 func (rb *RigidBody) GetInverseInertiaWorld() mgl64.Mat3 {
 	if rb.BodyType == BodyTypeStatic {
 		return mgl64.Mat3{0, 0, 0, 0, 0, 0, 0, 0, 0}
 	}
+	if rb.angularLock != 0 {
+		return rb.angularLock.projectMatrix(rb.rotatedInverseInertia())
+	}
 
-	// I_world^(-1) = R * I_local^(-1) * R^T
-	R := rb.Transform.Rotation.Mat4().Mat3()
-	return R.Mul3(rb.InverseInertiaLocal).Mul3(R.Transpose())
+	return rb.rotatedInverseInertia()
+}
+
+// rotatedInverseInertia returns R * I_local^(-1) * R^T, cached until the rotation changes
+func (rb *RigidBody) rotatedInverseInertia() mgl64.Mat3 {
+	if rb.inertiaForm == inertiaIsotropic {
+		return rb.InverseInertiaLocal
+	}
+
+	if rb.inverseInertiaCached && rb.inverseInertiaRotation == rb.Transform.Rotation {
+		return rb.inverseInertiaWorld
+	}
+
+	if rb.inertiaForm == inertiaDiagonal {
+		rb.inverseInertiaWorld = rotateDiagonal(rb.Transform.Rotation, rb.InverseInertiaLocal)
+	} else {
+		// I_world^(-1) = R * I_local^(-1) * R^T
+		R := rb.Transform.Rotation.Mat4().Mat3()
+		rb.inverseInertiaWorld = R.Mul3(rb.InverseInertiaLocal).Mul3(R.Transpose())
+	}
+	rb.inverseInertiaRotation = rb.Transform.Rotation
+	rb.inverseInertiaCached = true
+
+	return rb.inverseInertiaWorld
+}
+
+// InvalidateInertia discards the cached world inertia, after InertiaLocal or InverseInertiaLocal was modified.
+// The form of the tensors (isotropic, diagonal or general) is detected again for the fast paths.
+func (rb *RigidBody) InvalidateInertia() {
+	rb.inverseInertiaCached = false
+	rb.inertiaForm = classifyInertia(rb.InertiaLocal, rb.InverseInertiaLocal)
 }
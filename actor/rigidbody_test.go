@@ -1,7 +1,9 @@
 package actor
 
 import (
+	"errors"
 	"math"
+	"strings"
 	"testing"
 
 	"github.com/go-gl/mathgl/mgl64"
@@ -76,6 +78,30 @@ func TestMaterial_GetMass(t *testing.T) {
 	}
 }
 
+func TestRestitutionCurve_Scale(t *testing.T) {
+	tests := []struct {
+		name  string
+		curve RestitutionCurve
+		speed float64
+		want  float64
+	}{
+		{name: "zero curve", curve: RestitutionCurve{}, speed: 0, want: 1},
+		{name: "under MinSpeed", curve: RestitutionCurve{MinSpeed: 1, MaxSpeed: 3}, speed: 0.5, want: 0},
+		{name: "at MinSpeed", curve: RestitutionCurve{MinSpeed: 1, MaxSpeed: 3}, speed: 1, want: 0},
+		{name: "halfway", curve: RestitutionCurve{MinSpeed: 1, MaxSpeed: 3}, speed: 2, want: 0.5},
+		{name: "above MaxSpeed", curve: RestitutionCurve{MinSpeed: 1, MaxSpeed: 3}, speed: 10, want: 1},
+		{name: "step", curve: RestitutionCurve{MinSpeed: 2, MaxSpeed: 2}, speed: 2, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if scale := tt.curve.Scale(tt.speed); math.Abs(scale-tt.want) > 1e-12 {
+				t.Errorf("Scale(%v) = %v, want %v", tt.speed, scale, tt.want)
+			}
+		})
+	}
+}
+
 // =============================================================================
 // NewRigidBody Tests
 // =============================================================================
@@ -206,6 +232,146 @@ func TestNewRigidBody_DifferentShapes(t *testing.T) {
 	}
 }
 
+func TestNewRigidBodyWithMass(t *testing.T) {
+	transform := NewTransform()
+
+	tests := []struct {
+		name  string
+		shape ShapeInterface
+		mass  float64
+	}{
+		{"sphere", &Sphere{Radius: 0.3}, 0.43},
+		{"box", &Box{HalfExtents: mgl64.Vec3{0.5, 1, 2}}, 80},
+		{"thin plank", &Box{HalfExtents: mgl64.Vec3{2, 0.01, 0.2}}, 3.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rb := NewRigidBodyWithMass(transform, tt.shape, BodyTypeDynamic, tt.mass)
+
+			if !almostEqual(rb.Material.GetMass(), tt.mass, 1e-9) {
+				t.Errorf("Mass = %v, want %v", rb.Material.GetMass(), tt.mass)
+			}
+			if !almostEqual(tt.shape.ComputeMass(rb.Material.Density), tt.mass, 1e-9) {
+				t.Errorf("Density %v does not match the requested mass", rb.Material.Density)
+			}
+		})
+	}
+}
+
+func TestNewRigidBodyWithMass_Static(t *testing.T) {
+	rb := NewRigidBodyWithMass(NewTransform(), &Box{HalfExtents: mgl64.Vec3{1, 1, 1}}, BodyTypeStatic, 50)
+
+	if !math.IsInf(rb.Material.GetMass(), 1) {
+		t.Errorf("Static body mass = %v, want +Inf", rb.Material.GetMass())
+	}
+}
+
+func TestNewRigidBodyWithMass_Plane(t *testing.T) {
+	rb := NewRigidBodyWithMass(NewTransform(), &Plane{Normal: mgl64.Vec3{0, 1, 0}}, BodyTypeDynamic, 50)
+
+	if rb.Material.Density != 0 {
+		t.Errorf("Plane density = %v, want 0", rb.Material.Density)
+	}
+}
+
+func TestSetMass(t *testing.T) {
+	rb := NewRigidBody(NewTransform(), &Box{HalfExtents: mgl64.Vec3{1, 1, 1}}, BodyTypeDynamic, 1.0)
+	derived := rb.InertiaLocal
+
+	if err := rb.SetMass(16); err != nil {
+		t.Fatalf("SetMass failed: %v", err)
+	}
+	if rb.Material.GetMass() != 16 || !almostEqual(rb.InertiaLocal.At(0, 0), derived.At(0, 0)*2, 1e-9) {
+		t.Errorf("mass %v, inertia %v, want 16 and the derived inertia scaled", rb.Material.GetMass(), rb.InertiaLocal)
+	}
+
+	// The override survives the recomputation of the mass data, and allows a dynamic body without density
+	rb.Material.Density = 0
+	if err := rb.SetBodyType(BodyTypeStatic); err != nil {
+		t.Fatal(err)
+	}
+	if err := rb.SetBodyType(BodyTypeDynamic); err != nil || rb.Material.GetMass() != 16 {
+		t.Errorf("SetBodyType = %v, mass %v, want the override kept", err, rb.Material.GetMass())
+	}
+
+	for _, mass := range []float64{-1, math.NaN(), math.Inf(1)} {
+		if err := rb.SetMass(mass); !errors.Is(err, ErrInvalidMass) {
+			t.Errorf("SetMass(%v) = %v, want ErrInvalidMass", mass, err)
+		}
+	}
+	if err := rb.SetMass(0); !errors.Is(err, ErrDynamicWithoutDensity) {
+		t.Errorf("SetMass(0) = %v, want ErrDynamicWithoutDensity without density", err)
+	}
+}
+
+func TestSetInertia(t *testing.T) {
+	rb := NewRigidBody(NewTransform(), &Sphere{Radius: 1}, BodyTypeDynamic, 1.0)
+	derived := rb.InertiaLocal
+
+	inertia := mgl64.Diag3(mgl64.Vec3{1, 2, 3})
+	if err := rb.SetInertia(inertia); err != nil {
+		t.Fatalf("SetInertia failed: %v", err)
+	}
+	if rb.InertiaLocal != inertia || !almostEqual(rb.GetInverseInertiaWorld().At(2, 2), 1.0/3.0, 1e-12) {
+		t.Errorf("inertia %v, want the override", rb.InertiaLocal)
+	}
+	if err := rb.SetMass(10); err != nil || rb.InertiaLocal != inertia {
+		t.Errorf("inertia %v, want the override kept by SetMass", rb.InertiaLocal)
+	}
+
+	for _, invalid := range []mgl64.Mat3{mgl64.Diag3(mgl64.Vec3{1, -1, 1}), {1, 2, 0, 0, 1, 0, 0, 0, 1}} {
+		if err := rb.SetInertia(invalid); !errors.Is(err, ErrInvalidInertia) {
+			t.Errorf("SetInertia(%v) = %v, want ErrInvalidInertia", invalid, err)
+		}
+	}
+
+	if err := rb.SetMass(0); err != nil {
+		t.Fatal(err)
+	}
+	if err := rb.SetInertia(mgl64.Mat3{}); err != nil || rb.InertiaLocal != derived {
+		t.Errorf("inertia %v, want the derived inertia %v restored", rb.InertiaLocal, derived)
+	}
+}
+
+func TestSetCenterOfMass(t *testing.T) {
+	box := &Box{HalfExtents: mgl64.Vec3{1, 0.5, 1}}
+	transform := Transform{Position: mgl64.Vec3{0, 2, 0}, Rotation: mgl64.QuatRotate(math.Pi/2, mgl64.Vec3{0, 0, 1})}
+	transform.InverseRotation = transform.Rotation.Inverse()
+	rb := NewRigidBody(transform, box, BodyTypeDynamic, 1.0)
+	mass, inertia := rb.Material.GetMass(), rb.InertiaLocal
+	origin := rb.OriginTransform()
+	aabb := rb.Shape.GetAABB()
+
+	if err := rb.SetCenterOfMass(mgl64.Vec3{0, -0.4, 0}); err != nil {
+		t.Fatalf("SetCenterOfMass failed: %v", err)
+	}
+
+	// Transform is the pose of the center of mass, the shape and the body origin stay in place
+	if !vec3AlmostEqual(rb.Transform.Position, mgl64.Vec3{0.4, 2, 0}, 1e-9) {
+		t.Errorf("center of mass at %v, want {0.4 2 0}", rb.Transform.Position)
+	}
+	if !vec3AlmostEqual(rb.OriginTransform().Position, origin.Position, 1e-9) {
+		t.Errorf("origin at %v, want %v", rb.OriginTransform().Position, origin.Position)
+	}
+	if got := rb.Shape.GetAABB(); !vec3AlmostEqual(got.Min, aabb.Min, 1e-9) || !vec3AlmostEqual(got.Max, aabb.Max, 1e-9) {
+		t.Errorf("AABB %v, want %v", got, aabb)
+	}
+	if _, ok := rb.Shape.(*Compound); !ok || rb.Material.GetMass() != mass || rb.InertiaLocal != inertia {
+		t.Errorf("shape %T, mass %v, want the box wrapped into a compound with the same mass data", rb.Shape, rb.Material.GetMass())
+	}
+
+	// The offset is absolute: setting it back restores the center of the box
+	if err := rb.SetCenterOfMass(mgl64.Vec3{}); err != nil || !vec3AlmostEqual(rb.Transform.Position, transform.Position, 1e-9) {
+		t.Errorf("center of mass at %v, want %v", rb.Transform.Position, transform.Position)
+	}
+
+	plane := NewRigidBody(NewTransform(), &Plane{Normal: mgl64.Vec3{0, 1, 0}}, BodyTypeStatic, 0)
+	if err := plane.SetCenterOfMass(mgl64.Vec3{0, 1, 0}); !errors.Is(err, ErrCenterOfMassShape) {
+		t.Errorf("SetCenterOfMass on a plane = %v, want ErrCenterOfMassShape", err)
+	}
+}
+
 // =============================================================================
 // Integrate Tests
 // =============================================================================
@@ -1546,6 +1712,41 @@ func TestIntegrate_HighAngularVelocity(t *testing.T) {
 	}
 }
 
+// TestIntegrate_MaxRotationPerStep verifies the rotation of a single step is clamped
+func TestIntegrate_MaxRotationPerStep(t *testing.T) {
+	rb := NewRigidBody(NewTransform(), &Box{HalfExtents: mgl64.Vec3{1, 1, 1}}, BodyTypeDynamic, 1.0)
+
+	// 3 rad in a single step would alias with a quarter turn of the box
+	rb.AngularVelocity = mgl64.Vec3{0, 300, 0}
+	dt := 0.01
+	rb.Integrate(dt, mgl64.Vec3{})
+
+	angle := 2 * math.Acos(math.Min(1, math.Abs(rb.Transform.Rotation.W)))
+	// The first order quaternion update slightly undershoots the exact rotation
+	if !almostEqual(angle, MaxRotationPerStep, 1e-2) {
+		t.Errorf("rotation = %v, want clamped to %v", angle, MaxRotationPerStep)
+	}
+
+	rb.Update(dt)
+	if !vec3AlmostEqual(rb.AngularVelocity, mgl64.Vec3{0, angle / dt, 0}, 1e-6) {
+		t.Errorf("AngularVelocity = %v, want %v", rb.AngularVelocity, mgl64.Vec3{0, angle / dt, 0})
+	}
+}
+
+// TestIntegrate_SubdividedRotation verifies large rotations under the limit stay accurate
+func TestIntegrate_SubdividedRotation(t *testing.T) {
+	rb := NewRigidBody(NewTransform(), &Sphere{Radius: 1.0}, BodyTypeDynamic, 1.0)
+
+	rb.AngularVelocity = mgl64.Vec3{100, 0, 0}
+	dt := 0.01
+	rb.Integrate(dt, mgl64.Vec3{})
+
+	expected := mgl64.QuatRotate(1.0, mgl64.Vec3{1, 0, 0})
+	if !rb.Transform.Rotation.ApproxEqualThreshold(expected, 1e-2) {
+		t.Errorf("Rotation = %v, want %v", rb.Transform.Rotation, expected)
+	}
+}
+
 // =============================================================================
 // PHASE 7: Mathematical Consistency Tests
 // =============================================================================
@@ -1855,3 +2056,845 @@ func quatAlmostEqual(a, b mgl64.Quat, epsilon float64) bool {
 		almostEqual(a.V.Y(), b.V.Y(), epsilon) &&
 		almostEqual(a.V.Z(), b.V.Z(), epsilon)
 }
+
+// TestClosestPointWorld_RotatedBox verifies the query point is moved to local space and back
+func TestClosestPointWorld_RotatedBox(t *testing.T) {
+	transform := NewTransform()
+	transform.Position = mgl64.Vec3{10, 0, 0}
+	transform.Rotation = mgl64.QuatRotate(math.Pi/2, mgl64.Vec3{0, 0, 1})
+	rb := NewRigidBody(transform, &Box{HalfExtents: mgl64.Vec3{3, 1, 1}}, BodyTypeDynamic, 1.0)
+
+	// The long local X axis is aligned with world Y after rotation
+	closest := rb.ClosestPointWorld(mgl64.Vec3{10, 10, 0})
+	if !vec3AlmostEqual(closest, mgl64.Vec3{10, 3, 0}, 1e-9) {
+		t.Errorf("ClosestPointWorld = %v, want {10 3 0}", closest)
+	}
+
+	closest = rb.ClosestPointWorld(mgl64.Vec3{20, 0, 0})
+	if !vec3AlmostEqual(closest, mgl64.Vec3{11, 0, 0}, 1e-9) {
+		t.Errorf("ClosestPointWorld = %v, want {11 0 0}", closest)
+	}
+}
+
+// =============================================================================
+// Sleep Tests
+// =============================================================================
+
+func TestTrySleep_FallsAsleepAfterTimeThreshold(t *testing.T) {
+	rb := NewRigidBody(NewTransform(), &Sphere{Radius: 1}, BodyTypeDynamic, 1.0)
+	rb.Velocity = mgl64.Vec3{0.01, 0, 0}
+
+	for i := 0; i < 3; i++ {
+		if result := rb.TrySleep(0.0625, SleepThresholds{0.25, 0.05, 0.05, 0.15, 0.15}); result != 0 {
+			t.Fatalf("step %d: TrySleep = %d, want 0 before the time threshold", i, result)
+		}
+	}
+	if result := rb.TrySleep(0.0625, SleepThresholds{0.25, 0.05, 0.05, 0.15, 0.15}); result != 1 || !rb.IsSleeping {
+		t.Fatalf("TrySleep = %d, want 1 once the time threshold is reached", result)
+	}
+	if rb.Velocity.Len() != 0 {
+		t.Errorf("sleeping body velocity = %v, want zero", rb.Velocity)
+	}
+}
+
+func TestTrySleep_MovingBodyResetsTimer(t *testing.T) {
+	rb := NewRigidBody(NewTransform(), &Sphere{Radius: 1}, BodyTypeDynamic, 1.0)
+
+	rb.TrySleep(0.05, SleepThresholds{0.1, 0.05, 0.05, 0.15, 0.15})
+	rb.Velocity = mgl64.Vec3{0, 1, 0}
+	rb.TrySleep(0.05, SleepThresholds{0.1, 0.05, 0.05, 0.15, 0.15})
+	rb.Velocity = mgl64.Vec3{}
+
+	if result := rb.TrySleep(0.05, SleepThresholds{0.1, 0.05, 0.05, 0.15, 0.15}); result != 0 || rb.IsSleeping {
+		t.Error("timer should restart after the body moved")
+	}
+}
+
+func TestTrySleep_Hysteresis(t *testing.T) {
+	rb := NewRigidBody(NewTransform(), &Sphere{Radius: 1}, BodyTypeDynamic, 1.0)
+	rb.Sleep()
+
+	// Solver noise between the sleep and wake thresholds is discarded
+	rb.Velocity = mgl64.Vec3{0.1, 0, 0}
+	rb.AngularVelocity = mgl64.Vec3{0, 0.1, 0}
+	if result := rb.TrySleep(0.01, SleepThresholds{0.1, 0.05, 0.05, 0.15, 0.15}); result != 0 || !rb.IsSleeping {
+		t.Fatalf("TrySleep = %d, small velocities should not wake the body", result)
+	}
+	if rb.Velocity.Len() != 0 || rb.AngularVelocity.Len() != 0 {
+		t.Errorf("discarded velocities should be zeroed, got %v %v", rb.Velocity, rb.AngularVelocity)
+	}
+
+	// A real impulse wakes it up
+	rb.Velocity = mgl64.Vec3{0.5, 0, 0}
+	if result := rb.TrySleep(0.01, SleepThresholds{0.1, 0.05, 0.05, 0.15, 0.15}); result != 2 || rb.IsSleeping {
+		t.Errorf("TrySleep = %d, want 2 when pushed above the wake threshold", result)
+	}
+}
+
+func TestTrySleep_AngularThreshold(t *testing.T) {
+	rb := NewRigidBody(NewTransform(), &Sphere{Radius: 1}, BodyTypeDynamic, 1.0)
+	rb.AngularVelocity = mgl64.Vec3{0, 0.1, 0}
+
+	// A slow spin under the linear threshold keeps the body awake with a lower angular threshold
+	strict := SleepThresholds{Time: 0.1, LinearVelocity: 0.5, AngularVelocity: 0.05, WakeLinearVelocity: 1, WakeAngularVelocity: 0.15}
+	for i := 0; i < 5; i++ {
+		if result := rb.TrySleep(0.05, strict); result != 0 {
+			t.Fatalf("step %d: TrySleep = %d, want 0 while spinning above AngularVelocity", i, result)
+		}
+	}
+
+	loose := strict
+	loose.AngularVelocity = 0.2
+	rb.TrySleep(0.05, loose)
+	if result := rb.TrySleep(0.05, loose); result != 1 {
+		t.Errorf("TrySleep = %d, want 1 under AngularVelocity", result)
+	}
+}
+
+func TestRigidBody_SleepWakeUpResetTimer(t *testing.T) {
+	rb := NewRigidBody(NewTransform(), &Sphere{Radius: 1}, BodyTypeDynamic, 1.0)
+	rb.SleepTimer = 0.3
+	rb.Velocity = mgl64.Vec3{1, 0, 0}
+
+	rb.Sleep()
+	if !rb.IsSleeping || rb.SleepTimer != 0 || rb.Velocity.Len() != 0 {
+		t.Fatalf("after Sleep: sleeping %v, timer %v, velocity %v, want asleep and reset", rb.IsSleeping, rb.SleepTimer, rb.Velocity)
+	}
+
+	rb.SleepTimer = 0.3
+	rb.WakeUp()
+	if rb.IsSleeping || rb.SleepTimer != 0 {
+		t.Errorf("after WakeUp: sleeping %v, timer %v, want awake and reset", rb.IsSleeping, rb.SleepTimer)
+	}
+}
+
+func TestTrySleep_StaticNeverSleeps(t *testing.T) {
+	rb := NewRigidBody(NewTransform(), &Box{HalfExtents: mgl64.Vec3{1, 1, 1}}, BodyTypeStatic, 1.0)
+
+	for i := 0; i < 10; i++ {
+		if result := rb.TrySleep(0.1, SleepThresholds{0.25, 0.05, 0.05, 0.15, 0.15}); result != 0 {
+			t.Fatalf("step %d: TrySleep = %d, want 0 for a static body", i, result)
+		}
+	}
+	rb.Sleep()
+	if rb.IsSleeping {
+		t.Error("static bodies should never sleep")
+	}
+}
+
+// =============================================================================
+// Body Flags Tests
+// =============================================================================
+
+func TestValidate(t *testing.T) {
+	dynamic := NewRigidBody(NewTransform(), &Sphere{Radius: 1}, BodyTypeDynamic, 1.0)
+	dynamic.IsTrigger = true
+	dynamic.IsSleeping = true
+	if err := dynamic.Validate(); err != nil {
+		t.Errorf("sleeping dynamic trigger should be valid, got %v", err)
+	}
+
+	static := NewRigidBody(NewTransform(), &Sphere{Radius: 1}, BodyTypeStatic, 1.0)
+	static.IsTrigger = true
+	if err := static.Validate(); err != nil {
+		t.Errorf("static trigger should be valid, got %v", err)
+	}
+	static.IsSleeping = true
+	if err := static.Validate(); !errors.Is(err, ErrStaticSleeping) {
+		t.Errorf("Validate = %v, want ErrStaticSleeping", err)
+	}
+
+	invalid := NewRigidBody(NewTransform(), &Sphere{Radius: 1}, BodyType(42), 1.0)
+	if err := invalid.Validate(); !errors.Is(err, ErrInvalidBodyType) {
+		t.Errorf("Validate = %v, want ErrInvalidBodyType", err)
+	}
+
+	static.Name = "ground"
+	err := static.Validate()
+	if !errors.Is(err, ErrStaticSleeping) || !strings.Contains(err.Error(), `"ground"`) {
+		t.Errorf("Validate = %v, want ErrStaticSleeping naming the body", err)
+	}
+}
+
+func TestLabel(t *testing.T) {
+	body := NewRigidBody(NewTransform(), &Sphere{Radius: 1}, BodyTypeDynamic, 1.0)
+	if label := body.Label(); label != "unnamed body" {
+		t.Errorf("Label = %q, want unnamed body", label)
+	}
+	body.Id = 412
+	if label := body.Label(); label != "412" {
+		t.Errorf("Label = %q, want the Id", label)
+	}
+	body.Name = "crate_stack_07"
+	if label := body.Label(); label != "crate_stack_07" {
+		t.Errorf("Label = %q, want the Name", label)
+	}
+}
+
+func TestIsActive(t *testing.T) {
+	rb := NewRigidBody(NewTransform(), &Sphere{Radius: 1}, BodyTypeDynamic, 1.0)
+	if !rb.IsActive() {
+		t.Error("awake dynamic body should be active")
+	}
+
+	rb.Sleep()
+	if rb.IsActive() {
+		t.Error("sleeping body should be inactive")
+	}
+
+	static := NewRigidBody(NewTransform(), &Sphere{Radius: 1}, BodyTypeStatic, 1.0)
+	if static.IsActive() {
+		t.Error("static body should be inactive")
+	}
+}
+
+func TestSetBodyType(t *testing.T) {
+	rb := NewRigidBody(NewTransform(), &Box{HalfExtents: mgl64.Vec3{0.5, 0.5, 0.5}}, BodyTypeDynamic, 2.0)
+	rb.Velocity = mgl64.Vec3{1, 0, 0}
+	rb.AngularVelocity = mgl64.Vec3{0, 1, 0}
+
+	if err := rb.SetBodyType(BodyTypeStatic); err != nil {
+		t.Fatalf("SetBodyType(static) = %v", err)
+	}
+	if !math.IsInf(rb.Material.GetMass(), 1) {
+		t.Errorf("static mass = %v, want +Inf", rb.Material.GetMass())
+	}
+	if rb.Velocity.Len() != 0 || rb.AngularVelocity.Len() != 0 {
+		t.Errorf("velocities should be reset, got %v %v", rb.Velocity, rb.AngularVelocity)
+	}
+	if rb.GetInverseInertiaWorld() != (mgl64.Mat3{}) {
+		t.Errorf("static inverse inertia = %v, want zero", rb.GetInverseInertiaWorld())
+	}
+
+	if err := rb.SetBodyType(BodyTypeDynamic); err != nil {
+		t.Fatalf("SetBodyType(dynamic) = %v", err)
+	}
+	if !almostEqual(rb.Material.GetMass(), 2.0, 1e-9) {
+		t.Errorf("dynamic mass = %v, want 2", rb.Material.GetMass())
+	}
+
+	if err := rb.SetBodyType(BodyType(42)); !errors.Is(err, ErrInvalidBodyType) {
+		t.Errorf("SetBodyType = %v, want ErrInvalidBodyType", err)
+	}
+	if rb.BodyType != BodyTypeDynamic {
+		t.Error("an invalid body type should leave the body unchanged")
+	}
+}
+
+func TestSetBodyType_StaticWithoutDensity(t *testing.T) {
+	rb := NewRigidBody(NewTransform(), &Sphere{Radius: 1}, BodyTypeStatic, 1.0)
+
+	if err := rb.SetBodyType(BodyTypeDynamic); !errors.Is(err, ErrDynamicWithoutDensity) {
+		t.Fatalf("SetBodyType = %v, want ErrDynamicWithoutDensity", err)
+	}
+
+	rb.Material.Density = 1.0
+	if err := rb.SetBodyType(BodyTypeDynamic); err != nil {
+		t.Fatalf("SetBodyType = %v", err)
+	}
+	if rb.Material.GetMass() <= 0 || math.IsInf(rb.Material.GetMass(), 1) {
+		t.Errorf("dynamic mass = %v, want finite and positive", rb.Material.GetMass())
+	}
+}
+
+func TestSetTrigger_WakesUp(t *testing.T) {
+	rb := NewRigidBody(NewTransform(), &Sphere{Radius: 1}, BodyTypeDynamic, 1.0)
+	rb.Sleep()
+
+	rb.SetTrigger(true)
+	if !rb.IsTrigger || rb.IsSleeping {
+		t.Errorf("IsTrigger = %v, IsSleeping = %v, want a woken up trigger", rb.IsTrigger, rb.IsSleeping)
+	}
+}
+
+func TestSetEnabled(t *testing.T) {
+	rb := NewRigidBody(NewTransform(), &Sphere{Radius: 1}, BodyTypeDynamic, 1.0)
+	if !rb.IsEnabled() || !rb.IsActive() {
+		t.Fatal("a new body should be enabled and active")
+	}
+
+	// Disabled, the body keeps its state: it is neither integrated nor set to sleep
+	rb.SetEnabled(false)
+	rb.Velocity = mgl64.Vec3{1, 0, 0}
+	rb.Integrate(0.5, mgl64.Vec3{0, -9.81, 0})
+	rb.Update(0.5)
+	if rb.IsActive() || rb.Transform.Position != (mgl64.Vec3{}) || rb.Velocity != (mgl64.Vec3{1, 0, 0}) {
+		t.Errorf("disabled body at %v moving at %v, want its state kept", rb.Transform.Position, rb.Velocity)
+	}
+	rb.Velocity = mgl64.Vec3{}
+	if rb.TrySleep(10, SleepThresholds{LinearVelocity: 1, AngularVelocity: 1, Time: 1}) != 0 || rb.IsSleeping {
+		t.Error("a disabled body should not fall asleep")
+	}
+
+	rb.SetEnabled(true)
+	if !rb.IsEnabled() || !rb.IsActive() {
+		t.Error("the body should be enabled back")
+	}
+}
+
+func TestSetDeferred(t *testing.T) {
+	rb := NewRigidBody(NewTransform(), &Sphere{Radius: 1}, BodyTypeDynamic, 1.0)
+
+	// Deferred, the body is frozen with its velocities, and does not fall asleep
+	rb.SetDeferred(true)
+	rb.Velocity = mgl64.Vec3{0.01, 0, 0}
+	rb.Integrate(0.5, mgl64.Vec3{0, -9.81, 0})
+	rb.Update(0.5)
+	if rb.IsActive() || !rb.IsEnabled() || rb.Transform.Position != (mgl64.Vec3{}) || rb.Velocity != (mgl64.Vec3{0.01, 0, 0}) {
+		t.Errorf("deferred body at %v moving at %v, want its state kept", rb.Transform.Position, rb.Velocity)
+	}
+	if rb.TrySleep(10, SleepThresholds{LinearVelocity: 1, AngularVelocity: 1, Time: 1}) != 0 || rb.IsSleeping {
+		t.Error("a deferred body should not fall asleep")
+	}
+
+	rb.SetDeferred(false)
+	if rb.IsDeferred() || !rb.IsActive() {
+		t.Error("the body should be released")
+	}
+}
+
+func TestSetAxisLocks(t *testing.T) {
+	rb := NewRigidBody(NewTransform(), &Box{HalfExtents: mgl64.Vec3{1, 0.5, 0.25}}, BodyTypeDynamic, 1.0)
+	rb.Velocity = mgl64.Vec3{1, 1, 1}
+	rb.AngularVelocity = mgl64.Vec3{1, 1, 1}
+	rb.SetAxisLocks(LockY, LockX|LockZ)
+	if rb.Velocity != (mgl64.Vec3{1, 0, 1}) || rb.AngularVelocity != (mgl64.Vec3{0, 1, 0}) {
+		t.Fatalf("velocities %v and %v, want the locked axes removed", rb.Velocity, rb.AngularVelocity)
+	}
+
+	// Gravity and impulses do not move the body along the locked axes
+	rb.ApplyImpulse(mgl64.Vec3{0, 5, 0})
+	rb.Integrate(0.1, mgl64.Vec3{0, -9.81, 0})
+	if rb.Transform.Position.Y() != 0 || rb.Velocity.Y() != 0 {
+		t.Errorf("position %v, velocity %v, want no motion along Y", rb.Transform.Position, rb.Velocity)
+	}
+
+	// The solvers see no inertia about the locked axes
+	inverse := rb.GetInverseInertiaWorld()
+	for i := range 3 {
+		if inverse.At(0, i) != 0 || inverse.At(i, 0) != 0 || inverse.At(2, i) != 0 || inverse.At(i, 2) != 0 {
+			t.Fatalf("inverse inertia %v, want the X and Z rows and columns zeroed", inverse)
+		}
+	}
+	if inverse.At(1, 1) <= 0 {
+		t.Errorf("inverse inertia %v, want the rotation about Y free", inverse)
+	}
+
+	// A correction along a locked axis is discarded when the velocities are derived
+	rb.Transform.Position = rb.Transform.Position.Add(mgl64.Vec3{0, 0.5, 0})
+	rb.Update(0.1)
+	if rb.Transform.Position.Y() != 0 || rb.Velocity.Y() != 0 {
+		t.Errorf("position %v, velocity %v, want the correction along Y discarded", rb.Transform.Position, rb.Velocity)
+	}
+}
+
+func TestClampVelocity(t *testing.T) {
+	rb := NewRigidBody(NewTransform(), &Sphere{Radius: 1}, BodyTypeDynamic, 1.0)
+	rb.Velocity = mgl64.Vec3{30, 40, 0}
+	rb.AngularVelocity = mgl64.Vec3{0, 0, -20}
+
+	rb.ClampVelocity(10, 0)
+	if !vec3AlmostEqual(rb.Velocity, mgl64.Vec3{6, 8, 0}, 1e-12) || rb.AngularVelocity != (mgl64.Vec3{0, 0, -20}) {
+		t.Errorf("velocities %v and %v, want the linear one scaled to 10 m/s and the angular one unlimited", rb.Velocity, rb.AngularVelocity)
+	}
+
+	rb.ClampVelocity(20, 5)
+	if !vec3AlmostEqual(rb.Velocity, mgl64.Vec3{6, 8, 0}, 1e-12) || rb.AngularVelocity != (mgl64.Vec3{0, 0, -5}) {
+		t.Errorf("velocities %v and %v, want the linear one under its limit kept", rb.Velocity, rb.AngularVelocity)
+	}
+}
+
+func TestSetSleeping(t *testing.T) {
+	rb := NewRigidBody(NewTransform(), &Sphere{Radius: 1}, BodyTypeDynamic, 1.0)
+	rb.Velocity = mgl64.Vec3{1, 0, 0}
+
+	if err := rb.SetSleeping(true); err != nil || !rb.IsSleeping || rb.Velocity.Len() != 0 {
+		t.Errorf("SetSleeping(true) = %v, body should sleep without velocity", err)
+	}
+	if err := rb.SetSleeping(false); err != nil || rb.IsSleeping {
+		t.Errorf("SetSleeping(false) = %v, body should be awake", err)
+	}
+
+	static := NewRigidBody(NewTransform(), &Sphere{Radius: 1}, BodyTypeStatic, 1.0)
+	if err := static.SetSleeping(true); !errors.Is(err, ErrStaticSleeping) || static.IsSleeping {
+		t.Errorf("SetSleeping = %v, want ErrStaticSleeping", err)
+	}
+}
+
+func TestApplyShapeChanges_RecomputeMass(t *testing.T) {
+	box := &Box{HalfExtents: mgl64.Vec3{0.5, 0.5, 0.5}}
+	rb := NewRigidBody(NewTransform(), box, BodyTypeDynamic, 2.0)
+	rb.Sleep()
+
+	box.SetHalfExtents(mgl64.Vec3{1, 1, 1}, true)
+	if !rb.ApplyShapeChanges() {
+		t.Fatal("expected pending shape changes")
+	}
+
+	if !almostEqual(rb.Material.GetMass(), 16.0, 1e-9) {
+		t.Errorf("mass = %v, want 16", rb.Material.GetMass())
+	}
+	if !almostEqual(rb.InertiaLocal.At(0, 0), 16.0/12.0*8.0, 1e-9) {
+		t.Errorf("inertia = %v, want %v", rb.InertiaLocal.At(0, 0), 16.0/12.0*8.0)
+	}
+	if rb.Shape.GetAABB().Max != (mgl64.Vec3{1, 1, 1}) {
+		t.Errorf("AABB not updated: %v", rb.Shape.GetAABB())
+	}
+	if rb.IsSleeping {
+		t.Error("a resized body should be woken up")
+	}
+	if rb.ApplyShapeChanges() {
+		t.Error("changes should be applied only once")
+	}
+}
+
+func TestApplyShapeChanges_KeepMass(t *testing.T) {
+	sphere := &Sphere{Radius: 1}
+	rb := NewRigidBody(NewTransform(), sphere, BodyTypeDynamic, 1.0)
+	mass := rb.Material.GetMass()
+
+	sphere.SetRadius(2, false)
+	rb.ApplyShapeChanges()
+
+	if rb.Material.GetMass() != mass {
+		t.Errorf("mass = %v, want unchanged %v", rb.Material.GetMass(), mass)
+	}
+	if rb.Shape.GetAABB().Max != (mgl64.Vec3{2, 2, 2}) {
+		t.Errorf("AABB not updated: %v", rb.Shape.GetAABB())
+	}
+}
+
+func TestApplyShapeChanges_Unchanged(t *testing.T) {
+	rb := NewRigidBody(NewTransform(), &Plane{Normal: mgl64.Vec3{0, 1, 0}}, BodyTypeStatic, 0)
+	if rb.ApplyShapeChanges() {
+		t.Error("a plane has no setters, no changes expected")
+	}
+}
+
+func TestSetShape(t *testing.T) {
+	rb := NewRigidBody(NewTransform(), &Box{HalfExtents: mgl64.Vec3{0.5, 1, 0.5}}, BodyTypeDynamic, 2.0)
+	rb.Sleep()
+	previous := rb.Shape.GetAABB()
+
+	if err := rb.SetShape(&Box{HalfExtents: mgl64.Vec3{0.5, 0.5, 0.5}}); err != nil {
+		t.Fatalf("SetShape failed: %v", err)
+	}
+	if !almostEqual(rb.Material.GetMass(), 2.0, 1e-9) || !almostEqual(rb.InertiaLocal.At(0, 0), 2.0/12.0*2.0, 1e-9) {
+		t.Errorf("mass %v, inertia %v, want them recomputed from the new shape", rb.Material.GetMass(), rb.InertiaLocal)
+	}
+	if rb.Shape.GetAABB().Max != (mgl64.Vec3{0.5, 0.5, 0.5}) || rb.IsSleeping {
+		t.Errorf("AABB %v, sleeping %v, want the new AABB and the body woken up", rb.Shape.GetAABB(), rb.IsSleeping)
+	}
+
+	// The world is told the previous AABB once
+	if swapped, ok := rb.SwappedShape(); !ok || swapped != previous {
+		t.Errorf("SwappedShape = %v, %v, want %v", swapped, ok, previous)
+	}
+	if !rb.ApplyShapeChanges() || rb.ApplyShapeChanges() {
+		t.Error("the swap should be applied once")
+	}
+	if _, ok := rb.SwappedShape(); ok {
+		t.Error("the swap should be consumed by ApplyShapeChanges")
+	}
+
+	mesh, err := NewTriangleMesh([]mgl64.Vec3{{0, 0, 0}, {1, 0, 0}, {0, 0, 1}}, [][3]int{{0, 1, 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rb.SetShape(mesh); !errors.Is(err, ErrDynamicMesh) {
+		t.Errorf("SetShape(mesh) = %v, want ErrDynamicMesh", err)
+	}
+}
+
+func TestSetShape_KeepsOverrides(t *testing.T) {
+	rb := NewRigidBody(NewTransform(), &Box{HalfExtents: mgl64.Vec3{1, 1, 1}}, BodyTypeDynamic, 1.0)
+	if err := rb.SetMass(5); err != nil {
+		t.Fatal(err)
+	}
+	if err := rb.SetCenterOfMass(mgl64.Vec3{0, -0.5, 0}); err != nil {
+		t.Fatal(err)
+	}
+	origin := rb.OriginTransform()
+
+	// The sphere is centered where the box was, its center of mass lowered the same way
+	if err := rb.SetShape(&Sphere{Radius: 0.5}); err != nil {
+		t.Fatalf("SetShape failed: %v", err)
+	}
+	if rb.Material.GetMass() != 5 || rb.CenterOfMass() != (mgl64.Vec3{0, -0.5, 0}) {
+		t.Errorf("mass %v, center of mass %v, want the overrides kept", rb.Material.GetMass(), rb.CenterOfMass())
+	}
+	if aabb := rb.Shape.GetAABB(); !vec3AlmostEqual(aabb.Min, mgl64.Vec3{-0.5, -0.5, -0.5}, 1e-9) || !vec3AlmostEqual(aabb.Max, mgl64.Vec3{0.5, 0.5, 0.5}, 1e-9) {
+		t.Errorf("AABB %v, want the sphere at the center of the box", aabb)
+	}
+	if !vec3AlmostEqual(rb.OriginTransform().Position, origin.Position, 1e-9) {
+		t.Errorf("origin at %v, want %v", rb.OriginTransform().Position, origin.Position)
+	}
+
+	if err := rb.SetShape(&Plane{Normal: mgl64.Vec3{0, 1, 0}}); !errors.Is(err, ErrCenterOfMassShape) {
+		t.Errorf("SetShape(plane) = %v, want ErrCenterOfMassShape", err)
+	}
+}
+
+func TestGetInverseInertiaWorld_Cache(t *testing.T) {
+	rb := NewRigidBody(NewTransform(), &Box{HalfExtents: mgl64.Vec3{1, 2, 3}}, BodyTypeDynamic, 1.0)
+	initial := rb.GetInverseInertiaWorld()
+
+	// A rotation change invalidates the cache
+	rb.Transform.Rotation = mgl64.QuatRotate(math.Pi/2, mgl64.Vec3{0, 0, 1})
+	R := rb.Transform.Rotation.Mat4().Mat3()
+	expected := R.Mul3(rb.InverseInertiaLocal).Mul3(R.Transpose())
+	if rotated := rb.GetInverseInertiaWorld(); !rotated.ApproxEqual(expected) || rotated.ApproxEqual(initial) {
+		t.Errorf("inverse inertia not recomputed after rotation: %v", rotated)
+	}
+
+	// A local inertia change is picked up after InvalidateInertia
+	rb.InverseInertiaLocal = rb.InverseInertiaLocal.Mul(2)
+	rb.InvalidateInertia()
+	if doubled := rb.GetInverseInertiaWorld(); !doubled.ApproxEqual(expected.Mul(2)) {
+		t.Errorf("inverse inertia = %v, want %v", doubled, expected.Mul(2))
+	}
+}
+
+// =============================================================================
+// Impulse Tests
+// =============================================================================
+
+func TestApplyImpulse(t *testing.T) {
+	rb := NewRigidBodyWithMass(NewTransform(), &Sphere{Radius: 1}, BodyTypeDynamic, 2.0)
+	rb.Sleep()
+
+	rb.ApplyImpulse(mgl64.Vec3{4, 0, 0})
+
+	if !vec3AlmostEqual(rb.Velocity, mgl64.Vec3{2, 0, 0}, 1e-9) {
+		t.Errorf("Velocity = %v, want (2, 0, 0)", rb.Velocity)
+	}
+	if rb.IsSleeping {
+		t.Error("an impulse should wake the body up")
+	}
+}
+
+func TestApplyAngularImpulse(t *testing.T) {
+	rb := NewRigidBodyWithMass(NewTransform(), &Sphere{Radius: 1}, BodyTypeDynamic, 2.5)
+
+	// I = 2/5 * m * r² = 1
+	rb.ApplyAngularImpulse(mgl64.Vec3{0, 3, 0})
+
+	if !vec3AlmostEqual(rb.AngularVelocity, mgl64.Vec3{0, 3, 0}, 1e-9) {
+		t.Errorf("AngularVelocity = %v, want (0, 3, 0)", rb.AngularVelocity)
+	}
+	if rb.Velocity.Len() != 0 {
+		t.Errorf("Velocity = %v, an angular impulse should not change it", rb.Velocity)
+	}
+}
+
+func TestApplyAngularImpulse_RotatedBody(t *testing.T) {
+	// Box elongated along its local x axis, rotated by 90° around z: local x is world y
+	transform := NewTransform()
+	transform.Rotation = mgl64.QuatRotate(math.Pi/2, mgl64.Vec3{0, 0, 1})
+	rb := NewRigidBody(transform, &Box{HalfExtents: mgl64.Vec3{2, 0.5, 0.5}}, BodyTypeDynamic, 1.0)
+
+	rb.ApplyAngularImpulse(mgl64.Vec3{0, 1, 0})
+
+	// Spinning around world y is spinning around the long local x axis, with the smallest inertia
+	expected := 1.0 / rb.InertiaLocal.At(0, 0)
+	if !vec3AlmostEqual(rb.AngularVelocity, mgl64.Vec3{0, expected, 0}, 1e-9) {
+		t.Errorf("AngularVelocity = %v, want (0, %v, 0)", rb.AngularVelocity, expected)
+	}
+}
+
+func TestApplyImpulseAtPoint(t *testing.T) {
+	rb := NewRigidBodyWithMass(NewTransform(), &Sphere{Radius: 1}, BodyTypeDynamic, 2.5)
+	rb.Transform.Position = mgl64.Vec3{5, 0, 0}
+
+	// Kick along z on the +x side of the ball: spin around -y
+	rb.ApplyImpulseAtPoint(mgl64.Vec3{0, 0, 1}, mgl64.Vec3{6, 0, 0})
+
+	if !vec3AlmostEqual(rb.Velocity, mgl64.Vec3{0, 0, 0.4}, 1e-9) {
+		t.Errorf("Velocity = %v, want (0, 0, 0.4)", rb.Velocity)
+	}
+	if !vec3AlmostEqual(rb.AngularVelocity, mgl64.Vec3{0, -1, 0}, 1e-9) {
+		t.Errorf("AngularVelocity = %v, want (0, -1, 0)", rb.AngularVelocity)
+	}
+}
+
+func TestApplyImpulseAtLocalPoint(t *testing.T) {
+	transform := NewTransform()
+	transform.Position = mgl64.Vec3{5, 0, 0}
+	transform.Rotation = mgl64.QuatRotate(math.Pi/2, mgl64.Vec3{0, 1, 0})
+	local := NewRigidBodyWithMass(transform, &Sphere{Radius: 1}, BodyTypeDynamic, 2.5)
+	world := NewRigidBodyWithMass(transform, &Sphere{Radius: 1}, BodyTypeDynamic, 2.5)
+
+	// Local +z is world +x after the rotation around y
+	local.ApplyImpulseAtLocalPoint(mgl64.Vec3{0, 0, 1}, mgl64.Vec3{0, 0, 1})
+	world.ApplyImpulseAtPoint(mgl64.Vec3{0, 0, 1}, mgl64.Vec3{6, 0, 0})
+
+	if !vec3AlmostEqual(local.Velocity, world.Velocity, 1e-9) {
+		t.Errorf("Velocity = %v, want %v", local.Velocity, world.Velocity)
+	}
+	if !vec3AlmostEqual(local.AngularVelocity, world.AngularVelocity, 1e-9) {
+		t.Errorf("AngularVelocity = %v, want %v", local.AngularVelocity, world.AngularVelocity)
+	}
+}
+
+func TestApplyImpulse_Static(t *testing.T) {
+	rb := NewRigidBody(NewTransform(), &Sphere{Radius: 1}, BodyTypeStatic, 1.0)
+
+	rb.ApplyImpulse(mgl64.Vec3{1, 0, 0})
+	rb.ApplyAngularImpulse(mgl64.Vec3{0, 1, 0})
+	rb.ApplyImpulseAtLocalPoint(mgl64.Vec3{0, 0, 1}, mgl64.Vec3{1, 0, 0})
+
+	if rb.Velocity.Len() != 0 || rb.AngularVelocity.Len() != 0 {
+		t.Errorf("static body velocities changed: %v %v", rb.Velocity, rb.AngularVelocity)
+	}
+}
+
+func TestApplyForce(t *testing.T) {
+	rb := NewRigidBodyWithMass(NewTransform(), &Sphere{Radius: 1}, BodyTypeDynamic, 2.0)
+	rb.Sleep()
+
+	// 4N on 2kg accelerate at 2 m/s², at each Integrate until the forces are cleared
+	rb.ApplyForce(mgl64.Vec3{4, 0, 0})
+	if rb.IsSleeping {
+		t.Error("a force should wake the body up")
+	}
+	rb.Integrate(0.5, mgl64.Vec3{})
+	rb.Integrate(0.5, mgl64.Vec3{})
+	if !vec3AlmostEqual(rb.Velocity, mgl64.Vec3{2, 0, 0}, 1e-9) {
+		t.Errorf("Velocity = %v, want (2, 0, 0)", rb.Velocity)
+	}
+
+	rb.ClearForces()
+	rb.Integrate(0.5, mgl64.Vec3{})
+	if !vec3AlmostEqual(rb.Velocity, mgl64.Vec3{2, 0, 0}, 1e-9) {
+		t.Errorf("Velocity = %v, want unchanged once the forces are cleared", rb.Velocity)
+	}
+}
+
+func TestApplyForceAtPoint(t *testing.T) {
+	rb := NewRigidBodyWithMass(NewTransform(), &Sphere{Radius: 1}, BodyTypeDynamic, 2.5)
+	rb.Transform.Position = mgl64.Vec3{5, 0, 0}
+
+	// Pushed along z on the +x side of the ball for 0.1s: the same motion as the impulse of 0.1 N⋅s
+	rb.ApplyForceAtPoint(mgl64.Vec3{0, 0, 1}, mgl64.Vec3{6, 0, 0})
+	rb.Integrate(0.1, mgl64.Vec3{})
+
+	if !vec3AlmostEqual(rb.Velocity, mgl64.Vec3{0, 0, 0.04}, 1e-9) {
+		t.Errorf("Velocity = %v, want (0, 0, 0.04)", rb.Velocity)
+	}
+	if !vec3AlmostEqual(rb.AngularVelocity, mgl64.Vec3{0, -0.1, 0}, 1e-9) {
+		t.Errorf("AngularVelocity = %v, want (0, -0.1, 0)", rb.AngularVelocity)
+	}
+}
+
+func TestApplyTorque(t *testing.T) {
+	rb := NewRigidBodyWithMass(NewTransform(), &Sphere{Radius: 1}, BodyTypeDynamic, 2.5)
+
+	// I = 1: 3 N⋅m for 0.5s spin at 1.5 rad/s
+	rb.ApplyTorque(mgl64.Vec3{0, 3, 0})
+	rb.Integrate(0.5, mgl64.Vec3{})
+
+	if !vec3AlmostEqual(rb.AngularVelocity, mgl64.Vec3{0, 1.5, 0}, 1e-9) {
+		t.Errorf("AngularVelocity = %v, want (0, 1.5, 0)", rb.AngularVelocity)
+	}
+}
+
+func TestApplyForce_Static(t *testing.T) {
+	rb := NewRigidBody(NewTransform(), &Sphere{Radius: 1}, BodyTypeStatic, 1.0)
+
+	rb.ApplyForce(mgl64.Vec3{1, 0, 0})
+	rb.ApplyForceAtPoint(mgl64.Vec3{0, 0, 1}, mgl64.Vec3{1, 0, 0})
+	rb.ApplyTorque(mgl64.Vec3{0, 1, 0})
+	rb.Integrate(0.5, mgl64.Vec3{})
+
+	if rb.Velocity.Len() != 0 || rb.AngularVelocity.Len() != 0 {
+		t.Errorf("static body velocities changed: %v %v", rb.Velocity, rb.AngularVelocity)
+	}
+}
+
+func TestRigidBody_DynamicMesh(t *testing.T) {
+	mesh, err := NewTriangleMesh([]mgl64.Vec3{{0, 0, 0}, {0, 0, 1}, {1, 0, 0}}, [][3]int{{0, 1, 2}})
+	if err != nil {
+		t.Fatalf("NewTriangleMesh failed: %v", err)
+	}
+	body := NewRigidBody(NewTransform(), mesh, BodyTypeStatic, 1)
+
+	if err := body.SetBodyType(BodyTypeDynamic); !errors.Is(err, ErrDynamicMesh) {
+		t.Errorf("SetBodyType(dynamic) = %v, want ErrDynamicMesh", err)
+	}
+	body.BodyType = BodyTypeDynamic
+	if err := body.Validate(); !errors.Is(err, ErrDynamicMesh) {
+		t.Errorf("Validate = %v, want ErrDynamicMesh", err)
+	}
+}
+
+func TestRigidBody_ShapeOffset(t *testing.T) {
+	body := NewRigidBody(NewTransform(), &Box{HalfExtents: mgl64.Vec3{0.5, 1, 0.5}}, BodyTypeDynamic, 1.0)
+	if offset := body.ShapeOffset(); offset.Position != (mgl64.Vec3{}) || offset.Rotation != mgl64.QuatIdent() {
+		t.Errorf("ShapeOffset = %+v, want the identity", offset)
+	}
+
+	// A character whose origin is at its feet, the box center is 1 above
+	body.SetShapeOffset(mgl64.Vec3{0, 1, 0}, mgl64.QuatIdent())
+	if body.Transform.Position != (mgl64.Vec3{}) {
+		t.Errorf("Transform = %v, SetShapeOffset should not move the shape", body.Transform.Position)
+	}
+	if origin := body.OriginTransform(); !vec3Equal(origin.Position, mgl64.Vec3{0, -1, 0}, 1e-12) {
+		t.Errorf("origin = %v, want {0, -1, 0}", origin.Position)
+	}
+
+	spawn := Transform{Position: mgl64.Vec3{5, 0, 0}, Rotation: mgl64.QuatRotate(math.Pi/2, mgl64.Vec3{0, 0, 1})}
+	body.SetOriginTransform(spawn)
+	// Rotated a quarter turn around Z, the box center is on the -X side of the feet
+	if !vec3Equal(body.Transform.Position, mgl64.Vec3{4, 0, 0}, 1e-12) {
+		t.Errorf("Transform = %v, want {4, 0, 0}", body.Transform.Position)
+	}
+	if aabb := body.Shape.GetAABB(); !vec3Equal(aabb.Min, mgl64.Vec3{3, -0.5, -0.5}, 1e-12) {
+		t.Errorf("AABB = %v, want the AABB at the new pose", aabb)
+	}
+
+	origin := body.OriginTransform()
+	if !vec3Equal(origin.Position, spawn.Position, 1e-12) || !origin.Rotation.ApproxEqual(spawn.Rotation) {
+		t.Errorf("OriginTransform = %+v, want %+v", origin, spawn)
+	}
+}
+
+// TestRigidBody_ShapeOffsetRotation checks the origin follows the rotation of the body around its center of mass
+func TestRigidBody_ShapeOffsetRotation(t *testing.T) {
+	body := NewRigidBody(NewTransform(), &Sphere{Radius: 1}, BodyTypeDynamic, 1.0)
+	body.SetShapeOffset(mgl64.Vec3{1, 0, 0}, mgl64.QuatRotate(math.Pi/2, mgl64.Vec3{0, 1, 0}))
+
+	before := body.OriginTransform()
+	turn := mgl64.QuatRotate(math.Pi, mgl64.Vec3{0, 1, 0})
+	body.Transform.Rotation = turn.Mul(body.Transform.Rotation)
+	after := body.OriginTransform()
+
+	if !vec3Equal(after.Position, turn.Rotate(before.Position), 1e-12) {
+		t.Errorf("origin = %v, want %v", after.Position, turn.Rotate(before.Position))
+	}
+	if !after.Rotation.ApproxEqual(turn.Mul(before.Rotation)) {
+		t.Errorf("origin rotation = %v, want %v", after.Rotation, turn.Mul(before.Rotation))
+	}
+}
+
+// TestInertiaFastPath compares the fast paths of the isotropic and diagonal tensors to the full product
+func TestInertiaFastPath(t *testing.T) {
+	rotated, err := NewCompound([]CompoundChild{
+		{Shape: &Box{HalfExtents: mgl64.Vec3{2, 0.1, 1}}, Rotation: mgl64.QuatRotate(math.Pi/4, mgl64.Vec3{0, 0, 1})},
+	})
+	if err != nil {
+		t.Fatalf("NewCompound failed: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		shape ShapeInterface
+		form  inertiaForm
+	}{
+		{"sphere", &Sphere{Radius: 0.5}, inertiaIsotropic},
+		{"cube", &Box{HalfExtents: mgl64.Vec3{1, 1, 1}}, inertiaIsotropic},
+		{"box", &Box{HalfExtents: mgl64.Vec3{1, 2, 3}}, inertiaDiagonal},
+		{"cone", &Cone{Radius: 1, Height: 3}, inertiaDiagonal},
+		{"rotated child", rotated, inertiaGeneral},
+	}
+	rotation := mgl64.QuatRotate(0.7, mgl64.Vec3{1, 2, -0.5}.Normalize())
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := NewRigidBody(Transform{Position: mgl64.Vec3{}, Rotation: rotation, InverseRotation: rotation.Inverse()}, tt.shape, BodyTypeDynamic, 2)
+			if body.inertiaForm != tt.form {
+				t.Fatalf("inertia form = %v, want %v", body.inertiaForm, tt.form)
+			}
+
+			R := rotation.Mat4().Mat3()
+			expected := R.Mul3(body.InertiaLocal).Mul3(R.Transpose())
+			expectedInverse := R.Mul3(body.InverseInertiaLocal).Mul3(R.Transpose())
+			if inertia := body.GetInertiaWorld(); !mat3Equal(inertia, expected, 1e-9) {
+				t.Errorf("GetInertiaWorld = %v, want %v", inertia, expected)
+			}
+			if inverse := body.GetInverseInertiaWorld(); !mat3Equal(inverse, expectedInverse, 1e-9) {
+				t.Errorf("GetInverseInertiaWorld = %v, want %v", inverse, expectedInverse)
+			}
+		})
+	}
+}
+
+// TestInvalidateInertia_Reclassifies checks a tensor edited by hand leaves the isotropic fast path
+func TestInvalidateInertia_Reclassifies(t *testing.T) {
+	body := NewRigidBody(NewTransform(), &Sphere{Radius: 1}, BodyTypeDynamic, 1)
+	body.InertiaLocal.Set(0, 0, body.InertiaLocal.At(0, 0)*2)
+	body.InverseInertiaLocal = body.InertiaLocal.Inv()
+	body.InvalidateInertia()
+
+	if body.inertiaForm != inertiaDiagonal {
+		t.Errorf("inertia form = %v, want diagonal after the edit", body.inertiaForm)
+	}
+}
+
+func BenchmarkGetInverseInertiaWorld(b *testing.B) {
+	shapes := map[string]ShapeInterface{
+		"sphere": &Sphere{Radius: 0.5},
+		"box":    &Box{HalfExtents: mgl64.Vec3{1, 2, 3}},
+	}
+	for name, shape := range shapes {
+		b.Run(name, func(b *testing.B) {
+			body := NewRigidBody(NewTransform(), shape, BodyTypeDynamic, 1)
+			for i := 0; i < b.N; i++ {
+				// A new rotation at each query, as in the solver between two substeps
+				body.Transform.Rotation = mgl64.QuatRotate(float64(i)*1e-3, mgl64.Vec3{0, 1, 0})
+				body.GetInverseInertiaWorld()
+			}
+		})
+	}
+}
+
+// TestRaycastWorld casts a ray on a rotated box: the long axis stands along Y
+func TestContainsPointWorld(t *testing.T) {
+	rotation := mgl64.QuatRotate(math.Pi/2, mgl64.Vec3{0, 0, 1})
+	body := NewRigidBody(Transform{Position: mgl64.Vec3{0, 5, 0}, Rotation: rotation, InverseRotation: rotation.Inverse()},
+		&Box{HalfExtents: mgl64.Vec3{2, 0.5, 0.5}}, BodyTypeStatic, 0)
+
+	if !body.ContainsPointWorld(mgl64.Vec3{0, 6.5, 0}) {
+		t.Error("expected the point along the rotated long axis to be contained")
+	}
+	if body.ContainsPointWorld(mgl64.Vec3{1, 5, 0}) {
+		t.Error("expected the point beside the rotated box not to be contained")
+	}
+}
+
+func TestRaycastWorld(t *testing.T) {
+	rotation := mgl64.QuatRotate(math.Pi/2, mgl64.Vec3{0, 0, 1})
+	body := NewRigidBody(Transform{Position: mgl64.Vec3{0, 5, 0}, Rotation: rotation, InverseRotation: rotation.Inverse()},
+		&Box{HalfExtents: mgl64.Vec3{2, 0.5, 0.5}}, BodyTypeStatic, 0)
+
+	distance, normal, hit := body.RaycastWorld(mgl64.Vec3{0, 10, 0}, mgl64.Vec3{0, -1, 0}, 10)
+	if !hit || math.Abs(distance-3) > 1e-9 || !vec3Equal(normal, mgl64.Vec3{0, 1, 0}, 1e-9) {
+		t.Errorf("hit %v at %v with normal %v, want the top end at 3 facing up", hit, distance, normal)
+	}
+	if _, _, hit := body.RaycastWorld(mgl64.Vec3{1, 10, 0}, mgl64.Vec3{0, -1, 0}, 10); hit {
+		t.Error("expected the ray beside the rotated box to miss")
+	}
+}
+
+func TestBroadPhaseAABB_VelocityExpansion(t *testing.T) {
+	rb := NewRigidBody(NewTransform(), &Sphere{Radius: 1.0}, BodyTypeDynamic, 1.0)
+	if rb.BroadPhaseAABB() != rb.Shape.GetAABB() {
+		t.Errorf("BroadPhaseAABB = %v before any step, want the shape AABB", rb.BroadPhaseAABB())
+	}
+
+	rb.Velocity = mgl64.Vec3{10, 0, -5}
+	dt := 0.1
+	rb.Integrate(dt, mgl64.Vec3{})
+
+	// Swept toward the next displacement (1, 0, -0.5)
+	aabb := rb.Shape.GetAABB()
+	expected := AABB{Min: aabb.Min.Add(mgl64.Vec3{0, 0, -0.5 * VelocityExpansion}), Max: aabb.Max.Add(mgl64.Vec3{VelocityExpansion, 0, 0})}
+	if !vec3AlmostEqual(rb.BroadPhaseAABB().Min, expected.Min, 1e-10) || !vec3AlmostEqual(rb.BroadPhaseAABB().Max, expected.Max, 1e-10) {
+		t.Errorf("BroadPhaseAABB = %v, want %v", rb.BroadPhaseAABB(), expected)
+	}
+
+	// A sleeping body does not move
+	rb.Sleep()
+	if rb.BroadPhaseAABB() != rb.Shape.GetAABB() {
+		t.Errorf("BroadPhaseAABB = %v while sleeping, want the shape AABB", rb.BroadPhaseAABB())
+	}
+}
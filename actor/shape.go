@@ -13,6 +13,10 @@ const (
 	ShapeTypeSphere ShapeType = iota
 	ShapeTypeBox
 	ShapeTypePlane
+	ShapeTypeCone
+	ShapeTypeConvexHull
+	ShapeTypeTriangleMesh
+	ShapeTypeCompound
 )
 
 type ContactPoint struct {
@@ -34,6 +38,78 @@ type ShapeInterface interface {
 	Support(direction mgl64.Vec3) mgl64.Vec3
 	GetContactFeature(direction mgl64.Vec3, output *[8]mgl64.Vec3, count *int)
 	CollideWithPlane(planeNormal mgl64.Vec3, planeDistance float64, myTransform Transform) (bool, PlaneContact)
+	// ClosestPoint returns the point of the shape closest to the given point, in local space.
+	// Points inside the shape are returned unchanged.
+	ClosestPoint(point mgl64.Vec3) mgl64.Vec3
+	// BoundingRadius returns the radius of the smallest sphere centered on the local origin
+	// enclosing the shape
+	BoundingRadius() float64
+	// Raycast returns the distance along the normalized direction to the first point of the shape
+	// within maxDist, and the surface normal there, in local space.
+	// Rays starting inside the shape hit at distance 0, with the normal opposed to the direction.
+	Raycast(origin, direction mgl64.Vec3, maxDist float64) (float64, mgl64.Vec3, bool)
+	// ContainsPoint returns true if the point, in local space, lies inside the shape or on its surface.
+	// Triangle meshes and triangles have no volume, they contain no point.
+	ContainsPoint(point mgl64.Vec3) bool
+}
+
+// FeatureIndexer is implemented by the shapes with several contact features (e.g. the faces of a box).
+// FeatureIndex returns the index of the feature GetContactFeature returns for the same direction,
+// the manifolds tell their points apart by it. The other shapes have a single feature, indexed 0.
+type FeatureIndexer interface {
+	FeatureIndex(direction mgl64.Vec3) int
+}
+
+// mutableShape is implemented by the shapes whose parameters can be changed after the body creation
+type mutableShape interface {
+	// consumeChanges returns the pending changes and clears them
+	consumeChanges() (dirty bool, recomputeMass bool)
+}
+
+// shapeChanges records the pending parameter changes of a shape,
+// until the owning body applies them with RigidBody.ApplyShapeChanges
+type shapeChanges struct {
+	dirty         bool
+	recomputeMass bool
+}
+
+func (c *shapeChanges) markDirty(recomputeMass bool) {
+	c.dirty = true
+	c.recomputeMass = c.recomputeMass || recomputeMass
+}
+
+func (c *shapeChanges) consumeChanges() (bool, bool) {
+	dirty, recomputeMass := c.dirty, c.recomputeMass
+	*c = shapeChanges{}
+
+	return dirty, recomputeMass
+}
+
+// collisionMargin is embedded by the convex shapes to fatten their support function in GJK, see SetMargin
+type collisionMargin struct {
+	margin float64
+}
+
+// SetMargin sets the collision margin (m): GJK sees the shape inflated by margin in every direction,
+// and EPA removes it from the penetration depth. Touching shapes then overlap by the sum of their margins,
+// away from the degenerate simplices of exact contact. A margin of 0 (the default) disables it.
+// Keep it small relative to the shape (e.g. 0.01 to 0.04 for 1m boxes), the corners are rounded by the margin.
+func (m *collisionMargin) SetMargin(margin float64) {
+	m.margin = math.Max(0, margin)
+}
+
+// Margin returns the collision margin of the shape
+func (m *collisionMargin) Margin() float64 {
+	return m.margin
+}
+
+// ShapeMargin returns the collision margin of a shape, 0 for the shapes without margin (planes, meshes, compounds)
+func ShapeMargin(shape ShapeInterface) float64 {
+	if margined, ok := shape.(interface{ Margin() float64 }); ok {
+		return margined.Margin()
+	}
+
+	return 0
 }
 
 // Box represents an oriented box collision shape
@@ -41,6 +117,16 @@ type ShapeInterface interface {
 type Box struct {
 	HalfExtents mgl64.Vec3
 	aabb        AABB
+	shapeChanges
+	collisionMargin
+}
+
+// SetHalfExtents resizes the box without recreating its body (e.g. from an editor gizmo).
+// The body is updated at the next World.Step, or immediately with RigidBody.ApplyShapeChanges.
+// recomputeMass updates the mass and inertia from the density, otherwise they are kept.
+func (b *Box) SetHalfExtents(halfExtents mgl64.Vec3, recomputeMass bool) {
+	b.HalfExtents = halfExtents
+	b.markDirty(recomputeMass)
 }
 
 func (b *Box) ComputeAABB(transform Transform) {
@@ -125,30 +211,7 @@ func (b *Box) Support(direction mgl64.Vec3) mgl64.Vec3 {
 }
 
 func (b *Box) GetContactFeature(direction mgl64.Vec3, output *[8]mgl64.Vec3, count *int) {
-	// Trouver la face la plus alignée
-	axes := [3]mgl64.Vec3{
-		{1, 0, 0}, {0, 1, 0}, {0, 0, 1},
-	}
-
-	// ========== FIX : Comparer les valeurs absolues directement ==========
-	maxAbsDot := 0.0 // Commence à 0, pas -∞
-	bestAxisIdx := 0
-	sign := 1.0
-
-	for i, axis := range axes {
-		dot := direction.Dot(axis)
-		absDot := math.Abs(dot)
-
-		if absDot > maxAbsDot {
-			maxAbsDot = absDot
-			bestAxisIdx = i
-			if dot > 0 {
-				sign = 1
-			} else {
-				sign = -1
-			}
-		}
-	}
+	bestAxisIdx, sign := b.contactFace(direction)
 
 	halfSize := b.HalfExtents
 
@@ -178,6 +241,46 @@ func (b *Box) GetContactFeature(direction mgl64.Vec3, output *[8]mgl64.Vec3, cou
 	}
 }
 
+// FeatureIndex returns 2*axis for the face on the negative side of the axis, 2*axis+1 on the positive side
+func (b *Box) FeatureIndex(direction mgl64.Vec3) int {
+	axis, sign := b.contactFace(direction)
+	if sign > 0 {
+		return 2*axis + 1
+	}
+
+	return 2 * axis
+}
+
+// contactFace returns the axis and the side of the face the most aligned with the direction
+func (b *Box) contactFace(direction mgl64.Vec3) (int, float64) {
+	// Trouver la face la plus alignée
+	axes := [3]mgl64.Vec3{
+		{1, 0, 0}, {0, 1, 0}, {0, 0, 1},
+	}
+
+	// ========== FIX : Comparer les valeurs absolues directement ==========
+	maxAbsDot := 0.0 // Commence à 0, pas -∞
+	bestAxisIdx := 0
+	sign := 1.0
+
+	for i, axis := range axes {
+		dot := direction.Dot(axis)
+		absDot := math.Abs(dot)
+
+		if absDot > maxAbsDot {
+			maxAbsDot = absDot
+			bestAxisIdx = i
+			if dot > 0 {
+				sign = 1
+			} else {
+				sign = -1
+			}
+		}
+	}
+
+	return bestAxisIdx, sign
+}
+
 // CollideWithPlane - Collision Box/Plane
 func (b *Box) CollideWithPlane(planeNormal mgl64.Vec3, planeDistance float64, myTransform Transform) (bool, PlaneContact) {
 	h := b.HalfExtents
@@ -224,10 +327,55 @@ func (b *Box) CollideWithPlane(planeNormal mgl64.Vec3, planeDistance float64, my
 	return true, contactPoints
 }
 
+// ClosestPoint clamps the point to the box extents
+func (b *Box) ClosestPoint(point mgl64.Vec3) mgl64.Vec3 {
+	return mgl64.Vec3{
+		math.Max(-b.HalfExtents.X(), math.Min(b.HalfExtents.X(), point.X())),
+		math.Max(-b.HalfExtents.Y(), math.Min(b.HalfExtents.Y(), point.Y())),
+		math.Max(-b.HalfExtents.Z(), math.Min(b.HalfExtents.Z(), point.Z())),
+	}
+}
+
+// BoundingRadius returns the distance from the center to a corner
+func (b *Box) BoundingRadius() float64 {
+	return b.HalfExtents.Len()
+}
+
+// ContainsPoint tests the point against the half extents
+func (b *Box) ContainsPoint(point mgl64.Vec3) bool {
+	return math.Abs(point.X()) <= b.HalfExtents.X() &&
+		math.Abs(point.Y()) <= b.HalfExtents.Y() &&
+		math.Abs(point.Z()) <= b.HalfExtents.Z()
+}
+
+// Raycast intersects the ray with the faces of the box, the normal is the face entered
+func (b *Box) Raycast(origin, direction mgl64.Vec3, maxDist float64) (float64, mgl64.Vec3, bool) {
+	distance, axis, ok := raySlabs(origin, direction, b.HalfExtents.Mul(-1), b.HalfExtents, maxDist)
+	if !ok {
+		return 0, mgl64.Vec3{}, false
+	}
+	if axis < 0 {
+		return 0, direction.Mul(-1), true
+	}
+
+	var normal mgl64.Vec3
+	normal[axis] = -math.Copysign(1, direction[axis])
+
+	return distance, normal, true
+}
+
 // Sphere represents a spherical collision shape
 type Sphere struct {
 	Radius float64
 	aabb   AABB
+	shapeChanges
+	collisionMargin
+}
+
+// SetRadius resizes the sphere without recreating its body, see Box.SetHalfExtents
+func (s *Sphere) SetRadius(radius float64, recomputeMass bool) {
+	s.Radius = radius
+	s.markDirty(recomputeMass)
 }
 
 // ComputeAABB calculates the axis-aligned bounding box for the sphere
@@ -292,6 +440,336 @@ func (s *Sphere) CollideWithPlane(planeNormal mgl64.Vec3, planeDistance float64,
 	}
 }
 
+// ClosestPoint projects the point onto the sphere surface if it lies outside
+func (s *Sphere) ClosestPoint(point mgl64.Vec3) mgl64.Vec3 {
+	distance := point.Len()
+	if distance <= s.Radius {
+		return point
+	}
+
+	return point.Mul(s.Radius / distance)
+}
+
+func (s *Sphere) BoundingRadius() float64 {
+	return s.Radius
+}
+
+// ContainsPoint tests the distance of the point to the center
+func (s *Sphere) ContainsPoint(point mgl64.Vec3) bool {
+	return point.LenSqr() <= s.Radius*s.Radius
+}
+
+// Raycast solves |origin + t*direction| = Radius for the nearest t
+func (s *Sphere) Raycast(origin, direction mgl64.Vec3, maxDist float64) (float64, mgl64.Vec3, bool) {
+	b := origin.Dot(direction)
+	c := origin.LenSqr() - s.Radius*s.Radius
+	if c <= 0 {
+		return 0, direction.Mul(-1), true
+	}
+
+	discriminant := b*b - c
+	if b > 0 || discriminant < 0 {
+		return 0, mgl64.Vec3{}, false
+	}
+
+	distance := -b - math.Sqrt(discriminant)
+	if distance > maxDist {
+		return 0, mgl64.Vec3{}, false
+	}
+
+	return distance, origin.Add(direction.Mul(distance)).Mul(1 / s.Radius), true
+}
+
+// Cone represents a solid cone collision shape, its axis along local Y.
+// The local origin is the center of mass, a quarter of the height above the base:
+// the base is at y = -Height/4 and the apex at y = 3*Height/4.
+type Cone struct {
+	Radius float64 // Radius of the base
+	Height float64 // Distance from the base to the apex
+	aabb   AABB
+	collisionMargin
+}
+
+// apex returns the tip of the cone, in local space
+func (c *Cone) apex() mgl64.Vec3 {
+	return mgl64.Vec3{0, 0.75 * c.Height, 0}
+}
+
+// rimPoint returns the point of the base circle in the radial direction of the given vector,
+// the center of the base if the vector is parallel to the axis
+func (c *Cone) rimPoint(direction mgl64.Vec3) mgl64.Vec3 {
+	baseY := -0.25 * c.Height
+	radial := math.Sqrt(direction.X()*direction.X() + direction.Z()*direction.Z())
+	if radial < 1e-12 {
+		return mgl64.Vec3{0, baseY, 0}
+	}
+
+	return mgl64.Vec3{c.Radius * direction.X() / radial, baseY, c.Radius * direction.Z() / radial}
+}
+
+// ComputeAABB calculates the axis-aligned bounding box from the support points along the world axes
+func (c *Cone) ComputeAABB(transform Transform) {
+	var min, max mgl64.Vec3
+	axes := [3]mgl64.Vec3{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+
+	for i, axis := range axes {
+		localAxis := transform.Rotation.Conjugate().Rotate(axis)
+		max[i] = transform.Rotation.Rotate(c.Support(localAxis))[i]
+		min[i] = transform.Rotation.Rotate(c.Support(localAxis.Mul(-1)))[i]
+	}
+
+	c.aabb = AABB{Min: min.Add(transform.Position), Max: max.Add(transform.Position)}
+}
+
+func (c *Cone) GetAABB() AABB {
+	return c.aabb
+}
+
+// ComputeMass calculates mass data for the cone
+func (c *Cone) ComputeMass(density float64) float64 {
+	// Volume of cone = (1/3) * π * r² * h
+	volume := math.Pi * c.Radius * c.Radius * c.Height / 3.0
+
+	return density * volume
+}
+
+// ComputeInertia returns the inertia tensor about the center of mass:
+// Iy = 3/10 * m * r² around the axis, Ix = Iz = 3/20 * m * r² + 3/80 * m * h²
+func (c *Cone) ComputeInertia(mass float64) mgl64.Mat3 {
+	r2 := c.Radius * c.Radius
+	h2 := c.Height * c.Height
+
+	iy := 3.0 / 10.0 * mass * r2
+	ixz := 3.0/20.0*mass*r2 + 3.0/80.0*mass*h2
+
+	return mgl64.Mat3{
+		ixz, 0, 0,
+		0, iy, 0,
+		0, 0, ixz,
+	}
+}
+
+// Support returns the apex if the direction lies within its normal cone, the rim point otherwise
+// (G. van den Bergen, "Collision Detection in Interactive 3D Environments", 2003)
+func (c *Cone) Support(direction mgl64.Vec3) mgl64.Vec3 {
+	sinAngle := c.Radius / math.Sqrt(c.Radius*c.Radius+c.Height*c.Height)
+	if direction.Y() > direction.Len()*sinAngle {
+		return c.apex()
+	}
+
+	return c.rimPoint(direction)
+}
+
+// GetContactFeature returns the base (as a square inscribed in the base circle) if the direction is closer
+// to the base normal than to the side, otherwise the segment of the side from the apex to the rim
+func (c *Cone) GetContactFeature(direction mgl64.Vec3, output *[8]mgl64.Vec3, count *int) {
+	if c.baseFacing(direction) {
+		baseY := -0.25 * c.Height
+		output[0] = mgl64.Vec3{c.Radius, baseY, 0}
+		output[1] = mgl64.Vec3{0, baseY, c.Radius}
+		output[2] = mgl64.Vec3{-c.Radius, baseY, 0}
+		output[3] = mgl64.Vec3{0, baseY, -c.Radius}
+		*count = 4
+		return
+	}
+
+	output[0] = c.apex()
+	*count = 1
+	if radial := math.Sqrt(direction.X()*direction.X() + direction.Z()*direction.Z()); radial > 1e-9*direction.Len() {
+		output[1] = c.rimPoint(direction)
+		*count = 2
+	}
+}
+
+// FeatureIndex returns 0 for the base, 1 for the side
+func (c *Cone) FeatureIndex(direction mgl64.Vec3) int {
+	if c.baseFacing(direction) {
+		return 0
+	}
+
+	return 1
+}
+
+// baseFacing returns true if the direction is closer to the normal of the base than to the normal of the side
+func (c *Cone) baseFacing(direction mgl64.Vec3) bool {
+	slant := math.Sqrt(c.Radius*c.Radius + c.Height*c.Height)
+	radial := math.Sqrt(direction.X()*direction.X() + direction.Z()*direction.Z())
+	length := direction.Len()
+
+	baseDot := -direction.Y() / length
+	sideDot := (c.Height*radial + c.Radius*direction.Y()) / (slant * length)
+
+	return baseDot >= sideDot
+}
+
+// CollideWithPlane - Collision Cone/Plane
+// The apex, the deepest point of the rim and 8 samples of the rim are tested against the plane
+func (c *Cone) CollideWithPlane(planeNormal mgl64.Vec3, planeDistance float64, myTransform Transform) (bool, PlaneContact) {
+	const rimSamples = 8
+
+	deepestRim := c.rimPoint(myTransform.Rotation.Conjugate().Rotate(planeNormal.Mul(-1)))
+
+	var localVertices [rimSamples + 2]mgl64.Vec3
+	localVertices[0] = c.apex()
+	localVertices[1] = deepestRim
+	count := 2
+	for i := 0; i < rimSamples; i++ {
+		angle := 2 * math.Pi * float64(i) / rimSamples
+		sample := c.rimPoint(mgl64.Vec3{math.Cos(angle), 0, math.Sin(angle)})
+		// Skip the samples merged with the deepest point, they would weigh twice in the manifold
+		if sample.Sub(deepestRim).Len() > 1e-3*c.Radius {
+			localVertices[count] = sample
+			count++
+		}
+	}
+
+	var contactPoints []ContactPoint
+	for _, vertex := range localVertices[:count] {
+		worldVertex := myTransform.Rotation.Rotate(vertex).Add(myTransform.Position)
+		distance := worldVertex.Sub(planeNormal.Mul(-planeDistance)).Dot(planeNormal)
+
+		if distance < 0 {
+			contactPoints = append(contactPoints, ContactPoint{
+				Position:    worldVertex.Sub(planeNormal.Mul(distance)),
+				Penetration: -distance,
+			})
+		}
+	}
+
+	if len(contactPoints) == 0 {
+		return false, PlaneContact{}
+	}
+
+	if len(contactPoints) > 4 {
+		contactPoints = reduceTo4ContactPoints(contactPoints, planeNormal)
+	}
+
+	return true, contactPoints
+}
+
+// ClosestPoint solves the problem in the (radial, y) half-plane, where the cone is a triangle
+func (c *Cone) ClosestPoint(point mgl64.Vec3) mgl64.Vec3 {
+	if c.ContainsPoint(point) {
+		return point
+	}
+
+	radial := math.Sqrt(point.X()*point.X() + point.Z()*point.Z())
+	p := mgl64.Vec2{radial, point.Y()}
+
+	apex := mgl64.Vec2{0, 0.75 * c.Height}
+	rim := mgl64.Vec2{c.Radius, -0.25 * c.Height}
+	center := mgl64.Vec2{0, -0.25 * c.Height}
+
+	closest := closestOnSegment2D(p, apex, rim)
+	if candidate := closestOnSegment2D(p, center, rim); candidate.Sub(p).LenSqr() < closest.Sub(p).LenSqr() {
+		closest = candidate
+	}
+
+	if radial < 1e-12 {
+		return mgl64.Vec3{closest.X(), closest.Y(), 0}
+	}
+	scale := closest.X() / radial
+
+	return mgl64.Vec3{point.X() * scale, closest.Y(), point.Z() * scale}
+}
+
+// ContainsPoint tests the point in the (radial, height) plane: above the base and under the side
+func (c *Cone) ContainsPoint(point mgl64.Vec3) bool {
+	radial := math.Sqrt(point.X()*point.X() + point.Z()*point.Z())
+	p := mgl64.Vec2{radial, point.Y()}
+
+	apex := mgl64.Vec2{0, 0.75 * c.Height}
+	rim := mgl64.Vec2{c.Radius, -0.25 * c.Height}
+	center := mgl64.Vec2{0, -0.25 * c.Height}
+
+	side := rim.Sub(apex)
+	outward := mgl64.Vec2{-side.Y(), side.X()}
+	if outward.Dot(rim.Sub(center)) < 0 {
+		outward = outward.Mul(-1)
+	}
+
+	return p.Y() >= center.Y() && p.Sub(apex).Dot(outward) <= 0
+}
+
+// BoundingRadius returns the distance from the center of mass to the farthest of the apex and the rim
+func (c *Cone) BoundingRadius() float64 {
+	return math.Max(0.75*c.Height, math.Sqrt(c.Radius*c.Radius+0.0625*c.Height*c.Height))
+}
+
+// Raycast intersects the ray with the side (a quadratic in t) and with the base disk, the nearest one wins
+func (c *Cone) Raycast(origin, direction mgl64.Vec3, maxDist float64) (float64, mgl64.Vec3, bool) {
+	apexY, baseY := 0.75*c.Height, -0.25*c.Height
+	slope := c.Radius / c.Height
+
+	// Inside: above the base and under the side
+	if height := apexY - origin.Y(); origin.Y() >= baseY && height >= 0 &&
+		origin.X()*origin.X()+origin.Z()*origin.Z() <= slope*slope*height*height {
+		return 0, direction.Mul(-1), true
+	}
+
+	best := maxDist
+	var normal mgl64.Vec3
+	hit := false
+
+	// Side: x² + z² = slope² * (apexY - y)², the points of the upper nappe are discarded
+	height := apexY - origin.Y()
+	a := direction.X()*direction.X() + direction.Z()*direction.Z() - slope*slope*direction.Y()*direction.Y()
+	b := 2 * (origin.X()*direction.X() + origin.Z()*direction.Z() + slope*slope*height*direction.Y())
+	cc := origin.X()*origin.X() + origin.Z()*origin.Z() - slope*slope*height*height
+
+	var roots [2]float64
+	count := 0
+	if math.Abs(a) < 1e-12 {
+		if math.Abs(b) > 1e-12 {
+			roots[0], count = -cc/b, 1
+		}
+	} else if discriminant := b*b - 4*a*cc; discriminant >= 0 {
+		sqrt := math.Sqrt(discriminant)
+		roots[0], roots[1], count = (-b-sqrt)/(2*a), (-b+sqrt)/(2*a), 2
+	}
+	for _, t := range roots[:count] {
+		if t < 0 || t > best {
+			continue
+		}
+		point := origin.Add(direction.Mul(t))
+		if point.Y() < baseY || point.Y() > apexY {
+			continue
+		}
+
+		radial := math.Sqrt(point.X()*point.X() + point.Z()*point.Z())
+		if radial < 1e-12 {
+			normal = mgl64.Vec3{0, 1, 0}
+		} else {
+			normal = mgl64.Vec3{point.X() / radial, slope, point.Z() / radial}.Normalize()
+		}
+		best, hit = t, true
+	}
+
+	// Base disk, entered from below
+	if direction.Y() > 1e-12 {
+		if t := (baseY - origin.Y()) / direction.Y(); t >= 0 && t <= best {
+			point := origin.Add(direction.Mul(t))
+			if point.X()*point.X()+point.Z()*point.Z() <= c.Radius*c.Radius {
+				best, normal, hit = t, mgl64.Vec3{0, -1, 0}, true
+			}
+		}
+	}
+
+	return best, normal, hit
+}
+
+func closestOnSegment2D(point, a, b mgl64.Vec2) mgl64.Vec2 {
+	ab := b.Sub(a)
+	lengthSqr := ab.LenSqr()
+	if lengthSqr < 1e-24 {
+		return a
+	}
+
+	t := math.Max(0, math.Min(1, point.Sub(a).Dot(ab)/lengthSqr))
+
+	return a.Add(ab.Mul(t))
+}
+
 // Plane represents an infinite plane collision shape
 // The plane is defined by the equation: Normal · p + Distance = 0
 // where Normal is the plane's normal vector (must be normalized)
@@ -375,6 +853,47 @@ func (p *Plane) CollideWithPlane(planeNormal mgl64.Vec3, planeDistance float64,
 	return false, PlaneContact{}
 }
 
+// ClosestPoint projects the point onto the plane if it lies above it.
+// The plane is a half-space: points below it are inside.
+func (p *Plane) ClosestPoint(point mgl64.Vec3) mgl64.Vec3 {
+	distance := point.Dot(p.Normal) + p.Distance
+	if distance <= 0 {
+		return point
+	}
+
+	return point.Sub(p.Normal.Mul(distance))
+}
+
+// ContainsPoint returns true for the points below the plane, the plane is a half-space
+func (p *Plane) ContainsPoint(point mgl64.Vec3) bool {
+	return point.Dot(p.Normal)+p.Distance <= 0
+}
+
+// BoundingRadius is infinite, a plane has no bounds
+func (p *Plane) BoundingRadius() float64 {
+	return math.Inf(1)
+}
+
+// Raycast intersects the ray with the half-space, rays starting below the plane hit at distance 0
+func (p *Plane) Raycast(origin, direction mgl64.Vec3, maxDist float64) (float64, mgl64.Vec3, bool) {
+	distance := origin.Dot(p.Normal) + p.Distance
+	if distance <= 0 {
+		return 0, direction.Mul(-1), true
+	}
+
+	approach := direction.Dot(p.Normal)
+	if approach >= 0 {
+		return 0, mgl64.Vec3{}, false
+	}
+
+	t := -distance / approach
+	if t > maxDist {
+		return 0, mgl64.Vec3{}, false
+	}
+
+	return t, p.Normal, true
+}
+
 // Helper to generate the tangent basis
 func getTangentBasis(normal mgl64.Vec3) (mgl64.Vec3, mgl64.Vec3) {
 	var tangent1 mgl64.Vec3
@@ -226,6 +226,23 @@ func TestBoxSupportWithRotation(t *testing.T) {
 	}
 }
 
+func TestBoxFeatureIndex(t *testing.T) {
+	box := &Box{HalfExtents: mgl64.Vec3{1, 2, 3}}
+
+	seen := make(map[int]bool)
+	for _, direction := range []mgl64.Vec3{{1, 0, 0}, {-1, 0, 0}, {0, 1, 0}, {0, -1, 0}, {0, 0, 1}, {0, 0, -1}} {
+		feature := box.FeatureIndex(direction)
+		if feature < 0 || feature > 5 || seen[feature] {
+			t.Errorf("FeatureIndex(%v) = %d, want a distinct face in [0, 5]", direction, feature)
+		}
+		seen[feature] = true
+	}
+
+	if box.FeatureIndex(mgl64.Vec3{0.1, -1, 0.2}) != box.FeatureIndex(mgl64.Vec3{0, -1, 0}) {
+		t.Error("FeatureIndex changes for a direction facing the same face")
+	}
+}
+
 func TestBoxGetContactFeatureWithRotation(t *testing.T) {
 	box := &Box{HalfExtents: mgl64.Vec3{1, 2, 3}}
 
@@ -527,3 +544,304 @@ func TestGetTangentBasis(t *testing.T) {
 		})
 	}
 }
+
+// ========== CLOSEST POINT TESTS ==========
+
+func TestClosestPoint(t *testing.T) {
+	tests := []struct {
+		name     string
+		shape    ShapeInterface
+		point    mgl64.Vec3
+		expected mgl64.Vec3
+	}{
+		{"box outside face", &Box{HalfExtents: mgl64.Vec3{1, 2, 3}}, mgl64.Vec3{5, 0, 0}, mgl64.Vec3{1, 0, 0}},
+		{"box outside corner", &Box{HalfExtents: mgl64.Vec3{1, 2, 3}}, mgl64.Vec3{5, -5, 5}, mgl64.Vec3{1, -2, 3}},
+		{"box inside", &Box{HalfExtents: mgl64.Vec3{1, 2, 3}}, mgl64.Vec3{0.5, 1, -2}, mgl64.Vec3{0.5, 1, -2}},
+		{"sphere outside", &Sphere{Radius: 2}, mgl64.Vec3{0, 0, 10}, mgl64.Vec3{0, 0, 2}},
+		{"sphere inside", &Sphere{Radius: 2}, mgl64.Vec3{1, 0, 0}, mgl64.Vec3{1, 0, 0}},
+		{"plane above", &Plane{Normal: mgl64.Vec3{0, 1, 0}, Distance: -1}, mgl64.Vec3{3, 5, 2}, mgl64.Vec3{3, 1, 2}},
+		{"plane below", &Plane{Normal: mgl64.Vec3{0, 1, 0}, Distance: -1}, mgl64.Vec3{3, -5, 2}, mgl64.Vec3{3, -5, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.shape.ClosestPoint(tt.point)
+			if !vec3Equal(result, tt.expected, 1e-9) {
+				t.Errorf("ClosestPoint(%v) = %v, want %v", tt.point, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestContainsPoint(t *testing.T) {
+	cone := &Cone{Radius: 1, Height: 2} // Apex at y = 1.5, base at y = -0.5
+	mesh, err := NewTriangleMesh([]mgl64.Vec3{{0, 0, 0}, {0, 0, 1}, {1, 0, 0}}, [][3]int{{0, 1, 2}})
+	if err != nil {
+		t.Fatalf("NewTriangleMesh failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		shape    ShapeInterface
+		point    mgl64.Vec3
+		expected bool
+	}{
+		{"box inside", &Box{HalfExtents: mgl64.Vec3{1, 2, 3}}, mgl64.Vec3{0.5, -1.5, 2.9}, true},
+		{"box on the surface", &Box{HalfExtents: mgl64.Vec3{1, 2, 3}}, mgl64.Vec3{1, 0, 0}, true},
+		{"box outside", &Box{HalfExtents: mgl64.Vec3{1, 2, 3}}, mgl64.Vec3{0, 2.1, 0}, false},
+		{"sphere inside", &Sphere{Radius: 2}, mgl64.Vec3{1, 1, 1}, true},
+		{"sphere outside", &Sphere{Radius: 2}, mgl64.Vec3{1.5, 1.5, 0}, false},
+		{"cone inside", cone, mgl64.Vec3{0.3, 0, 0.3}, true},
+		{"cone under the base", cone, mgl64.Vec3{0, -0.6, 0}, false},
+		{"cone above the apex", cone, mgl64.Vec3{0, 1.6, 0}, false},
+		{"cone beside the side", cone, mgl64.Vec3{0.6, 0.5, 0}, false},
+		{"plane below", &Plane{Normal: mgl64.Vec3{0, 1, 0}, Distance: -1}, mgl64.Vec3{3, 0.5, 2}, true},
+		{"plane above", &Plane{Normal: mgl64.Vec3{0, 1, 0}, Distance: -1}, mgl64.Vec3{3, 1.5, 2}, false},
+		{"mesh", mesh, mgl64.Vec3{0.2, 0, 0.2}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.shape.ContainsPoint(tt.point); result != tt.expected {
+				t.Errorf("ContainsPoint(%v) = %v, want %v", tt.point, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBoundingRadius(t *testing.T) {
+	if r := (&Box{HalfExtents: mgl64.Vec3{1, 2, 2}}).BoundingRadius(); !floatEqual(r, 3, 1e-12) {
+		t.Errorf("Box BoundingRadius = %v, want 3", r)
+	}
+	if r := (&Sphere{Radius: 1.5}).BoundingRadius(); !floatEqual(r, 1.5, 1e-12) {
+		t.Errorf("Sphere BoundingRadius = %v, want 1.5", r)
+	}
+	if r := (&Plane{Normal: mgl64.Vec3{0, 1, 0}}).BoundingRadius(); !math.IsInf(r, 1) {
+		t.Errorf("Plane BoundingRadius = %v, want +Inf", r)
+	}
+}
+
+func TestConeComputeMassAndInertia(t *testing.T) {
+	cone := &Cone{Radius: 1, Height: 4}
+
+	if mass := cone.ComputeMass(3); !floatEqual(mass, 4*math.Pi, 1e-9) {
+		t.Errorf("mass = %v, want %v", mass, 4*math.Pi)
+	}
+
+	// Iy = 3/10 m r², Ix = Iz = 3/20 m r² + 3/80 m h² (checked by numerical integration)
+	expected := mgl64.Mat3{
+		0.75, 0, 0,
+		0, 0.3, 0,
+		0, 0, 0.75,
+	}
+	if inertia := cone.ComputeInertia(1); !mat3Equal(inertia, expected, 1e-9) {
+		t.Errorf("inertia = %v, want %v", inertia, expected)
+	}
+}
+
+func TestConeSupport(t *testing.T) {
+	cone := &Cone{Radius: 1, Height: 4}
+
+	tests := []struct {
+		name      string
+		direction mgl64.Vec3
+		expected  mgl64.Vec3
+	}{
+		{"up: apex", mgl64.Vec3{0, 1, 0}, mgl64.Vec3{0, 3, 0}},
+		{"down: base center", mgl64.Vec3{0, -1, 0}, mgl64.Vec3{0, -1, 0}},
+		{"side: rim", mgl64.Vec3{1, 0, 0}, mgl64.Vec3{1, -1, 0}},
+		{"steep: apex", mgl64.Vec3{0.2, 1, 0}, mgl64.Vec3{0, 3, 0}},
+		{"shallow: rim", mgl64.Vec3{0, 0.2, -1}, mgl64.Vec3{0, -1, -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if support := cone.Support(tt.direction); !vec3Equal(support, tt.expected, 1e-9) {
+				t.Errorf("Support(%v) = %v, want %v", tt.direction, support, tt.expected)
+			}
+		})
+	}
+}
+
+func TestConeComputeAABB(t *testing.T) {
+	cone := &Cone{Radius: 1, Height: 4}
+
+	cone.ComputeAABB(Transform{Position: mgl64.Vec3{10, 0, 0}, Rotation: mgl64.QuatIdent()})
+	aabb := cone.GetAABB()
+	if !vec3Equal(aabb.Min, mgl64.Vec3{9, -1, -1}, 1e-9) || !vec3Equal(aabb.Max, mgl64.Vec3{11, 3, 1}, 1e-9) {
+		t.Errorf("AABB = %v", aabb)
+	}
+
+	// Upside down: the apex points to -Y
+	cone.ComputeAABB(Transform{Rotation: mgl64.QuatRotate(math.Pi, mgl64.Vec3{1, 0, 0})})
+	aabb = cone.GetAABB()
+	if !vec3Equal(aabb.Min, mgl64.Vec3{-1, -3, -1}, 1e-9) || !vec3Equal(aabb.Max, mgl64.Vec3{1, 1, 1}, 1e-9) {
+		t.Errorf("rotated AABB = %v", aabb)
+	}
+}
+
+func TestConeGetContactFeature(t *testing.T) {
+	cone := &Cone{Radius: 1, Height: 4}
+	var output [8]mgl64.Vec3
+	var count int
+
+	cone.GetContactFeature(mgl64.Vec3{0, -1, 0}, &output, &count)
+	if count != 4 {
+		t.Fatalf("base feature count = %d, want 4", count)
+	}
+	for _, point := range output[:count] {
+		if !floatEqual(point.Y(), -1, 1e-9) {
+			t.Errorf("base point %v not on the base", point)
+		}
+	}
+
+	// Side normal, (h, r) in the (radial, y) plane
+	cone.GetContactFeature(mgl64.Vec3{4, 1, 0}, &output, &count)
+	if count != 2 || !vec3Equal(output[0], mgl64.Vec3{0, 3, 0}, 1e-9) || !vec3Equal(output[1], mgl64.Vec3{1, -1, 0}, 1e-9) {
+		t.Errorf("side feature = %v (count %d), want the apex and the rim", output[:count], count)
+	}
+
+	cone.GetContactFeature(mgl64.Vec3{0, 1, 0}, &output, &count)
+	if count != 1 || !vec3Equal(output[0], mgl64.Vec3{0, 3, 0}, 1e-9) {
+		t.Errorf("apex feature = %v (count %d), want the apex", output[:count], count)
+	}
+}
+
+func TestConeCollideWithPlane(t *testing.T) {
+	cone := &Cone{Radius: 1, Height: 4}
+	normal := mgl64.Vec3{0, 1, 0}
+
+	// Standing on its base, 0.1 into the ground
+	collision, contacts := cone.CollideWithPlane(normal, 0, Transform{Position: mgl64.Vec3{0, 0.9, 0}, Rotation: mgl64.QuatIdent()})
+	if !collision || len(contacts) != 4 {
+		t.Fatalf("collision = %v, %d contacts, want 4", collision, len(contacts))
+	}
+	for _, contact := range contacts {
+		if !floatEqual(contact.Penetration, 0.1, 1e-9) {
+			t.Errorf("penetration = %v, want 0.1", contact.Penetration)
+		}
+	}
+
+	// Lying on its side: the apex and the deepest rim point touch the ground
+	slant := math.Atan2(1, 4)
+	rotation := mgl64.QuatRotate(math.Pi/2+slant, mgl64.Vec3{0, 0, 1})
+	lying := Transform{Rotation: rotation}
+	lying.Position = mgl64.Vec3{0, -rotation.Rotate(mgl64.Vec3{0, 3, 0}).Y() - 0.05, 0}
+	collision, contacts = cone.CollideWithPlane(normal, 0, lying)
+	if !collision || len(contacts) != 2 {
+		t.Fatalf("collision = %v, %d contacts, want 2 along the side", collision, len(contacts))
+	}
+	for _, contact := range contacts {
+		if !floatEqual(contact.Penetration, 0.05, 1e-6) {
+			t.Errorf("penetration = %v, want 0.05", contact.Penetration)
+		}
+	}
+
+	// Above the ground
+	if collision, _ := cone.CollideWithPlane(normal, 0, Transform{Position: mgl64.Vec3{0, 2, 0}, Rotation: mgl64.QuatIdent()}); collision {
+		t.Error("expected no collision above the plane")
+	}
+}
+
+func TestConeClosestPoint(t *testing.T) {
+	cone := &Cone{Radius: 1, Height: 4}
+
+	tests := []struct {
+		name     string
+		point    mgl64.Vec3
+		expected mgl64.Vec3
+	}{
+		{"inside", mgl64.Vec3{0.2, 0, 0.1}, mgl64.Vec3{0.2, 0, 0.1}},
+		{"above the apex", mgl64.Vec3{0, 5, 0}, mgl64.Vec3{0, 3, 0}},
+		{"under the base", mgl64.Vec3{0.5, -3, 0}, mgl64.Vec3{0.5, -1, 0}},
+		{"beyond the rim", mgl64.Vec3{0, -2, 3}, mgl64.Vec3{0, -1, 1}},
+		{"beside the side", mgl64.Vec3{4, 4, 0}, mgl64.Vec3{0, 3, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if closest := cone.ClosestPoint(tt.point); !vec3Equal(closest, tt.expected, 1e-9) {
+				t.Errorf("ClosestPoint(%v) = %v, want %v", tt.point, closest, tt.expected)
+			}
+		})
+	}
+
+	// A point facing the middle of the side is projected onto it
+	side := mgl64.Vec3{0.5, 1, 0}
+	outward := mgl64.Vec3{4, 1, 0}.Normalize()
+	if closest := cone.ClosestPoint(side.Add(outward)); !vec3Equal(closest, side, 1e-9) {
+		t.Errorf("ClosestPoint = %v, want %v", closest, side)
+	}
+}
+
+func TestConeBoundingRadius(t *testing.T) {
+	if radius := (&Cone{Radius: 1, Height: 4}).BoundingRadius(); !floatEqual(radius, 3, 1e-9) {
+		t.Errorf("BoundingRadius = %v, want 3 (apex)", radius)
+	}
+	if radius := (&Cone{Radius: 4, Height: 4}).BoundingRadius(); !floatEqual(radius, math.Sqrt(17), 1e-9) {
+		t.Errorf("BoundingRadius = %v, want sqrt(17) (rim)", radius)
+	}
+}
+
+func TestShapeMargin(t *testing.T) {
+	box := &Box{HalfExtents: mgl64.Vec3{1, 1, 1}}
+	box.SetMargin(0.02)
+	cone := &Cone{Radius: 1, Height: 2}
+	cone.SetMargin(-1)
+
+	if margin := ShapeMargin(box); margin != 0.02 {
+		t.Errorf("box margin = %v, want 0.02", margin)
+	}
+	if margin := ShapeMargin(cone); margin != 0 {
+		t.Errorf("negative margin = %v, want clamped to 0", margin)
+	}
+	if margin := ShapeMargin(&Plane{Normal: mgl64.Vec3{0, 1, 0}}); margin != 0 {
+		t.Errorf("plane margin = %v, want 0", margin)
+	}
+}
+
+func TestShapeRaycast(t *testing.T) {
+	down := mgl64.Vec3{0, -1, 0}
+	tests := []struct {
+		name      string
+		shape     ShapeInterface
+		origin    mgl64.Vec3
+		direction mgl64.Vec3
+		maxDist   float64
+		hit       bool
+		distance  float64
+		normal    mgl64.Vec3
+	}{
+		{"box top", &Box{HalfExtents: mgl64.Vec3{1, 2, 3}}, mgl64.Vec3{0.5, 5, 0}, down, 10, true, 3, mgl64.Vec3{0, 1, 0}},
+		{"box side", &Box{HalfExtents: mgl64.Vec3{1, 2, 3}}, mgl64.Vec3{-4, 1, 1}, mgl64.Vec3{1, 0, 0}, 10, true, 3, mgl64.Vec3{-1, 0, 0}},
+		{"box miss", &Box{HalfExtents: mgl64.Vec3{1, 2, 3}}, mgl64.Vec3{2, 5, 0}, down, 10, false, 0, mgl64.Vec3{}},
+		{"box too far", &Box{HalfExtents: mgl64.Vec3{1, 2, 3}}, mgl64.Vec3{0, 5, 0}, down, 2, false, 0, mgl64.Vec3{}},
+		{"box inside", &Box{HalfExtents: mgl64.Vec3{1, 2, 3}}, mgl64.Vec3{0, 1, 0}, down, 10, true, 0, mgl64.Vec3{0, 1, 0}},
+		{"sphere", &Sphere{Radius: 2}, mgl64.Vec3{0, 5, 0}, down, 10, true, 3, mgl64.Vec3{0, 1, 0}},
+		{"sphere behind", &Sphere{Radius: 2}, mgl64.Vec3{0, 5, 0}, mgl64.Vec3{0, 1, 0}, 10, false, 0, mgl64.Vec3{}},
+		{"sphere inside", &Sphere{Radius: 2}, mgl64.Vec3{1, 0, 0}, down, 10, true, 0, mgl64.Vec3{0, 1, 0}},
+		// Radius 1 and height 4: apex at y = 3, base at y = -1
+		{"cone apex", &Cone{Radius: 1, Height: 4}, mgl64.Vec3{0, 5, 0}, down, 10, true, 2, mgl64.Vec3{0, 1, 0}},
+		{"cone side", &Cone{Radius: 1, Height: 4}, mgl64.Vec3{-3, 1, 0}, mgl64.Vec3{1, 0, 0}, 10, true, 2.5, mgl64.Vec3{-4, 1, 0}.Normalize()},
+		{"cone base", &Cone{Radius: 1, Height: 4}, mgl64.Vec3{0.5, -3, 0}, mgl64.Vec3{0, 1, 0}, 10, true, 2, down},
+		{"cone miss above apex", &Cone{Radius: 1, Height: 4}, mgl64.Vec3{-3, 3.5, 0}, mgl64.Vec3{1, 0, 0}, 10, false, 0, mgl64.Vec3{}},
+		{"plane", &Plane{Normal: mgl64.Vec3{0, 1, 0}, Distance: 1}, mgl64.Vec3{0, 4, 0}, down, 10, true, 5, mgl64.Vec3{0, 1, 0}},
+		{"plane parallel", &Plane{Normal: mgl64.Vec3{0, 1, 0}}, mgl64.Vec3{0, 4, 0}, mgl64.Vec3{1, 0, 0}, 10, false, 0, mgl64.Vec3{}},
+		{"plane below", &Plane{Normal: mgl64.Vec3{0, 1, 0}}, mgl64.Vec3{0, -1, 0}, mgl64.Vec3{1, 0, 0}, 10, true, 0, mgl64.Vec3{-1, 0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			distance, normal, hit := tt.shape.Raycast(tt.origin, tt.direction, tt.maxDist)
+			if hit != tt.hit {
+				t.Fatalf("hit = %v, want %v", hit, tt.hit)
+			}
+			if !hit {
+				return
+			}
+			if math.Abs(distance-tt.distance) > 1e-9 || !vec3Equal(normal, tt.normal, 1e-9) {
+				t.Errorf("hit at %v with normal %v, want %v with normal %v", distance, normal, tt.distance, tt.normal)
+			}
+		})
+	}
+}
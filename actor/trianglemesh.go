@@ -0,0 +1,470 @@
+package actor
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// bvhLeafSize is the maximum number of triangles in a leaf of the BVH
+const bvhLeafSize = 4
+
+// ErrInvalidMesh is returned when a triangle mesh has no triangle, or an index out of its vertices
+var ErrInvalidMesh = errors.New("actor: triangle mesh requires triangles indexing its vertices")
+
+// TriangleMesh represents static level geometry made of triangles, for static bodies only.
+// The triangles are stored in a bounding volume hierarchy: the narrow phase tests each convex body
+// against the triangles overlapping its AABB only, each of them as a Triangle shape.
+type TriangleMesh struct {
+	// Vertices of the mesh in local space
+	Vertices []mgl64.Vec3
+	// Indices of the vertices of each triangle, counter-clockwise seen from the front side
+	Indices [][3]int
+
+	nodes []bvhNode
+	order []int // Triangle indices, the leaves hold consecutive ranges
+	aabb  AABB
+}
+
+// bvhNode is a node of the BVH, leaves have no children and hold order[first:first+count]
+type bvhNode struct {
+	bounds AABB
+	right  int // The left child follows its parent
+	first  int
+	count  int
+}
+
+// NewTriangleMesh creates a mesh from its vertices and triangles, and builds its BVH.
+// Returns ErrInvalidMesh if there is no triangle, or if an index is out of range.
+func NewTriangleMesh(vertices []mgl64.Vec3, indices [][3]int) (*TriangleMesh, error) {
+	if len(indices) == 0 {
+		return nil, ErrInvalidMesh
+	}
+	for _, triangle := range indices {
+		for _, index := range triangle {
+			if index < 0 || index >= len(vertices) {
+				return nil, ErrInvalidMesh
+			}
+		}
+	}
+
+	mesh := &TriangleMesh{Vertices: vertices, Indices: indices}
+	mesh.order = make([]int, len(indices))
+	for i := range mesh.order {
+		mesh.order[i] = i
+	}
+	mesh.build(0, len(indices))
+
+	return mesh, nil
+}
+
+// build creates the node holding order[first:last], splitting the triangles at the median
+// of their centroids along the longest axis. Returns the index of the node.
+func (m *TriangleMesh) build(first, last int) int {
+	index := len(m.nodes)
+	m.nodes = append(m.nodes, bvhNode{first: first, count: last - first})
+
+	bounds := m.triangleBounds(m.order[first])
+	centroidMin, centroidMax := m.centroid(m.order[first]), m.centroid(m.order[first])
+	for _, triangle := range m.order[first+1 : last] {
+		bounds = mergeAABB(bounds, m.triangleBounds(triangle))
+		centroid := m.centroid(triangle)
+		for i := 0; i < 3; i++ {
+			centroidMin[i] = math.Min(centroidMin[i], centroid[i])
+			centroidMax[i] = math.Max(centroidMax[i], centroid[i])
+		}
+	}
+	m.nodes[index].bounds = bounds
+
+	if last-first <= bvhLeafSize {
+		return index
+	}
+
+	extent := centroidMax.Sub(centroidMin)
+	axis := 0
+	if extent[1] > extent[axis] {
+		axis = 1
+	}
+	if extent[2] > extent[axis] {
+		axis = 2
+	}
+
+	triangles := m.order[first:last]
+	sort.Slice(triangles, func(i, j int) bool {
+		return m.centroid(triangles[i])[axis] < m.centroid(triangles[j])[axis]
+	})
+
+	middle := (first + last) / 2
+	m.build(first, middle)
+	right := m.build(middle, last)
+	m.nodes[index].right = right
+	m.nodes[index].count = 0
+
+	return index
+}
+
+func (m *TriangleMesh) triangleBounds(triangle int) AABB {
+	a, b, c := m.triangleVertices(triangle)
+	bounds := AABB{Min: a, Max: a}
+	for _, vertex := range [2]mgl64.Vec3{b, c} {
+		for i := 0; i < 3; i++ {
+			bounds.Min[i] = math.Min(bounds.Min[i], vertex[i])
+			bounds.Max[i] = math.Max(bounds.Max[i], vertex[i])
+		}
+	}
+
+	return bounds
+}
+
+func (m *TriangleMesh) centroid(triangle int) mgl64.Vec3 {
+	a, b, c := m.triangleVertices(triangle)
+
+	return a.Add(b).Add(c).Mul(1.0 / 3.0)
+}
+
+func (m *TriangleMesh) triangleVertices(triangle int) (mgl64.Vec3, mgl64.Vec3, mgl64.Vec3) {
+	indices := m.Indices[triangle]
+
+	return m.Vertices[indices[0]], m.Vertices[indices[1]], m.Vertices[indices[2]]
+}
+
+// Triangle returns the shape of a triangle of the mesh, in the local space of the mesh
+func (m *TriangleMesh) Triangle(triangle int) *Triangle {
+	a, b, c := m.triangleVertices(triangle)
+
+	return &Triangle{A: a, B: b, C: c}
+}
+
+// Overlapping appends to result the triangles whose bounds overlap the world bounds,
+// for the mesh placed at transform
+func (m *TriangleMesh) Overlapping(worldBounds AABB, transform Transform, result []int) []int {
+	inverseRotation := transform.Rotation.Conjugate()
+	bounds := transformAABB(worldBounds, Transform{
+		Position: inverseRotation.Rotate(transform.Position).Mul(-1),
+		Rotation: inverseRotation,
+	})
+
+	// The median split keeps the depth logarithmic, 64 levels hold any mesh
+	var stack [64]int
+	size := 1
+
+	for size > 0 {
+		size--
+		index := stack[size]
+		node := &m.nodes[index]
+		if !node.bounds.Overlaps(bounds) {
+			continue
+		}
+
+		if node.count > 0 {
+			for _, triangle := range m.order[node.first : node.first+node.count] {
+				if m.triangleBounds(triangle).Overlaps(bounds) {
+					result = append(result, triangle)
+				}
+			}
+			continue
+		}
+
+		// The left child follows its parent
+		stack[size] = index + 1
+		stack[size+1] = node.right
+		size += 2
+	}
+
+	return result
+}
+
+func (m *TriangleMesh) ComputeAABB(transform Transform) {
+	bounds := m.nodes[0].bounds
+	m.aabb = transformAABB(bounds, transform)
+}
+
+func (m *TriangleMesh) GetAABB() AABB {
+	return m.aabb
+}
+
+// ComputeMass returns an infinite mass, meshes are static
+func (m *TriangleMesh) ComputeMass(density float64) float64 {
+	return math.Inf(1)
+}
+
+func (m *TriangleMesh) ComputeInertia(mass float64) mgl64.Mat3 {
+	return mgl64.Mat3{}
+}
+
+// Support returns the farthest vertex, the mesh is handled as its convex hull.
+// The narrow phase has a specific code path for meshes, it tests their triangles instead.
+func (m *TriangleMesh) Support(direction mgl64.Vec3) mgl64.Vec3 {
+	best := m.Vertices[0]
+	bestDot := best.Dot(direction)
+	for _, vertex := range m.Vertices[1:] {
+		if dot := vertex.Dot(direction); dot > bestDot {
+			best, bestDot = vertex, dot
+		}
+	}
+
+	return best
+}
+
+// The narrow phase has specific code path for meshes, this should not be called
+func (m *TriangleMesh) GetContactFeature(direction mgl64.Vec3, output *[8]mgl64.Vec3, count *int) {
+	output[0] = m.Support(direction)
+	*count = 1
+}
+
+// CollideWithPlane - TriangleMesh/Plane collision (not supported, both are static)
+func (m *TriangleMesh) CollideWithPlane(planeNormal mgl64.Vec3, planeDistance float64, myTransform Transform) (bool, PlaneContact) {
+	return false, PlaneContact{}
+}
+
+// ClosestPoint returns the closest point of the triangles, the mesh has no inside
+func (m *TriangleMesh) ClosestPoint(point mgl64.Vec3) mgl64.Vec3 {
+	var closest mgl64.Vec3
+	closestDistSq := math.Inf(1)
+	for triangle := range m.Indices {
+		a, b, c := m.triangleVertices(triangle)
+		candidate := closestPointOnTriangle(point, a, b, c)
+		if distSq := candidate.Sub(point).LenSqr(); distSq < closestDistSq {
+			closest, closestDistSq = candidate, distSq
+		}
+	}
+
+	return closest
+}
+
+// ContainsPoint is always false, the mesh is a surface without volume
+func (m *TriangleMesh) ContainsPoint(point mgl64.Vec3) bool {
+	return false
+}
+
+// BoundingRadius returns the distance from the local origin to the farthest vertex
+func (m *TriangleMesh) BoundingRadius() float64 {
+	radius := 0.0
+	for _, vertex := range m.Vertices {
+		radius = math.Max(radius, vertex.Len())
+	}
+
+	return radius
+}
+
+// Raycast walks the BVH and returns the nearest triangle hit, from either side
+func (m *TriangleMesh) Raycast(origin, direction mgl64.Vec3, maxDist float64) (float64, mgl64.Vec3, bool) {
+	best := maxDist
+	var normal mgl64.Vec3
+	hit := false
+
+	var stack [64]int
+	size := 1
+
+	for size > 0 {
+		size--
+		index := stack[size]
+		node := &m.nodes[index]
+		if _, ok := node.bounds.RayHit(origin, direction, best); !ok {
+			continue
+		}
+
+		if node.count > 0 {
+			for _, triangle := range m.order[node.first : node.first+node.count] {
+				a, b, c := m.triangleVertices(triangle)
+				if distance, triangleNormal, ok := rayTriangle(origin, direction, a, b, c, best); ok {
+					best, normal, hit = distance, triangleNormal, true
+				}
+			}
+			continue
+		}
+
+		stack[size] = index + 1
+		stack[size+1] = node.right
+		size += 2
+	}
+
+	return best, normal, hit
+}
+
+// Triangle is a single triangle, used by the narrow phase to collide convex bodies with a TriangleMesh.
+// It has no volume: GJK and EPA rely on the volume of the other shape.
+type Triangle struct {
+	A, B, C mgl64.Vec3
+	aabb    AABB
+}
+
+func (t *Triangle) ComputeAABB(transform Transform) {
+	t.aabb = transformAABB(AABB{
+		Min: mgl64.Vec3{math.Min(t.A.X(), math.Min(t.B.X(), t.C.X())), math.Min(t.A.Y(), math.Min(t.B.Y(), t.C.Y())), math.Min(t.A.Z(), math.Min(t.B.Z(), t.C.Z()))},
+		Max: mgl64.Vec3{math.Max(t.A.X(), math.Max(t.B.X(), t.C.X())), math.Max(t.A.Y(), math.Max(t.B.Y(), t.C.Y())), math.Max(t.A.Z(), math.Max(t.B.Z(), t.C.Z()))},
+	}, transform)
+}
+
+func (t *Triangle) GetAABB() AABB {
+	return t.aabb
+}
+
+// ComputeMass returns an infinite mass, triangles belong to static meshes
+func (t *Triangle) ComputeMass(density float64) float64 {
+	return math.Inf(1)
+}
+
+func (t *Triangle) ComputeInertia(mass float64) mgl64.Mat3 {
+	return mgl64.Mat3{}
+}
+
+func (t *Triangle) Support(direction mgl64.Vec3) mgl64.Vec3 {
+	best := t.A
+	bestDot := t.A.Dot(direction)
+	if dot := t.B.Dot(direction); dot > bestDot {
+		best, bestDot = t.B, dot
+	}
+	if dot := t.C.Dot(direction); dot > bestDot {
+		best = t.C
+	}
+
+	return best
+}
+
+// GetContactFeature returns the whole triangle if the direction is close to its normal,
+// otherwise the edge or the vertex the most aligned with the direction
+func (t *Triangle) GetContactFeature(direction mgl64.Vec3, output *[8]mgl64.Vec3, count *int) {
+	vertices := [3]mgl64.Vec3{t.A, t.B, t.C}
+	indices, n, _ := t.contactFeature(direction)
+	for i := 0; i < n; i++ {
+		output[i] = vertices[indices[i]]
+	}
+	*count = n
+}
+
+// FeatureIndex returns 0 for the face, 1+i for the vertex i (A, B, C) and 4+i for the edge from the vertex i to the next one
+func (t *Triangle) FeatureIndex(direction mgl64.Vec3) int {
+	_, _, feature := t.contactFeature(direction)
+
+	return feature
+}
+
+// contactFeature returns the vertices (0: A, 1: B, 2: C) of the feature the most aligned with the direction,
+// in the order of the triangle, their count and the index of the feature, see FeatureIndex
+func (t *Triangle) contactFeature(direction mgl64.Vec3) ([3]int, int, int) {
+	const faceThreshold = 0.7071 // cos(45°)
+	const edgeThreshold = 0.02
+
+	normal := t.B.Sub(t.A).Cross(t.C.Sub(t.A)).Normalize()
+	direction = direction.Normalize()
+	if math.Abs(normal.Dot(direction)) >= faceThreshold {
+		return [3]int{0, 1, 2}, 3, 0
+	}
+
+	// Sort the vertices by their projection, the two highest form an edge if they are close
+	vertices := [3]mgl64.Vec3{t.A, t.B, t.C}
+	indices := [3]int{0, 1, 2}
+	sort.Slice(indices[:], func(i, j int) bool {
+		return vertices[indices[i]].Dot(direction) > vertices[indices[j]].Dot(direction)
+	})
+
+	first, second := indices[0], indices[1]
+	if edge := vertices[first].Sub(vertices[second]); edge.Dot(direction) > edgeThreshold*edge.Len() {
+		return [3]int{first}, 1, 1 + first
+	}
+
+	// The edge from the vertex i to the next one
+	if second == (first+2)%3 {
+		first, second = second, first
+	}
+
+	return [3]int{first, second}, 2, 4 + first
+}
+
+// CollideWithPlane - Triangle/Plane collision (not supported, both are static)
+func (t *Triangle) CollideWithPlane(planeNormal mgl64.Vec3, planeDistance float64, myTransform Transform) (bool, PlaneContact) {
+	return false, PlaneContact{}
+}
+
+func (t *Triangle) ClosestPoint(point mgl64.Vec3) mgl64.Vec3 {
+	return closestPointOnTriangle(point, t.A, t.B, t.C)
+}
+
+// ContainsPoint is always false, the triangle has no volume
+func (t *Triangle) ContainsPoint(point mgl64.Vec3) bool {
+	return false
+}
+
+// BoundingRadius returns the distance from the local origin to the farthest vertex
+func (t *Triangle) BoundingRadius() float64 {
+	return math.Max(t.A.Len(), math.Max(t.B.Len(), t.C.Len()))
+}
+
+// Raycast intersects the ray with the triangle, from either side
+func (t *Triangle) Raycast(origin, direction mgl64.Vec3, maxDist float64) (float64, mgl64.Vec3, bool) {
+	return rayTriangle(origin, direction, t.A, t.B, t.C, maxDist)
+}
+
+// rayTriangle intersects the ray with the triangle abc (Möller-Trumbore).
+// The normal faces the origin of the ray.
+func rayTriangle(origin, direction, a, b, c mgl64.Vec3, maxDist float64) (float64, mgl64.Vec3, bool) {
+	ab, ac := b.Sub(a), c.Sub(a)
+	p := direction.Cross(ac)
+	determinant := ab.Dot(p)
+	if math.Abs(determinant) < 1e-12 {
+		return 0, mgl64.Vec3{}, false
+	}
+
+	inverse := 1 / determinant
+	ao := origin.Sub(a)
+	u := ao.Dot(p) * inverse
+	if u < 0 || u > 1 {
+		return 0, mgl64.Vec3{}, false
+	}
+	q := ao.Cross(ab)
+	v := direction.Dot(q) * inverse
+	if v < 0 || u+v > 1 {
+		return 0, mgl64.Vec3{}, false
+	}
+
+	distance := ac.Dot(q) * inverse
+	if distance < 0 || distance > maxDist {
+		return 0, mgl64.Vec3{}, false
+	}
+
+	normal := ab.Cross(ac).Normalize()
+	if normal.Dot(direction) > 0 {
+		normal = normal.Mul(-1)
+	}
+
+	return distance, normal, true
+}
+
+// mergeAABB returns the bounds enclosing both a and b
+func mergeAABB(a, b AABB) AABB {
+	for i := 0; i < 3; i++ {
+		a.Min[i] = math.Min(a.Min[i], b.Min[i])
+		a.Max[i] = math.Max(a.Max[i], b.Max[i])
+	}
+
+	return a
+}
+
+// transformAABB returns the world bounds of the local bounds, enclosing its 8 transformed corners
+func transformAABB(bounds AABB, transform Transform) AABB {
+	var result AABB
+	for i := 0; i < 8; i++ {
+		corner := mgl64.Vec3{bounds.Min.X(), bounds.Min.Y(), bounds.Min.Z()}
+		if i&1 != 0 {
+			corner[0] = bounds.Max.X()
+		}
+		if i&2 != 0 {
+			corner[1] = bounds.Max.Y()
+		}
+		if i&4 != 0 {
+			corner[2] = bounds.Max.Z()
+		}
+
+		worldCorner := transform.Rotation.Rotate(corner).Add(transform.Position)
+		if i == 0 {
+			result = AABB{Min: worldCorner, Max: worldCorner}
+			continue
+		}
+		result = mergeAABB(result, AABB{Min: worldCorner, Max: worldCorner})
+	}
+
+	return result
+}
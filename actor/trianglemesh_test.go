@@ -0,0 +1,175 @@
+package actor
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// gridMesh returns a flat floor at y=0 of n×n quads of the given size, centered on the origin
+func gridMesh(n int, size float64) ([]mgl64.Vec3, [][3]int) {
+	var vertices []mgl64.Vec3
+	offset := float64(n) * size / 2
+	for i := 0; i <= n; i++ {
+		for j := 0; j <= n; j++ {
+			vertices = append(vertices, mgl64.Vec3{float64(i)*size - offset, 0, float64(j)*size - offset})
+		}
+	}
+
+	var indices [][3]int
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			a := i*(n+1) + j
+			b := a + n + 1
+			indices = append(indices, [3]int{a, a + 1, b}, [3]int{b, a + 1, b + 1})
+		}
+	}
+
+	return vertices, indices
+}
+
+func TestNewTriangleMesh_Invalid(t *testing.T) {
+	vertices := []mgl64.Vec3{{0, 0, 0}, {1, 0, 0}, {0, 0, 1}}
+
+	if _, err := NewTriangleMesh(vertices, nil); !errors.Is(err, ErrInvalidMesh) {
+		t.Errorf("err = %v without triangles, want ErrInvalidMesh", err)
+	}
+	if _, err := NewTriangleMesh(vertices, [][3]int{{0, 1, 3}}); !errors.Is(err, ErrInvalidMesh) {
+		t.Errorf("err = %v with an index out of range, want ErrInvalidMesh", err)
+	}
+}
+
+func TestTriangleMeshOverlapping(t *testing.T) {
+	vertices, indices := gridMesh(16, 1)
+	mesh, err := NewTriangleMesh(vertices, indices)
+	if err != nil {
+		t.Fatalf("NewTriangleMesh failed: %v", err)
+	}
+	transform := Transform{Position: mgl64.Vec3{3, 1, -2}, Rotation: mgl64.QuatRotate(math.Pi/6, mgl64.Vec3{0, 1, 0})}
+
+	queries := []AABB{
+		{Min: mgl64.Vec3{2.5, 0.5, -2.5}, Max: mgl64.Vec3{3.5, 1.5, -1.5}},
+		{Min: mgl64.Vec3{-10, 0, -10}, Max: mgl64.Vec3{20, 2, 20}},
+		{Min: mgl64.Vec3{8, 0.9, 0}, Max: mgl64.Vec3{9, 1.1, 1}},
+		{Min: mgl64.Vec3{3, 5, -2}, Max: mgl64.Vec3{4, 6, -1}},
+	}
+
+	for _, query := range queries {
+		// Brute force in local space, the same conservative bounds as the BVH
+		inverse := transform.Rotation.Conjugate()
+		local := transformAABB(query, Transform{Position: inverse.Rotate(transform.Position).Mul(-1), Rotation: inverse})
+		var expected []int
+		for triangle := range indices {
+			if mesh.triangleBounds(triangle).Overlaps(local) {
+				expected = append(expected, triangle)
+			}
+		}
+
+		result := mesh.Overlapping(query, transform, nil)
+		sort.Ints(result)
+		if len(result) != len(expected) {
+			t.Errorf("query %v: %d triangles, brute force finds %d", query, len(result), len(expected))
+			continue
+		}
+		for i := range result {
+			if result[i] != expected[i] {
+				t.Errorf("query %v: triangles %v, want %v", query, result, expected)
+				break
+			}
+		}
+	}
+}
+
+func TestTriangleMeshComputeAABB(t *testing.T) {
+	vertices, indices := gridMesh(4, 1)
+	mesh, err := NewTriangleMesh(vertices, indices)
+	if err != nil {
+		t.Fatalf("NewTriangleMesh failed: %v", err)
+	}
+
+	mesh.ComputeAABB(Transform{Position: mgl64.Vec3{0, -1, 0}, Rotation: mgl64.QuatIdent()})
+	aabb := mesh.GetAABB()
+	if !vec3Equal(aabb.Min, mgl64.Vec3{-2, -1, -2}, 1e-9) || !vec3Equal(aabb.Max, mgl64.Vec3{2, -1, 2}, 1e-9) {
+		t.Errorf("AABB = %v", aabb)
+	}
+
+	if closest := mesh.ClosestPoint(mgl64.Vec3{0.3, 2, -5}); !vec3Equal(closest, mgl64.Vec3{0.3, 0, -2}, 1e-9) {
+		t.Errorf("ClosestPoint = %v, want {0.3 0 -2}", closest)
+	}
+}
+
+func TestTriangleGetContactFeature(t *testing.T) {
+	triangle := &Triangle{A: mgl64.Vec3{0, 0, 0}, B: mgl64.Vec3{0, 0, 1}, C: mgl64.Vec3{1, 0, 0}}
+	var output [8]mgl64.Vec3
+	var count int
+
+	triangle.GetContactFeature(mgl64.Vec3{0.1, 1, 0}, &output, &count)
+	if count != 3 {
+		t.Errorf("face feature count = %d, want 3", count)
+	}
+
+	// Edge A-B, along Z
+	triangle.GetContactFeature(mgl64.Vec3{-1, 0, 0}, &output, &count)
+	if count != 2 || output[0].X() != 0 || output[1].X() != 0 {
+		t.Errorf("edge feature = %v (count %d), want the edge at x=0", output[:count], count)
+	}
+
+	triangle.GetContactFeature(mgl64.Vec3{1, 0, -0.5}, &output, &count)
+	if count != 1 || output[0] != triangle.C {
+		t.Errorf("vertex feature = %v (count %d), want C", output[:count], count)
+	}
+}
+
+func TestTriangleFeatureIndex(t *testing.T) {
+	triangle := &Triangle{A: mgl64.Vec3{0, 0, 0}, B: mgl64.Vec3{0, 0, 1}, C: mgl64.Vec3{1, 0, 0}}
+	tests := []struct {
+		direction mgl64.Vec3
+		want      int
+	}{
+		{mgl64.Vec3{0.1, 1, 0}, 0},
+		{mgl64.Vec3{1, 0, -0.5}, 3}, // C
+		{mgl64.Vec3{-1, 0, 0}, 4},   // A-B
+		{mgl64.Vec3{-1, 0, 0.01}, 4},
+		{mgl64.Vec3{1, 0, 1}, 5}, // B-C
+	}
+	for _, tt := range tests {
+		if feature := triangle.FeatureIndex(tt.direction); feature != tt.want {
+			t.Errorf("FeatureIndex(%v) = %d, want %d", tt.direction, feature, tt.want)
+		}
+	}
+
+	// The vertices of an edge keep the order of the triangle, whichever is the highest
+	var output [8]mgl64.Vec3
+	var count int
+	triangle.GetContactFeature(mgl64.Vec3{-1, 0, 0.01}, &output, &count)
+	if count != 2 || output[0] != triangle.A || output[1] != triangle.B {
+		t.Errorf("edge feature = %v, want A then B", output[:count])
+	}
+}
+
+func TestTriangleMeshRaycast(t *testing.T) {
+	vertices, indices := gridMesh(8, 1)
+	mesh, err := NewTriangleMesh(vertices, indices)
+	if err != nil {
+		t.Fatalf("NewTriangleMesh failed: %v", err)
+	}
+
+	// From above and from below: both sides are hit, the normal faces the ray
+	for _, origin := range []mgl64.Vec3{{1.3, 2, 2.6}, {-1.3, -2, 2.6}} {
+		direction := mgl64.Vec3{0, -math.Copysign(1, origin.Y()), 0}
+		distance, normal, hit := mesh.Raycast(origin, direction, 10)
+		if !hit || math.Abs(distance-2) > 1e-9 || !vec3Equal(normal, direction.Mul(-1), 1e-9) {
+			t.Errorf("from %v: hit %v at %v with normal %v, want 2 facing the ray", origin, hit, distance, normal)
+		}
+	}
+
+	if _, _, hit := mesh.Raycast(mgl64.Vec3{9, 2, 1}, mgl64.Vec3{0, -1, 0}, 10); hit {
+		t.Error("expected the ray beside the mesh to miss")
+	}
+	if _, _, hit := mesh.Raycast(mgl64.Vec3{3, 2, 3}, mgl64.Vec3{0, -1, 0}, 1.5); hit {
+		t.Error("expected no hit within 1.5")
+	}
+}
@@ -0,0 +1,122 @@
+package feather
+
+import (
+	"sync"
+
+	"github.com/akmonengine/feather/constraint"
+)
+
+// minArenaChunk is the size of the first chunk of an ArenaAllocator buffer
+const minArenaChunk = 64
+
+// Allocator provides the transient structures of a Step: the contacts found at each substep,
+// their points, and the lists holding them. They are all released by Reset, called at the start
+// of the next Step, so the contacts given to the PreSolve hook must not be kept longer.
+// Contact and Points are called concurrently by the narrow phase workers.
+type Allocator interface {
+	// Reset releases the structures handed out during the previous Step
+	Reset()
+	// Contact returns a zeroed contact constraint
+	Contact() *constraint.ContactConstraint
+	// Points returns zeroed contact points, its capacity is clipped to n
+	Points(n int) []constraint.ContactPoint
+	// Contacts returns an empty list of contacts with at least the given capacity
+	Contacts(capacity int) []*constraint.ContactConstraint
+}
+
+// heapAllocator allocates every structure on the heap, it is used when World.Allocator is nil
+type heapAllocator struct{}
+
+func (heapAllocator) Reset() {}
+
+func (heapAllocator) Contact() *constraint.ContactConstraint {
+	return &constraint.ContactConstraint{}
+}
+
+func (heapAllocator) Points(n int) []constraint.ContactPoint {
+	return make([]constraint.ContactPoint, n)
+}
+
+func (heapAllocator) Contacts(capacity int) []*constraint.ContactConstraint {
+	return make([]*constraint.ContactConstraint, 0, capacity)
+}
+
+// ArenaAllocator is a bump allocator: the structures are carved out of chunks kept between steps,
+// so once the chunks fit the busiest Step, the contacts no longer allocate.
+// Intended for servers stepping many worlds, where the garbage collector pauses matter.
+type ArenaAllocator struct {
+	mutex    sync.Mutex
+	contacts bumpBuffer[constraint.ContactConstraint]
+	points   bumpBuffer[constraint.ContactPoint]
+	lists    bumpBuffer[*constraint.ContactConstraint]
+}
+
+// NewArenaAllocator creates an empty ArenaAllocator, its chunks grow during the first steps
+func NewArenaAllocator() *ArenaAllocator {
+	return &ArenaAllocator{}
+}
+
+func (a *ArenaAllocator) Reset() {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.contacts.reset()
+	a.points.reset()
+	a.lists.reset()
+}
+
+func (a *ArenaAllocator) Contact() *constraint.ContactConstraint {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	return &a.contacts.take(1)[0]
+}
+
+func (a *ArenaAllocator) Points(n int) []constraint.ContactPoint {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	return a.points.take(n)
+}
+
+func (a *ArenaAllocator) Contacts(capacity int) []*constraint.ContactConstraint {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	return a.lists.take(capacity)[:0]
+}
+
+// bumpBuffer hands out consecutive slices of its chunks. The chunks are never moved,
+// so the slices stay valid until reset, and are kept after it to be reused.
+type bumpBuffer[T any] struct {
+	chunks [][]T
+	chunk  int
+	used   int
+}
+
+// take returns n zeroed elements, a new chunk twice as large is allocated when the current ones are full
+func (b *bumpBuffer[T]) take(n int) []T {
+	for b.chunk < len(b.chunks) && b.used+n > len(b.chunks[b.chunk]) {
+		b.chunk++
+		b.used = 0
+	}
+
+	if b.chunk == len(b.chunks) {
+		size := minArenaChunk
+		if len(b.chunks) > 0 {
+			size = 2 * len(b.chunks[len(b.chunks)-1])
+		}
+		b.chunks = append(b.chunks, make([]T, max(size, n)))
+	}
+
+	slice := b.chunks[b.chunk][b.used : b.used+n : b.used+n]
+	b.used += n
+	clear(slice)
+
+	return slice
+}
+
+func (b *bumpBuffer[T]) reset() {
+	b.chunk = 0
+	b.used = 0
+}
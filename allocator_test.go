@@ -0,0 +1,88 @@
+package feather
+
+import (
+	"math"
+	"testing"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/akmonengine/feather/constraint"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestArenaAllocator_ReuseAfterReset(t *testing.T) {
+	arena := NewArenaAllocator()
+
+	first := arena.Contact()
+	first.Normal = mgl64.Vec3{0, 1, 0}
+	second := arena.Contact()
+	if first == second {
+		t.Fatal("expected distinct contacts within a step")
+	}
+
+	arena.Reset()
+	reused := arena.Contact()
+	if reused != first {
+		t.Error("expected the first contact to be reused after Reset")
+	}
+	if reused.Normal != (mgl64.Vec3{}) {
+		t.Errorf("expected a zeroed contact, got normal %v", reused.Normal)
+	}
+}
+
+func TestArenaAllocator_Points(t *testing.T) {
+	arena := NewArenaAllocator()
+
+	points := arena.Points(3)
+	next := arena.Points(2)
+	next[0].Penetration = 1
+
+	// The capacity is clipped, appending never overwrites the next points
+	points = append(points, constraint.ContactPoint{Penetration: 2})
+	if next[0].Penetration != 1 {
+		t.Errorf("append overwrote the next points: %v", next)
+	}
+
+	// Larger than a chunk
+	if large := arena.Points(10 * minArenaChunk); len(large) != 10*minArenaChunk {
+		t.Errorf("len = %d, want %d", len(large), 10*minArenaChunk)
+	}
+}
+
+func TestArenaAllocator_Contacts(t *testing.T) {
+	arena := NewArenaAllocator()
+
+	contacts := arena.Contacts(5)
+	if len(contacts) != 0 || cap(contacts) < 5 {
+		t.Errorf("len = %d, cap = %d, want an empty list with a capacity of 5", len(contacts), cap(contacts))
+	}
+}
+
+// TestWorld_ArenaAllocator steps a stack with an arena, it must settle as with the heap allocator
+// and the arena chunks must stop growing once warmed up
+func TestWorld_ArenaAllocator(t *testing.T) {
+	arena := NewArenaAllocator()
+	world := &World{Substeps: 4, Gravity: mgl64.Vec3{0, -9.81, 0}, Events: NewEvents(), Allocator: arena}
+	world.AddBody(createPlane(mgl64.Vec3{0, 1, 0}, 0))
+	box := createBox(mgl64.Vec3{0, 0.6, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+	sphere := createSphere(mgl64.Vec3{0, 1.8, 0}, 0.5, actor.BodyTypeDynamic)
+	world.AddBody(box)
+	world.AddBody(sphere)
+
+	for range 30 {
+		world.Step(1.0 / 60.0)
+	}
+	if y := box.Transform.Position.Y(); math.Abs(y-0.5) > 0.01 {
+		t.Errorf("box height = %v, want about 0.5", y)
+	}
+	if y := sphere.Transform.Position.Y(); math.Abs(y-1.5) > 0.01 {
+		t.Errorf("sphere height = %v, want about 1.5", y)
+	}
+
+	chunks := len(arena.contacts.chunks) + len(arena.points.chunks) + len(arena.lists.chunks)
+	for range 30 {
+		world.Step(1.0 / 60.0)
+	}
+	if grown := len(arena.contacts.chunks) + len(arena.points.chunks) + len(arena.lists.chunks); grown != chunks {
+		t.Errorf("arena chunks grew from %d to %d in steady state", chunks, grown)
+	}
+}
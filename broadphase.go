@@ -0,0 +1,98 @@
+package feather
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// Broadphase indexes the bodies by their AABB, to find the pairs that may collide and the candidates of the queries.
+// SpatialGrid and DynamicTree implement it, see World.Broadphase.
+// The bodies are identified by their index in the slice given to Update.
+type Broadphase interface {
+	// Update indexes the bodies, called at each substep with the bodies of the world.
+	// The disabled bodies are not indexed, see actor.RigidBody.SetEnabled.
+	Update(bodies []*actor.RigidBody)
+	// FindPairs returns the pairs whose AABB overlap, from the bodies indexed by the last Update.
+	// Static/static and sleeping/sleeping pairs are skipped, planes are paired with every other body
+	// (the plane first), triangle meshes with the active bodies overlapping them (the mesh first).
+	FindPairs(bodies []*actor.RigidBody, workersCount int) <-chan Pair
+	// QueryRegion calls fn for the bodies that may overlap region, planes and triangle meshes included.
+	// An index can be reported several times. Returns false if the index cannot answer cheaply
+	// (e.g. the region covers more cells than the grid holds): the caller then tests all the bodies.
+	QueryRegion(region actor.AABB, fn func(bodyIndex int)) bool
+	// QueryRay calls fn for the bodies that may be hit by the ray within reach(), planes and triangle meshes included.
+	// The direction is normalized, reach() never exceeds maxDist and may only shrink. Returns false like QueryRegion.
+	QueryRay(origin, direction mgl64.Vec3, maxDist float64, reach func() float64, fn func(bodyIndex int)) bool
+}
+
+// broadphase returns the index of the bodies used by the world: the Broadphase if set, else the SpatialGrid
+func (w *World) broadphase() Broadphase {
+	if w.Broadphase != nil {
+		return w.Broadphase
+	}
+	if w.SpatialGrid != nil {
+		return w.SpatialGrid
+	}
+
+	return nil
+}
+
+// indexedBroadphase returns the broadphase if it indexes the current Bodies, nil when the last Step used the brute force
+// broad phase or when bodies were added or removed since: the queries then test all the bodies
+func (w *World) indexedBroadphase() Broadphase {
+	if w.bruteForce || w.staleBroadphase {
+		return nil
+	}
+
+	return w.broadphase()
+}
+
+// broadPhaseWorkers returns the goroutines of the broad phase pair search: BroadPhaseWorkers, else all the cores
+func (w *World) broadPhaseWorkers() int {
+	if w.BroadPhaseWorkers > 0 {
+		return w.BroadPhaseWorkers
+	}
+
+	return runtime.GOMAXPROCS(0)
+}
+
+// pairTasks runs tasks on a pool of workersCount goroutines, each task appending its pairs to its own buffer,
+// and streams the buffers in the order of the tasks: the order of the pairs does not depend on the scheduling
+// nor on workersCount. The buffers are reused from one call to the next, the channel must be drained before.
+func pairTasks(buffers *[][]Pair, tasks, workersCount int, run func(task int, pairs []Pair) []Pair) <-chan Pair {
+	pairsChan := make(chan Pair, 64)
+	if len(*buffers) < tasks {
+		*buffers = append(*buffers, make([][]Pair, tasks-len(*buffers))...)
+	}
+	results := (*buffers)[:tasks]
+	ready := make([]sync.WaitGroup, tasks)
+	for i := range ready {
+		ready[i].Add(1)
+	}
+
+	var next atomic.Int64
+	for workerID := 0; workerID < max(1, min(workersCount, tasks)); workerID++ {
+		go func() {
+			for task := int(next.Add(1) - 1); task < tasks; task = int(next.Add(1) - 1) {
+				results[task] = run(task, results[task][:0])
+				ready[task].Done()
+			}
+		}()
+	}
+
+	go func() {
+		defer close(pairsChan)
+		for task := range results {
+			ready[task].Wait()
+			for _, pair := range results[task] {
+				pairsChan <- pair
+			}
+		}
+	}()
+
+	return pairsChan
+}
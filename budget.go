@@ -0,0 +1,169 @@
+package feather
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/akmonengine/feather/constraint"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// deferIslands defers the islands farthest from BudgetFocus until the remaining substeps are predicted to fit
+// in the StepBudget. Their bodies are frozen for the rest of the Step and owe the remaining time, at most dt,
+// simulated at the start of the next Step, see catchUpSubsteps.
+func (w *World) deferIslands(start time.Time, last time.Duration, remaining int, h, dt float64) {
+	if w.deferred == nil {
+		w.deferred = make(map[*actor.RigidBody]float64)
+	}
+
+	keep := float64(w.StepBudget-time.Since(start)) / float64(last*time.Duration(remaining))
+	for _, island := range farIslands(w.islands, w.BudgetFocus, keep) {
+		dropped := false
+		for _, body := range island.bodies() {
+			if body.IsDeferred() || !body.IsActive() {
+				continue
+			}
+
+			body.SetDeferred(true)
+			owed := w.deferred[body] + float64(remaining)*h
+			if owed > dt {
+				owed, dropped = dt, true
+			}
+			w.deferred[body] = owed
+		}
+
+		w.counters.deferredIslands++
+		if dropped {
+			w.counters.droppedIslands++
+		}
+	}
+}
+
+// farIslands returns the islands to defer, the farthest from the focus first, so the cost of the kept ones
+// (their contacts and constraints) is at most the fraction keep of the total
+func farIslands(islands []island, focus mgl64.Vec3, keep float64) []island {
+	type candidate struct {
+		island   island
+		distance float64
+	}
+
+	candidates := make([]candidate, len(islands))
+	kept := 0
+	for i, island := range islands {
+		candidates[i] = candidate{island: island, distance: island.distance(focus)}
+		kept += island.cost()
+	}
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		return cmp.Compare(b.distance, a.distance)
+	})
+
+	limit := keep * float64(kept)
+	var deferred []island
+	for _, candidate := range candidates {
+		if float64(kept) <= limit {
+			break
+		}
+		deferred = append(deferred, candidate.island)
+		kept -= candidate.island.cost()
+	}
+
+	return deferred
+}
+
+// cost estimates the solver and narrow phase work of the island by its contacts and constraints
+func (is island) cost() int {
+	return len(is.contacts) + len(is.constraints)
+}
+
+// bodies returns the dynamic bodies of the island, a body may be listed more than once
+func (is island) bodies() []*actor.RigidBody {
+	var bodies []*actor.RigidBody
+	add := func(body *actor.RigidBody) {
+		if body != nil && body.BodyType == actor.BodyTypeDynamic {
+			bodies = append(bodies, body)
+		}
+	}
+
+	for _, c := range is.contacts {
+		add(c.BodyA)
+		add(c.BodyB)
+	}
+	for _, c := range is.constraints {
+		if connector, ok := c.(constraint.Connector); ok {
+			for _, body := range connector.Bodies() {
+				add(body)
+			}
+		}
+	}
+
+	return bodies
+}
+
+// distance returns the distance from the point to the AABB of the dynamic bodies of the island, 0 inside
+func (is island) distance(point mgl64.Vec3) float64 {
+	bodies := is.bodies()
+	if len(bodies) == 0 {
+		return 0
+	}
+
+	bounds := bodies[0].Shape.GetAABB()
+	for _, body := range bodies[1:] {
+		aabb := body.Shape.GetAABB()
+		for axis := range 3 {
+			bounds.Min[axis] = min(bounds.Min[axis], aabb.Min[axis])
+			bounds.Max[axis] = max(bounds.Max[axis], aabb.Max[axis])
+		}
+	}
+
+	var outside mgl64.Vec3
+	for axis := range 3 {
+		outside[axis] = max(bounds.Min[axis]-point[axis], 0, point[axis]-bounds.Max[axis])
+	}
+
+	return outside.Len()
+}
+
+// catchUpSubsteps returns the substeps owed by the islands deferred by the last Step
+func (w *World) catchUpSubsteps(h float64) int {
+	count := 0
+	for _, owed := range w.deferred {
+		count = max(count, int(math.Round(owed/h)))
+	}
+
+	return count
+}
+
+// freezeCaughtUp freezes the dynamic bodies owing no more than the catch-up substep, so it only simulates
+// the deferred islands
+func (w *World) freezeCaughtUp(h float64, substep int) {
+	for _, body := range w.Bodies {
+		if body.BodyType == actor.BodyTypeDynamic {
+			body.SetDeferred(int(math.Round(w.deferred[body]/h)) <= substep)
+		}
+	}
+}
+
+// endCatchUp releases the bodies frozen by the catch-up, and removes the simulated substeps from the time owed
+func (w *World) endCatchUp(h float64, simulated int) {
+	for _, body := range w.Bodies {
+		body.SetDeferred(false)
+	}
+	for body, owed := range w.deferred {
+		if owed -= float64(simulated) * h; owed < h/2 {
+			delete(w.deferred, body)
+		} else {
+			w.deferred[body] = owed
+		}
+	}
+	w.counters.catchUpSubsteps = simulated
+}
+
+// releaseDeferred releases the bodies deferred during the Step, their time owed is kept for the next one
+func (w *World) releaseDeferred() {
+	for body := range w.deferred {
+		body.SetDeferred(false)
+	}
+}
@@ -0,0 +1,48 @@
+package feather
+
+import (
+	"testing"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/akmonengine/feather/constraint"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestFarIslands(t *testing.T) {
+	ground := createBox(mgl64.Vec3{0, -1, 0}, mgl64.Vec3{100, 1, 10}, actor.BodyTypeStatic)
+	a := createBox(mgl64.Vec3{-20, 0.5, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+	b := createBox(mgl64.Vec3{-20, 1.5, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+	c := createBox(mgl64.Vec3{0, 0.5, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+	d := createBox(mgl64.Vec3{40, 0.5, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+
+	// A pile of two boxes (cost 2) and two single boxes (cost 1)
+	islands, _ := buildIslands([]*constraint.ContactConstraint{
+		{BodyA: a, BodyB: ground},
+		{BodyA: b, BodyB: a},
+		{BodyA: c, BodyB: ground},
+		{BodyA: d, BodyB: ground},
+	}, nil)
+
+	// The farthest first, until the kept ones fit
+	deferred := farIslands(islands, mgl64.Vec3{0, 0, 0}, 0.75)
+	if len(deferred) != 1 || deferred[0].contacts[0].BodyA != d {
+		t.Errorf("deferred %d islands, want the island of d only", len(deferred))
+	}
+	deferred = farIslands(islands, mgl64.Vec3{0, 0, 0}, 0.25)
+	if len(deferred) != 2 || deferred[1].contacts[0].BodyA != a {
+		t.Errorf("deferred %d islands, want the islands of d then of the pile", len(deferred))
+	}
+
+	// The focus decides which islands are far
+	deferred = farIslands(islands, mgl64.Vec3{40, 0, 0}, 0.5)
+	if len(deferred) != 1 || deferred[0].contacts[0].BodyA != a {
+		t.Errorf("deferred %d islands, want the pile only", len(deferred))
+	}
+
+	if deferred := farIslands(islands, mgl64.Vec3{}, 1); len(deferred) != 0 {
+		t.Errorf("deferred %d islands within the budget, want 0", len(deferred))
+	}
+	if deferred := farIslands(islands, mgl64.Vec3{}, -1); len(deferred) != 3 {
+		t.Errorf("deferred %d islands over the budget, want all 3", len(deferred))
+	}
+}
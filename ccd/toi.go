@@ -0,0 +1,98 @@
+// Package ccd computes the time of impact of moving bodies, for continuous collision detection.
+//
+// The most common fast movers (sphere vs sphere, sphere vs plane) are solved in closed form,
+// assuming a linear motion at constant velocity during the step: the rotation of a sphere
+// does not change its shape, so the result is exact.
+package ccd
+
+import (
+	"github.com/akmonengine/feather/actor"
+	"github.com/akmonengine/feather/dmath"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// SphereSphere returns the time of impact in [0, dt] of two spheres moving at constant velocity.
+// Overlapping spheres return 0, spheres moving apart or missing each other return false.
+func SphereSphere(centerA mgl64.Vec3, radiusA float64, velocityA mgl64.Vec3, centerB mgl64.Vec3, radiusB float64, velocityB mgl64.Vec3, dt float64) (float64, bool) {
+	// Solve |p + v*t| = r for the relative motion of B seen from A
+	p := centerB.Sub(centerA)
+	v := velocityB.Sub(velocityA)
+	r := radiusA + radiusB
+
+	c := p.Dot(p) - r*r
+	if c <= 0 {
+		return 0, true
+	}
+
+	b := p.Dot(v)
+	if b >= 0 {
+		return 0, false
+	}
+
+	a := v.Dot(v)
+	discriminant := b*b - a*c
+	if discriminant < 0 {
+		return 0, false
+	}
+
+	// Smallest root: the first contact, b < 0 ensures it is positive
+	t := (-b - dmath.Sqrt(discriminant)) / a
+	if t > dt {
+		return 0, false
+	}
+
+	return t, true
+}
+
+// SpherePlane returns the time of impact in [0, dt] of a sphere and a plane, both moving at constant velocity.
+// The plane is a half-space (see actor.Plane): a sphere under its surface returns 0.
+func SpherePlane(center mgl64.Vec3, radius float64, velocity mgl64.Vec3, planeNormal mgl64.Vec3, planeDistance float64, planeVelocity mgl64.Vec3, dt float64) (float64, bool) {
+	distance := center.Dot(planeNormal) + planeDistance
+	if distance <= radius {
+		return 0, true
+	}
+
+	normalSpeed := velocity.Sub(planeVelocity).Dot(planeNormal)
+	if normalSpeed >= 0 {
+		return 0, false
+	}
+
+	t := (distance - radius) / -normalSpeed
+	if t > dt {
+		return 0, false
+	}
+
+	return t, true
+}
+
+// Supported returns true if the time of impact of the two bodies has a closed form
+func Supported(bodyA, bodyB *actor.RigidBody) bool {
+	_, aIsSphere := bodyA.Shape.(*actor.Sphere)
+	_, bIsSphere := bodyB.Shape.(*actor.Sphere)
+	_, aIsPlane := bodyA.Shape.(*actor.Plane)
+	_, bIsPlane := bodyB.Shape.(*actor.Plane)
+
+	return (aIsSphere && (bIsSphere || bIsPlane)) || (bIsSphere && aIsPlane)
+}
+
+// TimeOfImpact returns the time of impact in [0, dt] of two bodies, from their current transform and velocity.
+// Only the pairs accepted by Supported are handled, other pairs return false.
+func TimeOfImpact(bodyA, bodyB *actor.RigidBody, dt float64) (float64, bool) {
+	if _, ok := bodyA.Shape.(*actor.Plane); ok {
+		bodyA, bodyB = bodyB, bodyA
+	}
+
+	sphere, ok := bodyA.Shape.(*actor.Sphere)
+	if !ok {
+		return 0, false
+	}
+
+	switch shape := bodyB.Shape.(type) {
+	case *actor.Sphere:
+		return SphereSphere(bodyA.Transform.Position, sphere.Radius, bodyA.Velocity, bodyB.Transform.Position, shape.Radius, bodyB.Velocity, dt)
+	case *actor.Plane:
+		return SpherePlane(bodyA.Transform.Position, sphere.Radius, bodyA.Velocity, shape.Normal, shape.Distance, bodyB.Velocity, dt)
+	}
+
+	return 0, false
+}
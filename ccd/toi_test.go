@@ -0,0 +1,116 @@
+package ccd
+
+import (
+	"math"
+	"testing"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestSphereSphere(t *testing.T) {
+	tests := []struct {
+		name      string
+		centerB   mgl64.Vec3
+		velocityA mgl64.Vec3
+		velocityB mgl64.Vec3
+		dt        float64
+		hit       bool
+		toi       float64
+	}{
+		{name: "head-on", centerB: mgl64.Vec3{10, 0, 0}, velocityA: mgl64.Vec3{100, 0, 0}, dt: 1, hit: true, toi: 0.08},
+		{name: "both moving", centerB: mgl64.Vec3{10, 0, 0}, velocityA: mgl64.Vec3{40, 0, 0}, velocityB: mgl64.Vec3{-60, 0, 0}, dt: 1, hit: true, toi: 0.08},
+		{name: "too far for the step", centerB: mgl64.Vec3{10, 0, 0}, velocityA: mgl64.Vec3{100, 0, 0}, dt: 0.05, hit: false},
+		{name: "moving apart", centerB: mgl64.Vec3{10, 0, 0}, velocityA: mgl64.Vec3{-100, 0, 0}, dt: 1, hit: false},
+		{name: "miss", centerB: mgl64.Vec3{10, 3, 0}, velocityA: mgl64.Vec3{100, 0, 0}, dt: 1, hit: false},
+		{name: "grazing", centerB: mgl64.Vec3{10, 2, 0}, velocityA: mgl64.Vec3{100, 0, 0}, dt: 1, hit: true, toi: 0.1},
+		{name: "overlapping", centerB: mgl64.Vec3{1.5, 0, 0}, dt: 1, hit: true, toi: 0},
+		{name: "resting", centerB: mgl64.Vec3{10, 0, 0}, dt: 1, hit: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toi, hit := SphereSphere(mgl64.Vec3{}, 1, tt.velocityA, tt.centerB, 1, tt.velocityB, tt.dt)
+			if hit != tt.hit {
+				t.Fatalf("hit = %v, want %v", hit, tt.hit)
+			}
+			if hit && math.Abs(toi-tt.toi) > 1e-9 {
+				t.Errorf("toi = %v, want %v", toi, tt.toi)
+			}
+		})
+	}
+}
+
+func TestSpherePlane(t *testing.T) {
+	normal := mgl64.Vec3{0, 1, 0}
+
+	tests := []struct {
+		name          string
+		center        mgl64.Vec3
+		velocity      mgl64.Vec3
+		planeVelocity mgl64.Vec3
+		dt            float64
+		hit           bool
+		toi           float64
+	}{
+		{name: "falling", center: mgl64.Vec3{0, 11, 0}, velocity: mgl64.Vec3{5, -100, 0}, dt: 1, hit: true, toi: 0.1},
+		{name: "rising plane", center: mgl64.Vec3{0, 11, 0}, velocity: mgl64.Vec3{0, -50, 0}, planeVelocity: mgl64.Vec3{0, 50, 0}, dt: 1, hit: true, toi: 0.1},
+		{name: "too far for the step", center: mgl64.Vec3{0, 11, 0}, velocity: mgl64.Vec3{0, -100, 0}, dt: 0.05, hit: false},
+		{name: "moving away", center: mgl64.Vec3{0, 11, 0}, velocity: mgl64.Vec3{0, 100, 0}, dt: 1, hit: false},
+		{name: "parallel", center: mgl64.Vec3{0, 11, 0}, velocity: mgl64.Vec3{100, 0, 0}, dt: 1, hit: false},
+		{name: "under the surface", center: mgl64.Vec3{0, -5, 0}, dt: 1, hit: true, toi: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toi, hit := SpherePlane(tt.center, 1, tt.velocity, normal, 0, tt.planeVelocity, tt.dt)
+			if hit != tt.hit {
+				t.Fatalf("hit = %v, want %v", hit, tt.hit)
+			}
+			if hit && math.Abs(toi-tt.toi) > 1e-9 {
+				t.Errorf("toi = %v, want %v", toi, tt.toi)
+			}
+		})
+	}
+}
+
+func TestSpherePlane_Distance(t *testing.T) {
+	// Plane y = -2: Distance is the plane constant, as in actor.Plane
+	toi, hit := SpherePlane(mgl64.Vec3{0, 9, 0}, 1, mgl64.Vec3{0, -100, 0}, mgl64.Vec3{0, 1, 0}, 2, mgl64.Vec3{}, 1)
+	if !hit || math.Abs(toi-0.1) > 1e-9 {
+		t.Errorf("toi = %v, hit = %v, want 0.1", toi, hit)
+	}
+}
+
+func TestTimeOfImpact(t *testing.T) {
+	ball := actor.NewRigidBody(actor.Transform{Position: mgl64.Vec3{0, 11, 0}, Rotation: mgl64.QuatIdent()}, &actor.Sphere{Radius: 1}, actor.BodyTypeDynamic, 1.0)
+	ball.Velocity = mgl64.Vec3{0, -100, 0}
+	ground := actor.NewRigidBody(actor.Transform{Rotation: mgl64.QuatIdent()}, &actor.Plane{Normal: mgl64.Vec3{0, 1, 0}}, actor.BodyTypeStatic, 0)
+	box := actor.NewRigidBody(actor.Transform{Rotation: mgl64.QuatIdent()}, &actor.Box{HalfExtents: mgl64.Vec3{1, 1, 1}}, actor.BodyTypeDynamic, 1.0)
+
+	if !Supported(ball, ground) || !Supported(ground, ball) {
+		t.Error("sphere vs plane should be supported in both orders")
+	}
+	if Supported(ball, box) || Supported(ground, ground) {
+		t.Error("pairs with a box or two planes have no closed form")
+	}
+
+	for _, pair := range [][2]*actor.RigidBody{{ball, ground}, {ground, ball}} {
+		toi, hit := TimeOfImpact(pair[0], pair[1], 1.0/60.0)
+		if hit {
+			t.Errorf("toi = %v, the ball should not reach the ground within the step", toi)
+		}
+		toi, hit = TimeOfImpact(pair[0], pair[1], 1)
+		if !hit || math.Abs(toi-0.1) > 1e-9 {
+			t.Errorf("toi = %v, hit = %v, want 0.1", toi, hit)
+		}
+	}
+
+	other := actor.NewRigidBody(actor.Transform{Position: mgl64.Vec3{0, 1, 0}, Rotation: mgl64.QuatIdent()}, &actor.Sphere{Radius: 1}, actor.BodyTypeDynamic, 1.0)
+	if toi, hit := TimeOfImpact(ball, other, 1); !hit || math.Abs(toi-0.08) > 1e-9 {
+		t.Errorf("toi = %v, hit = %v, want 0.08", toi, hit)
+	}
+	if _, hit := TimeOfImpact(ball, box, 1); hit {
+		t.Error("unsupported pairs should return false")
+	}
+}
@@ -0,0 +1,175 @@
+package feather
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/akmonengine/feather/constraint"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// ErrInvalidCloth is returned by AddCloth for a cloth under 2x2 particles, flat, or without mass or radius
+var ErrInvalidCloth = errors.New("feather: cloth requires at least 2x2 particles, a surface, a mass and a radius")
+
+// ClothSettings describes a cloth built by World.AddCloth
+type ClothSettings struct {
+	// Columns and Rows are the particles along each edge of the cloth, at least 2
+	Columns int
+	Rows    int
+	// Mass (kg) of the whole cloth, shared by its particles
+	Mass float64
+	// Radius (m) of the particles, the thickness kept between the cloth and the surfaces of the rigid bodies
+	Radius float64
+	// StretchCompliance, ShearCompliance and BendCompliance are the inverse stiffness of the structural,
+	// diagonal and bending constraints, 0 is rigid
+	StretchCompliance float64
+	ShearCompliance   float64
+	BendCompliance    float64
+	// Friction is the coefficient of the particles sliding on the rigid bodies
+	Friction float64
+	// Damping is the LinearDamping of the particles, e.g. the drag of the air
+	Damping float64
+	// Substeps is the substeps count of the cloth at each Step, independently of the world; 0 is DEFAULT_CLOTH_SUBSTEPS
+	Substeps int
+}
+
+// Cloth is a grid of particles kept by distance constraints (XPBD): the structural ones join the neighbors
+// along the rows and the columns, the shear ones the diagonals, the bending ones the particles two apart.
+// It is stepped after the bodies with its own substeps, and collides one way: the bodies push the cloth,
+// the cloth never moves nor wakes them. It does not collide with itself nor the other cloths.
+type Cloth struct {
+	// Particles of the grid, row after row: the particle of column i and row j is Particles[j*Columns+i]
+	Particles []*actor.Particle
+	Columns   int
+	Rows      int
+	// Triangles index Particles, two per cell of the grid facing edgeU × edgeV (see AddCloth), to render it
+	Triangles [][3]int
+
+	Structural []*constraint.ParticleDistance
+	Shear      []*constraint.ParticleDistance
+	Bend       []*constraint.ParticleDistance
+
+	// Radius, Friction and Substeps of the cloth, see ClothSettings
+	Radius   float64
+	Friction float64
+	Substeps int
+
+	// constraints are the structural, shear and bending constraints, in their solve order
+	constraints []*constraint.ParticleDistance
+	// proxies are the spheres of the particles, given to the narrow phase against the rigid bodies
+	proxies []*actor.RigidBody
+}
+
+// AddCloth builds a flat cloth on the parallelogram spanned by edgeU (along the columns) and edgeV (along the rows)
+// from corner, and adds it to the world. A particle is pinned by setting its Mass to 0, e.g. a flag on its pole:
+// it is then moved by the user only.
+func (w *World) AddCloth(corner, edgeU, edgeV mgl64.Vec3, settings ClothSettings) (*Cloth, error) {
+	if settings.Columns < 2 || settings.Rows < 2 || !(edgeU.Cross(edgeV).Len() > 0) || !(settings.Mass > 0) || !(settings.Radius > 0) {
+		return nil, ErrInvalidCloth
+	}
+
+	cloth := &Cloth{
+		Particles: make([]*actor.Particle, settings.Columns*settings.Rows),
+		Columns:   settings.Columns,
+		Rows:      settings.Rows,
+		Radius:    settings.Radius,
+		Friction:  settings.Friction,
+		Substeps:  settings.Substeps,
+	}
+	mass := settings.Mass / float64(len(cloth.Particles))
+	for j := range cloth.Rows {
+		for i := range cloth.Columns {
+			u := edgeU.Mul(float64(i) / float64(cloth.Columns-1))
+			v := edgeV.Mul(float64(j) / float64(cloth.Rows-1))
+			particle := actor.NewParticle(corner.Add(u).Add(v), mass)
+			particle.LinearDamping = settings.Damping
+			cloth.Particles[j*cloth.Columns+i] = particle
+		}
+	}
+	cloth.proxies = newParticleProxies(cloth.Particles, settings.Radius)
+
+	join := func(constraints []*constraint.ParticleDistance, i, j, di, dj int, compliance float64) []*constraint.ParticleDistance {
+		if i+di < 0 || i+di >= cloth.Columns || j+dj >= cloth.Rows {
+			return constraints
+		}
+
+		return append(constraints, constraint.NewParticleDistance(cloth.Particle(i, j), cloth.Particle(i+di, j+dj), compliance))
+	}
+	for j := range cloth.Rows {
+		for i := range cloth.Columns {
+			cloth.Structural = join(cloth.Structural, i, j, 1, 0, settings.StretchCompliance)
+			cloth.Structural = join(cloth.Structural, i, j, 0, 1, settings.StretchCompliance)
+			cloth.Shear = join(cloth.Shear, i, j, 1, 1, settings.ShearCompliance)
+			cloth.Shear = join(cloth.Shear, i, j, -1, 1, settings.ShearCompliance)
+			cloth.Bend = join(cloth.Bend, i, j, 2, 0, settings.BendCompliance)
+			cloth.Bend = join(cloth.Bend, i, j, 0, 2, settings.BendCompliance)
+
+			if i+1 < cloth.Columns && j+1 < cloth.Rows {
+				a, b := j*cloth.Columns+i, j*cloth.Columns+i+1
+				c, d := a+cloth.Columns, b+cloth.Columns
+				cloth.Triangles = append(cloth.Triangles, [3]int{a, b, d}, [3]int{a, d, c})
+			}
+		}
+	}
+	cloth.constraints = slices.Concat(cloth.Structural, cloth.Shear, cloth.Bend)
+
+	w.cloths = append(w.cloths, cloth)
+
+	return cloth, nil
+}
+
+// RemoveCloth removes the cloth from the world
+func (w *World) RemoveCloth(cloth *Cloth) {
+	w.cloths = slices.DeleteFunc(w.cloths, func(other *Cloth) bool {
+		return other == cloth
+	})
+}
+
+// Cloths returns the cloths of the world
+func (w *World) Cloths() []*Cloth {
+	return w.cloths
+}
+
+// Particle returns the particle of column i and row j
+func (c *Cloth) Particle(i, j int) *actor.Particle {
+	return c.Particles[j*c.Columns+i]
+}
+
+// stepCloths steps the cloths by dt, after the bodies: each one runs its own substeps, against the bodies at their new poses
+func (w *World) stepCloths(dt float64) {
+	accumulate := w.Solver == SOLVER_XPBD
+	for _, cloth := range w.cloths {
+		substeps := cloth.Substeps
+		if substeps <= 0 {
+			substeps = DEFAULT_CLOTH_SUBSTEPS
+		}
+		h := dt / float64(substeps)
+
+		for range substeps {
+			for _, particle := range cloth.Particles {
+				particle.Integrate(h, w.Gravity)
+			}
+
+			contacts := w.collideParticles(cloth.Particles, cloth.proxies, cloth.Radius, cloth.Friction)
+			for _, c := range contacts {
+				c.OneWay = true
+			}
+			for _, c := range cloth.constraints {
+				c.BeginSubstep(accumulate)
+			}
+			for range iterations(w.PositionIterations, DEFAULT_POSITION_ITERATIONS) {
+				for _, c := range cloth.constraints {
+					c.SolvePosition(h)
+				}
+				for _, c := range contacts {
+					c.SolvePosition(h)
+				}
+			}
+
+			for _, particle := range cloth.Particles {
+				particle.Update(h)
+			}
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package feather
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestWorld_AddCloth_Invalid(t *testing.T) {
+	world := NewWorld()
+	edgeU, edgeV := mgl64.Vec3{1, 0, 0}, mgl64.Vec3{0, 0, 1}
+	for name, settings := range map[string]ClothSettings{
+		"one column": {Columns: 1, Rows: 4, Mass: 1, Radius: 0.01},
+		"no mass":    {Columns: 4, Rows: 4, Radius: 0.01},
+		"no radius":  {Columns: 4, Rows: 4, Mass: 1},
+	} {
+		if _, err := world.AddCloth(mgl64.Vec3{}, edgeU, edgeV, settings); !errors.Is(err, ErrInvalidCloth) {
+			t.Errorf("%s: err = %v, want ErrInvalidCloth", name, err)
+		}
+	}
+	if _, err := world.AddCloth(mgl64.Vec3{}, edgeU, edgeU, ClothSettings{Columns: 4, Rows: 4, Mass: 1, Radius: 0.01}); !errors.Is(err, ErrInvalidCloth) {
+		t.Errorf("flat: err = %v, want ErrInvalidCloth", err)
+	}
+	if len(world.Cloths()) != 0 {
+		t.Errorf("%d cloths added", len(world.Cloths()))
+	}
+}
+
+func TestWorld_ClothHanging(t *testing.T) {
+	world := NewWorld()
+	// A 1m flag hanging from its two top corners
+	cloth, err := world.AddCloth(mgl64.Vec3{0, 2, 0}, mgl64.Vec3{1, 0, 0}, mgl64.Vec3{0, 0, 1}, ClothSettings{
+		Columns:        11,
+		Rows:           11,
+		Mass:           0.2,
+		Radius:         0.01,
+		BendCompliance: 1e-3,
+		Damping:        0.5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cloth.Structural) != 2*11*10 || len(cloth.Shear) != 2*10*10 || len(cloth.Bend) != 2*11*9 || len(cloth.Triangles) != 2*10*10 {
+		t.Fatalf("%d structural, %d shear, %d bend constraints and %d triangles", len(cloth.Structural), len(cloth.Shear), len(cloth.Bend), len(cloth.Triangles))
+	}
+	cloth.Particle(0, 0).Mass = 0
+	cloth.Particle(10, 0).Mass = 0
+
+	for range 180 {
+		world.Step(1.0 / 60.0)
+	}
+
+	if cloth.Particle(0, 0).Position != (mgl64.Vec3{0, 2, 0}) || cloth.Particle(10, 0).Position != (mgl64.Vec3{1, 2, 0}) {
+		t.Errorf("pinned corners moved to %v and %v", cloth.Particle(0, 0).Position, cloth.Particle(10, 0).Position)
+	}
+	if y := cloth.Particle(5, 10).Position.Y(); y > 1.2 {
+		t.Errorf("bottom of the cloth at %v, want hanging under the pins", y)
+	}
+	for _, c := range cloth.Structural {
+		if length := c.ParticleB.Position.Sub(c.ParticleA.Position).Len(); length > c.RestLength*1.05 {
+			t.Errorf("structural constraint stretched to %v, rest length %v", length, c.RestLength)
+		}
+	}
+}
+
+func TestWorld_ClothOneWay(t *testing.T) {
+	world := NewWorld()
+	world.AddBody(createPlane(mgl64.Vec3{0, 1, 0}, 0))
+	crate := createBox(mgl64.Vec3{0, 0.5, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+	world.AddBody(crate)
+
+	// A sheet dropped over the crate drapes it, without moving it
+	cloth, err := world.AddCloth(mgl64.Vec3{-1, 1.5, -1}, mgl64.Vec3{2, 0, 0}, mgl64.Vec3{0, 0, 2}, ClothSettings{
+		Columns:        15,
+		Rows:           15,
+		Mass:           0.5,
+		Radius:         0.02,
+		BendCompliance: 1e-2,
+		Friction:       0.5,
+		Damping:        0.5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 180 {
+		world.Step(1.0 / 60.0)
+	}
+
+	if crate.Transform.Position.Sub(mgl64.Vec3{0, 0.5, 0}).Len() > 1e-3 || !crate.IsSleeping {
+		t.Errorf("crate at %v, sleeping %v, want left asleep under the cloth", crate.Transform.Position, crate.IsSleeping)
+	}
+	if center := cloth.Particle(7, 7).Position; center.Y() < 1+0.02-0.01 || center.Y() > 1.1 {
+		t.Errorf("center of the cloth at %v, want resting on the crate", center)
+	}
+	for i, particle := range cloth.Particles {
+		if particle.Position.Y() < 0.02-0.01 {
+			t.Errorf("particle %d at %v, through the plane", i, particle.Position)
+		}
+	}
+}
@@ -31,34 +31,99 @@ type CollisionPair struct {
 
 // BroadPhase performs broad-phase collision detection using AABB overlap tests
 // It returns pairs of bodies whose AABBs overlap and might be colliding
-// This is an O(n²) brute-force approach suitable for small numbers of bodies
+// The bodies are inserted into the SpatialGrid, only bodies sharing a cell are tested
 func BroadPhase(spatialGrid *SpatialGrid, bodies []*actor.RigidBody, workersCount int) <-chan Pair {
-	spatialGrid.Clear()
-	for i, body := range bodies {
-		spatialGrid.Insert(i, body)
-	}
-	spatialGrid.SortCells()
+	return broadPhase(spatialGrid, bodies, workersCount)
+}
 
-	checkingPairs := spatialGrid.FindPairsParallel(bodies, workersCount)
+// broadPhase indexes the bodies into the Broadphase, and returns the pairs it finds
+func broadPhase(broadphase Broadphase, bodies []*actor.RigidBody, workersCount int) <-chan Pair {
+	broadphase.Update(bodies)
 
-	return checkingPairs
+	return broadphase.FindPairs(bodies, workersCount)
+}
+
+// BruteForceBroadPhase performs broad-phase collision detection by testing all the pairs of bodies
+// This is an O(n²) approach, faster than the SpatialGrid for small numbers of bodies
+// It follows the same rules as the grid: static/static and sleeping/sleeping pairs are skipped,
+// planes are paired with every other body
+func BruteForceBroadPhase(bodies []*actor.RigidBody) <-chan Pair {
+	pairsChan := make(chan Pair, len(bodies))
+
+	go func() {
+		defer close(pairsChan)
+
+		for i, bodyA := range bodies {
+			if !bodyA.IsEnabled() {
+				continue
+			}
+			_, aIsPlane := bodyA.Shape.(*actor.Plane)
+
+			for _, bodyB := range bodies[i+1:] {
+				if !bodyB.IsEnabled() {
+					continue
+				}
+				_, bIsPlane := bodyB.Shape.(*actor.Plane)
+
+				switch {
+				case aIsPlane && bIsPlane:
+					continue
+				case aIsPlane:
+					pairsChan <- Pair{BodyA: bodyA, BodyB: bodyB}
+					continue
+				case bIsPlane:
+					pairsChan <- Pair{BodyA: bodyB, BodyB: bodyA}
+					continue
+				}
+
+				if !bodyA.IsActive() && !bodyB.IsActive() {
+					continue
+				}
+
+				if bodyA.BroadPhaseAABB().Overlaps(bodyB.BroadPhaseAABB()) {
+					pairsChan <- Pair{BodyA: bodyA, BodyB: bodyB}
+				}
+			}
+		}
+	}()
+
+	return pairsChan
 }
 
 func NarrowPhase(pairs <-chan Pair, workersCount int) []*constraint.ContactConstraint {
-	// Dispatcher: separate pairs with planes, and normal convex objects
+	return narrowPhase(pairs, workersCount, nil, heapAllocator{}, 0, defaultCollisions)
+}
+
+// narrowPhase runs the narrow phase, and records its diagnostics in counters (if not nil)
+// The contacts and their list are provided by allocator, capacity is the expected contacts count.
+// The pairs with a function in dispatcher are computed by it, before the generic paths.
+func narrowPhase(pairs <-chan Pair, workersCount int, counters *stepCounters, allocator Allocator, capacity int, dispatcher *CollisionDispatcher) []*constraint.ContactConstraint {
+	// Dispatcher: separate pairs with a collision function, with planes, with meshes, with compounds, and normal convex objects
+	dispatchedPairs := make(chan Pair, workersCount)
 	planePairs := make(chan Pair, workersCount)
+	meshPairs := make(chan Pair, workersCount)
+	compoundPairs := make(chan Pair, workersCount)
 	gjkPairs := make(chan Pair, workersCount)
 
 	go func() {
+		defer close(dispatchedPairs)
 		defer close(planePairs)
+		defer close(meshPairs)
+		defer close(compoundPairs)
 		defer close(gjkPairs)
 
 		for pair := range pairs {
 			_, aIsPlane := pair.BodyA.Shape.(*actor.Plane)
 			_, bIsPlane := pair.BodyB.Shape.(*actor.Plane)
 
-			if aIsPlane || bIsPlane {
+			if _, ok := dispatcher.lookup(pair.BodyA, pair.BodyB); ok {
+				dispatchedPairs <- pair
+			} else if aIsPlane || bIsPlane {
 				planePairs <- pair
+			} else if isMesh(pair.BodyA) || isMesh(pair.BodyB) {
+				meshPairs <- pair
+			} else if isCompound(pair.BodyA) || isCompound(pair.BodyB) {
+				compoundPairs <- pair
 			} else {
 				gjkPairs <- pair
 			}
@@ -72,8 +137,8 @@ func NarrowPhase(pairs <-chan Pair, workersCount int) []*constraint.ContactConst
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		collisionPairs := GJK(gjkPairs, workersCount)
-		contactsChan := EPA(collisionPairs, workersCount)
+		collisionPairs := gjkPhase(gjkPairs, workersCount, counters)
+		contactsChan := epaPhase(collisionPairs, workersCount, allocator)
 		for contact := range contactsChan {
 			allContacts <- contact
 		}
@@ -83,7 +148,37 @@ func NarrowPhase(pairs <-chan Pair, workersCount int) []*constraint.ContactConst
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		contactsChan := collidePlane(planePairs, workersCount)
+		contactsChan := collidePlane(planePairs, workersCount, allocator)
+		for contact := range contactsChan {
+			allContacts <- contact
+		}
+	}()
+
+	// Path 3: convex objects against the triangles of meshes
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		contactsChan := collideMesh(meshPairs, workersCount, allocator, counters)
+		for contact := range contactsChan {
+			allContacts <- contact
+		}
+	}()
+
+	// Path 4: children of compounds
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		contactsChan := collideCompound(compoundPairs, workersCount, allocator, counters)
+		for contact := range contactsChan {
+			allContacts <- contact
+		}
+	}()
+
+	// Path 5: collision functions of the dispatcher
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		contactsChan := collideDispatched(dispatchedPairs, workersCount, allocator, dispatcher)
 		for contact := range contactsChan {
 			allContacts <- contact
 		}
@@ -96,7 +191,7 @@ func NarrowPhase(pairs <-chan Pair, workersCount int) []*constraint.ContactConst
 	}()
 
 	// Collecter tous les contacts
-	contacts := make([]*constraint.ContactConstraint, 0)
+	contacts := allocator.Contacts(capacity)
 	for c := range allContacts {
 		contacts = append(contacts, c)
 	}
@@ -105,6 +200,11 @@ func NarrowPhase(pairs <-chan Pair, workersCount int) []*constraint.ContactConst
 }
 
 func GJK(pairChan <-chan Pair, workersCount int) <-chan CollisionPair {
+	return gjkPhase(pairChan, workersCount, nil)
+}
+
+// gjkPhase runs GJK on the pairs, and counts the queries capped by gjk.MaxIterations in counters (if not nil)
+func gjkPhase(pairChan <-chan Pair, workersCount int, counters *stepCounters) <-chan CollisionPair {
 	collisionChan := make(chan CollisionPair, workersCount)
 
 	go func() {
@@ -117,10 +217,19 @@ func GJK(pairChan <-chan Pair, workersCount int) <-chan CollisionPair {
 				defer wg.Done()
 
 				for p := range pairChan {
+					if !boundingSpheresOverlap(p.BodyA, p.BodyB) {
+						continue
+					}
+
 					simplex := gjk.SimplexPool.Get().(*gjk.Simplex)
 					simplex.Reset()
 
-					if collision := gjk.GJK(p.BodyA, p.BodyB, simplex); collision {
+					report := gjk.GJKReport(p.BodyA, p.BodyB, simplex)
+					if report.Capped && counters != nil {
+						counters.addGJKCapped(p.BodyA, p.BodyB)
+					}
+
+					if report.Collision {
 						collisionChan <- CollisionPair{
 							BodyA:   p.BodyA,
 							BodyB:   p.BodyB,
@@ -139,7 +248,21 @@ func GJK(pairChan <-chan Pair, workersCount int) <-chan CollisionPair {
 	return collisionChan
 }
 
+// boundingSpheresOverlap is a conservative test run before GJK.
+// It rejects the pairs whose AABBs only overlap by their corners (e.g. diagonal neighbours),
+// which the broad phase reports but that cannot collide.
+func boundingSpheresOverlap(a, b *actor.RigidBody) bool {
+	radii := a.Shape.BoundingRadius() + b.Shape.BoundingRadius()
+
+	return a.Transform.Position.Sub(b.Transform.Position).LenSqr() <= radii*radii
+}
+
 func EPA(p <-chan CollisionPair, workersCount int) <-chan *constraint.ContactConstraint {
+	return epaPhase(p, workersCount, heapAllocator{})
+}
+
+// epaPhase runs EPA on the colliding pairs, the contacts are provided by allocator
+func epaPhase(p <-chan CollisionPair, workersCount int, allocator Allocator) <-chan *constraint.ContactConstraint {
 	ch := make(chan *constraint.ContactConstraint, workersCount)
 
 	go func() {
@@ -156,7 +279,9 @@ func EPA(p <-chan CollisionPair, workersCount int) <-chan *constraint.ContactCon
 					if err != nil {
 						continue
 					}
-					ch <- &contact
+					result := allocator.Contact()
+					*result = contact
+					ch <- result
 				}
 			}()
 		}
@@ -167,7 +292,7 @@ func EPA(p <-chan CollisionPair, workersCount int) <-chan *constraint.ContactCon
 	return ch
 }
 
-func collidePlane(pairs <-chan Pair, workersCount int) <-chan *constraint.ContactConstraint {
+func collidePlane(pairs <-chan Pair, workersCount int, allocator Allocator) <-chan *constraint.ContactConstraint {
 	ch := make(chan *constraint.ContactConstraint, workersCount)
 
 	go func() {
@@ -199,24 +324,24 @@ func collidePlane(pairs <-chan Pair, workersCount int) <-chan *constraint.Contac
 						continue // No plane (should not happen, the data is prefiltered in NarrowPhase)
 					}
 
+					collidePlaneSensors(planeBody, object, contactNormal, allocator, ch)
 					collision, result := object.Shape.CollideWithPlane(plane.Normal, plane.Distance, object.Transform)
 
 					if !collision {
 						continue
 					}
 
-					var points []constraint.ContactPoint
-					for _, point := range result {
-						points = append(points, constraint.ContactPoint{Position: point.Position, Penetration: point.Penetration})
+					points := allocator.Points(len(result))
+					for i, point := range result {
+						points[i] = constraint.ContactPoint{Position: point.Position, Penetration: point.Penetration}
 					}
 
 					// Créer la contrainte
-					contact := &constraint.ContactConstraint{
-						BodyA:  planeBody,
-						BodyB:  object,
-						Normal: contactNormal,
-						Points: points,
-					}
+					contact := allocator.Contact()
+					contact.BodyA = planeBody
+					contact.BodyB = object
+					contact.Normal = contactNormal
+					contact.Points = points
 
 					ch <- contact
 				}
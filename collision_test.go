@@ -1,6 +1,7 @@
 package feather
 
 import (
+	"math"
 	"math/rand"
 	"os"
 	"runtime/pprof"
@@ -339,6 +340,112 @@ func TestNarrowPhaseOverlappingBoxes(t *testing.T) {
 	}
 }
 
+// TestBruteForceBroadPhaseMatchesGrid checks both broad phases report the same pairs
+func TestBruteForceBroadPhaseMatchesGrid(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	bodies := []*actor.RigidBody{createPlane(mgl64.Vec3{0, 1, 0}, 0)}
+	for i := 0; i < 40; i++ {
+		position := mgl64.Vec3{rng.Float64() * 8, rng.Float64() * 8, rng.Float64() * 8}
+		bodyType := actor.BodyTypeDynamic
+		if i%5 == 0 {
+			bodyType = actor.BodyTypeStatic
+		}
+		body := createBox(position, mgl64.Vec3{0.6, 0.6, 0.6}, bodyType)
+		body.IsSleeping = i%7 == 0
+		bodies = append(bodies, body)
+	}
+
+	collect := func(pairs <-chan Pair) map[Pair]bool {
+		result := make(map[Pair]bool)
+		for pair := range pairs {
+			result[OrderPair(pair.BodyA, pair.BodyB)] = true
+		}
+		return result
+	}
+
+	gridPairs := collect(BroadPhase(NewSpatialGrid(1.0, 4096), bodies, 4))
+	bruteForcePairs := collect(BruteForceBroadPhase(bodies))
+
+	if len(gridPairs) != len(bruteForcePairs) {
+		t.Fatalf("grid found %d pairs, brute force found %d", len(gridPairs), len(bruteForcePairs))
+	}
+	for pair := range gridPairs {
+		if !bruteForcePairs[pair] {
+			t.Errorf("pair %v missing from the brute force broad phase", pair)
+		}
+	}
+}
+
+func TestWorld_BroadPhaseSelection(t *testing.T) {
+	world := &World{SpatialGrid: NewSpatialGrid(1.0, 1024), Workers: 1, BruteForceThreshold: 3}
+	world.AddBody(createSphere(mgl64.Vec3{0, 0, 0}, 1, actor.BodyTypeDynamic))
+	world.AddBody(createSphere(mgl64.Vec3{1, 0, 0}, 1, actor.BodyTypeDynamic))
+
+	for range world.broadPhase() {
+	}
+	if !world.bruteForce {
+		t.Error("expected the brute force broad phase under the threshold")
+	}
+
+	world.AddBody(createSphere(mgl64.Vec3{2, 0, 0}, 1, actor.BodyTypeDynamic))
+	for range world.broadPhase() {
+	}
+	if world.bruteForce {
+		t.Error("expected the SpatialGrid once the threshold is reached")
+	}
+
+	world.BruteForceThreshold = -1
+	world.RemoveBody(world.Bodies[0])
+	for range world.broadPhase() {
+	}
+	if world.bruteForce {
+		t.Error("a negative threshold should always use the SpatialGrid")
+	}
+}
+
+// TestBoundingSpheresOverlap tests the conservative culling run before GJK
+func TestBoundingSpheresOverlap(t *testing.T) {
+	tests := []struct {
+		name     string
+		bodyA    *actor.RigidBody
+		bodyB    *actor.RigidBody
+		expected bool
+	}{
+		{
+			name:     "touching spheres",
+			bodyA:    createSphere(mgl64.Vec3{0, 0, 0}, 1, actor.BodyTypeDynamic),
+			bodyB:    createSphere(mgl64.Vec3{2, 0, 0}, 1, actor.BodyTypeDynamic),
+			expected: true,
+		},
+		{
+			name:     "diagonal spheres with overlapping AABBs",
+			bodyA:    createSphere(mgl64.Vec3{0, 0, 0}, 1, actor.BodyTypeDynamic),
+			bodyB:    createSphere(mgl64.Vec3{1.8, 1.8, 1.8}, 1, actor.BodyTypeDynamic),
+			expected: false,
+		},
+		{
+			name:     "boxes reaching with their corners",
+			bodyA:    createBox(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{1, 1, 1}, actor.BodyTypeDynamic),
+			bodyB:    createBox(mgl64.Vec3{1.9, 1.9, 1.9}, mgl64.Vec3{1, 1, 1}, actor.BodyTypeDynamic),
+			expected: true,
+		},
+		{
+			name:     "sphere beyond box corner",
+			bodyA:    createBox(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{1, 1, 1}, actor.BodyTypeDynamic),
+			bodyB:    createSphere(mgl64.Vec3{1.9, 1.9, 1.9}, 1, actor.BodyTypeDynamic),
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := boundingSpheresOverlap(tt.bodyA, tt.bodyB); result != tt.expected {
+				t.Errorf("boundingSpheresOverlap() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
 // //
 // TestNarrowPhaseNonOverlappingBoxes tests narrow phase with non-overlapping boxes
 func TestNarrowPhaseNonOverlappingBoxes(t *testing.T) {
@@ -371,7 +478,16 @@ func TestNarrowPhaseOverlappingSpheres(t *testing.T) {
 
 	// Should detect collision
 	if len(contacts) == 0 {
-		t.Error("NarrowPhase with overlapping spheres returned no contacts, expected at least 1")
+		t.Fatal("NarrowPhase with overlapping spheres returned no contacts, expected at least 1")
+	}
+
+	// Closed form: one point, along the line between the centers
+	contact := contacts[0]
+	if len(contact.Points) != 1 || math.Abs(contact.Points[0].Penetration-0.5) > 1e-9 {
+		t.Errorf("points = %+v, want one point of depth 0.5", contact.Points)
+	}
+	if contact.Normal != (mgl64.Vec3{1, 0, 0}) {
+		t.Errorf("normal = %v, want {1 0 0}", contact.Normal)
 	}
 }
 
@@ -447,6 +563,150 @@ func TestNarrowPhaseBoxOnPlane(t *testing.T) {
 	}
 }
 
+// //
+// TestNarrowPhaseConeOnBox tests narrow phase with a cone standing on a box, through GJK/EPA and the manifold
+func TestNarrowPhaseConeOnBox(t *testing.T) {
+	bodyA := createBox(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{2, 1, 2}, actor.BodyTypeStatic)
+	bodyB := actor.NewRigidBody(
+		actor.Transform{Position: mgl64.Vec3{0, 1.9, 0}, Rotation: mgl64.QuatIdent()},
+		&actor.Cone{Radius: 1, Height: 4},
+		actor.BodyTypeDynamic,
+		1.0,
+	)
+
+	pairs := make(chan Pair, 1)
+	pairs <- Pair{BodyA: bodyA, BodyB: bodyB}
+	close(pairs)
+
+	contacts := NarrowPhase(pairs, 8)
+
+	if len(contacts) != 1 {
+		t.Fatalf("NarrowPhase with cone on box returned %d contacts, expected 1", len(contacts))
+	}
+	if len(contacts[0].Points) < 3 {
+		t.Errorf("expected a base manifold of at least 3 points, got %d", len(contacts[0].Points))
+	}
+	if math.Abs(math.Abs(contacts[0].Normal.Y())-1) > 1e-6 {
+		t.Errorf("normal = %v, want vertical", contacts[0].Normal)
+	}
+}
+
+// TestNarrowPhaseConeLyingOnBox tests the side of a cone (a segment feature) against a box face
+func TestNarrowPhaseConeLyingOnBox(t *testing.T) {
+	rotation := mgl64.QuatRotate(math.Pi/2+math.Atan2(1, 4), mgl64.Vec3{0, 0, 1})
+	height := -rotation.Rotate(mgl64.Vec3{0, 3, 0}).Y()
+
+	bodyA := createBox(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{4, 1, 4}, actor.BodyTypeStatic)
+	bodyB := actor.NewRigidBody(
+		actor.Transform{Position: mgl64.Vec3{0, 1 + height - 0.05, 0}, Rotation: rotation, InverseRotation: rotation.Inverse()},
+		&actor.Cone{Radius: 1, Height: 4},
+		actor.BodyTypeDynamic,
+		1.0,
+	)
+
+	pairs := make(chan Pair, 1)
+	pairs <- Pair{BodyA: bodyA, BodyB: bodyB}
+	close(pairs)
+
+	contacts := NarrowPhase(pairs, 8)
+
+	if len(contacts) != 1 {
+		t.Fatalf("NarrowPhase with cone lying on box returned %d contacts, expected 1", len(contacts))
+	}
+	if len(contacts[0].Points) != 2 {
+		t.Errorf("expected 2 points along the side of the cone, got %d", len(contacts[0].Points))
+	}
+}
+
+// TestNarrowPhaseCrossingCones tests two segment features crossing each other: a single contact point
+func TestNarrowPhaseCrossingCones(t *testing.T) {
+	rotationA := mgl64.QuatRotate(math.Pi/2, mgl64.Vec3{0, 0, 1})
+	rotationB := mgl64.QuatRotate(-math.Pi/2, mgl64.Vec3{1, 0, 0})
+	bodyA := actor.NewRigidBody(
+		actor.Transform{Rotation: rotationA, InverseRotation: rotationA.Inverse()},
+		&actor.Cone{Radius: 1, Height: 4},
+		actor.BodyTypeDynamic,
+		1.0,
+	)
+	bodyB := actor.NewRigidBody(
+		actor.Transform{Position: mgl64.Vec3{0, 1.4, 0}, Rotation: rotationB, InverseRotation: rotationB.Inverse()},
+		&actor.Cone{Radius: 1, Height: 4},
+		actor.BodyTypeDynamic,
+		1.0,
+	)
+
+	pairs := make(chan Pair, 1)
+	pairs <- Pair{BodyA: bodyA, BodyB: bodyB}
+	close(pairs)
+
+	contacts := NarrowPhase(pairs, 8)
+
+	if len(contacts) != 1 {
+		t.Fatalf("NarrowPhase with crossing cones returned %d contacts, expected 1", len(contacts))
+	}
+	if len(contacts[0].Points) != 1 {
+		t.Errorf("expected the crossing point only, got %d points", len(contacts[0].Points))
+	}
+}
+
+// TestWorld_ConeRestsOnPlane drops a cone on its base, it must settle upright
+func TestWorld_ConeRestsOnPlane(t *testing.T) {
+	world := &World{Substeps: 8, Gravity: mgl64.Vec3{0, -9.81, 0}, Events: NewEvents()}
+	world.AddBody(createPlane(mgl64.Vec3{0, 1, 0}, 0))
+	cone := actor.NewRigidBody(
+		actor.Transform{Position: mgl64.Vec3{0, 1.5, 0}, Rotation: mgl64.QuatIdent()},
+		&actor.Cone{Radius: 1, Height: 4},
+		actor.BodyTypeDynamic,
+		1.0,
+	)
+	world.AddBody(cone)
+
+	for range 120 {
+		world.Step(1.0 / 60.0)
+	}
+
+	if y := cone.Transform.Position.Y(); math.Abs(y-1) > 0.05 {
+		t.Errorf("cone center height = %v, want about 1 (a quarter of its height)", y)
+	}
+	if up := cone.Transform.Rotation.Rotate(mgl64.Vec3{0, 1, 0}); up.Y() < 0.99 {
+		t.Errorf("cone axis = %v, want upright", up)
+	}
+}
+
+// TestWorld_ConvexHullRestsOnBox drops an octagonal prism hull on a static box, it must settle flat
+func TestWorld_ConvexHullRestsOnBox(t *testing.T) {
+	var points []mgl64.Vec3
+	for i := range 8 {
+		angle := 2 * math.Pi * float64(i) / 8
+		points = append(points, mgl64.Vec3{math.Cos(angle), -0.5, math.Sin(angle)}, mgl64.Vec3{math.Cos(angle), 0.5, math.Sin(angle)})
+	}
+	hull, err := actor.NewConvexHull(points)
+	if err != nil {
+		t.Fatalf("NewConvexHull failed: %v", err)
+	}
+
+	world := &World{Substeps: 8, Gravity: mgl64.Vec3{0, -9.81, 0}, Events: NewEvents()}
+	world.AddBody(createBox(mgl64.Vec3{0, -1, 0}, mgl64.Vec3{5, 1, 5}, actor.BodyTypeStatic))
+	body := actor.NewRigidBody(
+		actor.Transform{Position: mgl64.Vec3{0, 1, 0}, Rotation: mgl64.QuatIdent()},
+		hull,
+		actor.BodyTypeDynamic,
+		1.0,
+	)
+	world.AddBody(body)
+
+	for range 120 {
+		world.Step(1.0 / 60.0)
+	}
+
+	if y := body.Transform.Position.Y(); math.Abs(y-0.5) > 0.05 {
+		t.Errorf("hull center height = %v, want about 0.5", y)
+	}
+	if up := body.Transform.Rotation.Rotate(mgl64.Vec3{0, 1, 0}); up.Y() < 0.99 {
+		t.Errorf("hull axis = %v, want upright", up)
+	}
+}
+
 // //
 // TestNarrowPhaseMultiplePairs tests narrow phase with multiple collision pairs
 func TestNarrowPhaseMultiplePairs(t *testing.T) {
@@ -788,3 +1048,25 @@ func BenchmarkLargeWorldStep(b *testing.B) {
 	trace.Stop()
 	pprof.StopCPUProfile()
 }
+
+func TestBroadPhaseVelocityExpansion(t *testing.T) {
+	// A fast sphere 0.5m away from a box, closing in at 60m/s: they will overlap during the next substep
+	sphere := createSphere(mgl64.Vec3{0, 0, 0}, 0.5, actor.BodyTypeDynamic)
+	sphere.Velocity = mgl64.Vec3{60, 0, 0}
+	sphere.Integrate(1.0/60.0, mgl64.Vec3{})
+	box := createBox(sphere.Transform.Position.Add(mgl64.Vec3{1.5, 0, 0}), mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeStatic)
+	bodies := []*actor.RigidBody{sphere, box}
+
+	if sphere.Shape.GetAABB().Overlaps(box.Shape.GetAABB()) {
+		t.Fatal("the shapes should not overlap yet")
+	}
+	for name, pairs := range map[string]<-chan Pair{
+		"grid":        BroadPhase(NewHierarchicalSpatialGrid(1.0, 64, 3), bodies, 1),
+		"tree":        broadPhase(NewDynamicTree(0), bodies, 1),
+		"brute force": BruteForceBroadPhase(bodies),
+	} {
+		if len(collectPairs(pairs)) != 1 {
+			t.Errorf("%s: the pair of the fast sphere was not found", name)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package feather
+
+import (
+	"sync"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/akmonengine/feather/constraint"
+	"github.com/akmonengine/feather/epa"
+	"github.com/akmonengine/feather/gjk"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// isCompound returns true if the body is a Compound
+func isCompound(body *actor.RigidBody) bool {
+	_, ok := body.Shape.(*actor.Compound)
+
+	return ok
+}
+
+// isTriggerChild returns true if the part of the body is a trigger child of its compound, see actor.CompoundChild.Trigger
+func isTriggerChild(body *actor.RigidBody, part uint32) bool {
+	compound, ok := body.Shape.(*actor.Compound)
+
+	return ok && int(part) < len(compound.Children) && compound.Children[part].Trigger
+}
+
+// bodyParts appends the convex parts of the body to parts: a temporary body per child of a compound,
+// placed at the world transform of the child, or the body itself
+func bodyParts(body *actor.RigidBody, parts []*actor.RigidBody) []*actor.RigidBody {
+	compound, ok := body.Shape.(*actor.Compound)
+	if !ok {
+		return append(parts, body)
+	}
+
+	for i, child := range compound.Children {
+		parts = append(parts, &actor.RigidBody{
+			Transform: compound.ChildTransform(i, body.Transform),
+			Shape:     child.Shape,
+			BodyType:  body.BodyType,
+			Material:  body.Material,
+		})
+	}
+
+	return parts
+}
+
+// collideCompound tests the children of the compounds against the other body, or its children.
+// The contacts are given to the bodies themselves; the contacts sharing a normal are merged,
+// like the contacts of the triangles of a mesh, see mergeCoplanarContact. The contacts of the trigger children
+// are kept apart, marked as sensors.
+func collideCompound(pairs <-chan Pair, workersCount int, allocator Allocator, counters *stepCounters) <-chan *constraint.ContactConstraint {
+	ch := make(chan *constraint.ContactConstraint, workersCount)
+
+	go func() {
+		var wg sync.WaitGroup
+		defer close(ch)
+
+		for range workersCount {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				var partsA, partsB []*actor.RigidBody
+				var contacts []constraint.ContactConstraint
+				for pair := range pairs {
+					if !boundingSpheresOverlap(pair.BodyA, pair.BodyB) {
+						continue
+					}
+
+					// The children AABB are up to date, computed along the compound AABB
+					partsA = bodyParts(pair.BodyA, partsA[:0])
+					partsB = bodyParts(pair.BodyB, partsB[:0])
+					contacts = contacts[:0]
+					for i, partA := range partsA {
+						for j, partB := range partsB {
+							if !partA.Shape.GetAABB().Overlaps(partB.Shape.GetAABB()) {
+								continue
+							}
+
+							simplex := gjk.SimplexPool.Get().(*gjk.Simplex)
+							simplex.Reset()
+
+							report := gjk.GJKReport(partA, partB, simplex)
+							if report.Capped && counters != nil {
+								counters.addGJKCapped(pair.BodyA, pair.BodyB)
+							}
+							if !report.Collision {
+								gjk.SimplexPool.Put(simplex)
+								continue
+							}
+
+							contact, err := epa.EPA(partA, partB, simplex)
+							gjk.SimplexPool.Put(simplex)
+							if err != nil {
+								continue
+							}
+							for k := range contact.Points {
+								contact.Points[k].Feature.PartA = uint32(i)
+								contact.Points[k].Feature.PartB = uint32(j)
+							}
+
+							if isTriggerChild(pair.BodyA, uint32(i)) || isTriggerChild(pair.BodyB, uint32(j)) {
+								contact.Sensor = true
+								contacts = append(contacts, contact)
+								continue
+							}
+							contacts = mergeCoplanarContact(contacts, contact)
+						}
+					}
+
+					for _, contact := range contacts {
+						contact.Points = reduceMergedPoints(contact.Points)
+						result := allocator.Contact()
+						*result = contact
+						result.BodyA = pair.BodyA
+						result.BodyB = pair.BodyB
+						result.Points = append(allocator.Points(len(contact.Points))[:0], contact.Points...)
+						ch <- result
+					}
+				}
+			}()
+		}
+
+		wg.Wait()
+	}()
+
+	return ch
+}
+
+// collidePlaneSensors sends a sensor contact for each trigger child of the compound object overlapping the plane
+// of planeBody, see collideCompound: Compound.CollideWithPlane skips them
+func collidePlaneSensors(planeBody, object *actor.RigidBody, normal mgl64.Vec3, allocator Allocator, ch chan<- *constraint.ContactConstraint) {
+	compound, ok := object.Shape.(*actor.Compound)
+	if !ok {
+		return
+	}
+	plane := planeBody.Shape.(*actor.Plane)
+
+	for i, child := range compound.Children {
+		if !child.Trigger {
+			continue
+		}
+
+		collision, result := child.Shape.CollideWithPlane(plane.Normal, plane.Distance, compound.ChildTransform(i, object.Transform))
+		if !collision || len(result) == 0 {
+			continue
+		}
+
+		points := allocator.Points(len(result))
+		for k, point := range result {
+			points[k] = constraint.ContactPoint{Position: point.Position, Penetration: point.Penetration, Feature: constraint.FeatureID{PartB: uint32(i)}}
+		}
+
+		contact := allocator.Contact()
+		contact.BodyA = planeBody
+		contact.BodyB = object
+		contact.Normal = normal
+		contact.Points = points
+		contact.Sensor = true
+
+		ch <- contact
+	}
+}
@@ -0,0 +1,230 @@
+package feather
+
+import (
+	"math"
+	"testing"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// createTable creates a dynamic table: a top of 2x0.2x2 on 4 legs of 0.2x0.8x0.2, the legs end at y
+func createTable(t *testing.T, y float64) *actor.RigidBody {
+	t.Helper()
+	children := []actor.CompoundChild{
+		{Shape: &actor.Box{HalfExtents: mgl64.Vec3{1, 0.1, 1}}, Position: mgl64.Vec3{0, 0.9, 0}},
+	}
+	for _, x := range []float64{-0.9, 0.9} {
+		for _, z := range []float64{-0.9, 0.9} {
+			children = append(children, actor.CompoundChild{
+				Shape:    &actor.Box{HalfExtents: mgl64.Vec3{0.1, 0.4, 0.1}},
+				Position: mgl64.Vec3{x, 0.4, z},
+			})
+		}
+	}
+
+	compound, err := actor.NewCompound(children)
+	if err != nil {
+		t.Fatalf("NewCompound failed: %v", err)
+	}
+
+	return actor.NewRigidBody(
+		actor.Transform{Position: compound.CenterOfMass.Add(mgl64.Vec3{0, y, 0}), Rotation: mgl64.QuatIdent()},
+		compound,
+		actor.BodyTypeDynamic,
+		500,
+	)
+}
+
+// checkTableRests checks the legs of the table stand at the given height, and the table is upright
+func checkTableRests(t *testing.T, table *actor.RigidBody, ground float64) {
+	t.Helper()
+	compound := table.Shape.(*actor.Compound)
+
+	if bottom := table.Transform.Position.Y() - compound.CenterOfMass.Y(); math.Abs(bottom-ground) > 0.02 {
+		t.Errorf("table legs at %v, want about %v", bottom, ground)
+	}
+	if up := table.Transform.Rotation.Rotate(mgl64.Vec3{0, 1, 0}); up.Y() < 0.999 {
+		t.Errorf("table axis = %v, want upright", up)
+	}
+}
+
+// TestWorld_CompoundRestsOnBox drops a table on a static box: only the legs touch the ground
+func TestWorld_CompoundRestsOnBox(t *testing.T) {
+	world := &World{Substeps: 8, Gravity: mgl64.Vec3{0, -9.81, 0}, Events: NewEvents()}
+	world.AddBody(createBox(mgl64.Vec3{0, -1, 0}, mgl64.Vec3{5, 1, 5}, actor.BodyTypeStatic))
+	table := createTable(t, 0.3)
+	world.AddBody(table)
+
+	for range 120 {
+		world.Step(1.0 / 60.0)
+	}
+
+	checkTableRests(t, table, 0)
+}
+
+// TestWorld_CompoundRemoveChild removes the top of a resting table: the legs stay standing, lighter
+func TestWorld_CompoundRemoveChild(t *testing.T) {
+	world := &World{Substeps: 8, Gravity: mgl64.Vec3{0, -9.81, 0}, Events: NewEvents()}
+	world.AddBody(createBox(mgl64.Vec3{0, -1, 0}, mgl64.Vec3{5, 1, 5}, actor.BodyTypeStatic))
+	table := createTable(t, 0)
+	world.AddBody(table)
+	for range 60 {
+		world.Step(1.0 / 60.0)
+	}
+
+	changes := &eventCapture{}
+	world.Events.Subscribe(COMPOUND_CHANGED, changes.capture)
+	compound := table.Shape.(*actor.Compound)
+	mass := table.Material.GetMass()
+	if _, err := compound.RemoveChild(0); err != nil {
+		t.Fatalf("RemoveChild failed: %v", err)
+	}
+	for range 60 {
+		world.Step(1.0 / 60.0)
+	}
+
+	if len(changes.events) != 1 || changes.events[0].(CompoundChangedEvent).Body != table {
+		t.Fatalf("events = %v, want a single COMPOUND_CHANGED of the table", changes.events)
+	}
+	if legs := table.Material.GetMass(); math.Abs(legs-mass*0.128/0.928) > 1e-9 {
+		t.Errorf("mass = %v, want the mass of the legs", legs)
+	}
+	for i := range compound.Children {
+		if bottom := compound.ChildTransform(i, table.Transform).Position.Y() - 0.4; math.Abs(bottom) > 0.02 {
+			t.Errorf("leg %d at %v, want standing on the ground", i, bottom)
+		}
+	}
+}
+
+// TestWorld_CompoundOnCompound drops a box between the legs, on top of the table
+func TestWorld_CompoundOnCompound(t *testing.T) {
+	world := &World{Substeps: 8, Gravity: mgl64.Vec3{0, -9.81, 0}, Events: NewEvents()}
+	world.AddBody(createPlane(mgl64.Vec3{0, 1, 0}, 0))
+	table := createTable(t, 0)
+	world.AddBody(table)
+	crate := createBox(mgl64.Vec3{0, 1.6, 0}, mgl64.Vec3{0.3, 0.3, 0.3}, actor.BodyTypeDynamic)
+	world.AddBody(crate)
+
+	for range 120 {
+		world.Step(1.0 / 60.0)
+	}
+
+	checkTableRests(t, table, 0)
+	if y := crate.Transform.Position.Y(); math.Abs(y-1.3) > 0.02 {
+		t.Errorf("crate height = %v, want about 1.3 on the table top", y)
+	}
+}
+
+// TestWorld_CompoundTriggerChild drives a vehicle with a solid chassis and a bumper sensor: the bumper overlaps
+// the ground and a wall without contacts, and sends the trigger events of its own pairs
+func TestWorld_CompoundTriggerChild(t *testing.T) {
+	world := &World{Substeps: 8, Gravity: mgl64.Vec3{0, -9.81, 0}, Events: NewEvents()}
+	ground := createPlane(mgl64.Vec3{0, 1, 0}, 0)
+	world.AddBody(ground)
+	wall := createBox(mgl64.Vec3{2.1, 0.5, 0}, mgl64.Vec3{0.9, 0.5, 1}, actor.BodyTypeStatic)
+	world.AddBody(wall)
+
+	// The bumper sinks in the ground and reaches into the wall, the chassis touches the ground only
+	compound, err := actor.NewCompound([]actor.CompoundChild{
+		{Shape: &actor.Box{HalfExtents: mgl64.Vec3{1, 0.25, 0.5}}},
+		{Shape: &actor.Box{HalfExtents: mgl64.Vec3{0.2, 0.2, 0.5}}, Position: mgl64.Vec3{1.2, -0.1, 0}, Trigger: true},
+	})
+	if err != nil {
+		t.Fatalf("NewCompound failed: %v", err)
+	}
+	vehicle := actor.NewRigidBody(
+		actor.Transform{Position: compound.CenterOfMass.Add(mgl64.Vec3{0, 0.25, 0}), Rotation: mgl64.QuatIdent()},
+		compound,
+		actor.BodyTypeDynamic,
+		1000,
+	)
+	world.AddBody(vehicle)
+	start := vehicle.Transform.Position
+
+	events := &eventCapture{}
+	for _, eventType := range []EventType{TRIGGER_ENTER, TRIGGER_EXIT, COLLISION_ENTER} {
+		world.Events.Subscribe(eventType, events.capture)
+	}
+	for range 30 {
+		world.Step(1.0 / 60.0)
+	}
+
+	bumper := TriggerChild{Body: vehicle, Index: 1}
+	entered := map[*actor.RigidBody]bool{}
+	for _, event := range events.events {
+		switch event := event.(type) {
+		case TriggerEnterEvent:
+			if event.Child != bumper {
+				t.Errorf("TRIGGER_ENTER of %+v, want the bumper", event.Child)
+			}
+			entered[event.BodyA], entered[event.BodyB] = true, true
+		case CollisionEnterEvent:
+			if event.BodyA == wall || event.BodyB == wall {
+				t.Error("COLLISION_ENTER with the wall, want the bumper to let it through")
+			}
+		}
+	}
+	if !entered[ground] || !entered[wall] {
+		t.Errorf("bumper entered the ground %v and the wall %v, want both", entered[ground], entered[wall])
+	}
+	if !events.hasEventType(COLLISION_ENTER) {
+		t.Error("no COLLISION_ENTER, want the chassis on the ground")
+	}
+	if moved := vehicle.Transform.Position.Sub(start); moved.Len() > 0.01 {
+		t.Errorf("vehicle moved by %v, want the chassis resting, neither lifted nor pushed by the bumper", moved)
+	}
+
+	// Backing off, the bumper leaves the wall only
+	events.reset()
+	vehicle.Velocity = mgl64.Vec3{-5, 0, 0}
+	for range 30 {
+		world.Step(1.0 / 60.0)
+	}
+
+	exits := 0
+	for _, event := range events.events {
+		if exit, ok := event.(TriggerExitEvent); ok {
+			exits++
+			if exit.Child != bumper || (exit.BodyA != wall && exit.BodyB != wall) {
+				t.Errorf("TRIGGER_EXIT of %+v with %v and %v, want the bumper leaving the wall", exit.Child, exit.BodyA.Label(), exit.BodyB.Label())
+			}
+		}
+	}
+	if exits != 1 {
+		t.Errorf("%d TRIGGER_EXIT, want the bumper leaving the wall", exits)
+	}
+}
+
+// TestWorld_CompoundRestsOnTriangleMesh tests the legs one by one against the triangles
+func TestWorld_CompoundRestsOnTriangleMesh(t *testing.T) {
+	var vertices []mgl64.Vec3
+	var indices [][3]int
+	const size = 8
+	for i := 0; i <= size; i++ {
+		for j := 0; j <= size; j++ {
+			vertices = append(vertices, mgl64.Vec3{float64(i - size/2), 0, float64(j - size/2)})
+		}
+	}
+	for i := 0; i < size; i++ {
+		for j := 0; j < size; j++ {
+			a, b, c, d := i*(size+1)+j, i*(size+1)+j+1, (i+1)*(size+1)+j, (i+1)*(size+1)+j+1
+			indices = append(indices, [3]int{a, b, c}, [3]int{c, b, d})
+		}
+	}
+	mesh, err := actor.NewTriangleMesh(vertices, indices)
+	if err != nil {
+		t.Fatalf("NewTriangleMesh failed: %v", err)
+	}
+
+	world := &World{Substeps: 8, Gravity: mgl64.Vec3{0, -9.81, 0}, Events: NewEvents()}
+	world.AddBody(actor.NewRigidBody(actor.NewTransform(), mesh, actor.BodyTypeStatic, 0))
+	table := createTable(t, 0.3)
+	world.AddBody(table)
+
+	for range 120 {
+		world.Step(1.0 / 60.0)
+	}
+
+	checkTableRests(t, table, 0)
+}
@@ -0,0 +1,89 @@
+package constraint
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// Invariant checks of the solver, run only when built with the featherdebug tag:
+//
+//	go test -tags featherdebug ./...
+//
+// A broken invariant panics with the state of the contact, so integration bugs (NaN positions,
+// unnormalized shapes, teleported bodies) surface where they happen instead of as a slow drift.
+// The calls are guarded by the DebugAssertions constant, the compiler removes them from release builds.
+
+const (
+	// assertNormalTolerance is the accepted deviation of the contact normal length from 1
+	assertNormalTolerance = 1e-6
+	// assertSymmetryTolerance is the accepted relative asymmetry of an inverse inertia tensor
+	assertSymmetryTolerance = 1e-9
+)
+
+// assertContact checks the contact before it is solved: a normalized normal, symmetric inverse
+// inertia tensors, and penetrations no deeper than the smaller body
+func (c *ContactConstraint) assertContact(inverseInertiaA, inverseInertiaB mgl64.Mat3) {
+	if length := c.Normal.Len(); math.Abs(length-1) > assertNormalTolerance {
+		c.fail(fmt.Sprintf("contact normal not normalized (length %v)", length))
+	}
+
+	if !isSymmetric(inverseInertiaA) {
+		c.fail(fmt.Sprintf("inverse inertia of body A not symmetric: %v", inverseInertiaA))
+	}
+	if !isSymmetric(inverseInertiaB) {
+		c.fail(fmt.Sprintf("inverse inertia of body B not symmetric: %v", inverseInertiaB))
+	}
+
+	maxPenetration := 2 * math.Min(c.BodyA.Shape.BoundingRadius(), c.BodyB.Shape.BoundingRadius())
+	for i, point := range c.Points {
+		if !isFiniteVec(point.Position) || math.IsNaN(point.Penetration) || math.IsInf(point.Penetration, 0) {
+			c.fail(fmt.Sprintf("contact point %d not finite: %+v", i, point))
+		}
+		if point.Penetration > maxPenetration {
+			c.fail(fmt.Sprintf("contact point %d penetration %v deeper than the smaller body (%v)", i, point.Penetration, maxPenetration))
+		}
+	}
+}
+
+// assertFinite checks a correction or an impulse applied by the solver
+func (c *ContactConstraint) assertFinite(name string, v mgl64.Vec3) {
+	if !isFiniteVec(v) {
+		c.fail(fmt.Sprintf("%s not finite: %v", name, v))
+	}
+}
+
+func (c *ContactConstraint) fail(message string) {
+	panic(fmt.Sprintf("feather: %s\n\tnormal: %v\n\tpoints: %+v\n\tbody A: %s\n\tbody B: %s",
+		message, c.Normal, c.Points, describeBody(c.BodyA), describeBody(c.BodyB)))
+}
+
+func describeBody(body *actor.RigidBody) string {
+	return fmt.Sprintf("{Name: %q, Id: %v, Type: %v, Shape: %T, Position: %v, Rotation: %v, Velocity: %v, AngularVelocity: %v, Mass: %v}",
+		body.Name, body.Id, body.BodyType, body.Shape, body.Transform.Position, body.Transform.Rotation,
+		body.Velocity, body.AngularVelocity, body.Material.GetMass())
+}
+
+func isFiniteVec(v mgl64.Vec3) bool {
+	for _, x := range v {
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isSymmetric(m mgl64.Mat3) bool {
+	scale := 0.0
+	for _, x := range m {
+		scale = math.Max(scale, math.Abs(x))
+	}
+	tolerance := assertSymmetryTolerance * math.Max(scale, 1)
+
+	return math.Abs(m.At(0, 1)-m.At(1, 0)) <= tolerance &&
+		math.Abs(m.At(0, 2)-m.At(2, 0)) <= tolerance &&
+		math.Abs(m.At(1, 2)-m.At(2, 1)) <= tolerance
+}
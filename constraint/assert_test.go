@@ -0,0 +1,106 @@
+package constraint
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// expectPanic runs f and returns the panic message, failing the test if f does not panic
+func expectPanic(t *testing.T, f func()) string {
+	t.Helper()
+
+	var message string
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				message, _ = r.(string)
+			}
+		}()
+		f()
+	}()
+
+	if message == "" {
+		t.Fatal("expected a panic")
+	}
+
+	return message
+}
+
+func createAssertContact() *ContactConstraint {
+	bodyA := createStaticBody(mgl64.Vec3{0, 0, 0})
+	bodyB := createDynamicBody(mgl64.Vec3{0, 1.9, 0}, mgl64.Vec3{}, 1.0)
+	bodyB.Id = "falling ball"
+
+	return &ContactConstraint{
+		BodyA:  bodyA,
+		BodyB:  bodyB,
+		Normal: mgl64.Vec3{0, 1, 0},
+		Points: []ContactPoint{{Position: mgl64.Vec3{0, 1, 0}, Penetration: 0.1}},
+	}
+}
+
+func TestAssertContact_Valid(t *testing.T) {
+	c := createAssertContact()
+
+	c.assertContact(c.BodyA.GetInverseInertiaWorld(), c.BodyB.GetInverseInertiaWorld())
+	c.assertFinite("impulse", mgl64.Vec3{1, 2, 3})
+}
+
+func TestAssertContact_NormalNotNormalized(t *testing.T) {
+	c := createAssertContact()
+	c.Normal = mgl64.Vec3{0, 2, 0}
+
+	message := expectPanic(t, func() {
+		c.assertContact(c.BodyA.GetInverseInertiaWorld(), c.BodyB.GetInverseInertiaWorld())
+	})
+	if !strings.Contains(message, "not normalized") || !strings.Contains(message, "falling ball") {
+		t.Errorf("panic message lacks context: %s", message)
+	}
+}
+
+func TestAssertContact_AsymmetricInertia(t *testing.T) {
+	c := createAssertContact()
+	asymmetric := mgl64.Mat3{1, 0.5, 0, 0, 1, 0, 0, 0, 1}
+
+	message := expectPanic(t, func() {
+		c.assertContact(c.BodyA.GetInverseInertiaWorld(), asymmetric)
+	})
+	if !strings.Contains(message, "body B not symmetric") {
+		t.Errorf("unexpected panic message: %s", message)
+	}
+}
+
+func TestAssertContact_Penetration(t *testing.T) {
+	c := createAssertContact()
+
+	// Deeper than the unit ball
+	c.Points[0].Penetration = 2.5
+	message := expectPanic(t, func() {
+		c.assertContact(c.BodyA.GetInverseInertiaWorld(), c.BodyB.GetInverseInertiaWorld())
+	})
+	if !strings.Contains(message, "deeper than the smaller body") {
+		t.Errorf("unexpected panic message: %s", message)
+	}
+
+	c.Points[0].Penetration = math.NaN()
+	message = expectPanic(t, func() {
+		c.assertContact(c.BodyA.GetInverseInertiaWorld(), c.BodyB.GetInverseInertiaWorld())
+	})
+	if !strings.Contains(message, "not finite") {
+		t.Errorf("unexpected panic message: %s", message)
+	}
+}
+
+func TestAssertFinite(t *testing.T) {
+	c := createAssertContact()
+
+	message := expectPanic(t, func() {
+		c.assertFinite("linear impulse of body B", mgl64.Vec3{0, math.Inf(1), 0})
+	})
+	if !strings.Contains(message, "linear impulse of body B not finite") {
+		t.Errorf("unexpected panic message: %s", message)
+	}
+}
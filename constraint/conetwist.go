@@ -0,0 +1,192 @@
+package constraint
+
+import (
+	"math"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// ConeTwistJoint joins two bodies at a pivot (ball and socket), the axis of BodyB swinging in a cone
+// around the axis of BodyA and twisting about it within limits: ragdoll shoulders, hips and necks.
+type ConeTwistJoint struct {
+	BodyA *actor.RigidBody
+	BodyB *actor.RigidBody
+
+	// LocalAnchorA and LocalAnchorB are the pivot in the local space of each body
+	LocalAnchorA mgl64.Vec3
+	LocalAnchorB mgl64.Vec3
+	// LocalAxisA and LocalAxisB are the unit twist axis in the local space of each body, the center of the cone
+	LocalAxisA mgl64.Vec3
+	LocalAxisB mgl64.Vec3
+	// LocalReferenceA and LocalReferenceB are unit vectors orthogonal to the axis, aligned at the twist 0
+	LocalReferenceA mgl64.Vec3
+	LocalReferenceB mgl64.Vec3
+
+	// Compliance is the inverse stiffness (m/N) of the pivot, 0 is rigid
+	Compliance float64
+
+	// EnableSwingLimit keeps the angle between the axes under SwingLimit (rad, the half angle of the cone, in [0, π])
+	EnableSwingLimit bool
+	SwingLimit       float64
+
+	// EnableTwistLimits keeps the twist of BodyB relative to BodyA in [LowerTwist, UpperTwist] (rad, in [-π, π])
+	EnableTwistLimits bool
+	LowerTwist        float64
+	UpperTwist        float64
+
+	// DisableCollision skips the collisions between the bodies, e.g. the segments of a ragdoll overlapping at the pivot
+	DisableCollision bool
+
+	// JointLoad measures the force held by the pivot and the torque held by the limits,
+	// and breaks the joint over its thresholds
+	JointLoad
+
+	// accumulate keeps the multiplier over the passes of SolvePosition, see BeginSubstep
+	accumulate bool
+	// The multipliers of the pivot, of the cone and of the twist limits, accumulated since BeginSubstep
+	positionLambda float64
+	swingLambda    float64
+	twistLambda    float64
+}
+
+// NewConeTwistJoint joins the bodies at the pivot, the cone centered on the twist axis, both in world space
+// at the current poses. The current pose is the swing 0 and the twist 0.
+func NewConeTwistJoint(bodyA, bodyB *actor.RigidBody, pivot, axis mgl64.Vec3) *ConeTwistJoint {
+	axis = axis.Normalize()
+	reference, _ := tangentBasis(axis)
+	inverseA := bodyA.Transform.Rotation.Conjugate()
+	inverseB := bodyB.Transform.Rotation.Conjugate()
+
+	return &ConeTwistJoint{
+		BodyA:           bodyA,
+		BodyB:           bodyB,
+		LocalAnchorA:    inverseA.Rotate(pivot.Sub(bodyA.Transform.Position)),
+		LocalAnchorB:    inverseB.Rotate(pivot.Sub(bodyB.Transform.Position)),
+		LocalAxisA:      inverseA.Rotate(axis),
+		LocalAxisB:      inverseB.Rotate(axis),
+		LocalReferenceA: inverseA.Rotate(reference),
+		LocalReferenceB: inverseB.Rotate(reference),
+	}
+}
+
+// SetSwingLimit enables the cone, of half angle swing (rad, in [0, π])
+func (c *ConeTwistJoint) SetSwingLimit(swing float64) {
+	c.EnableSwingLimit = true
+	c.SwingLimit = swing
+}
+
+// SetTwistLimits enables the limits of the twist (rad, in [-π, π]), lower must not exceed upper
+func (c *ConeTwistJoint) SetTwistLimits(lower, upper float64) {
+	c.EnableTwistLimits = true
+	c.LowerTwist = lower
+	c.UpperTwist = upper
+}
+
+// Bodies returns the joined bodies, see Connector
+func (c *ConeTwistJoint) Bodies() []*actor.RigidBody {
+	return []*actor.RigidBody{c.BodyA, c.BodyB}
+}
+
+// CollisionDisabled returns DisableCollision, see CollisionDisabler
+func (c *ConeTwistJoint) CollisionDisabled() bool {
+	return c.DisableCollision
+}
+
+// BeginSubstep restarts the accumulation of the multipliers (see Accumulator)
+func (c *ConeTwistJoint) BeginSubstep(accumulate bool) {
+	c.accumulate = accumulate
+	c.positionLambda = 0
+	c.swingLambda = 0
+	c.twistLambda = 0
+}
+
+// SwingAngle returns the angle (rad, in [0, π]) between the axes of the bodies
+func (c *ConeTwistJoint) SwingAngle() float64 {
+	swing, _ := swingAngle(c.BodyA.Transform.Rotation.Rotate(c.LocalAxisA), c.BodyB.Transform.Rotation.Rotate(c.LocalAxisB))
+
+	return swing
+}
+
+// TwistAngle returns the angle (rad, in [-π, π]) of BodyB relative to BodyA about the axis of BodyA,
+// once the swing is removed. It is undefined when the axes are opposite.
+func (c *ConeTwistJoint) TwistAngle() float64 {
+	axisA := c.BodyA.Transform.Rotation.Rotate(c.LocalAxisA)
+	referenceA := c.BodyA.Transform.Rotation.Rotate(c.LocalReferenceA)
+	referenceB := c.BodyB.Transform.Rotation.Rotate(c.LocalReferenceB)
+
+	// The reference of BodyB is swung back by the shortest rotation from its axis onto the axis of BodyA
+	swing, swingAxis := swingAngle(axisA, c.BodyB.Transform.Rotation.Rotate(c.LocalAxisB))
+	if swing > 0 {
+		referenceB = mgl64.QuatRotate(-swing, swingAxis).Rotate(referenceB)
+	}
+
+	return twistAngle(axisA, referenceA, referenceB)
+}
+
+// SolvePosition keeps the axis of BodyB in the cone and its twist in the limits, then joins the pivots
+func (c *ConeTwistJoint) SolvePosition(dt float64) {
+	bodyA := c.BodyA
+	bodyB := c.BodyB
+	if c.broken || (!bodyA.IsActive() && !bodyB.IsActive()) {
+		return
+	}
+
+	lockBodies(bodyA, bodyB)
+	defer unlockBodies(bodyA, bodyB)
+
+	axisA := bodyA.Transform.Rotation.Rotate(c.LocalAxisA)
+	axisB := bodyB.Transform.Rotation.Rotate(c.LocalAxisB)
+	swing, swingAxis := swingAngle(axisA, axisB)
+	pivotA := bodyA.Transform.Position.Add(bodyA.Transform.Rotation.Rotate(c.LocalAnchorA))
+	pivotB := bodyB.Transform.Position.Add(bodyB.Transform.Rotation.Rotate(c.LocalAnchorB))
+	wakeUp(bodyA, bodyB, pivotB.Sub(pivotA).Len(), c.swingViolation(swing))
+
+	solver := newJointSolver(bodyA, bodyB, c.Compliance, dt, c.accumulate)
+	if !solver.movable() {
+		return
+	}
+
+	// 1. The axis of BodyB is rotated back into the cone, the limits are rigid
+	if violation := c.swingViolation(swing); violation > 0 {
+		c.swingLambda += solver.correctRotation(swingAxis, violation, nil)
+	}
+
+	// 2. The twist is measured after the swing correction, which changed it. Each body turns about its own axis,
+	// so the swing is kept.
+	if c.EnableTwistLimits {
+		axisA = bodyA.Transform.Rotation.Rotate(c.LocalAxisA)
+		axisB = bodyB.Transform.Rotation.Rotate(c.LocalAxisB)
+		twist := c.TwistAngle()
+		if twist < c.LowerTwist {
+			c.twistLambda += solver.correctTwist(axisA, axisB, twist-c.LowerTwist)
+		} else if twist > c.UpperTwist {
+			c.twistLambda += solver.correctTwist(axisA, axisB, twist-c.UpperTwist)
+		}
+	}
+
+	// 3. The pivots are joined, after the rotations which moved them
+	rA := bodyA.Transform.Rotation.Rotate(c.LocalAnchorA)
+	rB := bodyB.Transform.Rotation.Rotate(c.LocalAnchorB)
+	delta := bodyB.Transform.Position.Add(rB).Sub(bodyA.Transform.Position.Add(rA))
+	solver.correctPosition(rA, rB, delta, &c.positionLambda)
+}
+
+// SolveVelocity measures the loads of the substep, the joint acts on the positions only.
+// The cone holds torques orthogonal to the axis, the twist limits about it.
+func (c *ConeTwistJoint) SolveVelocity(dt float64) {
+	if c.broken || (!c.BodyA.IsActive() && !c.BodyB.IsActive()) {
+		return
+	}
+
+	c.measure(c.positionLambda, math.Hypot(c.swingLambda, c.twistLambda), dt)
+}
+
+// swingViolation returns the angle (rad) of the swing beyond the cone, 0 inside it
+func (c *ConeTwistJoint) swingViolation(swing float64) float64 {
+	if !c.EnableSwingLimit {
+		return 0
+	}
+
+	return math.Max(0, swing-c.SwingLimit)
+}
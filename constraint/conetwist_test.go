@@ -0,0 +1,85 @@
+package constraint
+
+import (
+	"math"
+	"testing"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// createConeTwist joins a dynamic unit sphere hanging at (0, -1, 0) to a static body at the origin, the cone around -Y
+func createConeTwist() (*ConeTwistJoint, *actor.RigidBody) {
+	shoulder := createStaticBody(mgl64.Vec3{0, 0, 0})
+	shoulder.Transform.Rotation = mgl64.QuatIdent()
+	arm := createDynamicBody(mgl64.Vec3{0, -1, 0}, mgl64.Vec3{}, 1.0)
+	arm.Transform.Rotation = mgl64.QuatIdent()
+
+	return NewConeTwistJoint(shoulder, arm, mgl64.Vec3{0, 0, 0}, mgl64.Vec3{0, -1, 0}), arm
+}
+
+// swingArm rotates the arm about the pivot, by the swing about X then the twist about its own axis
+func swingArm(arm *actor.RigidBody, swing, twist float64) {
+	arm.Transform.Rotation = mgl64.QuatRotate(swing, mgl64.Vec3{1, 0, 0}).Mul(mgl64.QuatRotate(twist, mgl64.Vec3{0, -1, 0}))
+	arm.Transform.Position = arm.Transform.Rotation.Rotate(mgl64.Vec3{0, -1, 0})
+}
+
+func TestConeTwistJoint_Angles(t *testing.T) {
+	c, arm := createConeTwist()
+
+	// The swing does not count as twist
+	swingArm(arm, 0.8, 0)
+	if swing, twist := c.SwingAngle(), c.TwistAngle(); math.Abs(swing-0.8) > 1e-9 || math.Abs(twist) > 1e-9 {
+		t.Errorf("swing %v and twist %v, want 0.8 and 0", swing, twist)
+	}
+
+	swingArm(arm, 0.8, 0.3)
+	if swing, twist := c.SwingAngle(), c.TwistAngle(); math.Abs(swing-0.8) > 1e-9 || math.Abs(twist-0.3) > 1e-9 {
+		t.Errorf("swing %v and twist %v, want 0.8 and 0.3", swing, twist)
+	}
+}
+
+func TestConeTwistJoint_SwingLimit(t *testing.T) {
+	c, arm := createConeTwist()
+	c.SetSwingLimit(0.5)
+
+	swingArm(arm, 1.2, 0)
+	for range 20 {
+		c.SolvePosition(1.0 / 60.0)
+	}
+	if swing := c.SwingAngle(); math.Abs(swing-0.5) > 1e-3 {
+		t.Errorf("swing %v, want held on the cone 0.5", swing)
+	}
+	pivot := arm.Transform.Position.Add(arm.Transform.Rotation.Rotate(c.LocalAnchorB))
+	if pivot.Len() > 1e-3 {
+		t.Errorf("pivot of the arm at %v, want at the origin", pivot)
+	}
+
+	// Inside the cone, the arm is free
+	swingArm(arm, 0.3, 0.2)
+	c.SolvePosition(1.0 / 60.0)
+	if swing, twist := c.SwingAngle(), c.TwistAngle(); math.Abs(swing-0.3) > 1e-9 || math.Abs(twist-0.2) > 1e-9 {
+		t.Errorf("swing %v and twist %v, want free at 0.3 and 0.2", swing, twist)
+	}
+}
+
+func TestConeTwistJoint_TwistLimits(t *testing.T) {
+	c, arm := createConeTwist()
+	c.SetTwistLimits(-0.2, 0.4)
+
+	swingArm(arm, 0.6, 1)
+	for range 20 {
+		c.SolvePosition(1.0 / 60.0)
+	}
+	if swing, twist := c.SwingAngle(), c.TwistAngle(); math.Abs(twist-0.4) > 1e-3 || math.Abs(swing-0.6) > 1e-3 {
+		t.Errorf("swing %v and twist %v, want the twist held at 0.4 and the swing kept at 0.6", swing, twist)
+	}
+
+	swingArm(arm, 0, -1)
+	for range 20 {
+		c.SolvePosition(1.0 / 60.0)
+	}
+	if twist := c.TwistAngle(); math.Abs(twist+0.2) > 1e-3 {
+		t.Errorf("twist %v, want held at -0.2", twist)
+	}
+}
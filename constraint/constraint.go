@@ -7,29 +7,113 @@ import (
 	"github.com/go-gl/mathgl/mgl64"
 )
 
+// Constraint is solved by the world at each substep of duration dt, the user constraints being added with
+// World.AddConstraint (e.g. a mouse pick, a path follower). SolvePosition is called PositionIterations times,
+// after the bodies are integrated, to move them; then SolveVelocity VelocityIterations times, once the velocities
+// are derived from the moves. A constraint may implement the optional interfaces below, called by the world:
+// PreSolver, Accumulator, Connector, CollisionDisabler, Breakable.
 type Constraint interface {
 	SolvePosition(dt float64)
 	SolveVelocity(dt float64)
 }
 
+// PreSolver is implemented by the constraints preparing each substep, before the first call to SolvePosition
+// (e.g. a mouse pick reading the cursor target, a path follower advancing its target along the path).
+// The constraints are prepared sequentially, in the order they were added.
+type PreSolver interface {
+	PreSolve(dt float64)
+}
+
+// Connector is implemented by the constraints reporting the bodies they move: the world solves them
+// in the island of these bodies, in parallel with the other islands. The constraints without it
+// (e.g. ParticleAttachment, whose particles may be shared) are solved apart, before the islands.
+type Connector interface {
+	Bodies() []*actor.RigidBody
+}
+
+// CollisionDisabler is implemented by the joints which may disable the collisions between their bodies
+// (e.g. the segments of a ragdoll, overlapping at their joints): the world skips their pair in the narrow phase.
+// The bodies are given by Connector.
+type CollisionDisabler interface {
+	CollisionDisabled() bool
+}
+
+// Breakable is implemented by the joints breaking under their load (the joints embedding JointLoad):
+// the world removes a broken joint at the end of the substep, and emits a JOINT_BROKEN event with its Impulses.
+type Breakable interface {
+	Broken() bool
+	Impulses() (float64, float64)
+}
+
+// Accumulator is implemented by the constraints accumulating their Lagrange multiplier over the position passes
+// of a substep (XPBD). BeginSubstep is called before the first pass, it restarts the multiplier from 0:
+// with accumulate, the passes converge toward the stiffness of the compliance, whatever their count.
+// Without it, each pass computes its correction from 0 and the constraint gets stiffer with the passes.
+type Accumulator interface {
+	BeginSubstep(accumulate bool)
+}
+
+// CombinedMaterial holds the coefficients of a contact, combined from the materials of its two bodies
+type CombinedMaterial struct {
+	Restitution      float64
+	RestitutionCurve actor.RestitutionCurve
+	StaticFriction   float64
+	DynamicFriction  float64
+	SpinFriction     float64
+}
+
+// CombineMaterials computes the coefficients of a contact between two materials
+func CombineMaterials(matA, matB actor.Material) CombinedMaterial {
+	return CombinedMaterial{
+		Restitution:      ComputeRestitution(matA, matB),
+		RestitutionCurve: ComputeRestitutionCurve(matA, matB),
+		StaticFriction:   ComputeStaticFriction(matA, matB),
+		DynamicFriction:  ComputeDynamicFriction(matA, matB),
+		SpinFriction:     ComputeSpinFriction(matA, matB),
+	}
+}
+
+// ComputeRestitution mixes the restitutions by the combine mode of the materials, the average by default
 func ComputeRestitution(matA, matB actor.Material) float64 {
-	// Option 1: Average (more realistic)
-	return (matA.Restitution + matB.Restitution) / 2.0
+	mode := combineMode(matA.RestitutionCombine, matB.RestitutionCombine, actor.CombineAverage)
 
-	// Option 2: Maximum (if one bounces, it bounces)
-	//return math.Max(matA.Restitution, matB.Restitution)
+	return mode.Combine(matA.Restitution, matB.Restitution)
+}
 
-	// Option 3: Geometric mean (Box2D approach)
-	// return math.Sqrt(matA.Restitution * matB.Restitution)
+// ComputeRestitutionCurve keeps the highest speeds of both curves: the material damping the bounces the most wins
+func ComputeRestitutionCurve(matA, matB actor.Material) actor.RestitutionCurve {
+	return actor.RestitutionCurve{
+		MinSpeed: math.Max(matA.RestitutionCurve.MinSpeed, matB.RestitutionCurve.MinSpeed),
+		MaxSpeed: math.Max(matA.RestitutionCurve.MaxSpeed, matB.RestitutionCurve.MaxSpeed),
+	}
 }
 
+// ComputeStaticFriction mixes the static frictions by the combine mode of the materials, the geometric mean by default
 func ComputeStaticFriction(matA, matB actor.Material) float64 {
-	// Moyenne géométrique (standard en physique)
-	return math.Sqrt(matA.StaticFriction * matB.StaticFriction)
+	return frictionMode(matA, matB).Combine(matA.StaticFriction, matB.StaticFriction)
 }
 
 func ComputeDynamicFriction(matA, matB actor.Material) float64 {
-	return math.Sqrt(matA.DynamicFriction * matB.DynamicFriction)
+	return frictionMode(matA, matB).Combine(matA.DynamicFriction, matB.DynamicFriction)
+}
+
+// ComputeSpinFriction combines the torsional friction lengths like the friction coefficients
+func ComputeSpinFriction(matA, matB actor.Material) float64 {
+	return frictionMode(matA, matB).Combine(matA.SpinFriction, matB.SpinFriction)
+}
+
+// frictionMode returns the combine mode of the frictions of two materials
+func frictionMode(matA, matB actor.Material) actor.CombineMode {
+	return combineMode(matA.FrictionCombine, matB.FrictionCombine, actor.CombineGeometric)
+}
+
+// combineMode returns the highest mode of two materials, or the default one of the coefficient
+func combineMode(modeA, modeB, defaultMode actor.CombineMode) actor.CombineMode {
+	if mode := max(modeA, modeB); mode != actor.CombineDefault {
+		return mode
+	}
+
+	return defaultMode
 }
 
 func clampSmallVelocities(rb *actor.RigidBody) {
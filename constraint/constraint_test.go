@@ -77,6 +77,81 @@ func TestComputeRestitution(t *testing.T) {
 	}
 }
 
+func TestCombineMaterials(t *testing.T) {
+	matA := actor.Material{Restitution: 0.2, StaticFriction: 0.4, DynamicFriction: 0.1, SpinFriction: 0.01}
+	matB := actor.Material{Restitution: 0.6, StaticFriction: 0.9, DynamicFriction: 0.4, SpinFriction: 0.04}
+
+	combined := CombineMaterials(matA, matB)
+
+	if math.Abs(combined.Restitution-0.4) > 1e-10 {
+		t.Errorf("Restitution = %v, want 0.4", combined.Restitution)
+	}
+	if math.Abs(combined.StaticFriction-0.6) > 1e-10 {
+		t.Errorf("StaticFriction = %v, want 0.6", combined.StaticFriction)
+	}
+	if math.Abs(combined.DynamicFriction-0.2) > 1e-10 {
+		t.Errorf("DynamicFriction = %v, want 0.2", combined.DynamicFriction)
+	}
+	if math.Abs(combined.SpinFriction-0.02) > 1e-10 {
+		t.Errorf("SpinFriction = %v, want 0.02", combined.SpinFriction)
+	}
+	if CombineMaterials(matB, matA) != combined {
+		t.Error("the combination should be symmetric")
+	}
+}
+
+func TestCombineMaterials_Modes(t *testing.T) {
+	tests := []struct {
+		name         string
+		modeA, modeB actor.CombineMode
+		friction     float64
+	}{
+		{"default geometric mean", actor.CombineDefault, actor.CombineDefault, 0.4},
+		{"average", actor.CombineAverage, actor.CombineDefault, 0.5},
+		{"min", actor.CombineMin, actor.CombineDefault, 0.2},
+		{"multiply", actor.CombineMultiply, actor.CombineMultiply, 0.16},
+		{"max", actor.CombineDefault, actor.CombineMax, 0.8},
+		{"highest mode wins", actor.CombineMin, actor.CombineMax, 0.8},
+		{"min over average", actor.CombineAverage, actor.CombineMin, 0.2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matA := actor.Material{StaticFriction: 0.2, DynamicFriction: 0.2, FrictionCombine: tt.modeA}
+			matB := actor.Material{StaticFriction: 0.8, DynamicFriction: 0.8, FrictionCombine: tt.modeB}
+
+			for _, combined := range []CombinedMaterial{CombineMaterials(matA, matB), CombineMaterials(matB, matA)} {
+				if math.Abs(combined.StaticFriction-tt.friction) > 1e-10 || math.Abs(combined.DynamicFriction-tt.friction) > 1e-10 {
+					t.Errorf("frictions = %v, %v, want %v", combined.StaticFriction, combined.DynamicFriction, tt.friction)
+				}
+			}
+		})
+	}
+
+	// The restitution has its own mode, the average by default
+	matA := actor.Material{Restitution: 0.2, StaticFriction: 0.2, FrictionCombine: actor.CombineMax}
+	matB := actor.Material{Restitution: 0.8, StaticFriction: 0.8}
+	if restitution := ComputeRestitution(matA, matB); math.Abs(restitution-0.5) > 1e-10 {
+		t.Errorf("Restitution = %v, want the average 0.5", restitution)
+	}
+	matB.RestitutionCombine = actor.CombineMin
+	if restitution := ComputeRestitution(matA, matB); restitution != 0.2 {
+		t.Errorf("Restitution = %v, want the min 0.2", restitution)
+	}
+}
+
+func TestComputeRestitutionCurve(t *testing.T) {
+	rubber := actor.Material{RestitutionCurve: actor.RestitutionCurve{MinSpeed: 0.2, MaxSpeed: 1}}
+	clay := actor.Material{RestitutionCurve: actor.RestitutionCurve{MinSpeed: 1, MaxSpeed: 4}}
+
+	if curve := ComputeRestitutionCurve(rubber, clay); curve != clay.RestitutionCurve {
+		t.Errorf("curve = %+v, want the highest speeds %+v", curve, clay.RestitutionCurve)
+	}
+	if curve := ComputeRestitutionCurve(rubber, actor.Material{}); curve != rubber.RestitutionCurve {
+		t.Errorf("curve = %+v, want the curve of the only material defining one", curve)
+	}
+}
+
 func TestClampSmallVelocities(t *testing.T) {
 	tests := []struct {
 		name             string
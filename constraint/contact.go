@@ -16,9 +16,58 @@ const (
 	DefaultCompliance = 1e-7
 )
 
+const (
+	// FeatureVertex and FeatureEdge tag the elements of a FeatureID: FeatureVertex|i is the vertex i
+	// of the feature, FeatureEdge|i its edge from the vertex i to the next one
+	FeatureVertex uint8 = 1 << 6
+	FeatureEdge   uint8 = 1 << 7
+)
+
+// FeatureID identifies a contact point by the features of both shapes it lies on, set by epa.GenerateManifold.
+// It stays the same while the shapes touch by the same features, the zero value is an unknown feature
+// (e.g. the points of CollideWithPlane).
+type FeatureID struct {
+	// PartA and PartB are the child of a compound or the triangle of a mesh, 0 for the other shapes
+	PartA, PartB uint32
+	// FaceA and FaceB are the indices of the contact features of the shapes (e.g. the face of a box), see actor.FeatureIndexer
+	FaceA, FaceB uint32
+	// ElementA and ElementB are the vertex or the edge of the feature the point lies on, 0 for its inside
+	ElementA, ElementB uint8
+}
+
+// Swapped returns the identifier seen from the bodies in the other order
+func (f FeatureID) Swapped() FeatureID {
+	return FeatureID{
+		PartA: f.PartB, PartB: f.PartA,
+		FaceA: f.FaceB, FaceB: f.FaceA,
+		ElementA: f.ElementB, ElementB: f.ElementA,
+	}
+}
+
 type ContactPoint struct {
-	Position    mgl64.Vec3
+	Position mgl64.Vec3
+	// Penetration is the depth along the normal, as detected then reduced by the corrections once anchored
 	Penetration float64
+
+	// LocalA and LocalB are Position in the local space of BodyA and BodyB, set by ContactConstraint.Anchor
+	LocalA mgl64.Vec3
+	LocalB mgl64.Vec3
+
+	// NormalImpulse is the impulse (N.s) pushing BodyB along Normal at this point during the substep, BodyA
+	// receiving the opposite: the share of the position correction (SolvePosition) plus the restitution (SolveVelocity).
+	// It bounds the friction, so resting contacts hold against the tangential forces (e.g. a box on a slope).
+	NormalImpulse float64
+	// TangentImpulse is the friction impulse (N.s) accumulated on BodyB at this point, in the contact plane.
+	// The world warm starts it from the point continuing it at the previous substep, anchored at the same places.
+	TangentImpulse mgl64.Vec3
+
+	// Feature identifies the point from one substep to the next, see FeatureID
+	Feature FeatureID
+
+	// depth is the Penetration detected when the point was anchored
+	depth float64
+	// split is the penetration the position passes leave to the next substeps, see SplitPenetration
+	split float64
 }
 
 type ContactConstraint struct {
@@ -26,24 +75,179 @@ type ContactConstraint struct {
 	BodyB  *actor.RigidBody
 	Points []ContactPoint
 	Normal mgl64.Vec3
+
+	// RestitutionThreshold is the approach speed (m/s) under which the restitution is ignored.
+	// Slow contacts (e.g. resting stacks) then cannot gain energy from the bounce of the
+	// velocity accumulated by gravity during a substep. The world sets it to 2*|gravity|*h.
+	RestitutionThreshold float64
+
+	// MaxMassRatio clamps the mass ratio between two dynamic bodies as seen by the solver (0 disables it).
+	// Light bodies squeezed between heavy ones (1:10000) jitter, the heavy body is then solved
+	// as if it was MaxMassRatio times heavier than the light one. Momentum is not exactly conserved.
+	MaxMassRatio float64
+
+	// Material is the combination of the materials of both bodies, computed at each solve if nil.
+	// The world sets it from a cache per pair, recomputed only when a material changes.
+	Material *CombinedMaterial
+
+	// SpinImpulse is the torsional friction impulse (N.m.s) accumulated on BodyB about Normal during the substep,
+	// BodyA receiving the opposite. It is bounded by the spin friction times the normal impulses of the points.
+	SpinImpulse float64
+
+	// Compliance is the inverse stiffness (m/N) of the contact, 0 uses DefaultCompliance.
+	// A soft contact sinks under the load, e.g. mud or cushions, when the multiplier is accumulated (see Accumulator).
+	Compliance float64
+
+	// Sensor marks the overlap of a trigger child of a compound (see actor.CompoundChild.Trigger) found by the
+	// narrow phase: the world records it for the trigger events, it is not solved. The children are the parts
+	// of the Feature of the points.
+	Sensor bool
+
+	// accumulate keeps lambda over the passes of SolvePosition, see BeginSubstep
+	accumulate bool
+	// lambda is the multiplier accumulated since BeginSubstep, negative as the contact only pushes
+	lambda float64
+	// stabilization is the separation (m) of the penetration existing before the substep, whose velocity
+	// is removed by Stabilize, see SplitPenetration
+	stabilization float64
+
+	// anchored is true once the points have their local positions, see Anchor
+	anchored bool
+	// warmStarted is true once SolveVelocity applied the friction impulses of the points, reset by Anchor
+	warmStarted bool
 }
 
-// SolvePosition resolves penetration (PBD style, no lambda accumulation)
+// Anchor records the points in the local space of both bodies, from their current transforms.
+// The solver then re-derives the world positions from the bodies as they move during the substep:
+// the corrections of the other constraints on the same bodies do not leave stale lever arms.
+// The world anchors its contacts after PreSolve, a contact modified later must be anchored again.
+// It starts the accumulation of the impulses of the substep: the normal impulses are reset.
+func (c *ContactConstraint) Anchor() {
+	inverseA := c.BodyA.Transform.Rotation.Conjugate()
+	inverseB := c.BodyB.Transform.Rotation.Conjugate()
+
+	for i := range c.Points {
+		point := &c.Points[i]
+		point.LocalA = inverseA.Rotate(point.Position.Sub(c.BodyA.Transform.Position))
+		point.LocalB = inverseB.Rotate(point.Position.Sub(c.BodyB.Transform.Position))
+		point.depth = point.Penetration
+		point.split = 0
+		point.NormalImpulse = 0
+	}
+	c.anchored = true
+	c.warmStarted = false
+	c.lambda = 0
+	c.stabilization = 0
+	c.SpinImpulse = 0
+}
+
+// SplitPenetration separates the penetration the points had before the substep (split impulse), estimated
+// from the relative velocity of the bodies: the position passes correct the penetration of the approach,
+// and only rate of the existing one beyond slop, whose velocity is then removed by Stabilize.
+// The world calls it after Anchor with World.SplitImpulse.
+func (c *ContactConstraint) SplitPenetration(dt, slop, rate float64) {
+	for i := range c.Points {
+		point := &c.Points[i]
+		velocityA := c.BodyA.Velocity.Add(c.BodyA.AngularVelocity.Cross(point.Position.Sub(c.BodyA.Transform.Position)))
+		velocityB := c.BodyB.Velocity.Add(c.BodyB.AngularVelocity.Cross(point.Position.Sub(c.BodyB.Transform.Position)))
+		approach := math.Max(0, -velocityB.Sub(velocityA).Dot(c.Normal)*dt)
+
+		existing := math.Max(0, point.depth-approach)
+		corrected := rate * math.Max(0, existing-slop)
+		point.split = existing - corrected
+		c.stabilization = math.Max(c.stabilization, corrected)
+	}
+}
+
+// BeginSubstep restarts the accumulation of the multiplier, the world calls it after Anchor (see Accumulator)
+func (c *ContactConstraint) BeginSubstep(accumulate bool) {
+	c.accumulate = accumulate
+	c.lambda = 0
+}
+
+// updatePoints moves the anchored points to the midpoint of their positions on both bodies.
+// Their penetration is the detected one, reduced by the separation of the anchors along the normal:
+// the solver passes after the first one only correct what is left.
+func (c *ContactConstraint) updatePoints() {
+	if !c.anchored {
+		return
+	}
+
+	for i := range c.Points {
+		point := &c.Points[i]
+		onA := c.BodyA.Transform.Position.Add(c.BodyA.Transform.Rotation.Rotate(point.LocalA))
+		onB := c.BodyB.Transform.Position.Add(c.BodyB.Transform.Rotation.Rotate(point.LocalB))
+		point.Position = onA.Add(onB).Mul(0.5)
+		point.Penetration = point.depth - onB.Sub(onA).Dot(c.Normal)
+	}
+}
+
+// MassRatio returns the ratio between the heavier and the lighter of two dynamic bodies.
+// Returns 1 if one of them is static.
+func MassRatio(bodyA, bodyB *actor.RigidBody) float64 {
+	if bodyA.BodyType == actor.BodyTypeStatic || bodyB.BodyType == actor.BodyTypeStatic {
+		return 1
+	}
+
+	massA, massB := bodyA.Material.GetMass(), bodyB.Material.GetMass()
+
+	return math.Max(massA, massB) / math.Min(massA, massB)
+}
+
+// massScales returns the factors applied to the inverse mass and inertia of each body,
+// raising the inverse mass of the heavier body so the ratio does not exceed MaxMassRatio
+func (c *ContactConstraint) massScales(invMassA, invMassB float64) (float64, float64) {
+	if c.MaxMassRatio <= 0 || invMassA == 0 || invMassB == 0 {
+		return 1, 1
+	}
+
+	if invMassA < invMassB && invMassB > invMassA*c.MaxMassRatio {
+		return invMassB / (invMassA * c.MaxMassRatio), 1
+	}
+	if invMassB < invMassA && invMassA > invMassB*c.MaxMassRatio {
+		return 1, invMassA / (invMassB * c.MaxMassRatio)
+	}
+
+	return 1, 1
+}
+
+// lockBodies locks the mutexes of the dynamic bodies of a contact. The static bodies are only read
+// by the solver: the islands resting on the same ground are solved in parallel without waiting on it.
+func lockBodies(bodyA, bodyB *actor.RigidBody) {
+	if bodyA.BodyType != actor.BodyTypeStatic {
+		bodyA.Mutex.Lock()
+	}
+	if bodyB.BodyType != actor.BodyTypeStatic {
+		bodyB.Mutex.Lock()
+	}
+}
+
+// unlockBodies unlocks the mutexes locked by lockBodies
+func unlockBodies(bodyA, bodyB *actor.RigidBody) {
+	if bodyB.BodyType != actor.BodyTypeStatic {
+		bodyB.Mutex.Unlock()
+	}
+	if bodyA.BodyType != actor.BodyTypeStatic {
+		bodyA.Mutex.Unlock()
+	}
+}
+
+// SolvePosition resolves penetration (PBD style), the normal impulses of the points accumulate over the passes
 func (c *ContactConstraint) SolvePosition(dt float64) {
 	if len(c.Points) == 0 {
 		return
 	}
-	if c.BodyA.IsSleeping && c.BodyB.IsSleeping {
+	if !c.BodyA.IsActive() && !c.BodyB.IsActive() {
 		return
 	}
 
 	bodyA := c.BodyA
 	bodyB := c.BodyB
 
-	bodyA.Mutex.Lock()
-	bodyB.Mutex.Lock()
-	defer bodyA.Mutex.Unlock()
-	defer bodyB.Mutex.Unlock()
+	lockBodies(bodyA, bodyB)
+	defer unlockBodies(bodyA, bodyB)
+
+	c.updatePoints()
 
 	// ========== 1. Calculate total effective weight ==========
 	invMassA := 1.0 / bodyA.Material.GetMass()
@@ -51,11 +255,19 @@ func (c *ContactConstraint) SolvePosition(dt float64) {
 	IA_inv := bodyA.GetInverseInertiaWorld()
 	IB_inv := bodyB.GetInverseInertiaWorld()
 
+	if scaleA, scaleB := c.massScales(invMassA, invMassB); scaleA != 1 || scaleB != 1 {
+		invMassA, invMassB = invMassA*scaleA, invMassB*scaleB
+		IA_inv, IB_inv = IA_inv.Mul(scaleA), IB_inv.Mul(scaleB)
+	}
+	if DebugAssertions {
+		c.assertContact(IA_inv, IB_inv)
+	}
+
 	var totalWeight float64
 	var totalPenetration float64
 
 	for _, point := range c.Points {
-		penetration := point.Penetration
+		penetration := point.Penetration - point.split
 		if penetration <= 1e-8 {
 			continue
 		}
@@ -82,12 +294,24 @@ func (c *ContactConstraint) SolvePosition(dt float64) {
 		return
 	}
 
-	compliance := DefaultCompliance
+	compliance := c.Compliance
+	if compliance <= 0 {
+		compliance = DefaultCompliance
+	}
 	alphaTilde := compliance / (dt * dt)
 	deltaLambda := -totalPenetration / (totalWeight + alphaTilde)
+	if c.accumulate {
+		// XPBD: the compliance term of the accumulated multiplier balances the penetration left,
+		// the contact only pushes so the multiplier stays negative
+		deltaLambda = math.Min((-totalPenetration-alphaTilde*c.lambda)/(totalWeight+alphaTilde), -c.lambda)
+		c.lambda += deltaLambda
+	}
 
 	// ========== 3. Apply linear corrections ==========
 	totalImpulse := c.Normal.Mul(deltaLambda)
+	if DebugAssertions {
+		c.assertFinite("position correction", totalImpulse)
+	}
 
 	if bodyA.BodyType != actor.BodyTypeStatic {
 		bodyA.Transform.Position = bodyA.Transform.Position.Add(totalImpulse.Mul(invMassA))
@@ -97,28 +321,41 @@ func (c *ContactConstraint) SolvePosition(dt float64) {
 	}
 
 	// ========== 4. Apply angular corrections ==========
+	// Each point receives the share of totalImpulse of its penetration: applying the whole impulse
+	// at every point multiplied the torque by the points count, and tipped over the offset stacks.
 	// Accumulate torques from all points, then apply ONE SINGLE correction
 	var totalTorqueA, totalTorqueB mgl64.Vec3
 
-	for _, point := range c.Points {
-		if point.Penetration <= 1e-8 {
+	for i := range c.Points {
+		point := &c.Points[i]
+		penetration := point.Penetration - point.split
+		if penetration <= 1e-8 {
 			continue
 		}
 
 		rA := point.Position.Sub(bodyA.Transform.Position)
 		rB := point.Position.Sub(bodyB.Transform.Position)
+		share := penetration / totalPenetration
+		pointImpulse := totalImpulse.Mul(share)
 
 		// Accumulate angular moments
-		// Body A receives +totalImpulse → torque_A = rA × (+totalImpulse)
-		// Body B receives -totalImpulse → torque_B = rB × (-totalImpulse)
-		totalTorqueA = totalTorqueA.Add(rA.Cross(totalImpulse))
-		totalTorqueB = totalTorqueB.Add(rB.Cross(totalImpulse.Mul(-1)))
+		// Body A receives +pointImpulse → torque_A = rA × (+pointImpulse)
+		// Body B receives -pointImpulse → torque_B = rB × (-pointImpulse)
+		totalTorqueA = totalTorqueA.Add(rA.Cross(pointImpulse))
+		totalTorqueB = totalTorqueB.Add(rB.Cross(pointImpulse.Mul(-1)))
+
+		// The position correction over the substep is the impulse lambda/h
+		point.NormalImpulse += -deltaLambda * share / dt
 	}
 
 	// Calculate total angular correction
 	// In XPBD: Δθ = I_inv * (Σ torque)
 	deltaRotA := IA_inv.Mul3x1(totalTorqueA)
 	deltaRotB := IB_inv.Mul3x1(totalTorqueB)
+	if DebugAssertions {
+		c.assertFinite("rotation correction of body A", deltaRotA)
+		c.assertFinite("rotation correction of body B", deltaRotB)
+	}
 
 	// Apply ONE SINGLE rotation correction via quaternions
 	// For a small angle δθ, the rotation quaternion is q_delta ≈ [1, δθ/2]
@@ -137,46 +374,141 @@ func (c *ContactConstraint) SolvePosition(dt float64) {
 	}
 }
 
-// SolveVelocity applies restitution
+// Stabilize removes the separating velocity the position passes gave to the bodies by correcting
+// the penetration existing before the substep (split impulse), see SplitPenetration: a body spawned
+// inside another is pushed out without being launched. The world calls it after the velocities
+// are derived from the positions, before SolveVelocity and its restitution.
+func (c *ContactConstraint) Stabilize(dt float64) {
+	if c.stabilization <= 0 || len(c.Points) == 0 {
+		return
+	}
+	if !c.BodyA.IsActive() && !c.BodyB.IsActive() {
+		return
+	}
+
+	bodyA := c.BodyA
+	bodyB := c.BodyB
+
+	lockBodies(bodyA, bodyB)
+	defer unlockBodies(bodyA, bodyB)
+
+	c.updatePoints()
+
+	var center mgl64.Vec3
+	for _, point := range c.Points {
+		center = center.Add(point.Position)
+	}
+	center = center.Mul(1.0 / float64(len(c.Points)))
+
+	solver := contactSolver{
+		contact:         c,
+		bodyA:           bodyA,
+		bodyB:           bodyB,
+		invMassA:        1.0 / bodyA.Material.GetMass(),
+		invMassB:        1.0 / bodyB.Material.GetMass(),
+		inverseInertiaA: bodyA.GetInverseInertiaWorld(),
+		inverseInertiaB: bodyB.GetInverseInertiaWorld(),
+	}
+	if scaleA, scaleB := c.massScales(solver.invMassA, solver.invMassB); scaleA != 1 || scaleB != 1 {
+		solver.invMassA, solver.invMassB = solver.invMassA*scaleA, solver.invMassB*scaleB
+		solver.inverseInertiaA, solver.inverseInertiaB = solver.inverseInertiaA.Mul(scaleA), solver.inverseInertiaB.Mul(scaleB)
+	}
+
+	// Applied at the center of the manifold: the separation of a resting face is removed without a torque
+	rA := center.Sub(bodyA.Transform.Position)
+	rB := center.Sub(bodyB.Transform.Position)
+	separation := solver.relativeVelocity(rA, rB).Dot(c.Normal)
+	effectiveMass := solver.effectiveMass(rA, rB, c.Normal)
+	if separation <= 0 || effectiveMass < 1e-10 {
+		return
+	}
+
+	removed := math.Min(separation, c.stabilization/dt)
+	solver.add(c.Normal.Mul(-removed/effectiveMass), rA, rB)
+	solver.apply()
+}
+
+// SolveVelocity applies the restitution and the friction, warm started by the friction impulses of the points.
+// The warm start is applied by the first pass after Anchor, the next passes refine the accumulated impulses.
 func (c *ContactConstraint) SolveVelocity(dt float64) {
 	if len(c.Points) == 0 {
 		return
 	}
-	if c.BodyA.IsSleeping && c.BodyB.IsSleeping {
+	if !c.BodyA.IsActive() && !c.BodyB.IsActive() {
 		return
 	}
 
 	bodyA := c.BodyA
 	bodyB := c.BodyB
 
-	bodyA.Mutex.Lock()
-	bodyB.Mutex.Lock()
-	defer bodyA.Mutex.Unlock()
-	defer bodyB.Mutex.Unlock()
+	lockBodies(bodyA, bodyB)
+	defer unlockBodies(bodyA, bodyB)
+
+	c.updatePoints()
 
 	invMassA := 1.0 / bodyA.Material.GetMass()
 	invMassB := 1.0 / bodyB.Material.GetMass()
 	IA_inv := bodyA.GetInverseInertiaWorld()
 	IB_inv := bodyB.GetInverseInertiaWorld()
 
-	restitution := ComputeRestitution(bodyA.Material, bodyB.Material)
-	staticFriction := ComputeStaticFriction(bodyA.Material, bodyB.Material)
-	dynamicFriction := ComputeDynamicFriction(bodyA.Material, bodyB.Material)
+	if scaleA, scaleB := c.massScales(invMassA, invMassB); scaleA != 1 || scaleB != 1 {
+		invMassA, invMassB = invMassA*scaleA, invMassB*scaleB
+		IA_inv, IB_inv = IA_inv.Mul(scaleA), IB_inv.Mul(scaleB)
+	}
+	if DebugAssertions {
+		c.assertContact(IA_inv, IB_inv)
+	}
+
+	var material CombinedMaterial
+	if c.Material != nil {
+		material = *c.Material
+	} else {
+		material = CombineMaterials(bodyA.Material, bodyB.Material)
+	}
+	restitution := material.Restitution
+	restitutionCurve := material.RestitutionCurve
+	staticFriction := material.StaticFriction
+	dynamicFriction := material.DynamicFriction
+
+	solver := contactSolver{
+		contact:         c,
+		bodyA:           bodyA,
+		bodyB:           bodyB,
+		invMassA:        invMassA,
+		invMassB:        invMassB,
+		inverseInertiaA: IA_inv,
+		inverseInertiaB: IB_inv,
+	}
+
+	// ========== WARM START friction ==========
+	// The friction impulses of the previous substep, still within the cone of the current normal impulses
+	if !c.warmStarted {
+		for i := range c.Points {
+			point := &c.Points[i]
+			warm := point.TangentImpulse.Sub(c.Normal.Mul(point.TangentImpulse.Dot(c.Normal)))
+			if maxFriction := staticFriction * point.NormalImpulse; warm.Len() > maxFriction {
+				warm = clampLength(warm, maxFriction)
+			}
+			point.TangentImpulse = warm
+			solver.add(warm, point.Position.Sub(bodyA.Transform.Position), point.Position.Sub(bodyB.Transform.Position))
+		}
+		solver.apply()
+		c.warmStarted = true
+	}
 
 	// ========== ACCUMULATE all impulses ==========
-	var totalLinearImpulseA mgl64.Vec3
-	var totalLinearImpulseB mgl64.Vec3
-	var totalAngularImpulseA mgl64.Vec3
-	var totalAngularImpulseB mgl64.Vec3
+	// The points are solved from the same velocities, each one receiving its share of the correction:
+	// a symmetric manifold stays symmetric, instead of each point cancelling the whole velocity.
+	share := 1.0 / float64(len(c.Points))
+	tangent1, tangent2 := tangentBasis(c.Normal)
 
-	for _, point := range c.Points {
+	for i := range c.Points {
+		point := &c.Points[i]
 		rA := point.Position.Sub(bodyA.Transform.Position)
 		rB := point.Position.Sub(bodyB.Transform.Position)
 
 		// ========== Velocities ==========
-		vA := bodyA.Velocity.Add(bodyA.AngularVelocity.Cross(rA))
-		vB := bodyB.Velocity.Add(bodyB.AngularVelocity.Cross(rB))
-		relativeVel := vB.Sub(vA)
+		relativeVel := solver.relativeVelocity(rA, rB)
 		normalVel := relativeVel.Dot(c.Normal)
 
 		// ========== Pre-resolution velocity ==========
@@ -186,99 +518,183 @@ func (c *ContactConstraint) SolveVelocity(dt float64) {
 		normalVelPrev := relativeVelPrev.Dot(c.Normal)
 
 		// ========== NORMAL IMPULSE (restitution) ==========
-		rA_cross_n := rA.Cross(c.Normal)
-		rB_cross_n := rB.Cross(c.Normal)
-
-		angularInertiaA := IA_inv.Mul3x1(rA_cross_n).Dot(rA_cross_n)
-		angularInertiaB := IB_inv.Mul3x1(rB_cross_n).Dot(rB_cross_n)
-
-		effectiveMassNormal := invMassA + invMassB + angularInertiaA + angularInertiaB
-
+		effectiveMassNormal := solver.effectiveMass(rA, rB, c.Normal)
 		if effectiveMassNormal < 1e-10 {
 			continue
 		}
 
-		// ========== Impulse for this point ==========
-		targetVel := -restitution * normalVelPrev
+		approachSpeed := math.Abs(normalVelPrev)
+		pointRestitution := restitution * restitutionCurve.Scale(approachSpeed)
+		if approachSpeed <= c.RestitutionThreshold {
+			pointRestitution = 0
+		}
+		targetVel := -pointRestitution * normalVelPrev
 		deltaV := targetVel - normalVel
-		lambdaNormal := deltaV / effectiveMassNormal
+		lambdaNormal := share * deltaV / effectiveMassNormal
 
 		// ========== CRITICAL: Prevent attractive impulses ==========
-		if lambdaNormal < 0 {
-			lambdaNormal = 0
+		if lambdaNormal > 0 {
+			solver.add(c.Normal.Mul(lambdaNormal), rA, rB)
+			point.NormalImpulse += lambdaNormal
 		}
 
-		normalImpulse := c.Normal.Mul(lambdaNormal)
+		// ========== TANGENTIAL IMPULSE (friction) ==========
+		// Only if there is a normal force, from the position correction or the restitution
+		if point.NormalImpulse <= 0 {
+			continue
+		}
 
-		// Accumulate normal impulse
-		totalLinearImpulseA = totalLinearImpulseA.Sub(normalImpulse.Mul(invMassA))
-		totalLinearImpulseB = totalLinearImpulseB.Add(normalImpulse.Mul(invMassB))
+		// Tangential velocity (component perpendicular to normal)
+		tangentVel := relativeVel.Sub(c.Normal.Mul(normalVel))
+		if tangentVel.Len() <= 1e-6 {
+			continue
+		}
 
-		torqueA := rA.Cross(normalImpulse.Mul(-1))
-		torqueB := rB.Cross(normalImpulse)
+		// Impulse to cancel tangential velocity, solved in the contact plane: the lever arms couple both tangents,
+		// an impulse along the sliding direction alone would deflect the point on tilted or diagonal contacts
+		tangentMass := solver.planeMass(rA, rB, tangent1, tangent2)
+		if tangentMass.Det() < 1e-20 {
+			continue
+		}
+		lambdaTangent := tangentMass.Inv().Mul2x1(mgl64.Vec2{tangentVel.Dot(tangent1), tangentVel.Dot(tangent2)}).Mul(-share)
+		accumulated := point.TangentImpulse.Add(tangent1.Mul(lambdaTangent.X())).Add(tangent2.Mul(lambdaTangent.Y()))
 
-		totalAngularImpulseA = totalAngularImpulseA.Add(IA_inv.Mul3x1(torqueA))
-		totalAngularImpulseB = totalAngularImpulseB.Add(IB_inv.Mul3x1(torqueB))
+		// Coulomb's law: |F_friction| ≤ μ * |F_normal|
+		// Static friction completely cancels tangential velocity, else it is limited by μ_dynamic
+		if accumulated.Len() > staticFriction*point.NormalImpulse {
+			accumulated = clampLength(tangentVel.Mul(-1), dynamicFriction*point.NormalImpulse)
+		}
 
-		// ========== TANGENTIAL IMPULSE (friction) ==========
-		// Only if there is a normal force
-		if lambdaNormal > 0 {
-			// Tangential velocity (component perpendicular to normal)
-			tangentVel := relativeVel.Sub(c.Normal.Mul(normalVel))
-			tangentSpeed := tangentVel.Len()
+		solver.add(accumulated.Sub(point.TangentImpulse), rA, rB)
+		point.TangentImpulse = accumulated
+	}
 
-			if tangentSpeed > 1e-6 {
-				// Tangential direction
-				tangentDir := tangentVel.Mul(1.0 / tangentSpeed)
+	// ========== APPLY all impulses ==========
+	solver.apply()
+
+	// ========== TORSIONAL FRICTION ==========
+	// A single point (a sphere on the ground) has no tangential velocity when spinning about the normal,
+	// the spin is opposed by an angular impulse bounded by the spin friction times the normal impulses
+	if spinFriction := material.SpinFriction; spinFriction > 0 {
+		solver.solveSpin(spinFriction)
+		solver.apply()
+	}
 
-				// Effective mass in tangential direction
-				rA_cross_t := rA.Cross(tangentDir)
-				rB_cross_t := rB.Cross(tangentDir)
-				angularInertiaA_t := IA_inv.Mul3x1(rA_cross_t).Dot(rA_cross_t)
-				angularInertiaB_t := IB_inv.Mul3x1(rB_cross_t).Dot(rB_cross_t)
+	clampSmallVelocities(bodyA)
+	clampSmallVelocities(bodyB)
+}
 
-				effectiveMassTangent := invMassA + invMassB + angularInertiaA_t + angularInertiaB_t
+// contactSolver accumulates the impulses of the points of a contact, with the masses seen by the solver
+type contactSolver struct {
+	contact                          *ContactConstraint
+	bodyA, bodyB                     *actor.RigidBody
+	invMassA, invMassB               float64
+	inverseInertiaA, inverseInertiaB mgl64.Mat3
 
-				if effectiveMassTangent < 1e-10 {
-					continue
-				}
+	// Velocity changes accumulated by add, applied to the bodies by apply
+	linearA, linearB, angularA, angularB mgl64.Vec3
+}
 
-				// Impulse to cancel tangential velocity
-				lambdaTangent := -tangentSpeed / effectiveMassTangent
+// relativeVelocity returns the velocity of the point of BodyB relative to the point of BodyA
+func (s *contactSolver) relativeVelocity(rA, rB mgl64.Vec3) mgl64.Vec3 {
+	vA := s.bodyA.Velocity.Add(s.bodyA.AngularVelocity.Cross(rA))
+	vB := s.bodyB.Velocity.Add(s.bodyB.AngularVelocity.Cross(rB))
 
-				// Coulomb's law: |F_friction| ≤ μ * |F_normal|
-				maxStaticFriction := staticFriction * math.Abs(lambdaNormal)
+	return vB.Sub(vA)
+}
 
-				var frictionImpulse mgl64.Vec3
+// effectiveMass returns the inverse of the mass opposed to an impulse along direction at the point
+func (s *contactSolver) effectiveMass(rA, rB, direction mgl64.Vec3) float64 {
+	rA_cross_d := rA.Cross(direction)
+	rB_cross_d := rB.Cross(direction)
 
-				if math.Abs(lambdaTangent) <= maxStaticFriction {
-					// Static friction: completely cancels tangential velocity
-					frictionImpulse = tangentDir.Mul(lambdaTangent)
-				} else {
-					// Dynamic friction: limited by μ_dynamic
-					maxDynamicFriction := dynamicFriction * math.Abs(lambdaNormal)
-					frictionImpulse = tangentDir.Mul(-math.Copysign(maxDynamicFriction, tangentSpeed))
-				}
+	return s.invMassA + s.invMassB +
+		s.inverseInertiaA.Mul3x1(rA_cross_d).Dot(rA_cross_d) +
+		s.inverseInertiaB.Mul3x1(rB_cross_d).Dot(rB_cross_d)
+}
 
-				// Accumulate friction impulse
-				totalLinearImpulseA = totalLinearImpulseA.Sub(frictionImpulse.Mul(invMassA))
-				totalLinearImpulseB = totalLinearImpulseB.Add(frictionImpulse.Mul(invMassB))
+// planeMass returns the inverse mass matrix of the point in the plane of tangent1 and tangent2:
+// the velocity change of the point along each tangent, for a unit impulse along each tangent
+func (s *contactSolver) planeMass(rA, rB, tangent1, tangent2 mgl64.Vec3) mgl64.Mat2 {
+	coupling := s.inverseInertiaA.Mul3x1(rA.Cross(tangent1)).Dot(rA.Cross(tangent2)) +
+		s.inverseInertiaB.Mul3x1(rB.Cross(tangent1)).Dot(rB.Cross(tangent2))
 
-				torqueA_friction := rA.Cross(frictionImpulse.Mul(-1))
-				torqueB_friction := rB.Cross(frictionImpulse)
+	return mgl64.Mat2{
+		s.effectiveMass(rA, rB, tangent1), coupling,
+		coupling, s.effectiveMass(rA, rB, tangent2),
+	}
+}
 
-				totalAngularImpulseA = totalAngularImpulseA.Add(IA_inv.Mul3x1(torqueA_friction))
-				totalAngularImpulseB = totalAngularImpulseB.Add(IB_inv.Mul3x1(torqueB_friction))
-			}
-		}
+// solveSpin accumulates the angular impulse about the normal cancelling the relative spin of the bodies,
+// clamped by spinFriction times the normal impulses of the points
+func (s *contactSolver) solveSpin(spinFriction float64) {
+	c := s.contact
+	normal := c.Normal
+	inverseInertia := s.inverseInertiaA.Mul3x1(normal).Dot(normal) + s.inverseInertiaB.Mul3x1(normal).Dot(normal)
+	if inverseInertia < 1e-10 {
+		return
 	}
 
-	// ========== APPLY all impulses ==========
-	bodyA.Velocity = bodyA.Velocity.Add(totalLinearImpulseA)
-	bodyB.Velocity = bodyB.Velocity.Add(totalLinearImpulseB)
-	bodyA.AngularVelocity = bodyA.AngularVelocity.Add(totalAngularImpulseA)
-	bodyB.AngularVelocity = bodyB.AngularVelocity.Add(totalAngularImpulseB)
+	var normalImpulse float64
+	for _, point := range c.Points {
+		normalImpulse += point.NormalImpulse
+	}
+	maxSpin := spinFriction * normalImpulse
+	if maxSpin <= 0 {
+		return
+	}
 
-	clampSmallVelocities(bodyA)
-	clampSmallVelocities(bodyB)
+	spin := s.bodyB.AngularVelocity.Sub(s.bodyA.AngularVelocity).Dot(normal)
+	accumulated := math.Max(-maxSpin, math.Min(maxSpin, c.SpinImpulse-spin/inverseInertia))
+	impulse := normal.Mul(accumulated - c.SpinImpulse)
+	c.SpinImpulse = accumulated
+
+	s.angularA = s.angularA.Sub(s.inverseInertiaA.Mul3x1(impulse))
+	s.angularB = s.angularB.Add(s.inverseInertiaB.Mul3x1(impulse))
+}
+
+// add accumulates impulse on BodyB at rB, and its opposite on BodyA at rA
+func (s *contactSolver) add(impulse, rA, rB mgl64.Vec3) {
+	s.linearA = s.linearA.Sub(impulse.Mul(s.invMassA))
+	s.linearB = s.linearB.Add(impulse.Mul(s.invMassB))
+	s.angularA = s.angularA.Add(s.inverseInertiaA.Mul3x1(rA.Cross(impulse.Mul(-1))))
+	s.angularB = s.angularB.Add(s.inverseInertiaB.Mul3x1(rB.Cross(impulse)))
+}
+
+// apply applies the accumulated velocity changes to the bodies, and resets them
+func (s *contactSolver) apply() {
+	if DebugAssertions {
+		s.contact.assertFinite("linear impulse of body A", s.linearA)
+		s.contact.assertFinite("linear impulse of body B", s.linearB)
+		s.contact.assertFinite("angular impulse of body A", s.angularA)
+		s.contact.assertFinite("angular impulse of body B", s.angularB)
+	}
+
+	s.bodyA.Velocity = s.bodyA.Velocity.Add(s.linearA)
+	s.bodyB.Velocity = s.bodyB.Velocity.Add(s.linearB)
+	s.bodyA.AngularVelocity = s.bodyA.AngularVelocity.Add(s.angularA)
+	s.bodyB.AngularVelocity = s.bodyB.AngularVelocity.Add(s.angularB)
+	s.linearA, s.linearB, s.angularA, s.angularB = mgl64.Vec3{}, mgl64.Vec3{}, mgl64.Vec3{}, mgl64.Vec3{}
+}
+
+// tangentBasis returns two orthonormal directions of the contact plane of normal
+func tangentBasis(normal mgl64.Vec3) (mgl64.Vec3, mgl64.Vec3) {
+	tangent1 := mgl64.Vec3{1, 0, 0}
+	if math.Abs(normal.X()) > 0.9 {
+		tangent1 = mgl64.Vec3{0, 1, 0}
+	}
+
+	tangent1 = tangent1.Sub(normal.Mul(tangent1.Dot(normal))).Normalize()
+	tangent2 := normal.Cross(tangent1).Normalize()
+
+	return tangent1, tangent2
+}
+
+// clampLength scales v down to the given length
+func clampLength(v mgl64.Vec3, length float64) mgl64.Vec3 {
+	if length <= 0 {
+		return mgl64.Vec3{}
+	}
+
+	return v.Mul(length / v.Len())
 }
@@ -402,3 +402,411 @@ func TestContactConstraint_SolveVelocity_SmallVelocityClamping(t *testing.T) {
 		t.Logf("Very small velocity was appropriately handled: %v", bodyA.Velocity)
 	}
 }
+
+func TestContactConstraint_SolveVelocity_RestitutionThreshold(t *testing.T) {
+	solve := func(threshold float64) float64 {
+		ball := createDynamicBody(mgl64.Vec3{0, 1, 0}, mgl64.Vec3{0, -0.2, 0}, 1.0)
+		ball.Material.Restitution = 1.0
+		ground := createStaticBody(mgl64.Vec3{0, -1, 0})
+		ground.Material.Restitution = 1.0
+
+		c := &ContactConstraint{
+			BodyA:                ground,
+			BodyB:                ball,
+			Normal:               mgl64.Vec3{0, 1, 0},
+			Points:               []ContactPoint{{Position: mgl64.Vec3{0, 0, 0}, Penetration: 0.01}},
+			RestitutionThreshold: threshold,
+		}
+		c.SolveVelocity(1.0 / 60.0)
+
+		return ball.Velocity.Y()
+	}
+
+	// Perfect restitution: the ball bounces back at 0.2 m/s
+	if bounce := solve(0); math.Abs(bounce-0.2) > 1e-9 {
+		t.Errorf("velocity without threshold = %v, want 0.2", bounce)
+	}
+	// Under the threshold the contact stops dead
+	if rest := solve(0.5); math.Abs(rest) > 1e-9 {
+		t.Errorf("velocity under threshold = %v, want 0", rest)
+	}
+}
+
+func TestContactConstraint_SolveVelocity_RestitutionCurve(t *testing.T) {
+	solve := func(speed float64) float64 {
+		ball := createDynamicBody(mgl64.Vec3{0, 1, 0}, mgl64.Vec3{0, -speed, 0}, 1.0)
+		ball.Material.Restitution = 1.0
+		ball.Material.RestitutionCurve = actor.RestitutionCurve{MinSpeed: 1, MaxSpeed: 3}
+		ground := createStaticBody(mgl64.Vec3{0, -1, 0})
+		ground.Material.Restitution = 1.0
+
+		c := &ContactConstraint{
+			BodyA:  ground,
+			BodyB:  ball,
+			Normal: mgl64.Vec3{0, 1, 0},
+			Points: []ContactPoint{{Position: mgl64.Vec3{0, 0, 0}, Penetration: 0.01}},
+		}
+		c.SolveVelocity(1.0 / 60.0)
+
+		return ball.Velocity.Y()
+	}
+
+	tests := []struct {
+		speed float64
+		want  float64
+	}{
+		{speed: 0.5, want: 0}, // under MinSpeed, no bounce
+		{speed: 2, want: 1},   // halfway, half the restitution
+		{speed: 5, want: 5},   // above MaxSpeed, the full restitution
+	}
+	for _, tt := range tests {
+		if bounce := solve(tt.speed); math.Abs(bounce-tt.want) > 1e-9 {
+			t.Errorf("speed %v: bounce = %v, want %v", tt.speed, bounce, tt.want)
+		}
+	}
+}
+
+func TestMassRatio(t *testing.T) {
+	light := createDynamicBody(mgl64.Vec3{}, mgl64.Vec3{}, 1.0)
+	heavy := createDynamicBody(mgl64.Vec3{}, mgl64.Vec3{}, 1000.0)
+	ground := createStaticBody(mgl64.Vec3{})
+
+	if ratio := MassRatio(light, heavy); math.Abs(ratio-1000) > 1e-9 {
+		t.Errorf("MassRatio = %v, want 1000", ratio)
+	}
+	if ratio := MassRatio(heavy, light); math.Abs(ratio-1000) > 1e-9 {
+		t.Errorf("MassRatio should not depend on the order, got %v", ratio)
+	}
+	if ratio := MassRatio(light, ground); ratio != 1 {
+		t.Errorf("MassRatio with a static body = %v, want 1", ratio)
+	}
+}
+
+func TestContactConstraint_SolvePosition_MaxMassRatio(t *testing.T) {
+	solve := func(maxMassRatio float64) (float64, float64) {
+		light := createDynamicBody(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{}, 1.0)
+		heavy := createDynamicBody(mgl64.Vec3{1.9, 0, 0}, mgl64.Vec3{}, 10000.0)
+
+		c := &ContactConstraint{
+			BodyA:        light,
+			BodyB:        heavy,
+			Normal:       mgl64.Vec3{1, 0, 0},
+			Points:       []ContactPoint{{Position: mgl64.Vec3{0.95, 0, 0}, Penetration: 0.1}},
+			MaxMassRatio: maxMassRatio,
+		}
+		c.SolvePosition(1.0 / 60.0)
+
+		return math.Abs(light.Transform.Position.X()), math.Abs(heavy.Transform.Position.X() - 1.9)
+	}
+
+	lightMove, heavyMove := solve(0)
+	clampedLightMove, clampedHeavyMove := solve(10)
+
+	if lightMove/heavyMove < 9000 {
+		t.Errorf("unclamped correction ratio = %v, want about 10000", lightMove/heavyMove)
+	}
+	if ratio := clampedLightMove / clampedHeavyMove; math.Abs(ratio-10) > 1e-6 {
+		t.Errorf("clamped correction ratio = %v, want 10", ratio)
+	}
+}
+
+// TestContactConstraint_Anchor moves the bodies after anchoring: the points follow them
+func TestContactConstraint_Anchor(t *testing.T) {
+	bodyA := createDynamicBody(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{}, 1.0)
+	bodyA.Transform.Rotation = mgl64.QuatIdent()
+	bodyB := createStaticBody(mgl64.Vec3{0, 2, 0})
+	bodyB.Transform.Rotation = mgl64.QuatIdent()
+
+	contact := &ContactConstraint{
+		BodyA:  bodyA,
+		BodyB:  bodyB,
+		Normal: mgl64.Vec3{0, 1, 0},
+		Points: []ContactPoint{{Position: mgl64.Vec3{0, 1, 0}, Penetration: 0.1}},
+	}
+
+	// Not anchored: the points are kept as detected
+	bodyA.Transform.Position = mgl64.Vec3{0.2, 0, 0}
+	contact.updatePoints()
+	if position := contact.Points[0].Position; position != (mgl64.Vec3{0, 1, 0}) {
+		t.Fatalf("position = %v, want the detected point without anchors", position)
+	}
+
+	bodyA.Transform.Position = mgl64.Vec3{}
+	contact.Anchor()
+	if local := contact.Points[0].LocalB; !local.ApproxEqual(mgl64.Vec3{0, -1, 0}) {
+		t.Errorf("LocalB = %v, want {0, -1, 0}", local)
+	}
+
+	// A is rotated a quarter turn around Z: its anchor moves to {-1, 0, 0}, B's anchor stays
+	bodyA.Transform.Rotation = mgl64.QuatRotate(math.Pi/2, mgl64.Vec3{0, 0, 1})
+	contact.updatePoints()
+	if position := contact.Points[0].Position; !position.ApproxEqual(mgl64.Vec3{-0.5, 0.5, 0}) {
+		t.Errorf("position = %v, want the midpoint {-0.5, 0.5, 0} of both anchors", position)
+	}
+}
+
+// createRestingContact returns a dynamic sphere resting on a static box, touching it at 4 points around its bottom
+func createRestingContact(penetrations [4]float64) *ContactConstraint {
+	ground := createStaticBody(mgl64.Vec3{0, -2, 0})
+	ground.Transform.Rotation = mgl64.QuatIdent()
+	body := createDynamicBody(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{}, 1.0)
+	body.Transform.Rotation = mgl64.QuatIdent()
+	body.Material.Restitution = 0
+	ground.Material.StaticFriction, ground.Material.DynamicFriction = 0.5, 0.5
+	body.Material.StaticFriction, body.Material.DynamicFriction = 0.5, 0.5
+
+	contact := &ContactConstraint{
+		BodyA:  ground,
+		BodyB:  body,
+		Normal: mgl64.Vec3{0, 1, 0},
+	}
+	for i, corner := range []mgl64.Vec3{{0.5, -1, 0.5}, {-0.5, -1, 0.5}, {-0.5, -1, -0.5}, {0.5, -1, -0.5}} {
+		contact.Points = append(contact.Points, ContactPoint{Position: corner, Penetration: penetrations[i]})
+	}
+
+	return contact
+}
+
+func TestContactConstraint_SolvePosition_NormalImpulse(t *testing.T) {
+	contact := createRestingContact([4]float64{0.01, 0.01, 0.03, 0.03})
+	h := 1.0 / 240.0
+	contact.SolvePosition(h)
+
+	points := contact.Points
+	if points[0].NormalImpulse <= 0 || math.Abs(points[2].NormalImpulse-3*points[0].NormalImpulse) > 1e-9 {
+		t.Errorf("normal impulses %v, %v, want shared by penetration (1:3)", points[0].NormalImpulse, points[2].NormalImpulse)
+	}
+
+	// The impulses over the substep give the displacement of the body
+	var total float64
+	for _, point := range points {
+		total += point.NormalImpulse
+	}
+	displacement := contact.BodyB.Transform.Position.Y()
+	if want := total * h / contact.BodyB.Material.GetMass(); math.Abs(displacement-want) > 1e-9 {
+		t.Errorf("displacement %v, want the normal impulses times h over the mass %v", displacement, want)
+	}
+}
+
+func TestContactConstraint_SolvePosition_Passes(t *testing.T) {
+	contact := createRestingContact([4]float64{0.02, 0.02, 0.02, 0.02})
+	contact.Anchor()
+	h := 1.0 / 240.0
+
+	// The second pass only corrects what the first one left, the penetration is re-derived from the anchors
+	contact.SolvePosition(h)
+	first := contact.BodyB.Transform.Position.Y()
+	impulse := contact.Points[0].NormalImpulse
+	contact.SolvePosition(h)
+	second := contact.BodyB.Transform.Position.Y() - first
+
+	if first <= 0 || second <= 0 || second >= first {
+		t.Errorf("passes moved the body by %v then %v, want a smaller remainder", first, second)
+	}
+	if penetration, want := contact.Points[0].Penetration, 0.02-first; math.Abs(penetration-want) > 1e-9 {
+		t.Errorf("penetration %v seen by the second pass, want %v", penetration, want)
+	}
+	if contact.Points[0].NormalImpulse < impulse {
+		t.Errorf("normal impulse %v, want accumulated over the passes from %v", contact.Points[0].NormalImpulse, impulse)
+	}
+
+	// Anchoring again starts a new substep
+	contact.Anchor()
+	if contact.Points[0].NormalImpulse != 0 {
+		t.Errorf("normal impulse %v after Anchor, want 0", contact.Points[0].NormalImpulse)
+	}
+}
+
+func TestContactConstraint_SolvePosition_Accumulate(t *testing.T) {
+	solve := func(accumulate bool, passes int) float64 {
+		contact := createRestingContact([4]float64{0.02, 0.02, 0.02, 0.02})
+		contact.Compliance = 1e-4
+		contact.Anchor()
+		contact.BeginSubstep(accumulate)
+		for range passes {
+			contact.SolvePosition(1.0 / 240.0)
+		}
+		contact.updatePoints()
+
+		return contact.Points[0].Penetration
+	}
+
+	// XPBD: the passes converge to the penetration balanced by the accumulated multiplier of the soft contact
+	converged := solve(true, 16)
+	if converged < 1e-3 || math.Abs(solve(true, 32)-converged) > 1e-9 {
+		t.Errorf("accumulated penetration %v after 16 passes, want a converged remainder (%v after 32)", converged, solve(true, 32))
+	}
+	if penetration := solve(false, 16); penetration > converged/2 {
+		t.Errorf("penetration %v after 16 passes without accumulation, want the passes to stiffen the contact", penetration)
+	}
+}
+
+func TestContactConstraint_SplitPenetration(t *testing.T) {
+	h := 1.0 / 240.0
+	solve := func(split bool) (*ContactConstraint, float64) {
+		// Resting 0.1m deep: the penetration existed before the substep
+		contact := createRestingContact([4]float64{0.1, 0.1, 0.1, 0.1})
+		contact.Anchor()
+		if split {
+			contact.SplitPenetration(h, 0.02, 0.5)
+		}
+		contact.SolvePosition(h)
+
+		return contact, contact.BodyB.Transform.Position.Y()
+	}
+
+	_, full := solve(false)
+	contact, partial := solve(true)
+	if want := full * 0.5 * (0.1 - 0.02) / 0.1; math.Abs(partial-want) > 1e-9 {
+		t.Errorf("split correction moved the body by %v, want rate of the penetration beyond the slop: %v", partial, want)
+	}
+
+	// The velocity of the correction is removed, the body is not launched
+	contact.BodyB.Velocity = mgl64.Vec3{0, partial / h, 0}
+	contact.Stabilize(h)
+	if velocity := contact.BodyB.Velocity; velocity.Len() > 1e-9 {
+		t.Errorf("velocity %v after Stabilize, want the separation removed", velocity)
+	}
+
+	// A body approaching the ground keeps its penetration in the position passes
+	contact = createRestingContact([4]float64{0.01, 0.01, 0.01, 0.01})
+	contact.BodyB.Velocity = mgl64.Vec3{0, -0.01 / h, 0}
+	contact.Anchor()
+	contact.SplitPenetration(h, 0.02, 0.5)
+	for _, point := range contact.Points {
+		if point.split != 0 {
+			t.Errorf("split %v of an approach penetration, want 0", point.split)
+		}
+	}
+}
+
+func TestContactConstraint_SolvePosition_OffsetTorque(t *testing.T) {
+	// Only the +X side penetrates: the body is pushed up and tilts around Z
+	contact := createRestingContact([4]float64{0.02, 0, 0, 0.02})
+	contact.SolvePosition(1.0 / 240.0)
+
+	// Each point receives its share of the impulse J = m*dy: the torque is 0.5*J around Z (lever arm x = 0.5),
+	// the unit sphere has an inertia of 0.4*m, so the angle is 1.25*dy
+	rotation := contact.BodyB.Transform.Rotation
+	angle := 2 * math.Asin(rotation.V.Z())
+	if want := 1.25 * contact.BodyB.Transform.Position.Y(); math.Abs(angle-want) > 1e-3*want {
+		t.Errorf("rotation angle %v, want %v", angle, want)
+	}
+}
+
+func TestContactConstraint_SolveVelocity_RestingFriction(t *testing.T) {
+	// Without restitution, the friction is bounded by the normal impulse of the position correction
+	contact := createRestingContact([4]float64{0.001, 0.001, 0.001, 0.001})
+	h := 1.0 / 240.0
+	contact.SolvePosition(h)
+	contact.BodyB.Velocity = mgl64.Vec3{0.01, 0, 0}
+	contact.BodyB.PresolveVelocity = contact.BodyB.Velocity
+
+	contact.SolveVelocity(h)
+
+	if speed := contact.BodyB.Velocity.X(); math.Abs(speed) >= 0.01 {
+		t.Errorf("tangential velocity %v, want reduced by the static friction", speed)
+	}
+	for i, point := range contact.Points {
+		if point.TangentImpulse.X() >= 0 || point.TangentImpulse.Y() != 0 {
+			t.Errorf("point %d: tangent impulse %v, want opposed to the sliding, in the contact plane", i, point.TangentImpulse)
+		}
+	}
+}
+
+func TestContactConstraint_SolveVelocity_FrictionCone(t *testing.T) {
+	// One point off both tangent axes: its lever arm couples the impulses along X and Z
+	slide := func(normalImpulse float64) (*ContactConstraint, mgl64.Vec3) {
+		contact := createRestingContact([4]float64{})
+		contact.Points = []ContactPoint{{Position: mgl64.Vec3{0.5, -1, 0.3}, NormalImpulse: normalImpulse}}
+		contact.BodyB.Velocity = mgl64.Vec3{1, 0, 0}
+		contact.BodyB.PresolveVelocity = contact.BodyB.Velocity
+		rB := contact.Points[0].Position.Sub(contact.BodyB.Transform.Position)
+		before := contact.BodyB.Velocity.Add(contact.BodyB.AngularVelocity.Cross(rB))
+
+		contact.SolveVelocity(1.0 / 240.0)
+
+		return contact, before
+	}
+
+	// Within the cone: the coupled solve stops the point, in both tangent directions
+	contact, _ := slide(100)
+	rB := contact.Points[0].Position.Sub(contact.BodyB.Transform.Position)
+	if velocity := contact.BodyB.Velocity.Add(contact.BodyB.AngularVelocity.Cross(rB)); math.Abs(velocity.X()) > 1e-9 || math.Abs(velocity.Z()) > 1e-9 {
+		t.Errorf("point velocity %v, want its tangential velocity stopped by the static friction", velocity)
+	}
+
+	// Sliding: the friction is on the cone, opposed to the sliding velocity of the point
+	contact, before := slide(0.1)
+	impulse := contact.Points[0].TangentImpulse
+	if math.Abs(impulse.Len()-0.05) > 1e-9 || impulse.Normalize().Dot(before.Normalize()) > -1+1e-9 {
+		t.Errorf("tangent impulse %v, want |0.05| opposed to the sliding velocity %v", impulse, before)
+	}
+}
+
+func TestContactConstraint_SolveVelocity_SpinFriction(t *testing.T) {
+	spin := func(normalImpulse float64) (*ContactConstraint, float64) {
+		// A ball on the ground, spinning about the normal on its single contact point
+		contact := createRestingContact([4]float64{})
+		contact.Points = []ContactPoint{{Position: mgl64.Vec3{0, -1, 0}, NormalImpulse: normalImpulse}}
+		contact.Material = &CombinedMaterial{SpinFriction: 0.1}
+		contact.BodyB.AngularVelocity = mgl64.Vec3{0, 5, 0}
+
+		contact.SolveVelocity(1.0 / 240.0)
+
+		return contact, contact.BodyB.AngularVelocity.Y()
+	}
+
+	// The torsional impulse is bounded by the spin friction times the normal impulse
+	contact, slowed := spin(1)
+	inertia := 1.0 / contact.BodyB.GetInverseInertiaWorld().At(1, 1)
+	if want := 5 - 0.1*1/inertia; math.Abs(slowed-want) > 1e-9 || math.Abs(contact.SpinImpulse+0.1) > 1e-9 {
+		t.Errorf("spin %v with impulse %v, want %v slowed by the bounded impulse -0.1", slowed, contact.SpinImpulse, want)
+	}
+
+	// Within the bound, the spin stops
+	if _, stopped := spin(1000); math.Abs(stopped) > 1e-9 {
+		t.Errorf("spin %v, want stopped by the torsional friction", stopped)
+	}
+}
+
+func TestContactConstraint_SolveVelocity_SymmetricManifold(t *testing.T) {
+	contact := createRestingContact([4]float64{0.01, 0.01, 0.01, 0.01})
+	contact.BodyB.Velocity = mgl64.Vec3{0, -1, 0}
+	contact.BodyB.PresolveVelocity = contact.BodyB.Velocity
+
+	contact.SolveVelocity(1.0 / 240.0)
+
+	// The points are solved from the same velocities: a symmetric manifold does not spin the body
+	if spin := contact.BodyB.AngularVelocity.Len(); spin > 1e-12 {
+		t.Errorf("angular velocity %v, want none", contact.BodyB.AngularVelocity)
+	}
+	if vy := contact.BodyB.Velocity.Y(); vy > 1e-9 || vy < -1 {
+		t.Errorf("normal velocity %v, want reduced without bouncing", vy)
+	}
+}
+
+func TestContactConstraint_SolveVelocity_WarmStart(t *testing.T) {
+	h := 1.0 / 240.0
+	cold := createRestingContact([4]float64{0.001, 0.001, 0.001, 0.001})
+	cold.SolvePosition(h)
+	cold.SolveVelocity(h)
+
+	warm := createRestingContact([4]float64{0.001, 0.001, 0.001, 0.001})
+	warm.SolvePosition(h)
+	maxFriction := 0.5 * warm.Points[0].NormalImpulse
+
+	// The previous impulses: one within the friction cone, one outside of it with a normal component
+	warm.Points[0].TangentImpulse = mgl64.Vec3{maxFriction / 2, 0, 0}
+	warm.Points[1].TangentImpulse = mgl64.Vec3{0, 1, 10 * maxFriction}
+	warm.SolveVelocity(h)
+
+	for i, point := range warm.Points {
+		if impulse := point.TangentImpulse; impulse.Len() > maxFriction+1e-12 || impulse.Y() != 0 {
+			t.Errorf("point %d: tangent impulse %v, want in the contact plane within %v", i, impulse, maxFriction)
+		}
+	}
+	if warm.BodyB.Velocity.X() <= cold.BodyB.Velocity.X() || warm.BodyB.Velocity.Z() <= cold.BodyB.Velocity.Z() {
+		t.Errorf("velocity %v, want pushed by the warm started impulses from %v", warm.BodyB.Velocity, cold.BodyB.Velocity)
+	}
+}
@@ -0,0 +1,6 @@
+//go:build featherdebug
+
+package constraint
+
+// DebugAssertions reports whether the solver checks its invariants, enabled by the featherdebug build tag
+const DebugAssertions = true
@@ -0,0 +1,122 @@
+package constraint
+
+import (
+	"math"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// FixedJoint welds two bodies, locking their relative position and rotation: debris glued to a wall,
+// the parts of a breakable structure. With BreakForce or BreakTorque, the joint breaks once its load exceeds them.
+type FixedJoint struct {
+	BodyA *actor.RigidBody
+	BodyB *actor.RigidBody
+
+	// LocalAnchorA and LocalAnchorB are the weld point in the local space of each body
+	LocalAnchorA mgl64.Vec3
+	LocalAnchorB mgl64.Vec3
+	// LocalRotation is the rotation of BodyB in the local space of BodyA
+	LocalRotation mgl64.Quat
+
+	// Compliance is the inverse stiffness (m/N) of the weld, 0 is rigid
+	Compliance float64
+
+	// DisableCollision skips the collisions between the welded bodies, e.g. parts of a structure welded in contact
+	DisableCollision bool
+
+	// JointLoad measures the load held at the weld point, and breaks the weld over its thresholds
+	JointLoad
+
+	// accumulate keeps the multipliers over the passes of SolvePosition, see BeginSubstep
+	accumulate bool
+	// The multipliers of the weld point and of the rotation, accumulated since BeginSubstep
+	positionLambda float64
+	rotationLambda float64
+}
+
+// NewFixedJoint welds the bodies at their current poses, at the anchor in world space
+// (e.g. the contact point of the glued bodies): the loads are measured at this point.
+func NewFixedJoint(bodyA, bodyB *actor.RigidBody, anchor mgl64.Vec3) *FixedJoint {
+	inverseA := bodyA.Transform.Rotation.Conjugate()
+
+	return &FixedJoint{
+		BodyA:         bodyA,
+		BodyB:         bodyB,
+		LocalAnchorA:  inverseA.Rotate(anchor.Sub(bodyA.Transform.Position)),
+		LocalAnchorB:  bodyB.Transform.Rotation.Conjugate().Rotate(anchor.Sub(bodyB.Transform.Position)),
+		LocalRotation: inverseA.Mul(bodyB.Transform.Rotation).Normalize(),
+	}
+}
+
+// Bodies returns the welded bodies, see Connector
+func (c *FixedJoint) Bodies() []*actor.RigidBody {
+	return []*actor.RigidBody{c.BodyA, c.BodyB}
+}
+
+// CollisionDisabled returns DisableCollision, see CollisionDisabler
+func (c *FixedJoint) CollisionDisabled() bool {
+	return c.DisableCollision
+}
+
+// BeginSubstep restarts the accumulation of the multipliers (see Accumulator)
+func (c *FixedJoint) BeginSubstep(accumulate bool) {
+	c.accumulate = accumulate
+	c.positionLambda = 0
+	c.rotationLambda = 0
+}
+
+// SolvePosition rotates BodyB back to its rotation relative to BodyA, then joins the weld points
+func (c *FixedJoint) SolvePosition(dt float64) {
+	bodyA := c.BodyA
+	bodyB := c.BodyB
+	if c.broken || (!bodyA.IsActive() && !bodyB.IsActive()) {
+		return
+	}
+
+	lockBodies(bodyA, bodyB)
+	defer unlockBodies(bodyA, bodyB)
+
+	angle, axis := c.rotationError()
+	pivotA := bodyA.Transform.Position.Add(bodyA.Transform.Rotation.Rotate(c.LocalAnchorA))
+	pivotB := bodyB.Transform.Position.Add(bodyB.Transform.Rotation.Rotate(c.LocalAnchorB))
+	wakeUp(bodyA, bodyB, pivotB.Sub(pivotA).Len(), angle)
+
+	solver := newJointSolver(bodyA, bodyB, c.Compliance, dt, c.accumulate)
+	if !solver.movable() {
+		return
+	}
+
+	solver.correctRotation(axis, angle, &c.rotationLambda)
+
+	rA := bodyA.Transform.Rotation.Rotate(c.LocalAnchorA)
+	rB := bodyB.Transform.Rotation.Rotate(c.LocalAnchorB)
+	delta := bodyB.Transform.Position.Add(rB).Sub(bodyA.Transform.Position.Add(rA))
+	solver.correctPosition(rA, rB, delta, &c.positionLambda)
+}
+
+// SolveVelocity measures the loads of the substep from the multipliers, and breaks the joint over its thresholds.
+// A sleeping structure keeps the loads measured before it fell asleep.
+func (c *FixedJoint) SolveVelocity(dt float64) {
+	if c.broken || (!c.BodyA.IsActive() && !c.BodyB.IsActive()) {
+		return
+	}
+
+	c.measure(c.positionLambda, c.rotationLambda, dt)
+}
+
+// rotationError returns the angle (rad) and the unit axis of the rotation of BodyB away from its welded rotation
+func (c *FixedJoint) rotationError() (float64, mgl64.Vec3) {
+	target := c.BodyA.Transform.Rotation.Mul(c.LocalRotation)
+	difference := c.BodyB.Transform.Rotation.Mul(target.Conjugate())
+	if difference.W < 0 {
+		difference = difference.Scale(-1)
+	}
+
+	sin := difference.V.Len()
+	if sin < 1e-12 {
+		return 0, mgl64.Vec3{}
+	}
+
+	return 2 * math.Atan2(sin, difference.W), difference.V.Mul(1.0 / sin)
+}
@@ -0,0 +1,77 @@
+package constraint
+
+import (
+	"math"
+	"testing"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// createWeld welds a dynamic unit sphere at (0, -1, 0) under a static body, at the top of the sphere
+func createWeld() (*FixedJoint, *actor.RigidBody) {
+	ceiling := createStaticBody(mgl64.Vec3{0, 1, 0})
+	ceiling.Transform.Rotation = mgl64.QuatIdent()
+	ball := createDynamicBody(mgl64.Vec3{0, -1, 0}, mgl64.Vec3{}, 1.0)
+	ball.Transform.Rotation = mgl64.QuatIdent()
+
+	return NewFixedJoint(ceiling, ball, mgl64.Vec3{0, 0, 0}), ball
+}
+
+func TestFixedJoint_SolvePosition(t *testing.T) {
+	c, ball := createWeld()
+
+	ball.Transform.Position = mgl64.Vec3{0.3, -1.2, 0.1}
+	ball.Transform.Rotation = mgl64.QuatRotate(0.4, mgl64.Vec3{1, 1, 0}.Normalize())
+	for range 20 {
+		c.SolvePosition(1.0 / 60.0)
+	}
+
+	if ball.Transform.Position.Sub(mgl64.Vec3{0, -1, 0}).Len() > 1e-3 {
+		t.Errorf("ball at %v, want welded at {0 -1 0}", ball.Transform.Position)
+	}
+	if angle, _ := c.rotationError(); angle > 1e-3 {
+		t.Errorf("ball rotated by %v rad, want welded at the identity", angle)
+	}
+}
+
+// TestFixedJoint_Loads lets the ball fall for a substep: the weld holds its weight, and breaks under it
+func TestFixedJoint_Loads(t *testing.T) {
+	const dt = 1.0 / 240.0
+	const gravity = 9.81
+
+	hold := func(breakForce float64) *FixedJoint {
+		c, ball := createWeld()
+		c.BreakForce = breakForce
+		c.BeginSubstep(false)
+		ball.Transform.Position = ball.Transform.Position.Sub(mgl64.Vec3{0, gravity * dt * dt, 0})
+		c.SolvePosition(dt)
+		c.SolveVelocity(dt)
+
+		return c
+	}
+
+	weight := hold(0).BodyB.Material.GetMass() * gravity
+	c := hold(0)
+	if math.Abs(c.Force-weight) > 1e-6*weight || c.Torque > 1e-9 {
+		t.Errorf("force %v and torque %v, want the weight %v without torque", c.Force, c.Torque, weight)
+	}
+	if c.Broken() {
+		t.Error("joint without BreakForce broken")
+	}
+
+	if c := hold(2 * weight); c.Broken() {
+		t.Errorf("joint broken under %v N, want held up to %v", c.Force, c.BreakForce)
+	}
+
+	c = hold(weight / 2)
+	if !c.Broken() {
+		t.Fatalf("joint held %v N, want broken over %v", c.Force, c.BreakForce)
+	}
+	position := c.BodyB.Transform.Position
+	c.BodyB.Transform.Position = position.Sub(mgl64.Vec3{0, 1, 0})
+	c.SolvePosition(dt)
+	if c.BodyB.Transform.Position != position.Sub(mgl64.Vec3{0, 1, 0}) {
+		t.Errorf("broken joint moved the ball to %v", c.BodyB.Transform.Position)
+	}
+}
@@ -0,0 +1,212 @@
+package constraint
+
+import (
+	"math"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// HingeJoint lets BodyB rotate relative to BodyA about a single axis through a pivot (revolute joint):
+// doors, wheels, pendulums. Hinge to a static body to pin a body to the world.
+// The angle may be limited to [LowerAngle, UpperAngle], and a motor may drive the rotation.
+type HingeJoint struct {
+	BodyA *actor.RigidBody
+	BodyB *actor.RigidBody
+
+	// LocalAnchorA and LocalAnchorB are the pivot in the local space of each body
+	LocalAnchorA mgl64.Vec3
+	LocalAnchorB mgl64.Vec3
+	// LocalAxisA and LocalAxisB are the unit hinge axis in the local space of each body
+	LocalAxisA mgl64.Vec3
+	LocalAxisB mgl64.Vec3
+	// LocalReferenceA and LocalReferenceB are unit vectors orthogonal to the axis, aligned at the angle 0
+	LocalReferenceA mgl64.Vec3
+	LocalReferenceB mgl64.Vec3
+
+	// Compliance is the inverse stiffness (m/N) of the pivot and of the axis, 0 is rigid
+	Compliance float64
+
+	// EnableLimits keeps the angle of BodyB relative to BodyA in [LowerAngle, UpperAngle] (rad, in [-π, π])
+	EnableLimits bool
+	LowerAngle   float64
+	UpperAngle   float64
+
+	// EnableMotor drives the angular velocity of BodyB relative to BodyA toward MotorSpeed (rad/s),
+	// with a torque up to MaxMotorTorque (N.m). A non positive MaxMotorTorque applies no limit.
+	// A motor with a zero speed and a small torque brakes the hinge.
+	EnableMotor    bool
+	MotorSpeed     float64
+	MaxMotorTorque float64
+
+	// MotorImpulse is the angular impulse (N.m.s) of the motor during the last substep
+	MotorImpulse float64
+
+	// DisableCollision skips the collisions between the hinged bodies, e.g. a door overlapping its frame at the hinge
+	DisableCollision bool
+
+	// JointLoad measures the force held by the pivot and the torque held by the axis and the limits,
+	// and breaks the hinge over its thresholds
+	JointLoad
+
+	// accumulate keeps the multipliers over the passes of SolvePosition, see BeginSubstep
+	accumulate bool
+	// The multipliers of the pivot, of the axis and of the limits, accumulated since BeginSubstep
+	positionLambda float64
+	axisLambda     float64
+	limitLambda    float64
+}
+
+// NewHingeJoint hinges the bodies at the pivot about the axis, both in world space at the current poses.
+// The current angle between the bodies is the angle 0.
+func NewHingeJoint(bodyA, bodyB *actor.RigidBody, pivot, axis mgl64.Vec3) *HingeJoint {
+	axis = axis.Normalize()
+	reference, _ := tangentBasis(axis)
+	inverseA := bodyA.Transform.Rotation.Conjugate()
+	inverseB := bodyB.Transform.Rotation.Conjugate()
+
+	return &HingeJoint{
+		BodyA:           bodyA,
+		BodyB:           bodyB,
+		LocalAnchorA:    inverseA.Rotate(pivot.Sub(bodyA.Transform.Position)),
+		LocalAnchorB:    inverseB.Rotate(pivot.Sub(bodyB.Transform.Position)),
+		LocalAxisA:      inverseA.Rotate(axis),
+		LocalAxisB:      inverseB.Rotate(axis),
+		LocalReferenceA: inverseA.Rotate(reference),
+		LocalReferenceB: inverseB.Rotate(reference),
+	}
+}
+
+// SetLimits enables the limits of the angle (rad, in [-π, π]), lower must not exceed upper
+func (c *HingeJoint) SetLimits(lower, upper float64) {
+	c.EnableLimits = true
+	c.LowerAngle = lower
+	c.UpperAngle = upper
+}
+
+// SetMotor enables the motor, driving the hinge at speed (rad/s) with a torque up to maxTorque (N.m)
+func (c *HingeJoint) SetMotor(speed, maxTorque float64) {
+	c.EnableMotor = true
+	c.MotorSpeed = speed
+	c.MaxMotorTorque = maxTorque
+}
+
+// Bodies returns the hinged bodies, see Connector
+func (c *HingeJoint) Bodies() []*actor.RigidBody {
+	return []*actor.RigidBody{c.BodyA, c.BodyB}
+}
+
+// CollisionDisabled returns DisableCollision, see CollisionDisabler
+func (c *HingeJoint) CollisionDisabled() bool {
+	return c.DisableCollision
+}
+
+// BeginSubstep restarts the accumulation of the multipliers and of the motor impulse (see Accumulator)
+func (c *HingeJoint) BeginSubstep(accumulate bool) {
+	c.accumulate = accumulate
+	c.positionLambda = 0
+	c.axisLambda = 0
+	c.limitLambda = 0
+	c.MotorImpulse = 0
+}
+
+// Angle returns the angle (rad, in [-π, π]) of BodyB relative to BodyA about the axis
+func (c *HingeJoint) Angle() float64 {
+	axis := c.BodyA.Transform.Rotation.Rotate(c.LocalAxisA)
+	referenceA := c.BodyA.Transform.Rotation.Rotate(c.LocalReferenceA)
+	referenceB := c.BodyB.Transform.Rotation.Rotate(c.LocalReferenceB)
+
+	return twistAngle(axis, referenceA, referenceB)
+}
+
+// SolvePosition aligns the axes, keeps the angle in the limits, then joins the pivots
+func (c *HingeJoint) SolvePosition(dt float64) {
+	bodyA := c.BodyA
+	bodyB := c.BodyB
+	if c.broken || (!bodyA.IsActive() && !bodyB.IsActive()) {
+		return
+	}
+
+	lockBodies(bodyA, bodyB)
+	defer unlockBodies(bodyA, bodyB)
+
+	axisA := bodyA.Transform.Rotation.Rotate(c.LocalAxisA)
+	axisB := bodyB.Transform.Rotation.Rotate(c.LocalAxisB)
+	swing, swingAxis := swingAngle(axisA, axisB)
+	pivotA := bodyA.Transform.Position.Add(bodyA.Transform.Rotation.Rotate(c.LocalAnchorA))
+	pivotB := bodyB.Transform.Position.Add(bodyB.Transform.Rotation.Rotate(c.LocalAnchorB))
+	wakeUp(bodyA, bodyB, pivotB.Sub(pivotA).Len(), swing)
+
+	solver := newJointSolver(bodyA, bodyB, c.Compliance, dt, c.accumulate)
+	if !solver.movable() {
+		return
+	}
+
+	// 1. The axis of BodyB is rotated back onto the axis of BodyA
+	solver.correctRotation(swingAxis, swing, &c.axisLambda)
+
+	// 2. The limits are rigid, only the violated one is corrected
+	if c.EnableLimits {
+		axis := bodyA.Transform.Rotation.Rotate(c.LocalAxisA)
+		angle := c.Angle()
+		if angle < c.LowerAngle {
+			c.limitLambda += solver.correctRotation(axis, angle-c.LowerAngle, nil)
+		} else if angle > c.UpperAngle {
+			c.limitLambda += solver.correctRotation(axis, angle-c.UpperAngle, nil)
+		}
+	}
+
+	// 3. The pivots are joined, after the rotations which moved them
+	rA := bodyA.Transform.Rotation.Rotate(c.LocalAnchorA)
+	rB := bodyB.Transform.Rotation.Rotate(c.LocalAnchorB)
+	delta := bodyB.Transform.Position.Add(rB).Sub(bodyA.Transform.Position.Add(rA))
+	solver.correctPosition(rA, rB, delta, &c.positionLambda)
+}
+
+// SolveVelocity measures the loads of the substep, then drives the hinge by the motor,
+// its impulse accumulated over the passes is clamped to MaxMotorTorque * dt
+func (c *HingeJoint) SolveVelocity(dt float64) {
+	bodyA := c.BodyA
+	bodyB := c.BodyB
+
+	// A sleeping hinge keeps the loads measured before it fell asleep.
+	// The axis and the limits hold torques about orthogonal directions.
+	if bodyA.IsActive() || bodyB.IsActive() {
+		c.measure(c.positionLambda, math.Hypot(c.axisLambda, c.limitLambda), dt)
+	}
+	if c.broken || !c.EnableMotor {
+		return
+	}
+
+	// A motor set to turn wakes up its bodies
+	if c.MotorSpeed != 0 {
+		if bodyA.IsSleeping {
+			bodyA.WakeUp()
+		}
+		if bodyB.IsSleeping {
+			bodyB.WakeUp()
+		}
+	}
+	if !bodyA.IsActive() && !bodyB.IsActive() {
+		return
+	}
+
+	lockBodies(bodyA, bodyB)
+	defer unlockBodies(bodyA, bodyB)
+
+	solver := newJointSolver(bodyA, bodyB, 0, dt, false)
+	axis := bodyA.Transform.Rotation.Rotate(c.LocalAxisA)
+	weight := solver.inverseInertiaA.Mul3x1(axis).Dot(axis) + solver.inverseInertiaB.Mul3x1(axis).Dot(axis)
+	if weight < 1e-10 {
+		return
+	}
+
+	accumulated := c.MotorImpulse + (c.MotorSpeed-solver.angularVelocity(axis))/weight
+	if c.MaxMotorTorque > 0 {
+		maxImpulse := c.MaxMotorTorque * dt
+		accumulated = math.Max(-maxImpulse, math.Min(maxImpulse, accumulated))
+	}
+
+	solver.applyAngularImpulse(axis, accumulated-c.MotorImpulse)
+	c.MotorImpulse = accumulated
+}
@@ -0,0 +1,120 @@
+package constraint
+
+import (
+	"math"
+	"testing"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// createHinge hinges a dynamic unit sphere hanging at (0, -1, 0) to a static body, about Z through the origin
+func createHinge() (*HingeJoint, *actor.RigidBody) {
+	frame := createStaticBody(mgl64.Vec3{0, 0, 0})
+	frame.Transform.Rotation = mgl64.QuatIdent()
+	bob := createDynamicBody(mgl64.Vec3{0, -1, 0}, mgl64.Vec3{}, 1.0)
+	bob.Transform.Rotation = mgl64.QuatIdent()
+
+	return NewHingeJoint(frame, bob, mgl64.Vec3{0, 0, 0}, mgl64.Vec3{0, 0, 1}), bob
+}
+
+func TestHingeJoint_Angle(t *testing.T) {
+	c, bob := createHinge()
+	if angle := c.Angle(); math.Abs(angle) > 1e-12 {
+		t.Fatalf("Angle = %v at creation, want 0", angle)
+	}
+
+	bob.Transform.Rotation = mgl64.QuatRotate(0.7, mgl64.Vec3{0, 0, 1})
+	if angle := c.Angle(); math.Abs(angle-0.7) > 1e-12 {
+		t.Errorf("Angle = %v, want 0.7", angle)
+	}
+}
+
+func TestHingeJoint_SolvePosition(t *testing.T) {
+	c, bob := createHinge()
+
+	// The bob is pulled away from the pivot and tilted off the axis
+	bob.Transform.Position = mgl64.Vec3{0.2, -1, 0.1}
+	bob.Transform.Rotation = mgl64.QuatRotate(0.3, mgl64.Vec3{1, 0, 0})
+	for range 20 {
+		c.SolvePosition(1.0 / 60.0)
+	}
+
+	pivot := bob.Transform.Position.Add(bob.Transform.Rotation.Rotate(c.LocalAnchorB))
+	if pivot.Len() > 1e-3 {
+		t.Errorf("pivot of the bob at %v, want at the origin", pivot)
+	}
+	axis := bob.Transform.Rotation.Rotate(c.LocalAxisB)
+	if swing, _ := swingAngle(mgl64.Vec3{0, 0, 1}, axis); swing > 1e-3 {
+		t.Errorf("axis of the bob %v is %v rad off Z", axis, swing)
+	}
+}
+
+func TestHingeJoint_Limits(t *testing.T) {
+	c, bob := createHinge()
+	c.SetLimits(-0.5, 0.5)
+
+	// Swung past the upper limit, about the pivot
+	bob.Transform.Rotation = mgl64.QuatRotate(1, mgl64.Vec3{0, 0, 1})
+	bob.Transform.Position = bob.Transform.Rotation.Rotate(mgl64.Vec3{0, -1, 0})
+	for range 20 {
+		c.SolvePosition(1.0 / 60.0)
+	}
+	if angle := c.Angle(); math.Abs(angle-0.5) > 1e-3 {
+		t.Errorf("Angle = %v, want held at the upper limit 0.5", angle)
+	}
+
+	// Inside the limits, the hinge is free
+	bob.Transform.Rotation = mgl64.QuatRotate(-0.2, mgl64.Vec3{0, 0, 1})
+	bob.Transform.Position = bob.Transform.Rotation.Rotate(mgl64.Vec3{0, -1, 0})
+	c.SolvePosition(1.0 / 60.0)
+	if angle := c.Angle(); math.Abs(angle+0.2) > 1e-9 {
+		t.Errorf("Angle = %v, want free at -0.2", angle)
+	}
+}
+
+func TestHingeJoint_Motor(t *testing.T) {
+	dt := 1.0 / 60.0
+	c, bob := createHinge()
+	c.BeginSubstep(false)
+
+	// Without torque limit, the bob reaches the motor speed
+	c.SetMotor(2, 0)
+	c.SolveVelocity(dt)
+	if !bob.AngularVelocity.ApproxEqualThreshold(mgl64.Vec3{0, 0, 2}, 1e-9) {
+		t.Errorf("angular velocity = %v, want {0 0 2}", bob.AngularVelocity)
+	}
+
+	// The torque limit clamps the impulse accumulated over the passes of the substep
+	bob.AngularVelocity = mgl64.Vec3{}
+	c.BeginSubstep(false)
+	c.SetMotor(2, 0.5)
+	c.SolveVelocity(dt)
+	c.SolveVelocity(dt)
+
+	want := bob.GetInverseInertiaWorld().Mul3x1(mgl64.Vec3{0, 0, 0.5 * dt})
+	if !bob.AngularVelocity.ApproxEqualThreshold(want, 1e-9) || math.Abs(c.MotorImpulse-0.5*dt) > 1e-12 {
+		t.Errorf("angular velocity = %v and impulse %v, want %v from MaxMotorTorque*dt", bob.AngularVelocity, c.MotorImpulse, want)
+	}
+}
+
+func TestHingeJoint_WakesSleepingBody(t *testing.T) {
+	door := createDynamicBody(mgl64.Vec3{1, 0, 0}, mgl64.Vec3{}, 1.0)
+	door.Transform.Rotation = mgl64.QuatIdent()
+	frame := createDynamicBody(mgl64.Vec3{-1, 0, 0}, mgl64.Vec3{}, 1.0)
+	frame.Transform.Rotation = mgl64.QuatIdent()
+	c := NewHingeJoint(frame, door, mgl64.Vec3{0, 0, 0}, mgl64.Vec3{0, 1, 0})
+
+	frame.Sleep()
+	door.Transform.Position = door.Transform.Position.Add(mgl64.Vec3{0.001, 0, 0})
+	c.SolvePosition(1.0 / 60.0)
+	if !frame.IsSleeping || frame.Transform.Position != (mgl64.Vec3{-1, 0, 0}) {
+		t.Fatalf("frame sleeping %v at %v, want held asleep under JointWakeDistance", frame.IsSleeping, frame.Transform.Position)
+	}
+
+	door.Transform.Position = door.Transform.Position.Add(mgl64.Vec3{0.5, 0, 0})
+	c.SolvePosition(1.0 / 60.0)
+	if frame.IsSleeping || frame.Transform.Position.X() <= -1 {
+		t.Errorf("frame sleeping %v at %v, want woken up and pulled", frame.IsSleeping, frame.Transform.Position)
+	}
+}
@@ -0,0 +1,227 @@
+package constraint
+
+import (
+	"math"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+const (
+	// JointWakeDistance is the gap (m) between the anchors of a joint that wakes up its sleeping body.
+	// Under it, a sleeping body holds the joint like a static one (e.g. a door hinged to a crate at rest).
+	JointWakeDistance = 0.01
+	// JointWakeAngle is the misalignment (rad) of the axes of a joint that wakes up its sleeping body
+	JointWakeAngle = 0.01
+)
+
+// JointLoad measures the load held by a joint, and breaks it over the thresholds (e.g. a door torn off its hinges,
+// a weld of a breakable structure). It is embedded in the joints, see Breakable.
+type JointLoad struct {
+	// BreakForce (N) and BreakTorque (N.m) break the joint when Force or Torque exceed them, 0 never breaks
+	BreakForce  float64
+	BreakTorque float64
+
+	// Force (N) and Torque (N.m) are the loads held by the joint during the last solved substep
+	Force  float64
+	Torque float64
+
+	broken bool
+	// The impulses (N.s and N.m.s) held during the last solved substep
+	impulse, angularImpulse float64
+}
+
+// Broken returns true once the load exceeded BreakForce or BreakTorque, the joint no longer acts (see Breakable)
+func (l *JointLoad) Broken() bool {
+	return l.broken
+}
+
+// Impulses returns the linear (N.s) and angular (N.m.s) impulses held during the last solved substep,
+// those which broke the joint once Broken
+func (l *JointLoad) Impulses() (float64, float64) {
+	return l.impulse, l.angularImpulse
+}
+
+// measure converts the multipliers of the positions and of the rotations, summed over the passes of the substep,
+// to the loads, and breaks the joint over the thresholds
+func (l *JointLoad) measure(positionLambda, rotationLambda, dt float64) {
+	l.impulse = math.Abs(positionLambda) / dt
+	l.angularImpulse = math.Abs(rotationLambda) / dt
+	l.Force = l.impulse / dt
+	l.Torque = l.angularImpulse / dt
+	if (l.BreakForce > 0 && l.Force > l.BreakForce) || (l.BreakTorque > 0 && l.Torque > l.BreakTorque) {
+		l.broken = true
+	}
+}
+
+// jointSolver corrects the bodies of a joint, with the masses seen by the solver:
+// static and sleeping bodies do not move, they hold the joint.
+type jointSolver struct {
+	bodyA, bodyB                     *actor.RigidBody
+	invMassA, invMassB               float64
+	inverseInertiaA, inverseInertiaB mgl64.Mat3
+
+	// alphaTilde is the compliance of the joint scaled by the substep, accumulate keeps the multipliers
+	alphaTilde float64
+	accumulate bool
+}
+
+// newJointSolver returns the solver of a joint between bodyA and bodyB, for the given compliance (m/N) and substep
+func newJointSolver(bodyA, bodyB *actor.RigidBody, compliance, dt float64, accumulate bool) jointSolver {
+	s := jointSolver{bodyA: bodyA, bodyB: bodyB, alphaTilde: compliance / (dt * dt), accumulate: accumulate}
+	if bodyA.IsActive() {
+		s.invMassA = 1.0 / bodyA.Material.GetMass()
+		s.inverseInertiaA = bodyA.GetInverseInertiaWorld()
+	}
+	if bodyB.IsActive() {
+		s.invMassB = 1.0 / bodyB.Material.GetMass()
+		s.inverseInertiaB = bodyB.GetInverseInertiaWorld()
+	}
+
+	return s
+}
+
+// movable returns false when neither body can move
+func (s *jointSolver) movable() bool {
+	return s.invMassA > 0 || s.invMassB > 0
+}
+
+// wakeUp wakes the sleeping body of a joint whose other body is awake, when the joint drifted
+// by more than JointWakeDistance or JointWakeAngle
+func wakeUp(bodyA, bodyB *actor.RigidBody, distance, angle float64) {
+	if distance <= JointWakeDistance && angle <= JointWakeAngle {
+		return
+	}
+
+	if bodyA.IsSleeping && bodyB.IsActive() {
+		bodyA.WakeUp()
+	} else if bodyB.IsSleeping && bodyA.IsActive() {
+		bodyB.WakeUp()
+	}
+}
+
+// correctPosition moves the point of BodyA at rA and the point of BodyB at rB onto each other,
+// delta being the vector from the first one to the second. The multiplier is accumulated in lambda.
+func (s *jointSolver) correctPosition(rA, rB, delta mgl64.Vec3, lambda *float64) {
+	distance := delta.Len()
+	if distance < 1e-9 {
+		return
+	}
+	normal := delta.Mul(1.0 / distance)
+
+	rACrossN := rA.Cross(normal)
+	rBCrossN := rB.Cross(normal)
+	weight := s.invMassA + s.invMassB +
+		s.inverseInertiaA.Mul3x1(rACrossN).Dot(rACrossN) +
+		s.inverseInertiaB.Mul3x1(rBCrossN).Dot(rBCrossN)
+	if weight < 1e-10 {
+		return
+	}
+
+	deltaLambda := s.deltaLambda(distance, weight, lambda)
+
+	// BodyA moves toward the point of BodyB, BodyB toward the point of BodyA
+	impulse := normal.Mul(-deltaLambda)
+	s.bodyA.Transform.Position = s.bodyA.Transform.Position.Add(impulse.Mul(s.invMassA))
+	s.bodyB.Transform.Position = s.bodyB.Transform.Position.Sub(impulse.Mul(s.invMassB))
+	rotate(s.bodyA, s.inverseInertiaA.Mul3x1(rA.Cross(impulse)))
+	rotate(s.bodyB, s.inverseInertiaB.Mul3x1(rB.Cross(impulse.Mul(-1))))
+}
+
+// correctRotation rotates the bodies to remove the angle (rad) of BodyB relative to BodyA about axis,
+// a unit vector. The multiplier is accumulated in lambda, a nil lambda is a rigid correction (e.g. a limit).
+// It returns the correction of the multiplier, so the rigid ones may be summed for the load.
+func (s *jointSolver) correctRotation(axis mgl64.Vec3, angle float64, lambda *float64) float64 {
+	if math.Abs(angle) < 1e-12 {
+		return 0
+	}
+
+	weight := s.inverseInertiaA.Mul3x1(axis).Dot(axis) + s.inverseInertiaB.Mul3x1(axis).Dot(axis)
+	if weight < 1e-10 {
+		return 0
+	}
+
+	var deltaLambda float64
+	if lambda == nil {
+		deltaLambda = -angle / weight
+	} else {
+		deltaLambda = s.deltaLambda(angle, weight, lambda)
+	}
+
+	impulse := axis.Mul(-deltaLambda)
+	rotate(s.bodyA, s.inverseInertiaA.Mul3x1(impulse))
+	rotate(s.bodyB, s.inverseInertiaB.Mul3x1(impulse.Mul(-1)))
+
+	return deltaLambda
+}
+
+// correctTwist rotates BodyA about axisA and BodyB about axisB, unit vectors, to remove the twist (rad) of BodyB
+// relative to BodyA. A body turning about its own axis keeps the swing between the axes.
+// The correction is rigid, it returns the correction of the multiplier.
+func (s *jointSolver) correctTwist(axisA, axisB mgl64.Vec3, angle float64) float64 {
+	if math.Abs(angle) < 1e-12 {
+		return 0
+	}
+
+	weight := s.inverseInertiaA.Mul3x1(axisA).Dot(axisA) + s.inverseInertiaB.Mul3x1(axisB).Dot(axisB)
+	if weight < 1e-10 {
+		return 0
+	}
+
+	deltaLambda := -angle / weight
+	rotate(s.bodyA, s.inverseInertiaA.Mul3x1(axisA.Mul(-deltaLambda)))
+	rotate(s.bodyB, s.inverseInertiaB.Mul3x1(axisB.Mul(deltaLambda)))
+
+	return deltaLambda
+}
+
+// deltaLambda returns the correction of the multiplier removing the violation, for the generalized inverse mass weight.
+// The corrections are summed in lambda in both modes, lambda/dt² is the force held by the joint.
+func (s *jointSolver) deltaLambda(violation, weight float64, lambda *float64) float64 {
+	deltaLambda := -violation / (weight + s.alphaTilde)
+	if s.accumulate {
+		// XPBD: the violation is balanced by the compliance term of the accumulated multiplier
+		deltaLambda = (-violation - s.alphaTilde**lambda) / (weight + s.alphaTilde)
+	}
+	*lambda += deltaLambda
+
+	return deltaLambda
+}
+
+// angularVelocity returns the angular velocity of BodyB relative to BodyA about axis
+func (s *jointSolver) angularVelocity(axis mgl64.Vec3) float64 {
+	return s.bodyB.AngularVelocity.Sub(s.bodyA.AngularVelocity).Dot(axis)
+}
+
+// applyAngularImpulse applies the angular impulse about axis to BodyB, and its opposite to BodyA
+func (s *jointSolver) applyAngularImpulse(axis mgl64.Vec3, impulse float64) {
+	s.bodyA.AngularVelocity = s.bodyA.AngularVelocity.Sub(s.inverseInertiaA.Mul3x1(axis.Mul(impulse)))
+	s.bodyB.AngularVelocity = s.bodyB.AngularVelocity.Add(s.inverseInertiaB.Mul3x1(axis.Mul(impulse)))
+}
+
+// rotate applies a small rotation (rad, about its direction) to the body
+func rotate(body *actor.RigidBody, deltaRotation mgl64.Vec3) {
+	if deltaRotation.Len() <= 1e-12 {
+		return
+	}
+
+	qDelta := mgl64.Quat{W: 1.0, V: deltaRotation.Mul(0.5)}.Normalize()
+	body.Transform.Rotation = qDelta.Mul(body.Transform.Rotation).Normalize()
+	body.Transform.InverseRotation = body.Transform.Rotation.Inverse()
+}
+
+// swingAngle returns the angle (rad) between the unit vectors a and b, and the unit axis rotating a onto b
+func swingAngle(a, b mgl64.Vec3) (float64, mgl64.Vec3) {
+	cross := a.Cross(b)
+	sin := cross.Len()
+	if sin < 1e-12 {
+		return 0, mgl64.Vec3{}
+	}
+
+	return math.Atan2(sin, a.Dot(b)), cross.Mul(1.0 / sin)
+}
+
+// twistAngle returns the signed angle (rad) about the unit axis from the reference a to the reference b
+func twistAngle(axis, a, b mgl64.Vec3) float64 {
+	return math.Atan2(axis.Dot(a.Cross(b)), a.Dot(b))
+}
@@ -0,0 +1,109 @@
+package constraint
+
+import (
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// AttachmentWakeDistance is the distance (m) between a particle and its anchor that wakes up a sleeping body.
+// Under it, a sleeping body holds the particle like a static one (e.g. a cloth hanging from a crate at rest).
+const AttachmentWakeDistance = 0.01
+
+// ParticleAttachment ties a particle to a point of a body (XPBD, the rest length is 0).
+// The correction is shared by their inverse masses, the body receiving it at the anchor:
+// a cloth pulls on its pole, a rope tied to a crate drags and rotates it.
+type ParticleAttachment struct {
+	Particle *actor.Particle
+	Body     *actor.RigidBody
+	// LocalAnchor is the attachment point in the local space of the body
+	LocalAnchor mgl64.Vec3
+	// Compliance is the inverse stiffness (m/N), 0 is rigid
+	Compliance float64
+
+	// accumulate keeps lambda over the passes of SolvePosition, see BeginSubstep
+	accumulate bool
+	// lambda is the multiplier accumulated since BeginSubstep
+	lambda float64
+}
+
+// NewParticleAttachment attaches the particle to the body at the current position of the particle
+func NewParticleAttachment(particle *actor.Particle, body *actor.RigidBody, compliance float64) *ParticleAttachment {
+	return &ParticleAttachment{
+		Particle:    particle,
+		Body:        body,
+		LocalAnchor: body.Transform.Rotation.Conjugate().Rotate(particle.Position.Sub(body.Transform.Position)),
+		Compliance:  compliance,
+	}
+}
+
+// Anchor returns the attachment point in world space
+func (c *ParticleAttachment) Anchor() mgl64.Vec3 {
+	return c.Body.Transform.Position.Add(c.Body.Transform.Rotation.Rotate(c.LocalAnchor))
+}
+
+// BeginSubstep restarts the accumulation of the multiplier (see Accumulator)
+func (c *ParticleAttachment) BeginSubstep(accumulate bool) {
+	c.accumulate = accumulate
+	c.lambda = 0
+}
+
+// SolvePosition moves the particle and the body toward each other
+func (c *ParticleAttachment) SolvePosition(dt float64) {
+	body := c.Body
+	body.Mutex.Lock()
+	defer body.Mutex.Unlock()
+
+	anchor := c.Anchor()
+	delta := c.Particle.Position.Sub(anchor)
+	distance := delta.Len()
+	if distance < 1e-9 {
+		return
+	}
+	normal := delta.Mul(1.0 / distance)
+
+	if body.IsSleeping && distance > AttachmentWakeDistance {
+		body.WakeUp()
+	}
+
+	// Generalized inverse masses: the body resists by its mass and by its inertia around the anchor
+	wParticle := c.Particle.InverseMass()
+	var wBody, invMassBody float64
+	var invInertia mgl64.Mat3
+	r := anchor.Sub(body.Transform.Position)
+	if body.IsActive() {
+		invMassBody = 1.0 / body.Material.GetMass()
+		invInertia = body.GetInverseInertiaWorld()
+		rCrossN := r.Cross(normal)
+		wBody = invMassBody + invInertia.Mul3x1(rCrossN).Dot(rCrossN)
+	}
+
+	alphaTilde := c.Compliance / (dt * dt)
+	weight := wParticle + wBody + alphaTilde
+	if weight < 1e-10 {
+		return
+	}
+
+	// The particle moves toward the anchor, the body toward the particle
+	deltaLambda := -distance / weight
+	if c.accumulate {
+		// XPBD: the stretch is balanced by the compliance term of the accumulated multiplier
+		deltaLambda = (-distance - alphaTilde*c.lambda) / weight
+		c.lambda += deltaLambda
+	}
+	impulse := normal.Mul(deltaLambda)
+	c.Particle.Position = c.Particle.Position.Add(impulse.Mul(wParticle))
+	if wBody == 0 {
+		return
+	}
+
+	body.Transform.Position = body.Transform.Position.Sub(impulse.Mul(invMassBody))
+	deltaRotation := invInertia.Mul3x1(r.Cross(impulse.Mul(-1)))
+	if deltaRotation.Len() > 1e-10 {
+		qDelta := mgl64.Quat{W: 1.0, V: deltaRotation.Mul(0.5)}.Normalize()
+		body.Transform.Rotation = qDelta.Mul(body.Transform.Rotation).Normalize()
+		body.Transform.InverseRotation = body.Transform.Rotation.Inverse()
+	}
+}
+
+// SolveVelocity does nothing, the velocities are derived from the corrected positions
+func (c *ParticleAttachment) SolveVelocity(dt float64) {}
@@ -0,0 +1,149 @@
+package constraint
+
+import (
+	"math"
+	"testing"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestParticleAttachment_ConservesMomentum(t *testing.T) {
+	body := createDynamicBody(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{}, 1.0)
+	body.Transform.Rotation = mgl64.QuatIdent()
+	particle := actor.NewParticle(mgl64.Vec3{1, 0, 0}, 0.5)
+	c := NewParticleAttachment(particle, body, 0)
+
+	// The particle is pulled 0.3m away along the lever arm: no torque, the correction is shared by the masses
+	particle.Position = mgl64.Vec3{1.3, 0, 0}
+	center := body.Transform.Position.Mul(body.Material.GetMass()).Add(particle.Position.Mul(particle.Mass))
+
+	c.SolvePosition(1.0 / 60.0)
+
+	if distance := particle.Position.Sub(c.Anchor()).Len(); distance > 1e-9 {
+		t.Errorf("particle %v is %v away from the anchor", particle.Position, distance)
+	}
+	after := body.Transform.Position.Mul(body.Material.GetMass()).Add(particle.Position.Mul(particle.Mass))
+	if !after.ApproxEqualThreshold(center, 1e-9) {
+		t.Errorf("weighted center = %v, want %v", after, center)
+	}
+	if body.Transform.Position.X() <= 0 {
+		t.Errorf("body %v not pulled toward the particle", body.Transform.Position)
+	}
+}
+
+func TestParticleAttachment_RotatesBody(t *testing.T) {
+	body := createDynamicBody(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{}, 1.0)
+	body.Transform.Rotation = mgl64.QuatIdent()
+	particle := actor.NewParticle(mgl64.Vec3{1, 0, 0}, 10)
+	c := NewParticleAttachment(particle, body, 0)
+
+	// Pulled sideways at the anchor: the body turns around Z
+	particle.Position = mgl64.Vec3{1, 0.2, 0}
+	c.SolvePosition(1.0 / 60.0)
+
+	axis := body.Transform.Rotation.V
+	if axis.Z() <= 0 || math.Abs(axis.X()) > 1e-9 || math.Abs(axis.Y()) > 1e-9 {
+		t.Errorf("rotation = %v, want a positive rotation around Z", body.Transform.Rotation)
+	}
+}
+
+func TestParticleAttachment_StaticBody(t *testing.T) {
+	body := createStaticBody(mgl64.Vec3{0, 2, 0})
+	body.Transform.Rotation = mgl64.QuatIdent()
+	particle := actor.NewParticle(mgl64.Vec3{0, 1, 0}, 1)
+	c := NewParticleAttachment(particle, body, 0)
+
+	particle.Position = mgl64.Vec3{0, 0.5, 0}
+	c.SolvePosition(1.0 / 60.0)
+
+	if !particle.Position.ApproxEqualThreshold(mgl64.Vec3{0, 1, 0}, 1e-9) {
+		t.Errorf("particle = %v, want back at the anchor", particle.Position)
+	}
+	if body.Transform.Position != (mgl64.Vec3{0, 2, 0}) {
+		t.Errorf("static body moved to %v", body.Transform.Position)
+	}
+}
+
+func TestParticleAttachment_PinnedParticle(t *testing.T) {
+	body := createDynamicBody(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{}, 1.0)
+	body.Transform.Rotation = mgl64.QuatIdent()
+	particle := actor.NewParticle(mgl64.Vec3{0, 1, 0}, 0)
+	c := NewParticleAttachment(particle, body, 0)
+
+	// A pinned particle is moved by the user, the body follows it
+	particle.Position = mgl64.Vec3{0, 1.5, 0}
+	c.SolvePosition(1.0 / 60.0)
+
+	if particle.Position != (mgl64.Vec3{0, 1.5, 0}) {
+		t.Errorf("pinned particle moved to %v", particle.Position)
+	}
+	if !body.Transform.Position.ApproxEqualThreshold(mgl64.Vec3{0, 0.5, 0}, 1e-9) {
+		t.Errorf("body = %v, want dragged to {0 0.5 0}", body.Transform.Position)
+	}
+}
+
+func TestParticleAttachment_Compliance(t *testing.T) {
+	body := createStaticBody(mgl64.Vec3{0, 0, 0})
+	body.Transform.Rotation = mgl64.QuatIdent()
+	particle := actor.NewParticle(mgl64.Vec3{0, 0, 0}, 1)
+	c := NewParticleAttachment(particle, body, 1e-4)
+
+	dt := 0.01
+	particle.Position = mgl64.Vec3{1, 0, 0}
+	c.SolvePosition(dt)
+
+	// XPBD: the particle moves by w / (w + compliance/dt²) of the error
+	expected := 1 - 1/(1+1e-4/(dt*dt))
+	if math.Abs(particle.Position.X()-expected) > 1e-9 {
+		t.Errorf("particle = %v, want x = %v", particle.Position, expected)
+	}
+}
+
+func TestParticleAttachment_AccumulatedCompliance(t *testing.T) {
+	solve := func(accumulate bool) float64 {
+		body := createStaticBody(mgl64.Vec3{0, 0, 0})
+		body.Transform.Rotation = mgl64.QuatIdent()
+		particle := actor.NewParticle(mgl64.Vec3{0, 0, 0}, 1)
+		c := NewParticleAttachment(particle, body, 1e-4)
+
+		particle.Position = mgl64.Vec3{1, 0, 0}
+		c.BeginSubstep(accumulate)
+		for range 4 {
+			c.SolvePosition(0.01)
+		}
+
+		return particle.Position.X()
+	}
+
+	// XPBD: the passes after the first one are balanced by the accumulated multiplier
+	expected := 1 - 1/(1+1e-4/(0.01*0.01))
+	if x := solve(true); math.Abs(x-expected) > 1e-9 {
+		t.Errorf("accumulated: particle x = %v after 4 passes, want %v as after 1", x, expected)
+	}
+	if x := solve(false); x > expected/4 {
+		t.Errorf("not accumulated: particle x = %v after 4 passes, want each pass to halve the stretch", x)
+	}
+}
+
+func TestParticleAttachment_WakesSleepingBody(t *testing.T) {
+	body := createDynamicBody(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{}, 1.0)
+	body.Transform.Rotation = mgl64.QuatIdent()
+	particle := actor.NewParticle(mgl64.Vec3{0, -1, 0}, 1)
+	c := NewParticleAttachment(particle, body, 0)
+	body.Sleep()
+
+	// Sagging under the wake distance: the sleeping body holds the particle
+	particle.Position = mgl64.Vec3{0, -1 - AttachmentWakeDistance/2, 0}
+	c.SolvePosition(1.0 / 60.0)
+	if !body.IsSleeping || body.Transform.Position != (mgl64.Vec3{}) {
+		t.Errorf("sleeping body disturbed: sleeping %v at %v", body.IsSleeping, body.Transform.Position)
+	}
+
+	// Yanked: the body wakes up and follows
+	particle.Position = mgl64.Vec3{0, -2, 0}
+	c.SolvePosition(1.0 / 60.0)
+	if body.IsSleeping || body.Transform.Position.Y() >= 0 {
+		t.Errorf("body not woken up: sleeping %v at %v", body.IsSleeping, body.Transform.Position)
+	}
+}
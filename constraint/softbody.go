@@ -0,0 +1,285 @@
+package constraint
+
+import (
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// ParticleContactWakeDepth (m) and ParticleContactWakeSpeed (m/s) are the penetration of a particle into a sleeping body,
+// and the speed at which it hits it, that wake the body up. Under both, a sleeping body holds the particles like a static one
+// (e.g. a soft body resting on a crate).
+const (
+	ParticleContactWakeDepth = 0.01
+	ParticleContactWakeSpeed = 0.5
+)
+
+// ParticleDistance keeps two particles at their rest length (XPBD): the edges of a soft body or of a cloth,
+// and their bending when it joins the opposite vertices of two adjacent triangles.
+type ParticleDistance struct {
+	ParticleA *actor.Particle
+	ParticleB *actor.Particle
+	// RestLength is the distance (m) kept between the particles
+	RestLength float64
+	// Compliance is the inverse stiffness (m/N), 0 is rigid
+	Compliance float64
+
+	// accumulate keeps lambda over the passes of SolvePosition, see BeginSubstep
+	accumulate bool
+	// lambda is the multiplier accumulated since BeginSubstep
+	lambda float64
+}
+
+// NewParticleDistance keeps the particles at their current distance
+func NewParticleDistance(particleA, particleB *actor.Particle, compliance float64) *ParticleDistance {
+	return &ParticleDistance{
+		ParticleA:  particleA,
+		ParticleB:  particleB,
+		RestLength: particleB.Position.Sub(particleA.Position).Len(),
+		Compliance: compliance,
+	}
+}
+
+// BeginSubstep restarts the accumulation of the multiplier (see Accumulator)
+func (c *ParticleDistance) BeginSubstep(accumulate bool) {
+	c.accumulate = accumulate
+	c.lambda = 0
+}
+
+// SolvePosition moves the particles along their axis, back to the rest length
+func (c *ParticleDistance) SolvePosition(dt float64) {
+	delta := c.ParticleB.Position.Sub(c.ParticleA.Position)
+	distance := delta.Len()
+	if distance < 1e-9 {
+		return
+	}
+	normal := delta.Mul(1.0 / distance)
+
+	wA := c.ParticleA.InverseMass()
+	wB := c.ParticleB.InverseMass()
+	alphaTilde := c.Compliance / (dt * dt)
+	weight := wA + wB + alphaTilde
+	if wA+wB < 1e-10 {
+		return
+	}
+
+	stretch := distance - c.RestLength
+	deltaLambda := -stretch / weight
+	if c.accumulate {
+		deltaLambda = (-stretch - alphaTilde*c.lambda) / weight
+		c.lambda += deltaLambda
+	}
+	c.ParticleA.Position = c.ParticleA.Position.Sub(normal.Mul(deltaLambda * wA))
+	c.ParticleB.Position = c.ParticleB.Position.Add(normal.Mul(deltaLambda * wB))
+}
+
+// SolveVelocity does nothing, the velocities are derived from the corrected positions
+func (c *ParticleDistance) SolveVelocity(dt float64) {}
+
+// ParticleVolume keeps the volume enclosed by a closed triangle mesh of particles (XPBD), e.g. the surface
+// of a soft body: squeezed on one side, it inflates on the others.
+type ParticleVolume struct {
+	Particles []*actor.Particle
+	// Triangles index Particles, wound counterclockwise seen from the outside
+	Triangles [][3]int
+	// RestVolume (m³) is the volume at creation, the constraint keeps Pressure * RestVolume
+	RestVolume float64
+	// Pressure scales the kept volume: above 1 the soft body inflates, under 1 it deflates
+	Pressure float64
+	// Compliance is the inverse stiffness of the volume, 0 is rigid
+	Compliance float64
+
+	// accumulate keeps lambda over the passes of SolvePosition, see BeginSubstep
+	accumulate bool
+	// lambda is the multiplier accumulated since BeginSubstep
+	lambda float64
+	// gradients of the volume for each particle, reused by the passes
+	gradients []mgl64.Vec3
+}
+
+// NewParticleVolume keeps the current volume of the mesh, at the pressure 1
+func NewParticleVolume(particles []*actor.Particle, triangles [][3]int, compliance float64) *ParticleVolume {
+	c := &ParticleVolume{
+		Particles:  particles,
+		Triangles:  triangles,
+		Pressure:   1,
+		Compliance: compliance,
+		gradients:  make([]mgl64.Vec3, len(particles)),
+	}
+	c.RestVolume = c.Volume()
+
+	return c
+}
+
+// Volume returns the volume (m³) enclosed by the triangles, negative when they are wound inward
+func (c *ParticleVolume) Volume() float64 {
+	volume := 0.0
+	for _, triangle := range c.Triangles {
+		p0 := c.Particles[triangle[0]].Position
+		p1 := c.Particles[triangle[1]].Position
+		p2 := c.Particles[triangle[2]].Position
+		volume += p0.Dot(p1.Cross(p2))
+	}
+
+	return volume / 6
+}
+
+// BeginSubstep restarts the accumulation of the multiplier (see Accumulator)
+func (c *ParticleVolume) BeginSubstep(accumulate bool) {
+	c.accumulate = accumulate
+	c.lambda = 0
+}
+
+// SolvePosition moves the particles along the normals of the surface, back to the kept volume
+func (c *ParticleVolume) SolvePosition(dt float64) {
+	if len(c.gradients) != len(c.Particles) {
+		c.gradients = make([]mgl64.Vec3, len(c.Particles))
+	}
+	clear(c.gradients)
+
+	// The gradient of the volume for a particle is the sum of the cross products of the opposite edges
+	for _, triangle := range c.Triangles {
+		p0 := c.Particles[triangle[0]].Position
+		p1 := c.Particles[triangle[1]].Position
+		p2 := c.Particles[triangle[2]].Position
+		c.gradients[triangle[0]] = c.gradients[triangle[0]].Add(p1.Cross(p2).Mul(1.0 / 6))
+		c.gradients[triangle[1]] = c.gradients[triangle[1]].Add(p2.Cross(p0).Mul(1.0 / 6))
+		c.gradients[triangle[2]] = c.gradients[triangle[2]].Add(p0.Cross(p1).Mul(1.0 / 6))
+	}
+
+	weight := 0.0
+	for i, particle := range c.Particles {
+		weight += particle.InverseMass() * c.gradients[i].LenSqr()
+	}
+	if weight < 1e-12 {
+		return
+	}
+
+	alphaTilde := c.Compliance / (dt * dt)
+	violation := c.Volume() - c.Pressure*c.RestVolume
+	deltaLambda := -violation / (weight + alphaTilde)
+	if c.accumulate {
+		deltaLambda = (-violation - alphaTilde*c.lambda) / (weight + alphaTilde)
+		c.lambda += deltaLambda
+	}
+	for i, particle := range c.Particles {
+		particle.Position = particle.Position.Add(c.gradients[i].Mul(deltaLambda * particle.InverseMass()))
+	}
+}
+
+// SolveVelocity does nothing, the velocities are derived from the corrected positions
+func (c *ParticleVolume) SolveVelocity(dt float64) {}
+
+// ParticleContact pushes a particle of radius Radius out of a body, along Normal, detected by the narrow phase
+// during the substep. The correction is shared by their inverse masses, the body receiving it at the contact:
+// a soft body pushes the crates it lands on.
+type ParticleContact struct {
+	Particle *actor.Particle
+	Body     *actor.RigidBody
+	// Normal is the unit normal of the surface of the body, toward the particle
+	Normal mgl64.Vec3
+	// LocalAnchor is the contact point on the surface of the body, in its local space
+	LocalAnchor mgl64.Vec3
+	// Radius (m) of the particle, kept from the surface
+	Radius float64
+	// Friction is the coefficient of the particle sliding on the body: the tangential motion of the substep
+	// is removed while it is under Friction times the normal correction
+	Friction float64
+	// OneWay pushes the particle alone, the body is neither moved nor woken up, as if it were static (e.g. a cloth)
+	OneWay bool
+}
+
+// NewParticleContact creates the contact of the particle touching the body at point, on the surface of the body
+func NewParticleContact(particle *actor.Particle, body *actor.RigidBody, point, normal mgl64.Vec3, radius, friction float64) *ParticleContact {
+	return &ParticleContact{
+		Particle:    particle,
+		Body:        body,
+		Normal:      normal,
+		LocalAnchor: body.Transform.Rotation.Conjugate().Rotate(point.Sub(body.Transform.Position)),
+		Radius:      radius,
+		Friction:    friction,
+	}
+}
+
+// SolvePosition pushes the particle out of the body along the normal, then removes their relative sliding
+func (c *ParticleContact) SolvePosition(dt float64) {
+	body := c.Body
+	body.Mutex.Lock()
+	defer body.Mutex.Unlock()
+
+	r := body.Transform.Rotation.Rotate(c.LocalAnchor)
+	anchor := body.Transform.Position.Add(r)
+	penetration := c.Radius - c.Particle.Position.Sub(anchor).Dot(c.Normal)
+	if penetration <= 0 {
+		return
+	}
+	if body.IsSleeping && !c.OneWay {
+		speed := c.Particle.PreviousPosition.Sub(c.Particle.Position).Dot(c.Normal) / dt
+		if penetration > ParticleContactWakeDepth || speed > ParticleContactWakeSpeed {
+			body.WakeUp()
+		}
+	}
+
+	wParticle := c.Particle.InverseMass()
+	var invMassBody float64
+	var invInertia mgl64.Mat3
+	moving := body.IsActive() && !c.OneWay
+	if moving {
+		invMassBody = 1.0 / body.Material.GetMass()
+		invInertia = body.GetInverseInertiaWorld()
+	}
+	weight := func(direction mgl64.Vec3) float64 {
+		rCrossD := r.Cross(direction)
+		return wParticle + invMassBody + invInertia.Mul3x1(rCrossD).Dot(rCrossD)
+	}
+
+	normalWeight := weight(c.Normal)
+	if normalWeight < 1e-10 {
+		return
+	}
+	normalCorrection := penetration / normalWeight
+	c.push(c.Normal.Mul(normalCorrection), r, wParticle, invMassBody, invInertia)
+
+	// The tangential motion of the particle relative to the contact point during the substep
+	previousAnchor := anchor
+	if moving {
+		previousAnchor = body.PreviousTransform.Position.Add(body.PreviousTransform.Rotation.Rotate(c.LocalAnchor))
+	}
+	motion := c.Particle.Position.Sub(c.Particle.PreviousPosition).Sub(anchor.Sub(previousAnchor))
+	tangent := motion.Sub(c.Normal.Mul(motion.Dot(c.Normal)))
+	sliding := tangent.Len()
+	if sliding < 1e-9 {
+		return
+	}
+	direction := tangent.Mul(1.0 / sliding)
+	tangentCorrection := sliding / weight(direction)
+	if maxCorrection := c.Friction * normalCorrection; tangentCorrection > maxCorrection {
+		tangentCorrection = maxCorrection
+	}
+	c.push(direction.Mul(-tangentCorrection), r, wParticle, invMassBody, invInertia)
+}
+
+// push moves the particle by impulse times its inverse mass, and the body by the opposite at r
+func (c *ParticleContact) push(impulse, r mgl64.Vec3, wParticle, invMassBody float64, invInertia mgl64.Mat3) {
+	c.Particle.Position = c.Particle.Position.Add(impulse.Mul(wParticle))
+	if invMassBody == 0 {
+		return
+	}
+
+	c.Body.Transform.Position = c.Body.Transform.Position.Sub(impulse.Mul(invMassBody))
+	deltaRotation := invInertia.Mul3x1(r.Cross(impulse.Mul(-1)))
+	if deltaRotation.Len() > 1e-10 {
+		qDelta := mgl64.Quat{W: 1.0, V: deltaRotation.Mul(0.5)}.Normalize()
+		c.Body.Transform.Rotation = qDelta.Mul(c.Body.Transform.Rotation).Normalize()
+		c.Body.Transform.InverseRotation = c.Body.Transform.Rotation.Inverse()
+	}
+}
+
+// SolveVelocity does nothing, the velocities are derived from the corrected positions
+func (c *ParticleContact) SolveVelocity(dt float64) {}
+
+// Penetration returns the depth (m) of the particle into the body, negative once separated
+func (c *ParticleContact) Penetration() float64 {
+	anchor := c.Body.Transform.Position.Add(c.Body.Transform.Rotation.Rotate(c.LocalAnchor))
+
+	return c.Radius - c.Particle.Position.Sub(anchor).Dot(c.Normal)
+}
@@ -0,0 +1,110 @@
+package constraint
+
+import (
+	"math"
+	"testing"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// tetrahedron returns the particles and the outward triangles of a tetrahedron of volume 1/6
+func tetrahedron() ([]*actor.Particle, [][3]int) {
+	particles := []*actor.Particle{
+		actor.NewParticle(mgl64.Vec3{0, 0, 0}, 1),
+		actor.NewParticle(mgl64.Vec3{1, 0, 0}, 1),
+		actor.NewParticle(mgl64.Vec3{0, 1, 0}, 1),
+		actor.NewParticle(mgl64.Vec3{0, 0, 1}, 1),
+	}
+
+	return particles, [][3]int{{0, 2, 1}, {0, 1, 3}, {0, 3, 2}, {1, 2, 3}}
+}
+
+func TestParticleDistance_SolvePosition(t *testing.T) {
+	particleA := actor.NewParticle(mgl64.Vec3{0, 0, 0}, 1)
+	particleB := actor.NewParticle(mgl64.Vec3{1, 0, 0}, 3)
+	c := NewParticleDistance(particleA, particleB, 0)
+
+	// Stretched by 0.4m, the lighter particle takes 3/4 of the correction
+	particleB.Position = mgl64.Vec3{1.4, 0, 0}
+	c.SolvePosition(1.0 / 60.0)
+
+	if length := particleB.Position.Sub(particleA.Position).Len(); math.Abs(length-1) > 1e-9 {
+		t.Errorf("length = %v, want the rest length 1", length)
+	}
+	if math.Abs(particleA.Position.X()-0.3) > 1e-9 {
+		t.Errorf("particle A = %v, want moved by 0.3", particleA.Position)
+	}
+}
+
+func TestParticleVolume_SolvePosition(t *testing.T) {
+	particles, triangles := tetrahedron()
+	c := NewParticleVolume(particles, triangles, 0)
+	if math.Abs(c.RestVolume-1.0/6) > 1e-12 {
+		t.Fatalf("rest volume = %v, want 1/6", c.RestVolume)
+	}
+
+	// Squeezed, the volume is restored by inflating the other particles
+	particles[3].Position = mgl64.Vec3{0, 0, 0.5}
+	for range 10 {
+		c.SolvePosition(1.0 / 60.0)
+	}
+	if volume := c.Volume(); math.Abs(volume-c.RestVolume) > 1e-6 {
+		t.Errorf("volume = %v, want %v", volume, c.RestVolume)
+	}
+
+	// The pressure scales the kept volume
+	c.Pressure = 2
+	for range 10 {
+		c.SolvePosition(1.0 / 60.0)
+	}
+	if volume := c.Volume(); math.Abs(volume-2*c.RestVolume) > 1e-6 {
+		t.Errorf("volume = %v, want %v", volume, 2*c.RestVolume)
+	}
+}
+
+func TestParticleContact_SolvePosition(t *testing.T) {
+	body := createStaticBody(mgl64.Vec3{0, 0, 0})
+	body.Transform.Rotation = mgl64.QuatIdent()
+	particle := actor.NewParticle(mgl64.Vec3{0, 0.55, 0}, 1)
+
+	// A particle of radius 0.1 sunk 0.05m into the top face of the body, at y = 0.5
+	c := NewParticleContact(particle, body, mgl64.Vec3{0, 0.5, 0}, mgl64.Vec3{0, 1, 0}, 0.1, 0)
+	if penetration := c.Penetration(); math.Abs(penetration-0.05) > 1e-9 {
+		t.Fatalf("penetration = %v, want 0.05", penetration)
+	}
+
+	c.SolvePosition(1.0 / 60.0)
+
+	if math.Abs(particle.Position.Y()-0.6) > 1e-9 {
+		t.Errorf("particle = %v, want pushed to the surface plus its radius", particle.Position)
+	}
+	if body.Transform.Position != (mgl64.Vec3{0, 0, 0}) {
+		t.Errorf("static body moved to %v", body.Transform.Position)
+	}
+}
+
+func TestParticleContact_Friction(t *testing.T) {
+	body := createStaticBody(mgl64.Vec3{0, 0, 0})
+	body.Transform.Rotation = mgl64.QuatIdent()
+	particle := actor.NewParticle(mgl64.Vec3{0, 0.65, 0}, 1)
+
+	// Landing while sliding 0.01m along X: the friction removes the sliding under Friction times the push
+	particle.Position = mgl64.Vec3{0.01, 0.55, 0}
+	c := NewParticleContact(particle, body, mgl64.Vec3{0, 0.5, 0}, mgl64.Vec3{0, 1, 0}, 0.1, 1)
+	c.SolvePosition(1.0 / 60.0)
+
+	if math.Abs(particle.Position.X()) > 1e-9 {
+		t.Errorf("particle = %v, want its sliding removed", particle.Position)
+	}
+
+	// Without friction, the particle keeps sliding
+	particle.PreviousPosition = mgl64.Vec3{0, 0.65, 0}
+	particle.Position = mgl64.Vec3{0.01, 0.55, 0}
+	c.Friction = 0
+	c.SolvePosition(1.0 / 60.0)
+
+	if math.Abs(particle.Position.X()-0.01) > 1e-9 {
+		t.Errorf("particle = %v, want sliding without friction", particle.Position)
+	}
+}
@@ -0,0 +1,92 @@
+package constraint
+
+import (
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// VelocityField returns the target velocity at a world position (e.g. a conveyor or a wind zone)
+type VelocityField func(position mgl64.Vec3) mgl64.Vec3
+
+// VelocityMatchConstraint pulls the linear velocity of BodyA toward a target, with a limited force.
+// It is used for effects that are not surface contacts: tractor beams, magnets, conveyor fields.
+//   - BodyB set: the two bodies are pulled toward the same velocity, momentum is conserved
+//   - BodyB nil: BodyA is pulled toward the velocity given by Field at its position
+//
+// The impulse is applied at the center of mass, it does not change the angular velocity.
+type VelocityMatchConstraint struct {
+	BodyA *actor.RigidBody
+	BodyB *actor.RigidBody
+	Field VelocityField
+
+	// MaxForce limits the force (N) applied at each substep, a non positive value applies no limit
+	MaxForce float64
+}
+
+// NewVelocityMatchConstraint pulls the velocities of two bodies toward each other
+func NewVelocityMatchConstraint(bodyA, bodyB *actor.RigidBody, maxForce float64) *VelocityMatchConstraint {
+	return &VelocityMatchConstraint{BodyA: bodyA, BodyB: bodyB, MaxForce: maxForce}
+}
+
+// NewVelocityFieldConstraint pulls the velocity of a body toward a velocity field
+func NewVelocityFieldConstraint(body *actor.RigidBody, field VelocityField, maxForce float64) *VelocityMatchConstraint {
+	return &VelocityMatchConstraint{BodyA: body, Field: field, MaxForce: maxForce}
+}
+
+// Bodies returns the bodies pulled by the constraint, see Connector
+func (c *VelocityMatchConstraint) Bodies() []*actor.RigidBody {
+	if c.BodyB == nil {
+		return []*actor.RigidBody{c.BodyA}
+	}
+
+	return []*actor.RigidBody{c.BodyA, c.BodyB}
+}
+
+// SolvePosition does nothing, the constraint only acts on velocities
+func (c *VelocityMatchConstraint) SolvePosition(dt float64) {}
+
+// SolveVelocity applies the impulse matching the velocities, clamped to MaxForce * dt
+func (c *VelocityMatchConstraint) SolveVelocity(dt float64) {
+	bodyA := c.BodyA
+	bodyB := c.BodyB
+
+	bodyA.Mutex.Lock()
+	defer bodyA.Mutex.Unlock()
+	if bodyB != nil {
+		bodyB.Mutex.Lock()
+		defer bodyB.Mutex.Unlock()
+	}
+
+	invMassA := 1.0 / bodyA.Material.GetMass()
+	invMassB := 0.0
+
+	var target mgl64.Vec3
+	if bodyB != nil {
+		invMassB = 1.0 / bodyB.Material.GetMass()
+		target = bodyB.Velocity
+	} else if c.Field != nil {
+		target = c.Field(bodyA.Transform.Position)
+	} else {
+		return
+	}
+
+	effectiveMass := invMassA + invMassB
+	if effectiveMass < 1e-10 {
+		return
+	}
+
+	// Impulse cancelling the relative velocity
+	impulse := target.Sub(bodyA.Velocity).Mul(1.0 / effectiveMass)
+
+	if c.MaxForce > 0 {
+		maxImpulse := c.MaxForce * dt
+		if length := impulse.Len(); length > maxImpulse {
+			impulse = impulse.Mul(maxImpulse / length)
+		}
+	}
+
+	bodyA.Velocity = bodyA.Velocity.Add(impulse.Mul(invMassA))
+	if bodyB != nil {
+		bodyB.Velocity = bodyB.Velocity.Sub(impulse.Mul(invMassB))
+	}
+}
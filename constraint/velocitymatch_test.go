@@ -0,0 +1,62 @@
+package constraint
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestVelocityMatchConstraint_ConservesMomentum(t *testing.T) {
+	bodyA := createDynamicBody(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{4, 0, 0}, 1.0)
+	bodyB := createDynamicBody(mgl64.Vec3{5, 0, 0}, mgl64.Vec3{0, 0, 0}, 3.0)
+	momentum := bodyA.Velocity.Mul(bodyA.Material.GetMass()).Add(bodyB.Velocity.Mul(bodyB.Material.GetMass()))
+
+	c := NewVelocityMatchConstraint(bodyA, bodyB, 0)
+	c.SolveVelocity(1.0 / 60.0)
+
+	if !bodyA.Velocity.ApproxEqual(bodyB.Velocity) {
+		t.Errorf("velocities not matched: %v %v", bodyA.Velocity, bodyB.Velocity)
+	}
+	if !bodyA.Velocity.ApproxEqual(mgl64.Vec3{1, 0, 0}) {
+		t.Errorf("velocity = %v, want {1 0 0}", bodyA.Velocity)
+	}
+
+	after := bodyA.Velocity.Mul(bodyA.Material.GetMass()).Add(bodyB.Velocity.Mul(bodyB.Material.GetMass()))
+	if !after.ApproxEqual(momentum) {
+		t.Errorf("momentum = %v, want %v", after, momentum)
+	}
+}
+
+func TestVelocityMatchConstraint_MaxForce(t *testing.T) {
+	body := createDynamicBody(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{0, 0, 0}, 1.0)
+	mass := body.Material.GetMass()
+	conveyor := func(position mgl64.Vec3) mgl64.Vec3 {
+		return mgl64.Vec3{10, 0, 0}
+	}
+
+	dt := 0.1
+	c := NewVelocityFieldConstraint(body, conveyor, 2.0)
+	c.SolveVelocity(dt)
+
+	// Impulse clamped to MaxForce * dt
+	expected := 2.0 * dt / mass
+	if math.Abs(body.Velocity.X()-expected) > 1e-9 {
+		t.Errorf("velocity = %v, want %v", body.Velocity.X(), expected)
+	}
+}
+
+func TestVelocityMatchConstraint_StaticTarget(t *testing.T) {
+	body := createDynamicBody(mgl64.Vec3{0, 3, 0}, mgl64.Vec3{0, 2, 0}, 1.0)
+	anchor := createStaticBody(mgl64.Vec3{0, 0, 0})
+
+	c := NewVelocityMatchConstraint(body, anchor, 0)
+	c.SolveVelocity(1.0 / 60.0)
+
+	if !body.Velocity.ApproxEqual(mgl64.Vec3{}) {
+		t.Errorf("velocity = %v, want zero", body.Velocity)
+	}
+	if anchor.Velocity != (mgl64.Vec3{}) {
+		t.Errorf("static body moved: %v", anchor.Velocity)
+	}
+}
@@ -0,0 +1,128 @@
+package feather
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/akmonengine/feather/constraint"
+	"github.com/akmonengine/feather/epa"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// CollisionFunc computes the contact of two bodies whose shapes are of the types it is registered for.
+// It returns the contact normal, pointing from bodyA toward bodyB, and the contact points appended to points:
+// an empty buffer of the world Allocator, with room for 4 points. ok is false if the bodies do not touch.
+type CollisionFunc func(bodyA, bodyB *actor.RigidBody, points []constraint.ContactPoint) (normal mgl64.Vec3, result []constraint.ContactPoint, ok bool)
+
+// CollisionDispatcher maps the pairs of shape types to the functions computing their contacts.
+// The narrow phase uses the function of a pair when there is one, otherwise its generic paths:
+// CollideWithPlane for the planes, the triangles of the meshes, the children of the compounds, and GJK/EPA.
+type CollisionDispatcher struct {
+	functions map[shapePair]CollisionFunc
+}
+
+// shapePair is the key of a CollisionDispatcher: the dynamic types of both shapes
+type shapePair struct {
+	a, b reflect.Type
+}
+
+// defaultCollisions holds the built-in functions, used by the worlds without a CollisionDispatcher
+var defaultCollisions = NewCollisionDispatcher()
+
+// NewCollisionDispatcher creates a dispatcher with the built-in functions: the closed-form contacts
+// of the sphere-sphere and box-sphere pairs, and the separating axis test of the box-box pairs
+func NewCollisionDispatcher() *CollisionDispatcher {
+	d := &CollisionDispatcher{functions: make(map[shapePair]CollisionFunc)}
+	d.Register(&actor.Sphere{}, &actor.Sphere{}, collideSpheres)
+	d.Register(&actor.Box{}, &actor.Sphere{}, collideSpheres)
+	d.Register(&actor.Box{}, &actor.Box{}, collideBoxes)
+
+	return d
+}
+
+// Register sets the function computing the contacts of the shapes of the types of shapeA and shapeB, e.g.
+//
+//	dispatcher.Register((*Capsule)(nil), &actor.Box{}, collideCapsuleBox)
+//
+// It replaces the function of the pair, in both orders: the bodies are given to collide in the order of
+// the registration, the normal is reversed for the other order. It must not be called during a Step.
+func (d *CollisionDispatcher) Register(shapeA, shapeB actor.ShapeInterface, collide CollisionFunc) {
+	typeA, typeB := reflect.TypeOf(shapeA), reflect.TypeOf(shapeB)
+
+	d.functions[shapePair{typeA, typeB}] = collide
+	if typeA != typeB {
+		d.functions[shapePair{typeB, typeA}] = func(bodyA, bodyB *actor.RigidBody, points []constraint.ContactPoint) (mgl64.Vec3, []constraint.ContactPoint, bool) {
+			normal, points, ok := collide(bodyB, bodyA, points)
+
+			return normal.Mul(-1), points, ok
+		}
+	}
+}
+
+// lookup returns the function of the shapes of the bodies, if there is one
+func (d *CollisionDispatcher) lookup(bodyA, bodyB *actor.RigidBody) (CollisionFunc, bool) {
+	collide, ok := d.functions[shapePair{reflect.TypeOf(bodyA.Shape), reflect.TypeOf(bodyB.Shape)}]
+
+	return collide, ok
+}
+
+// collideSpheres computes the contact of a sphere-sphere or box-sphere pair in closed form, see epa.SpherePenetration
+func collideSpheres(bodyA, bodyB *actor.RigidBody, points []constraint.ContactPoint) (mgl64.Vec3, []constraint.ContactPoint, bool) {
+	normal, depth, point, ok := epa.SpherePenetration(bodyA, bodyB)
+	if !ok {
+		return mgl64.Vec3{}, points, false
+	}
+
+	return normal, append(points, constraint.ContactPoint{Position: point, Penetration: depth}), true
+}
+
+// collideBoxes computes the contact of two boxes by the separating axis test, see epa.BoxPenetration
+func collideBoxes(bodyA, bodyB *actor.RigidBody, points []constraint.ContactPoint) (mgl64.Vec3, []constraint.ContactPoint, bool) {
+	normal, depth, ok := epa.BoxPenetration(bodyA, bodyB)
+	if !ok {
+		return mgl64.Vec3{}, points, false
+	}
+
+	return normal, append(points, epa.GenerateManifold(bodyA, bodyB, normal, depth)...), true
+}
+
+// collideDispatched computes the contacts of the pairs with the functions of the dispatcher
+func collideDispatched(pairs <-chan Pair, workersCount int, allocator Allocator, dispatcher *CollisionDispatcher) <-chan *constraint.ContactConstraint {
+	ch := make(chan *constraint.ContactConstraint, workersCount)
+
+	go func() {
+		var wg sync.WaitGroup
+		defer close(ch)
+
+		for range workersCount {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for pair := range pairs {
+					collide, ok := dispatcher.lookup(pair.BodyA, pair.BodyB)
+					if !ok {
+						continue
+					}
+
+					normal, points, ok := collide(pair.BodyA, pair.BodyB, allocator.Points(4)[:0])
+					if !ok || len(points) == 0 {
+						continue
+					}
+
+					contact := allocator.Contact()
+					contact.BodyA = pair.BodyA
+					contact.BodyB = pair.BodyB
+					contact.Normal = normal
+					contact.Points = points
+
+					ch <- contact
+				}
+			}()
+		}
+
+		wg.Wait()
+	}()
+
+	return ch
+}
@@ -0,0 +1,82 @@
+package feather
+
+import (
+	"testing"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/akmonengine/feather/constraint"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// customShape is a user shape, only known to the dispatcher
+type customShape struct {
+	*actor.Sphere
+}
+
+func TestCollisionDispatcher_BuiltIns(t *testing.T) {
+	dispatcher := NewCollisionDispatcher()
+	box := createBox(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{1, 1, 1}, actor.BodyTypeDynamic)
+	sphere := createSphere(mgl64.Vec3{0, 0, 0}, 1, actor.BodyTypeDynamic)
+	cone := &actor.RigidBody{Shape: &actor.Cone{Radius: 1, Height: 1}}
+
+	for _, pair := range [][2]*actor.RigidBody{{box, box}, {sphere, sphere}, {box, sphere}, {sphere, box}} {
+		if _, ok := dispatcher.lookup(pair[0], pair[1]); !ok {
+			t.Errorf("no function for %T-%T", pair[0].Shape, pair[1].Shape)
+		}
+	}
+	if _, ok := dispatcher.lookup(cone, box); ok {
+		t.Error("function for cone-box, want GJK/EPA")
+	}
+}
+
+func TestCollisionDispatcher_CustomShape(t *testing.T) {
+	dispatcher := NewCollisionDispatcher()
+	dispatcher.Register(customShape{}, &actor.Box{}, func(bodyA, bodyB *actor.RigidBody, points []constraint.ContactPoint) (mgl64.Vec3, []constraint.ContactPoint, bool) {
+		if _, ok := bodyA.Shape.(customShape); !ok {
+			t.Errorf("bodyA is a %T, want the custom shape", bodyA.Shape)
+		}
+
+		return mgl64.Vec3{0, -1, 0}, append(points, constraint.ContactPoint{Position: bodyA.Transform.Position, Penetration: 0.1}), true
+	})
+
+	custom := &actor.RigidBody{
+		Shape:     customShape{&actor.Sphere{Radius: 0.5}},
+		Transform: actor.Transform{Position: mgl64.Vec3{0, 1.4, 0}, Rotation: mgl64.QuatIdent()},
+	}
+	box := createBox(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{1, 1, 1}, actor.BodyTypeStatic)
+
+	// The pair in the other order: the normal is reversed
+	pairs := make(chan Pair, 1)
+	pairs <- Pair{BodyA: box, BodyB: custom}
+	close(pairs)
+
+	contacts := narrowPhase(pairs, 2, nil, heapAllocator{}, 0, dispatcher)
+	if len(contacts) != 1 {
+		t.Fatalf("%d contacts, want 1", len(contacts))
+	}
+	if contacts[0].BodyA != box || contacts[0].Normal != (mgl64.Vec3{0, 1, 0}) {
+		t.Errorf("normal = %v, want {0 1 0} from the box to the custom shape", contacts[0].Normal)
+	}
+	if len(contacts[0].Points) != 1 || contacts[0].Points[0].Penetration != 0.1 {
+		t.Errorf("points = %+v, want the point of the custom function", contacts[0].Points)
+	}
+}
+
+func TestWorld_CollisionsOverride(t *testing.T) {
+	world := NewWorld()
+	world.Gravity = mgl64.Vec3{}
+	world.Collisions = NewCollisionDispatcher()
+	world.Collisions.Register(&actor.Sphere{}, &actor.Sphere{}, func(bodyA, bodyB *actor.RigidBody, points []constraint.ContactPoint) (mgl64.Vec3, []constraint.ContactPoint, bool) {
+		return mgl64.Vec3{}, points, false
+	})
+
+	a := createSphere(mgl64.Vec3{0, 0, 0}, 1, actor.BodyTypeDynamic)
+	b := createSphere(mgl64.Vec3{1, 0, 0}, 1, actor.BodyTypeDynamic)
+	world.AddBody(a)
+	world.AddBody(b)
+	world.Step(1.0 / 60.0)
+
+	if a.Transform.Position != (mgl64.Vec3{}) || b.Transform.Position != (mgl64.Vec3{1, 0, 0}) {
+		t.Errorf("positions %v and %v, want the overlapping spheres left in place", a.Transform.Position, b.Transform.Position)
+	}
+}
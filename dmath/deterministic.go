@@ -0,0 +1,26 @@
+//go:build featherdeterministic
+
+package dmath
+
+// Deterministic reports whether the deterministic implementations are used
+const Deterministic = true
+
+// Exp returns e**x
+func Exp(x float64) float64 {
+	return exp(x)
+}
+
+// Sin returns the sine of x (radians)
+func Sin(x float64) float64 {
+	return sin(x)
+}
+
+// Cos returns the cosine of x (radians)
+func Cos(x float64) float64 {
+	return cos(x)
+}
+
+// Atan2 returns the arc tangent of y/x, using the signs of both to determine the quadrant
+func Atan2(y, x float64) float64 {
+	return atan2(y, x)
+}
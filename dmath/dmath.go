@@ -0,0 +1,25 @@
+// Package dmath provides the math functions used by the engine, with optional deterministic implementations.
+//
+// Lockstep multiplayer requires the same simulation results on every machine. The standard library
+// uses assembly for some functions on some architectures (e.g. math.Exp on amd64 and arm64),
+// and the compiler may fuse a*b+c into a single FMA instruction on arm64, ppc64 or s390x,
+// which changes the rounding of the result.
+//
+// Building with the featherdeterministic tag replaces these functions with pure Go implementations,
+// where every product is explicitly rounded to prevent the fusion:
+//
+//	go build -tags featherdeterministic
+//
+// Sqrt is always math.Sqrt: IEEE 754 requires it to be correctly rounded, on every architecture.
+//
+// The deterministic functions are slower, and only as accurate as the fdlibm/Cephes algorithms
+// they are based on (about 1 ulp). The vector operations of mgl64 remain subject to FMA fusion,
+// cross-architecture lockstep must also be validated on the target platforms.
+package dmath
+
+import "math"
+
+// Sqrt returns the square root of x, correctly rounded on every architecture
+func Sqrt(x float64) float64 {
+	return math.Sqrt(x)
+}
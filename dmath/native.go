@@ -0,0 +1,28 @@
+//go:build !featherdeterministic
+
+package dmath
+
+import "math"
+
+// Deterministic reports whether the deterministic implementations are used
+const Deterministic = false
+
+// Exp returns e**x
+func Exp(x float64) float64 {
+	return math.Exp(x)
+}
+
+// Sin returns the sine of x (radians)
+func Sin(x float64) float64 {
+	return math.Sin(x)
+}
+
+// Cos returns the cosine of x (radians)
+func Cos(x float64) float64 {
+	return math.Cos(x)
+}
+
+// Atan2 returns the arc tangent of y/x, using the signs of both to determine the quadrant
+func Atan2(y, x float64) float64 {
+	return math.Atan2(y, x)
+}
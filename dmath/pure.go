@@ -0,0 +1,231 @@
+package dmath
+
+import "math"
+
+// Pure Go implementations, used by the featherdeterministic build.
+// Every product followed by an addition is wrapped in an explicit float64 conversion:
+// the Go specification guarantees the conversion rounds the product, preventing FMA fusion.
+
+const (
+	// fdlibm e_exp.c
+	ln2Hi    = 6.93147180369123816490e-01
+	ln2Lo    = 1.90821492927058770002e-10
+	log2e    = 1.44269504088896338700e+00
+	expP1    = 1.66666666666666019037e-01
+	expP2    = -2.77777777770155933842e-03
+	expP3    = 6.61375632143793436117e-05
+	expP4    = -1.65339022054652515390e-06
+	expP5    = 4.13813679705723846039e-08
+	expOver  = 7.09782712893383973096e+02
+	expUnder = -7.45133219101941108420e+02
+
+	// fdlibm k_rem_pio2.c, medium precision reduction
+	invPio2 = 6.36619772367581382433e-01
+	pio2Hi  = 1.57079632673412561417e+00
+	pio2Lo  = 6.07710050650619224932e-11
+
+	// fdlibm k_sin.c
+	sinS1 = -1.66666666666666324348e-01
+	sinS2 = 8.33333333332248946124e-03
+	sinS3 = -1.98412698298579493134e-04
+	sinS4 = 2.75573137070700676789e-06
+	sinS5 = -2.50507602534068634195e-08
+	sinS6 = 1.58969099521155010221e-10
+
+	// fdlibm k_cos.c
+	cosC1 = 4.16666666666666019037e-02
+	cosC2 = -1.38888888888741095749e-03
+	cosC3 = 2.48015872894767294178e-05
+	cosC4 = -2.75573143513906633035e-07
+	cosC5 = 2.08757232129817482790e-09
+	cosC6 = -1.13596475577881948265e-11
+
+	// Cephes atan.c
+	atanP0    = -8.750608600031904122785e-01
+	atanP1    = -1.615753718733365076637e+01
+	atanP2    = -7.500855792314704667340e+01
+	atanP3    = -1.228866684490136173410e+02
+	atanP4    = -6.485021904942025371773e+01
+	atanQ0    = +2.485846490142306297962e+01
+	atanQ1    = +1.650270098316988542046e+02
+	atanQ2    = +4.328810604912902668951e+02
+	atanQ3    = +4.853903996359136964868e+02
+	atanQ4    = +1.945506571482613964425e+02
+	morebits  = 6.123233995736765886130e-17
+	tan3pio8  = 2.41421356237309504880
+	atanSmall = 0.66
+)
+
+func exp(x float64) float64 {
+	switch {
+	case math.IsNaN(x) || math.IsInf(x, 1):
+		return x
+	case math.IsInf(x, -1):
+		return 0
+	case x > expOver:
+		return math.Inf(1)
+	case x < expUnder:
+		return 0
+	}
+
+	// Reduce x = k*ln2 + r, |r| <= 0.5*ln2
+	var k int
+	if x < 0 {
+		k = int(float64(log2e*x) - 0.5)
+	} else {
+		k = int(float64(log2e*x) + 0.5)
+	}
+	hi := x - float64(float64(k)*ln2Hi)
+	lo := float64(float64(k) * ln2Lo)
+	r := hi - lo
+
+	t := float64(r * r)
+	c := r - float64(t*(expP1+float64(t*(expP2+float64(t*(expP3+float64(t*(expP4+float64(t*expP5)))))))))
+	y := 1 - ((lo - float64(r*c)/(2-c)) - hi)
+
+	return math.Ldexp(y, k)
+}
+
+// reducePio2 returns n and y such as x = n*π/2 + y, |y| <= π/4
+// The precision degrades for |x| > 2^20*π/2, the result stays deterministic.
+func reducePio2(x float64) (int, float64) {
+	n := math.Floor(float64(x*invPio2) + 0.5)
+	y := (x - float64(n*pio2Hi)) - float64(n*pio2Lo)
+
+	return int(n), y
+}
+
+func kernelSin(y float64) float64 {
+	z := float64(y * y)
+	v := float64(z * y)
+	r := sinS2 + float64(z*(sinS3+float64(z*(sinS4+float64(z*(sinS5+float64(z*sinS6)))))))
+
+	return y + float64(v*(sinS1+float64(z*r)))
+}
+
+func kernelCos(y float64) float64 {
+	z := float64(y * y)
+	r := float64(z * (cosC1 + float64(z*(cosC2+float64(z*(cosC3+float64(z*(cosC4+float64(z*(cosC5+float64(z*cosC6)))))))))))
+	hz := float64(0.5 * z)
+
+	return (1 - hz) + float64(z*r)
+}
+
+func sin(x float64) float64 {
+	if x == 0 || math.IsNaN(x) {
+		return x
+	}
+	if math.IsInf(x, 0) {
+		return math.NaN()
+	}
+
+	n, y := reducePio2(x)
+	switch n & 3 {
+	case 0:
+		return kernelSin(y)
+	case 1:
+		return kernelCos(y)
+	case 2:
+		return -kernelSin(y)
+	default:
+		return -kernelCos(y)
+	}
+}
+
+func cos(x float64) float64 {
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return math.NaN()
+	}
+
+	n, y := reducePio2(x)
+	switch n & 3 {
+	case 0:
+		return kernelCos(y)
+	case 1:
+		return -kernelSin(y)
+	case 2:
+		return -kernelCos(y)
+	default:
+		return kernelSin(y)
+	}
+}
+
+// xatan evaluates a rational polynomial approximation of atan on [0, 0.66]
+func xatan(x float64) float64 {
+	z := float64(x * x)
+
+	num := float64(atanP0*z) + atanP1
+	num = float64(num*z) + atanP2
+	num = float64(num*z) + atanP3
+	num = float64(num*z) + atanP4
+
+	den := z + atanQ0
+	den = float64(den*z) + atanQ1
+	den = float64(den*z) + atanQ2
+	den = float64(den*z) + atanQ3
+	den = float64(den*z) + atanQ4
+
+	z = float64(z*num) / den
+
+	return float64(x*z) + x
+}
+
+// satan reduces a positive argument to the range of xatan
+func satan(x float64) float64 {
+	if x <= atanSmall {
+		return xatan(x)
+	}
+	if x > tan3pio8 {
+		return math.Pi/2 - xatan(1/x) + morebits
+	}
+
+	return math.Pi/4 + xatan((x-1)/(x+1)) + 0.5*morebits
+}
+
+func atan(x float64) float64 {
+	if x == 0 {
+		return x
+	}
+	if x > 0 {
+		return satan(x)
+	}
+
+	return -satan(-x)
+}
+
+func atan2(y, x float64) float64 {
+	switch {
+	case math.IsNaN(y) || math.IsNaN(x):
+		return math.NaN()
+	case y == 0:
+		if x >= 0 && !math.Signbit(x) {
+			return math.Copysign(0, y)
+		}
+		return math.Copysign(math.Pi, y)
+	case x == 0:
+		return math.Copysign(math.Pi/2, y)
+	case math.IsInf(x, 0):
+		if math.IsInf(x, 1) {
+			if math.IsInf(y, 0) {
+				return math.Copysign(math.Pi/4, y)
+			}
+			return math.Copysign(0, y)
+		}
+		if math.IsInf(y, 0) {
+			return math.Copysign(3*math.Pi/4, y)
+		}
+		return math.Copysign(math.Pi, y)
+	case math.IsInf(y, 0):
+		return math.Copysign(math.Pi/2, y)
+	}
+
+	q := atan(y / x)
+	if x < 0 {
+		if q <= 0 {
+			return q + math.Pi
+		}
+		return q - math.Pi
+	}
+
+	return q
+}
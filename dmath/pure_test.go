@@ -0,0 +1,95 @@
+package dmath
+
+import (
+	"math"
+	"testing"
+)
+
+// ulpDistance returns the number of representable float64 between a and b
+func ulpDistance(a, b float64) uint64 {
+	ia, ib := int64(math.Float64bits(a)), int64(math.Float64bits(b))
+	if ia < 0 {
+		ia = math.MinInt64 - ia
+	}
+	if ib < 0 {
+		ib = math.MinInt64 - ib
+	}
+	if ia > ib {
+		return uint64(ia - ib)
+	}
+
+	return uint64(ib - ia)
+}
+
+func TestPureFunctions_MatchStandardLibrary(t *testing.T) {
+	functions := []struct {
+		name     string
+		pure     func(float64) float64
+		standard func(float64) float64
+		min, max float64
+	}{
+		{"exp", exp, math.Exp, -700, 700},
+		{"sin", sin, math.Sin, -100, 100},
+		{"cos", cos, math.Cos, -100, 100},
+		{"atan", atan, math.Atan, -1000, 1000},
+	}
+
+	const samples = 100000
+	for _, f := range functions {
+		t.Run(f.name, func(t *testing.T) {
+			for i := 0; i <= samples; i++ {
+				x := f.min + (f.max-f.min)*float64(i)/samples
+				got, want := f.pure(x), f.standard(x)
+
+				// Absolute tolerance near the zeros of sin and cos
+				if math.Abs(got-want) > 1e-15 && ulpDistance(got, want) > 2 {
+					t.Fatalf("%s(%v) = %v, want %v", f.name, x, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestAtan2_Quadrants(t *testing.T) {
+	tests := []struct{ y, x float64 }{
+		{1, 1}, {1, -1}, {-1, -1}, {-1, 1},
+		{0.3, 2}, {2, 0.3}, {-5, 0.1}, {1e-10, -1},
+	}
+
+	for _, tt := range tests {
+		got, want := atan2(tt.y, tt.x), math.Atan2(tt.y, tt.x)
+		if ulpDistance(got, want) > 2 {
+			t.Errorf("atan2(%v, %v) = %v, want %v", tt.y, tt.x, got, want)
+		}
+	}
+}
+
+func TestPureFunctions_SpecialCases(t *testing.T) {
+	inf := math.Inf(1)
+	tests := []struct {
+		name      string
+		got, want float64
+	}{
+		{"exp(-inf)", exp(-inf), 0},
+		{"exp(+inf)", exp(inf), inf},
+		{"exp(800)", exp(800), inf},
+		{"exp(-800)", exp(-800), 0},
+		{"exp(0)", exp(0), 1},
+		{"sin(0)", sin(0), 0},
+		{"cos(0)", cos(0), 1},
+		{"atan2(0, -1)", atan2(0, -1), math.Pi},
+		{"atan2(1, 0)", atan2(1, 0), math.Pi / 2},
+		{"atan2(inf, inf)", atan2(inf, inf), math.Pi / 4},
+		{"atan2(1, -inf)", atan2(1, -inf), math.Pi},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	if !math.IsNaN(sin(inf)) || !math.IsNaN(cos(inf)) || !math.IsNaN(atan2(math.NaN(), 1)) {
+		t.Error("expected NaN")
+	}
+}
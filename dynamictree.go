@@ -0,0 +1,470 @@
+package feather
+
+import (
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// DEFAULT_TREE_MARGIN is the distance (m) by which the leaves of a DynamicTree are inflated
+const DEFAULT_TREE_MARGIN = 0.1
+
+// nullNode marks the absence of a node in the DynamicTree
+const nullNode = -1
+
+// DynamicTree - Bounding volume hierarchy for broad phase, updated incrementally between steps.
+// Each body is a leaf holding its AABB inflated by Margin (the fat AABB): a body moving within it
+// keeps its place, only the bodies leaving it are removed and inserted again.
+// Unlike the SpatialGrid, it does not depend on a cell size: it suits the scenes whose body sizes vary
+// by orders of magnitude (pebbles next to buildings), where a grid either stores the big bodies in
+// many cells or the small ones by dozens in the same cell.
+type DynamicTree struct {
+	// Margin inflates the leaves (m), 0 uses DEFAULT_TREE_MARGIN
+	Margin float64
+
+	nodes    []treeNode
+	root     int
+	freeList int
+
+	// Leaf of each body, and the leaves in insertion order (removals stay deterministic)
+	proxies map[*actor.RigidBody]int
+	leaves  []int
+	stamp   uint64
+
+	planes []int
+	meshes []int
+
+	// Pairs of each task of FindPairs, reused from one step to the next
+	pairBuffers [][]Pair
+}
+
+// treeNode - Node of the DynamicTree, a leaf if left is nullNode
+type treeNode struct {
+	aabb   actor.AABB
+	parent int
+	left   int
+	right  int
+	// height is 0 for the leaves, nullNode for the nodes of the free list
+	height int
+
+	body      *actor.RigidBody
+	bodyIndex int
+	stamp     uint64
+}
+
+// NewDynamicTree - Creates an empty tree, whose leaves are inflated by margin (0 uses DEFAULT_TREE_MARGIN)
+func NewDynamicTree(margin float64) *DynamicTree {
+	return &DynamicTree{
+		Margin:   margin,
+		root:     nullNode,
+		freeList: nullNode,
+		proxies:  make(map[*actor.RigidBody]int),
+	}
+}
+
+// Update - Synchronizes the tree with the bodies: the new ones are inserted, the missing ones removed,
+// and the ones that left their fat AABB are moved. The leaves record the index of their body in bodies.
+func (t *DynamicTree) Update(bodies []*actor.RigidBody) {
+	if t.proxies == nil {
+		t.proxies = make(map[*actor.RigidBody]int)
+		t.root, t.freeList = nullNode, nullNode
+	}
+	t.stamp++
+	t.planes = t.planes[:0]
+	t.meshes = t.meshes[:0]
+
+	for i, body := range bodies {
+		if !body.IsEnabled() {
+			// Not stamped, its leaf is removed below
+			continue
+		}
+		if _, isPlane := body.Shape.(*actor.Plane); isPlane {
+			t.planes = append(t.planes, i)
+			continue
+		}
+		if isMesh(body) {
+			t.meshes = append(t.meshes, i)
+			continue
+		}
+
+		aabb := body.BroadPhaseAABB()
+		leaf, ok := t.proxies[body]
+		if !ok {
+			leaf = t.allocateNode()
+			t.nodes[leaf].body = body
+			t.nodes[leaf].aabb = t.fatten(aabb)
+			t.insertLeaf(leaf)
+			t.proxies[body] = leaf
+			t.leaves = append(t.leaves, leaf)
+		} else if !contains(t.nodes[leaf].aabb, aabb) {
+			t.removeLeaf(leaf)
+			t.nodes[leaf].aabb = t.fatten(aabb)
+			t.insertLeaf(leaf)
+		}
+		t.nodes[leaf].bodyIndex = i
+		t.nodes[leaf].stamp = t.stamp
+	}
+
+	// The bodies removed from the world since the last update
+	kept := t.leaves[:0]
+	for _, leaf := range t.leaves {
+		if t.nodes[leaf].stamp == t.stamp {
+			kept = append(kept, leaf)
+			continue
+		}
+
+		delete(t.proxies, t.nodes[leaf].body)
+		t.removeLeaf(leaf)
+		t.freeNode(leaf)
+	}
+	t.leaves = kept
+}
+
+// FindPairs - Returns the pairs of bodies whose AABB overlap, following the rules of SpatialGrid.FindPairsParallel.
+// The leaves are split into tasks among the workers, each one queries the tree with the AABB of its bodies.
+// The pairs are streamed in the order of the tasks, independently of workersCount.
+func (t *DynamicTree) FindPairs(bodies []*actor.RigidBody, workersCount int) <-chan Pair {
+	tasks := (len(t.leaves) + GRID_BODIES_PER_TASK - 1) / GRID_BODIES_PER_TASK
+
+	return pairTasks(&t.pairBuffers, tasks, workersCount, func(task int, pairs []Pair) []Pair {
+		var stack []int
+		leaves := t.leaves[task*GRID_BODIES_PER_TASK : min((task+1)*GRID_BODIES_PER_TASK, len(t.leaves))]
+		for _, leaf := range leaves {
+			bodyIdx := t.nodes[leaf].bodyIndex
+			bodyA := bodies[bodyIdx]
+			aabbA := bodyA.BroadPhaseAABB()
+
+			for _, planeId := range t.planes {
+				pairs = append(pairs, Pair{BodyA: bodies[planeId], BodyB: bodyA})
+			}
+
+			// meshes are static, only active bodies are tested against them
+			if bodyA.IsActive() {
+				for _, meshId := range t.meshes {
+					if bodies[meshId].Shape.GetAABB().Overlaps(aabbA) {
+						pairs = append(pairs, Pair{BodyA: bodies[meshId], BodyB: bodyA})
+					}
+				}
+			}
+
+			stack = t.query(aabbA, stack, func(otherIdx int) {
+				// Each pair is reported by its lowest index
+				if otherIdx <= bodyIdx {
+					return
+				}
+
+				bodyB := bodies[otherIdx]
+				if !bodyA.IsActive() && !bodyB.IsActive() {
+					return
+				}
+
+				if aabbA.Overlaps(bodyB.BroadPhaseAABB()) {
+					pairs = append(pairs, Pair{BodyA: bodyA, BodyB: bodyB})
+				}
+			})
+		}
+
+		return pairs
+	})
+}
+
+// QueryRegion - Calls fn for the planes, the meshes, and the bodies whose fat AABB overlaps region.
+// The tree answers any region, it always returns true.
+func (t *DynamicTree) QueryRegion(region actor.AABB, fn func(bodyIndex int)) bool {
+	for _, index := range t.planes {
+		fn(index)
+	}
+	for _, index := range t.meshes {
+		fn(index)
+	}
+	t.query(region, nil, fn)
+
+	return true
+}
+
+// QueryRay - Calls fn for the planes, the meshes, and the bodies whose fat AABB is crossed by the ray within reach().
+// The direction must be normalized. The tree answers any ray, it always returns true.
+func (t *DynamicTree) QueryRay(origin, direction mgl64.Vec3, maxDist float64, reach func() float64, fn func(bodyIndex int)) bool {
+	for _, index := range t.planes {
+		fn(index)
+	}
+	for _, index := range t.meshes {
+		fn(index)
+	}
+	if t.root == nullNode {
+		return true
+	}
+
+	stack := []int{t.root}
+	for len(stack) > 0 {
+		index := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		node := &t.nodes[index]
+		if _, ok := node.aabb.RayHit(origin, direction, min(maxDist, reach())); !ok {
+			continue
+		}
+
+		if node.left == nullNode {
+			fn(node.bodyIndex)
+			continue
+		}
+		stack = append(stack, node.left, node.right)
+	}
+
+	return true
+}
+
+// Height - Returns the height of the tree, 0 for a single leaf (and for an empty tree)
+func (t *DynamicTree) Height() int {
+	if t.root == nullNode {
+		return 0
+	}
+
+	return t.nodes[t.root].height
+}
+
+// query - Calls fn with the body index of the leaves whose fat AABB overlaps region.
+// stack is a reusable buffer, returned for the next call.
+func (t *DynamicTree) query(region actor.AABB, stack []int, fn func(bodyIndex int)) []int {
+	if t.root == nullNode {
+		return stack
+	}
+
+	stack = append(stack[:0], t.root)
+	for len(stack) > 0 {
+		index := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		node := &t.nodes[index]
+		if !node.aabb.Overlaps(region) {
+			continue
+		}
+
+		if node.left == nullNode {
+			fn(node.bodyIndex)
+			continue
+		}
+		stack = append(stack, node.left, node.right)
+	}
+
+	return stack
+}
+
+// fatten - Inflates an AABB by the margin of the tree
+func (t *DynamicTree) fatten(aabb actor.AABB) actor.AABB {
+	margin := t.Margin
+	if margin == 0 {
+		margin = DEFAULT_TREE_MARGIN
+	}
+	extent := mgl64.Vec3{margin, margin, margin}
+
+	return actor.AABB{Min: aabb.Min.Sub(extent), Max: aabb.Max.Add(extent)}
+}
+
+// allocateNode - Returns a node from the free list, or a new one
+func (t *DynamicTree) allocateNode() int {
+	if t.freeList == nullNode {
+		t.nodes = append(t.nodes, treeNode{})
+		t.freeList = len(t.nodes) - 1
+		t.nodes[t.freeList].parent = nullNode
+	}
+
+	index := t.freeList
+	t.freeList = t.nodes[index].parent
+	t.nodes[index] = treeNode{parent: nullNode, left: nullNode, right: nullNode}
+
+	return index
+}
+
+// freeNode - Returns a node to the free list, chained by its parent
+func (t *DynamicTree) freeNode(index int) {
+	t.nodes[index] = treeNode{parent: t.freeList, left: nullNode, right: nullNode, height: nullNode}
+	t.freeList = index
+}
+
+// insertLeaf - Inserts a leaf next to the sibling that enlarges the tree the least (surface area heuristic)
+func (t *DynamicTree) insertLeaf(leaf int) {
+	if t.root == nullNode {
+		t.root = leaf
+		t.nodes[leaf].parent = nullNode
+		return
+	}
+
+	// Find the best sibling
+	leafAABB := t.nodes[leaf].aabb
+	index := t.root
+	for t.nodes[index].left != nullNode {
+		node := t.nodes[index]
+		area := surfaceArea(node.aabb)
+		combinedArea := surfaceArea(union(node.aabb, leafAABB))
+
+		// Cost of creating a new parent for this node and the leaf, and the cost pushed down to the children
+		cost := 2 * combinedArea
+		inheritanceCost := 2 * (combinedArea - area)
+
+		childCost := func(child int) float64 {
+			merged := surfaceArea(union(leafAABB, t.nodes[child].aabb))
+			if t.nodes[child].left == nullNode {
+				return merged + inheritanceCost
+			}
+
+			return merged - surfaceArea(t.nodes[child].aabb) + inheritanceCost
+		}
+		costLeft := childCost(node.left)
+		costRight := childCost(node.right)
+
+		if cost < costLeft && cost < costRight {
+			break
+		}
+		if costLeft < costRight {
+			index = node.left
+		} else {
+			index = node.right
+		}
+	}
+	sibling := index
+
+	// Create a new parent
+	oldParent := t.nodes[sibling].parent
+	newParent := t.allocateNode()
+	t.nodes[newParent].parent = oldParent
+	t.nodes[newParent].aabb = union(leafAABB, t.nodes[sibling].aabb)
+	t.nodes[newParent].height = t.nodes[sibling].height + 1
+	t.nodes[newParent].left = sibling
+	t.nodes[newParent].right = leaf
+	t.nodes[sibling].parent = newParent
+	t.nodes[leaf].parent = newParent
+
+	if oldParent == nullNode {
+		t.root = newParent
+	} else if t.nodes[oldParent].left == sibling {
+		t.nodes[oldParent].left = newParent
+	} else {
+		t.nodes[oldParent].right = newParent
+	}
+
+	t.refit(t.nodes[leaf].parent)
+}
+
+// removeLeaf - Removes a leaf, its parent is replaced by its sibling
+func (t *DynamicTree) removeLeaf(leaf int) {
+	if leaf == t.root {
+		t.root = nullNode
+		return
+	}
+
+	parent := t.nodes[leaf].parent
+	grandParent := t.nodes[parent].parent
+	sibling := t.nodes[parent].left
+	if sibling == leaf {
+		sibling = t.nodes[parent].right
+	}
+
+	if grandParent == nullNode {
+		t.root = sibling
+		t.nodes[sibling].parent = nullNode
+		t.freeNode(parent)
+		return
+	}
+
+	if t.nodes[grandParent].left == parent {
+		t.nodes[grandParent].left = sibling
+	} else {
+		t.nodes[grandParent].right = sibling
+	}
+	t.nodes[sibling].parent = grandParent
+	t.freeNode(parent)
+
+	t.refit(grandParent)
+}
+
+// refit - Balances the ancestors from index up to the root, and fixes their height and AABB
+func (t *DynamicTree) refit(index int) {
+	for index != nullNode {
+		index = t.balance(index)
+
+		left := t.nodes[index].left
+		right := t.nodes[index].right
+		t.nodes[index].height = 1 + max(t.nodes[left].height, t.nodes[right].height)
+		t.nodes[index].aabb = union(t.nodes[left].aabb, t.nodes[right].aabb)
+
+		index = t.nodes[index].parent
+	}
+}
+
+// balance - Rotates the node if its children heights differ by more than 1 (AVL rotation).
+// Returns the index of the node now at its place.
+func (t *DynamicTree) balance(a int) int {
+	nodeA := &t.nodes[a]
+	if nodeA.left == nullNode || nodeA.height < 2 {
+		return a
+	}
+
+	b, c := nodeA.left, nodeA.right
+	difference := t.nodes[c].height - t.nodes[b].height
+	switch {
+	case difference > 1:
+		return t.rotate(a, c, b)
+	case difference < -1:
+		return t.rotate(a, b, c)
+	}
+
+	return a
+}
+
+// rotate - Promotes the taller child up of a, the other child stays under a.
+// The shorter grandchild of up replaces up under a. Returns up.
+func (t *DynamicTree) rotate(a, up, other int) int {
+	f := t.nodes[up].left
+	g := t.nodes[up].right
+
+	// up takes the place of a
+	t.nodes[up].left = a
+	t.nodes[up].parent = t.nodes[a].parent
+	t.nodes[a].parent = up
+
+	if parent := t.nodes[up].parent; parent == nullNode {
+		t.root = up
+	} else if t.nodes[parent].left == a {
+		t.nodes[parent].left = up
+	} else {
+		t.nodes[parent].right = up
+	}
+
+	// The taller grandchild stays under up, the shorter one goes under a
+	if t.nodes[f].height < t.nodes[g].height {
+		f, g = g, f
+	}
+	t.nodes[up].right = f
+	t.nodes[a].left = other
+	t.nodes[a].right = g
+	t.nodes[g].parent = a
+
+	t.nodes[a].aabb = union(t.nodes[other].aabb, t.nodes[g].aabb)
+	t.nodes[a].height = 1 + max(t.nodes[other].height, t.nodes[g].height)
+	t.nodes[up].aabb = union(t.nodes[a].aabb, t.nodes[f].aabb)
+	t.nodes[up].height = 1 + max(t.nodes[a].height, t.nodes[f].height)
+
+	return up
+}
+
+// union - Returns the AABB enclosing both AABBs
+func union(a, b actor.AABB) actor.AABB {
+	return actor.AABB{
+		Min: mgl64.Vec3{min(a.Min.X(), b.Min.X()), min(a.Min.Y(), b.Min.Y()), min(a.Min.Z(), b.Min.Z())},
+		Max: mgl64.Vec3{max(a.Max.X(), b.Max.X()), max(a.Max.Y(), b.Max.Y()), max(a.Max.Z(), b.Max.Z())},
+	}
+}
+
+// contains - Tells if outer encloses inner
+func contains(outer, inner actor.AABB) bool {
+	return outer.Min.X() <= inner.Min.X() && outer.Min.Y() <= inner.Min.Y() && outer.Min.Z() <= inner.Min.Z() &&
+		outer.Max.X() >= inner.Max.X() && outer.Max.Y() >= inner.Max.Y() && outer.Max.Z() >= inner.Max.Z()
+}
+
+// surfaceArea - Returns the area of the faces of the AABB, the cost of a node for the insertion heuristic
+func surfaceArea(aabb actor.AABB) float64 {
+	size := aabb.Max.Sub(aabb.Min)
+
+	return 2 * (size.X()*size.Y() + size.Y()*size.Z() + size.Z()*size.X())
+}
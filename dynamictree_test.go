@@ -0,0 +1,230 @@
+package feather
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// createMixedBodies creates bodies whose sizes span three orders of magnitude, with a plane
+func createMixedBodies(rng *rand.Rand, count int) []*actor.RigidBody {
+	bodies := []*actor.RigidBody{createPlane(mgl64.Vec3{0, 1, 0}, 0)}
+	for i := 0; i < count; i++ {
+		position := mgl64.Vec3{rng.Float64() * 40, rng.Float64() * 40, rng.Float64() * 40}
+		size := math.Pow(10, rng.Float64()*3-2)
+		bodyType := actor.BodyTypeDynamic
+		if i%5 == 0 {
+			bodyType = actor.BodyTypeStatic
+		}
+		body := createBox(position, mgl64.Vec3{size, size, size}, bodyType)
+		body.IsSleeping = i%7 == 0
+		bodies = append(bodies, body)
+	}
+
+	return bodies
+}
+
+func collectPairs(pairs <-chan Pair) map[Pair]bool {
+	result := make(map[Pair]bool)
+	for pair := range pairs {
+		result[OrderPair(pair.BodyA, pair.BodyB)] = true
+	}
+
+	return result
+}
+
+func comparePairs(t *testing.T, treePairs, bruteForcePairs map[Pair]bool) {
+	t.Helper()
+	if len(treePairs) != len(bruteForcePairs) {
+		t.Fatalf("tree found %d pairs, brute force found %d", len(treePairs), len(bruteForcePairs))
+	}
+	for pair := range treePairs {
+		if !bruteForcePairs[pair] {
+			t.Errorf("pair %v missing from the brute force broad phase", pair)
+		}
+	}
+}
+
+func TestDynamicTree_MatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	bodies := createMixedBodies(rng, 200)
+
+	tree := NewDynamicTree(0)
+	comparePairs(t, collectPairs(broadPhase(tree, bodies, 4)), collectPairs(BruteForceBroadPhase(bodies)))
+}
+
+func TestDynamicTree_IncrementalUpdate(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	bodies := createMixedBodies(rng, 100)
+	tree := NewDynamicTree(0.05)
+	tree.Update(bodies)
+
+	for frame := 0; frame < 20; frame++ {
+		// Some bodies move, within and out of their fat AABB
+		for _, body := range bodies[1:] {
+			if rng.Intn(3) == 0 {
+				offset := mgl64.Vec3{rng.NormFloat64(), rng.NormFloat64(), rng.NormFloat64()}.Mul(0.1 * float64(frame%4))
+				body.Transform.Position = body.Transform.Position.Add(offset)
+			}
+		}
+		// Some are removed, others are added
+		if frame%3 == 0 {
+			removed := 1 + rng.Intn(len(bodies)-1)
+			bodies = append(bodies[:removed], bodies[removed+1:]...)
+		}
+		if frame%4 == 0 {
+			bodies = append(bodies, createMixedBodies(rng, 3)[1:]...)
+		}
+
+		comparePairs(t, collectPairs(broadPhase(tree, bodies, 3)), collectPairs(BruteForceBroadPhase(bodies)))
+		if len(tree.leaves) != len(bodies)-1 || len(tree.proxies) != len(bodies)-1 {
+			t.Fatalf("frame %d: tree holds %d leaves for %d bodies", frame, len(tree.leaves), len(bodies)-1)
+		}
+	}
+}
+
+func TestDynamicTree_Balanced(t *testing.T) {
+	// Inserted in a line, an unbalanced tree would degenerate into a list
+	var bodies []*actor.RigidBody
+	for i := 0; i < 1024; i++ {
+		bodies = append(bodies, createSphere(mgl64.Vec3{float64(i) * 3, 0, 0}, 1, actor.BodyTypeDynamic))
+	}
+
+	tree := NewDynamicTree(0)
+	tree.Update(bodies)
+
+	// An AVL tree of n leaves is at most ~1.44*log2(n) high
+	if height := tree.Height(); height > int(1.44*math.Log2(1024))+1 {
+		t.Errorf("tree height = %d for 1024 leaves", height)
+	}
+
+	tree.Update(nil)
+	if tree.Height() != 0 || tree.root != nullNode || len(tree.proxies) != 0 {
+		t.Errorf("tree not emptied: height %d, %d proxies", tree.Height(), len(tree.proxies))
+	}
+}
+
+func TestDynamicTree_ReusesNodes(t *testing.T) {
+	bodies := []*actor.RigidBody{
+		createSphere(mgl64.Vec3{0, 0, 0}, 1, actor.BodyTypeDynamic),
+		createSphere(mgl64.Vec3{5, 0, 0}, 1, actor.BodyTypeDynamic),
+		createSphere(mgl64.Vec3{10, 0, 0}, 1, actor.BodyTypeDynamic),
+	}
+	tree := NewDynamicTree(0)
+	tree.Update(bodies)
+	nodes := len(tree.nodes)
+
+	for i := 0; i < 10; i++ {
+		for _, body := range bodies {
+			body.Transform.Position = body.Transform.Position.Add(mgl64.Vec3{0, 1, 0})
+		}
+		tree.Update(bodies)
+	}
+
+	if len(tree.nodes) != nodes {
+		t.Errorf("moved leaves allocated nodes: %d, want %d", len(tree.nodes), nodes)
+	}
+}
+
+func TestWorld_DynamicTreeQueries(t *testing.T) {
+	world := NewWorld(WithDynamicTree(0), WithGravity(mgl64.Vec3{}), WithSubsteps(1))
+	world.Workers = 1
+	world.BruteForceThreshold = -1
+
+	ground := createPlane(mgl64.Vec3{0, 1, 0}, 10)
+	pebble := createSphere(mgl64.Vec3{3, 0, 0}, 0.01, actor.BodyTypeStatic)
+	building := createBox(mgl64.Vec3{-60, 0, 0}, mgl64.Vec3{50, 50, 50}, actor.BodyTypeStatic)
+	for _, body := range []*actor.RigidBody{ground, pebble, building} {
+		world.AddBody(body)
+	}
+	world.Step(1.0 / 60.0)
+
+	if world.bruteForce {
+		t.Fatal("expected the DynamicTree to be used")
+	}
+
+	if body, _, found := world.ClosestBody(mgl64.Vec3{2, 0, 0}, 5, nil); !found || body != pebble {
+		t.Errorf("ClosestBody = %v, want the pebble", body)
+	}
+	if hit, found := world.Raycast(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{-1, 0, 0}, 100, nil); !found || hit.Body != building {
+		t.Errorf("Raycast = %+v, want the building", hit)
+	}
+	if hit, found := world.Raycast(mgl64.Vec3{3, 5, 0}, mgl64.Vec3{0, -1, 0}, 100, nil); !found || hit.Body != pebble {
+		t.Errorf("Raycast = %+v, want the pebble", hit)
+	}
+	if found := world.QueryAABB(actor.AABB{Min: mgl64.Vec3{2, -1, -1}, Max: mgl64.Vec3{4, 1, 1}}, nil); len(found) != 1 || found[0] != pebble {
+		t.Errorf("QueryAABB = %v, want the pebble", found)
+	}
+	if found := world.QueryPoint(mgl64.Vec3{0, -20, 0}, nil); len(found) != 1 || found[0] != ground {
+		t.Errorf("QueryPoint = %v, want the ground", found)
+	}
+}
+
+func TestWorld_DynamicTreeStep(t *testing.T) {
+	world := NewWorld(WithDynamicTree(0))
+	world.AddBody(createPlane(mgl64.Vec3{0, 1, 0}, 0))
+	sphere := createSphere(mgl64.Vec3{0, 2, 0}, 0.5, actor.BodyTypeDynamic)
+	world.AddBody(sphere)
+
+	for i := 0; i < 120; i++ {
+		world.Step(1.0 / 60.0)
+	}
+
+	if y := sphere.Transform.Position.Y(); math.Abs(y-0.5) > 0.05 {
+		t.Errorf("sphere at y = %v, want resting on the plane at 0.5", y)
+	}
+}
+
+func BenchmarkDynamicTreeMixedSizes(b *testing.B) {
+	rng := rand.New(rand.NewSource(0))
+	bodies := createMixedBodies(rng, 1000)
+	tree := NewDynamicTree(0)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for range broadPhase(tree, bodies, 4) {
+		}
+	}
+}
+
+func BenchmarkSpatialGridMixedSizes(b *testing.B) {
+	rng := rand.New(rand.NewSource(0))
+	bodies := createMixedBodies(rng, 1000)
+	grid := NewSpatialGrid(1.0, 4096)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for range broadPhase(grid, bodies, 4) {
+		}
+	}
+}
+
+func TestDynamicTree_DeterministicOrder(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+	bodies := createMixedBodies(rng, 1000)
+	tree := NewDynamicTree(0)
+	tree.Update(bodies)
+
+	var reference []Pair
+	for pair := range tree.FindPairs(bodies, 1) {
+		reference = append(reference, pair)
+	}
+	var pairs []Pair
+	for pair := range tree.FindPairs(bodies, 8) {
+		pairs = append(pairs, pair)
+	}
+
+	if len(pairs) != len(reference) {
+		t.Fatalf("8 workers found %d pairs, want %d", len(pairs), len(reference))
+	}
+	for i := range pairs {
+		if pairs[i] != reference[i] {
+			t.Fatalf("pair %d differs from the single worker order", i)
+		}
+	}
+}
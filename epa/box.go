@@ -0,0 +1,136 @@
+package epa
+
+import (
+	"math"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+const (
+	// boxEdgeAxisEpsilon discards edge-edge axes built from (nearly) parallel edges.
+	// Their cross product is too short to be normalized reliably.
+	boxEdgeAxisEpsilon = 1e-6
+
+	// boxFaceAxisBias favors face axes over edge-edge axes of similar depth.
+	// Face contacts produce stable 4-point manifolds, edge contacts only 1 or 2 points,
+	// so an edge axis must be clearly better before it is selected.
+	boxFaceAxisBias = 0.95
+
+	// boxFaceAxisTolerance is the absolute tolerance added to the face axis bias.
+	boxFaceAxisTolerance = 1e-5
+)
+
+// boxBoxPenetration computes the exact minimum translation vector between two oriented boxes
+// using the Separating Axis Theorem (SAT).
+//
+// EPA converges poorly on very elongated boxes (aspect ratio > 100, e.g. poles, beams, planks):
+// the Minkowski difference becomes a thin sliver and the polytope faces close to the origin
+// are nearly degenerate, which gives tilted normals. SAT does not depend on the aspect ratio,
+// it tests the 15 candidate axes of two boxes:
+//   - 3 face normals of A
+//   - 3 face normals of B
+//   - 9 cross products of the edges of A and B
+//
+// Returns:
+//   - normal: Contact normal pointing from body A toward body B
+//   - depth: Penetration depth along the normal (always positive)
+//   - ok: false if the boxes are separated on at least one axis
+func boxBoxPenetration(bodyA, bodyB *actor.RigidBody, boxA, boxB *actor.Box) (mgl64.Vec3, float64, bool) {
+	axesA := boxAxes(bodyA.Transform)
+	axesB := boxAxes(bodyB.Transform)
+	centerDelta := bodyB.Transform.Position.Sub(bodyA.Transform.Position)
+
+	bestDepth := math.Inf(1)
+	var bestAxis mgl64.Vec3
+
+	// testAxis projects both boxes onto a normalized axis, returns false on separation
+	testAxis := func(axis mgl64.Vec3) (float64, bool) {
+		radiusA := boxProjectedRadius(boxA.HalfExtents, axesA, axis)
+		radiusB := boxProjectedRadius(boxB.HalfExtents, axesB, axis)
+		depth := radiusA + radiusB - math.Abs(centerDelta.Dot(axis))
+
+		return depth, depth >= 0
+	}
+
+	// Face axes
+	for _, axes := range [2][3]mgl64.Vec3{axesA, axesB} {
+		for _, axis := range axes {
+			depth, overlap := testAxis(axis)
+			if !overlap {
+				return mgl64.Vec3{}, 0, false
+			}
+			if depth < bestDepth {
+				bestDepth = depth
+				bestAxis = axis
+			}
+		}
+	}
+
+	// Edge-edge axes
+	faceDepth := bestDepth
+	for _, edgeA := range axesA {
+		for _, edgeB := range axesB {
+			axis := edgeA.Cross(edgeB)
+			length := axis.Len()
+			if length < boxEdgeAxisEpsilon {
+				continue
+			}
+			axis = axis.Mul(1.0 / length)
+
+			depth, overlap := testAxis(axis)
+			if !overlap {
+				return mgl64.Vec3{}, 0, false
+			}
+			if depth < bestDepth && depth < boxFaceAxisBias*faceDepth-boxFaceAxisTolerance {
+				bestDepth = depth
+				bestAxis = axis
+			}
+		}
+	}
+
+	// Orient the normal from A toward B
+	if centerDelta.Dot(bestAxis) < 0 {
+		bestAxis = bestAxis.Mul(-1)
+	}
+
+	return snapNormalToAxis(bestAxis), bestDepth, true
+}
+
+// BoxPenetration computes the exact contact of two boxes by the separating axis test, without GJK and EPA.
+// The contact points are then given by GenerateManifold.
+//
+// Returns:
+//   - normal: Contact normal pointing from body A toward body B
+//   - depth: Penetration depth along the normal (always positive)
+//   - ok: false if the boxes are separated or only touching, or if a body is not a box
+func BoxPenetration(bodyA, bodyB *actor.RigidBody) (mgl64.Vec3, float64, bool) {
+	boxA, okA := bodyA.Shape.(*actor.Box)
+	boxB, okB := bodyB.Shape.(*actor.Box)
+	if !okA || !okB {
+		return mgl64.Vec3{}, 0, false
+	}
+
+	normal, depth, ok := boxBoxPenetration(bodyA, bodyB, boxA, boxB)
+	if !ok || depth <= 0 {
+		return mgl64.Vec3{}, 0, false
+	}
+
+	return normal, depth, true
+}
+
+// boxAxes returns the 3 world-space axes of a box with the given transform
+func boxAxes(transform actor.Transform) [3]mgl64.Vec3 {
+	return [3]mgl64.Vec3{
+		transform.Rotation.Rotate(mgl64.Vec3{1, 0, 0}),
+		transform.Rotation.Rotate(mgl64.Vec3{0, 1, 0}),
+		transform.Rotation.Rotate(mgl64.Vec3{0, 0, 1}),
+	}
+}
+
+// boxProjectedRadius returns the half-length of the projection of a box onto an axis
+func boxProjectedRadius(halfExtents mgl64.Vec3, axes [3]mgl64.Vec3, axis mgl64.Vec3) float64 {
+	return halfExtents.X()*math.Abs(axes[0].Dot(axis)) +
+		halfExtents.Y()*math.Abs(axes[1].Dot(axis)) +
+		halfExtents.Z()*math.Abs(axes[2].Dot(axis))
+}
@@ -0,0 +1,130 @@
+package epa
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/akmonengine/feather/gjk"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func newBoxBody(position mgl64.Vec3, rotation mgl64.Quat, halfExtents mgl64.Vec3) *actor.RigidBody {
+	return &actor.RigidBody{
+		Shape: &actor.Box{HalfExtents: halfExtents},
+		Transform: actor.Transform{
+			Position:        position,
+			Rotation:        rotation,
+			InverseRotation: rotation.Inverse(),
+		},
+	}
+}
+
+func TestBoxBoxPenetration(t *testing.T) {
+	tests := []struct {
+		name           string
+		bodyA          *actor.RigidBody
+		bodyB          *actor.RigidBody
+		expectedNormal mgl64.Vec3
+		expectedDepth  float64
+	}{
+		{
+			name:           "stacked_cubes",
+			bodyA:          newBoxBody(mgl64.Vec3{0, 0, 0}, mgl64.QuatIdent(), mgl64.Vec3{1, 1, 1}),
+			bodyB:          newBoxBody(mgl64.Vec3{0, 1.9, 0}, mgl64.QuatIdent(), mgl64.Vec3{1, 1, 1}),
+			expectedNormal: mgl64.Vec3{0, 1, 0},
+			expectedDepth:  0.1,
+		},
+		{
+			name:           "plank_on_ground_box",
+			bodyA:          newBoxBody(mgl64.Vec3{0, -1, 0}, mgl64.QuatIdent(), mgl64.Vec3{100, 1, 100}),
+			bodyB:          newBoxBody(mgl64.Vec3{3, 0.04, 0}, mgl64.QuatIdent(), mgl64.Vec3{20, 0.05, 0.1}),
+			expectedNormal: mgl64.Vec3{0, 1, 0},
+			expectedDepth:  0.01,
+		},
+		{
+			name:           "rotated_pole_on_ground_box",
+			bodyA:          newBoxBody(mgl64.Vec3{0, -1, 0}, mgl64.QuatIdent(), mgl64.Vec3{100, 1, 100}),
+			bodyB:          newBoxBody(mgl64.Vec3{0, 0.09, 0}, mgl64.QuatRotate(math.Pi/3, mgl64.Vec3{0, 1, 0}), mgl64.Vec3{30, 0.1, 0.1}),
+			expectedNormal: mgl64.Vec3{0, 1, 0},
+			expectedDepth:  0.01,
+		},
+		{
+			name:           "normal_from_b_side",
+			bodyA:          newBoxBody(mgl64.Vec3{0, 0, 0}, mgl64.QuatIdent(), mgl64.Vec3{1, 1, 1}),
+			bodyB:          newBoxBody(mgl64.Vec3{-1.8, 0, 0}, mgl64.QuatIdent(), mgl64.Vec3{1, 1, 1}),
+			expectedNormal: mgl64.Vec3{-1, 0, 0},
+			expectedDepth:  0.2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normal, depth, ok := boxBoxPenetration(tt.bodyA, tt.bodyB, tt.bodyA.Shape.(*actor.Box), tt.bodyB.Shape.(*actor.Box))
+			if !ok {
+				t.Fatal("expected boxes to overlap")
+			}
+			if !vec3ApproxEqual(normal, tt.expectedNormal, 1e-9) {
+				t.Errorf("normal = %v, want %v", normal, tt.expectedNormal)
+			}
+			if math.Abs(depth-tt.expectedDepth) > 1e-9 {
+				t.Errorf("depth = %v, want %v", depth, tt.expectedDepth)
+			}
+		})
+	}
+}
+
+func TestBoxBoxPenetration_Separated(t *testing.T) {
+	bodyA := newBoxBody(mgl64.Vec3{0, 0, 0}, mgl64.QuatIdent(), mgl64.Vec3{1, 1, 1})
+	// Corner-to-corner AABB overlap, but separated along the rotated face axis
+	bodyB := newBoxBody(mgl64.Vec3{2.2, 2.2, 0}, mgl64.QuatRotate(math.Pi/4, mgl64.Vec3{0, 0, 1}), mgl64.Vec3{1, 1, 1})
+
+	if _, _, ok := boxBoxPenetration(bodyA, bodyB, bodyA.Shape.(*actor.Box), bodyB.Shape.(*actor.Box)); ok {
+		t.Error("expected separated boxes")
+	}
+}
+
+func TestEPA_LongThinBox(t *testing.T) {
+	ground := newBoxBody(mgl64.Vec3{0, -1, 0}, mgl64.QuatIdent(), mgl64.Vec3{100, 1, 100})
+	beam := newBoxBody(mgl64.Vec3{5, 0.045, -2}, mgl64.QuatRotate(0.3, mgl64.Vec3{0, 1, 0}), mgl64.Vec3{25, 0.05, 0.05})
+
+	simplex := &gjk.Simplex{}
+	if !gjk.GJK(ground, beam, simplex) {
+		t.Fatal("GJK did not detect collision")
+	}
+
+	result, err := EPA(ground, beam, simplex)
+	if err != nil {
+		t.Fatalf("EPA failed: %v", err)
+	}
+
+	if !vec3ApproxEqual(result.Normal, mgl64.Vec3{0, 1, 0}, 1e-9) {
+		t.Errorf("normal = %v, want {0 1 0}", result.Normal)
+	}
+	if len(result.Points) != 4 {
+		t.Errorf("expected 4 contact points for a beam lying flat, got %d", len(result.Points))
+	}
+	for _, point := range result.Points {
+		if math.Abs(point.Penetration-0.005) > 1e-9 {
+			t.Errorf("penetration = %v, want 0.005", point.Penetration)
+		}
+	}
+}
+
+func TestEPA_TouchingBoxes(t *testing.T) {
+	// A box straddling two others, rotated by a few 1e-5 rad: GJK reports a degenerate overlap, SAT a separation
+	lower := newBoxBody(mgl64.Vec3{-0.5251379437351005, 0.4998579634669234, 0},
+		mgl64.Quat{W: 0.9999999975905224, V: mgl64.Vec3{0, 0, -6.941869473474e-05}}, mgl64.Vec3{0.5, 0.5, 0.5})
+	upper := newBoxBody(mgl64.Vec3{9.790622958689284e-05, 1.4998129786593204, 0},
+		mgl64.Quat{W: 0.9999999986580899, V: mgl64.Vec3{0, 0, -5.1805601722750487e-05}}, mgl64.Vec3{0.5, 0.5, 0.5})
+
+	simplex := &gjk.Simplex{}
+	if !gjk.GJK(lower, upper, simplex) {
+		t.Skip("GJK reports the boxes separated, nothing to check")
+	}
+
+	if _, err := EPA(lower, upper, simplex); !errors.Is(err, ErrSeparated) {
+		t.Errorf("err = %v, want ErrSeparated", err)
+	}
+}
@@ -17,6 +17,7 @@
 package epa
 
 import (
+	"errors"
 	"fmt"
 	"math"
 
@@ -56,6 +57,14 @@ const (
 	polytopeInitialCapacity = 4
 )
 
+// ErrWithinMargin is returned when the shapes only overlap by their collision margins:
+// they are separated, or touching, without penetration
+var ErrWithinMargin = errors.New("epa: shapes overlap within their collision margins")
+
+// ErrSeparated is returned when the exact test of a pair (SAT for two boxes) finds a separating axis:
+// GJK reported an overlap of shapes only touching within the floating point precision
+var ErrSeparated = errors.New("epa: shapes are separated")
+
 // EPA computes penetration depth and contact information for overlapping convex shapes.
 //
 // Algorithm overview:
@@ -78,8 +87,41 @@ const (
 // The contact normal points from body A toward body B (separation direction).
 // Penetration depth is always positive (how far to move B away from A).
 func EPA(a, b *actor.RigidBody, simplex *gjk.Simplex) (constraint.ContactConstraint, error) {
+	// Box pairs use the exact SAT solution: EPA normals degrade on long thin boxes
+	if boxA, ok := a.Shape.(*actor.Box); ok {
+		if boxB, ok := b.Shape.(*actor.Box); ok {
+			normal, depth, ok := boxBoxPenetration(a, b, boxA, boxB)
+			if !ok {
+				// The degenerate simplex of touching boxes would give an arbitrary deep contact
+				if gjk.Margin(a, b) > 0 {
+					return constraint.ContactConstraint{}, ErrWithinMargin
+				}
+				return constraint.ContactConstraint{}, ErrSeparated
+			}
+
+			return constraint.ContactConstraint{
+				BodyA:  a,
+				BodyB:  b,
+				Points: GenerateManifold(a, b, normal, depth),
+				Normal: normal,
+			}, nil
+		}
+	}
+
+	// Box-sphere pairs use the closest point of the box: the EPA polytope misplaces the contacts near the edges
+	if contact, ok := boxSphereContact(a, b); ok {
+		return contact, nil
+	}
+
+	// The polytope is built from the shapes inflated by their collision margins, see gjk.MinkowskiSupport
+	margin := gjk.Margin(a, b)
+
 	// If simplex is too small (degenerate case), create a minimal contact
 	if simplex.Count < 4 {
+		if margin > 0 {
+			// The shapes are only touching by their margins: GJK found the origin on the inflated boundary
+			return constraint.ContactConstraint{}, ErrWithinMargin
+		}
 		return handleDegenerateSimplex(a, b, simplex), nil
 	}
 
@@ -126,8 +168,13 @@ func EPA(a, b *actor.RigidBody, simplex *gjk.Simplex) (constraint.ContactConstra
 		// If the new support point doesn't significantly improve the distance,
 		// we've found the face of the Minkowski difference closest to the origin
 		if distance-closestFace.Distance < EPAConvergenceTolerance {
+			depth := closestFace.Distance - margin
+			if depth <= 0 {
+				return constraint.ContactConstraint{}, ErrWithinMargin
+			}
+
 			// Generate contact manifold (multiple contact points for stability)
-			manifoldPoints := GenerateManifold(a, b, closestFace.Normal, closestFace.Distance)
+			manifoldPoints := GenerateManifold(a, b, closestFace.Normal, depth)
 
 			return constraint.ContactConstraint{
 				BodyA:  a,
@@ -142,7 +189,11 @@ func EPA(a, b *actor.RigidBody, simplex *gjk.Simplex) (constraint.ContactConstra
 		// Zero allocations - all operations use fixed buffers
 		if err := builder.AddPointAndRebuildFaces(support, closestFaceIndex); err != nil {
 			// Buffer overflow - return current best estimate instead of failing
-			manifoldPoints := GenerateManifold(a, b, closestFace.Normal, closestFace.Distance)
+			depth := closestFace.Distance - margin
+			if depth <= 0 {
+				return constraint.ContactConstraint{}, ErrWithinMargin
+			}
+			manifoldPoints := GenerateManifold(a, b, closestFace.Normal, depth)
 			return constraint.ContactConstraint{
 				BodyA:  a,
 				BodyB:  b,
@@ -1,6 +1,7 @@
 package epa
 
 import (
+	"errors"
 	"math"
 	"testing"
 
@@ -165,6 +166,67 @@ func TestHandleDegenerateSimplex(t *testing.T) {
 	})
 }
 
+// newHullCube returns a cube convex hull body with the given margin, EPA runs its polytope (no SAT) on it
+func newHullCube(t *testing.T, position mgl64.Vec3, margin float64) *actor.RigidBody {
+	t.Helper()
+	var points []mgl64.Vec3
+	for _, x := range []float64{-1, 1} {
+		for _, y := range []float64{-1, 1} {
+			for _, z := range []float64{-1, 1} {
+				points = append(points, mgl64.Vec3{x, y, z})
+			}
+		}
+	}
+	hull, err := actor.NewConvexHull(points)
+	if err != nil {
+		t.Fatalf("NewConvexHull failed: %v", err)
+	}
+	hull.SetMargin(margin)
+
+	return &actor.RigidBody{
+		Shape:     hull,
+		Transform: actor.Transform{Position: position, Rotation: mgl64.QuatIdent(), InverseRotation: mgl64.QuatIdent()},
+	}
+}
+
+// TestEPA_Margin checks the margins are removed from the depth found on the inflated shapes
+func TestEPA_Margin(t *testing.T) {
+	t.Run("penetrating", func(t *testing.T) {
+		a := newHullCube(t, mgl64.Vec3{0, 0, 0}, 0.04)
+		b := newHullCube(t, mgl64.Vec3{0, 1.9, 0}, 0.04)
+		simplex := &gjk.Simplex{}
+		if !gjk.GJK(a, b, simplex) {
+			t.Fatal("expected a collision")
+		}
+
+		contact, err := EPA(a, b, simplex)
+		if err != nil {
+			t.Fatalf("EPA failed: %v", err)
+		}
+		if !vec3ApproxEqual(contact.Normal, mgl64.Vec3{0, 1, 0}, 1e-6) {
+			t.Errorf("normal = %v, want {0, 1, 0}", contact.Normal)
+		}
+		for _, point := range contact.Points {
+			if math.Abs(point.Penetration-0.1) > EPAConvergenceTolerance {
+				t.Errorf("penetration = %v, want 0.1 without the margins", point.Penetration)
+			}
+		}
+	})
+
+	t.Run("within_margins", func(t *testing.T) {
+		a := newHullCube(t, mgl64.Vec3{0, 0, 0}, 0.04)
+		b := newHullCube(t, mgl64.Vec3{0, 2.01, 0}, 0.04)
+		simplex := &gjk.Simplex{}
+		if !gjk.GJK(a, b, simplex) {
+			t.Fatal("expected the inflated shapes to collide")
+		}
+
+		if _, err := EPA(a, b, simplex); !errors.Is(err, ErrWithinMargin) {
+			t.Errorf("EPA error = %v, want ErrWithinMargin", err)
+		}
+	})
+}
+
 // TestEPA tests the main EPA function
 func TestEPA(t *testing.T) {
 	t.Run("convergence_success", func(t *testing.T) {
@@ -50,6 +50,17 @@ type ManifoldBuilder struct {
 	clipBuffer2   [maxBufferSize]mgl64.Vec3
 	tempPoints    [maxBufferSize]constraint.ContactPoint
 
+	// Elements of the features each clipped point lies on, along clipBuffer1 and clipBuffer2
+	clipTags1 [maxBufferSize]pointTag
+	clipTags2 [maxBufferSize]pointTag
+
+	// Features of the shapes, and which one is the incident feature, to build the constraint.FeatureID of the points
+	featureA       uint32
+	featureB       uint32
+	incidentB      bool
+	incidentCount  int
+	referenceCount int
+
 	// Counters
 	localFeatureACount int
 	localFeatureBCount int
@@ -61,6 +72,13 @@ type ManifoldBuilder struct {
 	tempPointsCount    int
 }
 
+// pointTag is the element of the incident and of the reference features a clipped point lies on:
+// constraint.FeatureVertex or constraint.FeatureEdge with the index of the element, 0 for the inside of the feature
+type pointTag struct {
+	incident  uint8
+	reference uint8
+}
+
 // Pool of builders for reuse
 var manifoldBuilderPool = sync.Pool{
 	New: func() interface{} {
@@ -103,12 +121,16 @@ func (b *ManifoldBuilder) Generate(bodyA, bodyB *actor.RigidBody, normal mgl64.V
 	// Transform into buffers
 	b.transformFeature(&b.localFeatureA, b.localFeatureACount, bodyA.Transform, bodyA.Shape, &b.worldFeatureA, &b.worldFeatureACount)
 	b.transformFeature(&b.localFeatureB, b.localFeatureBCount, bodyB.Transform, bodyB.Shape, &b.worldFeatureB, &b.worldFeatureBCount)
+	b.featureA = featureIndex(bodyA.Shape, localNormalA)
+	b.featureB = featureIndex(bodyB.Shape, localNormalB)
 
 	// Determine incident and reference
 	var incident *[8]mgl64.Vec3
 	var incidentCount int
 	var reference *[8]mgl64.Vec3
 	var referenceCount int
+	// Outward normal of the reference feature: the normal goes from A to B, a reference on B faces -normal
+	referenceNormal := normal
 
 	if b.worldFeatureBCount <= b.worldFeatureACount {
 		incident = &b.worldFeatureB
@@ -120,13 +142,16 @@ func (b *ManifoldBuilder) Generate(bodyA, bodyB *actor.RigidBody, normal mgl64.V
 		incidentCount = b.worldFeatureACount
 		reference = &b.worldFeatureB
 		referenceCount = b.worldFeatureBCount
+		referenceNormal = normal.Mul(-1)
 	}
+	b.incidentB = incident == &b.worldFeatureB
 
 	// Trivial case: single incident point
 	if incidentCount == 1 {
 		b.tempPoints[0] = constraint.ContactPoint{
 			Position:    incident[0],
 			Penetration: depth,
+			Feature:     b.featureID(pointTag{incident: constraint.FeatureVertex}),
 		}
 		b.tempPointsCount = 1
 		return b.buildResult()
@@ -135,14 +160,32 @@ func (b *ManifoldBuilder) Generate(bodyA, bodyB *actor.RigidBody, normal mgl64.V
 	// Clip incident against reference
 	clippedCount := b.clipIncidentAgainstReference(incident, incidentCount, reference, referenceCount, normal)
 
-	// Final clip against reference plane
-	if clippedCount > 0 && referenceCount > 0 {
-		b.clipAgainstReferencePlane(clippedCount, reference, referenceCount, normal, depth)
+	// Final clip against reference plane, a segment (e.g. the side of a cone) has no plane:
+	// the clipped points, on its line, are kept
+	if clippedCount > 0 && referenceCount > 2 {
+		b.clipAgainstReferencePlane(clippedCount, reference, referenceCount, referenceNormal, depth)
+	} else {
+		b.keepDistinctPoints(clippedCount, depth)
 	}
 
-	// Fallback
+	// Fallback: the deepest point of B, unless it is outside of A. The support of a large face is any
+	// of its corners (e.g. a box around a small cone), the deepest point of A is then used instead.
+	// The contact is finally kept within the overlap of the bounds of both bodies (e.g. two flat hulls).
 	if b.tempPointsCount == 0 {
+		inflation := depth + actor.ShapeMargin(bodyA.Shape) + actor.ShapeMargin(bodyB.Shape)
+		boundsA := inflateAABB(bodyA.Shape.GetAABB(), inflation)
+		boundsB := inflateAABB(bodyB.Shape.GetAABB(), inflation)
+
 		deepest := bodyB.SupportWorld(normal.Mul(-1))
+		if !boundsA.ContainsPoint(deepest) {
+			deepest = bodyA.SupportWorld(normal)
+		}
+		for i := 0; i < 3; i++ {
+			low, high := math.Max(boundsA.Min[i], boundsB.Min[i]), math.Min(boundsA.Max[i], boundsB.Max[i])
+			if low <= high {
+				deepest[i] = math.Max(low, math.Min(deepest[i], high))
+			}
+		}
 		b.tempPoints[0] = constraint.ContactPoint{
 			Position:    deepest,
 			Penetration: depth,
@@ -158,6 +201,35 @@ func (b *ManifoldBuilder) Generate(bodyA, bodyB *actor.RigidBody, normal mgl64.V
 	return b.buildResult()
 }
 
+// featureIndex returns the index of the contact feature of the shape for the local direction, see actor.FeatureIndexer
+func featureIndex(shape actor.ShapeInterface, direction mgl64.Vec3) uint32 {
+	if indexer, ok := shape.(actor.FeatureIndexer); ok {
+		return uint32(indexer.FeatureIndex(direction))
+	}
+
+	return 0
+}
+
+// featureID returns the identifier of a point lying on the elements of the tag
+func (b *ManifoldBuilder) featureID(tag pointTag) constraint.FeatureID {
+	feature := constraint.FeatureID{FaceA: b.featureA, FaceB: b.featureB}
+	if b.incidentB {
+		feature.ElementA, feature.ElementB = tag.reference, tag.incident
+	} else {
+		feature.ElementA, feature.ElementB = tag.incident, tag.reference
+	}
+
+	return feature
+}
+
+// inflateAABB grows the bounds by distance on every side
+func inflateAABB(aabb actor.AABB, distance float64) actor.AABB {
+	return actor.AABB{
+		Min: aabb.Min.Sub(mgl64.Vec3{distance, distance, distance}),
+		Max: aabb.Max.Add(mgl64.Vec3{distance, distance, distance}),
+	}
+}
+
 // transformFeature transforms features to world space
 func (b *ManifoldBuilder) transformFeature(input *[8]mgl64.Vec3, inputCount int, transform actor.Transform, shape actor.ShapeInterface, output *[8]mgl64.Vec3, outputCount *int) {
 	*outputCount = 0
@@ -173,10 +245,13 @@ func (b *ManifoldBuilder) transformFeature(input *[8]mgl64.Vec3, inputCount int,
 // clipIncidentAgainstReference clips the incident feature against the reference feature.
 // Always returns the result in clipBuffer1 for consistent downstream consumption.
 func (b *ManifoldBuilder) clipIncidentAgainstReference(incident *[8]mgl64.Vec3, incidentCount int, reference *[8]mgl64.Vec3, referenceCount int, normal mgl64.Vec3) int {
+	b.incidentCount, b.referenceCount = incidentCount, referenceCount
+
 	// Handle insufficient reference (need at least 2 points for edges)
 	if referenceCount < 2 {
 		for i := 0; i < incidentCount; i++ {
 			b.clipBuffer1[i] = incident[i]
+			b.clipTags1[i] = pointTag{incident: constraint.FeatureVertex | uint8(i)}
 		}
 		b.clipBuffer1Count = incidentCount
 		return incidentCount
@@ -185,6 +260,7 @@ func (b *ManifoldBuilder) clipIncidentAgainstReference(incident *[8]mgl64.Vec3,
 	// Copy incident to clipBuffer1
 	for i := 0; i < incidentCount; i++ {
 		b.clipBuffer1[i] = incident[i]
+		b.clipTags1[i] = pointTag{incident: constraint.FeatureVertex | uint8(i)}
 	}
 	b.clipBuffer1Count = incidentCount
 	b.clipBuffer2Count = 0
@@ -194,19 +270,21 @@ func (b *ManifoldBuilder) clipIncidentAgainstReference(incident *[8]mgl64.Vec3,
 	// Clip against each edge
 	for i := 0; i < referenceCount; i++ {
 		var inputBuffer *[8]mgl64.Vec3
+		var inputTags *[8]pointTag
 		var inputCount int
 		var outputBuffer *[8]mgl64.Vec3
+		var outputTags *[8]pointTag
 		var outputCount *int
 
 		if useBuffer1 {
-			inputBuffer = &b.clipBuffer1
+			inputBuffer, inputTags = &b.clipBuffer1, &b.clipTags1
 			inputCount = b.clipBuffer1Count
-			outputBuffer = &b.clipBuffer2
+			outputBuffer, outputTags = &b.clipBuffer2, &b.clipTags2
 			outputCount = &b.clipBuffer2Count
 		} else {
-			inputBuffer = &b.clipBuffer2
+			inputBuffer, inputTags = &b.clipBuffer2, &b.clipTags2
 			inputCount = b.clipBuffer2Count
-			outputBuffer = &b.clipBuffer1
+			outputBuffer, outputTags = &b.clipBuffer1, &b.clipTags1
 			outputCount = &b.clipBuffer1Count
 		}
 
@@ -230,15 +308,18 @@ func (b *ManifoldBuilder) clipIncidentAgainstReference(incident *[8]mgl64.Vec3,
 
 		clipNormal := edgeCrossNormal.Mul(1.0 / edgeCrossLen)
 
-		// Verify direction
-		center := b.computeCenter(reference, referenceCount)
-		toCenter := center.Sub(v1)
-		if toCenter.Dot(clipNormal) < 0 {
-			clipNormal = clipNormal.Mul(-1)
+		// Verify direction. The center of a segment is on its line, the test is then left to rounding:
+		// the reversed second edge already gives the opposite plane
+		if referenceCount > 2 {
+			center := b.computeCenter(reference, referenceCount)
+			toCenter := center.Sub(v1)
+			if toCenter.Dot(clipNormal) < 0 {
+				clipNormal = clipNormal.Mul(-1)
+			}
 		}
 
 		// Clip
-		b.clipPolygonAgainstPlane(inputBuffer, inputCount, v1, clipNormal, outputBuffer, outputCount)
+		b.clipPolygonAgainstPlane(inputBuffer, inputTags, inputCount, v1, clipNormal, constraint.FeatureEdge|uint8(i), outputBuffer, outputTags, outputCount)
 
 		useBuffer1 = !useBuffer1
 	}
@@ -253,15 +334,32 @@ func (b *ManifoldBuilder) clipIncidentAgainstReference(incident *[8]mgl64.Vec3,
 		finalCount = b.clipBuffer2Count
 		for i := 0; i < finalCount; i++ {
 			b.clipBuffer1[i] = b.clipBuffer2[i]
+			b.clipTags1[i] = b.clipTags2[i]
 		}
 		b.clipBuffer1Count = finalCount
 	}
 
+	// A segment reference (e.g. the side of a cone) also bounds the points along its length:
+	// a parallel incident segment is otherwise kept whole
+	if referenceCount == 2 && finalCount > 0 {
+		direction := reference[1].Sub(reference[0])
+		if length := direction.Len(); length > epsilonDistance {
+			direction = direction.Mul(1.0 / length)
+			b.clipPolygonAgainstPlane(&b.clipBuffer1, &b.clipTags1, b.clipBuffer1Count, reference[0], direction, constraint.FeatureVertex,
+				&b.clipBuffer2, &b.clipTags2, &b.clipBuffer2Count)
+			b.clipPolygonAgainstPlane(&b.clipBuffer2, &b.clipTags2, b.clipBuffer2Count, reference[1], direction.Mul(-1), constraint.FeatureVertex|1,
+				&b.clipBuffer1, &b.clipTags1, &b.clipBuffer1Count)
+			finalCount = b.clipBuffer1Count
+		}
+	}
+
 	return finalCount
 }
 
-// clipPolygonAgainstPlane clips a polygon against a plane using the Sutherland-Hodgman algorithm
-func (b *ManifoldBuilder) clipPolygonAgainstPlane(input *[8]mgl64.Vec3, inputCount int, planePoint, planeNormal mgl64.Vec3, output *[8]mgl64.Vec3, outputCount *int) {
+// clipPolygonAgainstPlane clips a polygon against a plane using the Sutherland-Hodgman algorithm.
+// plane is the element of the reference feature bounded by the plane, the tag of the intersections.
+func (b *ManifoldBuilder) clipPolygonAgainstPlane(input *[8]mgl64.Vec3, inputTags *[8]pointTag, inputCount int, planePoint, planeNormal mgl64.Vec3, plane uint8,
+	output *[8]mgl64.Vec3, outputTags *[8]pointTag, outputCount *int) {
 	if inputCount == 0 {
 		*outputCount = 0
 		return
@@ -279,26 +377,104 @@ func (b *ManifoldBuilder) clipPolygonAgainstPlane(input *[8]mgl64.Vec3, inputCou
 		if currentDist >= -epsilonDistance {
 			if *outputCount < maxBufferSize {
 				output[*outputCount] = current
+				outputTags[*outputCount] = inputTags[i]
 				*outputCount++
 			}
 
 			if nextDist < -epsilonDistance && *outputCount < maxBufferSize {
 				intersection := lineIntersectPlane(current, next, planePoint, planeNormal)
 				output[*outputCount] = intersection
+				outputTags[*outputCount] = b.intersectionTag(inputTags[i], inputTags[(i+1)%inputCount], plane)
 				*outputCount++
 			}
 		} else {
 			if nextDist >= -epsilonDistance && *outputCount < maxBufferSize {
 				intersection := lineIntersectPlane(current, next, planePoint, planeNormal)
 				output[*outputCount] = intersection
+				outputTags[*outputCount] = b.intersectionTag(inputTags[i], inputTags[(i+1)%inputCount], plane)
 				*outputCount++
 			}
 		}
 	}
 }
 
-// clipAgainstReferencePlane performs final clipping against the reference plane.
-// Reads from clipBuffer1 and writes results to tempPoints.
+// intersectionTag returns the tag of the intersection of the segment between two clipped points with the plane
+// bounding the reference element. The segment is an edge of the incident feature if they share one, otherwise
+// it lies on the plane of a previous reference edge: the intersection is the corner between both reference edges.
+func (b *ManifoldBuilder) intersectionTag(current, next pointTag, plane uint8) pointTag {
+	currentEdges, currentCount := elementEdges(current.incident, b.incidentCount)
+	nextEdges, nextCount := elementEdges(next.incident, b.incidentCount)
+	for _, edge := range currentEdges[:currentCount] {
+		for _, other := range nextEdges[:nextCount] {
+			if edge == other {
+				return pointTag{incident: constraint.FeatureEdge | edge, reference: plane}
+			}
+		}
+	}
+
+	side := current.reference
+	if side&constraint.FeatureEdge == 0 {
+		side = next.reference
+	}
+	if side&constraint.FeatureEdge != 0 && plane&constraint.FeatureEdge != 0 {
+		previous, edge := side&^constraint.FeatureEdge, plane&^constraint.FeatureEdge
+		count := uint8(max(b.referenceCount, 1))
+		switch {
+		case (previous+1)%count == edge:
+			return pointTag{reference: constraint.FeatureVertex | edge}
+		case (edge+1)%count == previous:
+			return pointTag{reference: constraint.FeatureVertex | previous}
+		}
+	}
+
+	return pointTag{reference: plane}
+}
+
+// elementEdges returns the edges of a feature of count vertices touching the element: both edges of a vertex, or the edge itself
+func elementEdges(element uint8, count int) ([2]uint8, int) {
+	index := element &^ (constraint.FeatureVertex | constraint.FeatureEdge)
+	switch {
+	case count == 0:
+		return [2]uint8{}, 0
+	case element&constraint.FeatureVertex != 0:
+		return [2]uint8{(index + uint8(count) - 1) % uint8(count), index}, 2
+	case element&constraint.FeatureEdge != 0:
+		return [2]uint8{index}, 1
+	}
+
+	return [2]uint8{}, 0
+}
+
+// keepDistinctPoints copies the clipped points from clipBuffer1 to tempPoints, merging the duplicates:
+// crossing segments are clipped to their intersection, once per clipping plane
+func (b *ManifoldBuilder) keepDistinctPoints(clippedCount int, depth float64) {
+	b.tempPointsCount = 0
+
+	for i := 0; i < clippedCount; i++ {
+		point := b.clipBuffer1[i]
+
+		duplicate := false
+		for j := 0; j < b.tempPointsCount; j++ {
+			if b.tempPoints[j].Position.Sub(point).Len() < epsilonDistance {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+
+		b.tempPoints[b.tempPointsCount] = constraint.ContactPoint{
+			Position:    point,
+			Penetration: depth,
+			Feature:     b.featureID(b.clipTags1[i]),
+		}
+		b.tempPointsCount++
+	}
+}
+
+// clipAgainstReferencePlane performs final clipping against the reference plane, normal is the outward normal
+// of the reference feature: the points behind it are kept. Reads from clipBuffer1 and writes results to tempPoints.
 func (b *ManifoldBuilder) clipAgainstReferencePlane(clippedCount int, reference *[8]mgl64.Vec3, referenceCount int, normal mgl64.Vec3, depth float64) {
 	b.tempPointsCount = 0
 
@@ -323,6 +499,7 @@ func (b *ManifoldBuilder) clipAgainstReferencePlane(clippedCount int, reference
 			b.tempPoints[b.tempPointsCount] = constraint.ContactPoint{
 				Position:    point,
 				Penetration: depth,
+				Feature:     b.featureID(b.clipTags1[i]),
 			}
 			b.tempPointsCount++
 		}
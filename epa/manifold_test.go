@@ -360,10 +360,11 @@ func TestClipPolygonAgainstPlane(t *testing.T) {
 			var inputBuf [8]mgl64.Vec3
 			copy(inputBuf[:], tt.input)
 
+			var inputTags, outputTags [8]pointTag
 			var outputBuf [8]mgl64.Vec3
 			var outputCount int
 
-			builder.clipPolygonAgainstPlane(&inputBuf, len(tt.input), tt.planePoint, tt.planeNormal, &outputBuf, &outputCount)
+			builder.clipPolygonAgainstPlane(&inputBuf, &inputTags, len(tt.input), tt.planePoint, tt.planeNormal, constraint.FeatureEdge, &outputBuf, &outputTags, &outputCount)
 
 			if outputCount != tt.expectedCount {
 				t.Errorf("outputCount = %d, want %d", outputCount, tt.expectedCount)
@@ -526,6 +527,63 @@ func TestClipIncidentAgainstReference(t *testing.T) {
 			t.Error("clipBuffer1Count = 0, expected result in clipBuffer1 after odd edges")
 		}
 	})
+
+	t.Run("crossing_segments", func(t *testing.T) {
+		builder.Reset()
+
+		// A segment reference, the center on its line must not flip the clipping planes
+		var reference [8]mgl64.Vec3
+		reference[0] = mgl64.Vec3{-1, 0, 0.3}
+		reference[1] = mgl64.Vec3{1, 0, 0.3}
+		referenceCount := 2
+
+		var incident [8]mgl64.Vec3
+		incident[0] = mgl64.Vec3{0.2, 0.1, -1}
+		incident[1] = mgl64.Vec3{0.2, 0.1, 1}
+		incidentCount := 2
+
+		normal := mgl64.Vec3{0, 1, 0}
+
+		count := builder.clipIncidentAgainstReference(&incident, incidentCount, &reference, referenceCount, normal)
+
+		// Clipped to the crossing point, once per clipping plane
+		if count == 0 {
+			t.Fatal("count = 0, expected the crossing point")
+		}
+		for i := 0; i < count; i++ {
+			if !builder.clipBuffer1[i].ApproxEqualThreshold(mgl64.Vec3{0.2, 0.1, 0.3}, 1e-9) {
+				t.Errorf("point %d = %v, want the crossing point", i, builder.clipBuffer1[i])
+			}
+		}
+	})
+
+	t.Run("parallel_segments", func(t *testing.T) {
+		builder.Reset()
+
+		var reference [8]mgl64.Vec3
+		reference[0] = mgl64.Vec3{-1, 0, 0}
+		reference[1] = mgl64.Vec3{1, 0, 0}
+		referenceCount := 2
+
+		// Longer than the reference: clipped to its length
+		var incident [8]mgl64.Vec3
+		incident[0] = mgl64.Vec3{-3, 0.1, 0}
+		incident[1] = mgl64.Vec3{0.5, 0.1, 0}
+		incidentCount := 2
+
+		normal := mgl64.Vec3{0, 1, 0}
+
+		count := builder.clipIncidentAgainstReference(&incident, incidentCount, &reference, referenceCount, normal)
+
+		if count == 0 {
+			t.Fatal("count = 0, expected the overlap of the segments")
+		}
+		for i := 0; i < count; i++ {
+			if x := builder.clipBuffer1[i].X(); x < -1-1e-9 || x > 0.5+1e-9 {
+				t.Errorf("point %d = %v, want within the reference segment", i, builder.clipBuffer1[i])
+			}
+		}
+	})
 }
 
 // TestClipAgainstReferencePlane tests final clipping against reference plane
@@ -935,6 +993,55 @@ func TestGenerateManifold(t *testing.T) {
 	})
 }
 
+// TestGenerateManifold_FeatureIDs tests the identifiers of the points: distinct, and unchanged by a small motion
+func TestGenerateManifold_FeatureIDs(t *testing.T) {
+	ground := &actor.RigidBody{
+		Shape:     &actor.Box{HalfExtents: mgl64.Vec3{2, 0.5, 2}},
+		Transform: actor.Transform{Rotation: mgl64.QuatIdent()},
+	}
+
+	// A box half over the edge of the ground: two corners and two clipped points
+	features := func(x float64) map[constraint.FeatureID]mgl64.Vec3 {
+		box := &actor.RigidBody{
+			Shape: &actor.Box{HalfExtents: mgl64.Vec3{0.5, 0.5, 0.5}},
+			Transform: actor.Transform{
+				Position: mgl64.Vec3{x, 0.99, 0},
+				Rotation: mgl64.QuatRotate(0.1, mgl64.Vec3{0, 1, 0}),
+			},
+		}
+
+		points := GenerateManifold(ground, box, mgl64.Vec3{0, 1, 0}, 0.01)
+		result := make(map[constraint.FeatureID]mgl64.Vec3)
+		for _, point := range points {
+			if point.Feature == (constraint.FeatureID{}) {
+				t.Errorf("point %v without feature", point.Position)
+			}
+			if _, ok := result[point.Feature]; ok {
+				t.Errorf("feature %+v shared by several points", point.Feature)
+			}
+			result[point.Feature] = point.Position
+		}
+		if len(points) != 4 {
+			t.Errorf("len(points) = %d, want 4", len(points))
+		}
+
+		return result
+	}
+
+	before := features(2)
+	after := features(2.01)
+	for feature, position := range before {
+		moved, ok := after[feature]
+		if !ok {
+			t.Errorf("feature %+v lost after a small motion", feature)
+			continue
+		}
+		if distance := moved.Sub(position).Len(); distance > 0.02 {
+			t.Errorf("feature %+v moved by %v, want the same point", feature, distance)
+		}
+	}
+}
+
 // TestManifoldBuilderReset tests Reset method
 func TestManifoldBuilderReset(t *testing.T) {
 	builder := &ManifoldBuilder{}
@@ -102,7 +102,8 @@ func (b *PolytopeBuilder) BuildInitialFaces(simplex *gjk.Simplex) error {
 // Algorithm:
 //  1. Compute normal via cross product: (b-a) × (c-a)
 //  2. Check if normal points toward opposite point (inward) → flip if needed
-//  3. Ensure distance is positive (normal away from origin)
+//  3. Clamp the distance to EPAMinFaceDistance: the normal is not flipped if the origin is (numerically)
+//     outside the face, an inward normal would break the expansion
 //  4. Snap near-zero components for numerical stability
 func (b *PolytopeBuilder) createFaceOutward(p0, p1, p2, oppositePoint mgl64.Vec3) Face {
 	var face Face
@@ -138,12 +139,6 @@ func (b *PolytopeBuilder) createFaceOutward(p0, p1, p2, oppositePoint mgl64.Vec3
 	// Calculate distance from origin to the plane
 	distance := p0.Dot(normal)
 
-	// Distance should be positive (normal points away from origin)
-	if distance < 0 {
-		normal = normal.Mul(-1)
-		distance = -distance
-	}
-
 	// Force minimum distance to avoid degenerate cases
 	if distance < EPAMinFaceDistance {
 		distance = EPAMinFaceDistance
@@ -0,0 +1,143 @@
+package epa
+
+import (
+	"math"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/akmonengine/feather/constraint"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// boxSpherePenetration computes the exact contact between a box and a sphere, from the point of the box
+// closest to the sphere center.
+//
+// EPA approximates the rounded Minkowski difference with a polytope: near the edges and corners of the box,
+// its normal drifts from the direction of the closest point, and spheres roll oddly off the box edges.
+//
+// Returns:
+//   - normal: Contact normal pointing from the box toward the sphere
+//   - depth: Penetration depth along the normal (always positive)
+//   - point: Deepest point of the sphere inside the box
+//   - ok: false if the sphere does not touch the box
+func boxSpherePenetration(boxBody, sphereBody *actor.RigidBody, box *actor.Box, sphere *actor.Sphere) (mgl64.Vec3, float64, mgl64.Vec3, bool) {
+	h := box.HalfExtents
+	center := sphereBody.Transform.Position
+	localCenter := boxBody.Transform.Rotation.Conjugate().Rotate(center.Sub(boxBody.Transform.Position))
+
+	closest := mgl64.Vec3{
+		math.Max(-h.X(), math.Min(h.X(), localCenter.X())),
+		math.Max(-h.Y(), math.Min(h.Y(), localCenter.Y())),
+		math.Max(-h.Z(), math.Min(h.Z(), localCenter.Z())),
+	}
+
+	var localNormal mgl64.Vec3
+	var depth float64
+	if closest != localCenter {
+		// Center outside: along the direction from the closest point, exact on the faces, edges and corners
+		delta := localCenter.Sub(closest)
+		distance := delta.Len()
+		if distance > sphere.Radius {
+			return mgl64.Vec3{}, 0, mgl64.Vec3{}, false
+		}
+		localNormal = delta.Mul(1.0 / distance)
+		depth = sphere.Radius - distance
+	} else {
+		// Center inside: out through the nearest face
+		axis := 0
+		nearest := math.Inf(1)
+		for i := 0; i < 3; i++ {
+			if distance := h[i] - math.Abs(localCenter[i]); distance < nearest {
+				axis, nearest = i, distance
+			}
+		}
+		localNormal[axis] = 1
+		if localCenter[axis] < 0 {
+			localNormal[axis] = -1
+		}
+		depth = sphere.Radius + nearest
+	}
+
+	normal := snapNormalToAxis(boxBody.Transform.Rotation.Rotate(localNormal))
+
+	return normal, depth, center.Sub(normal.Mul(sphere.Radius)), true
+}
+
+// boxSphereContact returns the contact of a box-sphere pair, in either order, with a single point
+func boxSphereContact(a, b *actor.RigidBody) (constraint.ContactConstraint, bool) {
+	normal, depth, point, ok := boxSpherePairPenetration(a, b)
+	if !ok {
+		return constraint.ContactConstraint{}, false
+	}
+
+	return constraint.ContactConstraint{
+		BodyA:  a,
+		BodyB:  b,
+		Points: []constraint.ContactPoint{{Position: point, Penetration: depth}},
+		Normal: normal,
+	}, true
+}
+
+// boxSpherePairPenetration is boxSpherePenetration for a box-sphere pair in either order, the normal pointing from A toward B.
+// ok is false if the pair is not a box and a sphere.
+func boxSpherePairPenetration(a, b *actor.RigidBody) (mgl64.Vec3, float64, mgl64.Vec3, bool) {
+	boxBody, sphereBody, sign := a, b, 1.0
+	box, ok := a.Shape.(*actor.Box)
+	if !ok {
+		boxBody, sphereBody, sign = b, a, -1.0
+		if box, ok = b.Shape.(*actor.Box); !ok {
+			return mgl64.Vec3{}, 0, mgl64.Vec3{}, false
+		}
+	}
+	sphere, ok := sphereBody.Shape.(*actor.Sphere)
+	if !ok {
+		return mgl64.Vec3{}, 0, mgl64.Vec3{}, false
+	}
+
+	normal, depth, point, ok := boxSpherePenetration(boxBody, sphereBody, box, sphere)
+	if !ok {
+		return mgl64.Vec3{}, 0, mgl64.Vec3{}, false
+	}
+
+	return normal.Mul(sign), depth, point, true
+}
+
+// sphereSpherePenetration computes the exact contact between two spheres, along the line between their centers.
+// Concentric spheres are pushed apart along +Y.
+//
+// Returns:
+//   - normal: Contact normal pointing from A toward B
+//   - depth: Penetration depth along the normal (always positive)
+//   - point: Deepest point of B inside A
+//   - ok: false if the spheres do not touch
+func sphereSpherePenetration(bodyA, bodyB *actor.RigidBody, sphereA, sphereB *actor.Sphere) (mgl64.Vec3, float64, mgl64.Vec3, bool) {
+	delta := bodyB.Transform.Position.Sub(bodyA.Transform.Position)
+	distance := delta.Len()
+	depth := sphereA.Radius + sphereB.Radius - distance
+	if depth <= 0 {
+		return mgl64.Vec3{}, 0, mgl64.Vec3{}, false
+	}
+
+	normal := mgl64.Vec3{0, 1, 0}
+	if distance > NormalSnapThreshold {
+		normal = snapNormalToAxis(delta.Mul(1.0 / distance))
+	}
+
+	return normal, depth, bodyB.Transform.Position.Sub(normal.Mul(sphereB.Radius)), true
+}
+
+// SpherePenetration computes the contact of a sphere-sphere or box-sphere pair, in either order, with a single point.
+//
+// Returns:
+//   - normal: Contact normal pointing from A toward B
+//   - depth: Penetration depth along the normal (always positive)
+//   - point: Contact point, on the surface of the sphere (of B for two spheres)
+//   - ok: false if the shapes do not touch, or are not a sphere pair
+func SpherePenetration(a, b *actor.RigidBody) (mgl64.Vec3, float64, mgl64.Vec3, bool) {
+	if sphereA, ok := a.Shape.(*actor.Sphere); ok {
+		if sphereB, ok := b.Shape.(*actor.Sphere); ok {
+			return sphereSpherePenetration(a, b, sphereA, sphereB)
+		}
+	}
+
+	return boxSpherePairPenetration(a, b)
+}
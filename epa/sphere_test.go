@@ -0,0 +1,185 @@
+package epa
+
+import (
+	"math"
+	"testing"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/akmonengine/feather/gjk"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func newSphereBody(position mgl64.Vec3, radius float64) *actor.RigidBody {
+	return &actor.RigidBody{
+		Shape:     &actor.Sphere{Radius: radius},
+		Transform: actor.Transform{Position: position, Rotation: mgl64.QuatIdent(), InverseRotation: mgl64.QuatIdent()},
+	}
+}
+
+func TestBoxSpherePenetration(t *testing.T) {
+	diagonal := mgl64.Vec3{1, 1, 0}.Normalize()
+	corner := mgl64.Vec3{1, 1, 1}.Normalize()
+
+	tests := []struct {
+		name           string
+		box            *actor.RigidBody
+		sphere         *actor.RigidBody
+		expectedNormal mgl64.Vec3
+		expectedDepth  float64
+		expectedPoint  mgl64.Vec3
+	}{
+		{
+			name:           "face",
+			box:            newBoxBody(mgl64.Vec3{0, 0, 0}, mgl64.QuatIdent(), mgl64.Vec3{1, 1, 1}),
+			sphere:         newSphereBody(mgl64.Vec3{0.3, 1.4, 0}, 0.5),
+			expectedNormal: mgl64.Vec3{0, 1, 0},
+			expectedDepth:  0.1,
+			expectedPoint:  mgl64.Vec3{0.3, 0.9, 0},
+		},
+		{
+			name:           "edge",
+			box:            newBoxBody(mgl64.Vec3{0, 0, 0}, mgl64.QuatIdent(), mgl64.Vec3{1, 1, 1}),
+			sphere:         newSphereBody(mgl64.Vec3{1, 1, 0}.Add(diagonal.Mul(0.4)), 0.5),
+			expectedNormal: diagonal,
+			expectedDepth:  0.1,
+			expectedPoint:  mgl64.Vec3{1, 1, 0}.Sub(diagonal.Mul(0.1)),
+		},
+		{
+			name:           "corner",
+			box:            newBoxBody(mgl64.Vec3{0, 0, 0}, mgl64.QuatIdent(), mgl64.Vec3{1, 1, 1}),
+			sphere:         newSphereBody(mgl64.Vec3{1, 1, 1}.Add(corner.Mul(0.3)), 0.5),
+			expectedNormal: corner,
+			expectedDepth:  0.2,
+			expectedPoint:  mgl64.Vec3{1, 1, 1}.Sub(corner.Mul(0.2)),
+		},
+		{
+			name:           "center_inside",
+			box:            newBoxBody(mgl64.Vec3{0, 0, 0}, mgl64.QuatIdent(), mgl64.Vec3{2, 1, 2}),
+			sphere:         newSphereBody(mgl64.Vec3{0.5, -0.8, 0}, 0.5),
+			expectedNormal: mgl64.Vec3{0, -1, 0},
+			expectedDepth:  0.7,
+			expectedPoint:  mgl64.Vec3{0.5, -0.3, 0},
+		},
+		{
+			name:           "rotated_box",
+			box:            newBoxBody(mgl64.Vec3{0, 0, 0}, mgl64.QuatRotate(math.Pi/4, mgl64.Vec3{0, 0, 1}), mgl64.Vec3{1, 1, 1}),
+			sphere:         newSphereBody(mgl64.Vec3{0, math.Sqrt2 + 0.4, 0}, 0.5),
+			expectedNormal: mgl64.Vec3{0, 1, 0},
+			expectedDepth:  0.1,
+			expectedPoint:  mgl64.Vec3{0, math.Sqrt2 - 0.1, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normal, depth, point, ok := boxSpherePenetration(tt.box, tt.sphere, tt.box.Shape.(*actor.Box), tt.sphere.Shape.(*actor.Sphere))
+			if !ok {
+				t.Fatal("expected the sphere to touch the box")
+			}
+			if !vec3ApproxEqual(normal, tt.expectedNormal, 1e-9) {
+				t.Errorf("normal = %v, want %v", normal, tt.expectedNormal)
+			}
+			if math.Abs(depth-tt.expectedDepth) > 1e-9 {
+				t.Errorf("depth = %v, want %v", depth, tt.expectedDepth)
+			}
+			if !vec3ApproxEqual(point, tt.expectedPoint, 1e-9) {
+				t.Errorf("point = %v, want %v", point, tt.expectedPoint)
+			}
+		})
+	}
+
+	// Near a corner, the AABBs overlap but the sphere does not touch the box
+	box := newBoxBody(mgl64.Vec3{0, 0, 0}, mgl64.QuatIdent(), mgl64.Vec3{1, 1, 1})
+	sphere := newSphereBody(mgl64.Vec3{1.4, 1.4, 0}, 0.5)
+	if _, _, _, ok := boxSpherePenetration(box, sphere, box.Shape.(*actor.Box), sphere.Shape.(*actor.Sphere)); ok {
+		t.Error("expected no contact near the edge")
+	}
+}
+
+// TestEPA_BoxSphere checks the normal goes from A to B for both orders of the pair
+func TestEPA_BoxSphere(t *testing.T) {
+	box := newBoxBody(mgl64.Vec3{0, 0, 0}, mgl64.QuatIdent(), mgl64.Vec3{1, 1, 1})
+	sphere := newSphereBody(mgl64.Vec3{1.3, 1.3, 0}, 0.5)
+	diagonal := mgl64.Vec3{1, 1, 0}.Normalize()
+
+	for _, order := range [][2]*actor.RigidBody{{box, sphere}, {sphere, box}} {
+		simplex := &gjk.Simplex{}
+		if !gjk.GJK(order[0], order[1], simplex) {
+			t.Fatal("expected GJK to detect the collision")
+		}
+
+		contact, err := EPA(order[0], order[1], simplex)
+		if err != nil {
+			t.Fatalf("EPA failed: %v", err)
+		}
+
+		expected := diagonal
+		if order[0] == sphere {
+			expected = diagonal.Mul(-1)
+		}
+		if !vec3ApproxEqual(contact.Normal, expected, 1e-9) {
+			t.Errorf("normal = %v, want %v", contact.Normal, expected)
+		}
+		if len(contact.Points) != 1 || math.Abs(contact.Points[0].Penetration-(0.5-0.3*math.Sqrt2)) > 1e-9 {
+			t.Errorf("points = %+v, want one point of depth %v", contact.Points, 0.5-0.3*math.Sqrt2)
+		}
+	}
+}
+
+// TestSpherePenetration checks the closed-form contacts of the sphere pairs, in both orders
+func TestSpherePenetration(t *testing.T) {
+	t.Run("sphere_sphere", func(t *testing.T) {
+		a := newSphereBody(mgl64.Vec3{0, 0, 0}, 1)
+		b := newSphereBody(mgl64.Vec3{1.2, 1.6, 0}, 1.5)
+
+		normal, depth, point, ok := SpherePenetration(a, b)
+		if !ok {
+			t.Fatal("expected a contact")
+		}
+		if !vec3ApproxEqual(normal, mgl64.Vec3{0.6, 0.8, 0}, 1e-9) || math.Abs(depth-0.5) > 1e-9 {
+			t.Errorf("normal = %v, depth = %v, want {0.6 0.8 0} and 0.5", normal, depth)
+		}
+		if !vec3ApproxEqual(point, mgl64.Vec3{0.3, 0.4, 0}, 1e-9) {
+			t.Errorf("point = %v, want the deepest point of B {0.3 0.4 0}", point)
+		}
+
+		if reversed, _, _, _ := SpherePenetration(b, a); !vec3ApproxEqual(reversed, normal.Mul(-1), 1e-9) {
+			t.Errorf("reversed normal = %v, want %v", reversed, normal.Mul(-1))
+		}
+	})
+
+	t.Run("separated", func(t *testing.T) {
+		a := newSphereBody(mgl64.Vec3{0, 0, 0}, 1)
+		b := newSphereBody(mgl64.Vec3{2, 0, 0}, 1)
+		if _, _, _, ok := SpherePenetration(a, b); ok {
+			t.Error("expected no contact for touching spheres")
+		}
+	})
+
+	t.Run("concentric", func(t *testing.T) {
+		a := newSphereBody(mgl64.Vec3{0, 0, 0}, 1)
+		b := newSphereBody(mgl64.Vec3{0, 0, 0}, 0.5)
+		normal, depth, _, ok := SpherePenetration(a, b)
+		if !ok || normal != (mgl64.Vec3{0, 1, 0}) || math.Abs(depth-1.5) > 1e-9 {
+			t.Errorf("normal = %v, depth = %v, want {0 1 0} and 1.5", normal, depth)
+		}
+	})
+
+	t.Run("sphere_box", func(t *testing.T) {
+		box := newBoxBody(mgl64.Vec3{0, 0, 0}, mgl64.QuatIdent(), mgl64.Vec3{1, 1, 1})
+		sphere := newSphereBody(mgl64.Vec3{0, 1.4, 0}, 0.5)
+
+		normal, depth, _, ok := SpherePenetration(sphere, box)
+		if !ok || !vec3ApproxEqual(normal, mgl64.Vec3{0, -1, 0}, 1e-9) || math.Abs(depth-0.1) > 1e-9 {
+			t.Errorf("normal = %v, depth = %v, want {0 -1 0} and 0.1", normal, depth)
+		}
+	})
+
+	t.Run("not_a_sphere_pair", func(t *testing.T) {
+		a := newBoxBody(mgl64.Vec3{0, 0, 0}, mgl64.QuatIdent(), mgl64.Vec3{1, 1, 1})
+		b := newBoxBody(mgl64.Vec3{0, 1.5, 0}, mgl64.QuatIdent(), mgl64.Vec3{1, 1, 1})
+		if _, _, _, ok := SpherePenetration(a, b); ok {
+			t.Error("expected no contact for a box pair")
+		}
+	})
+}
@@ -1,10 +1,11 @@
 package feather
 
 import (
-	"unsafe"
+	"sort"
 
 	"github.com/akmonengine/feather/actor"
 	"github.com/akmonengine/feather/constraint"
+	"github.com/go-gl/mathgl/mgl64"
 )
 
 const (
@@ -16,54 +17,63 @@ const (
 	COLLISION_EXIT
 	ON_SLEEP
 	ON_WAKE
+	PENETRATION_DEEP
+	JOINT_BROKEN
+	COMPOUND_CHANGED
 )
 
-type pairKey struct {
-	bodyA *actor.RigidBody
-	bodyB *actor.RigidBody
-}
-
-// makePairKey creates a normalized pair key with consistent ordering
-func makePairKey(bodyA, bodyB *actor.RigidBody) pairKey {
-	ptrA := uintptr(unsafe.Pointer(bodyA))
-	ptrB := uintptr(unsafe.Pointer(bodyB))
-
-	if ptrB < ptrA {
-		bodyA, bodyB = bodyB, bodyA
-	}
-
-	return pairKey{bodyA: bodyA, bodyB: bodyB}
-}
-
 type EventType uint8
 
 // Event interface - all events implement this
 type Event interface {
 	Type() EventType
+	// bodies returns the bodies of the event, in world order (the same body twice for single body events)
+	bodies() (*actor.RigidBody, *actor.RigidBody)
+}
+
+// TriggerChild is the trigger child of a compound sending a trigger event, see actor.CompoundChild.Trigger
+type TriggerChild struct {
+	// Body holds the compound, nil when the event is sent by a trigger body
+	Body *actor.RigidBody
+	// Index of the child in the compound
+	Index int
 }
 
 // Trigger events
 type TriggerEnterEvent struct {
 	BodyA *actor.RigidBody
 	BodyB *actor.RigidBody
+	// Child is the trigger child of BodyA or BodyB overlapping the other body: a pair may touch by a solid child
+	// (collision events) and overlap by a trigger child at the same time
+	Child TriggerChild
 }
 
 func (e TriggerEnterEvent) Type() EventType { return TRIGGER_ENTER }
 
+func (e TriggerEnterEvent) bodies() (*actor.RigidBody, *actor.RigidBody) { return e.BodyA, e.BodyB }
+
 type TriggerStayEvent struct {
 	BodyA *actor.RigidBody
 	BodyB *actor.RigidBody
+	// Child is the trigger child of BodyA or BodyB, see TriggerEnterEvent
+	Child TriggerChild
 }
 
 func (e TriggerStayEvent) Type() EventType { return TRIGGER_STAY }
 
+func (e TriggerStayEvent) bodies() (*actor.RigidBody, *actor.RigidBody) { return e.BodyA, e.BodyB }
+
 type TriggerExitEvent struct {
 	BodyA *actor.RigidBody
 	BodyB *actor.RigidBody
+	// Child is the trigger child of BodyA or BodyB, see TriggerEnterEvent
+	Child TriggerChild
 }
 
 func (e TriggerExitEvent) Type() EventType { return TRIGGER_EXIT }
 
+func (e TriggerExitEvent) bodies() (*actor.RigidBody, *actor.RigidBody) { return e.BodyA, e.BodyB }
+
 // Collision events
 type CollisionEnterEvent struct {
 	BodyA *actor.RigidBody
@@ -72,6 +82,8 @@ type CollisionEnterEvent struct {
 
 func (e CollisionEnterEvent) Type() EventType { return COLLISION_ENTER }
 
+func (e CollisionEnterEvent) bodies() (*actor.RigidBody, *actor.RigidBody) { return e.BodyA, e.BodyB }
+
 type CollisionStayEvent struct {
 	BodyA *actor.RigidBody
 	BodyB *actor.RigidBody
@@ -79,6 +91,8 @@ type CollisionStayEvent struct {
 
 func (e CollisionStayEvent) Type() EventType { return COLLISION_STAY }
 
+func (e CollisionStayEvent) bodies() (*actor.RigidBody, *actor.RigidBody) { return e.BodyA, e.BodyB }
+
 type CollisionExitEvent struct {
 	BodyA *actor.RigidBody
 	BodyB *actor.RigidBody
@@ -86,6 +100,8 @@ type CollisionExitEvent struct {
 
 func (e CollisionExitEvent) Type() EventType { return COLLISION_EXIT }
 
+func (e CollisionExitEvent) bodies() (*actor.RigidBody, *actor.RigidBody) { return e.BodyA, e.BodyB }
+
 // Sleep/Wake events
 type SleepEvent struct {
 	Body *actor.RigidBody
@@ -93,12 +109,57 @@ type SleepEvent struct {
 
 func (e SleepEvent) Type() EventType { return ON_SLEEP }
 
+func (e SleepEvent) bodies() (*actor.RigidBody, *actor.RigidBody) { return e.Body, e.Body }
+
 type WakeEvent struct {
 	Body *actor.RigidBody
 }
 
 func (e WakeEvent) Type() EventType { return ON_WAKE }
 
+func (e WakeEvent) bodies() (*actor.RigidBody, *actor.RigidBody) { return e.Body, e.Body }
+
+// PenetrationDeepEvent is sent along the COLLISION_ENTER of a pair whose first contact is deeper than
+// Events.PenetrationDepth: a body spawned inside another one, or tunneling through it.
+// The listener can teleport, destroy or fade the body instead of keeping the velocity given by the solver.
+type PenetrationDeepEvent struct {
+	BodyA *actor.RigidBody
+	BodyB *actor.RigidBody
+	// Depth is the deepest penetration of the contact points
+	Depth float64
+	// Depenetration is the translation of BodyB separating the bodies, -Depenetration separates BodyA
+	Depenetration mgl64.Vec3
+}
+
+func (e PenetrationDeepEvent) Type() EventType { return PENETRATION_DEEP }
+
+func (e PenetrationDeepEvent) bodies() (*actor.RigidBody, *actor.RigidBody) { return e.BodyA, e.BodyB }
+
+// JointBrokenEvent is sent when a joint broke under its load (see constraint.Breakable).
+// The world removed the joint, the listener can spawn the debris effects or join the bodies again.
+type JointBrokenEvent struct {
+	Joint constraint.Constraint
+	BodyA *actor.RigidBody
+	BodyB *actor.RigidBody
+	// Impulse (N.s) and AngularImpulse (N.m.s) are held by the joint during the substep which broke it
+	Impulse        float64
+	AngularImpulse float64
+}
+
+func (e JointBrokenEvent) Type() EventType { return JOINT_BROKEN }
+
+func (e JointBrokenEvent) bodies() (*actor.RigidBody, *actor.RigidBody) { return e.BodyA, e.BodyB }
+
+// CompoundChangedEvent is sent when the world applied the children added to or removed from the compound
+// of a body (see actor.Compound.AddChild and RemoveChild): its mass, center of mass and inertia are updated.
+type CompoundChangedEvent struct {
+	Body *actor.RigidBody
+}
+
+func (e CompoundChangedEvent) Type() EventType { return COMPOUND_CHANGED }
+
+func (e CompoundChangedEvent) bodies() (*actor.RigidBody, *actor.RigidBody) { return e.Body, e.Body }
+
 // EventListener - callback for events
 type EventListener func(event Event)
 
@@ -111,22 +172,72 @@ type Events struct {
 	buffer []Event
 
 	// Collision tracking for Enter/Stay/Exit detection
-	previousActivePairs map[pairKey]bool
-	currentActivePairs  map[pairKey]bool
+	previousActivePairs map[Pair]bool
+	currentActivePairs  map[Pair]bool
 
 	sleepStates map[*actor.RigidBody]bool
+
+	// Index of each body in the world, events are dispatched sorted by it
+	bodyOrder map[*actor.RigidBody]int
+
+	// ExitDelay is the number of frames a pair must stay separated before its Exit event.
+	// Grazing contacts lose their manifold for a frame, the delay avoids Enter/Exit flapping.
+	ExitDelay int
+	// ExitDistance keeps a separated pair active while the AABBs of its bodies,
+	// expanded by this distance, still overlap. The Exit event waits for both ExitDelay and ExitDistance.
+	ExitDistance float64
+	// Number of consecutive frames each pair has been separated, during the exit hysteresis
+	separatedFrames map[Pair]int
+
+	// Overlaps of the trigger children, tracked apart from the pairs of their bodies
+	previousChildPairs map[childPair]bool
+	currentChildPairs  map[childPair]bool
+	// Number of consecutive frames each child pair has been separated, see separatedFrames
+	separatedChildFrames map[childPair]int
+
+	// PenetrationDepth is the penetration (m) above which a new contact sends a PENETRATION_DEEP event, 0 disables it
+	PenetrationDepth float64
+	// Deepest contact of the pairs starting this frame, deeper than PenetrationDepth
+	deepPairs map[Pair]PenetrationDeepEvent
+}
+
+// childPair is a pair of bodies overlapping by a trigger child of one of them
+type childPair struct {
+	Pair
+	child TriggerChild
 }
 
 func NewEvents() Events {
 	return Events{
-		listeners:           make(map[EventType][]EventListener),
-		buffer:              make([]Event, 0, 256),
-		previousActivePairs: make(map[pairKey]bool),
-		currentActivePairs:  make(map[pairKey]bool),
-		sleepStates:         make(map[*actor.RigidBody]bool),
+		listeners:            make(map[EventType][]EventListener),
+		buffer:               make([]Event, 0, 256),
+		previousActivePairs:  make(map[Pair]bool),
+		currentActivePairs:   make(map[Pair]bool),
+		sleepStates:          make(map[*actor.RigidBody]bool),
+		bodyOrder:            make(map[*actor.RigidBody]int),
+		separatedFrames:      make(map[Pair]int),
+		previousChildPairs:   make(map[childPair]bool),
+		currentChildPairs:    make(map[childPair]bool),
+		separatedChildFrames: make(map[childPair]int),
+		deepPairs:            make(map[Pair]PenetrationDeepEvent),
 	}
 }
 
+// clear forgets the tracked pairs and sleep states and the buffered events, the listeners are kept, see World.Clear
+func (e *Events) clear() {
+	clear(e.buffer)
+	e.buffer = e.buffer[:0]
+	clear(e.previousActivePairs)
+	clear(e.currentActivePairs)
+	clear(e.sleepStates)
+	clear(e.bodyOrder)
+	clear(e.separatedFrames)
+	clear(e.previousChildPairs)
+	clear(e.currentChildPairs)
+	clear(e.separatedChildFrames)
+	clear(e.deepPairs)
+}
+
 // Subscribe adds a listener for an event type
 func (e *Events) Subscribe(eventType EventType, listener EventListener) {
 	e.listeners[eventType] = append(e.listeners[eventType], listener)
@@ -136,12 +247,21 @@ func (e *Events) Subscribe(eventType EventType, listener EventListener) {
 func (e *Events) recordCollisions(constraints []*constraint.ContactConstraint) []*constraint.ContactConstraint {
 	n := 0
 	for _, c := range constraints {
-		pair := makePairKey(c.BodyA, c.BodyB)
+		if c.Sensor {
+			e.recordSensor(c)
+			continue
+		}
+
+		pair := OrderPair(c.BodyA, c.BodyB)
 		e.currentActivePairs[pair] = true
 
 		if !c.BodyA.IsTrigger && !c.BodyB.IsTrigger {
 			constraints[n] = c
 			n++
+
+			if e.PenetrationDepth > 0 && !e.previousActivePairs[pair] {
+				e.recordPenetration(pair, c)
+			}
 		}
 	}
 	constraints = constraints[:n]
@@ -149,63 +269,124 @@ func (e *Events) recordCollisions(constraints []*constraint.ContactConstraint) [
 	return constraints
 }
 
+// recordSensor records the overlaps of the trigger children of a sensor contact, see constraint.ContactConstraint.Sensor
+func (e *Events) recordSensor(c *constraint.ContactConstraint) {
+	if len(c.Points) == 0 {
+		return
+	}
+
+	pair := OrderPair(c.BodyA, c.BodyB)
+	feature := c.Points[0].Feature
+	if isTriggerChild(c.BodyA, feature.PartA) {
+		e.currentChildPairs[childPair{Pair: pair, child: TriggerChild{Body: c.BodyA, Index: int(feature.PartA)}}] = true
+	}
+	if isTriggerChild(c.BodyB, feature.PartB) {
+		e.currentChildPairs[childPair{Pair: pair, child: TriggerChild{Body: c.BodyB, Index: int(feature.PartB)}}] = true
+	}
+}
+
+// recordPenetration keeps the deepest contact of a pair starting this frame, if deeper than PenetrationDepth
+func (e *Events) recordPenetration(pair Pair, c *constraint.ContactConstraint) {
+	depth := 0.0
+	for _, point := range c.Points {
+		depth = max(depth, point.Penetration)
+	}
+	if depth <= e.PenetrationDepth || depth <= e.deepPairs[pair].Depth {
+		return
+	}
+
+	e.deepPairs[pair] = PenetrationDeepEvent{
+		BodyA:         c.BodyA,
+		BodyB:         c.BodyB,
+		Depth:         depth,
+		Depenetration: c.Normal.Mul(depth),
+	}
+}
+
+// recordJointBroken buffers the JOINT_BROKEN of a joint removed by the world, with its bodies if it is a constraint.Connector
+func (e *Events) recordJointBroken(joint constraint.Constraint) {
+	event := JointBrokenEvent{Joint: joint}
+	if breakable, ok := joint.(constraint.Breakable); ok {
+		event.Impulse, event.AngularImpulse = breakable.Impulses()
+	}
+	if connector, ok := joint.(constraint.Connector); ok {
+		if bodies := connector.Bodies(); len(bodies) == 2 {
+			event.BodyA, event.BodyB = e.orderedBodies(Pair{BodyA: bodies[0], BodyB: bodies[1]})
+		}
+	}
+
+	e.buffer = append(e.buffer, event)
+}
+
+// recordTrigger records a trigger pair found outside the narrow phase, see World.sweepTriggers
+func (e *Events) recordTrigger(body, trigger *actor.RigidBody) {
+	e.currentActivePairs[OrderPair(body, trigger)] = true
+}
+
 // processCollisionEvents compares current and previous pairs to detect Enter/Stay/Exit
 // Should be called after all substeps
 func (e *Events) processCollisionEvents() {
 	// Detect Enter and Stay events
 	for pair := range e.currentActivePairs {
-		// Skip if both bodies are sleeping, to avoid spamming events
-		if pair.bodyA.IsSleeping && pair.bodyB.IsSleeping {
+		delete(e.separatedFrames, pair)
+
+		// Skip if both bodies are inactive (static or sleeping), to avoid spamming events
+		if !pair.BodyA.IsActive() && !pair.BodyB.IsActive() {
 			continue
 		}
 
-		isTrigger := pair.bodyA.IsTrigger || pair.bodyB.IsTrigger
+		bodyA, bodyB := e.orderedBodies(pair)
+		isTrigger := bodyA.IsTrigger || bodyB.IsTrigger
 
 		if e.previousActivePairs[pair] {
 			// Pair was active before and still is, Stay
 			if isTrigger {
-				e.buffer = append(e.buffer, TriggerStayEvent{
-					BodyA: pair.bodyA,
-					BodyB: pair.bodyB,
-				})
+				e.buffer = append(e.buffer, TriggerStayEvent{BodyA: bodyA, BodyB: bodyB})
 			} else {
-				e.buffer = append(e.buffer, CollisionStayEvent{
-					BodyA: pair.bodyA,
-					BodyB: pair.bodyB,
-				})
+				e.buffer = append(e.buffer, CollisionStayEvent{BodyA: bodyA, BodyB: bodyB})
 			}
 		} else {
 			// New pair, Enter
 			if isTrigger {
-				e.buffer = append(e.buffer, TriggerEnterEvent{
-					BodyA: pair.bodyA,
-					BodyB: pair.bodyB,
-				})
+				e.buffer = append(e.buffer, TriggerEnterEvent{BodyA: bodyA, BodyB: bodyB})
 			} else {
-				e.buffer = append(e.buffer, CollisionEnterEvent{
-					BodyA: pair.bodyA,
-					BodyB: pair.bodyB,
-				})
+				e.buffer = append(e.buffer, CollisionEnterEvent{BodyA: bodyA, BodyB: bodyB})
+				if deep, ok := e.deepPairs[pair]; ok {
+					e.buffer = append(e.buffer, orderedPenetration(deep, bodyA))
+				}
 			}
 		}
 	}
+	clear(e.deepPairs)
 
 	// Detect Exit events
 	for pair := range e.previousActivePairs {
 		if !e.currentActivePairs[pair] {
-			// Pair was active but is no longer, Exit
-			isTrigger := pair.bodyA.IsTrigger || pair.bodyB.IsTrigger
+			bodyA, bodyB := e.orderedBodies(pair)
+			isTrigger := bodyA.IsTrigger || bodyB.IsTrigger
+
+			// Pair separated during the exit hysteresis, still considered active
+			e.separatedFrames[pair]++
+			if e.holdExit(e.separatedFrames[pair], pair) {
+				e.currentActivePairs[pair] = true
+				if isTrigger {
+					e.buffer = append(e.buffer, TriggerStayEvent{BodyA: bodyA, BodyB: bodyB})
+				} else {
+					e.buffer = append(e.buffer, CollisionStayEvent{BodyA: bodyA, BodyB: bodyB})
+				}
+				continue
+			}
 
+			// Pair was active but is no longer, Exit
+			delete(e.separatedFrames, pair)
+			if !bodyA.IsEnabled() || !bodyB.IsEnabled() {
+				// A disabled body leaves its pairs silently, like a removed body
+				continue
+			}
 			if isTrigger {
-				e.buffer = append(e.buffer, TriggerExitEvent{
-					BodyA: pair.bodyA,
-					BodyB: pair.bodyB,
-				})
+				e.buffer = append(e.buffer, TriggerExitEvent{BodyA: bodyA, BodyB: bodyB})
 			} else {
-				e.buffer = append(e.buffer, CollisionExitEvent{
-					BodyA: pair.bodyA,
-					BodyB: pair.bodyB,
-				})
+				e.buffer = append(e.buffer, CollisionExitEvent{BodyA: bodyA, BodyB: bodyB})
 			}
 		}
 	}
@@ -213,12 +394,96 @@ func (e *Events) processCollisionEvents() {
 	// Swap for next frame and clear current
 	e.previousActivePairs, e.currentActivePairs = e.currentActivePairs, e.previousActivePairs
 	clear(e.currentActivePairs)
+
+	e.processChildEvents()
+}
+
+// processChildEvents sends the trigger events of the trigger children, like processCollisionEvents for the pairs
+func (e *Events) processChildEvents() {
+	for pair := range e.currentChildPairs {
+		delete(e.separatedChildFrames, pair)
+
+		if !pair.BodyA.IsActive() && !pair.BodyB.IsActive() {
+			continue
+		}
+
+		bodyA, bodyB := e.orderedBodies(pair.Pair)
+		if e.previousChildPairs[pair] {
+			e.buffer = append(e.buffer, TriggerStayEvent{BodyA: bodyA, BodyB: bodyB, Child: pair.child})
+		} else {
+			e.buffer = append(e.buffer, TriggerEnterEvent{BodyA: bodyA, BodyB: bodyB, Child: pair.child})
+		}
+	}
+
+	for pair := range e.previousChildPairs {
+		if e.currentChildPairs[pair] {
+			continue
+		}
+
+		bodyA, bodyB := e.orderedBodies(pair.Pair)
+		e.separatedChildFrames[pair]++
+		if e.holdExit(e.separatedChildFrames[pair], pair.Pair) {
+			e.currentChildPairs[pair] = true
+			e.buffer = append(e.buffer, TriggerStayEvent{BodyA: bodyA, BodyB: bodyB, Child: pair.child})
+			continue
+		}
+
+		delete(e.separatedChildFrames, pair)
+		if !bodyA.IsEnabled() || !bodyB.IsEnabled() {
+			continue
+		}
+		e.buffer = append(e.buffer, TriggerExitEvent{BodyA: bodyA, BodyB: bodyB, Child: pair.child})
+	}
+
+	e.previousChildPairs, e.currentChildPairs = e.currentChildPairs, e.previousChildPairs
+	clear(e.currentChildPairs)
+}
+
+// holdExit returns true while a pair separated for the given number of frames
+// is within ExitDelay frames or within ExitDistance
+func (e *Events) holdExit(separated int, pair Pair) bool {
+	if separated <= e.ExitDelay {
+		return true
+	}
+
+	if e.ExitDistance > 0 {
+		margin := mgl64.Vec3{e.ExitDistance, e.ExitDistance, e.ExitDistance}
+		aabbA := pair.BodyA.Shape.GetAABB()
+		aabbA.Min = aabbA.Min.Sub(margin)
+		aabbA.Max = aabbA.Max.Add(margin)
+
+		return aabbA.Overlaps(pair.BodyB.Shape.GetAABB())
+	}
+
+	return false
+}
+
+// orderedBodies returns the bodies of a pair sorted by their index in the world.
+// OrderPair orders by pointer, which changes from one run to another.
+func (e *Events) orderedBodies(pair Pair) (*actor.RigidBody, *actor.RigidBody) {
+	if e.bodyOrder[pair.BodyB] < e.bodyOrder[pair.BodyA] {
+		return pair.BodyB, pair.BodyA
+	}
+
+	return pair.BodyA, pair.BodyB
+}
+
+// orderedPenetration returns the event with bodyA first, the contact normal goes from its BodyA to its BodyB
+func orderedPenetration(event PenetrationDeepEvent, bodyA *actor.RigidBody) PenetrationDeepEvent {
+	if event.BodyA != bodyA {
+		event.BodyA, event.BodyB = event.BodyB, event.BodyA
+		event.Depenetration = event.Depenetration.Mul(-1)
+	}
+
+	return event
 }
 
 func (e *Events) processSleepEvents(bodies []*actor.RigidBody) {
-	for _, body := range bodies {
+	for i, body := range bodies {
+		e.bodyOrder[body] = i
+
 		trackedState, exists := e.sleepStates[body]
-		if !exists {
+		if !exists || !body.IsEnabled() {
 			e.sleepStates[body] = body.IsSleeping
 			continue
 		}
@@ -233,9 +498,55 @@ func (e *Events) processSleepEvents(bodies []*actor.RigidBody) {
 	}
 }
 
+// sortBuffer orders the buffered events by their bodies in the world, then by event type,
+// so listeners are called in the same order from one run to another
+func (e *Events) sortBuffer() {
+	sort.SliceStable(e.buffer, func(i, j int) bool {
+		iBodyA, iBodyB := e.buffer[i].bodies()
+		jBodyA, jBodyB := e.buffer[j].bodies()
+
+		if orderA, orderB := e.bodyOrder[iBodyA], e.bodyOrder[jBodyA]; orderA != orderB {
+			return orderA < orderB
+		}
+		if orderA, orderB := e.bodyOrder[iBodyB], e.bodyOrder[jBodyB]; orderA != orderB {
+			return orderA < orderB
+		}
+
+		if typeI, typeJ := e.buffer[i].Type(), e.buffer[j].Type(); typeI != typeJ {
+			return typeI < typeJ
+		}
+
+		// The trigger events of a pair: the trigger bodies first, then the trigger children by body and index
+		childI, childJ := eventChild(e.buffer[i]), eventChild(e.buffer[j])
+		if (childI.Body == nil) != (childJ.Body == nil) {
+			return childI.Body == nil
+		}
+		if orderI, orderJ := e.bodyOrder[childI.Body], e.bodyOrder[childJ.Body]; orderI != orderJ {
+			return orderI < orderJ
+		}
+
+		return childI.Index < childJ.Index
+	})
+}
+
+// eventChild returns the trigger child of a trigger event, the zero value for the other events
+func eventChild(event Event) TriggerChild {
+	switch event := event.(type) {
+	case TriggerEnterEvent:
+		return event.Child
+	case TriggerStayEvent:
+		return event.Child
+	case TriggerExitEvent:
+		return event.Child
+	}
+
+	return TriggerChild{}
+}
+
 // flush sends all buffered events and clears the buffer
 func (e *Events) flush() {
 	e.processCollisionEvents()
+	e.sortBuffer()
 
 	for _, event := range e.buffer {
 		if listeners, ok := e.listeners[event.Type()]; ok {
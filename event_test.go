@@ -1,6 +1,7 @@
 package feather
 
 import (
+	"math"
 	"testing"
 
 	"github.com/akmonengine/feather/actor"
@@ -141,48 +142,48 @@ func TestEvents_DifferentEventTypes(t *testing.T) {
 }
 
 // =============================================================================
-// makePairKey Tests
+// OrderPair Tests
 // =============================================================================
 
-func TestMakePairKey_Normalization(t *testing.T) {
+func TestOrderPair_Normalization(t *testing.T) {
 	bodyA := createTestBody("A", false, false)
 	bodyB := createTestBody("B", false, false)
 
 	// Create pairs in both orders
-	pairAB := makePairKey(bodyA, bodyB)
-	pairBA := makePairKey(bodyB, bodyA)
+	pairAB := OrderPair(bodyA, bodyB)
+	pairBA := OrderPair(bodyB, bodyA)
 
 	// Pairs should be identical (normalized)
-	if pairAB.bodyA != pairBA.bodyA || pairAB.bodyB != pairBA.bodyB {
-		t.Error("makePairKey should normalize pairs to consistent ordering")
+	if pairAB.BodyA != pairBA.BodyA || pairAB.BodyB != pairBA.BodyB {
+		t.Error("OrderPair should normalize pairs to consistent ordering")
 	}
 }
 
-func TestMakePairKey_SamePair(t *testing.T) {
+func TestOrderPair_SamePair(t *testing.T) {
 	bodyA := createTestBody("A", false, false)
 	bodyB := createTestBody("B", false, false)
 
-	pair1 := makePairKey(bodyA, bodyB)
-	pair2 := makePairKey(bodyA, bodyB)
+	pair1 := OrderPair(bodyA, bodyB)
+	pair2 := OrderPair(bodyA, bodyB)
 
 	// Same input should produce identical keys
-	if pair1.bodyA != pair2.bodyA || pair1.bodyB != pair2.bodyB {
-		t.Error("makePairKey should produce consistent keys for same input")
+	if pair1.BodyA != pair2.BodyA || pair1.BodyB != pair2.BodyB {
+		t.Error("OrderPair should produce consistent keys for same input")
 	}
 }
 
-func TestMakePairKey_DifferentPairs(t *testing.T) {
+func TestOrderPair_DifferentPairs(t *testing.T) {
 	bodyA := createTestBody("A", false, false)
 	bodyB := createTestBody("B", false, false)
 	bodyC := createTestBody("C", false, false)
 
-	pairAB := makePairKey(bodyA, bodyB)
-	pairAC := makePairKey(bodyA, bodyC)
+	pairAB := OrderPair(bodyA, bodyB)
+	pairAC := OrderPair(bodyA, bodyC)
 
 	// Different pairs should have different keys
-	isDifferent := (pairAB.bodyA != pairAC.bodyA || pairAB.bodyB != pairAC.bodyB)
+	isDifferent := (pairAB.BodyA != pairAC.BodyA || pairAB.BodyB != pairAC.BodyB)
 	if !isDifferent {
-		t.Error("makePairKey should produce different keys for different pairs")
+		t.Error("OrderPair should produce different keys for different pairs")
 	}
 }
 
@@ -207,7 +208,7 @@ func TestEvents_RecordCollisions_NormalCollision(t *testing.T) {
 	}
 
 	// Pair should be recorded
-	pair := makePairKey(bodyA, bodyB)
+	pair := OrderPair(bodyA, bodyB)
 	if !events.currentActivePairs[pair] {
 		t.Error("Normal collision pair should be recorded in currentActivePairs")
 	}
@@ -230,7 +231,7 @@ func TestEvents_RecordCollisions_TriggerCollision(t *testing.T) {
 	}
 
 	// Pair should still be recorded for event generation
-	pair := makePairKey(bodyA, bodyB)
+	pair := OrderPair(bodyA, bodyB)
 	if !events.currentActivePairs[pair] {
 		t.Error("Trigger pair should be recorded in currentActivePairs")
 	}
@@ -840,3 +841,320 @@ func TestEvents_MultipleFrames_EnterExitEnter(t *testing.T) {
 		t.Error("Expected ENTER again on frame 3")
 	}
 }
+
+// =============================================================================
+// Deterministic Ordering Tests
+// =============================================================================
+
+func TestEvents_DeterministicOrder(t *testing.T) {
+	bodies := []*actor.RigidBody{
+		createTestBody("A", false, false),
+		createTestBody("B", false, false),
+		createTestBody("C", true, false),
+		createTestBody("D", false, false),
+	}
+
+	for run := 0; run < 5; run++ {
+		events := NewEvents()
+		capture := &eventCapture{}
+		for _, eventType := range []EventType{TRIGGER_ENTER, COLLISION_ENTER, ON_SLEEP} {
+			events.Subscribe(eventType, capture.capture)
+		}
+
+		// Register the world order, then record the pairs out of order
+		events.processSleepEvents(bodies)
+		events.recordCollisions([]*constraint.ContactConstraint{
+			createTestConstraint(bodies[3], bodies[1]),
+			createTestConstraint(bodies[2], bodies[0]),
+			createTestConstraint(bodies[1], bodies[0]),
+		})
+		bodies[3].IsSleeping = true
+		events.processSleepEvents(bodies)
+		events.flush()
+		bodies[3].IsSleeping = false
+
+		expected := []struct {
+			eventType EventType
+			bodyA     *actor.RigidBody
+			bodyB     *actor.RigidBody
+		}{
+			{COLLISION_ENTER, bodies[0], bodies[1]},
+			{TRIGGER_ENTER, bodies[0], bodies[2]},
+			{COLLISION_ENTER, bodies[1], bodies[3]},
+			{ON_SLEEP, bodies[3], bodies[3]},
+		}
+
+		if capture.count() != len(expected) {
+			t.Fatalf("run %d: expected %d events, got %d", run, len(expected), capture.count())
+		}
+		for i, want := range expected {
+			event := capture.events[i]
+			bodyA, bodyB := event.bodies()
+			if event.Type() != want.eventType || bodyA != want.bodyA || bodyB != want.bodyB {
+				t.Errorf("run %d: event %d = (%v, %v, %v), want (%v, %v, %v)",
+					run, i, event.Type(), bodyA.Id, bodyB.Id, want.eventType, want.bodyA.Id, want.bodyB.Id)
+			}
+		}
+	}
+}
+
+// =============================================================================
+// Exit Hysteresis Tests
+// =============================================================================
+
+func TestEvents_ExitDelay(t *testing.T) {
+	events := NewEvents()
+	events.ExitDelay = 2
+	capture := &eventCapture{}
+	events.Subscribe(COLLISION_ENTER, capture.capture)
+	events.Subscribe(COLLISION_STAY, capture.capture)
+	events.Subscribe(COLLISION_EXIT, capture.capture)
+
+	bodyA := createTestBody("A", false, false)
+	bodyB := createTestBody("B", false, false)
+	c := createTestConstraint(bodyA, bodyB)
+
+	events.recordCollisions([]*constraint.ContactConstraint{c})
+	events.flush()
+
+	// Grazing contact: the manifold is lost for a single frame, then found again
+	capture.reset()
+	events.recordCollisions(nil)
+	events.flush()
+	events.recordCollisions([]*constraint.ContactConstraint{c})
+	events.flush()
+
+	if capture.hasEventType(COLLISION_EXIT) || capture.hasEventType(COLLISION_ENTER) {
+		t.Error("a single separated frame should not produce EXIT/ENTER")
+	}
+	if capture.count() != 2 {
+		t.Errorf("expected 2 STAY events, got %d", capture.count())
+	}
+
+	// Real separation: EXIT after the delay
+	for frame := 1; frame <= 3; frame++ {
+		capture.reset()
+		events.recordCollisions(nil)
+		events.flush()
+
+		if exited := capture.hasEventType(COLLISION_EXIT); exited != (frame == 3) {
+			t.Errorf("frame %d: EXIT = %v", frame, exited)
+		}
+	}
+}
+
+func TestEvents_ExitDistance(t *testing.T) {
+	events := NewEvents()
+	events.ExitDistance = 0.5
+	capture := &eventCapture{}
+	events.Subscribe(COLLISION_EXIT, capture.capture)
+
+	// Unit spheres, 0.2 apart
+	bodyA := createSphere(mgl64.Vec3{0, 0, 0}, 1, actor.BodyTypeDynamic)
+	bodyB := createSphere(mgl64.Vec3{2.2, 0, 0}, 1, actor.BodyTypeDynamic)
+	c := createTestConstraint(bodyA, bodyB)
+
+	events.recordCollisions([]*constraint.ContactConstraint{c})
+	events.flush()
+
+	events.recordCollisions(nil)
+	events.flush()
+	if capture.count() != 0 {
+		t.Error("no EXIT expected within ExitDistance")
+	}
+
+	bodyB.Transform.Position = mgl64.Vec3{3, 0, 0}
+	bodyB.Shape.ComputeAABB(bodyB.Transform)
+	events.recordCollisions(nil)
+	events.flush()
+	if !capture.hasEventType(COLLISION_EXIT) {
+		t.Error("expected EXIT beyond ExitDistance")
+	}
+}
+
+// =============================================================================
+// Penetration Tests
+// =============================================================================
+
+func TestEvents_PenetrationDeep(t *testing.T) {
+	events := NewEvents()
+	events.PenetrationDepth = 0.05
+	capture := &eventCapture{}
+	events.Subscribe(PENETRATION_DEEP, capture.capture)
+
+	bodyA := createTestBody("A", false, false)
+	bodyB := createTestBody("B", false, false)
+	events.processSleepEvents([]*actor.RigidBody{bodyA, bodyB})
+
+	// The contact lists the bodies in the opposite order of the world
+	c := createTestConstraint(bodyB, bodyA)
+	shallow := createTestConstraint(bodyB, bodyA)
+	shallow.Points[0].Penetration = 0.01
+	events.recordCollisions([]*constraint.ContactConstraint{shallow, c})
+	events.flush()
+
+	if capture.count() != 1 {
+		t.Fatalf("expected 1 PENETRATION_DEEP event, got %d", capture.count())
+	}
+	event := capture.events[0].(PenetrationDeepEvent)
+	if event.BodyA != bodyA || event.BodyB != bodyB {
+		t.Error("expected the bodies in world order")
+	}
+	if event.Depth != 0.1 {
+		t.Errorf("Depth = %v, want the deepest point 0.1", event.Depth)
+	}
+	if !vec3ApproxEqual(event.Depenetration, mgl64.Vec3{-0.1, 0, 0}, 1e-12) {
+		t.Errorf("Depenetration = %v, want BodyB pushed against the contact normal", event.Depenetration)
+	}
+
+	// The pair is no longer new
+	capture.reset()
+	events.recordCollisions([]*constraint.ContactConstraint{c})
+	events.flush()
+	if capture.count() != 0 {
+		t.Error("expected no PENETRATION_DEEP event for a persisting contact")
+	}
+}
+
+func TestEvents_PenetrationDeep_Shallow(t *testing.T) {
+	events := NewEvents()
+	capture := &eventCapture{}
+	events.Subscribe(PENETRATION_DEEP, capture.capture)
+
+	c := createTestConstraint(createTestBody("A", false, false), createTestBody("B", false, false))
+	events.recordCollisions([]*constraint.ContactConstraint{c})
+	events.flush()
+	if capture.count() != 0 {
+		t.Error("expected no event while PenetrationDepth is 0")
+	}
+
+	events = NewEvents()
+	events.PenetrationDepth = 0.2
+	events.Subscribe(PENETRATION_DEEP, capture.capture)
+	c = createTestConstraint(createTestBody("A", false, false), createTestBody("B", false, false))
+	events.recordCollisions([]*constraint.ContactConstraint{c})
+	events.flush()
+	if capture.count() != 0 {
+		t.Error("expected no event for a contact shallower than PenetrationDepth")
+	}
+}
+
+func TestWorld_PenetrationDeep_SpawnOverlap(t *testing.T) {
+	world := NewWorld(WithSubsteps(4))
+	world.Events.PenetrationDepth = 0.1
+	capture := &eventCapture{}
+	world.Events.Subscribe(PENETRATION_DEEP, capture.capture)
+
+	ground := createBox(mgl64.Vec3{0, -0.5, 0}, mgl64.Vec3{5, 0.5, 5}, actor.BodyTypeStatic)
+	// Spawned half sunk in the ground
+	crate := createBox(mgl64.Vec3{0, 0.1, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+	world.AddBody(ground)
+	world.AddBody(crate)
+	world.Step(1.0 / 60.0)
+
+	if capture.count() != 1 {
+		t.Fatalf("expected 1 PENETRATION_DEEP event, got %d", capture.count())
+	}
+	event := capture.events[0].(PenetrationDeepEvent)
+	if event.BodyA != ground || event.BodyB != crate {
+		t.Fatal("expected the ground then the crate")
+	}
+	if math.Abs(event.Depth-0.4) > 1e-3 {
+		t.Errorf("Depth = %v, want 0.4", event.Depth)
+	}
+	if !vec3ApproxEqual(event.Depenetration, mgl64.Vec3{0, 0.4, 0}, 1e-3) {
+		t.Errorf("Depenetration = %v, want the crate lifted by 0.4", event.Depenetration)
+	}
+}
+
+// TestWorld_JointBroken hangs a crate under a static ceiling by each joint: it holds under BreakForce,
+// and over it the world removes the joint and emits JOINT_BROKEN with the impulse, the crate falls
+func TestWorld_JointBroken(t *testing.T) {
+	type breakableJoint interface {
+		constraint.Constraint
+		constraint.Breakable
+	}
+	joints := map[string]func(ceiling, crate *actor.RigidBody, breakForce float64) breakableJoint{
+		"fixed": func(ceiling, crate *actor.RigidBody, breakForce float64) breakableJoint {
+			c := constraint.NewFixedJoint(ceiling, crate, mgl64.Vec3{0, 0.5, 0})
+			c.BreakForce = breakForce
+			return c
+		},
+		"hinge": func(ceiling, crate *actor.RigidBody, breakForce float64) breakableJoint {
+			c := constraint.NewHingeJoint(ceiling, crate, mgl64.Vec3{0, 0.5, 0}, mgl64.Vec3{0, 0, 1})
+			c.BreakForce = breakForce
+			return c
+		},
+		"cone twist": func(ceiling, crate *actor.RigidBody, breakForce float64) breakableJoint {
+			c := constraint.NewConeTwistJoint(ceiling, crate, mgl64.Vec3{0, 0.5, 0}, mgl64.Vec3{0, -1, 0})
+			c.BreakForce = breakForce
+			return c
+		},
+	}
+
+	for name, newJoint := range joints {
+		hang := func(breakForceRatio float64) (*World, *actor.RigidBody, breakableJoint, *eventCapture) {
+			world := NewWorld(WithSubsteps(4))
+			capture := &eventCapture{}
+			world.Events.Subscribe(JOINT_BROKEN, capture.capture)
+
+			ceiling := createBox(mgl64.Vec3{0, 1.5, 0}, mgl64.Vec3{1, 0.5, 1}, actor.BodyTypeStatic)
+			crate := createBox(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+			world.AddBody(ceiling)
+			world.AddBody(crate)
+
+			joint := newJoint(ceiling, crate, breakForceRatio*crate.Material.GetMass()*9.81)
+			world.AddConstraint(joint)
+			for range 60 {
+				world.Step(1.0 / 60.0)
+			}
+
+			return world, crate, joint, capture
+		}
+
+		world, crate, _, capture := hang(2)
+		if capture.count() != 0 || len(world.constraints) != 1 {
+			t.Fatalf("%s: %d JOINT_BROKEN events and %d constraints, want the joint held", name, capture.count(), len(world.constraints))
+		}
+		if crate.Transform.Position.Len() > 1e-3 {
+			t.Errorf("%s: crate at %v, want held at the origin", name, crate.Transform.Position)
+		}
+
+		world, crate, joint, capture := hang(0.5)
+		if capture.count() != 1 || len(world.constraints) != 0 {
+			t.Fatalf("%s: %d JOINT_BROKEN events and %d constraints, want the joint broken and removed", name, capture.count(), len(world.constraints))
+		}
+		event := capture.events[0].(JointBrokenEvent)
+		if event.Joint != joint || event.BodyA != world.Bodies[0] || event.BodyB != crate {
+			t.Errorf("%s: expected the joint, with the ceiling then the crate", name)
+		}
+		// Broken at the first substep, under the weight of the crate
+		h := 1.0 / 60.0 / 4
+		if impulse := crate.Material.GetMass() * 9.81 * h; math.Abs(event.Impulse-impulse) > 0.05*impulse {
+			t.Errorf("%s: Impulse = %v, want the weight over a substep %v", name, event.Impulse, impulse)
+		}
+		if crate.Transform.Position.Y() > -1 {
+			t.Errorf("%s: crate at %v, want fallen", name, crate.Transform.Position)
+		}
+	}
+}
+
+func TestOrderPair_MatchesEngineKeys(t *testing.T) {
+	world := NewWorld(WithSubsteps(1))
+	ground := createBox(mgl64.Vec3{0, -0.5, 0}, mgl64.Vec3{5, 0.5, 5}, actor.BodyTypeStatic)
+	crate := createBox(mgl64.Vec3{0, 0.45, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+	world.AddBody(ground)
+	world.AddBody(crate)
+	world.Step(1.0 / 60.0)
+
+	pair := OrderPair(crate, ground)
+	if !world.Events.previousActivePairs[pair] {
+		t.Error("expected the events to track the pair under OrderPair")
+	}
+	if _, ok := world.materials[pair]; !ok {
+		t.Error("expected the material cache to key the pair by OrderPair")
+	}
+	if (Pair{BodyA: ground, BodyB: crate}).Ordered() != pair {
+		t.Error("expected Ordered to match OrderPair")
+	}
+}
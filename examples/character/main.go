@@ -0,0 +1,129 @@
+// Character walks a sphere up a ramp and into a wall, moved by World.ShapeCast instead of the solver:
+// the motion of each frame is swept, stopped at the first hit and slid along its surface.
+//
+//	go run ./examples/character
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/akmonengine/feather"
+	"github.com/akmonengine/feather/actor"
+	"github.com/akmonengine/feather/examples/internal/ascii"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+const (
+	// Skin is the distance kept between the character and the surfaces, so the next sweep does not start inside them
+	Skin = 0.01
+	// SlideIterations is the number of sweeps per frame: the motion left after a hit is slid along the surface
+	SlideIterations = 3
+	// Speed is the walking speed (m/s)
+	Speed = 2.0
+	// FloorNormal is the minimum Y of the normal of a floor, cos(45°): steeper surfaces are walls
+	FloorNormal = 0.7
+)
+
+// Character is a sphere moved by sweeps, it is not a body of the world
+type Character struct {
+	Shape    *actor.Sphere
+	Position mgl64.Vec3
+	Velocity mgl64.Vec3
+	Grounded bool
+}
+
+// Move sweeps the character along motion, sliding along the surfaces hit
+func (c *Character) Move(world *feather.World, motion mgl64.Vec3) {
+	for i := 0; i < SlideIterations && motion.Len() > 1e-9; i++ {
+		target := c.Position.Add(motion)
+		hit, found := world.ShapeCast(c.Shape, c.Position, target, mgl64.QuatIdent(), nil)
+		if !found {
+			c.Position = target
+			return
+		}
+
+		// Stop at the hit, the skin away from the surface, and keep the motion left along the surface
+		c.Position = hit.Position.Add(hit.Normal.Mul(Skin))
+		motion = motion.Mul(1 - hit.Fraction)
+		motion = motion.Sub(hit.Normal.Mul(motion.Dot(hit.Normal)))
+
+		// The velocity into the surface is lost, a surface facing up is a floor: the fall stops
+		if into := c.Velocity.Dot(hit.Normal); into < 0 {
+			c.Velocity = c.Velocity.Sub(hit.Normal.Mul(into))
+		}
+		if hit.Normal.Y() > FloorNormal {
+			c.Velocity[1] = 0
+		}
+	}
+}
+
+// Probe tells if the character stands on a floor, with a short sweep down
+func (c *Character) Probe(world *feather.World) {
+	below := c.Position.Sub(mgl64.Vec3{0, 2 * Skin, 0})
+	hit, found := world.ShapeCast(c.Shape, c.Position, below, mgl64.QuatIdent(), nil)
+	c.Grounded = found && hit.Normal.Y() > FloorNormal
+}
+
+func main() {
+	world := feather.NewWorld()
+	rampRotation := mgl64.QuatRotate(15*math.Pi/180, mgl64.Vec3{0, 0, 1})
+	rampTransform := actor.Transform{Position: mgl64.Vec3{1, 0.15, 0}, Rotation: rampRotation, InverseRotation: rampRotation.Inverse()}
+	bodies := []*actor.RigidBody{
+		// Ground, a ramp of 15° up to a platform, and a wall
+		actor.NewRigidBody(
+			actor.Transform{Position: mgl64.Vec3{0, -0.5, 0}, Rotation: mgl64.QuatIdent()},
+			&actor.Box{HalfExtents: mgl64.Vec3{10, 0.5, 10}},
+			actor.BodyTypeStatic,
+			0,
+		),
+		actor.NewRigidBody(
+			rampTransform,
+			&actor.Box{HalfExtents: mgl64.Vec3{2, 0.25, 2}},
+			actor.BodyTypeStatic,
+			0,
+		),
+		actor.NewRigidBody(
+			actor.Transform{Position: mgl64.Vec3{4.5, 0.45, 0}, Rotation: mgl64.QuatIdent()},
+			&actor.Box{HalfExtents: mgl64.Vec3{1.65, 0.45, 2}},
+			actor.BodyTypeStatic,
+			0,
+		),
+		actor.NewRigidBody(
+			actor.Transform{Position: mgl64.Vec3{6.4, 1.5, 0}, Rotation: mgl64.QuatIdent()},
+			&actor.Box{HalfExtents: mgl64.Vec3{0.25, 1.5, 2}},
+			actor.BodyTypeStatic,
+			0,
+		),
+	}
+	for _, body := range bodies {
+		world.AddBody(body)
+	}
+
+	character := &Character{
+		Shape:    &actor.Sphere{Radius: 0.4},
+		Position: mgl64.Vec3{-3, 1.5, 0},
+	}
+
+	canvas := ascii.Canvas{Min: mgl64.Vec2{-4, -1}, Max: mgl64.Vec2{7, 3}, Columns: 88}
+	dt := 1.0 / 60.0
+	for frame := 1; frame <= 300; frame++ {
+		if err := world.Step(dt); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		// Walk to the right, fall under the gravity of the world
+		character.Velocity[0] = Speed
+		character.Velocity = character.Velocity.Add(world.Gravity.Mul(dt))
+		character.Move(world, character.Velocity.Mul(dt))
+		character.Probe(world)
+
+		if frame%30 == 0 {
+			marker := ascii.Marker{Position: character.Position, Radius: character.Shape.Radius, Glyph: 'C'}
+			title := fmt.Sprintf("t = %.1fs, character at %.2f, grounded %v", float64(frame)*dt, character.Position, character.Grounded)
+			canvas.Draw(os.Stdout, world, title, marker)
+		}
+	}
+}
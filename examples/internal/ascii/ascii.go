@@ -0,0 +1,74 @@
+// Package ascii is the renderer of the example programs: it draws the slice z = 0 of a World in the terminal,
+// one character per cell, by testing which body contains the center of each cell (see World.QueryPoint).
+package ascii
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/akmonengine/feather"
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// Canvas is a window of the XY plane, Min being the bottom left corner
+type Canvas struct {
+	Min mgl64.Vec2
+	Max mgl64.Vec2
+	// Columns is the width in characters, the height keeps the aspect ratio of a terminal cell (twice as tall as wide)
+	Columns int
+}
+
+// Glyph returns the character of a body: #' for the static bodies, '@' for the sleeping ones,
+// and the first letter of the Name (or 'o') for the others
+func Glyph(body *actor.RigidBody) byte {
+	switch {
+	case body.BodyType == actor.BodyTypeStatic:
+		return '#'
+	case body.IsSleeping:
+		return '@'
+	case body.Name != "":
+		return body.Name[0]
+	}
+
+	return 'o'
+}
+
+// Marker draws an object that is not a body of the world, e.g. a character moved by queries
+type Marker struct {
+	Position mgl64.Vec3
+	Radius   float64
+	Glyph    byte
+}
+
+// Draw writes a frame of the world and of the markers over it, with a title line
+func (c Canvas) Draw(out io.Writer, world *feather.World, title string, markers ...Marker) {
+	cell := (c.Max.X() - c.Min.X()) / float64(c.Columns)
+	rows := int((c.Max.Y() - c.Min.Y()) / (2 * cell))
+
+	var frame strings.Builder
+	frame.WriteString(title)
+	frame.WriteByte('\n')
+	for row := rows - 1; row >= 0; row-- {
+		y := c.Min.Y() + (float64(row)+0.5)*2*cell
+		for column := 0; column < c.Columns; column++ {
+			x := c.Min.X() + (float64(column)+0.5)*cell
+
+			point := mgl64.Vec3{x, y, 0}
+			glyph := byte(' ')
+			if bodies := world.QueryPoint(point, nil); len(bodies) > 0 {
+				glyph = Glyph(bodies[len(bodies)-1])
+			}
+			for _, marker := range markers {
+				if point.Sub(marker.Position).Len() <= marker.Radius {
+					glyph = marker.Glyph
+				}
+			}
+			frame.WriteByte(glyph)
+		}
+		frame.WriteByte('\n')
+	}
+
+	fmt.Fprint(out, frame.String())
+}
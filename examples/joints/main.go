@@ -0,0 +1,91 @@
+// Joints hangs bodies from a static beam: a pendulum on a hinge, a crate on a rope of boxes, and a paddle
+// turned by the motor of a hinge, then draws them swinging.
+//
+//	go run ./examples/joints
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/akmonengine/feather"
+	"github.com/akmonengine/feather/actor"
+	"github.com/akmonengine/feather/constraint"
+	"github.com/akmonengine/feather/examples/internal/ascii"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func main() {
+	world := feather.NewWorld(feather.WithSubsteps(8))
+
+	beam := actor.NewRigidBody(
+		actor.Transform{Position: mgl64.Vec3{0, 5.25, 0}, Rotation: mgl64.QuatIdent()},
+		&actor.Box{HalfExtents: mgl64.Vec3{6, 0.25, 0.5}},
+		actor.BodyTypeStatic,
+		0,
+	)
+	world.AddBody(beam)
+
+	// The pendulum (p) is released horizontal, hinged at the beam about Z
+	pendulum := actor.NewRigidBody(
+		actor.Transform{Position: mgl64.Vec3{-2, 4.75, 0}, Rotation: mgl64.QuatIdent()},
+		&actor.Box{HalfExtents: mgl64.Vec3{1.25, 0.15, 0.15}},
+		actor.BodyTypeDynamic,
+		1.0,
+	)
+	pendulum.Name = "pendulum"
+	world.AddBody(pendulum)
+	world.AddConstraint(constraint.NewHingeJoint(beam, pendulum, mgl64.Vec3{-3.25, 4.75, 0}, mgl64.Vec3{0, 0, 1}))
+
+	// The crate (c) hangs on a rope (r) tied to the beam, pushed sideways to swing
+	crate := actor.NewRigidBody(
+		actor.Transform{Position: mgl64.Vec3{1, 1.6, 0}, Rotation: mgl64.QuatIdent()},
+		&actor.Box{HalfExtents: mgl64.Vec3{0.4, 0.4, 0.4}},
+		actor.BodyTypeDynamic,
+		1.0,
+	)
+	crate.Name = "crate"
+	crate.Velocity = mgl64.Vec3{3, 0, 0}
+	world.AddBody(crate)
+	rope, err := world.AddRope(mgl64.Vec3{1, 5, 0}, mgl64.Vec3{1, 2, 0}, feather.RopeSettings{
+		Segments:  6,
+		Radius:    0.08,
+		Mass:      0.5,
+		StartBody: beam,
+		EndBody:   crate,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	for _, segment := range rope.Segments {
+		segment.Name = "rope"
+	}
+
+	// The paddle (m) is turned by the motor of its hinge, at half a turn per second
+	paddle := actor.NewRigidBody(
+		actor.Transform{Position: mgl64.Vec3{4, 3.5, 0}, Rotation: mgl64.QuatIdent()},
+		&actor.Box{HalfExtents: mgl64.Vec3{1, 0.1, 0.1}},
+		actor.BodyTypeDynamic,
+		1.0,
+	)
+	paddle.Name = "motor"
+	world.AddBody(paddle)
+	motor := constraint.NewHingeJoint(beam, paddle, mgl64.Vec3{4, 3.5, 0}, mgl64.Vec3{0, 0, 1})
+	motor.SetMotor(math.Pi, 100)
+	world.AddConstraint(motor)
+
+	canvas := ascii.Canvas{Min: mgl64.Vec2{-6, 1}, Max: mgl64.Vec2{6, 5.5}, Columns: 72}
+	dt := 1.0 / 60.0
+	for frame := 1; frame <= 180; frame++ {
+		if err := world.Step(dt); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		if frame%20 == 0 {
+			canvas.Draw(os.Stdout, world, fmt.Sprintf("t = %.2fs, paddle at %.0f°", float64(frame)*dt, motor.Angle()*180/math.Pi))
+		}
+	}
+}
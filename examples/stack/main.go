@@ -0,0 +1,71 @@
+// Stack drops a pyramid of boxes on the ground and draws it until it falls asleep.
+//
+//	go run ./examples/stack
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/akmonengine/feather"
+	"github.com/akmonengine/feather/actor"
+	"github.com/akmonengine/feather/examples/internal/ascii"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func main() {
+	world := feather.NewWorld(feather.WithSubsteps(8))
+
+	ground := actor.NewRigidBody(
+		actor.Transform{Position: mgl64.Vec3{0, -0.5, 0}, Rotation: mgl64.QuatIdent()},
+		&actor.Box{HalfExtents: mgl64.Vec3{10, 0.5, 10}},
+		actor.BodyTypeStatic,
+		0,
+	)
+	ground.Material.StaticFriction, ground.Material.DynamicFriction = 0.6, 0.5
+	world.AddBody(ground)
+
+	// A pyramid of 5 rows, each box slightly above the previous row and apart from its neighbours.
+	// The rows are named a to e, drawn with their letter.
+	const rows = 5
+	for row := 0; row < rows; row++ {
+		for column := 0; column < rows-row; column++ {
+			x := float64(column) - float64(rows-row-1)/2
+			box := actor.NewRigidBody(
+				actor.Transform{Position: mgl64.Vec3{x * 1.05, 0.5 + float64(row)*1.02, 0}, Rotation: mgl64.QuatIdent()},
+				&actor.Box{HalfExtents: mgl64.Vec3{0.5, 0.5, 0.5}},
+				actor.BodyTypeDynamic,
+				1.0,
+			)
+			box.Name = fmt.Sprintf("%c%d", 'a'+row, column)
+			box.Material.StaticFriction, box.Material.DynamicFriction = 0.6, 0.5
+			world.AddBody(box)
+		}
+	}
+
+	canvas := ascii.Canvas{Min: mgl64.Vec2{-4, -1}, Max: mgl64.Vec2{4, 6}, Columns: 64}
+	dt := 1.0 / 60.0
+	for frame := 1; frame <= 300; frame++ {
+		if err := world.Step(dt); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		// The queries of the renderer see the world as of the last Step
+		if frame%30 == 0 {
+			canvas.Draw(os.Stdout, world, fmt.Sprintf("t = %.1fs, %d bodies asleep", float64(frame)*dt, sleeping(world)))
+		}
+	}
+}
+
+// sleeping counts the dynamic bodies asleep, drawn with '@'
+func sleeping(world *feather.World) int {
+	count := 0
+	for _, body := range world.Bodies {
+		if body.BodyType == actor.BodyTypeDynamic && body.IsSleeping {
+			count++
+		}
+	}
+
+	return count
+}
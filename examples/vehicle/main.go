@@ -0,0 +1,89 @@
+// Vehicle drives a car over a bump: a box chassis and two sphere wheels on hinges, the rear wheel
+// turned by the motor of its hinge. The view follows the car.
+//
+//	go run ./examples/vehicle
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/akmonengine/feather"
+	"github.com/akmonengine/feather/actor"
+	"github.com/akmonengine/feather/constraint"
+	"github.com/akmonengine/feather/examples/internal/ascii"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// WheelSpeed is the angular velocity (rad/s) of the motor, negative about Z to roll toward +X
+const WheelSpeed = -8.0
+
+func main() {
+	world := feather.NewWorld(feather.WithSubsteps(8))
+
+	// The ground, and a bump made of a box rotated by 45°, half buried
+	bumpRotation := mgl64.QuatRotate(math.Pi/4, mgl64.Vec3{0, 0, 1})
+	for _, body := range []*actor.RigidBody{
+		actor.NewRigidBody(
+			actor.Transform{Position: mgl64.Vec3{20, -0.5, 0}, Rotation: mgl64.QuatIdent()},
+			&actor.Box{HalfExtents: mgl64.Vec3{30, 0.5, 5}},
+			actor.BodyTypeStatic,
+			0,
+		),
+		actor.NewRigidBody(
+			actor.Transform{Position: mgl64.Vec3{8, -0.5, 0}, Rotation: bumpRotation, InverseRotation: bumpRotation.Inverse()},
+			&actor.Box{HalfExtents: mgl64.Vec3{1, 1, 5}},
+			actor.BodyTypeStatic,
+			0,
+		),
+	} {
+		body.Material.StaticFriction, body.Material.DynamicFriction = 0.8, 0.6
+		world.AddBody(body)
+	}
+
+	chassis := actor.NewRigidBody(
+		actor.Transform{Position: mgl64.Vec3{0, 0.9, 0}, Rotation: mgl64.QuatIdent()},
+		&actor.Box{HalfExtents: mgl64.Vec3{1.2, 0.25, 0.5}},
+		actor.BodyTypeDynamic,
+		1.0,
+	)
+	chassis.Name = "chassis"
+	world.AddBody(chassis)
+
+	// The wheels (w) are hinged to the chassis about Z, the joints skip the collisions with the chassis
+	var motor *constraint.HingeJoint
+	for _, x := range []float64{-0.9, 0.9} {
+		wheel := actor.NewRigidBody(
+			actor.Transform{Position: mgl64.Vec3{x, 0.45, 0}, Rotation: mgl64.QuatIdent()},
+			&actor.Sphere{Radius: 0.45},
+			actor.BodyTypeDynamic,
+			1.0,
+		)
+		wheel.Name = "wheel"
+		wheel.Material.StaticFriction, wheel.Material.DynamicFriction = 1.0, 0.8
+		world.AddBody(wheel)
+
+		hinge := constraint.NewHingeJoint(chassis, wheel, wheel.Transform.Position, mgl64.Vec3{0, 0, 1})
+		hinge.DisableCollision = true
+		world.AddConstraint(hinge)
+		if x < 0 {
+			motor = hinge
+		}
+	}
+	motor.SetMotor(WheelSpeed, 50)
+
+	dt := 1.0 / 60.0
+	for frame := 1; frame <= 240; frame++ {
+		if err := world.Step(dt); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		if frame%20 == 0 {
+			x := chassis.Transform.Position.X()
+			canvas := ascii.Canvas{Min: mgl64.Vec2{x - 5, -0.5}, Max: mgl64.Vec2{x + 5, 3}, Columns: 60}
+			canvas.Draw(os.Stdout, world, fmt.Sprintf("t = %.2fs, x = %.1fm, %.1fm/s", float64(frame)*dt, x, chassis.Velocity.X()))
+		}
+	}
+}
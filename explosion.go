@@ -0,0 +1,81 @@
+package feather
+
+import (
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+const (
+	// FALLOFF_CONSTANT keeps the full strength up to the radius
+	FALLOFF_CONSTANT Falloff = iota
+	// FALLOFF_LINEAR decreases linearly from the full strength at the center to 0 at the radius
+	FALLOFF_LINEAR
+	// FALLOFF_QUADRATIC decreases with the square of the linear falloff, most of the strength stays near the center
+	FALLOFF_QUADRATIC
+)
+
+// Falloff is the decrease of an effect with the distance from its center, see World.ApplyRadialImpulse
+type Falloff uint8
+
+// Scale returns the part of the strength at distance from the center, in [0, 1]:
+// 0 beyond radius, and 1 everywhere for a radius of 0
+func (falloff Falloff) Scale(distance, radius float64) float64 {
+	if radius <= 0 {
+		return 1
+	}
+	if distance >= radius {
+		return 0
+	}
+
+	switch falloff {
+	case FALLOFF_LINEAR:
+		return 1 - distance/radius
+	case FALLOFF_QUADRATIC:
+		linear := 1 - distance/radius
+		return linear * linear
+	default:
+		return 1
+	}
+}
+
+// ApplyRadialImpulse blows the dynamic bodies within radius of center away from it, e.g. an explosion,
+// and returns them in world order. Each body receives an impulse of strength (N⋅s) scaled by the falloff,
+// at its point closest to the center: a body hit off its center of mass is also spun. The bodies are woken up.
+//
+// The candidates come from the Broadphase, as of the last Step: it is called between two Steps.
+// The triggers are ignored, and a radius of 0 reaches no body.
+func (w *World) ApplyRadialImpulse(center mgl64.Vec3, radius, strength float64, falloff Falloff) []*actor.RigidBody {
+	if !(radius > 0) {
+		return nil
+	}
+
+	extent := mgl64.Vec3{radius, radius, radius}
+	region := actor.AABB{Min: center.Sub(extent), Max: center.Add(extent)}
+	candidates := w.QueryAABB(region, func(body *actor.RigidBody) bool {
+		return body.BodyType == actor.BodyTypeDynamic && !body.IsTrigger
+	})
+
+	var hit []*actor.RigidBody
+	for _, body := range candidates {
+		point := body.ClosestPointWorld(center)
+		direction := point.Sub(center)
+		distance := direction.Len()
+		if distance < 1e-9 {
+			// The center is inside the body: it is pushed from its center of mass, without spin
+			point = body.Transform.Position
+			direction = point.Sub(center)
+			if direction.Len() < 1e-9 {
+				continue
+			}
+		}
+
+		scale := falloff.Scale(distance, radius)
+		if scale <= 0 {
+			continue
+		}
+		body.ApplyImpulseAtPoint(direction.Normalize().Mul(strength*scale), point)
+		hit = append(hit, body)
+	}
+
+	return hit
+}
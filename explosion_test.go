@@ -0,0 +1,70 @@
+package feather
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestFalloff_Scale(t *testing.T) {
+	tests := []struct {
+		falloff  Falloff
+		distance float64
+		want     float64
+	}{
+		{FALLOFF_CONSTANT, 3, 1},
+		{FALLOFF_LINEAR, 0, 1},
+		{FALLOFF_LINEAR, 1, 0.75},
+		{FALLOFF_QUADRATIC, 2, 0.25},
+		{FALLOFF_CONSTANT, 4, 0},
+		{FALLOFF_LINEAR, 5, 0},
+	}
+	for _, test := range tests {
+		if scale := test.falloff.Scale(test.distance, 4); math.Abs(scale-test.want) > 1e-12 {
+			t.Errorf("falloff %d at %v = %v, want %v", test.falloff, test.distance, scale, test.want)
+		}
+	}
+}
+
+func TestWorld_ApplyRadialImpulse(t *testing.T) {
+	near := createSphere(mgl64.Vec3{2, 0, 0}, 0.5, actor.BodyTypeDynamic)
+	sleeping := createSphere(mgl64.Vec3{0, 0, 2}, 0.5, actor.BodyTypeDynamic)
+	offCenter := createBox(mgl64.Vec3{-2, 1, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+	far := createSphere(mgl64.Vec3{8, 0, 0}, 0.5, actor.BodyTypeDynamic)
+	static := createBox(mgl64.Vec3{0, -2, 0}, mgl64.Vec3{1, 0.5, 1}, actor.BodyTypeStatic)
+	world := createQueryWorld(near, sleeping, offCenter, far, static)
+	sleeping.Sleep()
+
+	hit := world.ApplyRadialImpulse(mgl64.Vec3{}, 4, 10, FALLOFF_LINEAR)
+	if len(hit) != 3 || !slices.Contains(hit, near) || !slices.Contains(hit, sleeping) || !slices.Contains(hit, offCenter) {
+		t.Fatalf("hit %d bodies, want the 3 dynamic bodies in the radius", len(hit))
+	}
+
+	// The near sphere is hit at its surface, 1.5m from the center: 10 × (1 - 1.5/4) N⋅s away from it, without spin
+	want := mgl64.Vec3{10 * 0.625 / near.Material.GetMass(), 0, 0}
+	if !vec3ApproxEqual(near.Velocity, want, 1e-9) {
+		t.Errorf("near velocity = %v, want %v", near.Velocity, want)
+	}
+	if near.AngularVelocity.Len() > 1e-9 {
+		t.Errorf("near angular velocity = %v, want no spin", near.AngularVelocity)
+	}
+
+	// The sleeping body is woken up, the box hit below its center of mass is spun
+	if sleeping.IsSleeping || sleeping.Velocity.Z() <= 0 {
+		t.Errorf("sleeping body velocity %v, sleeping %v, want woken up and pushed", sleeping.Velocity, sleeping.IsSleeping)
+	}
+	if offCenter.Velocity.X() >= 0 || offCenter.AngularVelocity.Len() < 1e-6 {
+		t.Errorf("off center box velocity %v, angular %v, want pushed and spun", offCenter.Velocity, offCenter.AngularVelocity)
+	}
+
+	// The bodies beyond the radius and the static ones are not affected
+	if far.Velocity != (mgl64.Vec3{}) || static.Velocity != (mgl64.Vec3{}) {
+		t.Errorf("far velocity %v, static velocity %v, want untouched", far.Velocity, static.Velocity)
+	}
+	if hit := world.ApplyRadialImpulse(mgl64.Vec3{}, 0, 10, FALLOFF_CONSTANT); len(hit) != 0 {
+		t.Errorf("radius 0 hit %d bodies, want none", len(hit))
+	}
+}
@@ -0,0 +1,124 @@
+package feather
+
+import (
+	"slices"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// ForceField acts on the bodies at each substep, before integration: wind, magnets, explosions, whirlpools.
+// Apply is called for each dynamic, awake body, concurrently for different bodies, with the duration dt (s)
+// of the substep. It changes the velocities of the body, e.g. body.ApplyImpulse(force.Mul(dt)) for a force in N.
+// Unlike an AccelerationProvider, a field knows the mass of the body and may spin it.
+//
+// The impulses keep the bodies awake: a field with an unbounded reach keeps all the bodies moving.
+// A sleeping body is not exposed to the fields until it is woken up, e.g. when the wind starts.
+type ForceField interface {
+	Apply(body *actor.RigidBody, dt float64)
+}
+
+// AddForceField registers a field applied at each substep
+func (w *World) AddForceField(field ForceField) {
+	w.forceFields = append(w.forceFields, field)
+}
+
+// RemoveForceField unregisters a field, it must be comparable (e.g. a pointer)
+func (w *World) RemoveForceField(field ForceField) {
+	w.forceFields = slices.DeleteFunc(w.forceFields, func(other ForceField) bool {
+		return other == field
+	})
+}
+
+// applyForceFields applies the fields to the body, before its integration
+func (w *World) applyForceFields(body *actor.RigidBody, dt float64) {
+	if !body.IsActive() {
+		return
+	}
+
+	for _, field := range w.forceFields {
+		field.Apply(body, dt)
+	}
+}
+
+// WindField drags the bodies toward the velocity of the wind: the force is Drag times the velocity of the wind
+// relative to the body, so a body carried at the speed of the wind is no longer pushed
+type WindField struct {
+	// Velocity (m/s) of the wind
+	Velocity mgl64.Vec3
+	// Drag (kg/s) converts the relative velocity into a force
+	Drag float64
+}
+
+// Apply pushes the body toward the velocity of the wind, see ForceField
+func (f *WindField) Apply(body *actor.RigidBody, dt float64) {
+	relative := f.Velocity.Sub(body.Velocity)
+	body.ApplyImpulse(relative.Mul(f.Drag * dt))
+}
+
+// RadialField attracts the bodies toward its center (a magnet, a black hole) or repels them (a blast wave)
+type RadialField struct {
+	Center mgl64.Vec3
+	// Strength (N) of the force at the center, positive attracts and negative repels
+	Strength float64
+	// Radius (m) of the field, the force falls off linearly to 0 at it; 0 reaches everywhere at full strength
+	Radius float64
+}
+
+// Apply pulls the body toward the center, or pushes it away, see ForceField
+func (f *RadialField) Apply(body *actor.RigidBody, dt float64) {
+	offset := f.Center.Sub(body.Transform.Position)
+	distance := offset.Len()
+	if distance < 1e-9 {
+		return
+	}
+
+	scale := FALLOFF_LINEAR.Scale(distance, f.Radius)
+	if scale > 0 {
+		body.ApplyImpulse(offset.Mul(f.Strength * scale * dt / distance))
+	}
+}
+
+// ShiftOrigin translates the center, see World.ShiftOrigin
+func (f *RadialField) ShiftOrigin(offset mgl64.Vec3) {
+	f.Center = f.Center.Add(offset)
+}
+
+// VortexField spins the bodies around an axis (a tornado, a whirlpool): the force is tangential to the circles
+// around the axis, plus an optional pull toward it
+type VortexField struct {
+	Center mgl64.Vec3
+	// Axis is the unit axis of the rotation, counterclockwise seen from its tip
+	Axis mgl64.Vec3
+	// Strength (N) of the tangential force at the axis
+	Strength float64
+	// Pull (N) toward the axis at the axis, negative pushes away
+	Pull float64
+	// Radius (m) from the axis, the forces fall off linearly to 0 at it; 0 reaches everywhere at full strength
+	Radius float64
+}
+
+// Apply turns the body around the axis, see ForceField
+func (f *VortexField) Apply(body *actor.RigidBody, dt float64) {
+	// The offset of the body from the axis, orthogonal to it
+	offset := body.Transform.Position.Sub(f.Center)
+	offset = offset.Sub(f.Axis.Mul(offset.Dot(f.Axis)))
+	distance := offset.Len()
+	if distance < 1e-9 {
+		return
+	}
+
+	scale := FALLOFF_LINEAR.Scale(distance, f.Radius)
+	if scale <= 0 {
+		return
+	}
+	radial := offset.Mul(1 / distance)
+	tangent := f.Axis.Cross(radial)
+	force := tangent.Mul(f.Strength).Sub(radial.Mul(f.Pull))
+	body.ApplyImpulse(force.Mul(scale * dt))
+}
+
+// ShiftOrigin translates the center, see World.ShiftOrigin
+func (f *VortexField) ShiftOrigin(offset mgl64.Vec3) {
+	f.Center = f.Center.Add(offset)
+}
@@ -0,0 +1,117 @@
+package feather
+
+import (
+	"math"
+	"testing"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestWorld_ForceFields(t *testing.T) {
+	body := createSphere(mgl64.Vec3{0, 10, 0}, 0.5, actor.BodyTypeDynamic)
+	static := createSphere(mgl64.Vec3{5, 10, 0}, 0.5, actor.BodyTypeStatic)
+	world := &World{
+		Substeps:    1,
+		SpatialGrid: NewSpatialGrid(1.0, 1024),
+		Events:      NewEvents(),
+	}
+	world.AddBody(body)
+	world.AddBody(static)
+	wind := &WindField{Velocity: mgl64.Vec3{10, 0, 0}, Drag: body.Material.GetMass()}
+	world.AddForceField(wind)
+
+	// A drag of m kg/s in a 10 m/s wind accelerates the body at 10 m/s² from rest
+	world.Step(0.01)
+	if !vec3ApproxEqual(body.Velocity, mgl64.Vec3{0.1, 0, 0}, 1e-9) {
+		t.Errorf("velocity = %v, want pushed by the wind", body.Velocity)
+	}
+	if static.Velocity != (mgl64.Vec3{}) {
+		t.Errorf("static body moved by the wind")
+	}
+
+	// Removed, the field no longer acts
+	world.RemoveForceField(wind)
+	world.Step(0.01)
+	if !vec3ApproxEqual(body.Velocity, mgl64.Vec3{0.1, 0, 0}, 1e-9) {
+		t.Errorf("velocity = %v, want unchanged without the field", body.Velocity)
+	}
+}
+
+func TestWorld_ForceFields_SkipsSleeping(t *testing.T) {
+	world := &World{}
+	sleeping := createBox(mgl64.Vec3{}, mgl64.Vec3{1, 1, 1}, actor.BodyTypeDynamic)
+	sleeping.IsSleeping = true
+	world.AddForceField(&WindField{Velocity: mgl64.Vec3{10, 0, 0}, Drag: 1})
+
+	world.applyForceFields(sleeping, 0.1)
+	if sleeping.Velocity != (mgl64.Vec3{}) || !sleeping.IsSleeping {
+		t.Errorf("sleeping body pushed by the field, velocity %v", sleeping.Velocity)
+	}
+}
+
+func TestWindField(t *testing.T) {
+	field := &WindField{Velocity: mgl64.Vec3{5, 0, 0}, Drag: 2}
+
+	// Carried at the speed of the wind, the body is no longer pushed
+	body := createSphere(mgl64.Vec3{}, 0.5, actor.BodyTypeDynamic)
+	body.Velocity = mgl64.Vec3{5, 0, 0}
+	field.Apply(body, 0.1)
+	if !vec3ApproxEqual(body.Velocity, mgl64.Vec3{5, 0, 0}, 1e-12) {
+		t.Errorf("velocity = %v, want unchanged at the speed of the wind", body.Velocity)
+	}
+
+	// Against the wind, it is slowed down
+	body.Velocity = mgl64.Vec3{-5, 0, 0}
+	field.Apply(body, 0.1)
+	if want := -5 + 2*10*0.1/body.Material.GetMass(); math.Abs(body.Velocity.X()-want) > 1e-12 {
+		t.Errorf("velocity = %v, want %v", body.Velocity.X(), want)
+	}
+}
+
+func TestRadialField(t *testing.T) {
+	field := &RadialField{Center: mgl64.Vec3{}, Strength: 10, Radius: 4}
+
+	t.Run("attracts with falloff", func(t *testing.T) {
+		body := createSphere(mgl64.Vec3{2, 0, 0}, 0.5, actor.BodyTypeDynamic)
+		field.Apply(body, 0.1)
+
+		// Half way to the radius, half the strength toward the center
+		want := mgl64.Vec3{-10 * 0.5 * 0.1 / body.Material.GetMass(), 0, 0}
+		if !vec3ApproxEqual(body.Velocity, want, 1e-12) {
+			t.Errorf("velocity = %v, want %v", body.Velocity, want)
+		}
+	})
+
+	t.Run("repels", func(t *testing.T) {
+		repel := &RadialField{Center: mgl64.Vec3{}, Strength: -10}
+		body := createSphere(mgl64.Vec3{0, 0, 100}, 0.5, actor.BodyTypeDynamic)
+		repel.Apply(body, 0.1)
+
+		if body.Velocity.Z() <= 0 || body.Velocity.X() != 0 || body.Velocity.Y() != 0 {
+			t.Errorf("velocity = %v, want pushed away at any distance", body.Velocity)
+		}
+	})
+
+	t.Run("out of reach", func(t *testing.T) {
+		body := createSphere(mgl64.Vec3{5, 0, 0}, 0.5, actor.BodyTypeDynamic)
+		field.Apply(body, 0.1)
+
+		if body.Velocity != (mgl64.Vec3{}) || body.SleepTimer != 0 {
+			t.Errorf("velocity = %v, want untouched beyond the radius", body.Velocity)
+		}
+	})
+}
+
+func TestVortexField(t *testing.T) {
+	field := &VortexField{Center: mgl64.Vec3{0, -3, 0}, Axis: mgl64.Vec3{0, 1, 0}, Strength: 10, Pull: 4}
+	body := createSphere(mgl64.Vec3{2, 0, 0}, 0.5, actor.BodyTypeDynamic)
+	field.Apply(body, 0.1)
+
+	// Counterclockwise around +Y from +X is toward -Z, and the pull toward the axis is along -X
+	mass := body.Material.GetMass()
+	want := mgl64.Vec3{-4 * 0.1 / mass, 0, -10 * 0.1 / mass}
+	if !vec3ApproxEqual(body.Velocity, want, 1e-12) {
+		t.Errorf("velocity = %v, want %v", body.Velocity, want)
+	}
+}
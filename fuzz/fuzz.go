@@ -0,0 +1,199 @@
+// Package fuzz is a fuzzing harness for the collision core: it decodes random shape pairs from bytes,
+// runs them through GJK and EPA like the narrow phase of the World, and checks the invariants of the contacts.
+//
+// Collide is compatible with go test -fuzz, e.g. from a test of any package:
+//
+//	func FuzzCollide(f *testing.F) {
+//		f.Fuzz(func(t *testing.T, data []byte) {
+//			if err := fuzz.Collide(data); err != nil {
+//				t.Fatal(err)
+//			}
+//		})
+//	}
+//
+// The failing inputs found are added to testdata/fuzz by go test, they can be replayed with Decode and Check.
+package fuzz
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"runtime/debug"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/akmonengine/feather/epa"
+	"github.com/akmonengine/feather/gjk"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+const (
+	// MaxExtent bounds the sizes of the decoded shapes, the smallest is MaxExtent/1000 (thin boxes, tiny spheres)
+	MaxExtent = 5.0
+	// MaxOffset bounds the coordinates of the decoded positions, small enough for most pairs to overlap
+	MaxOffset = 4.0
+	// Tolerance on the invariants, relative to the size of the shapes
+	Tolerance = 1e-6
+)
+
+// Collide decodes a pair of bodies from data and checks the contact computed between them.
+// Returns nil if data does not decode into valid shapes, e.g. a flat convex hull.
+func Collide(data []byte) error {
+	a, b, ok := Decode(data)
+	if !ok {
+		return nil
+	}
+
+	return Check(a, b)
+}
+
+// Decode builds two bodies from data: a shape (box, sphere, cone or convex hull) with its sizes,
+// a collision margin, a position and a rotation each. Missing bytes read as zeros, any input is valid,
+// except the convex hulls whose points do not enclose a volume.
+func Decode(data []byte) (*actor.RigidBody, *actor.RigidBody, bool) {
+	d := decoder{data: data}
+
+	a, ok := d.body()
+	if !ok {
+		return nil, nil, false
+	}
+	b, ok := d.body()
+	if !ok {
+		return nil, nil, false
+	}
+
+	return a, b, true
+}
+
+// Check runs GJK then EPA on the bodies, and returns the first invariant broken by the contact:
+//   - no panic
+//   - the normal is normalized
+//   - the depths are finite and not negative
+//   - the manifold points are within the AABBs of both bodies, inflated by the depth and the margins
+func Check(a, b *actor.RigidBody) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("fuzz: panic %v\n%s", r, debug.Stack())
+		}
+	}()
+
+	simplex := &gjk.Simplex{}
+	if !gjk.GJK(a, b, simplex) {
+		return nil
+	}
+
+	contact, err := epa.EPA(a, b, simplex)
+	if err != nil {
+		// EPA may reject a pair, the narrow phase then skips it
+		return nil
+	}
+
+	scale := math.Max(1, math.Max(a.Shape.BoundingRadius(), b.Shape.BoundingRadius()))
+	if math.Abs(contact.Normal.Len()-1) > Tolerance {
+		return fmt.Errorf("fuzz: normal %v is not normalized (length %v)", contact.Normal, contact.Normal.Len())
+	}
+	for _, point := range contact.Points {
+		if math.IsNaN(point.Penetration) || math.IsInf(point.Penetration, 0) || point.Penetration < 0 {
+			return fmt.Errorf("fuzz: invalid depth %v at %v", point.Penetration, point.Position)
+		}
+
+		// The AABBs do not include the collision margins, the contacts are on the inflated shapes.
+		// EPA stops within EPAConvergenceTolerance of the depth: on a round shape, the normal then tilts
+		// and moves the contact sideways by up to sqrt(2 * tolerance * radius)
+		inflation := point.Penetration + actor.ShapeMargin(a.Shape) + actor.ShapeMargin(b.Shape) +
+			math.Sqrt(2*epa.EPAConvergenceTolerance*scale) + Tolerance*scale
+		for _, body := range [2]*actor.RigidBody{a, b} {
+			aabb := body.Shape.GetAABB()
+			for i := 0; i < 3; i++ {
+				if !(point.Position[i] >= aabb.Min[i]-inflation && point.Position[i] <= aabb.Max[i]+inflation) {
+					return fmt.Errorf("fuzz: point %v outside of the AABB %v of %T, inflated by %v",
+						point.Position, aabb, body.Shape, inflation)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// decoder reads the values of the bodies from the fuzzer bytes
+type decoder struct {
+	data []byte
+}
+
+// unit reads a value in [0, 1]
+func (d *decoder) unit() float64 {
+	var buffer [2]byte
+	n := copy(buffer[:], d.data)
+	d.data = d.data[n:]
+
+	return float64(binary.LittleEndian.Uint16(buffer[:])) / math.MaxUint16
+}
+
+// between reads a value in [low, high]
+func (d *decoder) between(low, high float64) float64 {
+	return low + d.unit()*(high-low)
+}
+
+// extent reads a size, on a logarithmic scale to reach the degenerate ratios
+func (d *decoder) extent() float64 {
+	return MaxExtent * math.Pow(1000, -d.unit())
+}
+
+func (d *decoder) vec3(low, high float64) mgl64.Vec3 {
+	return mgl64.Vec3{d.between(low, high), d.between(low, high), d.between(low, high)}
+}
+
+func (d *decoder) rotation() mgl64.Quat {
+	q := mgl64.Quat{W: d.between(-1, 1), V: d.vec3(-1, 1)}
+	if q.Len() < 1e-6 {
+		return mgl64.QuatIdent()
+	}
+
+	return q.Normalize()
+}
+
+func (d *decoder) shape() (actor.ShapeInterface, bool) {
+	kind := int(d.unit() * 4)
+
+	switch kind {
+	case 0:
+		box := &actor.Box{HalfExtents: mgl64.Vec3{d.extent(), d.extent(), d.extent()}}
+		box.SetMargin(d.between(0, 0.1))
+		return box, true
+	case 1:
+		sphere := &actor.Sphere{Radius: d.extent()}
+		sphere.SetMargin(d.between(0, 0.1))
+		return sphere, true
+	case 2:
+		cone := &actor.Cone{Radius: d.extent(), Height: d.extent()}
+		cone.SetMargin(d.between(0, 0.1))
+		return cone, true
+	default:
+		points := make([]mgl64.Vec3, 4+int(d.unit()*4))
+		for i := range points {
+			points[i] = d.vec3(-MaxExtent, MaxExtent)
+		}
+		hull, err := actor.NewConvexHull(points)
+		if err != nil {
+			return nil, false
+		}
+		hull.SetMargin(d.between(0, 0.1))
+		return hull, true
+	}
+}
+
+func (d *decoder) body() (*actor.RigidBody, bool) {
+	shape, ok := d.shape()
+	if !ok {
+		return nil, false
+	}
+
+	rotation := d.rotation()
+	transform := actor.Transform{
+		Position:        d.vec3(-MaxOffset, MaxOffset),
+		Rotation:        rotation,
+		InverseRotation: rotation.Inverse(),
+	}
+
+	return actor.NewRigidBody(transform, shape, actor.BodyTypeDynamic, 1.0), true
+}
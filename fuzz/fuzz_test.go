@@ -0,0 +1,52 @@
+package fuzz
+
+import (
+	"testing"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func FuzzCollide(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0x00, 0x00, 0xff, 0xff, 0xff, 0xff, 0x00, 0x00})
+	f.Add([]byte("box against a sphere at the same position"))
+	f.Add([]byte{0xff, 0xbf, 0x10, 0x20, 0x30, 0x40, 0x50, 0x60, 0x70, 0x80, 0x90, 0xa0, 0xb0, 0xc0, 0xd0, 0xe0, 0xf0})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if err := Collide(data); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestDecode(t *testing.T) {
+	a, b, ok := Decode(nil)
+	if !ok {
+		t.Fatal("expected empty data to decode")
+	}
+	if box, ok := a.Shape.(*actor.Box); !ok || box.HalfExtents != (mgl64.Vec3{MaxExtent, MaxExtent, MaxExtent}) {
+		t.Errorf("expected the largest box, got %+v", a.Shape)
+	}
+	if b.Transform.Position != a.Transform.Position {
+		t.Errorf("expected the same positions, got %v and %v", a.Transform.Position, b.Transform.Position)
+	}
+
+	// A flat convex hull: the kind is read first, then the count of points, all at zero
+	if _, _, ok := Decode([]byte{0xff, 0xff}); ok {
+		t.Error("expected a degenerate hull to be rejected")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	a, b, _ := Decode(nil)
+	if err := Check(a, b); err != nil {
+		t.Errorf("Check on coincident boxes: %v", err)
+	}
+
+	// A broken shape surfaces its panic as an error
+	a.Shape = &actor.ConvexHull{}
+	if err := Check(a, b); err == nil {
+		t.Error("expected the panic of an empty hull to be reported")
+	}
+}
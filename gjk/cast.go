@@ -0,0 +1,123 @@
+package gjk
+
+import (
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// CastMaxIterations caps the refinement loop of Cast.
+// Typical convergence is under 10 iterations, curved shapes converge slower near grazing hits.
+const CastMaxIterations = 64
+
+// CastTolerance is the distance under which the swept shape is considered in contact
+const CastTolerance = 1e-6
+
+// CastReport describes the first time of impact of a shape swept against another one
+type CastReport struct {
+	// Fraction of the translation travelled at the time of impact, in [0, 1].
+	// Shapes overlapping at the start of the sweep hit at 0.
+	Fraction float64
+	// Point is the world-space contact point on b at the time of impact
+	Point mgl64.Vec3
+	// Normal is the world-space surface normal of b at Point, pointing toward a (normalized).
+	// Shapes overlapping at the start of the sweep report the normal opposed to the translation.
+	Normal mgl64.Vec3
+	// Iterations is the number of refinement iterations run
+	Iterations int
+}
+
+// Cast sweeps a along translation, from its current transform, against b at rest,
+// and returns the first time of impact.
+//
+// It is the GJK ray cast of van den Bergen: a ray from the origin along translation is cast
+// against the Minkowski difference B - A. Each support point either advances the ray toward the
+// difference (conservative advancement, never past the first hit), or refines the simplex around
+// the ray point, until the point lies within CastTolerance of the difference.
+//
+// The collision margins of the shapes are ignored, the sweep hits their core shapes.
+//
+// Returns false if a never touches b along the translation.
+//
+// References:
+//   - Van den Bergen: "Ray Casting against General Convex Objects with Application to
+//     Continuous Collision Detection" (2004)
+func Cast(a, b *actor.RigidBody, translation mgl64.Vec3) (CastReport, bool) {
+	// Ray point x = lambda * translation, and simplex points y of B - A with the support point of b giving them
+	var lambda float64
+	var x, normal mgl64.Vec3
+	var points, supportsB [4]mgl64.Vec3
+	count := 0
+
+	// Any point of B - A starts the search, the difference of the positions is inside it
+	v := x.Sub(b.Transform.Position.Sub(a.Transform.Position))
+	var weights [4]float64
+
+	iterations := 0
+	for ; iterations < CastMaxIterations && v.LenSqr() > CastTolerance*CastTolerance; iterations++ {
+		supportB := b.SupportWorld(v)
+		point := supportB.Sub(a.SupportWorld(v.Mul(-1)))
+
+		// The ray point is beyond the supporting plane of B - A: advance it along the ray up to the plane
+		if w := x.Sub(point); v.Dot(w) > 0 {
+			vr := v.Dot(translation)
+			if vr >= 0 {
+				return CastReport{Iterations: iterations + 1}, false
+			}
+
+			lambda -= v.Dot(w) / vr
+			if lambda > 1 {
+				return CastReport{Iterations: iterations + 1}, false
+			}
+			x = translation.Mul(lambda)
+			normal = v
+		}
+
+		if count == len(points) {
+			break
+		}
+		points[count], supportsB[count] = point, supportB
+		count++
+
+		// The closest point of the simplex to the ray point gives the next direction.
+		// The simplex is expressed around x, which moved since the points were added.
+		simplex := Simplex{Count: count}
+		for i := 0; i < count; i++ {
+			simplex.Points[i] = x.Sub(points[i])
+		}
+		v, weights = simplex.closestPoint()
+
+		// Only the points supporting the closest feature are kept
+		kept := 0
+		for i := 0; i < count; i++ {
+			if weights[i] > 0 {
+				points[kept], supportsB[kept], weights[kept] = points[i], supportsB[i], weights[i]
+				kept++
+			}
+		}
+		count = kept
+	}
+
+	report := CastReport{Fraction: lambda, Iterations: iterations}
+
+	// The witness point on b is the combination of its support points
+	total := 0.0
+	for i := 0; i < count; i++ {
+		report.Point = report.Point.Add(supportsB[i].Mul(weights[i]))
+		total += weights[i]
+	}
+	if total > 0 {
+		report.Point = report.Point.Mul(1 / total)
+	} else {
+		report.Point = b.SupportWorld(translation.Mul(-1))
+	}
+
+	if lambda == 0 || normal.LenSqr() < 1e-24 {
+		if length := translation.Len(); length > 0 {
+			report.Normal = translation.Mul(-1 / length)
+		}
+	} else {
+		report.Normal = normal.Normalize()
+	}
+
+	return report, true
+}
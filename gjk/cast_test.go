@@ -0,0 +1,118 @@
+package gjk
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestCast(t *testing.T) {
+	t.Run("sphere against box", func(t *testing.T) {
+		a := createSphereBody(mgl64.Vec3{0, 0, 0}, 1)
+		b := createBoxBody(mgl64.Vec3{5, 0, 0}, mgl64.Vec3{1, 1, 1})
+
+		report, ok := Cast(a, b, mgl64.Vec3{10, 0, 0})
+		if !ok {
+			t.Fatal("expected a hit")
+		}
+		if math.Abs(report.Fraction-0.3) > 1e-4 {
+			t.Errorf("Fraction = %v, want 0.3", report.Fraction)
+		}
+		if !report.Normal.ApproxEqualThreshold(mgl64.Vec3{-1, 0, 0}, 1e-4) {
+			t.Errorf("Normal = %v, want (-1, 0, 0)", report.Normal)
+		}
+		if math.Abs(report.Point.X()-4) > 1e-4 {
+			t.Errorf("Point = %v, want on the face x = 4", report.Point)
+		}
+	})
+
+	t.Run("box against box, diagonal", func(t *testing.T) {
+		a := createBoxBody(mgl64.Vec3{0, 5, 0}, mgl64.Vec3{0.5, 0.5, 0.5})
+		b := createBoxBody(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{5, 0.5, 5})
+
+		report, ok := Cast(a, b, mgl64.Vec3{2, -8, 0})
+		if !ok {
+			t.Fatal("expected a hit")
+		}
+		// The bottom face travels from y = 4.5 down to the top face y = 0.5
+		if math.Abs(report.Fraction-0.5) > 1e-4 {
+			t.Errorf("Fraction = %v, want 0.5", report.Fraction)
+		}
+		if !report.Normal.ApproxEqualThreshold(mgl64.Vec3{0, 1, 0}, 1e-4) {
+			t.Errorf("Normal = %v, want (0, 1, 0)", report.Normal)
+		}
+		if math.Abs(report.Point.Y()-0.5) > 1e-4 {
+			t.Errorf("Point = %v, want on the top face", report.Point)
+		}
+	})
+
+	t.Run("sphere against sphere", func(t *testing.T) {
+		a := createSphereBody(mgl64.Vec3{0, 0, 0}, 1)
+		b := createSphereBody(mgl64.Vec3{0, 0, 10}, 2)
+
+		report, ok := Cast(a, b, mgl64.Vec3{0, 0, 20})
+		if !ok {
+			t.Fatal("expected a hit")
+		}
+		if math.Abs(report.Fraction-0.35) > 1e-4 {
+			t.Errorf("Fraction = %v, want 0.35", report.Fraction)
+		}
+		if !report.Point.ApproxEqualThreshold(mgl64.Vec3{0, 0, 8}, 1e-3) {
+			t.Errorf("Point = %v, want (0, 0, 8)", report.Point)
+		}
+	})
+
+	t.Run("too short", func(t *testing.T) {
+		a := createSphereBody(mgl64.Vec3{0, 0, 0}, 1)
+		b := createBoxBody(mgl64.Vec3{5, 0, 0}, mgl64.Vec3{1, 1, 1})
+
+		if _, ok := Cast(a, b, mgl64.Vec3{2, 0, 0}); ok {
+			t.Error("expected no hit before the end of the translation")
+		}
+	})
+
+	t.Run("moving away", func(t *testing.T) {
+		a := createSphereBody(mgl64.Vec3{0, 0, 0}, 1)
+		b := createBoxBody(mgl64.Vec3{5, 0, 0}, mgl64.Vec3{1, 1, 1})
+
+		if _, ok := Cast(a, b, mgl64.Vec3{-10, 0, 0}); ok {
+			t.Error("expected no hit moving away")
+		}
+	})
+
+	t.Run("passing by", func(t *testing.T) {
+		a := createSphereBody(mgl64.Vec3{0, 3, 0}, 1)
+		b := createBoxBody(mgl64.Vec3{5, 0, 0}, mgl64.Vec3{1, 1, 1})
+
+		if _, ok := Cast(a, b, mgl64.Vec3{10, 0, 0}); ok {
+			t.Error("expected no hit passing above the box")
+		}
+	})
+
+	t.Run("initial overlap", func(t *testing.T) {
+		a := createSphereBody(mgl64.Vec3{0, 0, 0}, 1)
+		b := createBoxBody(mgl64.Vec3{1, 0, 0}, mgl64.Vec3{1, 1, 1})
+
+		report, ok := Cast(a, b, mgl64.Vec3{4, 0, 0})
+		if !ok {
+			t.Fatal("expected a hit")
+		}
+		if report.Fraction != 0 {
+			t.Errorf("Fraction = %v, want 0", report.Fraction)
+		}
+		if !report.Normal.ApproxEqualThreshold(mgl64.Vec3{-1, 0, 0}, 1e-9) {
+			t.Errorf("Normal = %v, want opposed to the translation", report.Normal)
+		}
+	})
+}
+
+func BenchmarkCast_Boxes(b *testing.B) {
+	a := createBoxBody(mgl64.Vec3{0, 5, 0}, mgl64.Vec3{0.5, 0.5, 0.5})
+	ground := createBoxBody(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{5, 0.5, 5})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Cast(a, ground, mgl64.Vec3{2, -8, 0})
+	}
+}
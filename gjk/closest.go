@@ -0,0 +1,198 @@
+package gjk
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// degenerateEpsilon detects degenerate simplex features (zero length edges, flat triangles and tetrahedra)
+const degenerateEpsilon = 1e-12
+
+// ClosestPointToOrigin returns the point of the simplex closest to the origin.
+// For a tetrahedron containing the origin, the origin itself is returned.
+// The simplex is not modified, unlike the refinement done by GJK.
+func (s *Simplex) ClosestPointToOrigin() mgl64.Vec3 {
+	point, _ := s.closestPoint()
+
+	return point
+}
+
+// Barycentric returns the barycentric coordinates of ClosestPointToOrigin:
+// the closest point is the sum of Points[i] * weights[i], for i < Count.
+// Combined with the support points of each shape, it gives the witness points of a distance query.
+func (s *Simplex) Barycentric() [4]float64 {
+	_, weights := s.closestPoint()
+
+	return weights
+}
+
+func (s *Simplex) closestPoint() (mgl64.Vec3, [4]float64) {
+	switch s.Count {
+	case 1:
+		return s.Points[0], [4]float64{1}
+	case 2:
+		point, u, v := closestOnSegment(s.Points[0], s.Points[1])
+		return point, [4]float64{u, v}
+	case 3:
+		point, weights := closestOnTriangle(s.Points[0], s.Points[1], s.Points[2])
+		return point, [4]float64{weights[0], weights[1], weights[2]}
+	case 4:
+		return closestOnTetrahedron(s.Points)
+	}
+
+	return mgl64.Vec3{}, [4]float64{}
+}
+
+// closestOnSegment returns the point of the segment [a, b] closest to the origin, and its weights
+func closestOnSegment(a, b mgl64.Vec3) (mgl64.Vec3, float64, float64) {
+	ab := b.Sub(a)
+	lengthSqr := ab.LenSqr()
+	if lengthSqr < degenerateEpsilon {
+		return a, 1, 0
+	}
+
+	t := math.Max(0, math.Min(1, -a.Dot(ab)/lengthSqr))
+
+	return a.Add(ab.Mul(t)), 1 - t, t
+}
+
+// closestOnTriangle returns the point of the triangle abc closest to the origin, and its weights.
+// Voronoi region tests from Ericson, "Real-Time Collision Detection" (2004), section 5.1.5.
+func closestOnTriangle(a, b, c mgl64.Vec3) (mgl64.Vec3, [3]float64) {
+	ab := b.Sub(a)
+	ac := c.Sub(a)
+
+	// Flat triangle: closest of its 3 edges
+	if ab.Cross(ac).LenSqr() < degenerateEpsilon {
+		return closestOnDegenerateTriangle(a, b, c)
+	}
+
+	// Region A
+	ap := a.Mul(-1)
+	d1 := ab.Dot(ap)
+	d2 := ac.Dot(ap)
+	if d1 <= 0 && d2 <= 0 {
+		return a, [3]float64{1, 0, 0}
+	}
+
+	// Region B
+	bp := b.Mul(-1)
+	d3 := ab.Dot(bp)
+	d4 := ac.Dot(bp)
+	if d3 >= 0 && d4 <= d3 {
+		return b, [3]float64{0, 1, 0}
+	}
+
+	// Region AB
+	vc := d1*d4 - d3*d2
+	if vc <= 0 && d1 >= 0 && d3 <= 0 {
+		v := d1 / (d1 - d3)
+		return a.Add(ab.Mul(v)), [3]float64{1 - v, v, 0}
+	}
+
+	// Region C
+	cp := c.Mul(-1)
+	d5 := ab.Dot(cp)
+	d6 := ac.Dot(cp)
+	if d6 >= 0 && d5 <= d6 {
+		return c, [3]float64{0, 0, 1}
+	}
+
+	// Region AC
+	vb := d5*d2 - d1*d6
+	if vb <= 0 && d2 >= 0 && d6 <= 0 {
+		w := d2 / (d2 - d6)
+		return a.Add(ac.Mul(w)), [3]float64{1 - w, 0, w}
+	}
+
+	// Region BC
+	va := d3*d6 - d5*d4
+	if va <= 0 && (d4-d3) >= 0 && (d5-d6) >= 0 {
+		w := (d4 - d3) / ((d4 - d3) + (d5 - d6))
+		return b.Add(c.Sub(b).Mul(w)), [3]float64{0, 1 - w, w}
+	}
+
+	// Inside the face
+	denominator := 1.0 / (va + vb + vc)
+	v := vb * denominator
+	w := vc * denominator
+
+	return a.Add(ab.Mul(v)).Add(ac.Mul(w)), [3]float64{1 - v - w, v, w}
+}
+
+func closestOnDegenerateTriangle(a, b, c mgl64.Vec3) (mgl64.Vec3, [3]float64) {
+	point, u, v := closestOnSegment(a, b)
+	weights := [3]float64{u, v, 0}
+
+	if candidate, u, v := closestOnSegment(b, c); candidate.LenSqr() < point.LenSqr() {
+		point, weights = candidate, [3]float64{0, u, v}
+	}
+	if candidate, u, v := closestOnSegment(a, c); candidate.LenSqr() < point.LenSqr() {
+		point, weights = candidate, [3]float64{u, 0, v}
+	}
+
+	return point, weights
+}
+
+// closestOnTetrahedron returns the point of the tetrahedron closest to the origin, and its weights.
+// The origin is returned if it is inside, with the weights given by the signed volumes.
+func closestOnTetrahedron(points [4]mgl64.Vec3) (mgl64.Vec3, [4]float64) {
+	a, b, c, d := points[0], points[1], points[2], points[3]
+	volume := b.Sub(a).Cross(c.Sub(a)).Dot(d.Sub(a))
+
+	// Faces with the index of their vertices, and of the opposite vertex
+	faces := [4][4]int{
+		{1, 2, 3, 0},
+		{0, 2, 3, 1},
+		{0, 1, 3, 2},
+		{0, 1, 2, 3},
+	}
+
+	bestDistance := math.Inf(1)
+	var bestPoint mgl64.Vec3
+	var bestWeights [4]float64
+	outside := false
+
+	for _, face := range faces {
+		p0, p1, p2, opposite := points[face[0]], points[face[1]], points[face[2]], points[face[3]]
+		normal := p1.Sub(p0).Cross(p2.Sub(p0))
+
+		// Flat tetrahedra test every face, the origin cannot be inside
+		if math.Abs(volume) >= degenerateEpsilon {
+			originSide := normal.Dot(p0.Mul(-1))
+			oppositeSide := normal.Dot(opposite.Sub(p0))
+			if originSide*oppositeSide >= 0 {
+				continue
+			}
+		}
+
+		outside = true
+		point, weights := closestOnTriangle(p0, p1, p2)
+		if distance := point.LenSqr(); distance < bestDistance {
+			bestDistance = distance
+			bestPoint = point
+			bestWeights = [4]float64{}
+			bestWeights[face[0]] = weights[0]
+			bestWeights[face[1]] = weights[1]
+			bestWeights[face[2]] = weights[2]
+		}
+	}
+
+	if outside {
+		return bestPoint, bestWeights
+	}
+
+	// Origin inside: the weight of a vertex is the volume of the tetrahedron made
+	// of the origin and the opposite face, relative to the whole volume
+	var weights [4]float64
+	for _, face := range faces {
+		p0, p1, p2 := points[face[0]], points[face[1]], points[face[2]]
+		opposite := points[face[3]]
+		faceVolume := p1.Sub(p0).Cross(p2.Sub(p0)).Dot(p0.Mul(-1))
+		fullVolume := p1.Sub(p0).Cross(p2.Sub(p0)).Dot(opposite.Sub(p0))
+		weights[face[3]] = faceVolume / fullVolume
+	}
+
+	return mgl64.Vec3{}, weights
+}
@@ -0,0 +1,132 @@
+package gjk
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// reconstruct returns the point given by the barycentric coordinates of the simplex
+func reconstruct(simplex *Simplex) mgl64.Vec3 {
+	weights := simplex.Barycentric()
+
+	var point mgl64.Vec3
+	for i := 0; i < simplex.Count; i++ {
+		point = point.Add(simplex.Points[i].Mul(weights[i]))
+	}
+
+	return point
+}
+
+func TestSimplex_ClosestPointToOrigin(t *testing.T) {
+	tests := []struct {
+		name     string
+		simplex  Simplex
+		expected mgl64.Vec3
+	}{
+		{
+			name:     "single point",
+			simplex:  Simplex{Points: [4]mgl64.Vec3{{1, 2, 3}}, Count: 1},
+			expected: mgl64.Vec3{1, 2, 3},
+		},
+		{
+			name:     "segment interior",
+			simplex:  Simplex{Points: [4]mgl64.Vec3{{-1, 1, 0}, {1, 1, 0}}, Count: 2},
+			expected: mgl64.Vec3{0, 1, 0},
+		},
+		{
+			name:     "segment endpoint",
+			simplex:  Simplex{Points: [4]mgl64.Vec3{{1, 1, 0}, {2, 1, 0}}, Count: 2},
+			expected: mgl64.Vec3{1, 1, 0},
+		},
+		{
+			name:     "degenerate segment",
+			simplex:  Simplex{Points: [4]mgl64.Vec3{{1, 0, 0}, {1, 0, 0}}, Count: 2},
+			expected: mgl64.Vec3{1, 0, 0},
+		},
+		{
+			name:     "triangle face",
+			simplex:  Simplex{Points: [4]mgl64.Vec3{{-1, -1, 2}, {1, -1, 2}, {0, 1, 2}}, Count: 3},
+			expected: mgl64.Vec3{0, 0, 2},
+		},
+		{
+			name:     "triangle edge",
+			simplex:  Simplex{Points: [4]mgl64.Vec3{{-1, 1, 0}, {1, 1, 0}, {0, 3, 0}}, Count: 3},
+			expected: mgl64.Vec3{0, 1, 0},
+		},
+		{
+			name:     "triangle vertex",
+			simplex:  Simplex{Points: [4]mgl64.Vec3{{1, 1, 0}, {2, 1, 0}, {1, 2, 0}}, Count: 3},
+			expected: mgl64.Vec3{1, 1, 0},
+		},
+		{
+			name:     "collinear triangle",
+			simplex:  Simplex{Points: [4]mgl64.Vec3{{-1, 1, 0}, {0, 1, 0}, {1, 1, 0}}, Count: 3},
+			expected: mgl64.Vec3{0, 1, 0},
+		},
+		{
+			name: "origin inside tetrahedron",
+			simplex: Simplex{Points: [4]mgl64.Vec3{
+				{-1, -1, -1}, {1, 1, -1}, {1, -1, 1}, {-1, 1, 1},
+			}, Count: 4},
+			expected: mgl64.Vec3{0, 0, 0},
+		},
+		{
+			name: "origin outside tetrahedron",
+			simplex: Simplex{Points: [4]mgl64.Vec3{
+				{-1, -1, 1}, {1, -1, 1}, {0, 1, 1}, {0, 0, 3},
+			}, Count: 4},
+			expected: mgl64.Vec3{0, 0, 1},
+		},
+		{
+			name: "flat tetrahedron",
+			simplex: Simplex{Points: [4]mgl64.Vec3{
+				{-1, -1, 1}, {1, -1, 1}, {0, 1, 1}, {0, 0, 1},
+			}, Count: 4},
+			expected: mgl64.Vec3{0, 0, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			simplex := tt.simplex
+			before := simplex
+
+			point := simplex.ClosestPointToOrigin()
+			if !point.ApproxEqualThreshold(tt.expected, 1e-9) {
+				t.Errorf("expected closest point %v, got %v", tt.expected, point)
+			}
+
+			weights := simplex.Barycentric()
+			sum := 0.0
+			for i := 0; i < simplex.Count; i++ {
+				if weights[i] < -1e-9 {
+					t.Errorf("expected non-negative weights, got %v", weights)
+				}
+				sum += weights[i]
+			}
+			if math.Abs(sum-1) > 1e-9 {
+				t.Errorf("expected weights summing to 1, got %v (sum %v)", weights, sum)
+			}
+			if reconstructed := reconstruct(&simplex); !reconstructed.ApproxEqualThreshold(point, 1e-9) {
+				t.Errorf("barycentric coordinates give %v, closest point is %v", reconstructed, point)
+			}
+
+			if simplex != before {
+				t.Error("expected the simplex to be left unmodified")
+			}
+		})
+	}
+}
+
+func TestSimplex_ClosestPointToOrigin_Empty(t *testing.T) {
+	simplex := Simplex{}
+
+	if point := simplex.ClosestPointToOrigin(); point != (mgl64.Vec3{}) {
+		t.Errorf("expected zero vector for an empty simplex, got %v", point)
+	}
+	if weights := simplex.Barycentric(); weights != [4]float64{} {
+		t.Errorf("expected zero weights for an empty simplex, got %v", weights)
+	}
+}
@@ -0,0 +1,110 @@
+package gjk
+
+import (
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// DistanceTolerance is the relative progress under which the distance query has converged:
+// a new support point must bring the simplex closer to the origin by more than this fraction
+const DistanceTolerance = 1e-9
+
+// DistanceReport describes the separation of two convex bodies
+type DistanceReport struct {
+	// Overlap is true if the bodies intersect, the distance and the witness points are then zero
+	Overlap bool
+	// Distance between the surfaces of both bodies, their collision margins included
+	Distance float64
+	// PointA and PointB are the world-space closest points on a and b (the witness points)
+	PointA mgl64.Vec3
+	PointB mgl64.Vec3
+	// Iterations is the number of refinement iterations run
+	Iterations int
+	// Capped is true if the query stopped at MaxIterations, the distance is then an upper bound
+	Capped bool
+}
+
+// Distance computes the minimum distance between two convex bodies, and the closest point on each of them.
+// It is the distance mode of GJK: the simplex converges toward the point of A - B closest to the origin,
+// its barycentric coordinates combine the support points of each body into the witness points.
+// Proximity sensors and speculative contacts use it for shapes that are not yet in contact.
+//
+// The collision margins (see actor.ShapeMargin) are removed from the distance of the core shapes,
+// and the witness points moved onto the inflated surfaces. Bodies closer than their margins overlap.
+func Distance(a, b *actor.RigidBody) DistanceReport {
+	var points, supportsA, supportsB [4]mgl64.Vec3
+	var weights [4]float64
+
+	direction := b.Transform.Position.Sub(a.Transform.Position)
+	if direction.LenSqr() < 1e-8 {
+		direction = mgl64.Vec3{1, 0, 0}
+	}
+	supportsA[0] = a.SupportWorld(direction)
+	supportsB[0] = b.SupportWorld(direction.Mul(-1))
+	points[0] = supportsA[0].Sub(supportsB[0])
+	weights[0] = 1
+	count := 1
+	v := points[0]
+
+	report := DistanceReport{}
+	for ; report.Iterations < MaxIterations; report.Iterations++ {
+		vv := v.LenSqr()
+		if vv < 1e-24 {
+			return DistanceReport{Overlap: true, Iterations: report.Iterations}
+		}
+
+		// Support point of A - B toward the origin
+		supportA := a.SupportWorld(v.Mul(-1))
+		supportB := b.SupportWorld(v)
+		w := supportA.Sub(supportB)
+
+		// No support point gets closer to the origin than the current simplex: v is the closest point
+		if vv-v.Dot(w) <= DistanceTolerance*vv {
+			break
+		}
+
+		points[count], supportsA[count], supportsB[count] = w, supportA, supportB
+		count++
+
+		simplex := Simplex{Points: points, Count: count}
+		v, weights = simplex.closestPoint()
+
+		// Only the points supporting the closest feature are kept
+		kept := 0
+		for i := 0; i < count; i++ {
+			if weights[i] > 0 {
+				points[kept], supportsA[kept], supportsB[kept], weights[kept] = points[i], supportsA[i], supportsB[i], weights[i]
+				kept++
+			}
+		}
+		count = kept
+
+		// A full tetrahedron encloses the origin
+		if count == 4 {
+			return DistanceReport{Overlap: true, Iterations: report.Iterations + 1}
+		}
+	}
+	report.Capped = report.Iterations == MaxIterations
+
+	total := 0.0
+	for i := 0; i < count; i++ {
+		report.PointA = report.PointA.Add(supportsA[i].Mul(weights[i]))
+		report.PointB = report.PointB.Add(supportsB[i].Mul(weights[i]))
+		total += weights[i]
+	}
+	report.PointA = report.PointA.Mul(1 / total)
+	report.PointB = report.PointB.Mul(1 / total)
+
+	// v goes from B to A, the margins shrink the gap along it
+	distance := v.Len()
+	normal := v.Mul(1 / distance)
+	marginA, marginB := actor.ShapeMargin(a.Shape), actor.ShapeMargin(b.Shape)
+	if distance <= marginA+marginB {
+		return DistanceReport{Overlap: true, Iterations: report.Iterations}
+	}
+	report.Distance = distance - marginA - marginB
+	report.PointA = report.PointA.Sub(normal.Mul(marginA))
+	report.PointB = report.PointB.Add(normal.Mul(marginB))
+
+	return report
+}
@@ -0,0 +1,126 @@
+package gjk
+
+import (
+	"math"
+	"testing"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		name         string
+		a, b         *actor.RigidBody
+		wantDistance float64
+		wantPointA   mgl64.Vec3
+		wantPointB   mgl64.Vec3
+	}{
+		{
+			name:         "spheres",
+			a:            createSphereBody(mgl64.Vec3{0, 0, 0}, 1),
+			b:            createSphereBody(mgl64.Vec3{5, 0, 0}, 2),
+			wantDistance: 2,
+			wantPointA:   mgl64.Vec3{1, 0, 0},
+			wantPointB:   mgl64.Vec3{3, 0, 0},
+		},
+		{
+			name:         "box faces",
+			a:            createBoxBody(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{1, 1, 1}),
+			b:            createBoxBody(mgl64.Vec3{0, 3.5, 0.5}, mgl64.Vec3{0.5, 0.5, 0.5}),
+			wantDistance: 2,
+		},
+		{
+			name:         "box corner and sphere",
+			a:            createBoxBody(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{1, 1, 1}),
+			b:            createSphereBody(mgl64.Vec3{3, 3, 3}, 1),
+			wantDistance: 2*math.Sqrt(3) - 1,
+			wantPointA:   mgl64.Vec3{1, 1, 1},
+			wantPointB:   mgl64.Vec3{3, 3, 3}.Sub(mgl64.Vec3{1, 1, 1}.Normalize()),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Distance(tt.a, tt.b)
+			if report.Overlap || report.Capped {
+				t.Fatalf("expected separated bodies, got %+v", report)
+			}
+			if math.Abs(report.Distance-tt.wantDistance) > 1e-6 {
+				t.Errorf("Distance = %v, want %v", report.Distance, tt.wantDistance)
+			}
+			if math.Abs(report.PointB.Sub(report.PointA).Len()-report.Distance) > 1e-6 {
+				t.Errorf("witness points %v and %v are not Distance apart", report.PointA, report.PointB)
+			}
+			if tt.wantPointA != (mgl64.Vec3{}) && !report.PointA.ApproxEqualThreshold(tt.wantPointA, 1e-6) {
+				t.Errorf("PointA = %v, want %v", report.PointA, tt.wantPointA)
+			}
+			if tt.wantPointB != (mgl64.Vec3{}) && !report.PointB.ApproxEqualThreshold(tt.wantPointB, 1e-6) {
+				t.Errorf("PointB = %v, want %v", report.PointB, tt.wantPointB)
+			}
+		})
+	}
+}
+
+func TestDistance_RotatedEdges(t *testing.T) {
+	// Two cubes turned 45° around crossing axes face each other by their edges
+	a := createBoxBody(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{1, 1, 1})
+	a.Transform.Rotation = mgl64.QuatRotate(math.Pi/4, mgl64.Vec3{0, 0, 1})
+	a.Transform.InverseRotation = a.Transform.Rotation.Inverse()
+	b := createBoxBody(mgl64.Vec3{4, 0, 0}, mgl64.Vec3{1, 1, 1})
+	b.Transform.Rotation = mgl64.QuatRotate(math.Pi/4, mgl64.Vec3{0, 1, 0})
+	b.Transform.InverseRotation = b.Transform.Rotation.Inverse()
+
+	report := Distance(a, b)
+	if want := 4 - 2*math.Sqrt2; report.Overlap || math.Abs(report.Distance-want) > 1e-6 {
+		t.Fatalf("Distance = %v, want %v", report.Distance, want)
+	}
+	if !report.PointA.ApproxEqualThreshold(mgl64.Vec3{math.Sqrt2, 0, 0}, 1e-6) {
+		t.Errorf("PointA = %v, want on the edge of a", report.PointA)
+	}
+	if !report.PointB.ApproxEqualThreshold(mgl64.Vec3{4 - math.Sqrt2, 0, 0}, 1e-6) {
+		t.Errorf("PointB = %v, want on the edge of b", report.PointB)
+	}
+}
+
+func TestDistance_Overlap(t *testing.T) {
+	a := createBoxBody(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{1, 1, 1})
+	b := createSphereBody(mgl64.Vec3{1.5, 0, 0}, 1)
+
+	if report := Distance(a, b); !report.Overlap || report.Distance != 0 {
+		t.Errorf("expected an overlap, got %+v", report)
+	}
+	if report := Distance(a, createSphereBody(mgl64.Vec3{0, 0, 0}, 0.5)); !report.Overlap {
+		t.Errorf("expected concentric bodies to overlap, got %+v", report)
+	}
+}
+
+func TestDistance_Margin(t *testing.T) {
+	a := createBoxBody(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{1, 1, 1})
+	b := createBoxBody(mgl64.Vec3{0, 2.5, 0}, mgl64.Vec3{1, 1, 1})
+	a.Shape.(*actor.Box).SetMargin(0.1)
+	b.Shape.(*actor.Box).SetMargin(0.1)
+
+	report := Distance(a, b)
+	if math.Abs(report.Distance-0.3) > 1e-9 {
+		t.Errorf("Distance = %v, want the gap minus both margins", report.Distance)
+	}
+	if math.Abs(report.PointA.Y()-1.1) > 1e-9 || math.Abs(report.PointB.Y()-1.4) > 1e-9 {
+		t.Errorf("witness points %v and %v, want on the inflated faces", report.PointA, report.PointB)
+	}
+
+	b.Transform.Position = mgl64.Vec3{0, 2.15, 0}
+	if report := Distance(a, b); !report.Overlap {
+		t.Errorf("expected the inflated shapes to overlap, got %+v", report)
+	}
+}
+
+func BenchmarkDistance_Boxes(b *testing.B) {
+	a := createBoxBody(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{1, 1, 1})
+	body := createBoxBody(mgl64.Vec3{3, 2, 1}, mgl64.Vec3{1, 1, 1})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Distance(a, body)
+	}
+}
@@ -53,10 +53,46 @@ var SimplexPool = sync.Pool{
 //
 // This is the fundamental query that makes GJK work for any convex shape - shapes only
 // need to implement a Support() function, not expose their full geometry.
+//
+// The collision margins of the shapes (see actor.ShapeMargin) push the support point further along direction:
+// GJK and EPA then work on the shapes inflated by their margins, EPA removes them from the depth.
 func MinkowskiSupport(a, b *actor.RigidBody, direction mgl64.Vec3) mgl64.Vec3 {
 	supportA := a.SupportWorld(direction)
 	supportB := b.SupportWorld(direction.Mul(-1))
-	return supportA.Sub(supportB)
+	support := supportA.Sub(supportB)
+
+	if margin := Margin(a, b); margin > 0 {
+		if length := direction.Len(); length > 0 {
+			support = support.Add(direction.Mul(margin / length))
+		}
+	}
+
+	return support
+}
+
+// Margin returns the sum of the collision margins of both bodies,
+// the distance by which their Minkowski difference is inflated
+func Margin(a, b *actor.RigidBody) float64 {
+	return actor.ShapeMargin(a.Shape) + actor.ShapeMargin(b.Shape)
+}
+
+// MaxIterations caps the GJK refinement loop.
+// Typical convergence is 3-6 iterations, nearly touching smooth shapes (e.g. spheres)
+// can keep producing new support points that barely improve the simplex.
+const MaxIterations = 32
+
+// Report describes the outcome of a GJK query
+type Report struct {
+	// Collision is true if the origin is contained in the Minkowski difference
+	Collision bool
+	// Capped is true if the query stopped at MaxIterations without converging.
+	// The shapes are then reported as separated.
+	Capped bool
+	// Direction is the last search direction, toward the origin from the closest simplex feature.
+	// When there is no collision, it is the best known separating direction (not normalized).
+	Direction mgl64.Vec3
+	// Iterations is the number of refinement iterations run
+	Iterations int
 }
 
 // GJK performs collision detection between two convex rigid bodies.
@@ -76,6 +112,12 @@ func MinkowskiSupport(a, b *actor.RigidBody, direction mgl64.Vec3) mgl64.Vec3 {
 // The simplex is modified in place and contains 1-4 points. For collisions, it's always
 // a tetrahedron (4 points) containing the origin, which EPA uses as its initial polytope.
 func GJK(a, b *actor.RigidBody, simplex *Simplex) bool {
+	return GJKReport(a, b, simplex).Collision
+}
+
+// GJKReport runs the same query as GJK, and reports whether MaxIterations was hit
+// along with the best known separating direction
+func GJKReport(a, b *actor.RigidBody, simplex *Simplex) Report {
 	// Compute initial direction from A to B (optimization over random direction)
 	// Starting toward the other shape typically reduces iterations
 	direction := b.Transform.Position.Sub(a.Transform.Position)
@@ -92,11 +134,10 @@ func GJK(a, b *actor.RigidBody, simplex *Simplex) bool {
 
 	// If first support point is at/near origin, shapes are touching
 	if direction.LenSqr() < 1e-16 {
-		return true // Collision detected (rare: shapes exactly touching at point)
+		return Report{Collision: true, Direction: direction} // Collision detected (rare: shapes exactly touching at point)
 	}
 
-	maxIterations := 32 // Safety limit to prevent infinite loops
-	for i := 0; i < maxIterations; i++ {
+	for i := 0; i < MaxIterations; i++ {
 		// Find a new support point in the direction towards the origin
 		newPoint := MinkowskiSupport(a, b, direction)
 
@@ -105,7 +146,7 @@ func GJK(a, b *actor.RigidBody, simplex *Simplex) bool {
 		// This is the key optimization that makes GJK fast - we prove separation
 		// without building the full Minkowski difference.
 		if newPoint.Dot(direction) <= 0 {
-			return false // No collision detected - shapes are separated
+			return Report{Direction: direction, Iterations: i + 1} // No collision detected - shapes are separated
 		}
 
 		// Add the new point to the simplex
@@ -116,13 +157,13 @@ func GJK(a, b *actor.RigidBody, simplex *Simplex) bool {
 		// This function also updates the simplex and direction for the next iteration
 		// by reducing the simplex to its closest feature to the origin
 		if containsOrigin(simplex, &direction) {
-			return true // Collision detected - origin is inside simplex
+			return Report{Collision: true, Direction: direction, Iterations: i + 1} // Collision detected - origin is inside simplex
 		}
 	}
 
-	// Failed to converge after maxIterations (very rare, may indicate numerical issues)
-	// In practice this almost never happens for valid convex shapes
-	return false
+	// Failed to converge after MaxIterations (nearly touching smooth shapes, or numerical issues)
+	// The last search direction is the best known separating direction
+	return Report{Capped: true, Direction: direction, Iterations: MaxIterations}
 }
 
 // containsOrigin tests if the simplex contains the origin and refines the simplex.
@@ -291,6 +291,27 @@ func TestGJK_MixedShapes_Separated(t *testing.T) {
 
 // Edge case tests
 
+func TestGJKReport(t *testing.T) {
+	a := createSphereBody(mgl64.Vec3{0, 0, 0}, 1.0)
+
+	separated := GJKReport(a, createSphereBody(mgl64.Vec3{5, 0, 0}, 1.0), &Simplex{})
+	if separated.Collision || separated.Capped {
+		t.Fatalf("expected a separated, converged query: %+v", separated)
+	}
+	// The separating direction points from A toward B
+	if separated.Direction.Normalize().Dot(mgl64.Vec3{1, 0, 0}) < 0.99 {
+		t.Errorf("separating direction = %v, want toward +X", separated.Direction)
+	}
+	if separated.Iterations < 1 || separated.Iterations > MaxIterations {
+		t.Errorf("iterations = %d, want within [1, %d]", separated.Iterations, MaxIterations)
+	}
+
+	colliding := GJKReport(a, createSphereBody(mgl64.Vec3{1.5, 0, 0}, 1.0), &Simplex{})
+	if !colliding.Collision || colliding.Capped {
+		t.Errorf("expected a collision: %+v", colliding)
+	}
+}
+
 func TestGJK_EdgeCases(t *testing.T) {
 	t.Run("very small spheres overlapping", func(t *testing.T) {
 		a := createSphereBody(mgl64.Vec3{0, 0, 0}, 0.001)
@@ -858,3 +879,25 @@ func BenchmarkGJK_MixedShapes(b *testing.B) {
 		GJK(box, sphere, simplex)
 	}
 }
+
+// TestGJK_Margin checks the margins inflate the shapes: nearly touching boxes overlap within their margins
+func TestGJK_Margin(t *testing.T) {
+	a := createBoxBody(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{1, 1, 1})
+	b := createBoxBody(mgl64.Vec3{0, 2.01, 0}, mgl64.Vec3{1, 1, 1})
+
+	if GJK(a, b, &Simplex{}) {
+		t.Fatal("expected no collision without margins")
+	}
+
+	a.Shape.(*actor.Box).SetMargin(0.02)
+	b.Shape.(*actor.Box).SetMargin(0.02)
+	if margin := Margin(a, b); margin != 0.04 {
+		t.Errorf("Margin = %v, want 0.04", margin)
+	}
+	if support := MinkowskiSupport(a, b, mgl64.Vec3{0, 2, 0}); math.Abs(support.Y()-(-0.01+0.04)) > 1e-9 {
+		t.Errorf("support.Y = %v, want the gap minus both margins", support.Y())
+	}
+	if !GJK(a, b, &Simplex{}) {
+		t.Error("expected the inflated shapes to collide")
+	}
+}
@@ -0,0 +1,92 @@
+package feather
+
+import "github.com/akmonengine/feather/actor"
+
+// BodyID is a stable handle of a body in a world, returned by AddBody. Unlike an index in Bodies, it survives
+// the removal of the other bodies, and GetBody detects the handle of a removed body (stale handle):
+// its slot is reused by the next bodies with a new Generation. The zero BodyID is never valid.
+type BodyID struct {
+	Index      uint32
+	Generation uint32
+}
+
+// bodySlot holds the body of a handle, nil once removed, and the generation of the current handle
+type bodySlot struct {
+	body       *actor.RigidBody
+	generation uint32
+}
+
+// registerBody returns the handle of the body, a new one in a free slot if it has none
+func (w *World) registerBody(body *actor.RigidBody) BodyID {
+	if id, ok := w.bodyIDs[body]; ok {
+		return id
+	}
+	if w.bodyIDs == nil {
+		w.bodyIDs = make(map[*actor.RigidBody]BodyID)
+	}
+
+	var index uint32
+	if free := len(w.freeSlots); free > 0 {
+		index = w.freeSlots[free-1]
+		w.freeSlots = w.freeSlots[:free-1]
+	} else {
+		index = uint32(len(w.bodySlots))
+		// Generations start at 1, so the zero BodyID is never valid
+		w.bodySlots = append(w.bodySlots, bodySlot{generation: 1})
+	}
+
+	slot := &w.bodySlots[index]
+	slot.body = body
+	id := BodyID{Index: index, Generation: slot.generation}
+	w.bodyIDs[body] = id
+
+	return id
+}
+
+// unregisterBody frees the slot of the body: its handle becomes stale
+func (w *World) unregisterBody(body *actor.RigidBody) {
+	id, ok := w.bodyIDs[body]
+	if !ok {
+		return
+	}
+
+	delete(w.bodyIDs, body)
+	slot := &w.bodySlots[id.Index]
+	slot.body = nil
+	slot.generation++
+	w.freeSlots = append(w.freeSlots, id.Index)
+}
+
+// GetBody returns the body of the handle in O(1), false if the handle is stale (the body was removed)
+// or was not returned by this world
+func (w *World) GetBody(id BodyID) (*actor.RigidBody, bool) {
+	if int(id.Index) >= len(w.bodySlots) {
+		return nil, false
+	}
+
+	slot := w.bodySlots[id.Index]
+	if slot.body == nil || slot.generation != id.Generation {
+		return nil, false
+	}
+
+	return slot.body, true
+}
+
+// BodyIDOf returns the handle of a body added by AddBody, false for the bodies appended to Bodies directly
+func (w *World) BodyIDOf(body *actor.RigidBody) (BodyID, bool) {
+	id, ok := w.bodyIDs[body]
+
+	return id, ok
+}
+
+// RemoveBodyByID removes the body of the handle, see RemoveBody. Returns false for a stale handle.
+func (w *World) RemoveBodyByID(id BodyID) bool {
+	body, ok := w.GetBody(id)
+	if !ok {
+		return false
+	}
+
+	w.RemoveBody(body)
+
+	return true
+}
@@ -0,0 +1,78 @@
+package feather
+
+import (
+	"testing"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestWorld_BodyID(t *testing.T) {
+	world := NewWorld()
+	first := createSphere(mgl64.Vec3{}, 0.5, actor.BodyTypeDynamic)
+	second := createSphere(mgl64.Vec3{2, 0, 0}, 0.5, actor.BodyTypeDynamic)
+	firstID := world.AddBody(first)
+	secondID := world.AddBody(second)
+
+	if firstID == secondID || firstID == (BodyID{}) {
+		t.Fatalf("handles %v and %v, want distinct valid handles", firstID, secondID)
+	}
+	if body, ok := world.GetBody(secondID); !ok || body != second {
+		t.Errorf("GetBody = %v, %v, want the second body", body, ok)
+	}
+
+	// Removing the first body reorders Bodies, the handle of the second one still resolves
+	if !world.RemoveBodyByID(firstID) || len(world.Bodies) != 1 {
+		t.Fatalf("RemoveBodyByID failed, %d bodies left", len(world.Bodies))
+	}
+	if body, ok := world.GetBody(secondID); !ok || body != second {
+		t.Errorf("GetBody = %v, %v, want the second body after the removal", body, ok)
+	}
+
+	// The stale handle is detected, even once its slot is reused
+	third := createSphere(mgl64.Vec3{4, 0, 0}, 0.5, actor.BodyTypeDynamic)
+	thirdID := world.AddBody(third)
+	if thirdID.Index != firstID.Index || thirdID.Generation == firstID.Generation {
+		t.Errorf("handle %v, want the slot of %v reused with a new generation", thirdID, firstID)
+	}
+	if body, ok := world.GetBody(firstID); ok {
+		t.Errorf("GetBody(stale) = %v, want false", body)
+	}
+	if world.RemoveBodyByID(firstID) {
+		t.Error("RemoveBodyByID should ignore a stale handle")
+	}
+	if id, ok := world.BodyIDOf(third); !ok || id != thirdID {
+		t.Errorf("BodyIDOf = %v, %v, want %v", id, ok, thirdID)
+	}
+
+	// RemoveBody also invalidates the handle
+	world.RemoveBody(second)
+	if _, ok := world.GetBody(secondID); ok {
+		t.Error("the handle of a body removed by RemoveBody should be stale")
+	}
+	if _, ok := world.GetBody(BodyID{}); ok {
+		t.Error("the zero handle should never resolve")
+	}
+}
+
+func TestWorld_AddBodyTwice(t *testing.T) {
+	world := NewWorld()
+	body := createSphere(mgl64.Vec3{}, 0.5, actor.BodyTypeDynamic)
+	id := world.AddBody(body)
+
+	if again := world.AddBody(body); again != id {
+		t.Errorf("AddBody = %v, want the existing handle %v", again, id)
+	}
+	if len(world.Bodies) != 1 {
+		t.Fatalf("%d bodies, want the body added once", len(world.Bodies))
+	}
+
+	// A single removal takes the body out of the world
+	world.RemoveBody(body)
+	if len(world.Bodies) != 0 {
+		t.Errorf("%d bodies left after RemoveBody, want 0", len(world.Bodies))
+	}
+	if _, ok := world.GetBody(id); ok {
+		t.Error("the handle of the removed body should be stale")
+	}
+}
@@ -0,0 +1,134 @@
+package feather
+
+import (
+	"runtime"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/akmonengine/feather/constraint"
+)
+
+// island is a set of bodies connected by contacts or constraints, solved independently of the other islands.
+// Its contacts and constraints keep the order of the lists they come from.
+type island struct {
+	contacts    []*constraint.ContactConstraint
+	constraints []constraint.Constraint
+}
+
+// islandBuilder is a union-find over the dynamic bodies of the contacts and constraints of a substep
+type islandBuilder struct {
+	index  map[*actor.RigidBody]int
+	parent []int
+}
+
+// node returns the index of the body, or -1 for a static body: static bodies do not connect islands
+func (b *islandBuilder) node(body *actor.RigidBody) int {
+	if body == nil || body.BodyType == actor.BodyTypeStatic {
+		return -1
+	}
+
+	index, ok := b.index[body]
+	if !ok {
+		index = len(b.parent)
+		b.index[body] = index
+		b.parent = append(b.parent, index)
+	}
+
+	return index
+}
+
+// find returns the root of the node, halving the path
+func (b *islandBuilder) find(node int) int {
+	for b.parent[node] != node {
+		b.parent[node] = b.parent[b.parent[node]]
+		node = b.parent[node]
+	}
+
+	return node
+}
+
+// union merges the sets of both nodes, -1 is ignored
+func (b *islandBuilder) union(nodeA, nodeB int) {
+	if nodeA < 0 || nodeB < 0 {
+		return
+	}
+
+	rootA, rootB := b.find(nodeA), b.find(nodeB)
+	if rootA != rootB {
+		b.parent[max(rootA, rootB)] = min(rootA, rootB)
+	}
+}
+
+// buildIslands groups the contacts and the constraints implementing constraint.Connector by the dynamic bodies
+// they connect. The islands are ordered by their first constraint then their first contact, so the solve does
+// not depend on the scheduling. The other constraints may move any body (or none of the dynamic ones),
+// they are returned to be solved apart. The contacts between static bodies are never solved, they are dropped.
+func buildIslands(contacts []*constraint.ContactConstraint, constraints []constraint.Constraint) ([]island, []constraint.Constraint) {
+	builder := islandBuilder{index: make(map[*actor.RigidBody]int, 2*len(contacts))}
+
+	// The first node of each contact and constraint, -1 for the ones without dynamic body
+	contactNodes := make([]int, len(contacts))
+	constraintNodes := make([]int, len(constraints))
+
+	for i, c := range constraints {
+		constraintNodes[i] = -1
+		connector, ok := c.(constraint.Connector)
+		if !ok {
+			continue
+		}
+
+		for _, body := range connector.Bodies() {
+			node := builder.node(body)
+			if constraintNodes[i] < 0 {
+				constraintNodes[i] = node
+			}
+			builder.union(constraintNodes[i], node)
+		}
+	}
+	for i, c := range contacts {
+		nodeA, nodeB := builder.node(c.BodyA), builder.node(c.BodyB)
+		builder.union(nodeA, nodeB)
+		contactNodes[i] = max(nodeA, nodeB)
+	}
+
+	var islands []island
+	islandOf := make(map[int]int)
+	islandIndex := func(node int) int {
+		root := builder.find(node)
+		index, ok := islandOf[root]
+		if !ok {
+			index = len(islands)
+			islandOf[root] = index
+			islands = append(islands, island{})
+		}
+
+		return index
+	}
+
+	var unconnected []constraint.Constraint
+	for i, c := range constraints {
+		if constraintNodes[i] < 0 {
+			unconnected = append(unconnected, c)
+			continue
+		}
+
+		index := islandIndex(constraintNodes[i])
+		islands[index].constraints = append(islands[index].constraints, c)
+	}
+	for i, c := range contacts {
+		if contactNodes[i] >= 0 {
+			index := islandIndex(contactNodes[i])
+			islands[index].contacts = append(islands[index].contacts, c)
+		}
+	}
+
+	return islands, unconnected
+}
+
+// solverWorkers returns the goroutines solving the islands: SolverWorkers, else all the cores
+func (w *World) solverWorkers() int {
+	if w.SolverWorkers > 0 {
+		return w.SolverWorkers
+	}
+
+	return runtime.GOMAXPROCS(0)
+}
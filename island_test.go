@@ -0,0 +1,121 @@
+package feather
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/akmonengine/feather/constraint"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestBuildIslands(t *testing.T) {
+	ground := createBox(mgl64.Vec3{0, -1, 0}, mgl64.Vec3{10, 1, 10}, actor.BodyTypeStatic)
+	a := createBox(mgl64.Vec3{-3, 0.5, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+	b := createBox(mgl64.Vec3{-3, 1.5, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+	c := createBox(mgl64.Vec3{3, 0.5, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+	d := createBox(mgl64.Vec3{6, 0.5, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+
+	// Two piles on the same static ground: the ground does not connect them
+	contacts := []*constraint.ContactConstraint{
+		{BodyA: ground, BodyB: c},
+		{BodyA: a, BodyB: ground},
+		{BodyA: b, BodyB: a},
+		{BodyA: ground, BodyB: d},
+	}
+	islands, unconnected := buildIslands(contacts, nil)
+
+	if len(islands) != 3 || len(unconnected) != 0 {
+		t.Fatalf("got %d islands and %d unconnected, want 3 and 0", len(islands), len(unconnected))
+	}
+	if len(islands[0].contacts) != 1 || islands[0].contacts[0] != contacts[0] {
+		t.Errorf("first island = %v, want the contact of c first", islands[0].contacts)
+	}
+	if len(islands[1].contacts) != 2 || islands[1].contacts[0] != contacts[1] || islands[1].contacts[1] != contacts[2] {
+		t.Errorf("second island = %v, want the contacts of a and b in list order", islands[1].contacts)
+	}
+
+	// A connector merges the islands of c and d, the attachment is solved apart
+	match := constraint.NewVelocityMatchConstraint(c, d, 0)
+	field := constraint.NewVelocityFieldConstraint(a, nil, 0)
+	attachment := constraint.NewParticleAttachment(actor.NewParticle(mgl64.Vec3{-3, 2, 0}, 0.1), b, 0)
+	islands, unconnected = buildIslands(contacts, []constraint.Constraint{attachment, field, match})
+
+	if len(islands) != 2 {
+		t.Fatalf("got %d islands, want 2", len(islands))
+	}
+	if len(unconnected) != 1 || unconnected[0] != attachment {
+		t.Errorf("unconnected = %v, want the particle attachment", unconnected)
+	}
+	if len(islands[0].constraints) != 1 || islands[0].constraints[0] != field || len(islands[0].contacts) != 2 {
+		t.Errorf("first island = %+v, want the field and the contacts of a and b", islands[0])
+	}
+	if len(islands[1].constraints) != 1 || islands[1].constraints[0] != match || len(islands[1].contacts) != 2 {
+		t.Errorf("second island = %+v, want the match and the contacts of c and d", islands[1])
+	}
+}
+
+func TestBuildIslands_StaticConnector(t *testing.T) {
+	ground := createBox(mgl64.Vec3{0, -1, 0}, mgl64.Vec3{10, 1, 10}, actor.BodyTypeStatic)
+	field := constraint.NewVelocityFieldConstraint(ground, nil, 0)
+
+	islands, unconnected := buildIslands(nil, []constraint.Constraint{field})
+	if len(islands) != 0 || len(unconnected) != 1 {
+		t.Errorf("got %d islands and %d unconnected, want the constraint without dynamic body solved apart", len(islands), len(unconnected))
+	}
+}
+
+// TestWorld_Islands checks two piles on the same ground are solved as two islands,
+// with the same result whatever the goroutines of the narrow phase and of the solver
+func TestWorld_Islands(t *testing.T) {
+	simulate := func(workers int) (*World, []*actor.RigidBody) {
+		world := NewWorld(WithSubsteps(4), WithWorkers(workers), WithSolverWorkers(workers))
+		world.AddBody(createBox(mgl64.Vec3{0, -1, 0}, mgl64.Vec3{10, 1, 10}, actor.BodyTypeStatic))
+
+		var boxes []*actor.RigidBody
+		for _, x := range []float64{-3, 3} {
+			for i := range 3 {
+				box := createBox(mgl64.Vec3{x, 0.5 + float64(i), 0.1 * float64(i)}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+				world.AddBody(box)
+				boxes = append(boxes, box)
+			}
+		}
+		for range 30 {
+			world.Step(1.0 / 60.0)
+		}
+
+		return world, boxes
+	}
+
+	world, sequential := simulate(1)
+	if world.Stats.Islands != 2 {
+		t.Errorf("Islands = %d, want 2 piles", world.Stats.Islands)
+	}
+	for i, box := range sequential {
+		if y := 0.5 + float64(i%3); box.Transform.Position.Y() < y-0.05 {
+			t.Errorf("box %d at %v, want resting at y=%v", i, box.Transform.Position, y)
+		}
+	}
+
+	_, parallel := simulate(4)
+	for i := range sequential {
+		if sequential[i].Transform != parallel[i].Transform {
+			t.Errorf("box %d at %v with 4 workers, want %v as with 1", i, parallel[i].Transform.Position, sequential[i].Transform.Position)
+		}
+	}
+}
+
+func TestBalancedTask(t *testing.T) {
+	for _, workers := range []int{1, 3, 16} {
+		visits := make([]int32, 10)
+		balancedTask(workers, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, func(i int) {
+			atomic.AddInt32(&visits[i], 1)
+		})
+
+		for i, count := range visits {
+			if count != 1 {
+				t.Errorf("%d workers: item %d processed %d times, want 1", workers, i, count)
+			}
+		}
+	}
+}
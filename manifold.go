@@ -0,0 +1,130 @@
+package feather
+
+import (
+	"github.com/akmonengine/feather/actor"
+	"github.com/akmonengine/feather/constraint"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// CONTACT_MATCH_DISTANCE is the distance (m) under which a contact point continues a point of the previous substep,
+// measured between their anchors on each body. A resting contact finds the same points from one substep to another.
+// The points of the manifolds are matched by their constraint.FeatureID first, the distance is the fallback
+// for the points without one (e.g. the contacts with a plane).
+const CONTACT_MATCH_DISTANCE = 0.02
+
+// manifoldCacheEntry is the contact points of a pair at the end of a substep, anchored in the order of OrderPair
+type manifoldCacheEntry struct {
+	points  []constraint.ContactPoint
+	substep uint64
+}
+
+// warmStart copies the friction impulses accumulated at the previous substep into the points continuing them.
+// The solver starts from them instead of zero: the friction of a resting stack is spread over its points
+// from the first substep, instead of being recomputed point after point at each substep.
+// The normal impulses are recomputed by SolvePosition from the penetrations.
+func (w *World) warmStart(constraints []*constraint.ContactConstraint) {
+	if len(w.manifolds) == 0 {
+		return
+	}
+
+	task(w.Workers, constraints, func(c *constraint.ContactConstraint) {
+		pair := OrderPair(c.BodyA, c.BodyB)
+		entry, ok := w.manifolds[pair]
+		if !ok || entry.substep != w.manifoldSubstep {
+			return
+		}
+
+		swapped := c.BodyA != pair.BodyA
+		for i := range c.Points {
+			point := &c.Points[i]
+			localA, localB, feature := point.LocalA, point.LocalB, point.Feature
+			if swapped {
+				localA, localB, feature = localB, localA, feature.Swapped()
+			}
+
+			if previous, found := matchPoint(entry.points, feature, localA, localB); found {
+				point.TangentImpulse = previous.TangentImpulse
+				if swapped {
+					point.TangentImpulse = point.TangentImpulse.Mul(-1)
+				}
+			}
+		}
+	})
+}
+
+// matchPoint returns the point with the same known feature, otherwise the point whose anchors are
+// the closest to localA and localB, within CONTACT_MATCH_DISTANCE
+func matchPoint(points []constraint.ContactPoint, feature constraint.FeatureID, localA, localB mgl64.Vec3) (constraint.ContactPoint, bool) {
+	if feature != (constraint.FeatureID{}) {
+		for _, point := range points {
+			if point.Feature == feature {
+				return point, true
+			}
+		}
+	}
+
+	best := -1
+	bestDistance := CONTACT_MATCH_DISTANCE * CONTACT_MATCH_DISTANCE
+	for i, point := range points {
+		distanceA := point.LocalA.Sub(localA).LenSqr()
+		distanceB := point.LocalB.Sub(localB).LenSqr()
+		if distance := max(distanceA, distanceB); distance <= bestDistance {
+			best, bestDistance = i, distance
+		}
+	}
+	if best < 0 {
+		return constraint.ContactPoint{}, false
+	}
+
+	return points[best], true
+}
+
+// storeManifolds records the solved points of the contacts, for the warm start of the next substep.
+// The points of the contacts sharing a pair (e.g. the faces of a mesh) are stored together.
+func (w *World) storeManifolds(constraints []*constraint.ContactConstraint) {
+	if w.manifolds == nil {
+		w.manifolds = make(map[Pair]*manifoldCacheEntry)
+	}
+	w.manifoldSubstep++
+
+	for _, c := range constraints {
+		pair := OrderPair(c.BodyA, c.BodyB)
+
+		entry, ok := w.manifolds[pair]
+		if !ok {
+			entry = &manifoldCacheEntry{}
+			w.manifolds[pair] = entry
+		}
+		if entry.substep != w.manifoldSubstep {
+			entry.points = entry.points[:0]
+			entry.substep = w.manifoldSubstep
+		}
+
+		for _, point := range c.Points {
+			if c.BodyA != pair.BodyA {
+				point.LocalA, point.LocalB = point.LocalB, point.LocalA
+				point.TangentImpulse = point.TangentImpulse.Mul(-1)
+				point.Feature = point.Feature.Swapped()
+			}
+			entry.points = append(entry.points, point)
+		}
+	}
+}
+
+// pruneManifolds forgets the pairs without contact during the last substep
+func (w *World) pruneManifolds() {
+	for pair, entry := range w.manifolds {
+		if entry.substep != w.manifoldSubstep {
+			delete(w.manifolds, pair)
+		}
+	}
+}
+
+// forgetManifolds forgets the pairs of the body, whose anchors no longer match its shape
+func (w *World) forgetManifolds(body *actor.RigidBody) {
+	for pair := range w.manifolds {
+		if pair.BodyA == body || pair.BodyB == body {
+			delete(w.manifolds, pair)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package feather
+
+import (
+	"math"
+	"testing"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/akmonengine/feather/constraint"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestWarmStart_MatchesPreviousPoints(t *testing.T) {
+	world := NewWorld()
+	ground := createBox(mgl64.Vec3{0, -1, 0}, mgl64.Vec3{5, 1, 5}, actor.BodyTypeStatic)
+	box := createBox(mgl64.Vec3{0, 0.5, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+	pair := OrderPair(ground, box)
+
+	previous := &constraint.ContactConstraint{BodyA: pair.BodyA, BodyB: pair.BodyB, Normal: mgl64.Vec3{0, 1, 0}}
+	if pair.BodyA != ground {
+		previous.Normal = previous.Normal.Mul(-1)
+	}
+	previous.Points = []constraint.ContactPoint{
+		{Position: mgl64.Vec3{0.5, 0, 0.5}, TangentImpulse: mgl64.Vec3{0.1, 0, 0}},
+		{Position: mgl64.Vec3{-0.5, 0, -0.5}, TangentImpulse: mgl64.Vec3{0, 0, 0.2}},
+	}
+	previous.Anchor()
+	world.storeManifolds([]*constraint.ContactConstraint{previous})
+
+	// The bodies come in the other order, one point moved a little, one is new
+	current := &constraint.ContactConstraint{BodyA: pair.BodyB, BodyB: pair.BodyA, Normal: previous.Normal.Mul(-1)}
+	current.Points = []constraint.ContactPoint{
+		{Position: mgl64.Vec3{0.505, 0, 0.5}},
+		{Position: mgl64.Vec3{0.5, 0, -0.5}},
+	}
+	current.Anchor()
+	world.warmStart([]*constraint.ContactConstraint{current})
+
+	if impulse := current.Points[0].TangentImpulse; !impulse.ApproxEqual(mgl64.Vec3{-0.1, 0, 0}) {
+		t.Errorf("tangent impulse %v, want the previous one seen from the swapped bodies", impulse)
+	}
+	if impulse := current.Points[1].TangentImpulse; impulse != (mgl64.Vec3{}) {
+		t.Errorf("tangent impulse %v, want none for a new point", impulse)
+	}
+
+	// A substep without the contact: the pair is forgotten at the end of the Step
+	world.storeManifolds(nil)
+	world.pruneManifolds()
+	if _, ok := world.manifolds[pair]; ok {
+		t.Error("the pair without contact is still cached")
+	}
+}
+
+func TestWarmStart_MatchesFeatures(t *testing.T) {
+	world := NewWorld()
+	ground := createBox(mgl64.Vec3{0, -1, 0}, mgl64.Vec3{5, 1, 5}, actor.BodyTypeStatic)
+	ball := createSphere(mgl64.Vec3{0, 0.5, 0}, 0.5, actor.BodyTypeDynamic)
+	feature := constraint.FeatureID{FaceA: 3, ElementB: constraint.FeatureVertex}
+
+	previous := &constraint.ContactConstraint{BodyA: ground, BodyB: ball, Normal: mgl64.Vec3{0, 1, 0}}
+	previous.Points = []constraint.ContactPoint{
+		{Position: mgl64.Vec3{0, 0, 0}, TangentImpulse: mgl64.Vec3{0.1, 0, 0}, Feature: feature},
+	}
+	previous.Anchor()
+	world.storeManifolds([]*constraint.ContactConstraint{previous})
+
+	// The ball rolled farther than CONTACT_MATCH_DISTANCE, the contact is still between the same features
+	ball.Transform.Position = mgl64.Vec3{0.1, 0.5, 0}
+	current := &constraint.ContactConstraint{BodyA: ball, BodyB: ground, Normal: mgl64.Vec3{0, -1, 0}}
+	current.Points = []constraint.ContactPoint{
+		{Position: mgl64.Vec3{0.1, 0, 0}, Feature: feature.Swapped()},
+	}
+	current.Anchor()
+	world.warmStart([]*constraint.ContactConstraint{current})
+
+	if impulse := current.Points[0].TangentImpulse; !impulse.ApproxEqual(mgl64.Vec3{-0.1, 0, 0}) {
+		t.Errorf("tangent impulse %v, want the impulse of the same features seen from the swapped bodies", impulse)
+	}
+}
+
+func TestWorld_StaggeredStackStaysStable(t *testing.T) {
+	world := NewWorld(WithSubsteps(8))
+	world.Tolerances.SleepTime = math.Inf(1)
+	ground := createBox(mgl64.Vec3{0, -1, 0}, mgl64.Vec3{10, 1, 10}, actor.BodyTypeStatic)
+	ground.Material.StaticFriction, ground.Material.DynamicFriction = 0.5, 0.5
+	world.AddBody(ground)
+
+	// Each box is shifted and turned relative to the one below
+	var boxes []*actor.RigidBody
+	var origins []mgl64.Vec3
+	for i := range 5 {
+		position := mgl64.Vec3{0.15 * float64(i%2), 0.5 + float64(i), 0.05 * float64(i%3)}
+		box := createBox(position, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+		box.Transform.Rotation = mgl64.QuatRotate(0.1*float64(i), mgl64.Vec3{0, 1, 0})
+		box.Transform.InverseRotation = box.Transform.Rotation.Inverse()
+		box.Shape.ComputeAABB(box.Transform)
+		box.Material.StaticFriction, box.Material.DynamicFriction = 0.5, 0.5
+		world.AddBody(box)
+		boxes = append(boxes, box)
+		origins = append(origins, position)
+	}
+
+	for step := 0; step < 300; step++ {
+		world.Step(1.0 / 60.0)
+	}
+
+	for i, box := range boxes {
+		if speed := box.Velocity.Len(); speed > 0.2 {
+			t.Errorf("box %d moves at %v m/s, want resting", i, speed)
+		}
+		drift := box.Transform.Position.Sub(origins[i])
+		if horizontal := math.Hypot(drift.X(), drift.Z()); horizontal > 0.1 {
+			t.Errorf("box %d drifted by %v, want the stack standing", i, horizontal)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package feather
+
+import (
+	"github.com/akmonengine/feather/actor"
+	"github.com/akmonengine/feather/constraint"
+)
+
+// materialCacheEntry is the combined material of a pair, with the materials it was computed from
+type materialCacheEntry struct {
+	materialA actor.Material
+	materialB actor.Material
+	combined  constraint.CombinedMaterial
+	step      uint64
+}
+
+// combineMaterials sets the combined material of the contacts from the cache of their pair.
+// An entry is recomputed only when the material of one of the bodies changed,
+// resting pairs then combine their friction and restitution once.
+func (w *World) combineMaterials(constraints []*constraint.ContactConstraint) {
+	if w.materials == nil {
+		w.materials = make(map[Pair]*materialCacheEntry)
+	}
+
+	for _, c := range constraints {
+		pair := OrderPair(c.BodyA, c.BodyB)
+
+		entry, ok := w.materials[pair]
+		if !ok {
+			entry = &materialCacheEntry{}
+			w.materials[pair] = entry
+		}
+		if !ok || entry.materialA != pair.BodyA.Material || entry.materialB != pair.BodyB.Material {
+			entry.materialA = pair.BodyA.Material
+			entry.materialB = pair.BodyB.Material
+			entry.combined = constraint.CombineMaterials(pair.BodyA.Material, pair.BodyB.Material)
+		}
+		entry.step = w.materialStep
+
+		c.Material = &entry.combined
+	}
+}
+
+// pruneMaterials forgets the pairs without contact during the last step
+func (w *World) pruneMaterials() {
+	for pair, entry := range w.materials {
+		if entry.step != w.materialStep {
+			delete(w.materials, pair)
+		}
+	}
+	w.materialStep++
+}
@@ -0,0 +1,58 @@
+package feather
+
+import (
+	"testing"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/akmonengine/feather/constraint"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestCombineMaterials_Cache(t *testing.T) {
+	world := &World{}
+	bodyA := createBox(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{1, 1, 1}, actor.BodyTypeStatic)
+	bodyB := createBox(mgl64.Vec3{0, 1.9, 0}, mgl64.Vec3{1, 1, 1}, actor.BodyTypeDynamic)
+	bodyA.Material.StaticFriction = 0.4
+	bodyB.Material.StaticFriction = 0.9
+	bodyB.Material.Restitution = 0.5
+
+	first := &constraint.ContactConstraint{BodyA: bodyA, BodyB: bodyB}
+	world.combineMaterials([]*constraint.ContactConstraint{first})
+	expected := constraint.CombineMaterials(bodyA.Material, bodyB.Material)
+	if first.Material == nil || *first.Material != expected {
+		t.Fatalf("Material = %v, want %v", first.Material, expected)
+	}
+
+	// Same pair with swapped bodies, at the next substep: the entry is reused
+	second := &constraint.ContactConstraint{BodyA: bodyB, BodyB: bodyA}
+	world.combineMaterials([]*constraint.ContactConstraint{second})
+	if second.Material != first.Material {
+		t.Error("expected the cached combination to be reused")
+	}
+
+	// A changed material invalidates the entry
+	bodyB.Material.Restitution = 1.0
+	third := &constraint.ContactConstraint{BodyA: bodyA, BodyB: bodyB}
+	world.combineMaterials([]*constraint.ContactConstraint{third})
+	if third.Material.Restitution != constraint.ComputeRestitution(bodyA.Material, bodyB.Material) {
+		t.Errorf("Restitution = %v, want it recomputed after the material changed", third.Material.Restitution)
+	}
+}
+
+func TestPruneMaterials(t *testing.T) {
+	world := &World{}
+	bodyA := createBox(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{1, 1, 1}, actor.BodyTypeStatic)
+	bodyB := createBox(mgl64.Vec3{0, 1.9, 0}, mgl64.Vec3{1, 1, 1}, actor.BodyTypeDynamic)
+
+	world.combineMaterials([]*constraint.ContactConstraint{{BodyA: bodyA, BodyB: bodyB}})
+	world.pruneMaterials()
+	if len(world.materials) != 1 {
+		t.Fatalf("expected the pair in contact during the step to be kept, got %d entries", len(world.materials))
+	}
+
+	// No contact during the next step
+	world.pruneMaterials()
+	if len(world.materials) != 0 {
+		t.Errorf("expected the separated pair to be forgotten, got %d entries", len(world.materials))
+	}
+}
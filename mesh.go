@@ -0,0 +1,213 @@
+package feather
+
+import (
+	"math"
+	"sync"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/akmonengine/feather/constraint"
+	"github.com/akmonengine/feather/epa"
+	"github.com/akmonengine/feather/gjk"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// isMesh returns true if the body is a TriangleMesh
+func isMesh(body *actor.RigidBody) bool {
+	_, ok := body.Shape.(*actor.TriangleMesh)
+
+	return ok
+}
+
+// collideMesh tests the convex bodies, or the children of the compounds, against the triangles of the meshes, found by the BVH.
+// Each colliding triangle gives its own contact. The convex body is BodyA: the triangle is then
+// the incident feature of the manifold, clipped against the face of the body.
+// The triangles are one-sided, see triangleContact. The contacts of the trigger children are sensors, see collideCompound.
+func collideMesh(pairs <-chan Pair, workersCount int, allocator Allocator, counters *stepCounters) <-chan *constraint.ContactConstraint {
+	ch := make(chan *constraint.ContactConstraint, workersCount)
+
+	go func() {
+		var wg sync.WaitGroup
+		defer close(ch)
+
+		for range workersCount {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				var triangles []int
+				var parts []*actor.RigidBody
+				var contacts []constraint.ContactConstraint
+				for pair := range pairs {
+					meshBody, object := pair.BodyA, pair.BodyB
+					if !isMesh(meshBody) {
+						meshBody, object = object, meshBody
+					}
+					mesh := meshBody.Shape.(*actor.TriangleMesh)
+
+					// SupportWorld uses the inverse rotation, static bodies never integrate to update it
+					transform := meshBody.Transform
+					transform.InverseRotation = transform.Rotation.Inverse()
+
+					// The children of a compound are tested one by one against the triangles
+					parts = bodyParts(object, parts[:0])
+					contacts = contacts[:0]
+					for i, part := range parts {
+						triangles = mesh.Overlapping(part.Shape.GetAABB(), transform, triangles[:0])
+						for _, index := range triangles {
+							triangle := &actor.RigidBody{
+								Transform: transform,
+								Shape:     mesh.Triangle(index),
+								BodyType:  actor.BodyTypeStatic,
+								Material:  meshBody.Material,
+							}
+
+							simplex := gjk.SimplexPool.Get().(*gjk.Simplex)
+							simplex.Reset()
+
+							report := gjk.GJKReport(part, triangle, simplex)
+							gjk.SimplexPool.Put(simplex)
+							if report.Capped && counters != nil {
+								counters.addGJKCapped(object, meshBody)
+							}
+							if !report.Collision {
+								continue
+							}
+
+							contact, ok := triangleContact(part, triangle)
+							if !ok {
+								continue
+							}
+							for j := range contact.Points {
+								contact.Points[j].Feature.PartA = uint32(i)
+								contact.Points[j].Feature.PartB = uint32(index)
+							}
+
+							if isTriggerChild(object, uint32(i)) {
+								contact.Sensor = true
+								contacts = append(contacts, contact)
+								continue
+							}
+							contacts = mergeCoplanarContact(contacts, contact)
+						}
+					}
+
+					for _, contact := range contacts {
+						contact.Points = reduceMergedPoints(contact.Points)
+						result := allocator.Contact()
+						*result = contact
+						result.BodyA = object
+						result.BodyB = meshBody
+						result.Points = append(allocator.Points(len(contact.Points))[:0], contact.Points...)
+						ch <- result
+					}
+				}
+			}()
+		}
+
+		wg.Wait()
+	}()
+
+	return ch
+}
+
+// mergeCoplanarContact adds the contact to the contacts of the pair. The contacts of the triangles
+// sharing its normal (e.g. the two triangles of a quad) are merged into one constraint:
+// solved separately, each of them would push the object out of the whole penetration. The sensors are not merged.
+func mergeCoplanarContact(contacts []constraint.ContactConstraint, contact constraint.ContactConstraint) []constraint.ContactConstraint {
+	const coplanarThreshold = 1 - 1e-6
+
+	for i := range contacts {
+		if contacts[i].Sensor || contacts[i].Normal.Dot(contact.Normal) < coplanarThreshold {
+			continue
+		}
+
+		// Points on the shared edges are found by both triangles
+		for _, point := range contact.Points {
+			duplicate := false
+			for _, existing := range contacts[i].Points {
+				if existing.Position.Sub(point.Position).LenSqr() < 1e-12 {
+					duplicate = true
+					break
+				}
+			}
+			if !duplicate {
+				contacts[i].Points = append(contacts[i].Points, point)
+			}
+		}
+
+		return contacts
+	}
+
+	return append(contacts, contact)
+}
+
+// reduceMergedPoints keeps 4 points of a merged manifold by farthest point sampling.
+// The points found on the shared edges of the triangles are inside the contact area:
+// the corners are kept, the solver would otherwise get an off-center correction and tilt the object.
+func reduceMergedPoints(points []constraint.ContactPoint) []constraint.ContactPoint {
+	const maxPoints = 4
+	if len(points) <= maxPoints {
+		return points
+	}
+
+	var center mgl64.Vec3
+	for _, point := range points {
+		center = center.Add(point.Position)
+	}
+	center = center.Mul(1.0 / float64(len(points)))
+
+	// Start from the farthest point from the center, then the farthest from the selected ones
+	selected := 0
+	for i, point := range points {
+		if point.Position.Sub(center).LenSqr() > points[selected].Position.Sub(center).LenSqr() {
+			selected = i
+		}
+	}
+	points[0], points[selected] = points[selected], points[0]
+
+	for count := 1; count < maxPoints; count++ {
+		farthest, farthestDistSq := count, -1.0
+		for i := count; i < len(points); i++ {
+			distSq := math.Inf(1)
+			for _, chosen := range points[:count] {
+				distSq = math.Min(distSq, points[i].Position.Sub(chosen.Position).LenSqr())
+			}
+			if distSq > farthestDistSq {
+				farthest, farthestDistSq = i, distSq
+			}
+		}
+		points[count], points[farthest] = points[farthest], points[count]
+	}
+
+	return points[:maxPoints]
+}
+
+// triangleContact computes the contact of an object overlapping a triangle along the triangle normal:
+// EPA would find the shortest way out of a thin triangle, sideways across its edges, and push
+// the bodies resting on small triangles off the mesh. Objects whose center is behind the front side
+// (counter-clockwise winding) are not pushed back through it.
+func triangleContact(object, triangleBody *actor.RigidBody) (constraint.ContactConstraint, bool) {
+	triangle := triangleBody.Shape.(*actor.Triangle)
+	transform := triangleBody.Transform
+
+	a := transform.Rotation.Rotate(triangle.A).Add(transform.Position)
+	normal := transform.Rotation.Rotate(triangle.B.Sub(triangle.A).Cross(triangle.C.Sub(triangle.A))).Normalize()
+	if object.Transform.Position.Sub(a).Dot(normal) < 0 {
+		return constraint.ContactConstraint{}, false
+	}
+
+	depth := a.Sub(object.SupportWorld(normal.Mul(-1))).Dot(normal)
+	if depth <= 0 {
+		return constraint.ContactConstraint{}, false
+	}
+
+	// From the object towards the triangle
+	normal = normal.Mul(-1)
+
+	return constraint.ContactConstraint{
+		BodyA:  object,
+		BodyB:  triangleBody,
+		Points: epa.GenerateManifold(object, triangleBody, normal, depth),
+		Normal: normal,
+	}, true
+}
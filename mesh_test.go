@@ -0,0 +1,87 @@
+package feather
+
+import (
+	"math"
+	"testing"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/akmonengine/feather/constraint"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestMergeCoplanarContact(t *testing.T) {
+	up := mgl64.Vec3{0, -1, 0}
+	point := func(x, z float64) constraint.ContactPoint {
+		return constraint.ContactPoint{Position: mgl64.Vec3{x, 0, z}, Penetration: 0.1}
+	}
+
+	// The two triangles of a quad under a box: the corners and the points of the shared diagonal
+	var contacts []constraint.ContactConstraint
+	contacts = mergeCoplanarContact(contacts, constraint.ContactConstraint{Normal: up, Points: []constraint.ContactPoint{point(-1, -1), point(1, -1), point(-1, 1), point(0.5, -0.5)}})
+	contacts = mergeCoplanarContact(contacts, constraint.ContactConstraint{Normal: up, Points: []constraint.ContactPoint{point(1, -1), point(-1, 1), point(1, 1), point(-0.5, 0.5)}})
+	// A slope
+	contacts = mergeCoplanarContact(contacts, constraint.ContactConstraint{Normal: mgl64.Vec3{0.5, -0.5, 0}.Normalize(), Points: []constraint.ContactPoint{point(2, 0)}})
+
+	if len(contacts) != 2 {
+		t.Fatalf("contacts = %d, want the quad merged and the slope apart", len(contacts))
+	}
+	if len(contacts[0].Points) != 6 {
+		t.Fatalf("merged points = %d, want 6 without the duplicated corners", len(contacts[0].Points))
+	}
+
+	reduced := reduceMergedPoints(contacts[0].Points)
+	if len(reduced) != 4 {
+		t.Fatalf("reduced points = %d, want 4", len(reduced))
+	}
+	for _, p := range reduced {
+		if math.Abs(p.Position.X()) != 1 || math.Abs(p.Position.Z()) != 1 {
+			t.Errorf("point %v kept, want the corners of the quad only", p.Position)
+		}
+	}
+}
+
+// TestWorld_RestsOnTriangleMesh drops a box and a sphere on a floor mesh, across several triangles
+func TestWorld_RestsOnTriangleMesh(t *testing.T) {
+	const n = 8
+	var vertices []mgl64.Vec3
+	for i := 0; i <= n; i++ {
+		for j := 0; j <= n; j++ {
+			vertices = append(vertices, mgl64.Vec3{float64(i) - n/2, 0, float64(j) - n/2})
+		}
+	}
+	var indices [][3]int
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			a := i*(n+1) + j
+			b := a + n + 1
+			indices = append(indices, [3]int{a, a + 1, b}, [3]int{b, a + 1, b + 1})
+		}
+	}
+	mesh, err := actor.NewTriangleMesh(vertices, indices)
+	if err != nil {
+		t.Fatalf("NewTriangleMesh failed: %v", err)
+	}
+
+	for _, grid := range []*SpatialGrid{nil, NewSpatialGrid(2, 256)} {
+		world := &World{Substeps: 8, Gravity: mgl64.Vec3{0, -9.81, 0}, Events: NewEvents(), SpatialGrid: grid, BruteForceThreshold: -1}
+		world.AddBody(actor.NewRigidBody(actor.Transform{Position: mgl64.Vec3{0, -1, 0}, Rotation: mgl64.QuatIdent()}, mesh, actor.BodyTypeStatic, 0))
+		box := createBox(mgl64.Vec3{0.3, 0, 0.2}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+		sphere := createSphere(mgl64.Vec3{-2, 0, 1.5}, 0.5, actor.BodyTypeDynamic)
+		world.AddBody(box)
+		world.AddBody(sphere)
+
+		for range 120 {
+			world.Step(1.0 / 60.0)
+		}
+
+		if y := box.Transform.Position.Y(); math.Abs(y+0.5) > 0.02 {
+			t.Errorf("grid %v: box height = %v, want about -0.5", grid != nil, y)
+		}
+		if up := box.Transform.Rotation.Rotate(mgl64.Vec3{0, 1, 0}); up.Y() < 0.99 {
+			t.Errorf("grid %v: box tilted, up = %v", grid != nil, up)
+		}
+		if y := sphere.Transform.Position.Y(); math.Abs(y+0.5) > 0.02 {
+			t.Errorf("grid %v: sphere height = %v, want about -0.5", grid != nil, y)
+		}
+	}
+}
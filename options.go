@@ -0,0 +1,207 @@
+package feather
+
+import (
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+const (
+	// DEFAULT_SUBSTEPS is the substeps count of the worlds created by NewWorld
+	DEFAULT_SUBSTEPS = 4
+	// DEFAULT_GRID_CELL_SIZE, DEFAULT_GRID_CELLS and DEFAULT_GRID_LEVELS size the hierarchical SpatialGrid
+	// of the worlds created by NewWorld: 1m, 8m and 64m cells
+	DEFAULT_GRID_CELL_SIZE = 1.0
+	DEFAULT_GRID_CELLS     = 4096
+	DEFAULT_GRID_LEVELS    = 3
+	// DEFAULT_POSITION_ITERATIONS and DEFAULT_VELOCITY_ITERATIONS are the solver passes of each substep
+	// when World.PositionIterations and World.VelocityIterations are 0: the substeps converge with one pass
+	DEFAULT_POSITION_ITERATIONS = 1
+	DEFAULT_VELOCITY_ITERATIONS = 1
+	// DEFAULT_PENETRATION_SLOP (m) and DEFAULT_CORRECTION_RATE are the split impulse parameters
+	// when World.PenetrationSlop and World.CorrectionRate are 0, see World.SplitImpulse
+	DEFAULT_PENETRATION_SLOP = 0.001
+	DEFAULT_CORRECTION_RATE  = 0.8
+	// DEFAULT_CLOTH_SUBSTEPS is the substeps count of the cloths whose ClothSettings.Substeps is 0:
+	// the light particles of a cloth need more substeps than the bodies to stay stiff
+	DEFAULT_CLOTH_SUBSTEPS = 16
+)
+
+// Tolerances are the thresholds of the sleep system.
+// The zero fields use the defaults: SLEEP_TIME_THRESHOLD, SLEEP_VELOCITY_THRESHOLD, SLEEP_ANGULAR_VELOCITY_THRESHOLD,
+// WAKE_VELOCITY_THRESHOLD and WAKE_ANGULAR_VELOCITY_THRESHOLD.
+type Tolerances struct {
+	// SleepTime is the duration (s) a body must stay under SleepVelocity and SleepAngularVelocity to fall asleep
+	SleepTime float64
+	// SleepVelocity is the linear velocity (m/s) under which a body can sleep
+	SleepVelocity float64
+	// WakeVelocity is the linear velocity a sleeping body must receive to wake up, higher than SleepVelocity
+	WakeVelocity float64
+	// SleepAngularVelocity and WakeAngularVelocity are the angular counterparts (rad/s) of SleepVelocity and WakeVelocity.
+	// Large bodies rotating slowly move their surface fast, they need a lower angular threshold.
+	SleepAngularVelocity float64
+	WakeAngularVelocity  float64
+}
+
+// withDefaults replaces the zero fields by their default value
+func (t Tolerances) withDefaults() Tolerances {
+	if t.SleepTime == 0 {
+		t.SleepTime = SLEEP_TIME_THRESHOLD
+	}
+	if t.SleepVelocity == 0 {
+		t.SleepVelocity = SLEEP_VELOCITY_THRESHOLD
+	}
+	if t.WakeVelocity == 0 {
+		t.WakeVelocity = WAKE_VELOCITY_THRESHOLD
+	}
+	if t.SleepAngularVelocity == 0 {
+		t.SleepAngularVelocity = SLEEP_ANGULAR_VELOCITY_THRESHOLD
+	}
+	if t.WakeAngularVelocity == 0 {
+		t.WakeAngularVelocity = WAKE_ANGULAR_VELOCITY_THRESHOLD
+	}
+
+	return t
+}
+
+// thresholds returns the tolerances given to actor.RigidBody.TrySleep
+func (t Tolerances) thresholds() actor.SleepThresholds {
+	return actor.SleepThresholds{
+		Time:                t.SleepTime,
+		LinearVelocity:      t.SleepVelocity,
+		AngularVelocity:     t.SleepAngularVelocity,
+		WakeLinearVelocity:  t.WakeVelocity,
+		WakeAngularVelocity: t.WakeAngularVelocity,
+	}
+}
+
+// WorldOption configures a World created by NewWorld
+type WorldOption func(*World)
+
+// NewWorld creates a world ready to step: Earth gravity, DEFAULT_SUBSTEPS substeps, a hierarchical SpatialGrid
+// and the events wired. The options override these defaults, e.g.
+//
+//	world := feather.NewWorld(feather.WithSubsteps(8), feather.WithWorkers(runtime.NumCPU()))
+//
+// New defaults may be added in later versions, the worlds created by NewWorld get them.
+func NewWorld(options ...WorldOption) *World {
+	w := &World{
+		Gravity:     mgl64.Vec3{0, -9.81, 0},
+		Substeps:    DEFAULT_SUBSTEPS,
+		SpatialGrid: NewHierarchicalSpatialGrid(DEFAULT_GRID_CELL_SIZE, DEFAULT_GRID_CELLS, DEFAULT_GRID_LEVELS),
+		Workers:     DEFAULT_WORKERS,
+		Events:      NewEvents(),
+	}
+
+	for _, option := range options {
+		option(w)
+	}
+
+	return w
+}
+
+// WithGravity sets the gravity acceleration (m/s²)
+func WithGravity(gravity mgl64.Vec3) WorldOption {
+	return func(w *World) {
+		w.Gravity = gravity
+	}
+}
+
+// WithSubsteps sets the substeps count of each Step, at least 1
+func WithSubsteps(substeps int) WorldOption {
+	return func(w *World) {
+		w.Substeps = max(1, substeps)
+	}
+}
+
+// WithBroadPhase sets the SpatialGrid and the body count under which the grid is skipped for a brute force,
+// see World.BruteForceThreshold. A nil grid always uses the brute force.
+func WithBroadPhase(grid *SpatialGrid, bruteForceThreshold int) WorldOption {
+	return func(w *World) {
+		w.SpatialGrid = grid
+		w.BruteForceThreshold = bruteForceThreshold
+	}
+}
+
+// WithDynamicTree replaces the SpatialGrid by a DynamicTree fattening the AABBs by margin (m), for scenes mixing
+// bodies of very different sizes. A zero margin uses DEFAULT_TREE_MARGIN.
+func WithDynamicTree(margin float64) WorldOption {
+	return func(w *World) {
+		w.Broadphase = NewDynamicTree(margin)
+	}
+}
+
+// WithWorkers sets the number of goroutines of the pipeline, at least DEFAULT_WORKERS
+func WithWorkers(workers int) WorldOption {
+	return func(w *World) {
+		w.Workers = max(DEFAULT_WORKERS, workers)
+	}
+}
+
+// WithBroadPhaseWorkers sets the number of goroutines of the broad phase pair search, 0 uses all the cores
+func WithBroadPhaseWorkers(workers int) WorldOption {
+	return func(w *World) {
+		w.BroadPhaseWorkers = max(0, workers)
+	}
+}
+
+// WithSolverWorkers sets the number of goroutines solving the islands, 0 uses all the cores
+func WithSolverWorkers(workers int) WorldOption {
+	return func(w *World) {
+		w.SolverWorkers = max(0, workers)
+	}
+}
+
+// WithIterations sets the position and velocity passes of the solver at each substep
+func WithIterations(position, velocity int) WorldOption {
+	return func(w *World) {
+		w.PositionIterations = max(0, position)
+		w.VelocityIterations = max(0, velocity)
+	}
+}
+
+// WithSolver sets the position backend of the solver and the compliance of the contacts (0 uses the default one)
+func WithSolver(solver Solver, contactCompliance float64) WorldOption {
+	return func(w *World) {
+		w.Solver = solver
+		w.ContactCompliance = max(0, contactCompliance)
+	}
+}
+
+// WithSplitImpulse enables the split impulse, with the penetration slop (m) and the correction rate
+// (0 uses the defaults), see World.SplitImpulse
+func WithSplitImpulse(slop, rate float64) WorldOption {
+	return func(w *World) {
+		w.SplitImpulse = true
+		w.PenetrationSlop = max(0, slop)
+		w.CorrectionRate = max(0, min(rate, 1))
+	}
+}
+
+// WithTimeScale sets World.TimeScale, e.g. 0.25 for slow motion
+func WithTimeScale(scale float64) WorldOption {
+	return func(w *World) {
+		w.TimeScale = max(0, scale)
+	}
+}
+
+// WithMaxVelocities sets World.MaxVelocity (m/s) and World.MaxAngularVelocity (rad/s), 0 disables a limit
+func WithMaxVelocities(linear, angular float64) WorldOption {
+	return func(w *World) {
+		w.MaxVelocity = max(0, linear)
+		w.MaxAngularVelocity = max(0, angular)
+	}
+}
+
+// WithValidation sets World.Validate, Step then returns a ValidationError on the first invalid body
+func WithValidation() WorldOption {
+	return func(w *World) {
+		w.Validate = true
+	}
+}
+
+// WithTolerances sets the thresholds of the sleep system
+func WithTolerances(tolerances Tolerances) WorldOption {
+	return func(w *World) {
+		w.Tolerances = tolerances
+	}
+}
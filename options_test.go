@@ -0,0 +1,151 @@
+package feather
+
+import (
+	"math"
+	"testing"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestNewWorld_Defaults(t *testing.T) {
+	world := NewWorld()
+
+	if world.Gravity != (mgl64.Vec3{0, -9.81, 0}) || world.Substeps != DEFAULT_SUBSTEPS || world.Workers != DEFAULT_WORKERS {
+		t.Errorf("unexpected defaults: gravity %v, substeps %d, workers %d", world.Gravity, world.Substeps, world.Workers)
+	}
+	if world.SpatialGrid == nil {
+		t.Error("expected a SpatialGrid")
+	}
+
+	// The events are wired: a world created without options steps and dispatches events
+	sphere := createSphere(mgl64.Vec3{0, 0.45, 0}, 0.5, actor.BodyTypeDynamic)
+	world.AddBody(createPlane(mgl64.Vec3{0, 1, 0}, 0))
+	world.AddBody(sphere)
+	capture := &eventCapture{}
+	world.Events.Subscribe(COLLISION_ENTER, capture.capture)
+	world.Step(1.0 / 60.0)
+	if capture.count() != 1 {
+		t.Errorf("COLLISION_ENTER events = %d, want 1", capture.count())
+	}
+}
+
+func TestNewWorld_Options(t *testing.T) {
+	grid := NewSpatialGrid(2.0, 256)
+	tolerances := Tolerances{SleepTime: 1, SleepVelocity: 0.1}
+	world := NewWorld(
+		WithGravity(mgl64.Vec3{0, -1.62, 0}),
+		WithSubsteps(0),
+		WithBroadPhase(grid, -1),
+		WithWorkers(4),
+		WithTolerances(tolerances),
+		WithIterations(4, -1),
+		WithSolver(SOLVER_XPBD, 1e-4),
+		WithSplitImpulse(0.01, 2),
+		WithSolverWorkers(-2),
+		WithTimeScale(0.5),
+		WithMaxVelocities(100, -1),
+		WithValidation(),
+	)
+
+	if world.Gravity != (mgl64.Vec3{0, -1.62, 0}) {
+		t.Errorf("Gravity = %v, want the Moon gravity", world.Gravity)
+	}
+	if world.Substeps != 1 {
+		t.Errorf("Substeps = %d, want at least 1", world.Substeps)
+	}
+	if world.SpatialGrid != grid || world.BruteForceThreshold != -1 {
+		t.Errorf("broad phase not set: %p, %d", world.SpatialGrid, world.BruteForceThreshold)
+	}
+	if world.Workers != 4 {
+		t.Errorf("Workers = %d, want 4", world.Workers)
+	}
+	if world.SolverWorkers != 0 {
+		t.Errorf("SolverWorkers = %d, want 0 (all the cores) for a negative count", world.SolverWorkers)
+	}
+	if world.PositionIterations != 4 || world.VelocityIterations != 0 {
+		t.Errorf("iterations = %d, %d, want 4 and the default", world.PositionIterations, world.VelocityIterations)
+	}
+	if world.Solver != SOLVER_XPBD || world.ContactCompliance != 1e-4 {
+		t.Errorf("solver = %d with compliance %v, want XPBD with 1e-4", world.Solver, world.ContactCompliance)
+	}
+	if !world.SplitImpulse || world.PenetrationSlop != 0.01 || world.CorrectionRate != 1 {
+		t.Errorf("split impulse %v with slop %v and rate %v, want enabled with 0.01 and 1", world.SplitImpulse, world.PenetrationSlop, world.CorrectionRate)
+	}
+	if world.Tolerances != tolerances {
+		t.Errorf("Tolerances = %+v, want %+v", world.Tolerances, tolerances)
+	}
+	if world.TimeScale != 0.5 {
+		t.Errorf("TimeScale = %v, want 0.5", world.TimeScale)
+	}
+	if world.MaxVelocity != 100 || world.MaxAngularVelocity != 0 {
+		t.Errorf("max velocities = %v, %v, want 100 and no angular limit", world.MaxVelocity, world.MaxAngularVelocity)
+	}
+	if !world.Validate {
+		t.Errorf("Validate = false, want true")
+	}
+
+	defaults := world.Tolerances.withDefaults()
+	if defaults.SleepTime != 1 || defaults.SleepVelocity != 0.1 || defaults.WakeVelocity != WAKE_VELOCITY_THRESHOLD {
+		t.Errorf("withDefaults = %+v, want the zero fields replaced only", defaults)
+	}
+	if defaults.SleepAngularVelocity != SLEEP_ANGULAR_VELOCITY_THRESHOLD || defaults.WakeAngularVelocity != WAKE_ANGULAR_VELOCITY_THRESHOLD {
+		t.Errorf("withDefaults = %+v, want the default angular velocities", defaults)
+	}
+}
+
+// TestWorld_SleepTolerances checks a longer SleepTime delays the sleep of a resting body
+func TestWorld_SleepTolerances(t *testing.T) {
+	sleepAfter := func(tolerances Tolerances) float64 {
+		world := NewWorld(WithTolerances(tolerances))
+		world.AddBody(createPlane(mgl64.Vec3{0, 1, 0}, 0))
+		box := createBox(mgl64.Vec3{0, 0.5, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+		world.AddBody(box)
+
+		for i := 1; i <= 600; i++ {
+			world.Step(1.0 / 60.0)
+			if box.IsSleeping {
+				return float64(i) / 60.0
+			}
+		}
+
+		return math.Inf(1)
+	}
+
+	fast := sleepAfter(Tolerances{})
+	slow := sleepAfter(Tolerances{SleepTime: 2})
+	if math.IsInf(fast, 1) || slow < 2 || slow <= fast {
+		t.Errorf("slept after %vs with the defaults and %vs with SleepTime 2, want later", fast, slow)
+	}
+}
+
+// TestWorld_SleepWakeUpEvents checks RigidBody.Sleep and WakeUp emit ON_SLEEP and ON_WAKE at the next Step
+func TestWorld_SleepWakeUpEvents(t *testing.T) {
+	world := NewWorld(WithTolerances(Tolerances{SleepTime: math.Inf(1)}))
+	box := createBox(mgl64.Vec3{0, 5, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+	world.AddBody(box)
+
+	var received []Event
+	world.Events.Subscribe(ON_SLEEP, func(event Event) { received = append(received, event) })
+	world.Events.Subscribe(ON_WAKE, func(event Event) { received = append(received, event) })
+	world.Step(1.0 / 60.0)
+
+	box.Sleep()
+	height := box.Transform.Position.Y()
+	world.Step(1.0 / 60.0)
+	if len(received) != 1 || received[0] != (SleepEvent{Body: box}) {
+		t.Fatalf("events = %v, want ON_SLEEP after Sleep", received)
+	}
+	if box.Transform.Position.Y() != height {
+		t.Errorf("sleeping box at %v, want frozen at y=%v", box.Transform.Position, height)
+	}
+
+	box.WakeUp()
+	world.Step(1.0 / 60.0)
+	if len(received) != 2 || received[1] != (WakeEvent{Body: box}) {
+		t.Fatalf("events = %v, want ON_WAKE after WakeUp", received)
+	}
+	if box.Transform.Position.Y() >= height {
+		t.Errorf("woken box at %v, want falling", box.Transform.Position)
+	}
+}
@@ -0,0 +1,71 @@
+package feather
+
+import (
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// OriginShifter is implemented by the user constraints and force fields holding world-space positions (e.g. anchors),
+// World.ShiftOrigin translates them with the bodies
+type OriginShifter interface {
+	ShiftOrigin(offset mgl64.Vec3)
+}
+
+// ShiftOrigin translates the whole world by offset: the bodies (current and previous transforms, so the
+// interpolation and the trigger sweeps are not disturbed), the planes, the particles, cloths and particle systems,
+// the Broadphase entries, the BudgetFocus, and the user constraints and force fields implementing OriginShifter.
+// Velocities, sleep states and events are unchanged.
+//
+// Huge open worlds re-center it periodically around the player, e.g. world.ShiftOrigin(player.Transform.Position.Mul(-1)),
+// so the contact tolerances stay tuned and the float32 rendering stays precise.
+// It must be called between two Steps. The AccelerationProviders capturing a position (e.g. RotatingFrameAcceleration)
+// are not updated, they must be registered again.
+func (w *World) ShiftOrigin(offset mgl64.Vec3) {
+	for _, body := range w.Bodies {
+		if plane, ok := body.Shape.(*actor.Plane); ok {
+			// Planes collide from their normal and distance in world space, their transform is ignored
+			plane.Distance -= plane.Normal.Dot(offset)
+			plane.ComputeAABB(body.Transform)
+			continue
+		}
+
+		body.Transform.Position = body.Transform.Position.Add(offset)
+		body.PreviousTransform.Position = body.PreviousTransform.Position.Add(offset)
+		body.Shape.ComputeAABB(body.Transform)
+	}
+
+	for _, particle := range w.particles {
+		particle.Position = particle.Position.Add(offset)
+		particle.PreviousPosition = particle.PreviousPosition.Add(offset)
+	}
+	for _, cloth := range w.cloths {
+		for _, particle := range cloth.Particles {
+			particle.Position = particle.Position.Add(offset)
+			particle.PreviousPosition = particle.PreviousPosition.Add(offset)
+		}
+	}
+	for _, system := range w.particleSystems {
+		for i := range system.Particles {
+			system.Particles[i].Position = system.Particles[i].Position.Add(offset)
+		}
+	}
+
+	for _, c := range w.constraints {
+		if shifter, ok := c.(OriginShifter); ok {
+			shifter.ShiftOrigin(offset)
+		}
+	}
+	for _, field := range w.forceFields {
+		if shifter, ok := field.(OriginShifter); ok {
+			shifter.ShiftOrigin(offset)
+		}
+	}
+
+	w.BudgetFocus = w.BudgetFocus.Add(offset)
+
+	// The broadphase is rebuilt, so the queries run before the next Step see the shifted bodies
+	if broadphase := w.broadphase(); broadphase != nil && !w.bruteForce {
+		broadphase.Update(w.Bodies)
+		w.staleBroadphase = false
+	}
+}
@@ -0,0 +1,77 @@
+package feather
+
+import (
+	"math"
+	"testing"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// shiftRecorder is a user constraint recording the offsets it receives
+type shiftRecorder struct {
+	offset mgl64.Vec3
+}
+
+func (r *shiftRecorder) SolvePosition(dt float64)      {}
+func (r *shiftRecorder) SolveVelocity(dt float64)      {}
+func (r *shiftRecorder) ShiftOrigin(offset mgl64.Vec3) { r.offset = r.offset.Add(offset) }
+
+// TestShiftOrigin steps two identical worlds, one of them is shifted midway: the box sliding on the plane
+// follows the same motion. A single contact keeps the solver order, and both worlds, deterministic.
+func TestShiftOrigin(t *testing.T) {
+	createSlideWorld := func() (*World, *actor.RigidBody) {
+		world := NewWorld(WithBroadPhase(NewSpatialGrid(1, 1024), -1))
+		world.AddBody(createPlane(mgl64.Vec3{0, 1, 0}, 0))
+		box := createBox(mgl64.Vec3{0, 0.6, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+		box.Velocity = mgl64.Vec3{2, 0, 1}
+		box.AngularVelocity = mgl64.Vec3{0, 1, 0}
+		world.AddBody(box)
+
+		return world, box
+	}
+
+	reference, referenceBox := createSlideWorld()
+	shifted, shiftedBox := createSlideWorld()
+	recorder := &shiftRecorder{}
+	shifted.AddConstraint(recorder)
+
+	offset := mgl64.Vec3{1000, -250, 3000}
+	for i := range 120 {
+		if i == 30 {
+			shifted.ShiftOrigin(offset)
+		}
+		reference.Step(1.0 / 60.0)
+		shifted.Step(1.0 / 60.0)
+	}
+
+	expected := referenceBox.Transform.Position.Add(offset)
+	if position := shiftedBox.Transform.Position; !vec3ApproxEqual(position, expected, 1e-6) {
+		t.Errorf("box at %v, want %v", position, expected)
+	}
+	if distance := shiftedBox.Transform.Position.Sub(offset).Sub(mgl64.Vec3{0, 0.6, 0}).Len(); distance < 1 {
+		t.Errorf("box slid by %v, want a motion across the shift", distance)
+	}
+	if recorder.offset != offset {
+		t.Errorf("constraint shifted by %v, want %v", recorder.offset, offset)
+	}
+}
+
+// TestShiftOrigin_Queries checks the SpatialGrid is rebuilt before the next Step
+func TestShiftOrigin_Queries(t *testing.T) {
+	sphere := createSphere(mgl64.Vec3{5, 0, 0}, 1, actor.BodyTypeStatic)
+	world := createQueryWorld(sphere)
+	offset := mgl64.Vec3{-20, 0, 7}
+	world.ShiftOrigin(offset)
+
+	hit, found := world.Raycast(offset, mgl64.Vec3{1, 0, 0}, 10, nil)
+	if !found || hit.Body != sphere || math.Abs(hit.Distance-4) > 1e-9 {
+		t.Fatalf("expected the shifted sphere at 4, got %+v", hit)
+	}
+	if aabb := sphere.Shape.GetAABB(); !vec3ApproxEqual(aabb.Min, mgl64.Vec3{-16, -1, 6}, 1e-9) {
+		t.Errorf("AABB min = %v, want {-16 -1 6}", aabb.Min)
+	}
+	if _, found := world.Raycast(mgl64.Vec3{}, mgl64.Vec3{1, 0, 0}, 10, nil); found {
+		t.Error("expected nothing left at the former position")
+	}
+}
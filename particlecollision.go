@@ -0,0 +1,97 @@
+package feather
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/akmonengine/feather/constraint"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// newParticleProxies creates the spheres of radius standing for the particles in the narrow phase, see collideParticles
+func newParticleProxies(particles []*actor.Particle, radius float64) []*actor.RigidBody {
+	proxies := make([]*actor.RigidBody, len(particles))
+	for i, particle := range particles {
+		proxies[i] = actor.NewRigidBody(actor.Transform{Position: particle.Position, Rotation: mgl64.QuatIdent()},
+			&actor.Sphere{Radius: radius}, actor.BodyTypeDynamic, 1.0)
+	}
+
+	return proxies
+}
+
+// collideParticles detects the particles touching the rigid bodies: their proxy spheres are moved onto them and given
+// to the narrow phase with the bodies overlapping their bounds, triggers excluded. The contacts are returned sorted by
+// particle then by body, the narrow phase workers sending them in any order.
+func (w *World) collideParticles(particles []*actor.Particle, proxies []*actor.RigidBody, radius, friction float64) []*constraint.ParticleContact {
+	if len(particles) == 0 {
+		return nil
+	}
+
+	index := make(map[*actor.RigidBody]int, len(proxies))
+	bounds := actor.AABB{Min: particles[0].Position, Max: particles[0].Position}
+	for i, particle := range particles {
+		proxy := proxies[i]
+		proxy.Transform.Position = particle.Position
+		proxy.Shape.ComputeAABB(proxy.Transform)
+		index[proxy] = i
+		for axis := range 3 {
+			bounds.Min[axis] = min(bounds.Min[axis], particle.Position[axis])
+			bounds.Max[axis] = max(bounds.Max[axis], particle.Position[axis])
+		}
+	}
+	margin := mgl64.Vec3{radius, radius, radius}
+	bounds = actor.AABB{Min: bounds.Min.Sub(margin), Max: bounds.Max.Add(margin)}
+
+	var pairs []Pair
+	for _, body := range w.QueryAABB(bounds, func(body *actor.RigidBody) bool { return !body.IsTrigger }) {
+		_, isPlane := body.Shape.(*actor.Plane)
+		for _, proxy := range proxies {
+			if isPlane || proxy.Shape.GetAABB().Overlaps(body.Shape.GetAABB()) {
+				pairs = append(pairs, Pair{BodyA: body, BodyB: proxy})
+			}
+		}
+	}
+	if len(pairs) == 0 {
+		return nil
+	}
+
+	pairsChan := make(chan Pair, len(pairs))
+	for _, pair := range pairs {
+		pairsChan <- pair
+	}
+	close(pairsChan)
+	contacts := narrowPhase(pairsChan, w.Workers, nil, heapAllocator{}, len(pairs), w.collisions())
+
+	particleContacts := make([]*constraint.ParticleContact, 0, len(contacts))
+	for _, contact := range contacts {
+		// The trigger children, like the trigger bodies, let the particles through
+		if contact.Sensor {
+			continue
+		}
+
+		// The normal of the contact points from BodyA to BodyB, the particle contact needs it toward the particle
+		body, proxy, normal := contact.BodyA, contact.BodyB, contact.Normal
+		if _, ok := index[proxy]; !ok {
+			body, proxy, normal = proxy, body, normal.Mul(-1)
+		}
+
+		depth := 0.0
+		for _, point := range contact.Points {
+			depth = max(depth, point.Penetration)
+		}
+		particle := particles[index[proxy]]
+		point := particle.Position.Sub(normal.Mul(radius - depth))
+		particleContacts = append(particleContacts, constraint.NewParticleContact(particle, body, point, normal, radius, friction))
+	}
+
+	order := make(map[*actor.Particle]int, len(particles))
+	for i, particle := range particles {
+		order[particle] = i
+	}
+	slices.SortFunc(particleContacts, func(a, b *constraint.ParticleContact) int {
+		return cmp.Or(cmp.Compare(order[a.Particle], order[b.Particle]), cmp.Compare(w.bodyOrder[a.Body], w.bodyOrder[b.Body]))
+	})
+
+	return particleContacts
+}
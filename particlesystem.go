@@ -0,0 +1,155 @@
+package feather
+
+import (
+	"slices"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/akmonengine/feather/dmath"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// LightParticle is a particle of a ParticleSystem: a sphere without mass nor rotation, moved by its velocity
+type LightParticle struct {
+	Position mgl64.Vec3
+	Velocity mgl64.Vec3
+	// Radius (m) kept from the surfaces of the bodies
+	Radius float64
+	// Lifetime (s) left before the particle is removed, 0 keeps it until the system is cleared
+	Lifetime float64
+}
+
+// ParticleSystem holds many lightweight particles (debris, sparks, droplets) colliding with the bodies at a fraction
+// of the cost of rigid bodies: each particle casts a ray along its motion through the Broadphase, so the fast ones
+// do not tunnel, then is kept out of the closest body. The collisions are one way, the bodies are neither moved
+// nor woken up, and the particles do not collide with each other. The particles emit no events.
+type ParticleSystem struct {
+	Particles []LightParticle
+
+	// GravityScale multiplies World.Gravity, e.g. 1 for debris, negative for smoke rising
+	GravityScale float64
+	// LinearDamping slows the particles down exponentially, like Material.LinearDamping
+	LinearDamping float64
+	// Restitution is the part of the normal speed kept by a bounce, in [0, 1]
+	Restitution float64
+	// Friction is the coefficient removing the tangential speed at a bounce or on a resting contact
+	Friction float64
+	// Filter selects the bodies the particles collide with, nil for all of them; the triggers are always ignored
+	Filter QueryFilter
+}
+
+// NewParticleSystem creates an empty system under the gravity, with room for capacity particles
+func NewParticleSystem(capacity int) *ParticleSystem {
+	return &ParticleSystem{
+		Particles:    make([]LightParticle, 0, capacity),
+		GravityScale: 1,
+	}
+}
+
+// Emit adds a particle, living for lifetime seconds (0 for ever)
+func (s *ParticleSystem) Emit(position, velocity mgl64.Vec3, radius, lifetime float64) {
+	s.Particles = append(s.Particles, LightParticle{Position: position, Velocity: velocity, Radius: radius, Lifetime: lifetime})
+}
+
+// Clear removes all the particles, keeping the memory for the next ones
+func (s *ParticleSystem) Clear() {
+	s.Particles = s.Particles[:0]
+}
+
+// AddParticleSystem adds a particle system to the world, stepped at the end of each Step
+func (w *World) AddParticleSystem(system *ParticleSystem) {
+	w.particleSystems = append(w.particleSystems, system)
+}
+
+// RemoveParticleSystem removes a particle system from the world
+func (w *World) RemoveParticleSystem(system *ParticleSystem) {
+	w.particleSystems = slices.DeleteFunc(w.particleSystems, func(other *ParticleSystem) bool {
+		return other == system
+	})
+}
+
+// ParticleSystems returns the particle systems of the world
+func (w *World) ParticleSystems() []*ParticleSystem {
+	return w.particleSystems
+}
+
+// stepParticleSystems moves the particles by dt against the bodies at their new poses, in parallel,
+// then removes the expired ones keeping the order of the others
+func (w *World) stepParticleSystems(dt float64) {
+	for _, system := range w.particleSystems {
+		filter := func(body *actor.RigidBody) bool {
+			return !body.IsTrigger && (system.Filter == nil || system.Filter(body))
+		}
+
+		indices := make([]int, len(system.Particles))
+		for i := range indices {
+			indices[i] = i
+		}
+		task(w.Workers, indices, func(i int) {
+			w.stepParticle(system, &system.Particles[i], dt, filter)
+		})
+
+		system.Particles = slices.DeleteFunc(system.Particles, func(particle LightParticle) bool {
+			return particle.Lifetime < 0
+		})
+	}
+}
+
+// stepParticle integrates the particle, bounces it off the first body along its motion, then pushes it out of
+// the closest one. An expired particle gets a negative Lifetime.
+func (w *World) stepParticle(system *ParticleSystem, particle *LightParticle, dt float64, filter QueryFilter) {
+	if particle.Lifetime > 0 {
+		particle.Lifetime -= dt
+		if particle.Lifetime <= 0 {
+			particle.Lifetime = -1
+			return
+		}
+	}
+
+	particle.Velocity = particle.Velocity.Add(w.Gravity.Mul(system.GravityScale * dt))
+	particle.Velocity = particle.Velocity.Mul(dmath.Exp(-system.LinearDamping * dt))
+
+	// The center is swept along the motion, and stopped a radius before the surface
+	previous := particle.Position
+	motion := particle.Velocity.Mul(dt)
+	distance := motion.Len()
+	if hit, ok := w.Raycast(particle.Position, motion, distance+particle.Radius, filter); ok {
+		particle.Position = particle.Position.Add(motion.Mul(max(0, hit.Distance-particle.Radius) / distance))
+		particle.Velocity = system.bounce(particle.Velocity, hit.Normal, system.Restitution)
+	} else {
+		particle.Position = particle.Position.Add(motion)
+	}
+
+	// Resting or grazing, the particle is kept a radius away from the closest surface
+	_, closest, ok := w.ClosestBody(particle.Position, particle.Radius, filter)
+	if !ok {
+		return
+	}
+	offset := particle.Position.Sub(closest)
+	separation := offset.Len()
+	if separation < 1e-9 {
+		// Inside the body (e.g. it moved onto the particle), the normal is unknown: the particle stays where it was
+		particle.Position = previous
+		particle.Velocity = mgl64.Vec3{}
+		return
+	}
+	normal := offset.Mul(1 / separation)
+	particle.Position = closest.Add(normal.Mul(particle.Radius))
+	particle.Velocity = system.bounce(particle.Velocity, normal, 0)
+}
+
+// bounce returns the velocity after hitting a surface of normal: the normal speed is reversed and scaled
+// by restitution, the tangential speed reduced by Friction times the normal speed change
+func (s *ParticleSystem) bounce(velocity, normal mgl64.Vec3, restitution float64) mgl64.Vec3 {
+	normalSpeed := velocity.Dot(normal)
+	if normalSpeed >= 0 {
+		return velocity
+	}
+
+	tangent := velocity.Sub(normal.Mul(normalSpeed))
+	change := -normalSpeed * (1 + restitution)
+	if tangentSpeed := tangent.Len(); tangentSpeed > 1e-9 {
+		tangent = tangent.Mul(max(0, tangentSpeed-s.Friction*change) / tangentSpeed)
+	}
+
+	return tangent.Add(normal.Mul(-normalSpeed * restitution))
+}
@@ -0,0 +1,114 @@
+package feather
+
+import (
+	"math"
+	"testing"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestParticleSystem_Bounce(t *testing.T) {
+	world := NewWorld()
+	world.AddBody(createPlane(mgl64.Vec3{0, 1, 0}, 0))
+	system := NewParticleSystem(1)
+	system.Restitution = 0.5
+	world.AddParticleSystem(system)
+
+	// Thrown down at 10 m/s from 1 m, the particle bounces back up at half its speed
+	system.Emit(mgl64.Vec3{0, 1, 0}, mgl64.Vec3{0, -10, 0}, 0.01, 0)
+	var bounced bool
+	for range 10 {
+		before := system.Particles[0].Velocity.Y()
+		world.Step(1.0 / 60.0)
+		if after := system.Particles[0].Velocity.Y(); before < 0 && after > 0 {
+			bounced = true
+			if math.Abs(after+0.5*before) > 1 {
+				t.Errorf("bounced at %v from %v, want half the speed", after, before)
+			}
+		}
+	}
+	if !bounced {
+		t.Errorf("particle at %v, want bounced off the plane", system.Particles[0].Position)
+	}
+	if y := system.Particles[0].Position.Y(); y < 0.01-1e-6 {
+		t.Errorf("particle at %v, under the plane", y)
+	}
+}
+
+func TestParticleSystem_NoTunneling(t *testing.T) {
+	world := NewWorld()
+	wall := createBox(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{0.01, 1, 1}, actor.BodyTypeStatic)
+	world.AddBody(wall)
+	system := NewParticleSystem(1)
+	system.GravityScale = 0
+	world.AddParticleSystem(system)
+
+	// At 300 m/s, the spark moves 5 m per step through a 2 cm wall
+	system.Emit(mgl64.Vec3{-2, 0, 0}, mgl64.Vec3{300, 0, 0}, 0.005, 0)
+	for range 5 {
+		world.Step(1.0 / 60.0)
+	}
+
+	particle := system.Particles[0]
+	if particle.Position.X() > -0.01 {
+		t.Errorf("particle at %v, through the wall", particle.Position)
+	}
+	if particle.Velocity.X() > 0 {
+		t.Errorf("particle velocity %v, want stopped by the wall", particle.Velocity)
+	}
+}
+
+func TestParticleSystem_Resting(t *testing.T) {
+	world := NewWorld()
+	crate := createBox(mgl64.Vec3{0, 0.5, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+	crate.Sleep()
+	world.AddBody(crate)
+	world.AddBody(createPlane(mgl64.Vec3{0, 1, 0}, 0))
+	system := NewParticleSystem(1)
+	system.Friction = 0.5
+	world.AddParticleSystem(system)
+
+	// Landing while sliding on the sleeping crate, the particle stops on it without waking it up
+	system.Emit(mgl64.Vec3{-0.3, 1.2, 0}, mgl64.Vec3{1, 0, 0}, 0.02, 0)
+	for range 120 {
+		world.Step(1.0 / 60.0)
+	}
+
+	particle := system.Particles[0]
+	if math.Abs(particle.Position.Y()-1.02) > 1e-3 || particle.Position.X() > 0.5 {
+		t.Errorf("particle at %v, want resting on the crate", particle.Position)
+	}
+	if particle.Velocity.Len() > 0.2 {
+		t.Errorf("particle moving at %v, want stopped by the friction", particle.Velocity)
+	}
+	if !crate.IsSleeping {
+		t.Errorf("crate woken up by the particle")
+	}
+}
+
+func TestParticleSystem_Lifetime(t *testing.T) {
+	world := NewWorld()
+	system := NewParticleSystem(3)
+	world.AddParticleSystem(system)
+
+	system.Emit(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{}, 0.01, 0.05)
+	system.Emit(mgl64.Vec3{1, 0, 0}, mgl64.Vec3{}, 0.01, 0)
+	system.Emit(mgl64.Vec3{2, 0, 0}, mgl64.Vec3{}, 0.01, 0.5)
+	for range 6 {
+		world.Step(1.0 / 60.0)
+	}
+
+	// The expired particle is removed, the others keep their order
+	if len(system.Particles) != 2 || system.Particles[0].Position.X() != 1 || system.Particles[1].Position.X() != 2 {
+		t.Fatalf("particles %v, want the two living ones in order", system.Particles)
+	}
+	if math.Abs(system.Particles[1].Lifetime-(0.5-0.1)) > 1e-9 {
+		t.Errorf("lifetime = %v, want 0.4 left", system.Particles[1].Lifetime)
+	}
+
+	world.RemoveParticleSystem(system)
+	if len(world.ParticleSystems()) != 0 {
+		t.Errorf("%d particle systems left", len(world.ParticleSystems()))
+	}
+}
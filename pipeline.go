@@ -1,6 +1,9 @@
 package feather
 
-import "sync"
+import (
+	"sync"
+	"sync/atomic"
+)
 
 func task[T any](workersCount int, data []T, fn func(data T)) {
 	var wg sync.WaitGroup
@@ -18,3 +21,29 @@ func task[T any](workersCount int, data []T, fn func(data T)) {
 	}
 	wg.Wait()
 }
+
+// balancedTask calls fn on each item, the workers take the next item once done: the items may be
+// of very different costs (e.g. a large pile among single bodies), chunks would leave workers idle.
+// The items are processed inline by a single worker.
+func balancedTask[T any](workersCount int, data []T, fn func(data T)) {
+	workersCount = min(workersCount, len(data))
+	if workersCount <= 1 {
+		for _, item := range data {
+			fn(item)
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	var next atomic.Int64
+	for range workersCount {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := int(next.Add(1) - 1); i < len(data); i = int(next.Add(1) - 1) {
+				fn(data[i])
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,554 @@
+package feather
+
+import (
+	"math"
+	"sort"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/akmonengine/feather/gjk"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// QueryFilter selects the bodies considered by spatial queries.
+// A nil filter accepts every body, the disabled bodies are never considered.
+type QueryFilter func(body *actor.RigidBody) bool
+
+func (f QueryFilter) accept(body *actor.RigidBody) bool {
+	return f == nil || f(body)
+}
+
+// ClosestBody returns the body nearest to point within maxDist, and the closest point on its shape.
+// Points inside a shape are at distance 0 from it.
+//
+// The SpatialGrid is walked in rings of cells around the point (each level of a hierarchical grid in turn),
+// stopping as soon as no unvisited cell can hold a closer body. When the rings would cover more cells
+// than the grid holds (e.g. maxDist is infinite), or when the last Step used the brute force broad phase,
+// all the bodies are scanned instead. A World.Broadphase is queried with the region within maxDist.
+// The index reflects the positions of the bodies as of the last Step. After an AddBody or a RemoveBody,
+// all the bodies are tested until the next Step.
+//
+// Returns false if no body accepted by the filter lies within maxDist.
+func (w *World) ClosestBody(point mgl64.Vec3, maxDist float64, filter QueryFilter) (*actor.RigidBody, mgl64.Vec3, bool) {
+	var closestBody *actor.RigidBody
+	var closestPoint mgl64.Vec3
+	closestDistSq := maxDist * maxDist
+
+	test := func(bodyIndex int) {
+		if bodyIndex >= len(w.Bodies) {
+			return
+		}
+		body := w.Bodies[bodyIndex]
+		if !body.IsEnabled() || !filter.accept(body) {
+			return
+		}
+
+		candidate := body.ClosestPointWorld(point)
+		if distSq := candidate.Sub(point).LenSqr(); distSq <= closestDistSq {
+			closestBody = body
+			closestPoint = candidate
+			closestDistSq = distSq
+		}
+	}
+
+	grid, isGrid := w.indexedBroadphase().(*SpatialGrid)
+	if !isGrid || grid == nil {
+		// Other indexes are queried with the region within maxDist
+		region := actor.AABB{Min: point.Sub(mgl64.Vec3{maxDist, maxDist, maxDist}), Max: point.Add(mgl64.Vec3{maxDist, maxDist, maxDist})}
+		w.forEachInRegion(region, test)
+
+		return closestBody, closestPoint, closestBody != nil
+	}
+
+	maxRing := math.Ceil(maxDist/grid.cellSize) + 1
+	if side := 2*maxRing + 1; side*side*side > float64(len(grid.cells)) {
+		for i := range w.Bodies {
+			test(i)
+		}
+	} else {
+		for _, planeIndex := range grid.planes.bodyIndices {
+			test(planeIndex)
+		}
+
+		// Each level is walked until its unvisited cells are farther than the closest body found so far
+		for level := grid; level != nil; level = level.coarser {
+			center := level.worldToCell(point)
+			levelRings := math.Ceil(maxDist/level.cellSize) + 1
+			for ring := 0; ring <= int(levelRings); ring++ {
+				level.forEachInRing(center, ring, test)
+
+				// Cells beyond this ring are at least ring*cellSize away from the point
+				reach := float64(ring) * level.cellSize
+				if closestBody != nil && closestDistSq <= reach*reach {
+					break
+				}
+			}
+		}
+	}
+
+	return closestBody, closestPoint, closestBody != nil
+}
+
+// RaycastHit describes the first body hit by a ray
+type RaycastHit struct {
+	Body *actor.RigidBody
+	// Point is the world-space point hit on the surface of the body
+	Point mgl64.Vec3
+	// Normal is the world-space surface normal at Point
+	Normal mgl64.Vec3
+	// Distance from the origin to Point
+	Distance float64
+	// Fraction is Distance divided by maxDist, in [0, 1] (0 for an infinite maxDist)
+	Fraction float64
+}
+
+// Ray is a query of RaycastMany
+type Ray struct {
+	Origin    mgl64.Vec3
+	Direction mgl64.Vec3
+	MaxDist   float64
+}
+
+// Raycast returns the first body hit by the ray from origin along direction, within maxDist.
+// Rays starting inside a body hit it at distance 0, with the normal opposed to the direction.
+//
+// The SpatialGrid cells crossed by the ray are walked in order (3D DDA), stopping as soon as the next cell
+// is beyond the nearest hit. Planes and triangle meshes are always tested. When the ray would cross more
+// cells than the grid holds (e.g. maxDist is infinite), or when the last Step used the brute force broad phase,
+// all the bodies are tested instead. A World.Broadphase is walked along the ray by its own index.
+// The index reflects the positions of the bodies as of the last Step. After an AddBody or a RemoveBody,
+// all the bodies are tested until the next Step.
+//
+// Returns false if no body accepted by the filter is hit, or if direction is zero.
+func (w *World) Raycast(origin, direction mgl64.Vec3, maxDist float64, filter QueryFilter) (RaycastHit, bool) {
+	var hit RaycastHit
+	best := maxDist
+
+	w.castRay(origin, direction, maxDist, filter, func() float64 { return best }, func(result RaycastHit) {
+		hit, best = result, result.Distance
+	})
+
+	if hit.Body == nil {
+		return hit, false
+	}
+	hit.Fraction = rayFraction(hit.Distance, maxDist)
+
+	return hit, true
+}
+
+// RaycastAll returns every body hit by the ray within maxDist, sorted by distance, see Raycast.
+// Each body is reported once, at the point where the ray enters it.
+func (w *World) RaycastAll(origin, direction mgl64.Vec3, maxDist float64, filter QueryFilter) []RaycastHit {
+	var hits []RaycastHit
+	reported := make(map[*actor.RigidBody]struct{})
+
+	w.castRay(origin, direction, maxDist, filter, func() float64 { return maxDist }, func(result RaycastHit) {
+		if _, ok := reported[result.Body]; ok {
+			return
+		}
+		reported[result.Body] = struct{}{}
+		result.Fraction = rayFraction(result.Distance, maxDist)
+		hits = append(hits, result)
+	})
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Distance < hits[j].Distance
+	})
+
+	return hits
+}
+
+// RaycastMany casts the rays in parallel on World.Workers goroutines, for sensors and line of sight checks
+// casting many rays per frame. The hit of rays[i] is at index i, with a nil Body if the ray hit nothing.
+// The filter is called concurrently.
+func (w *World) RaycastMany(rays []Ray, filter QueryFilter) []RaycastHit {
+	hits := make([]RaycastHit, len(rays))
+	indices := make([]int, len(rays))
+	for i := range indices {
+		indices[i] = i
+	}
+
+	task(max(DEFAULT_WORKERS, w.Workers), indices, func(i int) {
+		hits[i], _ = w.Raycast(rays[i].Origin, rays[i].Direction, rays[i].MaxDist, filter)
+	})
+
+	return hits
+}
+
+// castRay calls onHit for the bodies accepted by the filter and hit by the ray within reach(),
+// walking the Broadphase as described in Raycast. reach is queried before each body and cell, it may only shrink.
+// A body spanning several cells can be reported several times.
+func (w *World) castRay(origin, direction mgl64.Vec3, maxDist float64, filter QueryFilter, reach func() float64, onHit func(hit RaycastHit)) {
+	length := direction.Len()
+	if length < 1e-12 {
+		return
+	}
+	direction = direction.Mul(1 / length)
+
+	test := func(bodyIndex int) {
+		if bodyIndex >= len(w.Bodies) {
+			return
+		}
+		body := w.Bodies[bodyIndex]
+		if !body.IsEnabled() || !filter.accept(body) {
+			return
+		}
+		if _, isPlane := body.Shape.(*actor.Plane); !isPlane {
+			if _, ok := body.Shape.GetAABB().RayHit(origin, direction, reach()); !ok {
+				return
+			}
+		}
+
+		if distance, normal, ok := body.RaycastWorld(origin, direction, reach()); ok {
+			onHit(RaycastHit{Body: body, Point: origin.Add(direction.Mul(distance)), Normal: normal, Distance: distance})
+		}
+	}
+
+	if broadphase := w.indexedBroadphase(); broadphase != nil {
+		if broadphase.QueryRay(origin, direction, maxDist, reach, test) {
+			return
+		}
+	}
+
+	for i := range w.Bodies {
+		test(i)
+	}
+}
+
+// rayFraction returns the fraction of maxDist at distance, 0 for an infinite maxDist
+func rayFraction(distance, maxDist float64) float64 {
+	if math.IsInf(maxDist, 1) || maxDist <= 0 {
+		return 0
+	}
+
+	return distance / maxDist
+}
+
+// ShapeCastHit describes the first body hit by a swept shape
+type ShapeCastHit struct {
+	Body *actor.RigidBody
+	// Point is the world-space contact point on the body hit, at the time of impact
+	Point mgl64.Vec3
+	// Normal is the world-space surface normal of the body hit at Point, pointing toward the swept shape
+	Normal mgl64.Vec3
+	// Position of the swept shape at the time of impact
+	Position mgl64.Vec3
+	// Fraction of the sweep from -> to travelled at the time of impact, in [0, 1]
+	Fraction float64
+}
+
+// ShapeCast sweeps the convex shape, or compound of convex shapes, with the given rotation from from to to,
+// and returns the first body it hits (character controllers, projectiles with a volume, placement previews).
+// Bodies overlapping the shape at from are hit with a Fraction of 0, and the normal opposed to the sweep
+// (the plane normal for planes).
+//
+// The bodies in the AABB of the whole sweep are the candidates, found as described in QueryAABB.
+// Each candidate is swept with the GJK ray cast, see gjk.Cast. Planes are swept analytically, meshes per triangle.
+//
+// The shape is not added to the world, nor modified: it may be shared by the bodies of the world.
+// Use the filter to skip the body being moved.
+//
+// Returns false if no body accepted by the filter is hit.
+func (w *World) ShapeCast(shape actor.ShapeInterface, from, to mgl64.Vec3, rotation mgl64.Quat, filter QueryFilter) (ShapeCastHit, bool) {
+	translation := to.Sub(from)
+	caster := &actor.RigidBody{
+		Transform: actor.Transform{Position: from, Rotation: rotation, InverseRotation: rotation.Inverse()},
+		Shape:     shape,
+	}
+
+	start := supportAABB(caster)
+	swept := actor.AABB{
+		Min: mgl64.Vec3{
+			math.Min(start.Min.X(), start.Min.X()+translation.X()),
+			math.Min(start.Min.Y(), start.Min.Y()+translation.Y()),
+			math.Min(start.Min.Z(), start.Min.Z()+translation.Z()),
+		},
+		Max: mgl64.Vec3{
+			math.Max(start.Max.X(), start.Max.X()+translation.X()),
+			math.Max(start.Max.Y(), start.Max.Y()+translation.Y()),
+			math.Max(start.Max.Z(), start.Max.Z()+translation.Z()),
+		},
+	}
+
+	casters := bodyParts(caster, nil)
+
+	var hit ShapeCastHit
+	var triangles []int
+	var targets []*actor.RigidBody
+
+	keep := func(body *actor.RigidBody, report gjk.CastReport) {
+		if hit.Body == nil || report.Fraction < hit.Fraction {
+			hit = ShapeCastHit{Body: body, Point: report.Point, Normal: report.Normal, Fraction: report.Fraction}
+		}
+	}
+
+	w.forEachInRegion(swept, func(bodyIndex int) {
+		body := w.Bodies[bodyIndex]
+		if !body.IsEnabled() || !filter.accept(body) {
+			return
+		}
+
+		// SupportWorld uses the inverse rotation, static bodies never integrate to update it
+		targetTransform := body.Transform
+		targetTransform.InverseRotation = targetTransform.Rotation.Inverse()
+
+		switch target := body.Shape.(type) {
+		case *actor.Plane:
+			for _, caster := range casters {
+				if report, ok := castPlane(caster, target, translation); ok {
+					keep(body, report)
+				}
+			}
+		case *actor.TriangleMesh:
+			triangles = target.Overlapping(swept, targetTransform, triangles[:0])
+			for _, index := range triangles {
+				triangle := &actor.RigidBody{Transform: targetTransform, Shape: target.Triangle(index)}
+				for _, caster := range casters {
+					if report, ok := gjk.Cast(caster, triangle, translation); ok {
+						keep(body, report)
+					}
+				}
+			}
+		default:
+			if !target.GetAABB().Overlaps(swept) {
+				return
+			}
+
+			targets = bodyParts(&actor.RigidBody{Transform: targetTransform, Shape: target}, targets[:0])
+			for _, part := range targets {
+				for _, caster := range casters {
+					if report, ok := gjk.Cast(caster, part, translation); ok {
+						keep(body, report)
+					}
+				}
+			}
+		}
+	})
+
+	if hit.Body == nil {
+		return hit, false
+	}
+	hit.Position = from.Add(translation.Mul(hit.Fraction))
+
+	return hit, true
+}
+
+// supportAABB returns the AABB of the convex body, or compound of convex shapes, from its support points along the axes.
+// Unlike ComputeAABB, the shape is left untouched: the queries do not move the AABB of a body sharing it.
+func supportAABB(body *actor.RigidBody) actor.AABB {
+	var bounds actor.AABB
+	for i := range 3 {
+		var axis mgl64.Vec3
+		axis[i] = 1
+		bounds.Max[i] = body.SupportWorld(axis)[i]
+		bounds.Min[i] = body.SupportWorld(axis.Mul(-1))[i]
+	}
+
+	return bounds
+}
+
+// castPlane sweeps the convex caster along translation against the half-space below the plane,
+// the deepest point of the caster is the first to cross it
+func castPlane(caster *actor.RigidBody, plane *actor.Plane, translation mgl64.Vec3) (gjk.CastReport, bool) {
+	deepest := caster.SupportWorld(plane.Normal.Mul(-1))
+	distance := plane.Normal.Dot(deepest) + plane.Distance
+
+	report := gjk.CastReport{Point: deepest, Normal: plane.Normal}
+	if distance <= 0 {
+		return report, true
+	}
+
+	approach := plane.Normal.Dot(translation)
+	if approach >= 0 || distance > -approach {
+		return report, false
+	}
+
+	report.Fraction = distance / -approach
+	report.Point = deepest.Add(translation.Mul(report.Fraction))
+
+	return report, true
+}
+
+// QueryAABB returns the bodies whose shape overlaps the region, in world order: their AABB for the convex shapes
+// and compounds, the half-space for the planes, and the bounds of the triangles for the meshes.
+//
+// The Broadphase (the SpatialGrid cells overlapped by the region) gives the candidates, planes and triangle meshes
+// are always tested. When the index cannot answer, e.g. the region overlaps more cells than the grid holds, or when
+// the last Step used the brute force broad phase, all the bodies are tested instead. The index reflects the positions
+// of the bodies as of the last Step. After an AddBody or a RemoveBody, all the bodies are tested until the next Step.
+func (w *World) QueryAABB(region actor.AABB, filter QueryFilter) []*actor.RigidBody {
+	var found []int
+	var triangles []int
+
+	w.forEachInRegion(region, func(bodyIndex int) {
+		body := w.Bodies[bodyIndex]
+		if !body.IsEnabled() || !filter.accept(body) {
+			return
+		}
+
+		switch target := body.Shape.(type) {
+		case *actor.Plane:
+			// The corner of the region furthest below the plane
+			var corner mgl64.Vec3
+			for i := range 3 {
+				corner[i] = region.Max[i]
+				if target.Normal[i] >= 0 {
+					corner[i] = region.Min[i]
+				}
+			}
+			if target.Normal.Dot(corner)+target.Distance > 0 {
+				return
+			}
+		case *actor.TriangleMesh:
+			if triangles = target.Overlapping(region, body.Transform, triangles[:0]); len(triangles) == 0 {
+				return
+			}
+		default:
+			if !target.GetAABB().Overlaps(region) {
+				return
+			}
+		}
+		found = append(found, bodyIndex)
+	})
+
+	return w.bodiesInOrder(found)
+}
+
+// OverlapShape returns the bodies intersecting the convex shape, or compound of convex shapes, placed at transform,
+// in world order (spawn checks, explosions, melee hits). The candidates are the bodies in the AABB of the shape,
+// see QueryAABB, each one is confirmed by GJK. Meshes are tested per triangle, planes by the deepest point of the shape.
+//
+// The shape is not added to the world, nor modified: it may be shared by the bodies of the world.
+func (w *World) OverlapShape(shape actor.ShapeInterface, transform actor.Transform, filter QueryFilter) []*actor.RigidBody {
+	transform.InverseRotation = transform.Rotation.Inverse()
+	query := &actor.RigidBody{Transform: transform, Shape: shape}
+	bounds := supportAABB(query)
+
+	parts := bodyParts(query, nil)
+
+	var found []int
+	var triangles []int
+	var targets []*actor.RigidBody
+	simplex := &gjk.Simplex{}
+
+	overlaps := func(target *actor.RigidBody) bool {
+		for _, part := range parts {
+			simplex.Reset()
+			if gjk.GJK(part, target, simplex) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	w.forEachInRegion(bounds, func(bodyIndex int) {
+		body := w.Bodies[bodyIndex]
+		if !body.IsEnabled() || !filter.accept(body) {
+			return
+		}
+
+		// SupportWorld uses the inverse rotation, static bodies never integrate to update it
+		targetTransform := body.Transform
+		targetTransform.InverseRotation = targetTransform.Rotation.Inverse()
+
+		switch target := body.Shape.(type) {
+		case *actor.Plane:
+			for _, part := range parts {
+				deepest := part.SupportWorld(target.Normal.Mul(-1))
+				if target.Normal.Dot(deepest)+target.Distance <= 0 {
+					found = append(found, bodyIndex)
+					return
+				}
+			}
+		case *actor.TriangleMesh:
+			triangles = target.Overlapping(bounds, targetTransform, triangles[:0])
+			for _, index := range triangles {
+				if overlaps(&actor.RigidBody{Transform: targetTransform, Shape: target.Triangle(index)}) {
+					found = append(found, bodyIndex)
+					return
+				}
+			}
+		default:
+			if !target.GetAABB().Overlaps(bounds) {
+				return
+			}
+
+			targets = bodyParts(&actor.RigidBody{Transform: targetTransform, Shape: target}, targets[:0])
+			for _, part := range targets {
+				if overlaps(part) {
+					found = append(found, bodyIndex)
+					return
+				}
+			}
+		}
+	})
+
+	return w.bodiesInOrder(found)
+}
+
+// QueryPoint returns the bodies whose shape contains the point, in world order (picking, "which room am I in").
+// Planes contain the points below them, triangle meshes contain no point, see actor.ShapeInterface.ContainsPoint.
+// The candidates are found as described in QueryAABB.
+func (w *World) QueryPoint(point mgl64.Vec3, filter QueryFilter) []*actor.RigidBody {
+	var found []int
+
+	w.forEachInRegion(actor.AABB{Min: point, Max: point}, func(bodyIndex int) {
+		body := w.Bodies[bodyIndex]
+		if !body.IsEnabled() || !filter.accept(body) {
+			return
+		}
+		if _, isPlane := body.Shape.(*actor.Plane); !isPlane && !body.Shape.GetAABB().ContainsPoint(point) {
+			return
+		}
+
+		if body.ContainsPointWorld(point) {
+			found = append(found, bodyIndex)
+		}
+	})
+
+	return w.bodiesInOrder(found)
+}
+
+// forEachInRegion calls fn once for each body index that may overlap the region: the bodies stored in the
+// Broadphase (the SpatialGrid cells overlapped by the region), the planes and the triangle meshes. When the index
+// cannot answer or is outdated, see indexedBroadphase, fn is called for all the bodies.
+func (w *World) forEachInRegion(region actor.AABB, fn func(bodyIndex int)) {
+	broadphase := w.indexedBroadphase()
+	if broadphase == nil {
+		for i := range w.Bodies {
+			fn(i)
+		}
+		return
+	}
+
+	visited := make(map[int]struct{})
+	visit := func(bodyIndex int) {
+		if bodyIndex >= len(w.Bodies) {
+			return
+		}
+		if _, ok := visited[bodyIndex]; ok {
+			return
+		}
+		visited[bodyIndex] = struct{}{}
+		fn(bodyIndex)
+	}
+
+	if !broadphase.QueryRegion(region, visit) {
+		clear(visited)
+		for i := range w.Bodies {
+			visit(i)
+		}
+	}
+}
+
+// bodiesInOrder returns the bodies at the indices, sorted by index
+func (w *World) bodiesInOrder(indices []int) []*actor.RigidBody {
+	sort.Ints(indices)
+
+	bodies := make([]*actor.RigidBody, len(indices))
+	for i, index := range indices {
+		bodies[i] = w.Bodies[index]
+	}
+
+	return bodies
+}
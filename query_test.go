@@ -0,0 +1,673 @@
+package feather
+
+import (
+	"math"
+	"testing"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// createQueryWorld creates a world without gravity, stepped once so the SpatialGrid is populated
+func createQueryWorld(bodies ...*actor.RigidBody) *World {
+	world := &World{
+		Substeps:            1,
+		SpatialGrid:         NewSpatialGrid(1.0, 1024),
+		Workers:             1,
+		BruteForceThreshold: -1,
+		Events:              NewEvents(),
+	}
+	for _, body := range bodies {
+		world.AddBody(body)
+	}
+	world.Step(1.0 / 60.0)
+
+	return world
+}
+
+func TestClosestBody(t *testing.T) {
+	near := createSphere(mgl64.Vec3{3, 0, 0}, 0.5, actor.BodyTypeStatic)
+	far := createBox(mgl64.Vec3{-8, 0, 0}, mgl64.Vec3{1, 1, 1}, actor.BodyTypeStatic)
+	world := createQueryWorld(near, far)
+
+	body, point, found := world.ClosestBody(mgl64.Vec3{0, 0, 0}, 20, nil)
+	if !found {
+		t.Fatal("expected a body to be found")
+	}
+	if body != near {
+		t.Errorf("expected the sphere to be the closest body")
+	}
+	if !vec3ApproxEqual(point, mgl64.Vec3{2.5, 0, 0}, 1e-9) {
+		t.Errorf("closest point = %v, want {2.5 0 0}", point)
+	}
+}
+
+func TestClosestBody_MaxDistance(t *testing.T) {
+	world := createQueryWorld(createSphere(mgl64.Vec3{10, 0, 0}, 1, actor.BodyTypeStatic))
+
+	if _, _, found := world.ClosestBody(mgl64.Vec3{0, 0, 0}, 5, nil); found {
+		t.Error("expected no body within 5 units")
+	}
+	if _, _, found := world.ClosestBody(mgl64.Vec3{0, 0, 0}, 9.5, nil); !found {
+		t.Error("expected the sphere within 9.5 units")
+	}
+}
+
+func TestClosestBody_Filter(t *testing.T) {
+	near := createSphere(mgl64.Vec3{2, 0, 0}, 0.5, actor.BodyTypeStatic)
+	far := createSphere(mgl64.Vec3{0, 6, 0}, 0.5, actor.BodyTypeStatic)
+	world := createQueryWorld(near, far)
+
+	body, _, found := world.ClosestBody(mgl64.Vec3{0, 0, 0}, 20, func(body *actor.RigidBody) bool {
+		return body != near
+	})
+	if !found || body != far {
+		t.Errorf("expected the filter to skip the nearest sphere")
+	}
+}
+
+func TestClosestBody_InsideShape(t *testing.T) {
+	box := createBox(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{2, 2, 2}, actor.BodyTypeStatic)
+	world := createQueryWorld(box, createSphere(mgl64.Vec3{0.5, 0, 0}, 0.1, actor.BodyTypeStatic))
+
+	body, point, found := world.ClosestBody(mgl64.Vec3{-1, 0, 0}, 1, nil)
+	if !found || body != box {
+		t.Fatal("expected the enclosing box")
+	}
+	if !vec3ApproxEqual(point, mgl64.Vec3{-1, 0, 0}, 1e-9) {
+		t.Errorf("point inside a shape should be returned unchanged, got %v", point)
+	}
+}
+
+func TestClosestBody_Plane(t *testing.T) {
+	world := createQueryWorld(createPlane(mgl64.Vec3{0, 1, 0}, 0))
+
+	body, point, found := world.ClosestBody(mgl64.Vec3{4, 3, -2}, 5, nil)
+	if !found || body == nil {
+		t.Fatal("expected the plane to be found")
+	}
+	if !vec3ApproxEqual(point, mgl64.Vec3{4, 0, -2}, 1e-9) {
+		t.Errorf("closest point = %v, want {4 0 -2}", point)
+	}
+}
+
+func TestClosestBody_InfiniteDistance(t *testing.T) {
+	sphere := createSphere(mgl64.Vec3{500, 0, 0}, 1, actor.BodyTypeStatic)
+	world := createQueryWorld(sphere)
+
+	body, _, found := world.ClosestBody(mgl64.Vec3{0, 0, 0}, math.Inf(1), nil)
+	if !found || body != sphere {
+		t.Error("expected the far sphere with an infinite search distance")
+	}
+}
+
+func TestClosestBody_BruteForceWorld(t *testing.T) {
+	near := createSphere(mgl64.Vec3{0, 0, 3}, 0.5, actor.BodyTypeStatic)
+	world := &World{
+		Substeps:    1,
+		SpatialGrid: NewSpatialGrid(1.0, 1024),
+		Events:      NewEvents(),
+	}
+	world.AddBody(near)
+	world.AddBody(createSphere(mgl64.Vec3{0, 0, -5}, 0.5, actor.BodyTypeStatic))
+	world.Step(1.0 / 60.0)
+
+	body, _, found := world.ClosestBody(mgl64.Vec3{}, 10, nil)
+	if !found || body != near {
+		t.Error("expected the nearest sphere when the grid is skipped")
+	}
+}
+
+func vec3ApproxEqual(a, b mgl64.Vec3, tolerance float64) bool {
+	return math.Abs(a.X()-b.X()) < tolerance &&
+		math.Abs(a.Y()-b.Y()) < tolerance &&
+		math.Abs(a.Z()-b.Z()) < tolerance
+}
+
+func TestRaycast(t *testing.T) {
+	sphere := createSphere(mgl64.Vec3{5, 0, 0}, 1, actor.BodyTypeStatic)
+	box := createBox(mgl64.Vec3{10, 0, 0}, mgl64.Vec3{1, 1, 1}, actor.BodyTypeStatic)
+	world := createQueryWorld(sphere, box)
+
+	hit, found := world.Raycast(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{2, 0, 0}, 20, nil)
+	if !found || hit.Body != sphere {
+		t.Fatalf("expected the sphere to be hit first, got %+v", hit)
+	}
+	if !vec3ApproxEqual(hit.Point, mgl64.Vec3{4, 0, 0}, 1e-9) || !vec3ApproxEqual(hit.Normal, mgl64.Vec3{-1, 0, 0}, 1e-9) {
+		t.Errorf("hit at %v with normal %v, want {4 0 0} facing -X", hit.Point, hit.Normal)
+	}
+	if math.Abs(hit.Distance-4) > 1e-9 || math.Abs(hit.Fraction-0.2) > 1e-9 {
+		t.Errorf("distance = %v, fraction = %v, want 4 and 0.2", hit.Distance, hit.Fraction)
+	}
+
+	if _, found := world.Raycast(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{1, 0, 0}, 3.5, nil); found {
+		t.Error("expected no hit within 3.5 units")
+	}
+	if _, found := world.Raycast(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{}, 20, nil); found {
+		t.Error("expected no hit with a zero direction")
+	}
+
+	hit, found = world.Raycast(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{1, 0, 0}, 20, func(body *actor.RigidBody) bool {
+		return body != sphere
+	})
+	if !found || hit.Body != box || math.Abs(hit.Distance-9) > 1e-9 {
+		t.Errorf("expected the filter to skip the sphere and hit the box at 9, got %+v", hit)
+	}
+}
+
+func TestRaycast_InsideShape(t *testing.T) {
+	box := createBox(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{2, 2, 2}, actor.BodyTypeStatic)
+	world := createQueryWorld(box)
+
+	hit, found := world.Raycast(mgl64.Vec3{0.5, 0, 0}, mgl64.Vec3{0, 1, 0}, 10, nil)
+	if !found || hit.Body != box || hit.Distance != 0 {
+		t.Fatalf("expected the enclosing box at distance 0, got %+v", hit)
+	}
+	if !vec3ApproxEqual(hit.Normal, mgl64.Vec3{0, -1, 0}, 1e-9) {
+		t.Errorf("normal = %v, want opposed to the direction", hit.Normal)
+	}
+}
+
+func TestRaycast_PlaneInfiniteDistance(t *testing.T) {
+	world := createQueryWorld(createPlane(mgl64.Vec3{0, 1, 0}, 0), createSphere(mgl64.Vec3{30, 5, 0}, 1, actor.BodyTypeStatic))
+
+	hit, found := world.Raycast(mgl64.Vec3{0, 10, 0}, mgl64.Vec3{1, -1, 0}, math.Inf(1), nil)
+	if !found || !vec3ApproxEqual(hit.Point, mgl64.Vec3{10, 0, 0}, 1e-9) {
+		t.Fatalf("expected the plane at {10 0 0}, got %+v", hit)
+	}
+	if hit.Fraction != 0 {
+		t.Errorf("fraction = %v, want 0 for an infinite distance", hit.Fraction)
+	}
+}
+
+// TestRaycast_GridMatchesBruteForce casts rays in every direction through a field of bodies,
+// the cells walked along the ray find the same hit as testing every body
+func TestRaycast_GridMatchesBruteForce(t *testing.T) {
+	var bodies []*actor.RigidBody
+	for x := -3; x <= 3; x++ {
+		for y := -3; y <= 3; y++ {
+			for z := -3; z <= 3; z++ {
+				if (x+y+z)%2 != 0 || (x == 0 && y == 0 && z == 0) {
+					continue
+				}
+				position := mgl64.Vec3{float64(x) * 1.7, float64(y) * 1.7, float64(z) * 1.7}
+				if (x+y)%2 == 0 {
+					bodies = append(bodies, createSphere(position, 0.4, actor.BodyTypeStatic))
+				} else {
+					bodies = append(bodies, createBox(position, mgl64.Vec3{0.3, 0.5, 0.4}, actor.BodyTypeStatic))
+				}
+			}
+		}
+	}
+	world := createQueryWorld(bodies...)
+
+	hits := 0
+	for i := 0; i < 200; i++ {
+		// Spread the directions over the sphere (golden spiral)
+		y := 1 - 2*(float64(i)+0.5)/200
+		radius := math.Sqrt(1 - y*y)
+		angle := float64(i) * math.Pi * (3 - math.Sqrt(5))
+		direction := mgl64.Vec3{math.Cos(angle) * radius, y, math.Sin(angle) * radius}
+		origin := mgl64.Vec3{0.1, -0.2, 0.3}
+
+		hit, found := world.Raycast(origin, direction, 15, nil)
+
+		var expected *actor.RigidBody
+		best := 15.0
+		for _, body := range bodies {
+			if distance, _, ok := body.RaycastWorld(origin, direction, best); ok {
+				expected, best = body, distance
+			}
+		}
+
+		if found != (expected != nil) || (found && math.Abs(hit.Distance-best) > 1e-9) {
+			t.Fatalf("direction %v: grid hit %v at %v, brute force hit %v at %v", direction, found, hit.Distance, expected != nil, best)
+		}
+		if found {
+			hits++
+		}
+	}
+	if hits < 50 {
+		t.Errorf("only %d rays hit a body, the field is too sparse to test the traversal", hits)
+	}
+}
+
+func TestRaycastAll(t *testing.T) {
+	wall := createBox(mgl64.Vec3{8, 0, 0}, mgl64.Vec3{0.5, 4, 4}, actor.BodyTypeStatic)
+	first := createSphere(mgl64.Vec3{2, 0, 0}, 0.5, actor.BodyTypeStatic)
+	second := createSphere(mgl64.Vec3{5, 0, 0}, 0.5, actor.BodyTypeStatic)
+	beside := createSphere(mgl64.Vec3{5, 3, 0}, 0.5, actor.BodyTypeStatic)
+	world := createQueryWorld(wall, second, beside, first)
+
+	hits := world.RaycastAll(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{1, 0, 0}, 20, nil)
+	expected := []*actor.RigidBody{first, second, wall}
+	if len(hits) != len(expected) {
+		t.Fatalf("hits = %d, want %d: the wall spanning many cells is reported once", len(hits), len(expected))
+	}
+	for i, hit := range hits {
+		if hit.Body != expected[i] {
+			t.Errorf("hit %d at %v, want the bodies sorted by distance", i, hit.Distance)
+		}
+	}
+	if math.Abs(hits[2].Distance-7.5) > 1e-9 || math.Abs(hits[2].Fraction-7.5/20) > 1e-9 {
+		t.Errorf("wall hit at %v (fraction %v), want 7.5", hits[2].Distance, hits[2].Fraction)
+	}
+
+	if hits := world.RaycastAll(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{1, 0, 0}, 4.6, nil); len(hits) != 2 {
+		t.Errorf("hits = %d within 4.6, want 2", len(hits))
+	}
+}
+
+func TestRaycastMany(t *testing.T) {
+	var bodies []*actor.RigidBody
+	for i := range 16 {
+		angle := float64(i) * math.Pi / 8
+		bodies = append(bodies, createSphere(mgl64.Vec3{math.Cos(angle) * 6, 0, math.Sin(angle) * 6}, 0.8, actor.BodyTypeStatic))
+	}
+	world := createQueryWorld(bodies...)
+	world.Workers = 4
+
+	// A lidar sweep around the origin, a ray every 2 degrees
+	rays := make([]Ray, 180)
+	for i := range rays {
+		angle := float64(i) * math.Pi / 90
+		rays[i] = Ray{Direction: mgl64.Vec3{math.Cos(angle), 0, math.Sin(angle)}, MaxDist: 10}
+	}
+	rays = append(rays, Ray{Direction: mgl64.Vec3{0, 1, 0}, MaxDist: 10})
+
+	hits := world.RaycastMany(rays, nil)
+	if len(hits) != len(rays) {
+		t.Fatalf("hits = %d, want one per ray", len(hits))
+	}
+	for i, ray := range rays {
+		expected, found := world.Raycast(ray.Origin, ray.Direction, ray.MaxDist, nil)
+		if hits[i].Body != expected.Body || (found && hits[i].Distance != expected.Distance) {
+			t.Fatalf("ray %d: hit %v at %v, want %v at %v", i, hits[i].Body != nil, hits[i].Distance, found, expected.Distance)
+		}
+	}
+	if hits[len(hits)-1].Body != nil {
+		t.Error("expected the ray going up to miss")
+	}
+}
+
+func TestShapeCast(t *testing.T) {
+	box := createBox(mgl64.Vec3{4, 0, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeStatic)
+	sphere := createSphere(mgl64.Vec3{8, 0, 0}, 1, actor.BodyTypeStatic)
+	world := createQueryWorld(box, sphere)
+	probe := &actor.Sphere{Radius: 0.5}
+
+	hit, found := world.ShapeCast(probe, mgl64.Vec3{0, 0, 0}, mgl64.Vec3{10, 0, 0}, mgl64.QuatIdent(), nil)
+	if !found || hit.Body != box {
+		t.Fatalf("expected the box to be hit first, got %+v", hit)
+	}
+	if math.Abs(hit.Fraction-0.3) > 1e-4 || !vec3ApproxEqual(hit.Position, mgl64.Vec3{3, 0, 0}, 1e-3) {
+		t.Errorf("hit at fraction %v, position %v, want 0.3 at {3 0 0}", hit.Fraction, hit.Position)
+	}
+	if !vec3ApproxEqual(hit.Normal, mgl64.Vec3{-1, 0, 0}, 1e-4) || math.Abs(hit.Point.X()-3.5) > 1e-4 {
+		t.Errorf("hit point %v, normal %v, want the face x = 3.5", hit.Point, hit.Normal)
+	}
+
+	hit, found = world.ShapeCast(probe, mgl64.Vec3{0, 0, 0}, mgl64.Vec3{10, 0, 0}, mgl64.QuatIdent(), func(body *actor.RigidBody) bool {
+		return body != box
+	})
+	if !found || hit.Body != sphere || math.Abs(hit.Fraction-0.65) > 1e-4 {
+		t.Errorf("expected the sphere at fraction 0.65 once the box is filtered, got %+v", hit)
+	}
+
+	if _, found := world.ShapeCast(probe, mgl64.Vec3{0, 2, 0}, mgl64.Vec3{10, 2, 0}, mgl64.QuatIdent(), nil); found {
+		t.Error("expected the sweep above the bodies to miss")
+	}
+}
+
+func TestShapeCast_Rotated(t *testing.T) {
+	world := createQueryWorld(createBox(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{5, 0.5, 5}, actor.BodyTypeStatic))
+
+	// A unit cube standing on an edge reaches sqrt(2)/2 below its center
+	rotation := mgl64.QuatRotate(math.Pi/4, mgl64.Vec3{0, 0, 1})
+	hit, found := world.ShapeCast(&actor.Box{HalfExtents: mgl64.Vec3{0.5, 0.5, 0.5}}, mgl64.Vec3{0, 5, 0}, mgl64.Vec3{0, -5, 0}, rotation, nil)
+	if !found {
+		t.Fatal("expected the ground to be hit")
+	}
+	if want := 0.5 + math.Sqrt2/2; math.Abs(hit.Position.Y()-want) > 1e-4 {
+		t.Errorf("stopped at y = %v, want %v", hit.Position.Y(), want)
+	}
+}
+
+func TestShapeCast_PlaneAndMesh(t *testing.T) {
+	mesh, err := actor.NewTriangleMesh(
+		[]mgl64.Vec3{{-2, 0, -2}, {-2, 0, 2}, {2, 0, -2}, {2, 0, 2}},
+		[][3]int{{0, 1, 2}, {2, 1, 3}},
+	)
+	if err != nil {
+		t.Fatalf("NewTriangleMesh failed: %v", err)
+	}
+	platform := actor.NewRigidBody(actor.Transform{Position: mgl64.Vec3{10, 1, 0}, Rotation: mgl64.QuatIdent()}, mesh, actor.BodyTypeStatic, 0)
+	ground := createPlane(mgl64.Vec3{0, 1, 0}, 0)
+	world := createQueryWorld(ground, platform)
+	probe := &actor.Box{HalfExtents: mgl64.Vec3{0.5, 0.5, 0.5}}
+
+	hit, found := world.ShapeCast(probe, mgl64.Vec3{0, 5, 0}, mgl64.Vec3{0, -5, 0}, mgl64.QuatIdent(), nil)
+	if !found || hit.Body != ground || math.Abs(hit.Fraction-0.45) > 1e-9 {
+		t.Fatalf("expected the plane at fraction 0.45, got %+v", hit)
+	}
+	if hit.Normal != (mgl64.Vec3{0, 1, 0}) || math.Abs(hit.Point.Y()) > 1e-9 {
+		t.Errorf("hit point %v, normal %v, want on the plane", hit.Point, hit.Normal)
+	}
+
+	hit, found = world.ShapeCast(probe, mgl64.Vec3{10, 5, 0}, mgl64.Vec3{10, -5, 0}, mgl64.QuatIdent(), nil)
+	if !found || hit.Body != platform || math.Abs(hit.Position.Y()-1.5) > 1e-4 {
+		t.Fatalf("expected the mesh with the box resting at y = 1.5, got %+v", hit)
+	}
+
+	hit, found = world.ShapeCast(probe, mgl64.Vec3{0, 0.2, 0}, mgl64.Vec3{5, 0.2, 0}, mgl64.QuatIdent(), nil)
+	if !found || hit.Body != ground || hit.Fraction != 0 {
+		t.Errorf("expected the plane overlapped at the start, got %+v", hit)
+	}
+}
+
+func TestShapeCast_Compound(t *testing.T) {
+	wall := createBox(mgl64.Vec3{0, 0, 6}, mgl64.Vec3{4, 4, 0.5}, actor.BodyTypeStatic)
+	world := createQueryWorld(wall)
+
+	// A dumbbell whose front sphere reaches 2 units ahead of its center
+	dumbbell, err := actor.NewCompound([]actor.CompoundChild{
+		{Shape: &actor.Sphere{Radius: 0.5}, Position: mgl64.Vec3{0, 0, 1.5}, Rotation: mgl64.QuatIdent()},
+		{Shape: &actor.Sphere{Radius: 0.5}, Position: mgl64.Vec3{0, 0, -1.5}, Rotation: mgl64.QuatIdent()},
+	})
+	if err != nil {
+		t.Fatalf("NewCompound failed: %v", err)
+	}
+
+	hit, found := world.ShapeCast(dumbbell, mgl64.Vec3{0, 0, 0}, mgl64.Vec3{0, 0, 10}, mgl64.QuatIdent(), nil)
+	if !found || hit.Body != wall {
+		t.Fatal("expected the wall to be hit")
+	}
+	if math.Abs(hit.Position.Z()-3.5) > 1e-4 {
+		t.Errorf("stopped at z = %v, want 3.5", hit.Position.Z())
+	}
+}
+
+// TestShapeCast_SharedShape sweeps the shape of a static wall, the AABB of the wall must not move:
+// static bodies never recompute it, the wall would be missed by the broad phase for good
+func TestShapeCast_SharedShape(t *testing.T) {
+	wall := createBox(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{0.5, 2, 2}, actor.BodyTypeStatic)
+	world := createQueryWorld(wall)
+	bounds := wall.Shape.GetAABB()
+
+	hit, found := world.ShapeCast(wall.Shape, mgl64.Vec3{10, 0, 0}, mgl64.Vec3{-10, 0, 0}, mgl64.QuatIdent(), nil)
+	if !found || hit.Body != wall || math.Abs(hit.Position.X()-1) > 1e-4 {
+		t.Fatalf("expected the wall to stop the sweep at x = 1, got %+v", hit)
+	}
+	if wall.Shape.GetAABB() != bounds {
+		t.Fatalf("the AABB of the wall moved to %v, want %v", wall.Shape.GetAABB(), bounds)
+	}
+
+	world.Step(1.0 / 60.0)
+	if bodies := world.QueryAABB(actor.AABB{Min: mgl64.Vec3{-0.1, -0.1, -0.1}, Max: mgl64.Vec3{0.1, 0.1, 0.1}}, nil); len(bodies) != 1 {
+		t.Errorf("expected the wall in its cells after a Step, got %d bodies", len(bodies))
+	}
+}
+
+// TestShapeCast_GridMatchesBruteForce sweeps a box through a field of bodies,
+// the cells overlapped by the sweep find the same hit as testing every body
+func TestShapeCast_GridMatchesBruteForce(t *testing.T) {
+	var bodies []*actor.RigidBody
+	for x := -3; x <= 3; x++ {
+		for z := -3; z <= 3; z++ {
+			if (x+z)%2 != 0 || (x == 0 && z == 0) {
+				continue
+			}
+			bodies = append(bodies, createSphere(mgl64.Vec3{float64(x) * 2, 0, float64(z) * 2}, 0.4, actor.BodyTypeStatic))
+		}
+	}
+	world := createQueryWorld(bodies...)
+	brute := createQueryWorld(bodies...)
+	brute.SpatialGrid = nil
+	probe := &actor.Box{HalfExtents: mgl64.Vec3{0.3, 0.3, 0.3}}
+
+	hits := 0
+	for i := range 64 {
+		angle := float64(i) * math.Pi / 32
+		to := mgl64.Vec3{math.Cos(angle) * 7, 0, math.Sin(angle) * 7}
+
+		hit, found := world.ShapeCast(probe, mgl64.Vec3{}, to, mgl64.QuatIdent(), nil)
+		expected, expectedFound := brute.ShapeCast(probe, mgl64.Vec3{}, to, mgl64.QuatIdent(), nil)
+		if found != expectedFound || hit.Body != expected.Body || math.Abs(hit.Fraction-expected.Fraction) > 1e-9 {
+			t.Fatalf("sweep %d: grid hit %v at %v, brute force hit %v at %v", i, found, hit.Fraction, expectedFound, expected.Fraction)
+		}
+		if found {
+			hits++
+		}
+	}
+	if hits < 16 {
+		t.Errorf("only %d sweeps hit a body", hits)
+	}
+}
+
+func TestQueryAABB(t *testing.T) {
+	inside := createSphere(mgl64.Vec3{1, 0, 0}, 0.5, actor.BodyTypeStatic)
+	straddling := createBox(mgl64.Vec3{3, 0, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeStatic)
+	outside := createSphere(mgl64.Vec3{6, 0, 0}, 0.5, actor.BodyTypeStatic)
+	ground := createPlane(mgl64.Vec3{0, 1, 0}, 1)
+	world := createQueryWorld(outside, straddling, ground, inside)
+	region := actor.AABB{Min: mgl64.Vec3{-1, -0.5, -1}, Max: mgl64.Vec3{2.6, 0.5, 1}}
+
+	bodies := world.QueryAABB(region, nil)
+	if len(bodies) != 2 || bodies[0] != straddling || bodies[1] != inside {
+		t.Fatalf("expected the box and the inner sphere in world order, got %d bodies", len(bodies))
+	}
+
+	// The region reaches below the plane y = -1
+	region.Min[1] = -1.5
+	if bodies := world.QueryAABB(region, nil); len(bodies) != 3 || bodies[1] != ground {
+		t.Errorf("expected the plane once the region reaches under it, got %d bodies", len(bodies))
+	}
+
+	bodies = world.QueryAABB(region, func(body *actor.RigidBody) bool {
+		return body != inside
+	})
+	if len(bodies) != 2 {
+		t.Errorf("expected the filter to skip the inner sphere, got %d bodies", len(bodies))
+	}
+}
+
+// TestQuery_AfterAddRemoveBody checks the queries between two Steps do not use the indices of the bodies shifted
+// by a RemoveBody, and find the bodies added since the last Step
+func TestQuery_AfterAddRemoveBody(t *testing.T) {
+	var boxes []*actor.RigidBody
+	for i := range 10 {
+		boxes = append(boxes, createBox(mgl64.Vec3{float64(i) * 2, 0, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeStatic))
+	}
+	worlds := map[string]*World{
+		"spatial grid": createQueryWorld(boxes...),
+		"dynamic tree": NewWorld(WithDynamicTree(0.1), WithBroadPhase(nil, -1)),
+	}
+	for _, box := range boxes {
+		worlds["dynamic tree"].AddBody(box)
+	}
+	worlds["dynamic tree"].Step(1.0 / 60.0)
+
+	for name, world := range worlds {
+		t.Run(name, func(t *testing.T) {
+			world.RemoveBody(boxes[0])
+			region := actor.AABB{Min: mgl64.Vec3{5.8, -0.2, -0.2}, Max: mgl64.Vec3{6.2, 0.2, 0.2}}
+			if found := world.QueryAABB(region, nil); len(found) != 1 || found[0] != boxes[3] {
+				t.Errorf("QueryAABB = %v, want the box 3 after the removal of the box 0", found)
+			}
+			if hit, ok := world.Raycast(mgl64.Vec3{6, 5, 0}, mgl64.Vec3{0, -1, 0}, 10, nil); !ok || hit.Body != boxes[3] {
+				t.Errorf("Raycast = %+v, want the box 3 after the removal of the box 0", hit)
+			}
+
+			added := createSphere(mgl64.Vec3{0, 5, 0}, 0.5, actor.BodyTypeStatic)
+			world.AddBody(added)
+			if body, _, ok := world.ClosestBody(mgl64.Vec3{0, 6, 0}, 2, nil); !ok || body != added {
+				t.Errorf("ClosestBody = %v, want the body added since the last Step", body)
+			}
+
+			// The next Step indexes the bodies again
+			world.Step(1.0 / 60.0)
+			if world.indexedBroadphase() == nil {
+				t.Errorf("broadphase outdated after the Step")
+			}
+			if found := world.QueryAABB(region, nil); len(found) != 1 || found[0] != boxes[3] {
+				t.Errorf("QueryAABB = %v, want the box 3 after the Step", found)
+			}
+		})
+	}
+}
+
+func TestQueryAABB_Mesh(t *testing.T) {
+	mesh, err := actor.NewTriangleMesh(
+		[]mgl64.Vec3{{0, 0, 0}, {0, 0, 4}, {4, 0, 0}},
+		[][3]int{{0, 1, 2}},
+	)
+	if err != nil {
+		t.Fatalf("NewTriangleMesh failed: %v", err)
+	}
+	world := createQueryWorld(actor.NewRigidBody(actor.Transform{Rotation: mgl64.QuatIdent()}, mesh, actor.BodyTypeStatic, 0))
+
+	if bodies := world.QueryAABB(actor.AABB{Min: mgl64.Vec3{0.5, -0.5, 0.5}, Max: mgl64.Vec3{1, 0.5, 1}}, nil); len(bodies) != 1 {
+		t.Error("expected the region over the triangle to find the mesh")
+	}
+	if bodies := world.QueryAABB(actor.AABB{Min: mgl64.Vec3{0.5, 1, 0.5}, Max: mgl64.Vec3{1, 2, 1}}, nil); len(bodies) != 0 {
+		t.Error("expected the region above the triangle to miss the mesh")
+	}
+}
+
+func TestOverlapShape(t *testing.T) {
+	box := createBox(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{1, 1, 1}, actor.BodyTypeStatic)
+	sphere := createSphere(mgl64.Vec3{5, 0, 0}, 1, actor.BodyTypeStatic)
+	ground := createPlane(mgl64.Vec3{0, 1, 0}, 1.5)
+	world := createQueryWorld(box, sphere, ground)
+	probe := &actor.Sphere{Radius: 0.5}
+	at := func(position mgl64.Vec3) actor.Transform {
+		return actor.Transform{Position: position, Rotation: mgl64.QuatIdent()}
+	}
+
+	if bodies := world.OverlapShape(probe, at(mgl64.Vec3{1.3, 0, 0}), nil); len(bodies) != 1 || bodies[0] != box {
+		t.Errorf("expected the box, got %d bodies", len(bodies))
+	}
+	// The AABBs overlap at the corner of the box, the shapes do not
+	if bodies := world.OverlapShape(probe, at(mgl64.Vec3{1.4, 1.4, 1.4}), nil); len(bodies) != 0 {
+		t.Errorf("expected GJK to reject the corner, got %d bodies", len(bodies))
+	}
+	if bodies := world.OverlapShape(probe, at(mgl64.Vec3{5, -1.3, 0}), nil); len(bodies) != 2 || bodies[0] != sphere || bodies[1] != ground {
+		t.Errorf("expected the sphere and the plane, got %d bodies", len(bodies))
+	}
+
+	// A rotated bar reaching both the box and the sphere
+	bar := &actor.Box{HalfExtents: mgl64.Vec3{0.2, 0.2, 3}}
+	rotation := mgl64.QuatRotate(math.Pi/2, mgl64.Vec3{0, 1, 0})
+	bodies := world.OverlapShape(bar, actor.Transform{Position: mgl64.Vec3{2.5, 0, 0}, Rotation: rotation}, nil)
+	if len(bodies) != 2 || bodies[0] != box || bodies[1] != sphere {
+		t.Errorf("expected the rotated bar to reach the box and the sphere, got %d bodies", len(bodies))
+	}
+}
+
+func TestOverlapShape_CompoundAndMesh(t *testing.T) {
+	mesh, err := actor.NewTriangleMesh(
+		[]mgl64.Vec3{{-4, 0, -4}, {-4, 0, 4}, {4, 0, -4}, {4, 0, 4}},
+		[][3]int{{0, 1, 2}, {2, 1, 3}},
+	)
+	if err != nil {
+		t.Fatalf("NewTriangleMesh failed: %v", err)
+	}
+	terrain := actor.NewRigidBody(actor.Transform{Rotation: mgl64.QuatIdent()}, mesh, actor.BodyTypeStatic, 0)
+	crate := createBox(mgl64.Vec3{0, 1, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeStatic)
+	world := createQueryWorld(terrain, crate)
+
+	// Two spheres 3 units apart: the gap between them straddles the crate
+	dumbbell, err := actor.NewCompound([]actor.CompoundChild{
+		{Shape: &actor.Sphere{Radius: 0.4}, Position: mgl64.Vec3{-1.5, 0, 0}, Rotation: mgl64.QuatIdent()},
+		{Shape: &actor.Sphere{Radius: 0.4}, Position: mgl64.Vec3{1.5, 0, 0}, Rotation: mgl64.QuatIdent()},
+	})
+	if err != nil {
+		t.Fatalf("NewCompound failed: %v", err)
+	}
+
+	if bodies := world.OverlapShape(dumbbell, actor.Transform{Position: mgl64.Vec3{0, 1, 0}, Rotation: mgl64.QuatIdent()}, nil); len(bodies) != 0 {
+		t.Errorf("expected the children to miss the crate between them, got %d bodies", len(bodies))
+	}
+	bodies := world.OverlapShape(dumbbell, actor.Transform{Position: mgl64.Vec3{1.5, 0.3, 0}, Rotation: mgl64.QuatIdent()}, nil)
+	if len(bodies) != 2 || bodies[0] != terrain || bodies[1] != crate {
+		t.Errorf("expected the terrain and the crate, got %d bodies", len(bodies))
+	}
+}
+
+// TestOverlapShape_SharedShape tests the shape of a static crate elsewhere, the AABB of the crate must not move
+func TestOverlapShape_SharedShape(t *testing.T) {
+	crate := createBox(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeStatic)
+	world := createQueryWorld(crate)
+	bounds := crate.Shape.GetAABB()
+
+	if bodies := world.OverlapShape(crate.Shape, actor.Transform{Position: mgl64.Vec3{0.8, 0, 0}, Rotation: mgl64.QuatIdent()}, nil); len(bodies) != 1 {
+		t.Fatalf("expected the crate, got %d bodies", len(bodies))
+	}
+	if bodies := world.OverlapShape(crate.Shape, actor.Transform{Position: mgl64.Vec3{5, 0, 0}, Rotation: mgl64.QuatIdent()}, nil); len(bodies) != 0 {
+		t.Fatalf("expected no body, got %d", len(bodies))
+	}
+	if crate.Shape.GetAABB() != bounds {
+		t.Fatalf("the AABB of the crate moved to %v, want %v", crate.Shape.GetAABB(), bounds)
+	}
+
+	world.Step(1.0 / 60.0)
+	if bodies := world.QueryAABB(actor.AABB{Min: mgl64.Vec3{-0.1, -0.1, -0.1}, Max: mgl64.Vec3{0.1, 0.1, 0.1}}, nil); len(bodies) != 1 {
+		t.Errorf("expected the crate in its cells after a Step, got %d bodies", len(bodies))
+	}
+}
+
+// TestQueryAABB_GridMatchesBruteForce moves a region through a field of bodies,
+// the cells overlapped by the region find the same bodies as testing every body
+func TestQueryAABB_GridMatchesBruteForce(t *testing.T) {
+	var bodies []*actor.RigidBody
+	for x := -4; x <= 4; x++ {
+		for z := -4; z <= 4; z++ {
+			bodies = append(bodies, createBox(mgl64.Vec3{float64(x) * 1.3, 0, float64(z) * 1.3}, mgl64.Vec3{0.3, 0.3, 0.3}, actor.BodyTypeStatic))
+		}
+	}
+	world := createQueryWorld(bodies...)
+	brute := createQueryWorld(bodies...)
+	brute.SpatialGrid = nil
+
+	for i := range 32 {
+		center := mgl64.Vec3{math.Cos(float64(i)) * 4, 0, math.Sin(float64(i)*0.7) * 4}
+		half := mgl64.Vec3{0.4 + float64(i%4), 0.5, 0.4 + float64(i%3)}
+		region := actor.AABB{Min: center.Sub(half), Max: center.Add(half)}
+
+		found, expected := world.QueryAABB(region, nil), brute.QueryAABB(region, nil)
+		if len(found) != len(expected) || len(found) == 0 {
+			t.Fatalf("region %d: grid found %d bodies, brute force %d", i, len(found), len(expected))
+		}
+		for j := range found {
+			if found[j] != expected[j] {
+				t.Fatalf("region %d: body %d differs", i, j)
+			}
+		}
+	}
+}
+
+func TestQueryPoint(t *testing.T) {
+	room := createBox(mgl64.Vec3{0, 2, 0}, mgl64.Vec3{4, 2, 4}, actor.BodyTypeStatic)
+	room.IsTrigger = true
+	ball := createSphere(mgl64.Vec3{1, 1, 0}, 1, actor.BodyTypeStatic)
+	ground := createPlane(mgl64.Vec3{0, 1, 0}, 0)
+	world := createQueryWorld(ground, room, ball)
+
+	if bodies := world.QueryPoint(mgl64.Vec3{1.5, 1.5, 0}, nil); len(bodies) != 2 || bodies[0] != room || bodies[1] != ball {
+		t.Errorf("expected the room and the ball in world order, got %d bodies", len(bodies))
+	}
+	// Inside the AABB of the ball, outside the sphere
+	if bodies := world.QueryPoint(mgl64.Vec3{1.8, 1.8, 0.8}, nil); len(bodies) != 1 || bodies[0] != room {
+		t.Errorf("expected the room only, got %d bodies", len(bodies))
+	}
+	if bodies := world.QueryPoint(mgl64.Vec3{10, -1, 0}, nil); len(bodies) != 1 || bodies[0] != ground {
+		t.Errorf("expected the plane under the ground, got %d bodies", len(bodies))
+	}
+
+	triggers := world.QueryPoint(mgl64.Vec3{1.5, 1.5, 0}, func(body *actor.RigidBody) bool {
+		return body.IsTrigger
+	})
+	if len(triggers) != 1 || triggers[0] != room {
+		t.Errorf("expected the filter to keep the room only, got %d bodies", len(triggers))
+	}
+}
@@ -0,0 +1,86 @@
+package feather
+
+import (
+	"errors"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/akmonengine/feather/constraint"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// ErrInvalidRope is returned by AddRope for a rope without segments, length, radius or mass
+var ErrInvalidRope = errors.New("feather: rope requires segments, a length, a radius and a mass")
+
+// RopeSettings describes a rope built by World.AddRope
+type RopeSettings struct {
+	// Segments is the count of bodies along the rope: more segments bend smoothly, at the cost of more joints
+	Segments int
+	// Radius is the half thickness (m) of the segments
+	Radius float64
+	// Mass (kg) of the whole rope, shared by its segments
+	Mass float64
+	// Compliance is the inverse stiffness (m/N) of the joints, 0 is rigid
+	Compliance float64
+
+	// StartBody and EndBody are attached to the ends of the rope, nil leaves the end free (e.g. a static hook and a crate)
+	StartBody *actor.RigidBody
+	EndBody   *actor.RigidBody
+}
+
+// Rope holds the bodies and the joints of a rope built by World.AddRope
+type Rope struct {
+	// Segments are the bodies of the rope, from its start to its end
+	Segments []*actor.RigidBody
+	// Joints[i] joins Segments[i] to Segments[i+1]
+	Joints []*constraint.ConeTwistJoint
+
+	// StartJoint and EndJoint attach the ends to StartBody and EndBody, nil for a free end
+	StartJoint *constraint.ConeTwistJoint
+	EndJoint   *constraint.ConeTwistJoint
+}
+
+// AddRope builds a straight rope from start to end, made of box segments joined by ball joints (ConeTwistJoint
+// without limits), and adds its bodies and joints to the world. The joints skip the collisions of the bodies they join,
+// the segments colliding with the other ones and the world. The caller owns them, as for AddBody and AddConstraint.
+func (w *World) AddRope(start, end mgl64.Vec3, settings RopeSettings) (*Rope, error) {
+	length := end.Sub(start).Len()
+	if settings.Segments < 1 || !(length > 0) || !(settings.Radius > 0) || !(settings.Mass > 0) {
+		return nil, ErrInvalidRope
+	}
+
+	direction := end.Sub(start).Mul(1.0 / length)
+	segmentLength := length / float64(settings.Segments)
+	transform := actor.Transform{Rotation: mgl64.QuatBetweenVectors(mgl64.Vec3{0, 0, 1}, direction)}
+	transform.InverseRotation = transform.Rotation.Inverse()
+	shape := actor.Box{HalfExtents: mgl64.Vec3{settings.Radius, settings.Radius, segmentLength / 2}}
+
+	rope := &Rope{Segments: make([]*actor.RigidBody, settings.Segments)}
+	for i := range rope.Segments {
+		transform.Position = start.Add(direction.Mul((float64(i) + 0.5) * segmentLength))
+		box := shape
+		rope.Segments[i] = actor.NewRigidBodyWithMass(transform, &box, actor.BodyTypeDynamic, settings.Mass/float64(settings.Segments))
+		w.AddBody(rope.Segments[i])
+	}
+
+	join := func(bodyA, bodyB *actor.RigidBody, pivot mgl64.Vec3) *constraint.ConeTwistJoint {
+		joint := constraint.NewConeTwistJoint(bodyA, bodyB, pivot, direction)
+		joint.Compliance = settings.Compliance
+		joint.DisableCollision = true
+		w.AddConstraint(joint)
+
+		return joint
+	}
+
+	if settings.StartBody != nil {
+		rope.StartJoint = join(settings.StartBody, rope.Segments[0], start)
+	}
+	for i := 1; i < settings.Segments; i++ {
+		pivot := start.Add(direction.Mul(float64(i) * segmentLength))
+		rope.Joints = append(rope.Joints, join(rope.Segments[i-1], rope.Segments[i], pivot))
+	}
+	if settings.EndBody != nil {
+		rope.EndJoint = join(rope.Segments[settings.Segments-1], settings.EndBody, end)
+	}
+
+	return rope, nil
+}
@@ -0,0 +1,81 @@
+package feather
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestWorld_AddRope_Invalid(t *testing.T) {
+	world := NewWorld()
+	for name, settings := range map[string]RopeSettings{
+		"no segment": {Radius: 0.05, Mass: 1},
+		"no radius":  {Segments: 4, Mass: 1},
+		"no mass":    {Segments: 4, Radius: 0.05},
+	} {
+		if _, err := world.AddRope(mgl64.Vec3{}, mgl64.Vec3{1, 0, 0}, settings); !errors.Is(err, ErrInvalidRope) {
+			t.Errorf("%s: err = %v, want ErrInvalidRope", name, err)
+		}
+	}
+	if _, err := world.AddRope(mgl64.Vec3{}, mgl64.Vec3{}, RopeSettings{Segments: 4, Radius: 0.05, Mass: 1}); !errors.Is(err, ErrInvalidRope) {
+		t.Errorf("zero length: err = %v, want ErrInvalidRope", err)
+	}
+	if len(world.Bodies) != 0 || len(world.constraints) != 0 {
+		t.Errorf("%d bodies and %d constraints added by invalid ropes", len(world.Bodies), len(world.constraints))
+	}
+}
+
+func TestWorld_AddRope(t *testing.T) {
+	world := NewWorld(WithSubsteps(8), WithIterations(4, 1))
+	hook := createSphere(mgl64.Vec3{0, 3, 0}, 0.1, actor.BodyTypeStatic)
+	crate := createBox(mgl64.Vec3{2, 2.8, 0}, mgl64.Vec3{0.2, 0.2, 0.2}, actor.BodyTypeDynamic)
+	world.AddBody(hook)
+	world.AddBody(crate)
+
+	// Stretched horizontally from the hook to the top of the crate, the crate swings down under the hook
+	rope, err := world.AddRope(mgl64.Vec3{0, 3, 0}, mgl64.Vec3{2, 3, 0}, RopeSettings{
+		Segments:  10,
+		Radius:    0.02,
+		Mass:      0.5,
+		StartBody: hook,
+		EndBody:   crate,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rope.Segments) != 10 || len(rope.Joints) != 9 || rope.StartJoint == nil || rope.EndJoint == nil {
+		t.Fatalf("%d segments, %d joints, want 10 segments joined by 9 joints and attached at both ends", len(rope.Segments), len(rope.Joints))
+	}
+	if mass := rope.Segments[0].Material.GetMass(); math.Abs(mass-0.05) > 1e-9 {
+		t.Errorf("segment mass = %v, want the rope mass shared by the segments", mass)
+	}
+
+	// Swinging under the hook, the rope bends without extending
+	var maxLength float64
+	minY := crate.Transform.Position.Y()
+	for range 240 {
+		world.Step(1.0 / 60.0)
+
+		top := crate.Transform.Position.Add(crate.Transform.Rotation.Rotate(rope.EndJoint.LocalAnchorB))
+		maxLength = max(maxLength, top.Sub(hook.Transform.Position).Len())
+		minY = min(minY, crate.Transform.Position.Y())
+	}
+	if maxLength > 2.02 {
+		t.Errorf("rope extended to %v, want held at its length 2", maxLength)
+	}
+	if minY > 1 {
+		t.Errorf("crate down to %v, want swung under the hook", minY)
+	}
+
+	// The pivots of the joints stay joined
+	for _, joint := range append(rope.Joints, rope.StartJoint, rope.EndJoint) {
+		pivotA := joint.BodyA.Transform.Position.Add(joint.BodyA.Transform.Rotation.Rotate(joint.LocalAnchorA))
+		pivotB := joint.BodyB.Transform.Position.Add(joint.BodyB.Transform.Rotation.Rotate(joint.LocalAnchorB))
+		if gap := pivotB.Sub(pivotA).Len(); gap > 1e-2 {
+			t.Errorf("joint open by %v", gap)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package feather
+
+import (
+	"github.com/akmonengine/feather/constraint"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// smoothNormals blends the contact normals with the normals of the previous substep for the same pair.
+// Pairs without contact in this substep are forgotten, a pair starts again from its raw normal.
+func (w *World) smoothNormals(constraints []*constraint.ContactConstraint) {
+	if w.NormalSmoothing <= 0 {
+		clear(w.smoothedNormals)
+		return
+	}
+
+	// Swap the maps, so they are reused from one substep to another
+	if w.smoothedNormals == nil {
+		w.smoothedNormals = make(map[Pair]mgl64.Vec3)
+		w.previousNormals = make(map[Pair]mgl64.Vec3)
+	}
+	w.previousNormals, w.smoothedNormals = w.smoothedNormals, w.previousNormals
+	clear(w.smoothedNormals)
+	previous := w.previousNormals
+
+	for _, c := range constraints {
+		pair := OrderPair(c.BodyA, c.BodyB)
+
+		// Store the normals in the pair order, the bodies of a contact may be swapped from one substep to another
+		normal := c.Normal
+		if c.BodyA != pair.BodyA {
+			normal = normal.Mul(-1)
+		}
+
+		if previousNormal, ok := previous[pair]; ok && previousNormal.Dot(normal) > 0 {
+			blended := previousNormal.Mul(w.NormalSmoothing).Add(normal.Mul(1 - w.NormalSmoothing))
+			if length := blended.Len(); length > 1e-8 {
+				normal = blended.Mul(1.0 / length)
+			}
+		}
+		w.smoothedNormals[pair] = normal
+
+		if c.BodyA != pair.BodyA {
+			c.Normal = normal.Mul(-1)
+		} else {
+			c.Normal = normal
+		}
+	}
+}
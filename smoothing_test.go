@@ -0,0 +1,63 @@
+package feather
+
+import (
+	"testing"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/akmonengine/feather/constraint"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestSmoothNormals(t *testing.T) {
+	world := &World{NormalSmoothing: 0.5}
+	bodyA := createBox(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{1, 1, 1}, actor.BodyTypeStatic)
+	bodyB := createBox(mgl64.Vec3{0, 1.9, 0}, mgl64.Vec3{1, 1, 1}, actor.BodyTypeDynamic)
+
+	contact := func(bodyA, bodyB *actor.RigidBody, normal mgl64.Vec3) *constraint.ContactConstraint {
+		return &constraint.ContactConstraint{BodyA: bodyA, BodyB: bodyB, Normal: normal}
+	}
+
+	first := contact(bodyA, bodyB, mgl64.Vec3{0, 1, 0})
+	world.smoothNormals([]*constraint.ContactConstraint{first})
+	if first.Normal != (mgl64.Vec3{0, 1, 0}) {
+		t.Errorf("a new pair should keep its raw normal, got %v", first.Normal)
+	}
+
+	// A flickering normal is blended with the previous one
+	tilted := mgl64.Vec3{1, 1, 0}.Normalize()
+	second := contact(bodyA, bodyB, tilted)
+	world.smoothNormals([]*constraint.ContactConstraint{second})
+	expected := mgl64.Vec3{0, 1, 0}.Add(tilted).Normalize()
+	if !vec3ApproxEqual(second.Normal, expected, 1e-9) {
+		t.Errorf("normal = %v, want %v", second.Normal, expected)
+	}
+
+	// Swapped bodies: the normal is flipped, and still blended
+	third := contact(bodyB, bodyA, mgl64.Vec3{0, -1, 0})
+	world.smoothNormals([]*constraint.ContactConstraint{third})
+	if third.Normal.Y() > -0.9 || third.Normal.X() >= 0 {
+		t.Errorf("normal = %v, want blended and oriented from B to A", third.Normal)
+	}
+
+	// A pair without contact is forgotten
+	world.smoothNormals(nil)
+	fourth := contact(bodyA, bodyB, tilted)
+	world.smoothNormals([]*constraint.ContactConstraint{fourth})
+	if fourth.Normal != tilted {
+		t.Errorf("normal = %v, want the raw normal after a separation", fourth.Normal)
+	}
+}
+
+func TestSmoothNormals_Disabled(t *testing.T) {
+	world := &World{}
+	bodyA := createBox(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{1, 1, 1}, actor.BodyTypeStatic)
+	bodyB := createBox(mgl64.Vec3{0, 1.9, 0}, mgl64.Vec3{1, 1, 1}, actor.BodyTypeDynamic)
+
+	world.smoothNormals([]*constraint.ContactConstraint{{BodyA: bodyA, BodyB: bodyB, Normal: mgl64.Vec3{0, 1, 0}}})
+	c := &constraint.ContactConstraint{BodyA: bodyA, BodyB: bodyB, Normal: mgl64.Vec3{1, 0, 0}}
+	world.smoothNormals([]*constraint.ContactConstraint{c})
+
+	if c.Normal != (mgl64.Vec3{1, 0, 0}) {
+		t.Errorf("normal = %v, want unchanged without smoothing", c.Normal)
+	}
+}
@@ -0,0 +1,327 @@
+package feather
+
+import (
+	"fmt"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// ShapeSnapshot holds the parameters of a shape, only the fields of its type are set
+type ShapeSnapshot struct {
+	Type        actor.ShapeType
+	HalfExtents mgl64.Vec3      `json:",omitempty"`
+	Radius      float64         `json:",omitempty"`
+	Height      float64         `json:",omitempty"`
+	Vertices    []mgl64.Vec3    `json:",omitempty"`
+	Indices     [][3]int        `json:",omitempty"`
+	Normal      mgl64.Vec3      `json:",omitempty"`
+	Distance    float64         `json:",omitempty"`
+	Children    []ChildSnapshot `json:",omitempty"`
+	Margin      float64         `json:",omitempty"`
+}
+
+// ChildSnapshot holds a child of a compound shape
+type ChildSnapshot struct {
+	Shape    ShapeSnapshot
+	Position mgl64.Vec3
+	Rotation mgl64.Quat
+	Trigger  bool `json:",omitempty"`
+}
+
+// BodySnapshot holds the state required to recreate a rigid body.
+// Id is copied as is, it must be serializable by the encoder used by the caller.
+type BodySnapshot struct {
+	Id              any
+	Name            string
+	BodyType        actor.BodyType
+	Transform       actor.Transform
+	ShapeOffset     actor.Transform
+	Velocity        mgl64.Vec3
+	AngularVelocity mgl64.Vec3
+	IsTrigger       bool
+	IsSleeping      bool
+	// Disabled is omitted for the enabled bodies, see actor.RigidBody.SetEnabled
+	Disabled bool `json:",omitzero"`
+	// LinearLock and AngularLock are omitted for the free bodies, see actor.RigidBody.SetAxisLocks
+	LinearLock  actor.AxisLock `json:",omitzero"`
+	AngularLock actor.AxisLock `json:",omitzero"`
+	// MaxVelocity and MaxAngularVelocity are omitted for the bodies using the limits of the world
+	MaxVelocity        float64 `json:",omitzero"`
+	MaxAngularVelocity float64 `json:",omitzero"`
+	Density            float64
+	// Mass, Inertia and CenterOfMass are omitted when derived from the shape, see actor.RigidBody.SetMass,
+	// actor.RigidBody.SetInertia and actor.RigidBody.SetCenterOfMass. Shape is the Compound wrapping the shape
+	// of a moved center of mass.
+	Mass         float64    `json:",omitzero"`
+	Inertia      mgl64.Mat3 `json:",omitzero"`
+	CenterOfMass mgl64.Vec3 `json:",omitzero"`
+	Restitution  float64
+	// RestitutionCurve is omitted for the constant restitution
+	RestitutionCurve actor.RestitutionCurve `json:",omitzero"`
+	StaticFriction   float64
+	DynamicFriction  float64
+	LinearDamping    float64
+	AngularDamping   float64
+	Shape            ShapeSnapshot
+	// SpinFriction is omitted when the bodies spin freely
+	SpinFriction float64 `json:",omitzero"`
+	// FrictionCombine and RestitutionCombine are omitted for the default rules
+	FrictionCombine    actor.CombineMode `json:",omitzero"`
+	RestitutionCombine actor.CombineMode `json:",omitzero"`
+}
+
+// Snapshot is a serializable copy of a set of bodies (e.g. a streamed world chunk).
+// All its fields are exported, so it can be saved with encoding/json or encoding/gob.
+type Snapshot struct {
+	Bodies []BodySnapshot
+}
+
+// Snapshot copies the state of the bodies accepted by the filter (nil for all the bodies).
+// Returns an error if a body uses a shape that cannot be serialized.
+func (w *World) Snapshot(filter QueryFilter) (Snapshot, error) {
+	snapshot := Snapshot{Bodies: make([]BodySnapshot, 0, len(w.Bodies))}
+
+	for _, body := range w.Bodies {
+		if !filter.accept(body) {
+			continue
+		}
+
+		bodySnapshot, err := snapshotBody(body)
+		if err != nil {
+			return Snapshot{}, err
+		}
+		snapshot.Bodies = append(snapshot.Bodies, bodySnapshot)
+	}
+
+	return snapshot, nil
+}
+
+// SnapshotRegion copies the state of the bodies whose AABB overlaps the region,
+// and accepted by the filter (nil for all the bodies). Planes are never part of a region.
+func (w *World) SnapshotRegion(region actor.AABB, filter QueryFilter) (Snapshot, error) {
+	return w.Snapshot(func(body *actor.RigidBody) bool {
+		if _, isPlane := body.Shape.(*actor.Plane); isPlane {
+			return false
+		}
+
+		return body.Shape.GetAABB().Overlaps(region) && filter.accept(body)
+	})
+}
+
+// LoadSnapshot merges the bodies of a snapshot into the world, next to the existing bodies.
+// The created bodies are returned in the snapshot order, so bodies[i] was created from
+// snapshot.Bodies[i]: callers use it to remap their own references to the new bodies.
+func (w *World) LoadSnapshot(snapshot Snapshot) ([]*actor.RigidBody, error) {
+	bodies := make([]*actor.RigidBody, len(snapshot.Bodies))
+
+	for i, bodySnapshot := range snapshot.Bodies {
+		if !bodySnapshot.BodyType.IsValid() {
+			return nil, fmt.Errorf("snapshot: %w %d", actor.ErrInvalidBodyType, bodySnapshot.BodyType)
+		}
+
+		shape, err := restoreShape(bodySnapshot.Shape)
+		if err != nil {
+			return nil, err
+		}
+
+		transform := bodySnapshot.Transform
+		transform.InverseRotation = transform.Rotation.Inverse()
+
+		body := actor.NewRigidBody(transform, shape, bodySnapshot.BodyType, bodySnapshot.Density)
+		body.Id = bodySnapshot.Id
+		body.Name = bodySnapshot.Name
+		if err := restoreMassData(body, bodySnapshot); err != nil {
+			return nil, err
+		}
+		if bodySnapshot.ShapeOffset.Rotation != (mgl64.Quat{}) {
+			body.SetShapeOffset(bodySnapshot.ShapeOffset.Position, bodySnapshot.ShapeOffset.Rotation)
+		}
+		body.Velocity = bodySnapshot.Velocity
+		body.AngularVelocity = bodySnapshot.AngularVelocity
+		body.IsTrigger = bodySnapshot.IsTrigger
+		body.MaxVelocity = bodySnapshot.MaxVelocity
+		body.MaxAngularVelocity = bodySnapshot.MaxAngularVelocity
+		body.SetAxisLocks(bodySnapshot.LinearLock, bodySnapshot.AngularLock)
+		// Static bodies never sleep, a sleeping static body is loaded awake
+		body.IsSleeping = bodySnapshot.IsSleeping && bodySnapshot.BodyType != actor.BodyTypeStatic
+		body.SetEnabled(!bodySnapshot.Disabled)
+		body.Material.Restitution = bodySnapshot.Restitution
+		body.Material.RestitutionCurve = bodySnapshot.RestitutionCurve
+		body.Material.StaticFriction = bodySnapshot.StaticFriction
+		body.Material.DynamicFriction = bodySnapshot.DynamicFriction
+		body.Material.SpinFriction = bodySnapshot.SpinFriction
+		body.Material.FrictionCombine = bodySnapshot.FrictionCombine
+		body.Material.RestitutionCombine = bodySnapshot.RestitutionCombine
+		body.Material.LinearDamping = bodySnapshot.LinearDamping
+		body.Material.AngularDamping = bodySnapshot.AngularDamping
+
+		bodies[i] = body
+	}
+
+	// Add the bodies once all of them are valid, a failed load leaves the world untouched
+	for _, body := range bodies {
+		w.AddBody(body)
+	}
+
+	return bodies, nil
+}
+
+// restoreMassData applies the overrides of the mass data. The saved Transform and ShapeOffset already
+// account for the center of mass: the transform moved by SetCenterOfMass is restored.
+func restoreMassData(body *actor.RigidBody, bodySnapshot BodySnapshot) error {
+	if bodySnapshot.Mass != 0 {
+		if err := body.SetMass(bodySnapshot.Mass); err != nil {
+			return fmt.Errorf("snapshot: %w", err)
+		}
+	}
+	if bodySnapshot.Inertia != (mgl64.Mat3{}) {
+		if err := body.SetInertia(bodySnapshot.Inertia); err != nil {
+			return fmt.Errorf("snapshot: %w", err)
+		}
+	}
+	if bodySnapshot.CenterOfMass != (mgl64.Vec3{}) {
+		transform := body.Transform
+		if err := body.SetCenterOfMass(bodySnapshot.CenterOfMass); err != nil {
+			return fmt.Errorf("snapshot: %w", err)
+		}
+		body.Transform, body.PreviousTransform = transform, transform
+		body.Shape.ComputeAABB(transform)
+	}
+
+	return nil
+}
+
+func snapshotBody(body *actor.RigidBody) (BodySnapshot, error) {
+	shape, err := snapshotShape(body.Shape)
+	if err != nil {
+		return BodySnapshot{}, err
+	}
+	linearLock, angularLock := body.AxisLocks()
+
+	return BodySnapshot{
+		Id:                 body.Id,
+		Name:               body.Name,
+		BodyType:           body.BodyType,
+		Transform:          body.Transform,
+		ShapeOffset:        body.ShapeOffset(),
+		Velocity:           body.Velocity,
+		AngularVelocity:    body.AngularVelocity,
+		IsTrigger:          body.IsTrigger,
+		IsSleeping:         body.IsSleeping,
+		Disabled:           !body.IsEnabled(),
+		LinearLock:         linearLock,
+		AngularLock:        angularLock,
+		MaxVelocity:        body.MaxVelocity,
+		MaxAngularVelocity: body.MaxAngularVelocity,
+		Density:            body.Material.Density,
+		Mass:               body.MassOverride(),
+		Inertia:            body.InertiaOverride(),
+		CenterOfMass:       body.CenterOfMass(),
+		Restitution:        body.Material.Restitution,
+		RestitutionCurve:   body.Material.RestitutionCurve,
+		StaticFriction:     body.Material.StaticFriction,
+		DynamicFriction:    body.Material.DynamicFriction,
+		LinearDamping:      body.Material.LinearDamping,
+		AngularDamping:     body.Material.AngularDamping,
+		Shape:              shape,
+		SpinFriction:       body.Material.SpinFriction,
+		FrictionCombine:    body.Material.FrictionCombine,
+		RestitutionCombine: body.Material.RestitutionCombine,
+	}, nil
+}
+
+func snapshotShape(shape actor.ShapeInterface) (ShapeSnapshot, error) {
+	snapshot, err := snapshotShapeParameters(shape)
+	snapshot.Margin = actor.ShapeMargin(shape)
+
+	return snapshot, err
+}
+
+func snapshotShapeParameters(shape actor.ShapeInterface) (ShapeSnapshot, error) {
+	switch s := shape.(type) {
+	case *actor.Box:
+		return ShapeSnapshot{Type: actor.ShapeTypeBox, HalfExtents: s.HalfExtents}, nil
+	case *actor.Sphere:
+		return ShapeSnapshot{Type: actor.ShapeTypeSphere, Radius: s.Radius}, nil
+	case *actor.Plane:
+		return ShapeSnapshot{Type: actor.ShapeTypePlane, Normal: s.Normal, Distance: s.Distance}, nil
+	case *actor.Cone:
+		return ShapeSnapshot{Type: actor.ShapeTypeCone, Radius: s.Radius, Height: s.Height}, nil
+	case *actor.TriangleMesh:
+		return ShapeSnapshot{
+			Type:     actor.ShapeTypeTriangleMesh,
+			Vertices: append([]mgl64.Vec3(nil), s.Vertices...),
+			Indices:  append([][3]int(nil), s.Indices...),
+		}, nil
+	case *actor.ConvexHull:
+		return ShapeSnapshot{Type: actor.ShapeTypeConvexHull, Vertices: append([]mgl64.Vec3(nil), s.Vertices...)}, nil
+	case *actor.Compound:
+		children := make([]ChildSnapshot, len(s.Children))
+		for i, child := range s.Children {
+			shape, err := snapshotShape(child.Shape)
+			if err != nil {
+				return ShapeSnapshot{}, err
+			}
+			children[i] = ChildSnapshot{Shape: shape, Position: child.Position, Rotation: child.Rotation, Trigger: child.Trigger}
+		}
+		return ShapeSnapshot{Type: actor.ShapeTypeCompound, Children: children}, nil
+	}
+
+	return ShapeSnapshot{}, fmt.Errorf("snapshot: unsupported shape %T", shape)
+}
+
+func restoreShape(shape ShapeSnapshot) (actor.ShapeInterface, error) {
+	restored, err := restoreShapeParameters(shape)
+	if err != nil {
+		return nil, err
+	}
+	if margined, ok := restored.(interface{ SetMargin(float64) }); ok {
+		margined.SetMargin(shape.Margin)
+	}
+
+	return restored, nil
+}
+
+func restoreShapeParameters(shape ShapeSnapshot) (actor.ShapeInterface, error) {
+	switch shape.Type {
+	case actor.ShapeTypeBox:
+		return &actor.Box{HalfExtents: shape.HalfExtents}, nil
+	case actor.ShapeTypeSphere:
+		return &actor.Sphere{Radius: shape.Radius}, nil
+	case actor.ShapeTypePlane:
+		return &actor.Plane{Normal: shape.Normal, Distance: shape.Distance}, nil
+	case actor.ShapeTypeCone:
+		return &actor.Cone{Radius: shape.Radius, Height: shape.Height}, nil
+	case actor.ShapeTypeConvexHull:
+		// The vertices are already centered on the center of mass, the hull is rebuilt from them
+		hull, err := actor.NewConvexHull(shape.Vertices)
+		if err != nil {
+			return nil, fmt.Errorf("snapshot: %w", err)
+		}
+		return hull, nil
+	case actor.ShapeTypeTriangleMesh:
+		mesh, err := actor.NewTriangleMesh(shape.Vertices, shape.Indices)
+		if err != nil {
+			return nil, fmt.Errorf("snapshot: %w", err)
+		}
+		return mesh, nil
+	case actor.ShapeTypeCompound:
+		// The children are already placed around the center of mass, the compound is rebuilt from them
+		children := make([]actor.CompoundChild, len(shape.Children))
+		for i, child := range shape.Children {
+			childShape, err := restoreShape(child.Shape)
+			if err != nil {
+				return nil, err
+			}
+			children[i] = actor.CompoundChild{Shape: childShape, Position: child.Position, Rotation: child.Rotation, Trigger: child.Trigger}
+		}
+		compound, err := actor.NewCompound(children)
+		if err != nil {
+			return nil, fmt.Errorf("snapshot: %w", err)
+		}
+		return compound, nil
+	}
+
+	return nil, fmt.Errorf("snapshot: unsupported shape type %d", shape.Type)
+}
@@ -0,0 +1,345 @@
+package feather
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestSnapshotRegion(t *testing.T) {
+	world := &World{Events: NewEvents()}
+	inside := createBox(mgl64.Vec3{1, 0, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+	crossing := createSphere(mgl64.Vec3{10.2, 0, 0}, 0.5, actor.BodyTypeDynamic)
+	outside := createSphere(mgl64.Vec3{30, 0, 0}, 0.5, actor.BodyTypeDynamic)
+	world.AddBody(createPlane(mgl64.Vec3{0, 1, 0}, 0))
+	world.AddBody(inside)
+	world.AddBody(crossing)
+	world.AddBody(outside)
+
+	snapshot, err := world.SnapshotRegion(actor.AABB{Min: mgl64.Vec3{0, -10, -10}, Max: mgl64.Vec3{10, 10, 10}}, nil)
+	if err != nil {
+		t.Fatalf("SnapshotRegion failed: %v", err)
+	}
+
+	if len(snapshot.Bodies) != 2 {
+		t.Fatalf("expected 2 bodies in the region, got %d", len(snapshot.Bodies))
+	}
+	if snapshot.Bodies[0].Shape.Type != actor.ShapeTypeBox || snapshot.Bodies[1].Shape.Type != actor.ShapeTypeSphere {
+		t.Errorf("unexpected shapes in the region: %v", snapshot.Bodies)
+	}
+}
+
+func TestSnapshot_Filter(t *testing.T) {
+	world := &World{Events: NewEvents()}
+	for i := 0; i < 4; i++ {
+		body := createSphere(mgl64.Vec3{float64(i) * 3, 0, 0}, 1, actor.BodyTypeDynamic)
+		body.Id = i
+		world.AddBody(body)
+	}
+
+	snapshot, err := world.Snapshot(func(body *actor.RigidBody) bool {
+		return body.Id.(int)%2 == 0
+	})
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	if len(snapshot.Bodies) != 2 || snapshot.Bodies[0].Id != 0 || snapshot.Bodies[1].Id != 2 {
+		t.Errorf("expected bodies 0 and 2, got %v", snapshot.Bodies)
+	}
+}
+
+func TestLoadSnapshot_MergeRoundTrip(t *testing.T) {
+	source := &World{Events: NewEvents()}
+	box := actor.NewRigidBody(
+		actor.Transform{Position: mgl64.Vec3{1, 2, 3}, Rotation: mgl64.QuatRotate(0.5, mgl64.Vec3{0, 1, 0})},
+		&actor.Box{HalfExtents: mgl64.Vec3{1, 2, 0.5}},
+		actor.BodyTypeDynamic,
+		700,
+	)
+	box.Id = "crate"
+	box.Name = "crate_stack_07"
+	box.SetShapeOffset(mgl64.Vec3{0, 2, 0}, mgl64.QuatIdent())
+	box.Velocity = mgl64.Vec3{0, -1, 0}
+	box.AngularVelocity = mgl64.Vec3{0, 2, 0}
+	box.Material.Restitution = 0.4
+	box.Material.RestitutionCurve = actor.RestitutionCurve{MinSpeed: 0.5, MaxSpeed: 2}
+	box.Material.StaticFriction = 0.6
+	box.Material.DynamicFriction = 0.5
+	box.Material.SpinFriction = 0.02
+	box.Material.FrictionCombine = actor.CombineMax
+	box.Material.RestitutionCombine = actor.CombineMin
+	source.AddBody(box)
+	source.AddBody(createPlane(mgl64.Vec3{0, 1, 0}, 0))
+
+	snapshot, err := source.Snapshot(nil)
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		t.Fatalf("json.Marshal failed: %v", err)
+	}
+	var decoded Snapshot
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal failed: %v", err)
+	}
+
+	// Merge into a world that already holds a body
+	target := &World{Events: NewEvents()}
+	existing := createSphere(mgl64.Vec3{}, 1, actor.BodyTypeDynamic)
+	target.AddBody(existing)
+
+	bodies, err := target.LoadSnapshot(decoded)
+	if err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+
+	if len(target.Bodies) != 3 || target.Bodies[0] != existing {
+		t.Fatalf("expected the snapshot to be merged after the existing body, got %d bodies", len(target.Bodies))
+	}
+	if len(bodies) != 2 || target.Bodies[1] != bodies[0] || target.Bodies[2] != bodies[1] {
+		t.Fatal("LoadSnapshot should return the created bodies in snapshot order")
+	}
+
+	loaded := bodies[0]
+	if loaded.Id != "crate" {
+		t.Errorf("Id = %v, want crate", loaded.Id)
+	}
+	if loaded.Name != "crate_stack_07" {
+		t.Errorf("Name = %q, want crate_stack_07", loaded.Name)
+	}
+	if loaded.ShapeOffset().Position != (mgl64.Vec3{0, 2, 0}) {
+		t.Errorf("ShapeOffset = %+v, want {0, 2, 0}", loaded.ShapeOffset())
+	}
+	if !vec3ApproxEqual(loaded.Transform.Position, box.Transform.Position, 1e-12) {
+		t.Errorf("Position = %v, want %v", loaded.Transform.Position, box.Transform.Position)
+	}
+	if !loaded.Transform.Rotation.ApproxEqual(box.Transform.Rotation) {
+		t.Errorf("Rotation = %v, want %v", loaded.Transform.Rotation, box.Transform.Rotation)
+	}
+	if loaded.Velocity != box.Velocity || loaded.AngularVelocity != box.AngularVelocity {
+		t.Errorf("velocities not restored: %v %v", loaded.Velocity, loaded.AngularVelocity)
+	}
+	if loaded.Material.GetMass() != box.Material.GetMass() {
+		t.Errorf("mass = %v, want %v", loaded.Material.GetMass(), box.Material.GetMass())
+	}
+	if loaded.Material.Restitution != 0.4 || loaded.Material.StaticFriction != 0.6 || loaded.Material.DynamicFriction != 0.5 || loaded.Material.SpinFriction != 0.02 {
+		t.Errorf("material not restored: %+v", loaded.Material)
+	}
+	if loaded.Material.FrictionCombine != actor.CombineMax || loaded.Material.RestitutionCombine != actor.CombineMin {
+		t.Errorf("combine modes not restored: %+v", loaded.Material)
+	}
+	if loaded.Material.RestitutionCurve != box.Material.RestitutionCurve {
+		t.Errorf("RestitutionCurve = %+v, want %+v", loaded.Material.RestitutionCurve, box.Material.RestitutionCurve)
+	}
+	if plane, ok := bodies[1].Shape.(*actor.Plane); !ok || plane.Normal != (mgl64.Vec3{0, 1, 0}) {
+		t.Errorf("plane not restored: %+v", bodies[1].Shape)
+	}
+}
+
+func TestLoadSnapshot_InvalidShape(t *testing.T) {
+	world := &World{Events: NewEvents()}
+	snapshot := Snapshot{Bodies: []BodySnapshot{
+		{Shape: ShapeSnapshot{Type: actor.ShapeTypeSphere, Radius: 1}},
+		{Shape: ShapeSnapshot{Type: actor.ShapeType(99)}},
+	}}
+
+	if _, err := world.LoadSnapshot(snapshot); err == nil {
+		t.Error("expected an error for an unknown shape type")
+	}
+	if len(world.Bodies) != 0 {
+		t.Errorf("a failed load should not add bodies, got %d", len(world.Bodies))
+	}
+}
+
+func TestLoadSnapshot_BodyFlags(t *testing.T) {
+	world := &World{Events: NewEvents()}
+	snapshot := Snapshot{Bodies: []BodySnapshot{
+		{Shape: ShapeSnapshot{Type: actor.ShapeTypeSphere, Radius: 1}, BodyType: actor.BodyTypeStatic, IsSleeping: true},
+		{Shape: ShapeSnapshot{Type: actor.ShapeTypeSphere, Radius: 1}, BodyType: actor.BodyTypeDynamic, Density: 1, Disabled: true},
+	}}
+
+	bodies, err := world.LoadSnapshot(snapshot)
+	if err != nil {
+		t.Fatalf("LoadSnapshot: %v", err)
+	}
+	if bodies[0].IsSleeping {
+		t.Error("a static body should be loaded awake")
+	}
+	if !bodies[0].IsEnabled() || bodies[1].IsEnabled() {
+		t.Errorf("enabled = %v and %v, want the disabled body loaded disabled", bodies[0].IsEnabled(), bodies[1].IsEnabled())
+	}
+	if resaved, _ := world.Snapshot(nil); !resaved.Bodies[1].Disabled {
+		t.Error("the disabled body should be saved disabled")
+	}
+
+	snapshot.Bodies[0].BodyType = actor.BodyType(42)
+	if _, err := world.LoadSnapshot(snapshot); !errors.Is(err, actor.ErrInvalidBodyType) {
+		t.Errorf("LoadSnapshot = %v, want ErrInvalidBodyType", err)
+	}
+}
+
+func TestSnapshot_Cone(t *testing.T) {
+	source := &World{Events: NewEvents()}
+	source.AddBody(actor.NewRigidBody(actor.NewTransform(), &actor.Cone{Radius: 0.5, Height: 2}, actor.BodyTypeDynamic, 10))
+
+	snapshot, err := source.Snapshot(nil)
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	target := &World{Events: NewEvents()}
+	bodies, err := target.LoadSnapshot(snapshot)
+	if err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+	if cone, ok := bodies[0].Shape.(*actor.Cone); !ok || cone.Radius != 0.5 || cone.Height != 2 {
+		t.Errorf("cone not restored: %+v", bodies[0].Shape)
+	}
+}
+
+func TestSnapshot_ConvexHull(t *testing.T) {
+	hull, err := actor.NewConvexHull([]mgl64.Vec3{{0, 0, 0}, {2, 0, 0}, {0, 2, 0}, {0, 0, 2}, {2, 2, 2}})
+	if err != nil {
+		t.Fatalf("NewConvexHull failed: %v", err)
+	}
+	hull.SetMargin(0.02)
+	source := &World{Events: NewEvents()}
+	original := actor.NewRigidBody(actor.NewTransform(), hull, actor.BodyTypeDynamic, 10)
+	source.AddBody(original)
+
+	snapshot, err := source.Snapshot(nil)
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	target := &World{Events: NewEvents()}
+	bodies, err := target.LoadSnapshot(snapshot)
+	if err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+	restored, ok := bodies[0].Shape.(*actor.ConvexHull)
+	if !ok || len(restored.Vertices) != len(hull.Vertices) {
+		t.Fatalf("convex hull not restored: %+v", bodies[0].Shape)
+	}
+	if restored.Margin() != hull.Margin() {
+		t.Errorf("margin = %v, want %v", restored.Margin(), hull.Margin())
+	}
+	if restored.CenterOfMass.Len() > 1e-9 {
+		t.Errorf("restored vertices should already be centered, got offset %v", restored.CenterOfMass)
+	}
+	if math.Abs(bodies[0].Material.GetMass()-original.Material.GetMass()) > 1e-9 {
+		t.Errorf("mass = %v, want %v", bodies[0].Material.GetMass(), original.Material.GetMass())
+	}
+}
+
+func TestSnapshot_TriangleMesh(t *testing.T) {
+	mesh, err := actor.NewTriangleMesh([]mgl64.Vec3{{0, 0, 0}, {0, 0, 1}, {1, 0, 0}, {1, 0, 1}}, [][3]int{{0, 1, 2}, {2, 1, 3}})
+	if err != nil {
+		t.Fatalf("NewTriangleMesh failed: %v", err)
+	}
+	source := &World{Events: NewEvents()}
+	source.AddBody(actor.NewRigidBody(actor.NewTransform(), mesh, actor.BodyTypeStatic, 0))
+
+	snapshot, err := source.Snapshot(nil)
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	target := &World{Events: NewEvents()}
+	bodies, err := target.LoadSnapshot(snapshot)
+	if err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+	restored, ok := bodies[0].Shape.(*actor.TriangleMesh)
+	if !ok || len(restored.Vertices) != 4 || len(restored.Indices) != 2 {
+		t.Fatalf("triangle mesh not restored: %+v", bodies[0].Shape)
+	}
+	if len(restored.Overlapping(restored.GetAABB(), bodies[0].Transform, nil)) != 2 {
+		t.Error("expected the BVH of the restored mesh to be built")
+	}
+}
+
+func TestSnapshot_MassData(t *testing.T) {
+	source := &World{Events: NewEvents()}
+	original := createBox(mgl64.Vec3{1, 2, 3}, mgl64.Vec3{1, 0.5, 1}, actor.BodyTypeDynamic)
+	original.Transform.Rotation = mgl64.QuatRotate(0.5, mgl64.Vec3{1, 0, 0})
+	original.Transform.InverseRotation = original.Transform.Rotation.Inverse()
+	original.SetShapeOffset(mgl64.Vec3{0, 0.5, 0}, mgl64.QuatIdent())
+	if err := original.SetMass(20); err != nil {
+		t.Fatal(err)
+	}
+	if err := original.SetInertia(mgl64.Diag3(mgl64.Vec3{4, 5, 6})); err != nil {
+		t.Fatal(err)
+	}
+	if err := original.SetCenterOfMass(mgl64.Vec3{0, -0.3, 0}); err != nil {
+		t.Fatal(err)
+	}
+	source.AddBody(original)
+
+	snapshot, err := source.Snapshot(nil)
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	bodies, err := (&World{Events: NewEvents()}).LoadSnapshot(snapshot)
+	if err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+
+	restored := bodies[0]
+	if restored.Material.GetMass() != 20 || restored.InertiaLocal != original.InertiaLocal || restored.CenterOfMass() != original.CenterOfMass() {
+		t.Errorf("mass %v, inertia %v, center of mass %v, want the overrides restored",
+			restored.Material.GetMass(), restored.InertiaLocal, restored.CenterOfMass())
+	}
+	if !vec3ApproxEqual(restored.Transform.Position, original.Transform.Position, 1e-9) ||
+		!vec3ApproxEqual(restored.OriginTransform().Position, original.OriginTransform().Position, 1e-9) {
+		t.Errorf("center of mass at %v, origin at %v, want %v and %v", restored.Transform.Position,
+			restored.OriginTransform().Position, original.Transform.Position, original.OriginTransform().Position)
+	}
+	if got, want := restored.Shape.GetAABB(), original.Shape.GetAABB(); !vec3ApproxEqual(got.Min, want.Min, 1e-9) || !vec3ApproxEqual(got.Max, want.Max, 1e-9) {
+		t.Errorf("AABB %v, want the shape in place at %v", got, want)
+	}
+}
+
+func TestSnapshot_Compound(t *testing.T) {
+	compound, err := actor.NewCompound([]actor.CompoundChild{
+		{Shape: &actor.Box{HalfExtents: mgl64.Vec3{1, 0.1, 1}}, Position: mgl64.Vec3{0, 1, 0}},
+		{Shape: &actor.Sphere{Radius: 0.5}, Position: mgl64.Vec3{0, -1, 0}, Rotation: mgl64.QuatRotate(0.3, mgl64.Vec3{1, 0, 0}), Trigger: true},
+	})
+	if err != nil {
+		t.Fatalf("NewCompound failed: %v", err)
+	}
+	source := &World{Events: NewEvents()}
+	original := actor.NewRigidBody(actor.NewTransform(), compound, actor.BodyTypeDynamic, 10)
+	source.AddBody(original)
+
+	snapshot, err := source.Snapshot(nil)
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	target := &World{Events: NewEvents()}
+	bodies, err := target.LoadSnapshot(snapshot)
+	if err != nil {
+		t.Fatalf("LoadSnapshot failed: %v", err)
+	}
+	restored, ok := bodies[0].Shape.(*actor.Compound)
+	if !ok || len(restored.Children) != 2 {
+		t.Fatalf("compound not restored: %+v", bodies[0].Shape)
+	}
+	if _, ok := restored.Children[1].Shape.(*actor.Sphere); !ok || restored.Children[1].Rotation != compound.Children[1].Rotation || !restored.Children[1].Trigger {
+		t.Errorf("child not restored: %+v", restored.Children[1])
+	}
+	if restored.CenterOfMass.Len() > 1e-9 {
+		t.Errorf("restored children should already be centered, got offset %v", restored.CenterOfMass)
+	}
+	if math.Abs(bodies[0].Material.GetMass()-original.Material.GetMass()) > 1e-9 {
+		t.Errorf("mass = %v, want %v", bodies[0].Material.GetMass(), original.Material.GetMass())
+	}
+}
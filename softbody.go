@@ -0,0 +1,165 @@
+package feather
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/akmonengine/feather/constraint"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// ErrInvalidSoftBody is returned by AddSoftBody for a mesh which is not closed or wound outward, or without mass or radius
+var ErrInvalidSoftBody = errors.New("feather: soft body requires a closed triangle mesh wound outward, a mass and a radius")
+
+// SoftBodySettings describes a soft body built by World.AddSoftBody
+type SoftBodySettings struct {
+	// Mass (kg) of the whole soft body, shared by its particles
+	Mass float64
+	// Radius (m) of the particles, kept from the surfaces of the rigid bodies
+	Radius float64
+	// StretchCompliance, BendCompliance and VolumeCompliance are the inverse stiffness of the edges,
+	// of the bending across the edges and of the volume, 0 is rigid
+	StretchCompliance float64
+	BendCompliance    float64
+	VolumeCompliance  float64
+	// Pressure scales the kept volume, 0 is 1: above 1 the soft body inflates, under 1 it deflates
+	Pressure float64
+	// Friction is the coefficient of the particles sliding on the rigid bodies
+	Friction float64
+}
+
+// SoftBody is a deformable prop (experimental): the vertices of a closed triangle mesh are particles,
+// kept together by constraints along the edges, across the edges and on the enclosed volume.
+// Its particles collide with the rigid bodies, but not with the other soft bodies nor themselves.
+type SoftBody struct {
+	Particles []*actor.Particle
+	// Triangles index Particles, wound counterclockwise seen from the outside
+	Triangles [][3]int
+
+	// Edges keep the length of the edges, Bends the distance between the opposite vertices of adjacent triangles
+	Edges  []*constraint.ParticleDistance
+	Bends  []*constraint.ParticleDistance
+	Volume *constraint.ParticleVolume
+
+	// Radius and Friction of the particles, see SoftBodySettings
+	Radius   float64
+	Friction float64
+
+	// proxies are the spheres of the particles, given to the narrow phase against the rigid bodies
+	proxies []*actor.RigidBody
+}
+
+// AddSoftBody builds a soft body from a closed triangle mesh (e.g. a sphere or a box tessellated), wound outward,
+// and adds its particles and constraints to the world. The caller owns them, RemoveSoftBody removes them.
+func (w *World) AddSoftBody(vertices []mgl64.Vec3, triangles [][3]int, settings SoftBodySettings) (*SoftBody, error) {
+	if len(vertices) == 0 || !(settings.Mass > 0) || !(settings.Radius > 0) {
+		return nil, ErrInvalidSoftBody
+	}
+
+	// Each edge of a closed mesh is shared by two triangles, whose opposite vertices are bent around it
+	opposites := make(map[[2]int][]int)
+	for _, triangle := range triangles {
+		for i := range 3 {
+			a, b, opposite := triangle[i], triangle[(i+1)%3], triangle[(i+2)%3]
+			if a < 0 || a >= len(vertices) || b < 0 || b >= len(vertices) {
+				return nil, ErrInvalidSoftBody
+			}
+			edge := [2]int{min(a, b), max(a, b)}
+			opposites[edge] = append(opposites[edge], opposite)
+		}
+	}
+	edges := make([][2]int, 0, len(opposites))
+	for edge, vertices := range opposites {
+		if len(vertices) != 2 {
+			return nil, ErrInvalidSoftBody
+		}
+		edges = append(edges, edge)
+	}
+	// The map is walked in any order, the constraints are solved in the order of the edges
+	slices.SortFunc(edges, func(a, b [2]int) int {
+		return cmp.Or(cmp.Compare(a[0], b[0]), cmp.Compare(a[1], b[1]))
+	})
+
+	softBody := &SoftBody{
+		Particles: make([]*actor.Particle, len(vertices)),
+		Triangles: triangles,
+		Radius:    settings.Radius,
+		Friction:  settings.Friction,
+	}
+	for i, vertex := range vertices {
+		softBody.Particles[i] = actor.NewParticle(vertex, settings.Mass/float64(len(vertices)))
+	}
+	softBody.proxies = newParticleProxies(softBody.Particles, settings.Radius)
+
+	softBody.Volume = constraint.NewParticleVolume(softBody.Particles, triangles, settings.VolumeCompliance)
+	if !(softBody.Volume.RestVolume > 0) {
+		return nil, ErrInvalidSoftBody
+	}
+	if settings.Pressure > 0 {
+		softBody.Volume.Pressure = settings.Pressure
+	}
+	for _, edge := range edges {
+		particles := softBody.Particles
+		softBody.Edges = append(softBody.Edges, constraint.NewParticleDistance(particles[edge[0]], particles[edge[1]], settings.StretchCompliance))
+		bend := opposites[edge]
+		softBody.Bends = append(softBody.Bends, constraint.NewParticleDistance(particles[bend[0]], particles[bend[1]], settings.BendCompliance))
+	}
+
+	for _, particle := range softBody.Particles {
+		w.AddParticle(particle)
+	}
+	for _, c := range softBody.Edges {
+		w.AddConstraint(c)
+	}
+	for _, c := range softBody.Bends {
+		w.AddConstraint(c)
+	}
+	w.AddConstraint(softBody.Volume)
+	w.softBodies = append(w.softBodies, softBody)
+
+	return softBody, nil
+}
+
+// RemoveSoftBody removes the soft body, its particles and its constraints from the world
+func (w *World) RemoveSoftBody(softBody *SoftBody) {
+	index := slices.Index(w.softBodies, softBody)
+	if index < 0 {
+		return
+	}
+	w.softBodies = slices.Delete(w.softBodies, index, index+1)
+
+	for _, particle := range softBody.Particles {
+		w.RemoveParticle(particle)
+	}
+	owned := make(map[constraint.Constraint]bool, len(softBody.Edges)+len(softBody.Bends)+1)
+	for _, c := range softBody.Edges {
+		owned[c] = true
+	}
+	for _, c := range softBody.Bends {
+		owned[c] = true
+	}
+	owned[softBody.Volume] = true
+	w.constraints = slices.DeleteFunc(w.constraints, func(c constraint.Constraint) bool {
+		return owned[c]
+	})
+}
+
+// SoftBodies returns the soft bodies of the world
+func (w *World) SoftBodies() []*SoftBody {
+	return w.softBodies
+}
+
+// collideSoftBodies returns the user constraints with the contacts of the particles of the soft bodies,
+// which are solved before the islands as they may move any body
+func (w *World) collideSoftBodies(userConstraints []constraint.Constraint) []constraint.Constraint {
+	result := userConstraints[:len(userConstraints):len(userConstraints)]
+	for _, softBody := range w.softBodies {
+		for _, c := range w.collideParticles(softBody.Particles, softBody.proxies, softBody.Radius, softBody.Friction) {
+			result = append(result, c)
+		}
+	}
+
+	return result
+}
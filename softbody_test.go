@@ -0,0 +1,134 @@
+package feather
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// cubeMesh returns the vertices and the outward triangles of a cube
+func cubeMesh(center mgl64.Vec3, halfExtent float64) ([]mgl64.Vec3, [][3]int) {
+	vertices := make([]mgl64.Vec3, 8)
+	for i := range vertices {
+		// The bits of i select the sides along X, Y and Z
+		for axis := range 3 {
+			offset := -halfExtent
+			if i&(1<<axis) != 0 {
+				offset = halfExtent
+			}
+			vertices[i][axis] = center[axis] + offset
+		}
+	}
+
+	return vertices, [][3]int{
+		{0, 4, 6}, {0, 6, 2}, {1, 3, 7}, {1, 7, 5},
+		{0, 1, 5}, {0, 5, 4}, {2, 6, 7}, {2, 7, 3},
+		{0, 2, 3}, {0, 3, 1}, {4, 5, 7}, {4, 7, 6},
+	}
+}
+
+func TestWorld_AddSoftBody_Invalid(t *testing.T) {
+	world := NewWorld()
+	vertices, triangles := cubeMesh(mgl64.Vec3{}, 0.5)
+	settings := SoftBodySettings{Mass: 1, Radius: 0.05}
+
+	inward := make([][3]int, len(triangles))
+	for i, triangle := range triangles {
+		inward[i] = [3]int{triangle[0], triangle[2], triangle[1]}
+	}
+	for name, mesh := range map[string][][3]int{
+		"open":         triangles[:11],
+		"wound inward": inward,
+		"out of range": append(triangles[:11:11], [3]int{4, 7, 8}),
+	} {
+		if _, err := world.AddSoftBody(vertices, mesh, settings); !errors.Is(err, ErrInvalidSoftBody) {
+			t.Errorf("%s: err = %v, want ErrInvalidSoftBody", name, err)
+		}
+	}
+	if _, err := world.AddSoftBody(vertices, triangles, SoftBodySettings{Radius: 0.05}); !errors.Is(err, ErrInvalidSoftBody) {
+		t.Errorf("no mass: err = %v, want ErrInvalidSoftBody", err)
+	}
+	if len(world.particles) != 0 || len(world.constraints) != 0 || len(world.SoftBodies()) != 0 {
+		t.Errorf("%d particles and %d constraints added by invalid soft bodies", len(world.particles), len(world.constraints))
+	}
+}
+
+func TestWorld_SoftBody(t *testing.T) {
+	world := NewWorld(WithSubsteps(8))
+	ground := createBox(mgl64.Vec3{0, -0.5, 0}, mgl64.Vec3{5, 0.5, 5}, actor.BodyTypeStatic)
+	world.AddBody(ground)
+
+	vertices, triangles := cubeMesh(mgl64.Vec3{0, 1.5, 0}, 0.5)
+	softBody, err := world.AddSoftBody(vertices, triangles, SoftBodySettings{
+		Mass:              2,
+		Radius:            0.05,
+		StretchCompliance: 1e-4,
+		BendCompliance:    1e-3,
+		Friction:          0.5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(softBody.Edges) != 18 || len(softBody.Bends) != 18 {
+		t.Fatalf("%d edges and %d bends, want the 18 edges of the triangulated cube", len(softBody.Edges), len(softBody.Bends))
+	}
+
+	// Dropped on the ground, the soft body lands and rests on it, keeping its volume
+	for range 180 {
+		world.Step(1.0 / 60.0)
+	}
+	for i, particle := range softBody.Particles {
+		if particle.Position.Y() < 0.05-0.01 {
+			t.Errorf("particle %d at %v, sunk into the ground", i, particle.Position)
+		}
+		if particle.Velocity.Len() > 0.05 {
+			t.Errorf("particle %d moving at %v, want at rest", i, particle.Velocity)
+		}
+	}
+	if ratio := softBody.Volume.Volume() / softBody.Volume.RestVolume; math.Abs(ratio-1) > 0.1 {
+		t.Errorf("volume ratio = %v, want kept", ratio)
+	}
+
+	// Removed, its particles and constraints leave the world
+	world.RemoveSoftBody(softBody)
+	if len(world.particles) != 0 || len(world.constraints) != 0 || len(world.SoftBodies()) != 0 {
+		t.Errorf("%d particles and %d constraints left by the removed soft body", len(world.particles), len(world.constraints))
+	}
+}
+
+func TestWorld_SoftBodyOnBody(t *testing.T) {
+	world := NewWorld(WithSubsteps(8))
+	world.AddBody(createPlane(mgl64.Vec3{0, 1, 0}, 0))
+	crate := actor.NewRigidBodyWithMass(actor.Transform{Position: mgl64.Vec3{0, 0.25, 0}, Rotation: mgl64.QuatIdent()},
+		&actor.Box{HalfExtents: mgl64.Vec3{0.25, 0.25, 0.25}}, actor.BodyTypeDynamic, 5)
+	world.AddBody(crate)
+
+	// The crate falls asleep before the soft body lands on it, the impact wakes it up
+	vertices, triangles := cubeMesh(mgl64.Vec3{0, 1.2, 0}, 0.2)
+	softBody, err := world.AddSoftBody(vertices, triangles, SoftBodySettings{Mass: 1, Radius: 0.05, Friction: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var slept, woken bool
+	for range 120 {
+		world.Step(1.0 / 60.0)
+		slept = slept || crate.IsSleeping
+		woken = woken || (slept && !crate.IsSleeping)
+	}
+	if !slept || !woken {
+		t.Errorf("crate slept %v, woken %v, want woken up by the soft body", slept, woken)
+	}
+
+	// The soft body rests on the crate, which holds it on the plane
+	for i, particle := range softBody.Particles {
+		if particle.Position.Y() < 0.55-0.01 {
+			t.Errorf("particle %d at %v, sunk into the crate", i, particle.Position)
+		}
+	}
+	if crate.Transform.Position.Sub(mgl64.Vec3{0, 0.25, 0}).Len() > 0.02 {
+		t.Errorf("crate at %v, want held on the plane under the soft body", crate.Transform.Position)
+	}
+}
@@ -3,7 +3,7 @@ package feather
 import (
 	"math"
 	"sort"
-	"sync"
+	"unsafe"
 
 	"github.com/akmonengine/feather/actor"
 	"github.com/go-gl/mathgl/mgl64"
@@ -19,17 +19,65 @@ type Cell struct {
 	bodyIndices []int
 }
 
-// Pair - Pair of bodies potentially in collision
+// Pair - Pair of bodies potentially in collision.
+// The broad phase orders the bodies for the narrow phase (e.g. the plane or the mesh first),
+// the engine keys its per pair state (events, material and normal caches) by OrderPair.
 type Pair struct {
 	BodyA *actor.RigidBody
 	BodyB *actor.RigidBody
 }
 
-// SpatialGrid - Uniform spatial grid with hashing for broad phase
+// OrderPair - Returns the pair in the canonical order of the engine, the same for (a, b) and (b, a).
+// User maps keyed by OrderPair match the keys of the engine. The order is by address:
+// it is stable while the bodies live, not from one run to another.
+func OrderPair(bodyA, bodyB *actor.RigidBody) Pair {
+	if uintptr(unsafe.Pointer(bodyB)) < uintptr(unsafe.Pointer(bodyA)) {
+		bodyA, bodyB = bodyB, bodyA
+	}
+
+	return Pair{BodyA: bodyA, BodyB: bodyB}
+}
+
+// Ordered - Returns the pair in the canonical order, see OrderPair
+func (p Pair) Ordered() Pair {
+	return OrderPair(p.BodyA, p.BodyB)
+}
+
+// GRID_LEVEL_RATIO is the ratio between the cell sizes of two successive levels of a hierarchical SpatialGrid
+const GRID_LEVEL_RATIO = 8.0
+
+const (
+	// GRID_CELLS_PER_TASK and GRID_BODIES_PER_TASK size the tasks of FindPairsParallel shared by the workers
+	GRID_CELLS_PER_TASK  = 256
+	GRID_BODIES_PER_TASK = 256
+)
+
+// SpatialGrid - Spatial grid with hashing for broad phase, uniform or hierarchical.
+// A hierarchical grid chains coarser levels, each one with cells GRID_LEVEL_RATIO times larger:
+// a body is stored in the finest level whose cells are at least as large as its AABB, so it occupies
+// at most 8 cells. Huge bodies (platforms, terrain blocks) stay in a few coarse cells,
+// while the small ones keep a fine culling.
 type SpatialGrid struct {
 	cellSize float64
 	cells    []Cell
 	planes   Cell
+	// Triangle meshes span too many cells, they are tested against every body by their AABB
+	meshes Cell
+	// coarser is the next level of a hierarchical grid, nil for the coarsest (or a uniform grid)
+	coarser *SpatialGrid
+	// Pairs of each task of FindPairsParallel, reused from one step to the next
+	pairBuffers [][]Pair
+
+	// Cells occupied by the body of each index at the last Update
+	proxies []gridProxy
+}
+
+// gridProxy - Cells occupied by a body indexed by Update: it is reinserted only when they change.
+// The level is nil for the planes and the meshes.
+type gridProxy struct {
+	body             *actor.RigidBody
+	level            *SpatialGrid
+	minCell, maxCell CellKey
 }
 
 // NewSpatialGrid - Creates a new spatial grid
@@ -45,25 +93,67 @@ func NewSpatialGrid(cellSize float64, numCells int) *SpatialGrid {
 	}
 }
 
-// Insert - Inserts a body into all cells it occupies
+// NewHierarchicalSpatialGrid - Creates a spatial grid of levels (at least 1), the finest one with cells of cellSize.
+// Each level holds numCells cells.
+func NewHierarchicalSpatialGrid(cellSize float64, numCells int, levels int) *SpatialGrid {
+	grid := NewSpatialGrid(cellSize, numCells)
+	level := grid
+	for i := 1; i < levels; i++ {
+		level.coarser = NewSpatialGrid(level.cellSize*GRID_LEVEL_RATIO, numCells)
+		level = level.coarser
+	}
+
+	return grid
+}
+
+// Levels - Returns the number of levels of the grid, 1 for a uniform grid
+func (sg *SpatialGrid) Levels() int {
+	levels := 0
+	for level := sg; level != nil; level = level.coarser {
+		levels++
+	}
+
+	return levels
+}
+
+// levelOf - Returns the level storing a body of this AABB: the finest one whose cells are at least as large,
+// or the coarsest one
+func (sg *SpatialGrid) levelOf(aabb actor.AABB) *SpatialGrid {
+	size := aabb.Max.Sub(aabb.Min)
+	extent := math.Max(size.X(), math.Max(size.Y(), size.Z()))
+
+	level := sg
+	for level.coarser != nil && extent > level.cellSize {
+		level = level.coarser
+	}
+
+	return level
+}
+
+// Insert - Inserts a body into all cells it occupies, in its level
 func (sg *SpatialGrid) Insert(bodyIndex int, body *actor.RigidBody) {
 	if _, ok := body.Shape.(*actor.Plane); ok {
 		sg.planes.bodyIndices = append(sg.planes.bodyIndices, bodyIndex)
 		return
 	}
+	if isMesh(body) {
+		sg.meshes.bodyIndices = append(sg.meshes.bodyIndices, bodyIndex)
+		return
+	}
 
-	aabb := body.Shape.GetAABB()
-	minCell := sg.worldToCell(aabb.Min)
-	maxCell := sg.worldToCell(aabb.Max)
+	aabb := body.BroadPhaseAABB()
+	level := sg.levelOf(aabb)
+	minCell := level.worldToCell(aabb.Min)
+	maxCell := level.worldToCell(aabb.Max)
 
 	for x := minCell.X; x <= maxCell.X; x++ {
 		for y := minCell.Y; y <= maxCell.Y; y++ {
 			for z := minCell.Z; z <= maxCell.Z; z++ {
 				cellKey := CellKey{x, y, z}
-				cellIdx := sg.hashCell(cellKey)
+				cellIdx := level.hashCell(cellKey)
 
-				sg.cells[cellIdx].bodyIndices = append(
-					sg.cells[cellIdx].bodyIndices,
+				level.cells[cellIdx].bodyIndices = append(
+					level.cells[cellIdx].bodyIndices,
 					bodyIndex,
 				)
 			}
@@ -71,96 +161,379 @@ func (sg *SpatialGrid) Insert(bodyIndex int, body *actor.RigidBody) {
 	}
 }
 
-// Clear - Resets the spatial grid by clearing all body indices from cells and planes
+// Clear - Resets the spatial grid by clearing all body indices from cells of all levels, planes and meshes
 func (sg *SpatialGrid) Clear() {
 	sg.planes.bodyIndices = sg.planes.bodyIndices[:0]
+	sg.meshes.bodyIndices = sg.meshes.bodyIndices[:0]
+	clear(sg.proxies)
+	sg.proxies = sg.proxies[:0]
 
-	for i := range sg.cells {
-		sg.cells[i].bodyIndices = sg.cells[i].bodyIndices[:0]
+	for level := sg; level != nil; level = level.coarser {
+		for i := range level.cells {
+			level.cells[i].bodyIndices = level.cells[i].bodyIndices[:0]
+		}
 	}
 }
 
-// SortCells - Sorts body indices within each cell for optimized collision detection
+// SortCells - Sorts body indices within each cell of all levels for optimized collision detection
 func (sg *SpatialGrid) SortCells() {
-	for i := range sg.cells {
-		if len(sg.cells[i].bodyIndices) > 1 {
-			sort.Ints(sg.cells[i].bodyIndices)
+	for level := sg; level != nil; level = level.coarser {
+		for i := range level.cells {
+			if len(level.cells[i].bodyIndices) > 1 {
+				sort.Ints(level.cells[i].bodyIndices)
+			}
+		}
+	}
+}
+
+// Update - Indexes the bodies incrementally: a body is reinserted only when its AABB crosses a cell boundary
+// (or changes level), or when its index changes (e.g. a body before it was removed). The cells stay sorted.
+// Update must not be mixed with Insert, except after a Clear.
+func (sg *SpatialGrid) Update(bodies []*actor.RigidBody) {
+	sg.planes.bodyIndices = sg.planes.bodyIndices[:0]
+	sg.meshes.bodyIndices = sg.meshes.bodyIndices[:0]
+
+	for i, body := range bodies {
+		proxy := gridProxy{body: body}
+		_, isPlane := body.Shape.(*actor.Plane)
+		switch {
+		case !body.IsEnabled():
+			// Left out of the cells, like a removed body
+		case isPlane:
+			sg.planes.bodyIndices = append(sg.planes.bodyIndices, i)
+		case isMesh(body):
+			sg.meshes.bodyIndices = append(sg.meshes.bodyIndices, i)
+		default:
+			aabb := body.BroadPhaseAABB()
+			proxy.level = sg.levelOf(aabb)
+			proxy.minCell = proxy.level.worldToCell(aabb.Min)
+			proxy.maxCell = proxy.level.worldToCell(aabb.Max)
+		}
+
+		if i == len(sg.proxies) {
+			sg.proxies = append(sg.proxies, gridProxy{})
+		}
+		previous := sg.proxies[i]
+		if previous == proxy {
+			continue
+		}
+		if previous.level != nil {
+			previous.level.removeRange(i, previous.minCell, previous.maxCell)
+		}
+		if proxy.level != nil {
+			proxy.level.insertRange(i, proxy.minCell, proxy.maxCell)
+		}
+		sg.proxies[i] = proxy
+	}
+
+	// The indices beyond the bodies, left by the bodies removed since the last Update
+	for i := len(bodies); i < len(sg.proxies); i++ {
+		if previous := sg.proxies[i]; previous.level != nil {
+			previous.level.removeRange(i, previous.minCell, previous.maxCell)
+		}
+	}
+	clear(sg.proxies[len(bodies):])
+	sg.proxies = sg.proxies[:len(bodies)]
+}
+
+// insertRange - Inserts a body index into the cells of the range in this level, keeping them sorted
+func (sg *SpatialGrid) insertRange(bodyIndex int, minCell, maxCell CellKey) {
+	for x := minCell.X; x <= maxCell.X; x++ {
+		for y := minCell.Y; y <= maxCell.Y; y++ {
+			for z := minCell.Z; z <= maxCell.Z; z++ {
+				cell := &sg.cells[sg.hashCell(CellKey{x, y, z})]
+				i := sort.SearchInts(cell.bodyIndices, bodyIndex)
+				cell.bodyIndices = append(cell.bodyIndices, 0)
+				copy(cell.bodyIndices[i+1:], cell.bodyIndices[i:])
+				cell.bodyIndices[i] = bodyIndex
+			}
 		}
 	}
 }
 
-// FindPairsParallel - Parallel version returning a channel
+// removeRange - Removes a body index from the cells of the range in this level, once per cell of the range
+func (sg *SpatialGrid) removeRange(bodyIndex int, minCell, maxCell CellKey) {
+	for x := minCell.X; x <= maxCell.X; x++ {
+		for y := minCell.Y; y <= maxCell.Y; y++ {
+			for z := minCell.Z; z <= maxCell.Z; z++ {
+				cell := &sg.cells[sg.hashCell(CellKey{x, y, z})]
+				i := sort.SearchInts(cell.bodyIndices, bodyIndex)
+				if i < len(cell.bodyIndices) && cell.bodyIndices[i] == bodyIndex {
+					cell.bodyIndices = append(cell.bodyIndices[:i], cell.bodyIndices[i+1:]...)
+				}
+			}
+		}
+	}
+}
+
+// FindPairs - Returns the pairs of bodies sharing a cell whose AABB overlap, see FindPairsParallel
+func (sg *SpatialGrid) FindPairs(bodies []*actor.RigidBody, workersCount int) <-chan Pair {
+	return sg.FindPairsParallel(bodies, workersCount)
+}
+
+// QueryRegion - Calls fn for the planes, the meshes and the bodies stored in the cells overlapped by region,
+// in all levels. Returns false if the region overlaps more cells than the finest level holds.
+func (sg *SpatialGrid) QueryRegion(region actor.AABB, fn func(bodyIndex int)) bool {
+	if sg.boundsCells(region) > float64(len(sg.cells)) {
+		return false
+	}
+
+	for _, index := range sg.planes.bodyIndices {
+		fn(index)
+	}
+	for _, index := range sg.meshes.bodyIndices {
+		fn(index)
+	}
+	for level := sg; level != nil; level = level.coarser {
+		level.forEachInBounds(region, fn)
+	}
+
+	return true
+}
+
+// QueryRay - Calls fn for the planes, the meshes and the bodies stored in the cells crossed by the ray,
+// walked in order (3D DDA) in each level and stopping at the first cell beyond reach().
+// Returns false if the ray crosses more cells than the finest level holds.
+func (sg *SpatialGrid) QueryRay(origin, direction mgl64.Vec3, maxDist float64, reach func() float64, fn func(bodyIndex int)) bool {
+	if 3*(maxDist/sg.cellSize+1) > float64(len(sg.cells)) {
+		return false
+	}
+
+	for _, index := range sg.planes.bodyIndices {
+		fn(index)
+	}
+	for _, index := range sg.meshes.bodyIndices {
+		fn(index)
+	}
+	for level := sg; level != nil; level = level.coarser {
+		level.forEachOnRay(origin, direction, maxDist, func(cell CellKey, entry float64) bool {
+			if entry > reach() {
+				return false
+			}
+			level.forEachInRing(cell, 0, fn)
+
+			return true
+		})
+	}
+
+	return true
+}
+
+// FindPairsParallel - Parallel version returning a channel, on a pool of workersCount goroutines.
+// The cells are split into tasks: each pair of bodies of a level is reported by the cell holding the min corner
+// of the overlap of their AABB, so the tasks need no shared state. The bodies are split into tasks too, for their
+// pairs with the planes, the meshes and the bodies of the coarser levels. The pairs are streamed in the order
+// of the tasks, independently of workersCount. The cells must be sorted (see SortCells).
 func (sg *SpatialGrid) FindPairsParallel(bodies []*actor.RigidBody, workersCount int) <-chan Pair {
-	var wg sync.WaitGroup
-	pairsChan := make(chan Pair, workersCount*10)
-	clearSeen := make([]bool, len(bodies))
+	type cellTask struct {
+		level      *SpatialGrid
+		start, end int
+	}
+	var cellTasks []cellTask
+	for level := sg; level != nil; level = level.coarser {
+		for start := 0; start < len(level.cells); start += GRID_CELLS_PER_TASK {
+			cellTasks = append(cellTasks, cellTask{level, start, min(start+GRID_CELLS_PER_TASK, len(level.cells))})
+		}
+	}
+	bodyTasks := (len(bodies) + GRID_BODIES_PER_TASK - 1) / GRID_BODIES_PER_TASK
 
-	dataSize := len(bodies)
-	chunkSize := (dataSize + workersCount - 1) / workersCount
-	for workerID := 0; workerID < workersCount; workerID++ {
-		wg.Add(1)
+	return pairTasks(&sg.pairBuffers, len(cellTasks)+bodyTasks, workersCount, func(task int, pairs []Pair) []Pair {
+		if task < len(cellTasks) {
+			return cellTasks[task].level.cellPairs(cellTasks[task].start, cellTasks[task].end, bodies, pairs)
+		}
 
-		go func(start, end int) {
-			defer wg.Done()
+		start := (task - len(cellTasks)) * GRID_BODIES_PER_TASK
+		return sg.bodyPairs(start, min(start+GRID_BODIES_PER_TASK, len(bodies)), bodies, pairs)
+	})
+}
 
-			seen := make([]bool, len(bodies))
-			for bodyIdx := start; bodyIdx < end; bodyIdx++ {
-				if _, isPlane := bodies[bodyIdx].Shape.(*actor.Plane); isPlane {
+// cellPairs - Appends the overlapping pairs of bodies stored in the cells [start, end[ of this level.
+// A pair is appended by the cell holding the min corner of the overlap, occupied by both bodies:
+// once, even if they share several cells.
+func (sg *SpatialGrid) cellPairs(start, end int, bodies []*actor.RigidBody, pairs []Pair) []Pair {
+	for cellIdx := start; cellIdx < end; cellIdx++ {
+		indices := sg.cells[cellIdx].bodyIndices
+		for i, indexA := range indices {
+			// A body in several cells hashed together is stored several times, side by side once sorted
+			if i > 0 && indices[i-1] == indexA {
+				continue
+			}
+
+			bodyA := bodies[indexA]
+			aabbA := bodyA.BroadPhaseAABB()
+			for j := i + 1; j < len(indices); j++ {
+				indexB := indices[j]
+				if indices[j-1] == indexB {
 					continue
 				}
-				bodyA := bodies[bodyIdx]
 
-				// write all planes/body collisions
-				for _, planeId := range sg.planes.bodyIndices {
-					pairsChan <- Pair{BodyA: bodies[planeId], BodyB: bodyA}
+				bodyB := bodies[indexB]
+				if !bodyA.IsActive() && !bodyB.IsActive() {
+					continue
 				}
 
-				copy(seen, clearSeen)
-
-				// Find cells occupied by bodyA
-				minCell := sg.worldToCell(bodyA.Shape.GetAABB().Min)
-				maxCell := sg.worldToCell(bodyA.Shape.GetAABB().Max)
-
-				// Iterate through these cells
-				for x := minCell.X; x <= maxCell.X; x++ {
-					for y := minCell.Y; y <= maxCell.Y; y++ {
-						for z := minCell.Z; z <= maxCell.Z; z++ {
-							cellKey := CellKey{x, y, z}
-							cellIdx := sg.hashCell(cellKey)
-
-							// Test against all bodies in this cell
-							for _, otherIdx := range sg.cells[cellIdx].bodyIndices {
-								// Avoid duplicates
-								if otherIdx <= bodyIdx || seen[otherIdx] {
-									continue
-								}
-								seen[otherIdx] = true
-
-								bodyB := bodies[otherIdx]
-								if bodyA.BodyType == actor.BodyTypeStatic && bodyB.BodyType == actor.BodyTypeStatic {
-									continue
-								}
-								if bodyA.IsSleeping && bodyB.IsSleeping {
-									continue
-								}
-
-								if bodyA.Shape.GetAABB().Overlaps(bodyB.Shape.GetAABB()) {
-									pairsChan <- Pair{BodyA: bodyA, BodyB: bodyB}
-								}
+				aabbB := bodyB.BroadPhaseAABB()
+				if !aabbA.Overlaps(aabbB) || sg.hashCell(sg.worldToCell(maxCorner(aabbA.Min, aabbB.Min))) != cellIdx {
+					continue
+				}
+				pairs = append(pairs, Pair{BodyA: bodyA, BodyB: bodyB})
+			}
+		}
+	}
+
+	return pairs
+}
+
+// bodyPairs - Appends the pairs of the bodies [start, end[ with the planes, the meshes,
+// and the bodies of the coarser levels than their own
+func (sg *SpatialGrid) bodyPairs(start, end int, bodies []*actor.RigidBody, pairs []Pair) []Pair {
+	for bodyIdx := start; bodyIdx < end; bodyIdx++ {
+		bodyA := bodies[bodyIdx]
+		if _, isPlane := bodyA.Shape.(*actor.Plane); isPlane || isMesh(bodyA) || !bodyA.IsEnabled() {
+			continue
+		}
+		aabbA := bodyA.BroadPhaseAABB()
+
+		// write all planes/body collisions
+		for _, planeId := range sg.planes.bodyIndices {
+			pairs = append(pairs, Pair{BodyA: bodies[planeId], BodyB: bodyA})
+		}
+
+		// meshes are static, only active bodies are tested against them
+		if bodyA.IsActive() {
+			for _, meshId := range sg.meshes.bodyIndices {
+				if bodies[meshId].Shape.GetAABB().Overlaps(aabbA) {
+					pairs = append(pairs, Pair{BodyA: bodies[meshId], BodyB: bodyA})
+				}
+			}
+		}
+
+		for level := sg.levelOf(aabbA).coarser; level != nil; level = level.coarser {
+			minCell := level.worldToCell(aabbA.Min)
+			maxCell := level.worldToCell(aabbA.Max)
+
+			for x := minCell.X; x <= maxCell.X; x++ {
+				for y := minCell.Y; y <= maxCell.Y; y++ {
+					for z := minCell.Z; z <= maxCell.Z; z++ {
+						indices := level.cells[level.hashCell(CellKey{x, y, z})].bodyIndices
+						for j, otherIdx := range indices {
+							if j > 0 && indices[j-1] == otherIdx {
+								continue
+							}
+
+							bodyB := bodies[otherIdx]
+							if !bodyA.IsActive() && !bodyB.IsActive() {
+								continue
 							}
+
+							// Appended by the cell holding the min corner of the overlap, like cellPairs
+							aabbB := bodyB.BroadPhaseAABB()
+							if !aabbA.Overlaps(aabbB) || level.worldToCell(maxCorner(aabbA.Min, aabbB.Min)) != (CellKey{x, y, z}) {
+								continue
+							}
+							pairs = append(pairs, Pair{BodyA: bodyA, BodyB: bodyB})
 						}
 					}
 				}
 			}
-		}(workerID*chunkSize, min((workerID+1)*chunkSize, dataSize))
+		}
+	}
+
+	return pairs
+}
+
+// maxCorner - Returns the componentwise maximum of two points, the min corner of the overlap of two AABBs
+func maxCorner(a, b mgl64.Vec3) mgl64.Vec3 {
+	return mgl64.Vec3{math.Max(a.X(), b.X()), math.Max(a.Y(), b.Y()), math.Max(a.Z(), b.Z())}
+}
+
+// forEachInRing - Calls fn for each body index stored in the cells at Chebyshev distance ring from center
+// Ring 0 is the center cell itself. An index can be reported several times
+// (bodies spanning several cells, hash collisions), callers must tolerate duplicates.
+func (sg *SpatialGrid) forEachInRing(center CellKey, ring int, fn func(bodyIndex int)) {
+	for x := center.X - ring; x <= center.X+ring; x++ {
+		for y := center.Y - ring; y <= center.Y+ring; y++ {
+			// Inside the shell on X and Y: only the two Z faces belong to the ring
+			zStep := 1
+			if ring > 0 && x != center.X-ring && x != center.X+ring && y != center.Y-ring && y != center.Y+ring {
+				zStep = 2 * ring
+			}
+
+			for z := center.Z - ring; z <= center.Z+ring; z += zStep {
+				cellIdx := sg.hashCell(CellKey{x, y, z})
+				for _, bodyIndex := range sg.cells[cellIdx].bodyIndices {
+					fn(bodyIndex)
+				}
+			}
+		}
+	}
+}
+
+// forEachInBounds - Calls fn for each body index stored in the cells overlapped by bounds.
+// Like forEachInRing, an index can be reported several times.
+func (sg *SpatialGrid) forEachInBounds(bounds actor.AABB, fn func(bodyIndex int)) {
+	minCell := sg.worldToCell(bounds.Min)
+	maxCell := sg.worldToCell(bounds.Max)
+
+	for x := minCell.X; x <= maxCell.X; x++ {
+		for y := minCell.Y; y <= maxCell.Y; y++ {
+			for z := minCell.Z; z <= maxCell.Z; z++ {
+				cellIdx := sg.hashCell(CellKey{x, y, z})
+				for _, bodyIndex := range sg.cells[cellIdx].bodyIndices {
+					fn(bodyIndex)
+				}
+			}
+		}
 	}
+}
 
-	go func() {
-		wg.Wait()
-		close(pairsChan)
-	}()
+// boundsCells - Returns the number of cells overlapped by bounds
+func (sg *SpatialGrid) boundsCells(bounds actor.AABB) float64 {
+	size := bounds.Max.Sub(bounds.Min)
+
+	return (size.X()/sg.cellSize + 2) * (size.Y()/sg.cellSize + 2) * (size.Z()/sg.cellSize + 2)
+}
 
-	return pairsChan
+// forEachOnRay - Calls fn for each cell crossed by the ray within maxDist, in order, with the distance at which
+// the ray enters it (Amanatides-Woo traversal). The direction must be normalized, fn returns false to stop.
+func (sg *SpatialGrid) forEachOnRay(origin, direction mgl64.Vec3, maxDist float64, fn func(cell CellKey, entry float64) bool) {
+	start := sg.worldToCell(origin)
+	cell := [3]int{start.X, start.Y, start.Z}
+
+	var step [3]int
+	var next, delta [3]float64
+	for i := 0; i < 3; i++ {
+		switch {
+		case direction[i] > 0:
+			step[i] = 1
+			next[i] = (float64(cell[i]+1)*sg.cellSize - origin[i]) / direction[i]
+			delta[i] = sg.cellSize / direction[i]
+		case direction[i] < 0:
+			step[i] = -1
+			next[i] = (float64(cell[i])*sg.cellSize - origin[i]) / direction[i]
+			delta[i] = -sg.cellSize / direction[i]
+		default:
+			next[i], delta[i] = math.Inf(1), math.Inf(1)
+		}
+	}
+
+	entry := 0.0
+	for entry <= maxDist {
+		if !fn(CellKey{cell[0], cell[1], cell[2]}, entry) {
+			return
+		}
+
+		axis := 0
+		if next[1] < next[axis] {
+			axis = 1
+		}
+		if next[2] < next[axis] {
+			axis = 2
+		}
+		entry = next[axis]
+		cell[axis] += step[axis]
+		next[axis] += delta[axis]
+	}
 }
 
 // worldToCell - Converts a world position to cell coordinates
@@ -1,6 +1,8 @@
 package feather
 
 import (
+	"fmt"
+	"math/rand"
 	"sort"
 	"testing"
 
@@ -572,3 +574,296 @@ func BenchmarkFindPairsParallel(b *testing.B) {
 		}
 	}
 }
+
+func TestForEachInRing(t *testing.T) {
+	grid := NewSpatialGrid(1.0, 4096)
+	bodies := []*actor.RigidBody{
+		createTestBox(mgl64.Vec3{0.5, 0.5, 0.5}, mgl64.Vec3{0.1, 0.1, 0.1}),  // ring 0
+		createTestBox(mgl64.Vec3{1.5, 1.5, 0.5}, mgl64.Vec3{0.1, 0.1, 0.1}),  // ring 1
+		createTestBox(mgl64.Vec3{0.5, 0.5, 2.5}, mgl64.Vec3{0.1, 0.1, 0.1}),  // ring 2
+		createTestBox(mgl64.Vec3{-1.5, 2.5, 0.5}, mgl64.Vec3{0.1, 0.1, 0.1}), // ring 2
+	}
+	for i, body := range bodies {
+		grid.Insert(i, body)
+	}
+
+	expected := [][]int{{0}, {1}, {2, 3}}
+	for ring, want := range expected {
+		// Hash collisions can report an index twice
+		seen := make(map[int]bool)
+		grid.forEachInRing(CellKey{0, 0, 0}, ring, func(bodyIndex int) {
+			seen[bodyIndex] = true
+		})
+		var got []int
+		for bodyIndex := range seen {
+			got = append(got, bodyIndex)
+		}
+		sort.Ints(got)
+
+		if len(got) != len(want) {
+			t.Fatalf("ring %d: got indices %v, want %v", ring, got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("ring %d: got indices %v, want %v", ring, got, want)
+			}
+		}
+	}
+}
+
+func TestHierarchicalGridLevels(t *testing.T) {
+	grid := NewHierarchicalSpatialGrid(1.0, 1024, 3)
+	if grid.Levels() != 3 || grid.coarser.cellSize != GRID_LEVEL_RATIO || grid.coarser.coarser.cellSize != GRID_LEVEL_RATIO*GRID_LEVEL_RATIO {
+		t.Fatalf("unexpected levels: %d", grid.Levels())
+	}
+
+	small := createTestBox(mgl64.Vec3{0.5, 0.5, 0.5}, mgl64.Vec3{0.2, 0.2, 0.2})
+	medium := createTestBox(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{2, 2, 2})
+	platform := createTestBox(mgl64.Vec3{0, -1, 0}, mgl64.Vec3{200, 1, 200})
+	bodies := []*actor.RigidBody{small, medium, platform}
+	for i, body := range bodies {
+		grid.Insert(i, body)
+	}
+
+	expected := []*SpatialGrid{grid, grid.coarser, grid.coarser.coarser}
+	for i, body := range bodies {
+		if level := grid.levelOf(body.Shape.GetAABB()); level != expected[i] {
+			t.Errorf("body %d stored in the level of cells %v", i, level.cellSize)
+		}
+	}
+
+	// The platform covers 480000 cells of 1m, at most 8x2x8 cells of 64m
+	occupied := 0
+	for level := grid; level != nil; level = level.coarser {
+		for _, cell := range level.cells {
+			for _, index := range cell.bodyIndices {
+				if index == 2 {
+					occupied++
+				}
+			}
+		}
+	}
+	if occupied > 128 {
+		t.Errorf("platform stored in %d cells", occupied)
+	}
+
+	pairs := make(map[Pair]bool)
+	for pair := range grid.FindPairsParallel(bodies, 2) {
+		pairs[pair.Ordered()] = true
+	}
+	for _, pair := range []Pair{OrderPair(small, medium), OrderPair(medium, platform)} {
+		if !pairs[pair] {
+			t.Errorf("pair missing across levels")
+		}
+	}
+	if len(pairs) != 2 {
+		t.Errorf("found %d pairs, want 2", len(pairs))
+	}
+}
+
+func TestHierarchicalGridMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	bodies := createMixedBodies(rng, 300)
+	// Huge platforms among them
+	bodies = append(bodies, createTestBox(mgl64.Vec3{20, 0, 20}, mgl64.Vec3{100, 0.5, 100}))
+	bodies = append(bodies, createTestBox(mgl64.Vec3{0, 20, 0}, mgl64.Vec3{30, 30, 0.5}))
+
+	grid := NewHierarchicalSpatialGrid(1.0, 4096, 3)
+	comparePairs(t, collectPairs(broadPhase(grid, bodies, 4)), collectPairs(BruteForceBroadPhase(bodies)))
+}
+
+func TestHierarchicalGridQueries(t *testing.T) {
+	pebble := createSphere(mgl64.Vec3{3, 0, 0}, 0.05, actor.BodyTypeStatic)
+	platform := createBox(mgl64.Vec3{0, -10, 0}, mgl64.Vec3{100, 1, 100}, actor.BodyTypeStatic)
+	world := createQueryWorld(pebble, platform)
+	world.SpatialGrid = NewHierarchicalSpatialGrid(1.0, 1024, 3)
+	world.Step(1.0 / 60.0)
+
+	if body, _, found := world.ClosestBody(mgl64.Vec3{0, -7, 0}, 5, nil); !found || body != platform {
+		t.Errorf("ClosestBody = %v, want the platform", body)
+	}
+	if body, _, found := world.ClosestBody(mgl64.Vec3{2.5, 0, 0}, 5, nil); !found || body != pebble {
+		t.Errorf("ClosestBody = %v, want the pebble", body)
+	}
+	if hit, found := world.Raycast(mgl64.Vec3{50, 0, 50}, mgl64.Vec3{0, -1, 0}, 20, nil); !found || hit.Body != platform {
+		t.Errorf("Raycast = %+v, want the platform", hit)
+	}
+	if found := world.QueryAABB(actor.AABB{Min: mgl64.Vec3{2, -9.5, -1}, Max: mgl64.Vec3{4, 1, 1}}, nil); len(found) != 2 {
+		t.Errorf("QueryAABB = %v, want the pebble and the platform", found)
+	}
+}
+
+func BenchmarkHierarchicalGridMixedSizes(b *testing.B) {
+	rng := rand.New(rand.NewSource(0))
+	bodies := createMixedBodies(rng, 1000)
+	grid := NewHierarchicalSpatialGrid(1.0, 4096, 3)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for range broadPhase(grid, bodies, 4) {
+		}
+	}
+}
+
+func TestFindPairsParallelDeterministicOrder(t *testing.T) {
+	rng := rand.New(rand.NewSource(11))
+	bodies := createMixedBodies(rng, 2000)
+	grid := NewHierarchicalSpatialGrid(1.0, 4096, 3)
+	grid.Update(bodies)
+
+	collectOrdered := func(workers int) []Pair {
+		var pairs []Pair
+		for pair := range grid.FindPairsParallel(bodies, workers) {
+			pairs = append(pairs, pair)
+		}
+		return pairs
+	}
+
+	reference := collectOrdered(1)
+	comparePairs(t, collectPairs(grid.FindPairsParallel(bodies, 3)), collectPairs(BruteForceBroadPhase(bodies)))
+	for _, workers := range []int{2, 8, 64} {
+		pairs := collectOrdered(workers)
+		if len(pairs) != len(reference) {
+			t.Fatalf("%d workers found %d pairs, want %d", workers, len(pairs), len(reference))
+		}
+		for i := range pairs {
+			if pairs[i] != reference[i] {
+				t.Fatalf("%d workers: pair %d differs from the single worker order", workers, i)
+			}
+		}
+	}
+}
+
+func BenchmarkFindPairsParallelLargeWorld(b *testing.B) {
+	const bodiesCount = 10000
+	rng := rand.New(rand.NewSource(0))
+	bodies := make([]*actor.RigidBody, bodiesCount)
+	for i := range bodies {
+		position := mgl64.Vec3{rng.Float64() * 100, rng.Float64() * 20, rng.Float64() * 100}
+		bodies[i] = createTestBox(position, mgl64.Vec3{0.4, 0.4, 0.4})
+	}
+	grid := NewHierarchicalSpatialGrid(1.0, 16384, 3)
+	grid.Update(bodies)
+
+	for _, workers := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for range grid.FindPairsParallel(bodies, workers) {
+				}
+			}
+		})
+	}
+}
+
+// gridContent returns the sorted cells of all the levels of a grid
+func gridContent(grid *SpatialGrid) [][]int {
+	var content [][]int
+	for level := grid; level != nil; level = level.coarser {
+		for _, cell := range level.cells {
+			content = append(content, append([]int(nil), cell.bodyIndices...))
+		}
+	}
+	content = append(content, append([]int(nil), grid.planes.bodyIndices...))
+
+	return content
+}
+
+func TestUpdateIncrementalMatchesRebuild(t *testing.T) {
+	rng := rand.New(rand.NewSource(21))
+	bodies := createMixedBodies(rng, 300)
+	grid := NewHierarchicalSpatialGrid(1.0, 1024, 3)
+	grid.Update(bodies)
+
+	for frame := 0; frame < 30; frame++ {
+		for _, body := range bodies[1:] {
+			if rng.Intn(4) == 0 {
+				offset := mgl64.Vec3{rng.NormFloat64(), rng.NormFloat64(), rng.NormFloat64()}.Mul(0.3)
+				body.Transform.Position = body.Transform.Position.Add(offset)
+				body.Shape.ComputeAABB(body.Transform)
+			}
+		}
+		if frame%3 == 0 {
+			removed := 1 + rng.Intn(len(bodies)-1)
+			bodies = append(bodies[:removed], bodies[removed+1:]...)
+		}
+		if frame%5 == 0 {
+			bodies = append(bodies, createMixedBodies(rng, 4)[1:]...)
+		}
+
+		grid.Update(bodies)
+		rebuilt := NewHierarchicalSpatialGrid(1.0, 1024, 3)
+		for i, body := range bodies {
+			rebuilt.Insert(i, body)
+		}
+		rebuilt.SortCells()
+
+		incremental, expected := gridContent(grid), gridContent(rebuilt)
+		for i := range expected {
+			if fmt.Sprint(incremental[i]) != fmt.Sprint(expected[i]) {
+				t.Fatalf("frame %d: cell %d holds %v, want %v", frame, i, incremental[i], expected[i])
+			}
+		}
+		if len(grid.proxies) != len(bodies) {
+			t.Fatalf("frame %d: %d proxies for %d bodies", frame, len(grid.proxies), len(bodies))
+		}
+	}
+}
+
+func TestUpdateKeepsBodiesWithinTheirCells(t *testing.T) {
+	grid := NewSpatialGrid(1.0, 64)
+	body := createTestBox(mgl64.Vec3{0.5, 0.5, 0.5}, mgl64.Vec3{0.1, 0.1, 0.1})
+	bodies := []*actor.RigidBody{body}
+	grid.Update(bodies)
+	cell := &grid.cells[grid.hashCell(CellKey{0, 0, 0})]
+
+	// Moving within the cell: the cell is not rewritten
+	body.Transform.Position = mgl64.Vec3{0.6, 0.4, 0.5}
+	body.Shape.ComputeAABB(body.Transform)
+	cell.bodyIndices[0] = 42
+	grid.Update(bodies)
+	if cell.bodyIndices[0] != 42 {
+		t.Errorf("body reinserted while staying in its cell")
+	}
+	cell.bodyIndices[0] = 0
+
+	// Crossing a boundary: the body spans two cells
+	body.Transform.Position = mgl64.Vec3{0.95, 0.5, 0.5}
+	body.Shape.ComputeAABB(body.Transform)
+	grid.Update(bodies)
+	if len(cell.bodyIndices) != 1 || len(grid.cells[grid.hashCell(CellKey{1, 0, 0})].bodyIndices) != 1 {
+		t.Errorf("body not reinserted after crossing a cell boundary")
+	}
+
+	// Removed from the world
+	grid.Update(nil)
+	for i, cell := range grid.cells {
+		if len(cell.bodyIndices) != 0 {
+			t.Errorf("cell %d still holds %v", i, cell.bodyIndices)
+		}
+	}
+}
+
+func BenchmarkUpdateMostlyStatic(b *testing.B) {
+	rng := rand.New(rand.NewSource(0))
+	bodies := make([]*actor.RigidBody, 10000)
+	for i := range bodies {
+		position := mgl64.Vec3{rng.Float64() * 100, rng.Float64() * 20, rng.Float64() * 100}
+		bodies[i] = createTestBox(position, mgl64.Vec3{0.4, 0.4, 0.4})
+	}
+	grid := NewHierarchicalSpatialGrid(1.0, 16384, 3)
+	grid.Update(bodies)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// 1% of the bodies move
+		for _, body := range bodies[:100] {
+			body.Transform.Position = body.Transform.Position.Add(mgl64.Vec3{0.05, 0, 0})
+			body.Shape.ComputeAABB(body.Transform)
+		}
+		grid.Update(bodies)
+	}
+}
@@ -0,0 +1,118 @@
+package feather
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/akmonengine/feather/actor"
+)
+
+// Stats holds the diagnostics of the last World.Step
+type Stats struct {
+	// GJKCapped counts the GJK queries stopped by gjk.MaxIterations without converging.
+	// The pairs are reported as separated. A steady non-zero count points to problematic
+	// shape configurations, typically smooth shapes resting nearly in contact.
+	GJKCapped int
+
+	// ExtremeMassRatios counts the contacts between dynamic bodies whose mass ratio exceeds
+	// EXTREME_MASS_RATIO, summed over the substeps. Light bodies squeezed between heavy ones jitter,
+	// see World.MaxMassRatio.
+	ExtremeMassRatios int
+
+	// GJKCappedPair and ExtremeMassRatioPair name one of the pairs behind the counts above
+	// (e.g. "crate_stack_07 vs ground"), from the bodies Label. Empty when the count is 0.
+	GJKCappedPair        string
+	ExtremeMassRatioPair string
+
+	// CollisionTime and SolveTime are the durations of the collision detection (broad and narrow phase,
+	// events, PreSolve hook) and of the solver, summed over the substeps
+	CollisionTime time.Duration
+	SolveTime     time.Duration
+
+	// DegradedSubsteps counts the substeps solved with a single iteration to respect World.StepBudget
+	DegradedSubsteps int
+	// DeferredIslands counts the islands deferred to the next Step to respect World.StepBudget
+	DeferredIslands int
+	// CatchUpSubsteps counts the substeps run at the start of the Step for the islands deferred by the last one
+	CatchUpSubsteps int
+	// DroppedIslands counts the deferred islands owing more than a Step, the time beyond it is lost
+	DroppedIslands int
+
+	// Islands is the number of islands solved at the last substep: the groups of dynamic bodies
+	// connected by contacts or constraints, solved independently of each other
+	Islands int
+}
+
+// stepCounters are incremented concurrently by the pipeline workers during a Step
+type stepCounters struct {
+	gjkCapped         atomic.Int64
+	extremeMassRatios atomic.Int64
+
+	// The first pair counted, kept by CompareAndSwap
+	gjkCappedPair        atomic.Pointer[[2]*actor.RigidBody]
+	extremeMassRatioPair atomic.Pointer[[2]*actor.RigidBody]
+
+	// Written by the Step goroutine only
+	collisionTime    time.Duration
+	solveTime        time.Duration
+	degradedSubsteps int
+	deferredIslands  int
+	catchUpSubsteps  int
+	droppedIslands   int
+	islands          int
+	// Whether the solver iterations are cut for the rest of the Step, see World.StepBudget
+	degraded bool
+}
+
+func (c *stepCounters) reset() {
+	c.gjkCapped.Store(0)
+	c.extremeMassRatios.Store(0)
+	c.gjkCappedPair.Store(nil)
+	c.extremeMassRatioPair.Store(nil)
+	c.collisionTime = 0
+	c.solveTime = 0
+	c.degradedSubsteps = 0
+	c.deferredIslands = 0
+	c.catchUpSubsteps = 0
+	c.droppedIslands = 0
+	c.islands = 0
+	c.degraded = false
+}
+
+// stats copies the counters into a Stats
+func (c *stepCounters) stats() Stats {
+	return Stats{
+		GJKCapped:            int(c.gjkCapped.Load()),
+		ExtremeMassRatios:    int(c.extremeMassRatios.Load()),
+		GJKCappedPair:        pairLabel(c.gjkCappedPair.Load()),
+		ExtremeMassRatioPair: pairLabel(c.extremeMassRatioPair.Load()),
+		CollisionTime:        c.collisionTime,
+		SolveTime:            c.solveTime,
+		DegradedSubsteps:     c.degradedSubsteps,
+		DeferredIslands:      c.deferredIslands,
+		CatchUpSubsteps:      c.catchUpSubsteps,
+		DroppedIslands:       c.droppedIslands,
+		Islands:              c.islands,
+	}
+}
+
+// addGJKCapped counts a GJK query stopped by gjk.MaxIterations
+func (c *stepCounters) addGJKCapped(bodyA, bodyB *actor.RigidBody) {
+	c.gjkCapped.Add(1)
+	c.gjkCappedPair.CompareAndSwap(nil, &[2]*actor.RigidBody{bodyA, bodyB})
+}
+
+// addExtremeMassRatio counts a contact exceeding EXTREME_MASS_RATIO
+func (c *stepCounters) addExtremeMassRatio(bodyA, bodyB *actor.RigidBody) {
+	c.extremeMassRatios.Add(1)
+	c.extremeMassRatioPair.CompareAndSwap(nil, &[2]*actor.RigidBody{bodyA, bodyB})
+}
+
+// pairLabel formats the labels of a pair, the labels are built only when the Stats are read
+func pairLabel(pair *[2]*actor.RigidBody) string {
+	if pair == nil {
+		return ""
+	}
+
+	return pair[0].Label() + " vs " + pair[1].Label()
+}
@@ -0,0 +1,170 @@
+package feather
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestStepCounters(t *testing.T) {
+	var counters stepCounters
+	counters.gjkCapped.Add(3)
+
+	if stats := counters.stats(); stats.GJKCapped != 3 {
+		t.Errorf("GJKCapped = %d, want 3", stats.GJKCapped)
+	}
+
+	counters.reset()
+	if stats := counters.stats(); stats.GJKCapped != 0 {
+		t.Errorf("GJKCapped = %d after reset, want 0", stats.GJKCapped)
+	}
+}
+
+func TestWorld_StatsResetEachStep(t *testing.T) {
+	world := &World{Substeps: 2, Events: NewEvents()}
+	world.AddBody(createSphere(mgl64.Vec3{0, 0, 0}, 1, actor.BodyTypeDynamic))
+	world.AddBody(createSphere(mgl64.Vec3{1.5, 0, 0}, 1, actor.BodyTypeDynamic))
+
+	world.counters.gjkCapped.Add(5)
+	world.Step(1.0 / 60.0)
+
+	if world.Stats.GJKCapped != 0 {
+		t.Errorf("GJKCapped = %d, want 0 for converging spheres", world.Stats.GJKCapped)
+	}
+}
+
+func TestWorld_StatsExtremeMassRatios(t *testing.T) {
+	world := &World{Substeps: 1, Events: NewEvents()}
+	light := createSphere(mgl64.Vec3{0, 0, 0}, 1, actor.BodyTypeDynamic)
+	light.Name = "pebble"
+	world.AddBody(light)
+	heavy := createSphere(mgl64.Vec3{1.5, 0, 0}, 1, actor.BodyTypeDynamic)
+	heavy = actor.NewRigidBodyWithMass(heavy.Transform, heavy.Shape, actor.BodyTypeDynamic, 1e6)
+	heavy.Name = "boulder"
+	world.AddBody(heavy)
+
+	world.Step(1.0 / 60.0)
+
+	if world.Stats.ExtremeMassRatios != 1 {
+		t.Errorf("ExtremeMassRatios = %d, want 1", world.Stats.ExtremeMassRatios)
+	}
+	if pair := world.Stats.ExtremeMassRatioPair; pair != "pebble vs boulder" && pair != "boulder vs pebble" {
+		t.Errorf("ExtremeMassRatioPair = %q, want the names of the spheres", pair)
+	}
+	if world.Stats.GJKCappedPair != "" {
+		t.Errorf("GJKCappedPair = %q, want empty without capped queries", world.Stats.GJKCappedPair)
+	}
+}
+
+func TestWorld_StatsTiming(t *testing.T) {
+	world := &World{Substeps: 2, Events: NewEvents()}
+	world.AddBody(createSphere(mgl64.Vec3{0, 0, 0}, 1, actor.BodyTypeDynamic))
+	world.AddBody(createSphere(mgl64.Vec3{1.5, 0, 0}, 1, actor.BodyTypeDynamic))
+
+	world.Step(1.0 / 60.0)
+
+	if world.Stats.CollisionTime <= 0 || world.Stats.SolveTime <= 0 {
+		t.Errorf("CollisionTime = %v, SolveTime = %v, want positive durations", world.Stats.CollisionTime, world.Stats.SolveTime)
+	}
+	if world.Stats.DeferredIslands != 0 || world.Stats.CatchUpSubsteps != 0 {
+		t.Errorf("deferred %d islands, caught up %d substeps without budget, want 0", world.Stats.DeferredIslands, world.Stats.CatchUpSubsteps)
+	}
+}
+
+func TestWorld_StepBudget(t *testing.T) {
+	// Two boxes sliding on the ground, two islands, in a world over its budget and in a reference world
+	newWorld := func(budget time.Duration) (*World, []*actor.RigidBody) {
+		world := &World{Substeps: 8, Gravity: mgl64.Vec3{0, -10, 0}, Events: NewEvents(), StepBudget: budget}
+		world.AddBody(createBox(mgl64.Vec3{0, -1, 0}, mgl64.Vec3{100, 1, 10}, actor.BodyTypeStatic))
+
+		var boxes []*actor.RigidBody
+		for _, x := range []float64{0, 50} {
+			box := createBox(mgl64.Vec3{x, 0.5, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+			box.Material.StaticFriction, box.Material.DynamicFriction = 0.5, 0.5
+			box.Velocity = mgl64.Vec3{2, 0, 0}
+			world.AddBody(box)
+			boxes = append(boxes, box)
+		}
+
+		return world, boxes
+	}
+	world, boxes := newWorld(time.Nanosecond)
+	reference, expected := newWorld(0)
+
+	// The solver already runs a single iteration: after the first substep, both islands are deferred
+	world.Step(0.1)
+	reference.Step(0.1)
+	if world.Stats.DegradedSubsteps != 0 || world.Stats.DeferredIslands != 2 || world.Stats.DroppedIslands != 0 {
+		t.Fatalf("degraded %d substeps, deferred %d islands, dropped %d, want 0, 2 and 0",
+			world.Stats.DegradedSubsteps, world.Stats.DeferredIslands, world.Stats.DroppedIslands)
+	}
+	for i, box := range boxes {
+		if box.IsDeferred() {
+			t.Errorf("box %d should be released at the end of the Step", i)
+		}
+		if travelled, want := box.Transform.Position.X()-50*float64(i), expected[0].Transform.Position.X(); travelled >= want/4 {
+			t.Errorf("box %d travelled %v, want a single substep of the %v of the reference", i, travelled, want)
+		}
+	}
+
+	// The deferred time is simulated at the start of the next Step, the boxes join the reference
+	world.StepBudget = 0
+	world.Step(0.1)
+	reference.Step(0.1)
+	if world.Stats.CatchUpSubsteps != 7 || world.Stats.DeferredIslands != 0 {
+		t.Errorf("caught up %d substeps, deferred %d islands, want 7 and 0", world.Stats.CatchUpSubsteps, world.Stats.DeferredIslands)
+	}
+	for i, box := range boxes {
+		if !vec3ApproxEqual(box.Transform.Position, expected[i].Transform.Position, 1e-6) || !vec3ApproxEqual(box.Velocity, expected[i].Velocity, 1e-6) {
+			t.Errorf("box %d at %v moving at %v, want %v and %v", i, box.Transform.Position, box.Velocity,
+				expected[i].Transform.Position, expected[i].Velocity)
+		}
+	}
+
+	world.Step(0.1)
+	if world.Stats.CatchUpSubsteps != 0 {
+		t.Errorf("caught up %d substeps once the time is paid back, want 0", world.Stats.CatchUpSubsteps)
+	}
+}
+
+// TestWorld_StepBudgetStack checks a stack stays standing when the budget is always exceeded
+func TestWorld_StepBudgetStack(t *testing.T) {
+	world := NewWorld(WithSubsteps(8), WithIterations(4, 2))
+	world.StepBudget = time.Nanosecond
+	world.Tolerances.SleepTime = math.Inf(1)
+	ground := createBox(mgl64.Vec3{0, -1, 0}, mgl64.Vec3{10, 1, 10}, actor.BodyTypeStatic)
+	ground.Material.StaticFriction, ground.Material.DynamicFriction = 0.5, 0.5
+	world.AddBody(ground)
+
+	var boxes []*actor.RigidBody
+	for i := range 5 {
+		box := createBox(mgl64.Vec3{0, 0.5 + float64(i), 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+		box.Material.StaticFriction, box.Material.DynamicFriction = 0.5, 0.5
+		world.AddBody(box)
+		boxes = append(boxes, box)
+	}
+
+	// The stack runs a catch-up substep, the first substep and a degraded one, then it is deferred again:
+	// it owes more than a Step, the rest is dropped
+	for i := range 600 {
+		world.Step(1.0 / 60.0)
+		if world.Stats.DeferredIslands == 0 {
+			t.Fatalf("step %d: no island deferred", i)
+		}
+		if i > 0 && (world.Stats.CatchUpSubsteps != 1 || world.Stats.DroppedIslands != 1) {
+			t.Fatalf("step %d: caught up %d substeps, dropped %d islands, want 1 and 1", i, world.Stats.CatchUpSubsteps, world.Stats.DroppedIslands)
+		}
+	}
+
+	for i, box := range boxes {
+		if speed := box.Velocity.Len(); speed > 0.2 {
+			t.Errorf("box %d moves at %v m/s, want resting", i, speed)
+		}
+		if drift := box.Transform.Position.Sub(mgl64.Vec3{0, 0.5 + float64(i), 0}); drift.Len() > 0.1 {
+			t.Errorf("box %d moved by %v, want the stack standing", i, drift)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package feather
+
+import (
+	"math"
+
+	"github.com/akmonengine/feather/actor"
+)
+
+// maxTriggerSweepSamples limits the samples along the motion of a body swept against a trigger
+const maxTriggerSweepSamples = 64
+
+// sweepTriggers records the trigger pairs crossed by the bodies during the substep, but not overlapping
+// at its end: a fast body passes through a thin trigger (checkpoint, kill zone) between two substeps
+// without a contact. The triggers are taken at their pose at the end of the substep.
+func (w *World) sweepTriggers() {
+	for _, trigger := range w.Bodies {
+		if !trigger.IsTrigger || !trigger.IsEnabled() {
+			continue
+		}
+
+		triggerBounds := trigger.Shape.GetAABB()
+		for _, body := range w.Bodies {
+			if body == trigger || body.IsTrigger || !body.IsActive() {
+				continue
+			}
+
+			if sweptOverlap(body, trigger, triggerBounds) {
+				w.Events.recordTrigger(body, trigger)
+			}
+		}
+	}
+}
+
+// sweptOverlap tests the bounding sphere of the body along its motion during the substep against the trigger.
+// The samples are at most one radius apart, so the sphere cannot skip a trigger, however thin.
+// Only the samples strictly inside the motion are tested: the start was tested by the previous substep,
+// the end by the narrow phase, with the exact shape. Slow bodies, moving less than their radius, are not sampled.
+func sweptOverlap(body, trigger *actor.RigidBody, triggerBounds actor.AABB) bool {
+	start := body.PreviousTransform.Position
+	displacement := body.Transform.Position.Sub(start)
+	radius := body.Shape.BoundingRadius()
+	if !(radius > 0) {
+		return false
+	}
+
+	// Bounds swept from the start to the end of the motion
+	swept := body.Shape.GetAABB()
+	for i := 0; i < 3; i++ {
+		swept.Min[i] = math.Min(swept.Min[i], swept.Min[i]-displacement[i])
+		swept.Max[i] = math.Max(swept.Max[i], swept.Max[i]-displacement[i])
+	}
+	if !swept.Overlaps(triggerBounds) {
+		return false
+	}
+
+	intervals := min(maxTriggerSweepSamples, int(math.Ceil(displacement.Len()/radius)))
+	for i := 1; i < intervals; i++ {
+		point := start.Add(displacement.Mul(float64(i) / float64(intervals)))
+		if trigger.ClosestPointWorld(point).Sub(point).LenSqr() <= radius*radius {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,57 @@
+package feather
+
+import (
+	"testing"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// TestWorld_FastBodyCrossesThinTrigger fires a bullet through a thin checkpoint: its positions at the end
+// of the substeps are on both sides of the trigger, the sweep must still report the crossing
+func TestWorld_FastBodyCrossesThinTrigger(t *testing.T) {
+	world := &World{Substeps: 4, Events: NewEvents()}
+	checkpoint := createBox(mgl64.Vec3{5, 0, 0}, mgl64.Vec3{0.05, 2, 2}, actor.BodyTypeStatic)
+	checkpoint.IsTrigger = true
+	world.AddBody(checkpoint)
+
+	// 2.5 m per substep: 2.8, 5.3, 7.8, 10.3
+	bullet := createSphere(mgl64.Vec3{0.3, 0, 0}, 0.1, actor.BodyTypeDynamic)
+	bullet.Velocity = mgl64.Vec3{600, 0, 0}
+	world.AddBody(bullet)
+
+	enter, exit := &eventCapture{}, &eventCapture{}
+	world.Events.Subscribe(TRIGGER_ENTER, enter.capture)
+	world.Events.Subscribe(TRIGGER_EXIT, exit.capture)
+
+	world.Step(1.0 / 60.0)
+	if enter.count() != 1 {
+		t.Fatalf("TRIGGER_ENTER events = %d, want 1", enter.count())
+	}
+	if event := enter.events[0].(TriggerEnterEvent); event.BodyA != checkpoint || event.BodyB != bullet {
+		t.Errorf("TRIGGER_ENTER bodies = %v, %v, want the checkpoint and the bullet", event.BodyA.Id, event.BodyB.Id)
+	}
+
+	world.Step(1.0 / 60.0)
+	if exit.count() != 1 {
+		t.Errorf("TRIGGER_EXIT events = %d, want 1 once the bullet is gone", exit.count())
+	}
+}
+
+// TestSweptOverlap_SlowBody checks slow bodies are left to the narrow phase: their bounding sphere
+// touching the trigger is not a trigger event
+func TestSweptOverlap_SlowBody(t *testing.T) {
+	trigger := createBox(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{1, 1, 1}, actor.BodyTypeStatic)
+	body := createBox(mgl64.Vec3{2.2, 0, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+	body.PreviousTransform.Position = mgl64.Vec3{2.4, 0, 0}
+
+	if sweptOverlap(body, trigger, trigger.Shape.GetAABB()) {
+		t.Error("expected no swept overlap for a body moving less than its radius")
+	}
+
+	// The same body moving fast through the trigger
+	body.PreviousTransform.Position = mgl64.Vec3{-3, 0, 0}
+	if !sweptOverlap(body, trigger, trigger.Shape.GetAABB()) {
+		t.Error("expected a swept overlap for a body crossing the trigger")
+	}
+}
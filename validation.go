@@ -0,0 +1,117 @@
+package feather
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/akmonengine/feather/constraint"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+// ErrInvalidState is wrapped by the ValidationError returned by Step, see World.Validate
+var ErrInvalidState = errors.New("feather: invalid state")
+
+// validationRotationTolerance is the accepted deviation of the rotation quaternion length from 1
+const validationRotationTolerance = 1e-6
+
+// StepPhase names the phase of a Step after which the state is validated
+type StepPhase string
+
+const (
+	// PhaseStart is the state given to Step, after the queued commands and the shape changes
+	PhaseStart         StepPhase = "start"
+	PhaseIntegrate     StepPhase = "integrate"
+	PhaseSolvePosition StepPhase = "solve position"
+	PhaseUpdate        StepPhase = "update"
+	PhaseStabilize     StepPhase = "stabilize"
+	PhaseSolveVelocity StepPhase = "solve velocity"
+)
+
+// ValidationError identifies the first body found in an invalid state by World.Validate,
+// and the phase of the Step which produced it
+type ValidationError struct {
+	Body  *actor.RigidBody
+	Phase StepPhase
+	// Substep is the index of the substep, 0 for PhaseStart
+	Substep int
+	Reason  string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("feather: %s after %s (substep %d): %s", e.Body.Label(), e.Phase, e.Substep, e.Reason)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidState
+}
+
+// validate checks the enabled bodies after a phase of the Step, and the impulses of the contacts
+// once the velocities are solved. It returns nil when World.Validate is not set.
+func (w *World) validate(phase StepPhase, substep int, constraints []*constraint.ContactConstraint) error {
+	if !w.Validate {
+		return nil
+	}
+
+	for _, body := range w.Bodies {
+		if !body.IsEnabled() {
+			continue
+		}
+		if reason := invalidBodyState(body); reason != "" {
+			return &ValidationError{Body: body, Phase: phase, Substep: substep, Reason: reason}
+		}
+	}
+
+	if phase != PhaseSolveVelocity {
+		return nil
+	}
+	for _, c := range constraints {
+		if reason := invalidImpulses(c); reason != "" {
+			return &ValidationError{Body: c.BodyB, Phase: phase, Substep: substep, Reason: reason}
+		}
+	}
+
+	return nil
+}
+
+// invalidBodyState describes the first invalid value of the body, "" if it is valid
+func invalidBodyState(body *actor.RigidBody) string {
+	switch rotation := body.Transform.Rotation; {
+	case !isFiniteVec(body.Transform.Position):
+		return fmt.Sprintf("position not finite: %v", body.Transform.Position)
+	case !isFinite(rotation.W) || !isFiniteVec(rotation.V):
+		return fmt.Sprintf("rotation not finite: %v", rotation)
+	case math.Abs(rotation.Len()-1) > validationRotationTolerance:
+		return fmt.Sprintf("rotation not normalized (length %v)", rotation.Len())
+	case !isFiniteVec(body.Velocity):
+		return fmt.Sprintf("velocity not finite: %v", body.Velocity)
+	case !isFiniteVec(body.AngularVelocity):
+		return fmt.Sprintf("angular velocity not finite: %v", body.AngularVelocity)
+	}
+
+	return ""
+}
+
+// invalidImpulses describes the first impulse of the contact which is not finite, "" if they all are
+func invalidImpulses(c *constraint.ContactConstraint) string {
+	if !isFinite(c.SpinImpulse) {
+		return fmt.Sprintf("spin impulse of the contact with %s not finite: %v", c.BodyA.Label(), c.SpinImpulse)
+	}
+	for i, point := range c.Points {
+		if !isFinite(point.NormalImpulse) || !isFiniteVec(point.TangentImpulse) {
+			return fmt.Sprintf("impulse of the contact point %d with %s not finite: %v, %v",
+				i, c.BodyA.Label(), point.NormalImpulse, point.TangentImpulse)
+		}
+	}
+
+	return ""
+}
+
+func isFinite(x float64) bool {
+	return !math.IsNaN(x) && !math.IsInf(x, 0)
+}
+
+func isFiniteVec(v mgl64.Vec3) bool {
+	return isFinite(v[0]) && isFinite(v[1]) && isFinite(v[2])
+}
@@ -0,0 +1,73 @@
+package feather
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestWorld_Validate(t *testing.T) {
+	world := NewWorld(WithValidation())
+	ground := createBox(mgl64.Vec3{0, -0.5, 0}, mgl64.Vec3{10, 0.5, 10}, actor.BodyTypeStatic)
+	world.AddBody(ground)
+	box := createBox(mgl64.Vec3{0, 0.5, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+	box.Name = "box"
+	world.AddBody(box)
+	other := createSphere(mgl64.Vec3{5, 3, 0}, 0.5, actor.BodyTypeDynamic)
+	world.AddBody(other)
+
+	for range 30 {
+		if err := world.Step(1.0 / 60.0); err != nil {
+			t.Fatalf("Step() = %v, want nil for a valid world", err)
+		}
+	}
+
+	// A NaN force reaches the velocity at the integration
+	box.ApplyForce(mgl64.Vec3{math.NaN(), 0, 0})
+	err := world.Step(1.0 / 60.0)
+	var validationError *ValidationError
+	if !errors.As(err, &validationError) || !errors.Is(err, ErrInvalidState) {
+		t.Fatalf("Step() = %v, want a ValidationError", err)
+	}
+	if validationError.Body != box || validationError.Phase != PhaseIntegrate || validationError.Substep != 0 {
+		t.Errorf("error on %s after %s (substep %d), want box after %s (substep 0)",
+			validationError.Body.Label(), validationError.Phase, validationError.Substep, PhaseIntegrate)
+	}
+
+	// The invalid state stops the substeps before it spreads to the other bodies, and it is reported at the start of the next Step
+	if !isFiniteVec(other.Transform.Position) || !isFiniteVec(ground.Transform.Position) {
+		t.Errorf("positions %v and %v, want the other bodies untouched", other.Transform.Position, ground.Transform.Position)
+	}
+	err = world.Step(1.0 / 60.0)
+	if !errors.As(err, &validationError) || validationError.Body != box || validationError.Phase != PhaseStart {
+		t.Errorf("Step() = %v, want a ValidationError on box at %s", err, PhaseStart)
+	}
+}
+
+func TestWorld_ValidateDisabled(t *testing.T) {
+	world := NewWorld()
+	body := createSphere(mgl64.Vec3{}, 0.5, actor.BodyTypeDynamic)
+	body.Velocity = mgl64.Vec3{math.Inf(1), 0, 0}
+	world.AddBody(body)
+
+	if err := world.Step(1.0 / 60.0); err != nil {
+		t.Errorf("Step() = %v, want nil without Validate", err)
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	body := createSphere(mgl64.Vec3{}, 0.5, actor.BodyTypeDynamic)
+	body.Name = "ball"
+	body.Transform.Rotation = mgl64.Quat{W: 2}
+
+	world := NewWorld(WithValidation())
+	world.AddBody(body)
+	err := world.Step(1.0 / 60.0)
+	want := "feather: ball after start (substep 0): rotation not normalized (length 2)"
+	if err == nil || err.Error() != want {
+		t.Errorf("Step() = %v, want %q", err, want)
+	}
+}
@@ -1,6 +1,12 @@
 package feather
 
 import (
+	"cmp"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
 	"github.com/akmonengine/feather/actor"
 	"github.com/akmonengine/feather/constraint"
 	"github.com/go-gl/mathgl/mgl64"
@@ -8,21 +14,217 @@ import (
 
 const DEFAULT_WORKERS = 1
 
+// DEFAULT_BRUTE_FORCE_THRESHOLD is the body count under which the broad phase tests all the pairs
+// instead of using the SpatialGrid, whose overhead exceeds the brute force for small scenes
+const DEFAULT_BRUTE_FORCE_THRESHOLD = 50
+
+// EXTREME_MASS_RATIO is the mass ratio between two dynamic bodies in contact reported in Stats
+const EXTREME_MASS_RATIO = 100.0
+
+const (
+	// SLEEP_TIME_THRESHOLD is the duration (s) a body must stay under the sleep velocities to fall asleep
+	SLEEP_TIME_THRESHOLD = 0.1
+	// SLEEP_VELOCITY_THRESHOLD is the linear velocity (m/s) under which a body can sleep
+	SLEEP_VELOCITY_THRESHOLD = 0.05
+	// SLEEP_ANGULAR_VELOCITY_THRESHOLD is the angular velocity (rad/s) under which a body can sleep
+	SLEEP_ANGULAR_VELOCITY_THRESHOLD = 0.05
+	// WAKE_VELOCITY_THRESHOLD and WAKE_ANGULAR_VELOCITY_THRESHOLD are the velocities a sleeping body must receive
+	// to wake up. They are higher than the sleep velocities, so solver noise does not wake resting stacks.
+	WAKE_VELOCITY_THRESHOLD         = 0.15
+	WAKE_ANGULAR_VELOCITY_THRESHOLD = 0.15
+)
+
+const (
+	// SOLVER_PBD computes the correction of each constraint from 0 at each position pass:
+	// the compliance softens each pass, the constraints get stiffer with World.PositionIterations
+	SOLVER_PBD Solver = iota
+	// SOLVER_XPBD accumulates the Lagrange multiplier of each constraint over the passes of a substep:
+	// the compliance gives the same stiffness whatever the passes and the time step, soft contacts sink
+	// under their load, and the stiff stacks and attachments converge with the passes at large time steps
+	SOLVER_XPBD
+)
+
+// Solver is the position backend of the world, see World.Solver
+type Solver uint8
+
+// PreSolveHook receives the contacts detected during a substep, before they are solved.
+// It returns the contacts to solve (it may filter them or add scripted contacts), and extra
+// constraints solved along with them during this substep only (e.g. gameplay forces).
+type PreSolveHook func(h float64, contacts []*constraint.ContactConstraint) ([]*constraint.ContactConstraint, []constraint.Constraint)
+
 type World struct {
 	// List of all rigid bodies in the world
 	Bodies []*actor.RigidBody
 	// Gravity acceleration (m/s², or N/kg)
-	Gravity     mgl64.Vec3
-	Substeps    int
+	Gravity  mgl64.Vec3
+	Substeps int
+	// TimeScale multiplies the duration given to Step, e.g. 0.25 for slow motion or 2 for fast forward.
+	// 0 runs at the normal speed. Every duration of the Step is scaled (integration, damping, compliance, sleep time),
+	// and fast forward runs proportionally more substeps so each one lasts as long as at the normal speed.
+	TimeScale   float64
 	SpatialGrid *SpatialGrid
-	Workers     int
+	// Broadphase replaces the SpatialGrid when set, e.g. a DynamicTree for bodies of very different sizes
+	Broadphase Broadphase
+	Workers    int
+	// Goroutines of the broad phase pair search, 0 uses all the cores (runtime.GOMAXPROCS).
+	// The pairs are merged in a fixed order, the simulation does not depend on it.
+	BroadPhaseWorkers int
+	// Goroutines solving the islands, 0 uses all the cores (runtime.GOMAXPROCS).
+	// The islands share no dynamic body, the simulation does not depend on it.
+	SolverWorkers int
+	// Body count under which the SpatialGrid (or Broadphase) is skipped in favor of a brute force broad phase
+	// 0 uses DEFAULT_BRUTE_FORCE_THRESHOLD, a negative value always uses the SpatialGrid
+	BruteForceThreshold int
+
+	// PositionIterations and VelocityIterations are the passes of the solver over the constraints at each substep,
+	// 0 uses DEFAULT_POSITION_ITERATIONS and DEFAULT_VELOCITY_ITERATIONS. More passes stiffen the tall stacks
+	// and the heavy chains, at a cost proportional to the solver time; more substeps are usually more accurate.
+	PositionIterations int
+	VelocityIterations int
+	// Solver selects the position backend, SOLVER_PBD by default
+	Solver Solver
+	// ContactCompliance is the inverse stiffness (m/N) of the contacts, 0 uses constraint.DefaultCompliance.
+	// It is set on the contacts without compliance after PreSolve, which may set the compliance of each contact.
+	ContactCompliance float64
+
+	// SplitImpulse separates the correction of the penetration the contacts had before each substep (split impulse):
+	// the solver corrects a part of it at each substep, and its velocity is removed after the velocities are derived
+	// from the positions. Bodies spawned or pushed inside each other are separated without being launched.
+	SplitImpulse bool
+	// PenetrationSlop is the existing penetration (m) left uncorrected, 0 uses DEFAULT_PENETRATION_SLOP
+	PenetrationSlop float64
+	// CorrectionRate is the fraction of the existing penetration beyond the slop corrected at each substep,
+	// in ]0, 1]. 0 uses DEFAULT_CORRECTION_RATE
+	CorrectionRate float64
 
 	Events Events
+
+	// Diagnostics of the last Step
+	Stats Stats
+
+	// Tolerances of the sleep system, the zero fields use the defaults
+	Tolerances Tolerances
+
+	// DisableRestitutionClamp keeps the restitution of slow contacts.
+	// By default, contacts approaching slower than 2*|Gravity|*h do not bounce,
+	// so stacks with a high restitution do not gain energy.
+	DisableRestitutionClamp bool
+
+	// NormalSmoothing low-pass filters the contact normal of persistent pairs, in [0, 1[ (0 disables it).
+	// It is the weight of the previous normal: 0.5 averages the last substeps, 0.8 smooths over ~5 substeps.
+	// It removes the flicker of EPA picking different faces on near-degenerate configurations.
+	NormalSmoothing float64
+
+	// MaxVelocity (m/s) and MaxAngularVelocity (rad/s) bound the velocities of the bodies (0 disables them),
+	// once the forces are integrated (before the bodies move) and after the velocity solve of each substep:
+	// an explosive solver feedback cannot launch a body at an absurd speed through the others.
+	// RigidBody.MaxVelocity and RigidBody.MaxAngularVelocity override them for a body.
+	MaxVelocity        float64
+	MaxAngularVelocity float64
+
+	// Validate checks the state of the bodies after each phase of the Step (finite positions and velocities,
+	// normalized rotations, finite contact impulses). The first invalid body stops the substeps, and Step
+	// returns a ValidationError identifying it and the phase, instead of propagating the NaNs to the other bodies.
+	// It costs a pass over the bodies per phase, it is meant for debugging.
+	Validate bool
+
+	// MaxMassRatio clamps the mass ratio of the contacts between dynamic bodies (0 disables it).
+	// See constraint.ContactConstraint.MaxMassRatio
+	MaxMassRatio float64
+
+	// PreSolve is called at each substep, before solving, with the contacts of the substep (optional)
+	PreSolve PreSolveHook
+
+	// StepBudget is the wall-clock duration a Step should not exceed (0 disables it).
+	// When the last substep predicts an overrun, the remaining substeps are solved with a single position and
+	// velocity iteration, see Stats.DegradedSubsteps. If they still overrun, the islands farthest from BudgetFocus
+	// are deferred until the remaining substeps fit: they are frozen for the rest of the Step, and the time they
+	// owe is simulated at the start of the next Step, before the other bodies move (Stats.DeferredIslands,
+	// Stats.CatchUpSubsteps). An island owes at most a Step, the time beyond it is lost (Stats.DroppedIslands).
+	// The substeps keep their duration, so the degraded Step stays as stable. The simulation then depends
+	// on the machine load, it is not reproducible.
+	StepBudget time.Duration
+	// BudgetFocus is the point kept simulated under the StepBudget, e.g. the camera or the player:
+	// the islands farthest from it are deferred first
+	BudgetFocus mgl64.Vec3
+
+	// Allocator provides the contacts of each Step, released at the start of the next one.
+	// nil allocates them on the heap, see ArenaAllocator to reuse them between steps.
+	Allocator Allocator
+
+	// Collisions maps the pairs of shape types to their collision functions, e.g. for custom shapes.
+	// nil uses the built-in functions, see NewCollisionDispatcher.
+	Collisions *CollisionDispatcher
+
+	// External acceleration providers, summed with Gravity, and force fields applied before the integration
+	accelerations []AccelerationProvider
+	forceFields   []ForceField
+
+	// User constraints (e.g. constraint.VelocityMatchConstraint), solved before the contacts
+	constraints []constraint.Constraint
+
+	// Particles integrated with the bodies, coupled to them by constraints (e.g. constraint.ParticleAttachment)
+	particles []*actor.Particle
+
+	// Whether the last broad phase skipped the SpatialGrid or Broadphase (its content is then outdated)
+	bruteForce bool
+	// Whether bodies were added or removed since the SpatialGrid or Broadphase indexed them:
+	// the indices it holds no longer match Bodies until it is updated, see indexedBroadphase
+	staleBroadphase bool
+
+	// Largest contacts count of a substep, used to size the contacts list
+	contactsCapacity int
+	// Index of each body in Bodies, the contacts are sorted by it, see sortContacts
+	bodyOrder map[*actor.RigidBody]int
+	// Pairs (ordered by OrderPair) whose collisions are disabled by a joint, see filterPairs
+	disabledPairs map[Pair]bool
+	// Soft bodies added by AddSoftBody, their particles collide with the bodies, see collideSoftBodies
+	softBodies []*SoftBody
+	// Cloths added by AddCloth, stepped after the bodies, see stepCloths
+	cloths []*Cloth
+	// Particle systems added by AddParticleSystem, stepped after the cloths, see stepParticleSystems
+	particleSystems []*ParticleSystem
+
+	// Counters of the current Step, copied into Stats at its end
+	counters stepCounters
+	// Islands solved at the last substep, the candidates to defer, see StepBudget
+	islands []island
+	// Time (s) owed by the bodies of the deferred islands, simulated at the start of the next Step
+	deferred map[*actor.RigidBody]float64
+
+	// Filtered contact normal of each pair, oriented from the BodyA to the BodyB of OrderPair
+	smoothedNormals map[Pair]mgl64.Vec3
+	previousNormals map[Pair]mgl64.Vec3
+
+	// Combined material of the pairs in contact, and the Step counter used to prune them
+	materials    map[Pair]*materialCacheEntry
+	materialStep uint64
+
+	// Contact points of the pairs at the end of the last substep, to warm start the next one, and the substep counter
+	manifolds       map[Pair]*manifoldCacheEntry
+	manifoldSubstep uint64
+
+	// Handles of the bodies added by AddBody, see BodyID: the slots by index, the free ones, and the handle of each body
+	bodySlots []bodySlot
+	freeSlots []uint32
+	bodyIDs   map[*actor.RigidBody]BodyID
+
+	// Commands queued by Enqueue, run at the start of the next Step
+	commands      []func(*World)
+	commandsMutex sync.Mutex
 }
 
-// AddBody adds a rigid body to the world
-func (w *World) AddBody(body *actor.RigidBody) {
+// AddBody adds a rigid body to the world, and returns its stable handle, see BodyID.
+// A body already in the world is not added twice, its handle is returned.
+func (w *World) AddBody(body *actor.RigidBody) BodyID {
+	if id, ok := w.bodyIDs[body]; ok {
+		return id
+	}
+
 	w.Bodies = append(w.Bodies, body)
+	w.staleBroadphase = true
+
+	return w.registerBody(body)
 }
 
 // RemoveBody removes a rigid body from the world
@@ -37,78 +239,667 @@ func (w *World) RemoveBody(body *actor.RigidBody) {
 
 	if k != -1 {
 		w.Bodies = append(w.Bodies[:k], w.Bodies[k+1:]...)
+		w.staleBroadphase = true
 	}
+	w.unregisterBody(body)
+	delete(w.deferred, body)
 
 	delete(w.Events.sleepStates, body)
+	delete(w.Events.bodyOrder, body)
 	for pair := range w.Events.previousActivePairs {
-		if pair.bodyA == body || pair.bodyB == body {
+		if pair.BodyA == body || pair.BodyB == body {
 			delete(w.Events.previousActivePairs, pair)
+			delete(w.Events.separatedFrames, pair)
+		}
+	}
+	for pair := range w.Events.previousChildPairs {
+		if pair.BodyA == body || pair.BodyB == body {
+			delete(w.Events.previousChildPairs, pair)
+			delete(w.Events.separatedChildFrames, pair)
 		}
 	}
 }
 
-func (w *World) Step(dt float64) {
-	w.Workers = max(DEFAULT_WORKERS, w.Workers)
-	h := dt / float64(w.Substeps)
+// Clear removes the bodies, user constraints, particles, soft bodies, cloths and particle systems, empties the broad phase
+// and forgets the state of the events and the caches of the pairs, e.g. to reload a level. The allocated buffers
+// are kept, so the next level does not reallocate them. The configuration is kept: the options, the acceleration
+// providers, the force fields, the event listeners and the commands queued by Enqueue.
+// The handles of the removed bodies become stale, see BodyID. Nothing is emitted for the removed bodies.
+func (w *World) Clear() {
+	for _, body := range w.Bodies {
+		w.unregisterBody(body)
+	}
+	clear(w.Bodies)
+	w.Bodies = w.Bodies[:0]
+	clear(w.constraints)
+	w.constraints = w.constraints[:0]
+	clear(w.particles)
+	w.particles = w.particles[:0]
+	clear(w.softBodies)
+	w.softBodies = w.softBodies[:0]
+	clear(w.cloths)
+	w.cloths = w.cloths[:0]
+	clear(w.particleSystems)
+	w.particleSystems = w.particleSystems[:0]
+
+	// Updated without bodies, the broad phase removes all its entries and keeps its cells and nodes
+	if broadphase := w.broadphase(); broadphase != nil {
+		broadphase.Update(w.Bodies)
+	}
+	w.staleBroadphase = false
+	w.Events.clear()
 
-	for range w.Substeps {
-		w.integrate(h)
+	clear(w.bodyOrder)
+	clear(w.disabledPairs)
+	clear(w.smoothedNormals)
+	clear(w.previousNormals)
+	clear(w.materials)
+	clear(w.manifolds)
+	clear(w.deferred)
+	w.islands = nil
+	w.Stats = Stats{}
+}
 
-		// Phase 2.0: Collision pair finding - Broad phase
-		// Phase 2.1: Collision pair finding - narrow phase
-		constraints := w.detectCollision()
+// AddParticle adds a particle to the world, it is integrated under Gravity at each substep
+func (w *World) AddParticle(particle *actor.Particle) {
+	w.particles = append(w.particles, particle)
+}
 
-		constraints = w.Events.recordCollisions(constraints)
+// RemoveParticle removes a particle from the world
+// Its constraints are not removed, the caller owns them
+func (w *World) RemoveParticle(particle *actor.Particle) {
+	for i, other := range w.particles {
+		if other == particle {
+			w.particles = append(w.particles[:i], w.particles[i+1:]...)
+			return
+		}
+	}
+}
+
+// Particles returns the particles of the world
+func (w *World) Particles() []*actor.Particle {
+	return w.particles
+}
 
-		// Phase 3: Solver, only one iteration is required thanks to substeps
-		w.solvePosition(h, constraints)
+// Enqueue schedules a command at the start of the next Step, in the order of the calls.
+// It is safe to call from any goroutine, while the world is stepping: gameplay code requests
+// impulses, spawns or removals without locking the whole world. AddBody, RemoveBody and the impulses
+// of the bodies are not safe during a Step: EnqueueAddBody, EnqueueRemoveBody and EnqueueImpulse queue them.
+// Commands enqueued by a command run at the following Step.
+func (w *World) Enqueue(command func(*World)) {
+	w.commandsMutex.Lock()
+	w.commands = append(w.commands, command)
+	w.commandsMutex.Unlock()
+}
 
-		// Phase 4: Update Position & Velocity
-		// Calculate final velocities and commit positions
-		w.update(h)
+// EnqueueAddBody adds the body at the start of the next Step, see Enqueue.
+// added, if not nil, receives its handle then, on the goroutine running the Step.
+func (w *World) EnqueueAddBody(body *actor.RigidBody, added func(id BodyID)) {
+	w.Enqueue(func(w *World) {
+		id := w.AddBody(body)
+		if added != nil {
+			added(id)
+		}
+	})
+}
 
-		// Phase 5: Velocity
-		w.solveVelocity(h, constraints)
+// EnqueueRemoveBody removes the body at the start of the next Step, see Enqueue
+func (w *World) EnqueueRemoveBody(body *actor.RigidBody) {
+	w.Enqueue(func(w *World) {
+		w.RemoveBody(body)
+	})
+}
 
-		w.trySleep(h)
+// EnqueueRemoveBodyByID removes the body of the handle at the start of the next Step, see Enqueue.
+// A handle stale by then is ignored.
+func (w *World) EnqueueRemoveBodyByID(id BodyID) {
+	w.Enqueue(func(w *World) {
+		w.RemoveBodyByID(id)
+	})
+}
+
+// EnqueueImpulse applies an impulse in N⋅s at the center of mass at the start of the next Step, see Enqueue
+// and actor.RigidBody.ApplyImpulse: the body is not modified while the solver may be running.
+func (w *World) EnqueueImpulse(body *actor.RigidBody, impulse mgl64.Vec3) {
+	w.Enqueue(func(w *World) {
+		body.ApplyImpulse(impulse)
+	})
+}
+
+// EnqueueImpulseAtPoint applies an impulse in N⋅s at a point in world space at the start of the next Step,
+// see EnqueueImpulse and actor.RigidBody.ApplyImpulseAtPoint
+func (w *World) EnqueueImpulseAtPoint(body *actor.RigidBody, impulse, point mgl64.Vec3) {
+	w.Enqueue(func(w *World) {
+		body.ApplyImpulseAtPoint(impulse, point)
+	})
+}
+
+// runCommands runs the queued commands, the lock is released before running them
+func (w *World) runCommands() {
+	w.commandsMutex.Lock()
+	commands := w.commands
+	w.commands = nil
+	w.commandsMutex.Unlock()
+
+	for _, command := range commands {
+		command(w)
+	}
+}
+
+// AddConstraint adds a user constraint to the world
+func (w *World) AddConstraint(c constraint.Constraint) {
+	w.constraints = append(w.constraints, c)
+}
+
+// RemoveConstraint removes a user constraint from the world
+// The constraints are not removed with their bodies, the caller owns them
+func (w *World) RemoveConstraint(c constraint.Constraint) {
+	for i, other := range w.constraints {
+		if other == c {
+			w.constraints = append(w.constraints[:i], w.constraints[i+1:]...)
+			return
+		}
+	}
+}
+
+// Step advances the simulation by dt seconds. The error is always nil unless Validate is set, see ValidationError
+func (w *World) Step(dt float64) error {
+	start := time.Now()
+	w.Workers = max(DEFAULT_WORKERS, w.Workers)
+	dt *= w.timeScale()
+	substeps := w.scaledSubsteps()
+	h := dt / float64(substeps)
+
+	w.counters.reset()
+	w.allocator().Reset()
+	w.runCommands()
+	w.applyShapeChanges()
+
+	// An invalid state stops the substeps, the Step is still completed so the world stays consistent
+	err := w.validate(PhaseStart, 0, nil)
+	substep := 0
+	if catchUp := w.catchUpSubsteps(h); catchUp > 0 {
+		// The islands deferred by the last Step catch up first, alone, unless they would overrun the budget again
+		var last time.Duration
+		for ; substep < catchUp && err == nil && !w.overBudget(start, last, catchUp+substeps-substep); substep++ {
+			w.freezeCaughtUp(h, substep)
+			substepStart := time.Now()
+			err = w.substep(h, substep)
+			last = time.Since(substepStart)
+		}
+		w.endCatchUp(h, substep)
+	}
+
+	// Every body runs the first substep, so the deferred islands keep their pairs in the events
+	var last time.Duration
+	for regular := 0; regular < substeps && err == nil; regular++ {
+		// Out of budget: the solver iterations are cut first, then the islands far from BudgetFocus are deferred.
+		// The substeps keep their duration h, a longer one would let the bodies tunnel and the stacks explode.
+		if w.overBudget(start, last, substeps-regular) {
+			if !w.degradeIterations() {
+				w.deferIslands(start, last, substeps-regular, h, dt)
+			}
+		}
+		if w.counters.degraded {
+			w.counters.degradedSubsteps++
+		}
+
+		substepStart := time.Now()
+		err = w.substep(h, substep+regular)
+		last = time.Since(substepStart)
+	}
+	w.releaseDeferred()
+	// The forces applied by the user act during a single Step
+	for _, body := range w.Bodies {
+		body.ClearForces()
 	}
+	w.stepCloths(dt)
+	w.stepParticleSystems(dt)
 
+	w.pruneMaterials()
+	w.pruneManifolds()
 	w.Events.processSleepEvents(w.Bodies)
 	w.Events.flush()
+
+	w.Stats = w.counters.stats()
+
+	return err
+}
+
+// timeScale returns TimeScale, 1 if it is not set
+func (w *World) timeScale() float64 {
+	if !(w.TimeScale > 0) {
+		return 1
+	}
+
+	return w.TimeScale
+}
+
+// scaledSubsteps returns the substeps of a Step: fast forward runs more of them, so each one lasts
+// no longer than at the normal speed and the solver stays as stable
+func (w *World) scaledSubsteps() int {
+	if scale := w.timeScale(); scale > 1 {
+		return int(math.Ceil(float64(w.Substeps) * scale))
+	}
+
+	return w.Substeps
+}
+
+func (w *World) substep(h float64, substep int) error {
+	w.integrate(h)
+	if err := w.validate(PhaseIntegrate, substep, nil); err != nil {
+		return err
+	}
+
+	// Phase 2.0: Collision pair finding - Broad phase
+	// Phase 2.1: Collision pair finding - narrow phase
+	collisionStart := time.Now()
+	constraints := w.detectCollision()
+
+	constraints = w.Events.recordCollisions(constraints)
+	w.sweepTriggers()
+	w.smoothNormals(constraints)
+	w.combineMaterials(constraints)
+	constraints, userConstraints := w.preSolve(h, constraints)
+	userConstraints = w.collideSoftBodies(userConstraints)
+
+	// Phase 3: Solver, one pass is usually enough thanks to substeps, see PositionIterations
+	// The islands are shared by the position and the velocity phases, their contacts and constraints are the same
+	solveStart := time.Now()
+	w.counters.collisionTime += solveStart.Sub(collisionStart)
+	islands, unconnected := buildIslands(constraints, userConstraints)
+	w.counters.islands = len(islands)
+	w.islands = islands
+	w.solvePosition(h, constraints, userConstraints, islands, unconnected)
+	if err := w.validate(PhaseSolvePosition, substep, nil); err != nil {
+		return err
+	}
+
+	// Phase 4: Update Position & Velocity
+	// Calculate final velocities and commit positions
+	w.update(h)
+	if err := w.validate(PhaseUpdate, substep, nil); err != nil {
+		return err
+	}
+	w.stabilize(h, constraints)
+	if err := w.validate(PhaseStabilize, substep, nil); err != nil {
+		return err
+	}
+
+	// Phase 5: Velocity
+	w.solveVelocity(h, constraints, islands, unconnected)
+	w.counters.solveTime += time.Since(solveStart)
+	if err := w.validate(PhaseSolveVelocity, substep, constraints); err != nil {
+		return err
+	}
+	w.removeBrokenJoints()
+
+	w.trySleep(h)
+
+	return nil
+}
+
+// removeBrokenJoints removes the user constraints broken during the substep, see constraint.Breakable
+func (w *World) removeBrokenJoints() {
+	w.constraints = slices.DeleteFunc(w.constraints, func(c constraint.Constraint) bool {
+		breakable, ok := c.(constraint.Breakable)
+		if !ok || !breakable.Broken() {
+			return false
+		}
+
+		w.Events.recordJointBroken(c)
+		return true
+	})
+}
+
+// overBudget estimates the duration of the Step from the last substep (0 before the first one),
+// and reports whether running the remaining ones would exceed StepBudget
+func (w *World) overBudget(start time.Time, last time.Duration, remaining int) bool {
+	if w.StepBudget <= 0 || last == 0 {
+		return false
+	}
+
+	return time.Since(start)+last*time.Duration(remaining) > w.StepBudget
+}
+
+// degradeIterations cuts the solver to a single position and velocity iteration for the rest of the Step.
+// Returns false if it already runs a single one, or was already cut: the Step cannot be made cheaper this way.
+func (w *World) degradeIterations() bool {
+	if w.counters.degraded {
+		return false
+	}
+	if iterations(w.PositionIterations, DEFAULT_POSITION_ITERATIONS) == 1 && iterations(w.VelocityIterations, DEFAULT_VELOCITY_ITERATIONS) == 1 {
+		return false
+	}
+	w.counters.degraded = true
+
+	return true
+}
+
+// positionIterations returns the passes of the solver over the positions, 1 when cut by the StepBudget
+func (w *World) positionIterations() int {
+	if w.counters.degraded {
+		return 1
+	}
+
+	return iterations(w.PositionIterations, DEFAULT_POSITION_ITERATIONS)
+}
+
+// velocityIterations returns the passes of the solver over the velocities, 1 when cut by the StepBudget
+func (w *World) velocityIterations() int {
+	if w.counters.degraded {
+		return 1
+	}
+
+	return iterations(w.VelocityIterations, DEFAULT_VELOCITY_ITERATIONS)
+}
+
+// applyShapeChanges updates the bodies whose shape was modified or replaced since the last step,
+// and emits COMPOUND_CHANGED for the compounds whose children were added or removed.
+// The sleeping bodies overlapping the previous or the new AABB are woken up,
+// so a stack does not stay floating above a shrunk body, and the contacts cached for the previous shape are discarded.
+func (w *World) applyShapeChanges() {
+	for _, body := range w.Bodies {
+		previous, swapped := body.SwappedShape()
+		if !swapped {
+			previous = body.Shape.GetAABB()
+		}
+		compound, isCompound := body.Shape.(*actor.Compound)
+		childrenChanged := isCompound && compound.ChildrenChanged()
+		if !body.ApplyShapeChanges() {
+			continue
+		}
+		w.forgetManifolds(body)
+		if childrenChanged {
+			w.Events.buffer = append(w.Events.buffer, CompoundChangedEvent{Body: body})
+		}
+
+		current := body.Shape.GetAABB()
+		for _, other := range w.Bodies {
+			if other.IsSleeping && (previous.Overlaps(other.Shape.GetAABB()) || current.Overlaps(other.Shape.GetAABB())) {
+				other.WakeUp()
+			}
+		}
+	}
 }
 
 func (w *World) integrate(h float64) {
 	task(w.Workers, w.Bodies, func(body *actor.RigidBody) {
-		body.Integrate(h, w.Gravity)
+		w.applyForceFields(body, h)
+		maxVelocity, maxAngularVelocity := w.velocityLimits(body)
+		body.IntegrateClamped(h, w.acceleration(body), maxVelocity, maxAngularVelocity)
 	})
+
+	for _, particle := range w.particles {
+		particle.Integrate(h, w.Gravity)
+	}
 }
 
 func (w *World) detectCollision() []*constraint.ContactConstraint {
-	return NarrowPhase(BroadPhase(w.SpatialGrid, w.Bodies, w.Workers), w.Workers)
+	contacts := narrowPhase(w.filterPairs(w.broadPhase()), w.Workers, &w.counters, w.allocator(), w.contactsCapacity, w.collisions())
+	w.contactsCapacity = max(w.contactsCapacity, len(contacts))
+	w.sortContacts(contacts)
+
+	return contacts
+}
+
+// sortContacts orders the contacts by the index of their bodies in the world. The narrow phase workers
+// send them in any order, the islands and their solve would depend on the scheduling.
+// The contacts of a pair come from a single worker, the stable sort keeps their order.
+func (w *World) sortContacts(contacts []*constraint.ContactConstraint) {
+	if w.bodyOrder == nil {
+		w.bodyOrder = make(map[*actor.RigidBody]int, len(w.Bodies))
+	}
+	clear(w.bodyOrder)
+	for i, body := range w.Bodies {
+		w.bodyOrder[body] = i
+	}
+
+	slices.SortStableFunc(contacts, func(a, b *constraint.ContactConstraint) int {
+		if order := cmp.Compare(w.bodyOrder[a.BodyA], w.bodyOrder[b.BodyA]); order != 0 {
+			return order
+		}
+
+		return cmp.Compare(w.bodyOrder[a.BodyB], w.bodyOrder[b.BodyB])
+	})
+}
+
+// filterPairs drops the pairs whose collisions are disabled by a user constraint, see constraint.CollisionDisabler.
+// The pairs are gathered at each substep, so the removed and broken joints no longer disable them.
+func (w *World) filterPairs(pairs <-chan Pair) <-chan Pair {
+	if w.disabledPairs == nil {
+		w.disabledPairs = make(map[Pair]bool)
+	}
+	clear(w.disabledPairs)
+	for _, c := range w.constraints {
+		disabler, ok := c.(constraint.CollisionDisabler)
+		if !ok || !disabler.CollisionDisabled() {
+			continue
+		}
+		if connector, ok := c.(constraint.Connector); ok {
+			if bodies := connector.Bodies(); len(bodies) == 2 {
+				w.disabledPairs[OrderPair(bodies[0], bodies[1])] = true
+			}
+		}
+	}
+	if len(w.disabledPairs) == 0 {
+		return pairs
+	}
+
+	filtered := make(chan Pair, w.Workers)
+	go func() {
+		defer close(filtered)
+
+		for pair := range pairs {
+			if !w.disabledPairs[pair.Ordered()] {
+				filtered <- pair
+			}
+		}
+	}()
+
+	return filtered
+}
+
+// allocator returns the Allocator of the world, or the heap allocator if it is not set
+func (w *World) allocator() Allocator {
+	if w.Allocator == nil {
+		return heapAllocator{}
+	}
+
+	return w.Allocator
+}
+
+// collisions returns the CollisionDispatcher of the world, or the built-in functions if it is not set
+func (w *World) collisions() *CollisionDispatcher {
+	if w.Collisions == nil {
+		return defaultCollisions
+	}
+
+	return w.Collisions
+}
+
+// broadPhase selects the brute force or the Broadphase (SpatialGrid by default), depending on the body count
+// The selection is done at each substep, so the world switches seamlessly as the scene grows
+func (w *World) broadPhase() <-chan Pair {
+	threshold := w.BruteForceThreshold
+	if threshold == 0 {
+		threshold = DEFAULT_BRUTE_FORCE_THRESHOLD
+	}
+
+	broadphase := w.broadphase()
+	w.bruteForce = broadphase == nil || len(w.Bodies) < threshold
+	if w.bruteForce {
+		return BruteForceBroadPhase(w.Bodies)
+	}
+	w.staleBroadphase = false
+
+	return broadPhase(broadphase, w.Bodies, w.broadPhaseWorkers())
+}
+
+// preSolve calls the PreSolve hook, which may replace the contacts.
+// Returns the contacts and the user constraints to solve during the substep.
+func (w *World) preSolve(h float64, contacts []*constraint.ContactConstraint) ([]*constraint.ContactConstraint, []constraint.Constraint) {
+	if w.PreSolve == nil {
+		return contacts, w.constraints
+	}
+
+	contacts, extra := w.PreSolve(h, contacts)
+	if len(extra) == 0 {
+		return contacts, w.constraints
+	}
+
+	// Clip the capacity, so the extra constraints never overwrite the world ones
+	return contacts, append(w.constraints[:len(w.constraints):len(w.constraints)], extra...)
 }
 
-func (w *World) solvePosition(h float64, constraints []*constraint.ContactConstraint) {
-	task(w.Workers, constraints, func(constraint *constraint.ContactConstraint) {
-		constraint.SolvePosition(h)
+func (w *World) solvePosition(h float64, constraints []*constraint.ContactConstraint, userConstraints []constraint.Constraint, islands []island, unconnected []constraint.Constraint) {
+	// Anchored before any correction, at the transforms the points were detected with
+	accumulate := w.Solver == SOLVER_XPBD
+	slop, rate := w.stabilization()
+	task(w.Workers, constraints, func(c *constraint.ContactConstraint) {
+		c.Anchor()
+		c.BeginSubstep(accumulate)
+		if w.SplitImpulse {
+			c.SplitPenetration(h, slop, rate)
+		}
+		c.MaxMassRatio = w.MaxMassRatio
+		if c.Compliance == 0 {
+			c.Compliance = w.ContactCompliance
+		}
+		if constraint.MassRatio(c.BodyA, c.BodyB) > EXTREME_MASS_RATIO {
+			w.counters.addExtremeMassRatio(c.BodyA, c.BodyB)
+		}
 	})
+	w.warmStart(constraints)
+	for _, c := range userConstraints {
+		if preSolver, ok := c.(constraint.PreSolver); ok {
+			preSolver.PreSolve(h)
+		}
+		if accumulator, ok := c.(constraint.Accumulator); ok {
+			accumulator.BeginSubstep(accumulate)
+		}
+	}
+
+	for range w.positionIterations() {
+		for _, c := range unconnected {
+			c.SolvePosition(h)
+		}
+
+		// The islands share no dynamic body, each one is solved in order by a single worker
+		balancedTask(w.solverWorkers(), islands, func(island island) {
+			for _, c := range island.constraints {
+				c.SolvePosition(h)
+			}
+			for _, c := range island.contacts {
+				c.SolvePosition(h)
+			}
+		})
+	}
+}
+
+// iterations returns the passes count of the solver, or the default one if it is not set
+func iterations(count, defaultCount int) int {
+	if count <= 0 {
+		return defaultCount
+	}
+
+	return count
 }
 
 func (w *World) update(h float64) {
 	task(w.Workers, w.Bodies, func(body *actor.RigidBody) {
 		body.Update(h)
 	})
+
+	for _, particle := range w.particles {
+		particle.Update(h)
+	}
+}
+
+// stabilization returns the penetration slop and the correction rate of the split impulse, see SplitImpulse
+func (w *World) stabilization() (float64, float64) {
+	slop := w.PenetrationSlop
+	if slop <= 0 {
+		slop = DEFAULT_PENETRATION_SLOP
+	}
+	rate := w.CorrectionRate
+	if rate <= 0 {
+		rate = DEFAULT_CORRECTION_RATE
+	}
+
+	return slop, min(rate, 1)
 }
 
-func (w *World) solveVelocity(h float64, constraints []*constraint.ContactConstraint) {
-	task(w.Workers, constraints, func(constraint *constraint.ContactConstraint) {
-		constraint.SolveVelocity(h)
+// stabilize removes the velocity of the correction of the existing penetrations, see SplitImpulse
+func (w *World) stabilize(h float64, constraints []*constraint.ContactConstraint) {
+	if !w.SplitImpulse {
+		return
+	}
+
+	task(w.Workers, constraints, func(c *constraint.ContactConstraint) {
+		c.Stabilize(h)
 	})
 }
 
+func (w *World) solveVelocity(h float64, constraints []*constraint.ContactConstraint, islands []island, unconnected []constraint.Constraint) {
+	restitutionThreshold := 0.0
+	if !w.DisableRestitutionClamp {
+		restitutionThreshold = 2.0 * w.Gravity.Len() * h
+	}
+
+	for range w.velocityIterations() {
+		// The constraints without island may share bodies with any island, they are solved sequentially
+		for _, c := range unconnected {
+			c.SolveVelocity(h)
+		}
+
+		balancedTask(w.solverWorkers(), islands, func(island island) {
+			for _, c := range island.constraints {
+				c.SolveVelocity(h)
+			}
+			for _, c := range island.contacts {
+				c.RestitutionThreshold = restitutionThreshold
+				c.SolveVelocity(h)
+			}
+		})
+	}
+	// The impulses along the locked axes are discarded (see actor.RigidBody.SetAxisLocks), then the velocities are clamped
+	for _, body := range w.Bodies {
+		if body.IsActive() {
+			body.ApplyAxisLocks()
+		}
+		w.clampVelocity(body)
+	}
+	w.storeManifolds(constraints)
+}
+
+// clampVelocity bounds the velocities of the active body, see velocityLimits
+func (w *World) clampVelocity(body *actor.RigidBody) {
+	if !body.IsActive() {
+		return
+	}
+
+	body.ClampVelocity(w.velocityLimits(body))
+}
+
+// velocityLimits returns the limits of the body, MaxVelocity and MaxAngularVelocity for the ones it does not set
+func (w *World) velocityLimits(body *actor.RigidBody) (float64, float64) {
+	maxVelocity, maxAngularVelocity := w.MaxVelocity, w.MaxAngularVelocity
+	if body.MaxVelocity > 0 {
+		maxVelocity = body.MaxVelocity
+	}
+	if body.MaxAngularVelocity > 0 {
+		maxAngularVelocity = body.MaxAngularVelocity
+	}
+
+	return maxVelocity, maxAngularVelocity
+}
+
 // trySleep sets the body to sleep if its velocity is lower than the threshold, for a given duration
 // this method is too simple to use a task, it slows down in multiple goroutines
 func (w *World) trySleep(h float64) {
+	thresholds := w.Tolerances.withDefaults().thresholds()
 	for _, body := range w.Bodies {
-		body.TrySleep(h, 0.1, 0.05)
+		body.TrySleep(h, thresholds)
 	}
 }
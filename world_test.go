@@ -0,0 +1,920 @@
+package feather
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/akmonengine/feather/actor"
+	"github.com/akmonengine/feather/constraint"
+	"github.com/go-gl/mathgl/mgl64"
+)
+
+func TestWorld_ApplyShapeChanges(t *testing.T) {
+	world := &World{Substeps: 1, Events: NewEvents()}
+	ground := &actor.Box{HalfExtents: mgl64.Vec3{5, 1, 5}}
+	world.AddBody(actor.NewRigidBody(actor.Transform{Position: mgl64.Vec3{0, -1, 0}, Rotation: mgl64.QuatIdent()}, ground, actor.BodyTypeStatic, 0))
+
+	resting := createBox(mgl64.Vec3{0, 0.5, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+	resting.Sleep()
+	world.AddBody(resting)
+
+	far := createBox(mgl64.Vec3{20, 0.5, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+	far.Sleep()
+	world.AddBody(far)
+
+	// Shrink the ground below the resting box
+	ground.SetHalfExtents(mgl64.Vec3{5, 0.5, 5}, false)
+	world.applyShapeChanges()
+
+	if ground.GetAABB().Max.Y() != -0.5 {
+		t.Errorf("ground AABB not updated: %v", ground.GetAABB())
+	}
+	if resting.IsSleeping {
+		t.Error("the body resting on the resized ground should be woken up")
+	}
+	if !far.IsSleeping {
+		t.Error("a distant body should stay asleep")
+	}
+}
+
+func TestWorld_SetShape(t *testing.T) {
+	world := NewWorld()
+	world.AddBody(createPlane(mgl64.Vec3{0, 1, 0}, 0))
+	character := createBox(mgl64.Vec3{0, 1, 0}, mgl64.Vec3{0.3, 1, 0.3}, actor.BodyTypeDynamic)
+	world.AddBody(character)
+	crate := createBox(mgl64.Vec3{0, 2.25, 0}, mgl64.Vec3{0.25, 0.25, 0.25}, actor.BodyTypeDynamic)
+	world.AddBody(crate)
+	for range 120 {
+		world.Step(1.0 / 60.0)
+	}
+	crate.Sleep()
+
+	// Crouching: the crate sleeping on the previous shape is woken up, the contacts of the character are forgotten
+	if err := character.SetShape(&actor.Box{HalfExtents: mgl64.Vec3{0.3, 0.5, 0.3}}); err != nil {
+		t.Fatalf("SetShape failed: %v", err)
+	}
+	world.applyShapeChanges()
+	if crate.IsSleeping {
+		t.Error("the crate on the character should be woken up")
+	}
+	for pair := range world.manifolds {
+		if pair.BodyA == character || pair.BodyB == character {
+			t.Errorf("the contacts of %v should be discarded", pair)
+		}
+	}
+
+	for range 120 {
+		world.Step(1.0 / 60.0)
+	}
+	if math.Abs(character.Transform.Position.Y()-0.5) > 0.02 || math.Abs(crate.Transform.Position.Y()-1.25) > 0.05 {
+		t.Errorf("character at %v, crate at %v, want them resting on the crouched character", character.Transform.Position, crate.Transform.Position)
+	}
+}
+
+func TestWorld_Clear(t *testing.T) {
+	world := NewWorld(WithBroadPhase(NewSpatialGrid(1.0, 1024), -1))
+	exits := &eventCapture{}
+	world.Events.Subscribe(COLLISION_EXIT, exits.capture)
+	loadLevel := func() (*actor.RigidBody, BodyID) {
+		world.AddBody(createPlane(mgl64.Vec3{0, 1, 0}, 0))
+		crate := createBox(mgl64.Vec3{0, 0.5, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+		id := world.AddBody(crate)
+		world.AddParticle(actor.NewParticle(mgl64.Vec3{0, 5, 0}, 1))
+		for range 10 {
+			world.Step(1.0 / 60.0)
+		}
+
+		return crate, id
+	}
+
+	_, id := loadLevel()
+	capacity := cap(world.Bodies)
+	world.Clear()
+	if len(world.Bodies) != 0 || len(world.Particles()) != 0 || cap(world.Bodies) != capacity {
+		t.Fatalf("%d bodies and %d particles left, capacity %d, want an empty world keeping its capacity %d",
+			len(world.Bodies), len(world.Particles()), cap(world.Bodies), capacity)
+	}
+	if _, ok := world.GetBody(id); ok {
+		t.Error("the handle of a cleared body should be stale")
+	}
+	if found := world.QueryAABB(actor.AABB{Min: mgl64.Vec3{-1, -1, -1}, Max: mgl64.Vec3{1, 1, 1}}, nil); len(found) != 0 {
+		t.Errorf("QueryAABB = %v, want the grid emptied", found)
+	}
+	if len(world.manifolds) != 0 || len(world.Events.previousActivePairs) != 0 {
+		t.Error("the caches of the pairs and the events should be forgotten")
+	}
+
+	// The level is reloaded in the same world, without exit events for the previous one
+	crate, _ := loadLevel()
+	if len(exits.events) != 0 {
+		t.Errorf("%d exit events, want none for the cleared bodies", len(exits.events))
+	}
+	if math.Abs(crate.Transform.Position.Y()-0.5) > 0.01 {
+		t.Errorf("crate at %v, want resting on the reloaded plane", crate.Transform.Position)
+	}
+}
+
+func TestWorld_TimeScale(t *testing.T) {
+	newWorld := func(substeps int, scale float64) (*World, *actor.RigidBody) {
+		world := NewWorld(WithSubsteps(substeps), WithTimeScale(scale))
+		world.AddBody(createPlane(mgl64.Vec3{0, 1, 0}, 0))
+		ball := createSphere(mgl64.Vec3{0, 1, 0}, 0.25, actor.BodyTypeDynamic)
+		ball.Velocity = mgl64.Vec3{2, 0, 0}
+		ball.Material.Restitution = 0.5
+		ball.Material.LinearDamping, ball.Material.AngularDamping = 0.3, 0.3
+		world.AddBody(ball)
+
+		return world, ball
+	}
+
+	// In slow motion, two Steps of 4 substeps simulate the duration of one Step at the normal speed,
+	// with the substeps of a world running 8 of them
+	slow, slowBall := newWorld(4, 0.5)
+	normal, normalBall := newWorld(8, 0)
+	for range 60 {
+		slow.Step(1.0 / 60.0)
+		slow.Step(1.0 / 60.0)
+		normal.Step(1.0 / 60.0)
+	}
+	if !vec3ApproxEqual(slowBall.Transform.Position, normalBall.Transform.Position, 1e-9) ||
+		!vec3ApproxEqual(slowBall.Velocity, normalBall.Velocity, 1e-9) {
+		t.Errorf("slow motion ball at %v moving at %v, want %v and %v", slowBall.Transform.Position, slowBall.Velocity,
+			normalBall.Transform.Position, normalBall.Velocity)
+	}
+
+	// In fast forward, a Step runs the substeps of two Steps at the normal speed
+	fast, fastBall := newWorld(4, 2)
+	normal, normalBall = newWorld(4, 1)
+	for range 30 {
+		fast.Step(1.0 / 60.0)
+		normal.Step(1.0 / 60.0)
+		normal.Step(1.0 / 60.0)
+	}
+	if !vec3ApproxEqual(fastBall.Transform.Position, normalBall.Transform.Position, 1e-9) ||
+		!vec3ApproxEqual(fastBall.Velocity, normalBall.Velocity, 1e-9) {
+		t.Errorf("fast forward ball at %v moving at %v, want %v and %v", fastBall.Transform.Position, fastBall.Velocity,
+			normalBall.Transform.Position, normalBall.Velocity)
+	}
+}
+
+func TestWorld_MaxVelocity(t *testing.T) {
+	world := NewWorld(WithGravity(mgl64.Vec3{}), WithMaxVelocities(50, 10))
+	bullet := createSphere(mgl64.Vec3{}, 0.1, actor.BodyTypeDynamic)
+	bullet.Velocity = mgl64.Vec3{1000, 0, 0}
+	bullet.AngularVelocity = mgl64.Vec3{0, 100, 0}
+	world.AddBody(bullet)
+	slow := createSphere(mgl64.Vec3{0, 5, 0}, 0.1, actor.BodyTypeDynamic)
+	slow.Velocity = mgl64.Vec3{0, 0, 1000}
+	slow.MaxVelocity = 5
+	world.AddBody(slow)
+
+	// The velocities are clamped once integrated: the bodies never move faster than their limit
+	world.Step(1.0 / 60.0)
+	if !vec3ApproxEqual(bullet.Velocity, mgl64.Vec3{50, 0, 0}, 1e-9) || !vec3ApproxEqual(bullet.AngularVelocity, mgl64.Vec3{0, 10, 0}, 0.01) {
+		t.Errorf("bullet velocities %v and %v, want the limits of the world", bullet.Velocity, bullet.AngularVelocity)
+	}
+	if bullet.Transform.Position.X() > 50.0/60.0+1e-9 {
+		t.Errorf("bullet at %v, want it moved at 50 m/s at most", bullet.Transform.Position)
+	}
+	if !vec3ApproxEqual(slow.Velocity, mgl64.Vec3{0, 0, 5}, 1e-9) {
+		t.Errorf("velocity %v, want the limit of the body", slow.Velocity)
+	}
+
+	// The gravity integrated during the substep does not exceed the limit either
+	falling := NewWorld(WithGravity(mgl64.Vec3{0, -1000, 0}), WithSubsteps(1), WithMaxVelocities(5, 0))
+	body := createSphere(mgl64.Vec3{0, 10, 0}, 0.5, actor.BodyTypeDynamic)
+	falling.AddBody(body)
+	falling.Step(1.0 / 60.0)
+	if speed := body.PresolveVelocity.Len(); speed > 5+1e-9 {
+		t.Errorf("velocity %v m/s after the integration, want 5 at most", speed)
+	}
+	if fall := 10 - body.Transform.Position.Y(); fall > 5.0/60.0+1e-9 {
+		t.Errorf("fell by %v, want moved at 5 m/s at most", fall)
+	}
+}
+
+func TestWorld_ApplyForce(t *testing.T) {
+	world := &World{Substeps: 4, Events: NewEvents()}
+	body := createSphere(mgl64.Vec3{}, 0.5, actor.BodyTypeDynamic)
+	world.AddBody(body)
+
+	// The force acts over all the substeps of the next Step: 0.1s of m N accelerate at 1 m/s²
+	body.ApplyForce(mgl64.Vec3{body.Material.GetMass(), 0, 0})
+	world.Step(0.1)
+	if !vec3ApproxEqual(body.Velocity, mgl64.Vec3{0.1, 0, 0}, 1e-9) {
+		t.Errorf("velocity = %v, want {0.1 0 0}", body.Velocity)
+	}
+
+	// Then it is cleared
+	world.Step(0.1)
+	if !vec3ApproxEqual(body.Velocity, mgl64.Vec3{0.1, 0, 0}, 1e-9) {
+		t.Errorf("velocity = %v, want unchanged by the next Step", body.Velocity)
+	}
+}
+
+func TestWorld_DisabledBody(t *testing.T) {
+	broadphases := map[string]func() *World{
+		"spatial grid": func() *World { return NewWorld(WithBroadPhase(NewSpatialGrid(1.0, 1024), -1)) },
+		"dynamic tree": func() *World { return NewWorld(WithDynamicTree(0.1), WithBroadPhase(nil, -1)) },
+		"brute force":  func() *World { return NewWorld(WithBroadPhase(nil, 1000)) },
+	}
+	for name, newWorld := range broadphases {
+		t.Run(name, func(t *testing.T) {
+			world := newWorld()
+			world.AddBody(createPlane(mgl64.Vec3{0, 1, 0}, 0))
+			crate := createBox(mgl64.Vec3{0, 0.5, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+			world.AddBody(crate)
+			for range 30 {
+				world.Step(1.0 / 60.0)
+			}
+
+			// Disabled, the crate resting on the plane leaves its pair without event, and keeps its state
+			exits := &eventCapture{}
+			world.Events.Subscribe(COLLISION_EXIT, exits.capture)
+			crate.SetEnabled(false)
+			crate.Velocity = mgl64.Vec3{1, 0, 0}
+			position := crate.Transform.Position
+			ball := createSphere(mgl64.Vec3{0, 2, 0}, 0.25, actor.BodyTypeDynamic)
+			world.AddBody(ball)
+			for range 60 {
+				world.Step(1.0 / 60.0)
+			}
+			if len(exits.events) != 0 {
+				t.Errorf("%d exit events, want none for the disabled crate", len(exits.events))
+			}
+			if crate.Transform.Position != position || crate.Velocity != (mgl64.Vec3{1, 0, 0}) {
+				t.Errorf("disabled crate at %v moving at %v, want its state kept", crate.Transform.Position, crate.Velocity)
+			}
+
+			// The ball falls through it onto the plane, the queries ignore it
+			if math.Abs(ball.Transform.Position.Y()-0.25) > 0.01 {
+				t.Errorf("ball at %v, want through the disabled crate onto the plane", ball.Transform.Position)
+			}
+			region := actor.AABB{Min: mgl64.Vec3{-0.4, 0.6, -0.4}, Max: mgl64.Vec3{0.4, 0.9, 0.4}}
+			if found := world.QueryAABB(region, nil); len(found) != 0 {
+				t.Errorf("QueryAABB = %v, want the disabled crate ignored", found)
+			}
+			if hit, ok := world.Raycast(mgl64.Vec3{0.3, 2, 0.3}, mgl64.Vec3{0, -1, 0}, 5, nil); !ok || hit.Body == crate {
+				t.Errorf("Raycast = %+v, want the plane under the disabled crate", hit)
+			}
+
+			// Enabled back, it resumes from its state. The ball resting inside it is removed first,
+			// a body enabled over another one is not separated from it
+			world.RemoveBody(ball)
+			crate.SetEnabled(true)
+			world.Step(1.0 / 60.0)
+			if crate.Transform.Position.X() <= position.X() {
+				t.Errorf("enabled crate at %v, want moving again", crate.Transform.Position)
+			}
+		})
+	}
+}
+
+func TestWorld_AxisLocks(t *testing.T) {
+	world := NewWorld()
+	world.AddBody(createPlane(mgl64.Vec3{0.3, 1, 0.3}.Normalize(), 0))
+	crate := createBox(mgl64.Vec3{0, 2, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+	crate.AngularVelocity = mgl64.Vec3{1, 1, 1}
+	crate.SetAxisLocks(actor.LockZ, actor.LockX|actor.LockY)
+	world.AddBody(crate)
+
+	// Falling onto a slope tilted towards X and Z, the crate slides and tumbles in the XY plane only
+	for range 120 {
+		world.Step(1.0 / 60.0)
+	}
+	if crate.Transform.Position.Z() != 0 || crate.Velocity.Z() != 0 {
+		t.Errorf("crate at %v moving at %v, want it kept in the XY plane", crate.Transform.Position, crate.Velocity)
+	}
+	if crate.Transform.Position.X() <= 0.1 {
+		t.Errorf("crate at %v, want sliding down the slope along X", crate.Transform.Position)
+	}
+	rotation := crate.Transform.Rotation
+	if math.Abs(rotation.V.X()) > 1e-9 || math.Abs(rotation.V.Y()) > 1e-9 || crate.AngularVelocity.X() != 0 || crate.AngularVelocity.Y() != 0 {
+		t.Errorf("rotation %v, angular velocity %v, want a rotation about Z only", rotation, crate.AngularVelocity)
+	}
+}
+
+func TestWorld_CenterOfMass(t *testing.T) {
+	// A tilted post falls over, with its center of mass lowered near its base it rights itself
+	for _, tt := range []struct {
+		name    string
+		offset  mgl64.Vec3
+		upright bool
+	}{
+		{"shape center", mgl64.Vec3{}, false},
+		{"lowered", mgl64.Vec3{0, -0.9, 0}, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			world := NewWorld()
+			world.AddBody(createPlane(mgl64.Vec3{0, 1, 0}, 0))
+			post := createBox(mgl64.Vec3{0, 1.2, 0}, mgl64.Vec3{0.25, 1, 0.25}, actor.BodyTypeDynamic)
+			post.Transform.Rotation = mgl64.QuatRotate(0.4, mgl64.Vec3{0, 0, 1})
+			post.Transform.InverseRotation = post.Transform.Rotation.Inverse()
+			post.Material.StaticFriction, post.Material.DynamicFriction = 0.8, 0.6
+			post.Material.AngularDamping = 0.5
+			if err := post.SetCenterOfMass(tt.offset); err != nil {
+				t.Fatal(err)
+			}
+			world.AddBody(post)
+
+			for range 300 {
+				world.Step(1.0 / 60.0)
+			}
+			up := post.Transform.Rotation.Rotate(mgl64.Vec3{0, 1, 0})
+			if upright := math.Abs(up.Y()) > 0.95; upright != tt.upright {
+				t.Errorf("post axis %v, upright = %v, want %v", up, upright, tt.upright)
+			}
+		})
+	}
+}
+
+func TestWorld_VelocityFieldConstraint(t *testing.T) {
+	world := &World{Substeps: 4, Events: NewEvents()}
+	body := createSphere(mgl64.Vec3{}, 0.5, actor.BodyTypeDynamic)
+	world.AddBody(body)
+
+	c := constraint.NewVelocityFieldConstraint(body, func(position mgl64.Vec3) mgl64.Vec3 {
+		return mgl64.Vec3{0, 0, 3}
+	}, 0)
+	world.AddConstraint(c)
+	world.Step(1.0 / 60.0)
+
+	if !vec3ApproxEqual(body.Velocity, mgl64.Vec3{0, 0, 3}, 1e-9) {
+		t.Errorf("velocity = %v, want {0 0 3}", body.Velocity)
+	}
+
+	world.RemoveConstraint(c)
+	if len(world.constraints) != 0 {
+		t.Errorf("expected no constraints, got %d", len(world.constraints))
+	}
+}
+
+func TestWorld_PreSolveHook(t *testing.T) {
+	world := &World{Substeps: 4, Events: NewEvents()}
+	body := createSphere(mgl64.Vec3{}, 0.5, actor.BodyTypeDynamic)
+	world.AddBody(body)
+
+	calls := 0
+	world.PreSolve = func(h float64, contacts []*constraint.ContactConstraint) ([]*constraint.ContactConstraint, []constraint.Constraint) {
+		calls++
+		if h != 1.0/60.0/4 {
+			t.Errorf("h = %v, want the substep duration", h)
+		}
+
+		return contacts, []constraint.Constraint{
+			constraint.NewVelocityFieldConstraint(body, func(position mgl64.Vec3) mgl64.Vec3 {
+				return mgl64.Vec3{1, 0, 0}
+			}, 0),
+		}
+	}
+	world.Step(1.0 / 60.0)
+
+	if calls != 4 {
+		t.Errorf("PreSolve called %d times, want once per substep", calls)
+	}
+	if !vec3ApproxEqual(body.Velocity, mgl64.Vec3{1, 0, 0}, 1e-9) {
+		t.Errorf("velocity = %v, want the hook constraint applied", body.Velocity)
+	}
+	if len(world.constraints) != 0 {
+		t.Error("hook constraints should not be kept by the world")
+	}
+}
+
+func TestWorld_PreSolveHook_FilterContacts(t *testing.T) {
+	world := &World{Substeps: 1, Gravity: mgl64.Vec3{0, -9.81, 0}, Events: NewEvents()}
+	world.AddBody(createPlane(mgl64.Vec3{0, 1, 0}, 0))
+	body := createSphere(mgl64.Vec3{0, 0.4, 0}, 0.5, actor.BodyTypeDynamic)
+	world.AddBody(body)
+
+	// Dropping all the contacts lets the sphere sink into the plane
+	world.PreSolve = func(h float64, contacts []*constraint.ContactConstraint) ([]*constraint.ContactConstraint, []constraint.Constraint) {
+		return nil, nil
+	}
+	world.Step(1.0 / 60.0)
+
+	if body.Transform.Position.Y() >= 0.4 {
+		t.Errorf("position = %v, want the contact ignored", body.Transform.Position)
+	}
+}
+
+func TestWorld_Enqueue(t *testing.T) {
+	world := &World{Substeps: 1, Events: NewEvents()}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			world.Enqueue(func(w *World) {
+				w.AddBody(createSphere(mgl64.Vec3{float64(len(w.Bodies)) * 3, 0, 0}, 0.5, actor.BodyTypeDynamic))
+			})
+		}()
+	}
+	wg.Wait()
+
+	if len(world.Bodies) != 0 {
+		t.Fatal("commands should wait for the next Step")
+	}
+
+	world.Step(1.0 / 60.0)
+	if len(world.Bodies) != 8 {
+		t.Errorf("expected 8 bodies after the Step, got %d", len(world.Bodies))
+	}
+}
+
+func TestWorld_EnqueueBodies(t *testing.T) {
+	world := &World{Substeps: 2, Events: NewEvents()}
+	target := createSphere(mgl64.Vec3{}, 0.5, actor.BodyTypeDynamic)
+	world.AddBody(target)
+	removed := createSphere(mgl64.Vec3{10, 0, 0}, 0.5, actor.BodyTypeDynamic)
+	removedID := world.AddBody(removed)
+
+	// Gameplay goroutines mutate the world while it is stepping
+	var ids sync.Map
+	var wg sync.WaitGroup
+	stepping := make(chan struct{})
+	go func() {
+		defer close(stepping)
+		for range 20 {
+			world.Step(1.0 / 60.0)
+		}
+	}()
+	for i := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			spawned := createSphere(mgl64.Vec3{float64(i+1) * 3, 5, 0}, 0.5, actor.BodyTypeDynamic)
+			world.EnqueueAddBody(spawned, func(id BodyID) {
+				ids.Store(spawned, id)
+			})
+			world.EnqueueImpulse(target, mgl64.Vec3{target.Material.GetMass(), 0, 0})
+		}()
+	}
+	world.EnqueueRemoveBodyByID(removedID)
+	wg.Wait()
+	<-stepping
+	world.Step(1.0 / 60.0)
+
+	if len(world.Bodies) != 5 {
+		t.Fatalf("%d bodies, want the 4 spawned bodies and the target", len(world.Bodies))
+	}
+	for _, body := range world.Bodies[1:] {
+		if id, ok := ids.Load(body); !ok || !slices.Contains(world.Bodies, body) {
+			t.Errorf("spawned body without handle: %v", id)
+		} else if found, _ := world.GetBody(id.(BodyID)); found != body {
+			t.Errorf("GetBody(%v) = %v, want the spawned body", id, found)
+		}
+	}
+	if !vec3ApproxEqual(target.Velocity, mgl64.Vec3{4, 0, 0}, 1e-9) {
+		t.Errorf("target velocity = %v, want the 4 impulses applied", target.Velocity)
+	}
+}
+
+func TestWorld_Enqueue_FromCommand(t *testing.T) {
+	world := &World{Substeps: 1, Events: NewEvents()}
+
+	order := []int{}
+	world.Enqueue(func(w *World) {
+		order = append(order, 1)
+		w.Enqueue(func(w *World) {
+			order = append(order, 3)
+		})
+	})
+	world.Enqueue(func(w *World) {
+		order = append(order, 2)
+	})
+
+	world.Step(1.0 / 60.0)
+	if len(order) != 2 || order[0] != 1 || order[1] != 2 {
+		t.Fatalf("order = %v, want [1 2]", order)
+	}
+
+	world.Step(1.0 / 60.0)
+	if len(order) != 3 || order[2] != 3 {
+		t.Errorf("order = %v, want the nested command at the next Step", order)
+	}
+}
+
+func TestWorld_ParticleAttachment(t *testing.T) {
+	world := &World{Substeps: 4, Gravity: mgl64.Vec3{0, -9.81, 0}, Events: NewEvents()}
+	pole := createBox(mgl64.Vec3{0, 2, 0}, mgl64.Vec3{0.1, 1, 0.1}, actor.BodyTypeStatic)
+	world.AddBody(pole)
+
+	particle := actor.NewParticle(mgl64.Vec3{0, 3, 0}, 0.1)
+	world.AddParticle(particle)
+	world.AddConstraint(constraint.NewParticleAttachment(particle, pole, 0))
+
+	free := actor.NewParticle(mgl64.Vec3{1, 3, 0}, 0.1)
+	world.AddParticle(free)
+
+	for i := 0; i < 60; i++ {
+		world.Step(1.0 / 60.0)
+	}
+
+	if !vec3ApproxEqual(particle.Position, mgl64.Vec3{0, 3, 0}, 1e-9) {
+		t.Errorf("attached particle = %v, want held at {0 3 0}", particle.Position)
+	}
+	if free.Position.Y() > 3-4.5 {
+		t.Errorf("free particle = %v, want fallen under gravity", free.Position)
+	}
+
+	world.RemoveParticle(free)
+	if len(world.Particles()) != 1 {
+		t.Errorf("expected 1 particle, got %d", len(world.Particles()))
+	}
+}
+
+// TestWorld_IterationsStiffenStack drops a stack with a single substep: more solver passes keep it higher and calmer
+func TestWorld_IterationsStiffenStack(t *testing.T) {
+	settle := func(iterations int) (float64, float64) {
+		world := NewWorld(WithSubsteps(1), WithIterations(iterations, iterations))
+		world.Tolerances.SleepTime = math.Inf(1)
+		world.AddBody(createBox(mgl64.Vec3{0, -1, 0}, mgl64.Vec3{10, 1, 10}, actor.BodyTypeStatic))
+
+		var boxes []*actor.RigidBody
+		for i := range 10 {
+			box := createBox(mgl64.Vec3{0, 0.5 + float64(i), 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+			world.AddBody(box)
+			boxes = append(boxes, box)
+		}
+		for range 120 {
+			world.Step(1.0 / 60.0)
+		}
+
+		speed := 0.0
+		for _, box := range boxes {
+			speed = max(speed, box.Velocity.Len())
+		}
+
+		return 9.5 - boxes[9].Transform.Position.Y(), speed
+	}
+
+	sinkOne, speedOne := settle(1)
+	sinkEight, speedEight := settle(8)
+	if sinkEight > sinkOne/2 {
+		t.Errorf("top box sank by %v with 8 passes, want less than half of %v with 1 pass", sinkEight, sinkOne)
+	}
+	if speedEight > 0.2 || speedEight > speedOne {
+		t.Errorf("max speed %v with 8 passes, want resting (%v with 1 pass)", speedEight, speedOne)
+	}
+}
+
+// TestWorld_SpinFriction checks a ball spinning on the ground slows down by the torque
+// of its spin friction, SpinFriction*m*g, where it only loses its angular damping without it
+func TestWorld_SpinFriction(t *testing.T) {
+	spin := func(spinFriction float64) float64 {
+		world := NewWorld()
+		ground := createBox(mgl64.Vec3{0, -1, 0}, mgl64.Vec3{10, 1, 10}, actor.BodyTypeStatic)
+		ball := createSphere(mgl64.Vec3{0, 0.5, 0}, 0.5, actor.BodyTypeDynamic)
+		for _, body := range []*actor.RigidBody{ground, ball} {
+			body.Material.SpinFriction = spinFriction
+			body.Material.StaticFriction, body.Material.DynamicFriction = 0.5, 0.5
+		}
+		world.AddBody(ground)
+		world.AddBody(ball)
+
+		for range 10 {
+			world.Step(1.0 / 60.0)
+		}
+		ball.AngularVelocity = mgl64.Vec3{0, 10, 0}
+		for range 120 {
+			world.Step(1.0 / 60.0)
+		}
+
+		return ball.AngularVelocity.Y()
+	}
+
+	// Solid sphere: I = 0.4*m*r², the deceleration is SpinFriction*g/(0.4*r²)
+	free := spin(0)
+	want := free * (10 - 2*0.01*9.81/(0.4*0.25)) / 10
+	if slowed := spin(0.01); math.Abs(slowed-want) > 0.02*want {
+		t.Errorf("spin %v rad/s after 2s, want %v (%v without spin friction)", slowed, want, free)
+	}
+}
+
+// TestWorld_SplitImpulse checks a box spawned deep inside the ground is pushed out without being launched
+func TestWorld_SplitImpulse(t *testing.T) {
+	spawn := func(options ...WorldOption) (float64, float64) {
+		world := NewWorld(options...)
+		world.AddBody(createBox(mgl64.Vec3{0, -1, 0}, mgl64.Vec3{10, 1, 10}, actor.BodyTypeStatic))
+		box := createBox(mgl64.Vec3{0, 0.2, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+		world.AddBody(box)
+
+		speed := 0.0
+		for range 60 {
+			world.Step(1.0 / 60.0)
+			speed = max(speed, box.Velocity.Y())
+		}
+
+		return speed, box.Transform.Position.Y()
+	}
+
+	if speed, _ := spawn(); speed < 5 {
+		t.Fatalf("box spawned 0.3m deep left at %v m/s without split impulse, the scene does not launch it", speed)
+	}
+	speed, y := spawn(WithSplitImpulse(0, 0))
+	if speed > 0.1 {
+		t.Errorf("box spawned 0.3m deep left at %v m/s, want pushed out without velocity", speed)
+	}
+	if math.Abs(y-0.5) > 0.01 {
+		t.Errorf("box at y = %v after 1s, want resting on the ground at 0.5", y)
+	}
+}
+
+// TestWorld_SolverXPBD checks the XPBD backend keeps the compliance of the contacts whatever the passes:
+// a box resting on 4 points of a soft contact sinks by compliance*m*g/4, where the passes stiffen the PBD one
+func TestWorld_SolverXPBD(t *testing.T) {
+	settle := func(solver Solver, iterations int) float64 {
+		world := NewWorld(WithIterations(iterations, 1), WithSolver(solver, 1e-3))
+		world.Tolerances.SleepTime = math.Inf(1)
+		world.AddBody(createBox(mgl64.Vec3{0, -1, 0}, mgl64.Vec3{10, 1, 10}, actor.BodyTypeStatic))
+		box := createBox(mgl64.Vec3{0, 0.5, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+		world.AddBody(box)
+		for range 120 {
+			world.Step(1.0 / 60.0)
+		}
+
+		return 0.5 - box.Transform.Position.Y()
+	}
+
+	want := 1e-3 * 9.81 * createBox(mgl64.Vec3{}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic).Material.GetMass() / 4
+	for _, iterations := range []int{4, 16} {
+		if sink := settle(SOLVER_XPBD, iterations); math.Abs(sink-want) > 0.05*want {
+			t.Errorf("XPBD box sank by %v with %d passes, want %v", sink, iterations, want)
+		}
+	}
+	if sink := settle(SOLVER_PBD, 16); sink > want/2 {
+		t.Errorf("PBD box sank by %v with 16 passes, want stiffened under %v", sink, want/2)
+	}
+}
+
+// TestWorld_HingeJoint swings a pendulum hinged about Z: it stays on its circle in the XY plane,
+// without gaining amplitude, and the lower limit stops it. The bob is light on a long lever,
+// its rotation about its center needs a few position passes to follow the swing.
+func TestWorld_HingeJoint(t *testing.T) {
+	swing := func(limited bool) (minAngle, maxAngle, maxGap, maxZ float64) {
+		world := NewWorld(WithSubsteps(8), WithIterations(4, 1))
+		frame := createSphere(mgl64.Vec3{0, 0, 0}, 0.05, actor.BodyTypeStatic)
+		bob := createSphere(mgl64.Vec3{0, -1, 0}, 0.1, actor.BodyTypeDynamic)
+		world.AddBody(frame)
+		world.AddBody(bob)
+
+		hinge := constraint.NewHingeJoint(frame, bob, mgl64.Vec3{0, 0, 0}, mgl64.Vec3{0, 0, 1})
+		if limited {
+			hinge.SetLimits(-0.3, 0.6)
+		}
+		world.AddConstraint(hinge)
+
+		// Released at 0.5 rad, the angle 0 hanging down
+		bob.Transform.Rotation = mgl64.QuatRotate(0.5, mgl64.Vec3{0, 0, 1})
+		bob.Transform.Position = bob.Transform.Rotation.Rotate(mgl64.Vec3{0, -1, 0})
+		for range 240 {
+			world.Step(1.0 / 60.0)
+
+			minAngle = min(minAngle, hinge.Angle())
+			maxAngle = max(maxAngle, hinge.Angle())
+			maxGap = max(maxGap, math.Abs(bob.Transform.Position.Len()-1))
+			maxZ = max(maxZ, math.Abs(bob.Transform.Position.Z()))
+		}
+
+		return minAngle, maxAngle, maxGap, maxZ
+	}
+
+	minAngle, maxAngle, maxGap, maxZ := swing(false)
+	if minAngle < -0.5-1e-2 || minAngle > -0.45 || maxAngle > 0.5+1e-2 {
+		t.Errorf("angle in [%v, %v], want swinging at the amplitude 0.5", minAngle, maxAngle)
+	}
+	if maxGap > 2e-3 || maxZ > 1e-9 {
+		t.Errorf("bob %v off its circle and %v off its plane", maxGap, maxZ)
+	}
+
+	minAngle, maxAngle, maxGap, _ = swing(true)
+	if minAngle < -0.3-2e-2 || maxAngle > 0.5 {
+		t.Errorf("angle in [%v, %v], want stopped by the lower limit -0.3 without gaining amplitude", minAngle, maxAngle)
+	}
+	if maxGap > 1e-2 {
+		t.Errorf("bob %v off its circle, want held by the pivot at the limit", maxGap)
+	}
+}
+
+// TestWorld_HingeLimitTorque holds a bob horizontally on a hinge, at its lower limit: the limit holds the torque
+// of its weight, and BreakTorque tears it off
+func TestWorld_HingeLimitTorque(t *testing.T) {
+	hold := func(breakTorque float64) (*actor.RigidBody, *constraint.HingeJoint) {
+		world := NewWorld(WithSubsteps(8), WithIterations(4, 1))
+		frame := createSphere(mgl64.Vec3{0, 0, 0}, 0.05, actor.BodyTypeStatic)
+		bob := createSphere(mgl64.Vec3{1, 0, 0}, 0.1, actor.BodyTypeDynamic)
+		world.AddBody(frame)
+		world.AddBody(bob)
+
+		hinge := constraint.NewHingeJoint(frame, bob, mgl64.Vec3{0, 0, 0}, mgl64.Vec3{0, 0, 1})
+		hinge.SetLimits(0, 1)
+		hinge.BreakTorque = breakTorque
+		world.AddConstraint(hinge)
+		for range 60 {
+			world.Step(1.0 / 60.0)
+		}
+
+		return bob, hinge
+	}
+
+	bob, hinge := hold(0)
+	torque := bob.Material.GetMass() * 9.81
+	if math.Abs(hinge.Torque-torque) > 0.02*torque || math.Abs(hinge.Angle()) > 1e-2 {
+		t.Errorf("Torque = %v at the angle %v, want the weight at 1 m %v at the limit 0", hinge.Torque, hinge.Angle(), torque)
+	}
+
+	bob, hinge = hold(torque / 2)
+	if !hinge.Broken() || bob.Transform.Position.Y() > -1 {
+		t.Errorf("hinge broken %v, bob at %v, want torn off and fallen", hinge.Broken(), bob.Transform.Position)
+	}
+}
+
+// TestWorld_HingeMotor spins a wheel hinged about Y at its center: it reaches the motor speed and stays in place
+func TestWorld_HingeMotor(t *testing.T) {
+	world := NewWorld(WithSubsteps(4))
+	frame := createSphere(mgl64.Vec3{0, 0, 0}, 0.05, actor.BodyTypeStatic)
+	wheel := createSphere(mgl64.Vec3{0, 1, 0}, 0.5, actor.BodyTypeDynamic)
+	world.AddBody(frame)
+	world.AddBody(wheel)
+
+	hinge := constraint.NewHingeJoint(frame, wheel, mgl64.Vec3{0, 1, 0}, mgl64.Vec3{0, 1, 0})
+	hinge.SetMotor(3, 1)
+	world.AddConstraint(hinge)
+
+	for range 60 {
+		world.Step(1.0 / 60.0)
+	}
+
+	if !wheel.AngularVelocity.ApproxEqualThreshold(mgl64.Vec3{0, 3, 0}, 0.05) {
+		t.Errorf("angular velocity = %v, want the motor speed {0 3 0}", wheel.AngularVelocity)
+	}
+	if !wheel.Transform.Position.ApproxEqualThreshold(mgl64.Vec3{0, 1, 0}, 1e-3) {
+		t.Errorf("wheel at %v, want held at {0 1 0}", wheel.Transform.Position)
+	}
+}
+
+// TestWorld_FixedJointTorque welds a crate by its side to a static wall: the weld holds the torque of its weight,
+// and BreakTorque breaks it under this torque alone
+func TestWorld_FixedJointTorque(t *testing.T) {
+	cantilever := func(breakTorque float64) (*actor.RigidBody, *constraint.FixedJoint) {
+		world := NewWorld(WithSubsteps(4))
+		wall := createBox(mgl64.Vec3{-1, 0, 0}, mgl64.Vec3{0.5, 1, 1}, actor.BodyTypeStatic)
+		crate := createBox(mgl64.Vec3{0.5, 0, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+		world.AddBody(wall)
+		world.AddBody(crate)
+
+		weld := constraint.NewFixedJoint(wall, crate, mgl64.Vec3{0, 0, 0})
+		weld.BreakTorque = breakTorque
+		world.AddConstraint(weld)
+		for range 60 {
+			world.Step(1.0 / 60.0)
+		}
+
+		return crate, weld
+	}
+
+	crate, weld := cantilever(0)
+	torque := crate.Material.GetMass() * 9.81 * 0.5
+	if math.Abs(weld.Torque-torque) > 0.01*torque || weld.Broken() {
+		t.Errorf("Torque = %v, want the weight at 0.5 m from the wall %v", weld.Torque, torque)
+	}
+	if crate.Transform.Position.Sub(mgl64.Vec3{0.5, 0, 0}).Len() > 1e-3 {
+		t.Errorf("crate at %v, want held at {0.5 0 0}", crate.Transform.Position)
+	}
+
+	crate, weld = cantilever(torque / 2)
+	if !weld.Broken() || crate.Transform.Position.Y() > -1 {
+		t.Errorf("weld broken %v, crate at %v, want broken by the torque and fallen", weld.Broken(), crate.Transform.Position)
+	}
+}
+
+// TestWorld_ConeTwistJoint releases an arm held horizontally from a shoulder, spinning about its length:
+// it falls against the cone, its twist stopped by the limits, and stays on the shoulder
+func TestWorld_ConeTwistJoint(t *testing.T) {
+	world := NewWorld(WithSubsteps(8), WithIterations(4, 1))
+	shoulder := createSphere(mgl64.Vec3{-0.2, 0, 0}, 0.1, actor.BodyTypeStatic)
+	arm := createBox(mgl64.Vec3{0.5, 0, 0}, mgl64.Vec3{0.5, 0.1, 0.1}, actor.BodyTypeDynamic)
+	world.AddBody(shoulder)
+	world.AddBody(arm)
+
+	joint := constraint.NewConeTwistJoint(shoulder, arm, mgl64.Vec3{0, 0, 0}, mgl64.Vec3{1, 0, 0})
+	joint.SetSwingLimit(0.5)
+	joint.SetTwistLimits(-0.2, 0.2)
+	world.AddConstraint(joint)
+
+	arm.AngularVelocity = mgl64.Vec3{5, 0, 0}
+	var maxSwing, maxTwist, maxGap float64
+	for range 120 {
+		world.Step(1.0 / 60.0)
+
+		maxSwing = max(maxSwing, joint.SwingAngle())
+		maxTwist = max(maxTwist, math.Abs(joint.TwistAngle()))
+		pivot := arm.Transform.Position.Add(arm.Transform.Rotation.Rotate(joint.LocalAnchorB))
+		maxGap = max(maxGap, pivot.Len())
+	}
+
+	if maxSwing > 0.5+1e-2 || math.Abs(joint.SwingAngle()-0.5) > 1e-2 {
+		t.Errorf("swing up to %v, resting at %v, want stopped by the cone 0.5", maxSwing, joint.SwingAngle())
+	}
+	if maxTwist > 0.2+1e-2 {
+		t.Errorf("twist up to %v, want stopped by the limits 0.2", maxTwist)
+	}
+	if maxGap > 1e-2 {
+		t.Errorf("arm %v off the shoulder", maxGap)
+	}
+}
+
+// TestWorld_JointDisableCollision joins two ragdoll segments overlapping at the pivot: with DisableCollision,
+// the pair is skipped and the segments stay at rest; without it, or once the joint is removed, they collide
+func TestWorld_JointDisableCollision(t *testing.T) {
+	world := NewWorld(WithSubsteps(4))
+	world.Gravity = mgl64.Vec3{}
+	capture := &eventCapture{}
+	world.Events.Subscribe(COLLISION_ENTER, capture.capture)
+
+	upperArm := createBox(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{0.5, 0.1, 0.1}, actor.BodyTypeDynamic)
+	forearm := createBox(mgl64.Vec3{0.9, 0, 0}, mgl64.Vec3{0.5, 0.1, 0.1}, actor.BodyTypeDynamic)
+	world.AddBody(upperArm)
+	world.AddBody(forearm)
+
+	elbow := constraint.NewConeTwistJoint(upperArm, forearm, mgl64.Vec3{0.45, 0, 0}, mgl64.Vec3{1, 0, 0})
+	elbow.DisableCollision = true
+	world.AddConstraint(elbow)
+	for range 10 {
+		world.Step(1.0 / 60.0)
+	}
+	if capture.count() != 0 || forearm.Transform.Position.Sub(mgl64.Vec3{0.9, 0, 0}).Len() > 1e-9 {
+		t.Fatalf("%d COLLISION_ENTER events, forearm at %v, want the pair skipped", capture.count(), forearm.Transform.Position)
+	}
+
+	// At rest, the segments fell asleep
+	elbow.DisableCollision = false
+	upperArm.WakeUp()
+	forearm.WakeUp()
+	world.Step(1.0 / 60.0)
+	if capture.count() != 1 {
+		t.Errorf("%d COLLISION_ENTER events, want the segments colliding without DisableCollision", capture.count())
+	}
+
+	elbow.DisableCollision = true
+	world.Step(1.0 / 60.0)
+	world.RemoveConstraint(elbow)
+	capture.events = nil
+	upperArm.WakeUp()
+	forearm.WakeUp()
+	world.Step(1.0 / 60.0)
+	if capture.count() != 1 {
+		t.Errorf("%d COLLISION_ENTER events, want the segments colliding once the joint is removed", capture.count())
+	}
+}
+
+// mousePick is a custom constraint dragging a body toward a cursor, read at each substep by PreSolve
+type mousePick struct {
+	body   *actor.RigidBody
+	cursor func() mgl64.Vec3
+	target mgl64.Vec3
+	calls  []string
+}
+
+func (c *mousePick) PreSolve(dt float64) {
+	c.target = c.cursor()
+	c.calls = append(c.calls, "pre")
+}
+
+func (c *mousePick) SolvePosition(dt float64) {
+	c.body.Transform.Position = c.body.Transform.Position.Add(c.target.Sub(c.body.Transform.Position).Mul(0.5))
+	c.calls = append(c.calls, "position")
+}
+
+func (c *mousePick) SolveVelocity(dt float64) {
+	c.calls = append(c.calls, "velocity")
+}
+
+// TestWorld_CustomConstraint drags a body by a custom constraint: it is prepared once per substep,
+// before its position and velocity passes, and the body follows the cursor
+func TestWorld_CustomConstraint(t *testing.T) {
+	world := NewWorld(WithSubsteps(2), WithIterations(2, 1))
+	world.Gravity = mgl64.Vec3{}
+	box := createBox(mgl64.Vec3{0, 0, 0}, mgl64.Vec3{0.5, 0.5, 0.5}, actor.BodyTypeDynamic)
+	world.AddBody(box)
+
+	cursor := mgl64.Vec3{0, 0, 0}
+	pick := &mousePick{body: box, cursor: func() mgl64.Vec3 { return cursor }}
+	world.AddConstraint(pick)
+
+	cursor = mgl64.Vec3{1, 0, 0}
+	world.Step(1.0 / 60.0)
+	want := []string{"pre", "position", "position", "velocity", "pre", "position", "position", "velocity"}
+	if !slices.Equal(pick.calls, want) {
+		t.Errorf("calls = %v, want %v", pick.calls, want)
+	}
+	for range 30 {
+		world.Step(1.0 / 60.0)
+	}
+	if box.Transform.Position.Sub(cursor).Len() > 1e-3 {
+		t.Errorf("box at %v, want dragged to the cursor %v", box.Transform.Position, cursor)
+	}
+}